@@ -84,10 +84,26 @@ func runHookPreReceive(_ context.Context, cmd *cli.Command) error {
 		}
 		oldCommitID := string(fields[0])
 		newCommitID := string(fields[1])
-		branchName := git.RefShortName(string(fields[2]))
+		refName := string(fields[2])
+		repoID, _ := strconv.ParseInt(os.Getenv(database.EnvRepoID), 10, 64)
+		userID, _ := strconv.ParseInt(os.Getenv(database.EnvAuthUserID), 10, 64)
+
+		// Tag protection
+		if strings.HasPrefix(refName, git.RefsTags) {
+			tagName := strings.TrimPrefix(refName, git.RefsTags)
+			allowed, err := database.CanUserChangeTag(repoID, userID, tagName)
+			if err != nil {
+				fail("Internal error", "CanUserChangeTag [repo_id: %d, tag: %s]: %v", repoID, tagName, err)
+			} else if !allowed {
+				fail(fmt.Sprintf("Tag '%s' is protected and you are not in the whitelist", tagName), "")
+			}
+			continue
+		} else if !strings.HasPrefix(refName, git.RefsHeads) {
+			continue
+		}
 
 		// Branch protection
-		repoID, _ := strconv.ParseInt(os.Getenv(database.EnvRepoID), 10, 64)
+		branchName := strings.TrimPrefix(refName, git.RefsHeads)
 		protectBranch, err := database.GetProtectBranchOfRepoByName(repoID, branchName)
 		if err != nil {
 			if database.IsErrBranchNotExist(err) {
@@ -103,9 +119,8 @@ func runHookPreReceive(_ context.Context, cmd *cli.Command) error {
 		bypassRequirePullRequest := false
 
 		// Check if user is in whitelist when enabled
-		userID, _ := strconv.ParseInt(os.Getenv(database.EnvAuthUserID), 10, 64)
 		if protectBranch.EnableWhitelist {
-			if !database.IsUserInProtectBranchWhitelist(repoID, userID, branchName) {
+			if !protectBranch.IsUserWhitelisted(userID) {
 				fail(fmt.Sprintf("Branch '%s' is protected and you are not in the push whitelist", branchName), "")
 			}
 
@@ -122,13 +137,26 @@ func runHookPreReceive(_ context.Context, cmd *cli.Command) error {
 			fail(fmt.Sprintf("Branch '%s' is protected from deletion", branchName), "")
 		}
 
-		// Check force push
-		output, err := git.NewCommand("rev-list", "--max-count=1", oldCommitID, "^"+newCommitID).
-			RunInDir(database.RepoPath(os.Getenv(database.EnvRepoOwnerName), os.Getenv(database.EnvRepoName)))
-		if err != nil {
-			fail("Internal error", "Failed to detect force push: %v", err)
-		} else if len(output) > 0 {
-			fail(fmt.Sprintf("Branch '%s' is protected from force push", branchName), "")
+		// Check force push, a new branch that matches a pattern has nothing to be
+		// forced over.
+		if oldCommitID != git.EmptyID {
+			output, err := git.NewCommand("rev-list", "--max-count=1", oldCommitID, "^"+newCommitID).
+				RunInDir(database.RepoPath(os.Getenv(database.EnvRepoOwnerName), os.Getenv(database.EnvRepoName)))
+			if err != nil {
+				fail("Internal error", "Failed to detect force push: %v", err)
+			} else if len(output) > 0 {
+				fail(fmt.Sprintf("Branch '%s' is protected from force push", branchName), "")
+			}
+		}
+
+		// Check signatures of new commits
+		if protectBranch.RequireSignedCommits {
+			checkCommitSignatures(branchName, oldCommitID, newCommitID)
+		}
+
+		// Check merge commits
+		if protectBranch.RequireLinearHistory {
+			checkLinearHistory(branchName, oldCommitID, newCommitID)
 		}
 	}
 
@@ -153,6 +181,46 @@ func runHookPreReceive(_ context.Context, cmd *cli.Command) error {
 	return nil
 }
 
+// checkCommitSignatures fails the push if any of the new commits of the branch
+// does not have a verified signature.
+func checkCommitSignatures(branchName, oldCommitID, newCommitID string) {
+	repoPath := database.RepoPath(os.Getenv(database.EnvRepoOwnerName), os.Getenv(database.EnvRepoName))
+	refspecs := []string{newCommitID}
+	var opts git.RevListOptions
+	if oldCommitID == git.EmptyID {
+		// Exclude commits reachable from existing refs, the second "--not" makes
+		// the new commit itself not excluded.
+		opts.CommandOptions = git.CommandOptions{Args: []string{"--not", "--all", "--not"}}
+	} else {
+		refspecs = append(refspecs, "^"+oldCommitID)
+	}
+
+	commit, v, err := database.FindUnverifiedCommit(context.Background(), repoPath, refspecs, opts)
+	if err != nil {
+		fail("Internal error", "Failed to verify signatures of new commits: %v", err)
+	} else if commit != nil {
+		fail(fmt.Sprintf("Branch '%s' requires signed commits but commit %s is not verified (%s)", branchName, commit.ID, v.Reason), "")
+	}
+}
+
+// checkLinearHistory fails the push if any of the new commits of the branch is
+// a merge commit.
+func checkLinearHistory(branchName, oldCommitID, newCommitID string) {
+	args := []string{"rev-list", "--min-parents=2", "--max-count=1", newCommitID}
+	if oldCommitID == git.EmptyID {
+		args = append(args, "--not", "--all")
+	} else {
+		args = append(args, "^"+oldCommitID)
+	}
+	output, err := git.NewCommand(args...).
+		RunInDir(database.RepoPath(os.Getenv(database.EnvRepoOwnerName), os.Getenv(database.EnvRepoName)))
+	if err != nil {
+		fail("Internal error", "Failed to detect merge commits: %v", err)
+	} else if len(bytes.TrimSpace(output)) > 0 {
+		fail(fmt.Sprintf("Branch '%s' requires linear history and merge commit %s is not allowed", branchName, bytes.TrimSpace(output)), "")
+	}
+}
+
 func runHookUpdate(_ context.Context, cmd *cli.Command) error {
 	if os.Getenv("SSH_ORIGINAL_COMMAND") == "" {
 		return nil
@@ -43,6 +43,7 @@ import (
 	"gogs.io/gogs/internal/route/lfs"
 	"gogs.io/gogs/internal/route/org"
 	"gogs.io/gogs/internal/route/repo"
+	"gogs.io/gogs/internal/route/scim"
 	"gogs.io/gogs/internal/route/user"
 	"gogs.io/gogs/internal/ssh"
 	"gogs.io/gogs/internal/template"
@@ -83,9 +84,13 @@ func Run(configPath string, portOverride int) error {
 				c.Redirect(conf.Server.Subpath + "/explore/repos")
 			})
 			m.Get("/repos", route.ExploreRepos)
+			m.Get("/topics", route.ExploreTopics)
 			m.Get("/users", route.ExploreUsers)
 			m.Get("/organizations", route.ExploreOrganizations)
-		}, ignSignIn)
+			m.Get("/code", route.ExploreCode)
+		}, ignSignIn, func(c *context.Context) {
+			c.Data["IsCodeSearchEnabled"] = database.IsCodeIndexerEnabled()
+		})
 		m.Get("/^:type(issues|pulls)$", reqSignIn, user.Issues)
 
 		// ***** START: User *****
@@ -103,6 +108,9 @@ func Run(configPath string, portOverride int) error {
 			m.Combo("/ssh").Get(user.SettingsSSHKeys).
 				Post(bindIgnErr(form.AddSSHKey{}), user.SettingsSSHKeysPost)
 			m.Post("/ssh/delete", user.DeleteSSHKey)
+			m.Combo("/gpg").Get(user.SettingsGPGKeys).
+				Post(bindIgnErr(form.AddGPGKey{}), user.SettingsGPGKeysPost)
+			m.Post("/gpg/delete", user.DeleteGPGKey)
 			m.Group("/security", func() {
 				m.Get("", user.SettingsSecurity)
 				m.Combo("/two_factor_enable").Get(user.SettingsTwoFactorEnable).
@@ -119,11 +127,26 @@ func Run(configPath string, portOverride int) error {
 				m.Get("", user.SettingsOrganizations)
 				m.Post("/leave", user.SettingsLeaveOrganization)
 			})
+			m.Group("/blocked_users", func() {
+				m.Combo("").Get(user.SettingsBlockedUsers).
+					Post(user.SettingsBlockedUsersPost)
+				m.Post("/unblock", user.SettingsUnblockUser)
+			})
 
 			settingsHandler := user.NewSettingsHandler(user.NewSettingsStore())
 			m.Combo("/applications").Get(settingsHandler.Applications()).
 				Post(bindIgnErr(form.NewAccessToken{}), settingsHandler.ApplicationsPost())
 			m.Post("/applications/delete", settingsHandler.DeleteApplication())
+			m.Post("/applications/feed_token", settingsHandler.RegenerateFeedToken())
+			m.Group("/oauth2", func() {
+				m.Combo("").Get(user.SettingsOAuth2Applications).
+					Post(bindIgnErr(form.OAuth2Application{}), user.SettingsOAuth2ApplicationsPost)
+				m.Post("/delete", user.DeleteOAuth2Application)
+				m.Post("/revoke", user.RevokeOAuth2Grant)
+				m.Combo("/:id").Get(user.SettingsOAuth2Application).
+					Post(bindIgnErr(form.OAuth2Application{}), user.SettingsOAuth2ApplicationPost)
+				m.Post("/:id/regenerate_secret", user.RegenerateOAuth2ClientSecret)
+			}, user.MustEnableOAuth2)
 			m.Route("/delete", "GET,POST", user.SettingsDelete)
 		}, reqSignIn, func(c *context.Context) {
 			c.Data["PageIsUserSettings"] = true
@@ -133,6 +156,12 @@ func Run(configPath string, portOverride int) error {
 			m.Any("/activate_email", user.ActivateEmail)
 			m.Get("/email2user", user.Email2User)
 		})
+
+		m.Group("/login/oauth", func() {
+			m.Combo("/authorize", reqSignIn).Get(user.OAuth2Authorize).Post(user.OAuth2AuthorizePost)
+			m.Post("/access_token", user.OAuth2AccessToken)
+			m.Post("/revoke", user.OAuth2Revoke)
+		}, user.MustEnableOAuth2)
 		// ***** END: User *****
 
 		reqAdmin := context.Toggle(&context.ToggleOptions{SignInRequired: true, AdminRequired: true})
@@ -176,6 +205,9 @@ func Run(configPath string, portOverride int) error {
 		}, reqAdmin)
 		// ***** END: Admin *****
 
+		// Feed readers cannot sign in, private feeds are authenticated by feed tokens.
+		m.Get("/:username\\.:format(atom|rss)$", context.FeedTokenAuth(), ignSignIn, context.InjectParamsUser(), user.Feed)
+
 		m.Group("", func() {
 			m.Group("/:username", func() {
 				m.Get("", user.Profile)
@@ -312,6 +344,17 @@ func Run(configPath string, portOverride int) error {
 					m.Post("/avatar", binding.MultipartForm(form.Avatar{}), org.SettingsAvatar)
 					m.Post("/avatar/delete", org.SettingsDeleteAvatar)
 					m.Group("/hooks", webhookRoutes)
+					m.Group("/branches", func() {
+						m.Get("", org.SettingsProtectedBranches)
+						m.Post("/delete", org.DeleteProtectedBranch)
+						m.Combo("/*").Get(org.SettingsProtectedBranch).
+							Post(bindIgnErr(form.ProtectBranch{}), org.SettingsProtectedBranchPost)
+					})
+					m.Group("/blocked_users", func() {
+						m.Combo("").Get(org.SettingsBlockedUsers).
+							Post(org.SettingsBlockedUsersPost)
+						m.Post("/unblock", org.SettingsUnblockUser)
+					})
 					m.Route("/delete", "GET,POST", org.SettingsDelete)
 				})
 
@@ -342,11 +385,12 @@ func Run(configPath string, portOverride int) error {
 					m.Post("/access_mode", repo.ChangeCollaborationAccessMode)
 					m.Post("/delete", repo.DeleteCollaboration)
 				})
-				m.Group("/branches", func() {
-					m.Get("", repo.SettingsBranches)
-					m.Post("/default_branch", repo.UpdateDefaultBranch)
-					m.Combo("/*").Get(repo.SettingsProtectedBranch).
-						Post(bindIgnErr(form.ProtectBranch{}), repo.SettingsProtectedBranchPost)
+				m.Group("/tags", func() {
+					m.Combo("").Get(repo.SettingsTags).
+						Post(bindIgnErr(form.ProtectTag{}), repo.SettingsTagsPost)
+					m.Post("/delete", repo.DeleteProtectedTag)
+					m.Combo("/:id").Get(repo.SettingsProtectedTag).
+						Post(bindIgnErr(form.ProtectTag{}), repo.SettingsProtectedTagPost)
 				}, func(c *context.Context) {
 					if c.Repo.Repository.IsMirror {
 						c.NotFound()
@@ -379,6 +423,22 @@ func Run(configPath string, portOverride int) error {
 			})
 		}, reqSignIn, context.RepoAssignment(), reqRepoAdmin, context.RepoRef())
 
+		// Names of branch protection rules can be glob patterns that are not valid
+		// references, thus routes are not going through context.RepoRef.
+		m.Group("/:username/:reponame/settings/branches", func() {
+			m.Get("", repo.SettingsBranches)
+			m.Post("/default_branch", repo.UpdateDefaultBranch)
+			m.Post("/delete", repo.DeleteProtectedBranch)
+			m.Combo("/*").Get(repo.SettingsProtectedBranch).
+				Post(bindIgnErr(form.ProtectBranch{}), repo.SettingsProtectedBranchPost)
+		}, reqSignIn, context.RepoAssignment(), reqRepoAdmin, func(c *context.Context) {
+			if c.Repo.Repository.IsMirror {
+				c.NotFound()
+				return
+			}
+			c.Data["PageIsSettings"] = true
+		})
+
 		m.Post("/:username/:reponame/action/:action", reqSignIn, context.RepoAssignment(), repo.Action)
 		m.Group("/:username/:reponame", func() {
 			m.Get("/issues", repo.RetrieveLabels, repo.Issues)
@@ -397,11 +457,13 @@ func Run(configPath string, portOverride int) error {
 					m.Post("/title", repo.UpdateIssueTitle)
 					m.Post("/content", repo.UpdateIssueContent)
 					m.Combo("/comments").Post(bindIgnErr(form.CreateComment{}), repo.NewComment)
+					m.Post("/reactions/:action", repo.ChangeIssueReaction)
 				})
 			})
 			m.Group("/comments/:id", func() {
 				m.Post("", repo.UpdateCommentContent)
 				m.Post("/delete", repo.DeleteComment)
+				m.Post("/reactions/:action", repo.ChangeCommentReaction)
 			})
 		}, reqSignIn, context.RepoAssignment(true))
 		m.Group("/:username/:reponame", func() {
@@ -419,6 +481,21 @@ func Run(configPath string, portOverride int) error {
 					m.Post("/label", repo.UpdateIssueLabel)
 					m.Post("/milestone", repo.UpdateIssueMilestone)
 					m.Post("/assignee", repo.UpdateIssueAssignee)
+					m.Post("/reviewer", repo.UpdatePullReviewer)
+					m.Post("/team_reviewer", repo.UpdatePullTeamReviewer)
+					m.Post("/dependency", repo.UpdateIssueDependency)
+					m.Group("/times", func() {
+						m.Post("/stopwatch/toggle", repo.ToggleStopwatch)
+						m.Post("/stopwatch/cancel", repo.CancelStopwatch)
+						m.Post("/add", bindIgnErr(form.TrackedTime{}), repo.AddTrackedTime)
+						m.Post("/:id/delete", repo.DeleteTrackedTime)
+					})
+					m.Post("/estimate", bindIgnErr(form.TrackedTime{}), repo.UpdateTimeEstimate)
+					m.Post("/lock", repo.LockIssue)
+					m.Post("/unlock", repo.UnlockIssue)
+					m.Post("/pin", repo.PinIssue)
+					m.Post("/unpin", repo.UnpinIssue)
+					m.Post("/transfer", repo.TransferIssue)
 				}, reqRepoWriter)
 			})
 			m.Group("/labels", func() {
@@ -482,6 +559,11 @@ func Run(configPath string, portOverride int) error {
 			})
 		}, reqSignIn, context.RepoAssignment())
 
+		m.Group("/:username/:reponame", func() {
+			m.Get("/releases\\.:format(atom|rss)$", repo.ReleasesFeed)
+			m.Get("/tags\\.:format(atom|rss)$", repo.TagsFeed)
+			m.Get("/:format(atom|rss)/branch/*", repo.BranchFeed)
+		}, context.FeedTokenAuth(), ignSignIn, context.RepoAssignment(), repo.MustBeNotBare)
 		m.Group("/:username/:reponame", func() {
 			m.Group("", func() {
 				m.Get("/releases", repo.MustBeNotBare, repo.Releases)
@@ -513,11 +595,20 @@ func Run(configPath string, portOverride int) error {
 				m.Get("/commits", context.RepoRef(), repo.ViewPullCommits)
 				m.Get("/files", context.RepoRef(), repo.ViewPullFiles)
 				m.Post("/merge", reqRepoWriter, repo.MergePullRequest)
+				m.Post("/auto_merge", reqRepoWriter, repo.ScheduleAutoMerge)
+				m.Post("/auto_merge/cancel", reqRepoWriter, repo.CancelAutoMerge)
+				m.Post("/sign_off", reqRepoWriter, repo.SignOffAsCodeOwner)
+				m.Post("/sign_off/withdraw", reqRepoWriter, repo.WithdrawCodeOwnerSignOff)
+				m.Post("/update", reqSignIn, repo.UpdatePullRequestBranch)
+				m.Post("/maintainer_edit", reqSignIn, repo.UpdateAllowMaintainerEdit)
 			}, repo.MustAllowPulls)
 
 			m.Group("", func() {
 				m.Get("/src/*", repo.Home)
+				m.Get("/blame/*", repo.Blame)
+				m.Get("/search", repo.Search)
 				m.Get("/commits/*", repo.RefCommits)
+				m.Get("/graph", repo.Graph)
 				m.Get("/forks", repo.Forks)
 			}, repo.MustBeNotBare, context.RepoRef())
 			// Bridged to Flamego to skip the legacy `RepoRef` middleware, which double-resolves the ref.
@@ -544,6 +635,10 @@ func Run(configPath string, portOverride int) error {
 			apiv1.RegisterRoutes(m)
 		}, ignSignIn)
 
+		m.Group("/scim/v2", func() {
+			scim.RegisterRoutes(m.Router)
+		})
+
 		m.Any("/api/web/*", flamegoBridger(webHandler))
 		m.Get("/redirect", flamegoBridger(webHandler))
 		m.Get("/captcha/*", flamegoBridger(webHandler))
@@ -933,6 +1028,10 @@ func initServices(customConf string) error {
 	database.InitSyncMirrors()
 	database.InitDeliverHooks()
 	database.InitTestPullRequests()
+	database.InitAutoMergePullRequests()
+	database.InitIssueIndexer()
+	database.InitCodeIndexer()
+	database.InitLanguageStats()
 
 	if conf.HasMinWinSvc {
 		log.Info("Builtin Windows Service is supported")
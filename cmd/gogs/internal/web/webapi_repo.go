@@ -3,6 +3,7 @@ package web
 import (
 	"context"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/cockroachdb/errors"
@@ -98,12 +99,20 @@ type repoCommitSignature struct {
 	ProfileURL string    `json:"profileURL,omitempty"`
 }
 
+type repoCommitVerification struct {
+	Verified   bool   `json:"verified"`
+	Reason     string `json:"reason"`
+	Signer     string `json:"signer,omitempty"`
+	SigningKey string `json:"signingKey,omitempty"`
+}
+
 type repoCommit struct {
-	SHA     string              `json:"sha"`
-	Subject string              `json:"subject"`
-	Body    string              `json:"body"`
-	Author  repoCommitSignature `json:"author"`
-	Parents []string            `json:"parents"`
+	SHA          string                  `json:"sha"`
+	Subject      string                  `json:"subject"`
+	Body         string                  `json:"body"`
+	Author       repoCommitSignature     `json:"author"`
+	Parents      []string                `json:"parents"`
+	Verification *repoCommitVerification `json:"verification,omitempty"`
 }
 
 func getRepoCommit(c flamego.Context, repoCtx *repoContext) (statusCode int, resp *repoCommit, err error) {
@@ -141,32 +150,133 @@ func getRepoCommit(c flamego.Context, repoCtx *repoContext) (statusCode int, res
 		parents[i] = sha.String()
 	}
 
-	toSignature := func(s *git.Signature) repoCommitSignature {
-		sig := repoCommitSignature{
-			Name:      s.Name,
-			Email:     s.Email,
-			When:      s.When.UTC(),
-			AvatarURL: tool.AvatarLink(s.Email),
-		}
-		if u, err := database.Handle.Users().GetByEmail(ctx, s.Email); err == nil && u != nil {
-			sig.ProfileURL = conf.Server.Subpath + "/" + u.Name
-		}
-		return sig
-	}
-
 	subject := commit.Summary()
 	var body string
 	if msg := commit.Message; len(msg) > len(subject) {
 		body = msg[len(subject):]
 	}
 
-	return http.StatusOK, &repoCommit{
+	resp = &repoCommit{
 		SHA:     commitID,
 		Subject: subject,
 		Body:    body,
-		Author:  toSignature(commit.Author),
+		Author:  toRepoCommitSignature(ctx, commit.Author),
 		Parents: parents,
-	}, nil
+	}
+	if v := database.VerifyCommit(ctx, gitRepo.Path(), commit); v.Reason != database.VerificationReasonUnsigned {
+		resp.Verification = &repoCommitVerification{
+			Verified:   v.Verified,
+			Reason:     v.Reason,
+			SigningKey: v.SigningKey,
+		}
+		if v.Signer != nil {
+			resp.Verification.Signer = v.Signer.Name
+		}
+	}
+	return http.StatusOK, resp, nil
+}
+
+func toRepoCommitSignature(ctx context.Context, s *git.Signature) repoCommitSignature {
+	sig := repoCommitSignature{
+		Name:      s.Name,
+		Email:     s.Email,
+		When:      s.When.UTC(),
+		AvatarURL: tool.AvatarLink(s.Email),
+	}
+	if u, err := database.Handle.Users().GetByEmail(ctx, s.Email); err == nil && u != nil {
+		sig.ProfileURL = conf.Server.Subpath + "/" + u.Name
+	}
+	return sig
+}
+
+type repoGraphLine struct {
+	From  int `json:"from"`
+	To    int `json:"to"`
+	Color int `json:"color"`
+}
+
+type repoGraphRow struct {
+	SHA      string              `json:"sha"`
+	Subject  string              `json:"subject"`
+	Author   repoCommitSignature `json:"author"`
+	Parents  []string            `json:"parents"`
+	Branches []string            `json:"branches,omitempty"`
+	Column   int                 `json:"column"`
+	Color    int                 `json:"color"`
+	Upper    []repoGraphLine     `json:"upper"`
+	Lower    []repoGraphLine     `json:"lower"`
+}
+
+type repoGraph struct {
+	Rows    []repoGraphRow `json:"rows"`
+	Width   int            `json:"width"`
+	HasMore bool           `json:"hasMore"`
+}
+
+func toRepoGraphLines(lines []gitx.GraphLine) []repoGraphLine {
+	out := make([]repoGraphLine, len(lines))
+	for i, line := range lines {
+		out[i] = repoGraphLine{
+			From:  line.From,
+			To:    line.To,
+			Color: line.Color,
+		}
+	}
+	return out
+}
+
+// getRepoGraph returns a page of the commit graph of all branches or branches
+// selected by the "branch" query parameters.
+func getRepoGraph(c flamego.Context, repoCtx *repoContext) (statusCode int, resp *repoGraph, err error) {
+	if !repoCtx.ViewerCanRead() {
+		return http.StatusNotFound, nil, errors.New("repository does not exist")
+	}
+
+	ctx := c.Request().Context()
+	owner := repoCtx.Owner
+	repo := repoCtx.Repo
+	query := c.Request().URL.Query()
+
+	var branches []string
+	for _, branch := range query["branch"] {
+		if branch != "" {
+			branches = append(branches, branch)
+		}
+	}
+	page, _ := strconv.Atoi(query.Get("page"))
+	page = max(page, 1)
+	pageSize := conf.UI.User.CommitsPagingNum
+
+	graph, err := gitx.RepoGraph(ctx, repox.RepositoryPath(owner.Name, repo.Name), gitx.GraphOptions{
+		Branches: branches,
+		Skip:     (page - 1) * pageSize,
+		Limit:    pageSize,
+		Timeout:  time.Duration(conf.Git.Timeout.Diff) * time.Second,
+	})
+	if err != nil {
+		log.Error("getRepoGraph: get commit graph of %q/%q: %v", owner.Name, repo.Name, err)
+		return http.StatusInternalServerError, nil, errors.Wrap(err, "get commit graph")
+	}
+
+	resp = &repoGraph{
+		Rows:    make([]repoGraphRow, len(graph.Rows)),
+		Width:   graph.Width,
+		HasMore: graph.HasMore,
+	}
+	for i, row := range graph.Rows {
+		resp.Rows[i] = repoGraphRow{
+			SHA:      row.Commit.ID,
+			Subject:  row.Commit.Subject,
+			Author:   toRepoCommitSignature(ctx, row.Commit.Author),
+			Parents:  row.Commit.ParentIDs,
+			Branches: row.Commit.Branches,
+			Column:   row.Column,
+			Color:    row.Color,
+			Upper:    toRepoGraphLines(row.Upper),
+			Lower:    toRepoGraphLines(row.Lower),
+		}
+	}
+	return http.StatusOK, resp, nil
 }
 
 type repoWatchResponse struct {
@@ -185,7 +295,9 @@ func repoWatchAction(ctx context.Context, repoCtx *repoContext, watching bool) (
 	} else {
 		err = database.WatchRepo(repoCtx.ViewerID, repo.ID, false)
 	}
-	if err != nil {
+	if database.IsErrBlockedByUser(err) {
+		return http.StatusForbidden, nil, errors.New("blocked by the repository owner")
+	} else if err != nil {
 		log.Error("repoWatchAction: set watching=%t for user %d on repo %d: %v", watching, repoCtx.ViewerID, repo.ID, err)
 		return http.StatusInternalServerError, nil, errors.Wrap(err, "watch repo")
 	}
@@ -224,7 +336,9 @@ func repoStarAction(ctx context.Context, repoCtx *repoContext, starring bool) (s
 	} else {
 		err = database.StarRepo(repoCtx.ViewerID, repo.ID, false)
 	}
-	if err != nil {
+	if database.IsErrBlockedByUser(err) {
+		return http.StatusForbidden, nil, errors.New("blocked by the repository owner")
+	} else if err != nil {
 		log.Error("repoStarAction: set starred=%t for user %d on repo %d: %v", starring, repoCtx.ViewerID, repo.ID, err)
 		return http.StatusInternalServerError, nil, errors.Wrap(err, "star repo")
 	}
@@ -42,6 +42,7 @@ func main() {
 		Commands: []*cli.Command{
 			&webCommand,
 			&servCommand,
+			&principalsCommand,
 			&hookCommand,
 			&adminCommand,
 			&importCommand,
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+	"golang.org/x/crypto/ssh"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/database"
+)
+
+var principalsCommand = cli.Command{
+	Name:  "principals",
+	Usage: "This command should only be called by SSH server as AuthorizedPrincipalsCommand",
+	Description: `Principals prints the authorized principals of an SSH certificate,
+which is expected to be configured in "sshd_config" as:
+
+    AuthorizedPrincipalsCommand /path/to/gogs principals --config=/path/to/app.ini %t %k`,
+	Action: runPrincipals,
+	Flags: []cli.Flag{
+		stringFlag("config, c", "", "Custom configuration file path"),
+	},
+}
+
+func runPrincipals(ctx context.Context, cmd *cli.Command) error {
+	setup(cmd, "principals.log", true)
+
+	if conf.SSH.Disabled {
+		return nil
+	}
+
+	if cmd.Args().Len() < 2 {
+		fail("Not enough arguments", "Not enough arguments")
+	}
+
+	certType, certData := cmd.Args().Get(0), cmd.Args().Get(1)
+	if !strings.HasSuffix(certType, "-cert-v01@openssh.com") {
+		fail("Not a certificate", "Unexpected certificate type %q", certType)
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(certData)
+	if err != nil {
+		fail("Invalid certificate", "Failed to decode certificate: %v", err)
+	}
+	key, err := ssh.ParsePublicKey(raw)
+	if err != nil {
+		fail("Invalid certificate", "Failed to parse certificate: %v", err)
+	}
+	cert, ok := key.(*ssh.Certificate)
+	if !ok || cert.CertType != ssh.UserCert {
+		fail("Invalid certificate", "Not a user certificate: %s", certType)
+	}
+
+	// OpenSSH has verified the certificate against its own "TrustedUserCAKeys",
+	// additionally require the CA to be trusted by us when we are told so.
+	if conf.SSH.TrustedUserCAKeysFile != "" {
+		authorities, err := database.LoadTrustedUserCAKeys(conf.SSH.TrustedUserCAKeysFile)
+		if err != nil {
+			fail("Internal error", "Failed to load trusted user CA keys: %v", err)
+		}
+
+		err = database.CheckSSHUserCertificate(cert, authorities, nil)
+		if err != nil {
+			fail("Certificate is not trusted", "Rejected certificate %q: %v", cert.KeyId, err)
+		}
+	}
+
+	user, principal, err := database.GetUserBySSHCertificate(ctx, cert)
+	if err != nil {
+		if database.IsErrUserNotExist(err) {
+			fail("No matching user", "No user matches principals %v of certificate %q", cert.ValidPrincipals, cert.KeyId)
+		}
+		fail("Internal error", "Failed to get user by certificate: %v", err)
+	}
+
+	fmt.Print(database.AuthorizedPrincipalsString(user.ID, principal))
+	return nil
+}
@@ -182,23 +182,39 @@ func runServ(ctx context.Context, cmd *cli.Command) error {
 	// Allow anonymous (user is nil) clone for public repositories.
 	var user *database.User
 
-	keyID, _ := strconv.ParseInt(strings.TrimPrefix(cmd.Args().Get(0), "key-"), 10, 64)
-	key, err := database.GetPublicKeyByID(keyID)
-	if err != nil {
-		fail("Invalid key ID", "Invalid key ID '%s': %v", cmd.Args().Get(0), err)
+	// The key is nil when the user is authenticated by an SSH certificate.
+	var key *database.PublicKey
+	identity := cmd.Args().Get(0)
+	if strings.HasPrefix(identity, "user-") {
+		userID, _ := strconv.ParseInt(strings.TrimPrefix(identity, "user-"), 10, 64)
+		user, err = database.Handle.Users().GetByID(ctx, userID)
+		if err != nil {
+			fail("Invalid user ID", "Invalid user ID '%s': %v", identity, err)
+		}
+		if !user.IsActive || user.ProhibitLogin {
+			fail("User is not allowed to sign in", "User '%s' is not allowed to sign in", user.Name)
+		}
+	} else {
+		keyID, _ := strconv.ParseInt(strings.TrimPrefix(identity, "key-"), 10, 64)
+		key, err = database.GetPublicKeyByID(keyID)
+		if err != nil {
+			fail("Invalid key ID", "Invalid key ID '%s': %v", identity, err)
+		}
 	}
 
 	if requestMode == database.AccessModeWrite || repo.IsPrivate {
 		// Check deploy key or user key.
-		if key.IsDeployKey() {
+		if key != nil && key.IsDeployKey() {
 			if key.Mode < requestMode {
 				fail("Key permission denied", "Cannot push with deployment key: %d", key.ID)
 			}
 			checkDeployKey(key, repo)
 		} else {
-			user, err = database.Handle.Users().GetByKeyID(ctx, key.ID)
-			if err != nil {
-				fail("Internal error", "Failed to get user by key ID '%d': %v", key.ID, err)
+			if user == nil {
+				user, err = database.Handle.Users().GetByKeyID(ctx, key.ID)
+				if err != nil {
+					fail("Internal error", "Failed to get user by key ID '%d': %v", key.ID, err)
+				}
 			}
 
 			mode := database.Handle.Permissions().AccessMode(ctx, user.ID, repo.ID,
@@ -222,13 +238,13 @@ func runServ(ctx context.Context, cmd *cli.Command) error {
 		// A deploy key doesn't represent a signed in user, so in a site with Auth.RequireSignInView enabled,
 		// we should give read access only in repositories where this deploy key is in use. In other cases,
 		// a server or system using an active deploy key can get read access to all repositories on a Gogs instance.
-		if key.IsDeployKey() && conf.Auth.RequireSigninView {
+		if key != nil && key.IsDeployKey() && conf.Auth.RequireSigninView {
 			checkDeployKey(key, repo)
 		}
 	}
 
 	// Update user key activity.
-	if key.ID > 0 {
+	if key != nil && key.ID > 0 {
 		key, err := database.GetPublicKeyByID(key.ID)
 		if err != nil {
 			fail("Internal error", "GetPublicKeyByID: %v", err)
@@ -0,0 +1,143 @@
+// Package codeowners parses CODEOWNERS files and matches paths of files to
+// their owners.
+package codeowners
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// Paths are paths of CODEOWNERS files relative to the root of the tree, in the
+// order of lookup. Only the first file that exists is used.
+var Paths = []string{"CODEOWNERS", "docs/CODEOWNERS", ".gogs/CODEOWNERS"}
+
+// Rule is a line of a CODEOWNERS file.
+type Rule struct {
+	Pattern string
+	// Owners are user names (e.g. "@unknwon"), team names (e.g.
+	// "@gogs/maintainers") or email addresses. A rule without owners makes
+	// matching files unowned.
+	Owners []string
+
+	re *regexp.Regexp
+}
+
+// File is a parsed CODEOWNERS file.
+type File struct {
+	Rules []*Rule
+}
+
+// Parse parses the content of a CODEOWNERS file. Lines with malformed patterns
+// are skipped.
+func Parse(content []byte) *File {
+	f := new(File)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// Comments start with "#" at the beginning of a field, an escaped "\#"
+		// is part of the pattern.
+		for i := range fields {
+			if strings.HasPrefix(fields[i], "#") {
+				fields = fields[:i]
+				break
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		re := patternToRegexp(fields[0])
+		if re == nil {
+			continue
+		}
+		f.Rules = append(f.Rules, &Rule{
+			Pattern: fields[0],
+			Owners:  fields[1:],
+			re:      re,
+		})
+	}
+	return f
+}
+
+// Match returns the rule that applies to the file in given path relative to
+// the root of the tree, which is the last matching rule. It returns nil if no
+// rule matches.
+func (f *File) Match(filePath string) *Rule {
+	for i := len(f.Rules) - 1; i >= 0; i-- {
+		if f.Rules[i].re.MatchString(filePath) {
+			return f.Rules[i]
+		}
+	}
+	return nil
+}
+
+// patternToRegexp converts the pattern of a CODEOWNERS file to a regular
+// expression that matches full paths of files. It returns nil if the pattern
+// is malformed.
+//
+// Patterns follow the rules of .gitignore files, a pattern matches files in a
+// matching directory as well, except patterns ending with "/*" that only match
+// files directly in the directory.
+func patternToRegexp(pattern string) *regexp.Regexp {
+	// Negation is not supported by CODEOWNERS files.
+	if strings.HasPrefix(pattern, "!") {
+		return nil
+	}
+
+	suffix := "(/.*)?"
+	switch {
+	case strings.HasSuffix(pattern, "/*"):
+		suffix = ""
+	case strings.HasSuffix(pattern, "/"):
+		// Patterns that end with a slash only match directories.
+		suffix = "/.*"
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+
+	// A pattern without a slash, except the trailing one, matches at any level.
+	prefix := ""
+	if !strings.Contains(pattern, "/") {
+		prefix = "(.*/)?"
+	}
+	pattern = strings.TrimPrefix(pattern, "/")
+	if pattern == "" {
+		return nil
+	}
+
+	re, err := regexp.Compile("^" + prefix + globToRegexp(pattern) + suffix + "$")
+	if err != nil {
+		return nil
+	}
+	return re
+}
+
+// globToRegexp converts the glob pattern to a regular expression without
+// anchors, where "**" matches across directories.
+func globToRegexp(pattern string) string {
+	var buf strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			buf.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			buf.WriteString(".*")
+			i++
+		case c == '*':
+			buf.WriteString("[^/]*")
+		case c == '?':
+			buf.WriteString("[^/]")
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			buf.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		case c >= 0x80:
+			// Bytes of multibyte characters are literal.
+			buf.WriteByte(c)
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,52 @@
+package codeowners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFile_Match(t *testing.T) {
+	f := Parse([]byte(`# Default owners
+*                  @admin
+
+*.js               @frontend @gogs/web # Trailing comment
+/docs/             docs@example.com
+docs/*.md          @writer
+build/logs/        @ops
+internal/**/db.go  @dba
+vendor/
+!negated           @nobody
+\#hash             @hash
+`))
+
+	tests := []struct {
+		path        string
+		wantPattern string
+		wantOwners  []string
+	}{
+		{path: "main.go", wantPattern: "*", wantOwners: []string{"@admin"}},
+		{path: "public/js/app.js", wantPattern: "*.js", wantOwners: []string{"@frontend", "@gogs/web"}},
+		{path: "docs/api/index.html", wantPattern: "/docs/", wantOwners: []string{"docs@example.com"}},
+		{path: "docs/README.md", wantPattern: "docs/*.md", wantOwners: []string{"@writer"}},
+		{path: "docs/api/README.md", wantPattern: "/docs/", wantOwners: []string{"docs@example.com"}},
+		{path: "build/logs/2026/out.log", wantPattern: "build/logs/", wantOwners: []string{"@ops"}},
+		{path: "internal/db.go", wantPattern: "internal/**/db.go", wantOwners: []string{"@dba"}},
+		{path: "internal/database/db.go", wantPattern: "internal/**/db.go", wantOwners: []string{"@dba"}},
+		{path: "vendor/lib/lib.go", wantPattern: "vendor/", wantOwners: []string{}},
+		{path: "negated", wantPattern: "*", wantOwners: []string{"@admin"}},
+		{path: "#hash", wantPattern: `\#hash`, wantOwners: []string{"@hash"}},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			rule := f.Match(test.path)
+			if !assert.NotNil(t, rule) {
+				return
+			}
+			assert.Equal(t, test.wantPattern, rule.Pattern)
+			assert.Equal(t, test.wantOwners, rule.Owners)
+		})
+	}
+
+	assert.Nil(t, Parse([]byte("/docs/* @writer")).Match("docs/api/README.md"))
+}
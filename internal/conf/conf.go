@@ -127,6 +127,14 @@ func Init(customConf string) error {
 	}
 	SSH.RootPath = ensureAbs(SSH.RootPath)
 	SSH.KeyTestPath = ensureAbs(SSH.KeyTestPath)
+	if SSH.TrustedUserCAKeysFile != "" {
+		SSH.TrustedUserCAKeysFile = ensureAbs(SSH.TrustedUserCAKeysFile)
+	}
+
+	SSH.CertificatePrincipals = map[string]string{}
+	for _, key := range File.Section("ssh.certificate_principals").Keys() {
+		SSH.CertificatePrincipals[key.Name()] = key.String()
+	}
 
 	if !SSH.Disabled {
 		if !SSH.StartBuiltinServer {
@@ -176,6 +184,13 @@ func Init(customConf string) error {
 	}
 	Repository.Root = ensureAbs(Repository.Root)
 	Repository.Upload.TempPath = ensureAbs(Repository.Upload.TempPath)
+	for _, format := range Repository.Archive.Formats {
+		switch format {
+		case "zip", "tar", "tar.gz", "tar.bz2", "tar.xz":
+		default:
+			return errors.Newf("unsupported '[repository.archive] FORMATS' %q", format)
+		}
+	}
 
 	// *****************************
 	// ----- Database settings -----
@@ -377,6 +392,16 @@ func Init(customConf string) error {
 	LFS.ObjectsPath = ensureAbs(LFS.ObjectsPath)
 	LFS.ObjectsTempPath = ensureAbs(LFS.ObjectsTempPath)
 
+	// *****************************
+	// ----- Indexer settings -----
+	// *****************************
+
+	if err = File.Section("indexer").MapTo(&Indexer); err != nil {
+		return errors.Wrap(err, "mapping [indexer] section")
+	}
+	Indexer.IssuePath = ensureAbs(Indexer.IssuePath)
+	Indexer.CodePath = ensureAbs(Indexer.CodePath)
+
 	handleDeprecated()
 	if !HookMode {
 		for _, warning := range checkInvalidOptions(File) {
@@ -404,6 +429,10 @@ func Init(customConf string) error {
 		return errors.Wrap(err, "mapping [git] section")
 	} else if err = File.Section("api").MapTo(&API); err != nil {
 		return errors.Wrap(err, "mapping [api] section")
+	} else if err = File.Section("oauth2").MapTo(&OAuth2); err != nil {
+		return errors.Wrap(err, "mapping [oauth2] section")
+	} else if err = File.Section("scim").MapTo(&SCIM); err != nil {
+		return errors.Wrap(err, "mapping [scim] section")
 	} else if err = File.Section("ui").MapTo(&UI); err != nil {
 		return errors.Wrap(err, "mapping [ui] section")
 	} else if err = File.Section("prometheus").MapTo(&Prometheus); err != nil {
@@ -412,6 +441,10 @@ func Init(customConf string) error {
 		return errors.Wrap(err, "mapping [other] section")
 	}
 
+	if SCIM.Enabled && SCIM.Token == "" {
+		return errors.New("[scim] TOKEN must be set when SCIM is enabled")
+	}
+
 	HasRobotsTxt = osx.IsFile(filepath.Join(CustomDir(), "robots.txt"))
 	return nil
 }
@@ -76,3 +76,27 @@ func SetMockPicture(t *testing.T, opts PictureOpts) {
 		mockPicture.Unlock()
 	})
 }
+
+var mockOAuth2 sync.Mutex
+
+func SetMockOAuth2(t *testing.T, opts OAuth2Opts) {
+	mockOAuth2.Lock()
+	before := OAuth2
+	OAuth2 = opts
+	t.Cleanup(func() {
+		OAuth2 = before
+		mockOAuth2.Unlock()
+	})
+}
+
+var mockSCIM sync.Mutex
+
+func SetMockSCIM(t *testing.T, opts SCIMOpts) {
+	mockSCIM.Lock()
+	before := SCIM
+	SCIM = opts
+	t.Cleanup(func() {
+		SCIM = before
+		mockSCIM.Unlock()
+	})
+}
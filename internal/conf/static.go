@@ -307,6 +307,9 @@ type SSHOpts struct {
 	ServerCiphers      []string `ini:"SSH_SERVER_CIPHERS"`
 	ServerMACs         []string `ini:"SSH_SERVER_MACS"`
 	ServerAlgorithms   []string `ini:"SSH_SERVER_ALGORITHMS"`
+
+	TrustedUserCAKeysFile string            `ini:"SSH_TRUSTED_USER_CA_KEYS_FILE"`
+	CertificatePrincipals map[string]string `ini:"-"` // Load from [ssh.certificate_principals]
 }
 
 // SSH settings
@@ -339,6 +342,12 @@ type RepositoryOpts struct {
 		FileMaxSize  int64
 		MaxFiles     int
 	} `ini:"repository.upload"`
+
+	// Repository archive settings
+	Archive struct {
+		Formats    []string
+		IncludeLFS bool `ini:"INCLUDE_LFS"`
+	} `ini:"repository.archive"`
 }
 
 // Repository settings
@@ -369,6 +378,18 @@ type LFSOpts struct {
 // LFS settings
 var LFS LFSOpts
 
+type IndexerOpts struct {
+	IssueType string
+	IssuePath string
+
+	CodeType        string
+	CodePath        string
+	CodeMaxFileSize int64
+}
+
+// Indexer settings
+var Indexer IndexerOpts
+
 type UIUserOpts struct {
 	RepoPagingNum     int
 	NewsFeedPagingNum int
@@ -379,8 +400,10 @@ type UIOpts struct {
 	ExplorePagingNum   int
 	IssuePagingNum     int
 	FeedMaxCommitNum   int
+	FeedPagingNum      int
 	ThemeColorMetaTag  string
 	MaxDisplayFileSize int64
+	Reactions          []string
 
 	Admin struct {
 		UserPagingNum   int
@@ -408,6 +431,25 @@ type PictureOpts struct {
 // Picture settings
 var Picture PictureOpts
 
+type OAuth2Opts struct {
+	Enabled                   bool
+	AuthorizationCodeLifetime time.Duration
+	AccessTokenLifetime       time.Duration
+	RefreshTokenLifetime      time.Duration
+}
+
+// OAuth2 provider settings
+var OAuth2 OAuth2Opts
+
+type SCIMOpts struct {
+	Enabled      bool
+	Token        string
+	Organization string
+}
+
+// SCIM provisioning settings
+var SCIM SCIMOpts
+
 type i18nConf struct {
 	Langs     []string          `delim:","`
 	Names     []string          `delim:","`
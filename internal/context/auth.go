@@ -82,6 +82,40 @@ func Toggle(options *ToggleOptions) macaron.Handler {
 	}
 }
 
+// FeedTokenAuth authenticates the user by the feed token in the "token" query
+// parameter when the user is not signed in. It must only be used by routes of
+// Atom and RSS feeds, which are read-only.
+func FeedTokenAuth() macaron.Handler {
+	return func(c *Context) {
+		token := c.Query("token")
+		if c.IsLogged || token == "" {
+			return
+		}
+
+		user, err := database.Handle.Users().GetByFeedToken(c.Req.Context(), token)
+		if err != nil {
+			if database.IsErrUserNotExist(err) {
+				c.Status(http.StatusUnauthorized)
+			} else {
+				c.Error(err, "get user by feed token")
+			}
+			return
+		} else if user.ProhibitLogin || (!user.IsActive && conf.Auth.RequireEmailConfirmation) {
+			c.Status(http.StatusUnauthorized)
+			return
+		}
+
+		c.User = user
+		c.IsLogged = true
+		c.IsTokenAuth = true
+		c.Data["IsLogged"] = c.IsLogged
+		c.Data["LoggedUser"] = c.User
+		c.Data["LoggedUserID"] = c.User.ID
+		c.Data["LoggedUserName"] = c.User.Name
+		c.Data["IsAdmin"] = c.User.IsAdmin
+	}
+}
+
 func isAPIPath(url string) bool {
 	return strings.HasPrefix(url, "/api/")
 }
@@ -145,7 +179,8 @@ func authenticatedUserID(store AuthStore, c *macaron.Context, sess session.Store
 		auHead := c.Req.Header.Get("Authorization")
 		if auHead != "" {
 			auths := strings.Fields(auHead)
-			if len(auths) == 2 && auths[0] == "token" {
+			// NOTE: The "Bearer" scheme is used by OAuth2 clients.
+			if len(auths) == 2 && (auths[0] == "token" || strings.EqualFold(auths[0], "bearer")) {
 				tokenSHA = auths[1]
 			}
 		}
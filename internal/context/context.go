@@ -17,6 +17,7 @@ import (
 	"gogs.io/gogs/internal/conf"
 	"gogs.io/gogs/internal/database"
 	"gogs.io/gogs/internal/errx"
+	"gogs.io/gogs/internal/feed"
 	"gogs.io/gogs/internal/form"
 	"gogs.io/gogs/internal/template"
 )
@@ -242,6 +243,18 @@ func (c *Context) PlainText(status int, msg string) {
 	c.Render.PlainText(status, []byte(msg))
 }
 
+// Feed responses the feed encoded in given format with status http.StatusOK.
+func (c *Context) Feed(f *feed.Feed, format feed.Format) {
+	data, err := f.Encode(format)
+	if err != nil {
+		c.Error(err, "encode feed")
+		return
+	}
+
+	c.Header().Set("Content-Type", format.ContentType())
+	c.Render.RawData(http.StatusOK, data)
+}
+
 func (c *Context) ServeContent(name string, r io.ReadSeeker, params ...any) {
 	modtime := time.Now()
 	for _, p := range params {
@@ -0,0 +1,128 @@
+package cryptox
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/pem"
+	"hash"
+
+	"github.com/cockroachdb/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+// See https://github.com/openssh/openssh-portable/blob/master/PROTOCOL.sshsig
+// for the format of SSH signatures.
+const (
+	sshSigMagicPreamble = "SSHSIG"
+	sshSigVersion       = 1
+	sshSigPEMType       = "SSH SIGNATURE"
+)
+
+type sshSigBlob struct {
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     []byte
+}
+
+type sshSigSignedData struct {
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Hash          []byte
+}
+
+// sshSignatureHash returns the hash function for the named hash algorithm of
+// SSH signatures.
+func sshSignatureHash(algorithm string) (hash.Hash, error) {
+	switch algorithm {
+	case "sha256":
+		return sha256.New(), nil
+	case "sha512":
+		return sha512.New(), nil
+	}
+	return nil, errors.Newf("unsupported hash algorithm %q", algorithm)
+}
+
+// SSHSignedData returns the data to be signed for the message in the namespace
+// using the named hash algorithm, as produced by "ssh-keygen -Y sign".
+func SSHSignedData(message []byte, namespace, hashAlgorithm string) ([]byte, error) {
+	h, err := sshSignatureHash(hashAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	_, _ = h.Write(message)
+
+	return append(
+		[]byte(sshSigMagicPreamble),
+		ssh.Marshal(sshSigSignedData{
+			Namespace:     namespace,
+			HashAlgorithm: hashAlgorithm,
+			Hash:          h.Sum(nil),
+		})...,
+	), nil
+}
+
+// VerifySSHSignature verifies the armored SSH signature of the message in the
+// namespace, and returns the public key that made the signature.
+func VerifySSHSignature(armored string, message []byte, namespace string) (ssh.PublicKey, error) {
+	block, _ := pem.Decode([]byte(armored))
+	if block == nil || block.Type != sshSigPEMType {
+		return nil, errors.New("not an armored SSH signature")
+	}
+
+	data, ok := bytes.CutPrefix(block.Bytes, []byte(sshSigMagicPreamble))
+	if !ok {
+		return nil, errors.New("invalid magic preamble")
+	}
+
+	var blob sshSigBlob
+	err := ssh.Unmarshal(data, &blob)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshal signature blob")
+	} else if blob.Version != sshSigVersion {
+		return nil, errors.Newf("unsupported signature version %d", blob.Version)
+	} else if blob.Namespace != namespace {
+		return nil, errors.Newf("unexpected namespace %q", blob.Namespace)
+	}
+
+	publicKey, err := ssh.ParsePublicKey(blob.PublicKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse public key")
+	}
+
+	var signature ssh.Signature
+	err = ssh.Unmarshal(blob.Signature, &signature)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshal signature")
+	}
+
+	signed, err := SSHSignedData(message, namespace, blob.HashAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	err = publicKey.Verify(signed, &signature)
+	if err != nil {
+		return nil, errors.Wrap(err, "verify signature")
+	}
+	return publicKey, nil
+}
+
+// ArmorSSHSignature returns the armored SSH signature made by the public key,
+// which is the reverse of VerifySSHSignature.
+func ArmorSSHSignature(publicKey ssh.PublicKey, signature *ssh.Signature, namespace, hashAlgorithm string) string {
+	data := append(
+		[]byte(sshSigMagicPreamble),
+		ssh.Marshal(sshSigBlob{
+			Version:       sshSigVersion,
+			PublicKey:     publicKey.Marshal(),
+			Namespace:     namespace,
+			HashAlgorithm: hashAlgorithm,
+			Signature:     ssh.Marshal(signature),
+		})...,
+	)
+	return string(pem.EncodeToMemory(&pem.Block{Type: sshSigPEMType, Bytes: data}))
+}
@@ -0,0 +1,38 @@
+package cryptox
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestVerifySSHSignature(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(priv)
+	require.NoError(t, err)
+
+	message := []byte("tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n")
+	signed, err := SSHSignedData(message, "git", "sha512")
+	require.NoError(t, err)
+	signature, err := signer.Sign(rand.Reader, signed)
+	require.NoError(t, err)
+	armored := ArmorSSHSignature(signer.PublicKey(), signature, "git", "sha512")
+
+	publicKey, err := VerifySSHSignature(armored, message, "git")
+	require.NoError(t, err)
+	assert.Equal(t, signer.PublicKey().Marshal(), publicKey.Marshal())
+
+	_, err = VerifySSHSignature(armored, []byte("tampered"), "git")
+	assert.Error(t, err)
+
+	_, err = VerifySSHSignature(armored, message, "file")
+	assert.Error(t, err)
+
+	_, err = VerifySSHSignature("not a signature", message, "git")
+	assert.Error(t, err)
+}
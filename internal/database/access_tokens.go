@@ -13,13 +13,19 @@ import (
 	"gogs.io/gogs/internal/errx"
 )
 
-// AccessToken is a personal access token.
+// AccessToken is a personal access token, or an access token issued to an
+// OAuth2 application when the GrantID is not zero.
 type AccessToken struct {
 	ID     int64 `gorm:"primarykey"`
 	UserID int64 `xorm:"uid" gorm:"column:uid;index"`
 	Name   string
 	Sha1   string `gorm:"type:VARCHAR(40);unique"`
 	SHA256 string `gorm:"type:VARCHAR(64);unique;not null"`
+	// The ID of the OAuth2Grant that the token is issued for, zero for personal
+	// access tokens.
+	GrantID int64 `gorm:"index;not null;default:0"`
+	// The time that the token expires at, zero means never.
+	ExpiresUnix int64 `gorm:"not null;default:0"`
 
 	Created           time.Time `gorm:"-" json:"-"`
 	CreatedUnix       int64
@@ -73,7 +79,7 @@ func (err ErrAccessTokenAlreadyExist) Error() string {
 // ErrAccessTokenAlreadyExist when an access token with same name already exists
 // for the user.
 func (s *AccessTokensStore) Create(ctx context.Context, userID int64, name string) (*AccessToken, error) {
-	err := s.db.WithContext(ctx).Where("uid = ? AND name = ?", userID, name).First(new(AccessToken)).Error
+	err := s.db.WithContext(ctx).Where("uid = ? AND name = ? AND grant_id = 0", userID, name).First(new(AccessToken)).Error
 	if err == nil {
 		return nil, ErrAccessTokenAlreadyExist{args: errx.Args{"userID": userID, "name": name}}
 	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
@@ -127,7 +133,7 @@ func (ErrAccessTokenNotExist) NotFound() bool {
 }
 
 // GetBySHA1 returns the access token with given SHA1. It returns
-// ErrAccessTokenNotExist when not found.
+// ErrAccessTokenNotExist when not found or expired.
 func (s *AccessTokensStore) GetBySHA1(ctx context.Context, sha1 string) (*AccessToken, error) {
 	// No need to waste a query for an empty SHA1.
 	if sha1 == "" {
@@ -142,13 +148,17 @@ func (s *AccessTokensStore) GetBySHA1(ctx context.Context, sha1 string) (*Access
 	} else if err != nil {
 		return nil, err
 	}
+
+	if token.ExpiresUnix > 0 && token.ExpiresUnix <= s.db.NowFunc().Unix() {
+		return nil, ErrAccessTokenNotExist{args: errx.Args{"sha": sha1}}
+	}
 	return token, nil
 }
 
-// List returns all access tokens belongs to given user.
+// List returns all personal access tokens belongs to given user.
 func (s *AccessTokensStore) List(ctx context.Context, userID int64) ([]*AccessToken, error) {
 	var tokens []*AccessToken
-	return tokens, s.db.WithContext(ctx).Where("uid = ?", userID).Order("id ASC").Find(&tokens).Error
+	return tokens, s.db.WithContext(ctx).Where("uid = ? AND grant_id = 0", userID).Order("id ASC").Find(&tokens).Error
 }
 
 // Touch updates the updated time of the given access token to the current time.
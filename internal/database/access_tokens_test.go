@@ -190,6 +190,12 @@ func accessTokensGetBySHA(t *testing.T, ctx context.Context, s *AccessTokensStor
 		},
 	}
 	assert.Equal(t, wantErr, err)
+
+	// Expired token should not be returned
+	err = s.db.Model(new(AccessToken)).Where("id = ?", token.ID).UpdateColumn("expires_unix", s.db.NowFunc().Add(-time.Minute).Unix()).Error
+	require.NoError(t, err)
+	_, err = s.GetBySHA1(ctx, token.Sha1)
+	assert.True(t, IsErrAccessTokenNotExist(err))
 }
 
 func accessTokensList(t *testing.T, ctx context.Context, s *AccessTokensStore) {
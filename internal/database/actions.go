@@ -16,6 +16,7 @@ import (
 	log "unknwon.dev/clog/v2"
 
 	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/gitx"
 	"gogs.io/gogs/internal/lazyregexp"
 	"gogs.io/gogs/internal/repox"
 	apiv1types "gogs.io/gogs/internal/route/api/v1/types"
@@ -398,6 +399,10 @@ func updateCommitReferencesToIssues(doer *User, repo *Repository, commits []*Pus
 			}
 
 			if err = issue.ChangeStatus(doer, repo, true); err != nil {
+				// Issues blocked by open issues cannot be closed by commits.
+				if IsErrIssueHasOpenDependencies(err) {
+					continue
+				}
 				return err
 			}
 		}
@@ -870,6 +875,20 @@ func (pcs *PushCommits) APIFormat(ctx context.Context, usersStore *UsersStore, r
 		return user.Name, nil
 	}
 
+	var sigs map[string]*gitx.ObjectSignature
+	if !testx.InTest {
+		commitIDs := make([]string, len(pcs.Commits))
+		for i, commit := range pcs.Commits {
+			commitIDs[i] = commit.Sha1
+		}
+
+		var err error
+		sigs, err = gitx.CommitSignatures(repoPath, commitIDs, time.Duration(conf.Git.Timeout.Diff)*time.Second)
+		if err != nil {
+			return nil, errors.Wrap(err, "get commit signatures")
+		}
+	}
+
 	commits := make([]*apiv1types.WebhookPayloadCommit, len(pcs.Commits))
 	for i, commit := range pcs.Commits {
 		authorUsername, err := getUsernameByEmail(commit.AuthorEmail)
@@ -883,11 +902,14 @@ func (pcs *PushCommits) APIFormat(ctx context.Context, usersStore *UsersStore, r
 		}
 
 		nameStatus := &git.NameStatus{}
+		var verification *apiv1types.CommitVerification
 		if !testx.InTest {
 			nameStatus, err = git.ShowNameStatus(repoPath, commit.Sha1)
 			if err != nil {
 				return nil, errors.Wrapf(err, "show name status [commit_sha1: %s]", commit.Sha1)
 			}
+
+			verification = VerifyObjectSignature(ctx, sigs[commit.Sha1], commit.CommitterEmail).APIFormat()
 		}
 
 		commits[i] = &apiv1types.WebhookPayloadCommit{
@@ -908,6 +930,8 @@ func (pcs *PushCommits) APIFormat(ctx context.Context, usersStore *UsersStore, r
 			Removed:   nameStatus.Removed,
 			Modified:  nameStatus.Modified,
 			Timestamp: commit.Timestamp,
+
+			Verification: verification,
 		}
 	}
 	return commits, nil
@@ -27,7 +27,7 @@ func TestDumpAndImport(t *testing.T) {
 	}
 	t.Parallel()
 
-	const wantTables = 8
+	const wantTables = 14
 	if len(Tables) != wantTables {
 		t.Fatalf("New table has added (want %d got %d), please add new tests for the table and update this check", wantTables, len(Tables))
 	}
@@ -85,6 +85,15 @@ func setupDBToDump(t *testing.T, db *gorm.DB) {
 			SHA256:      cryptox.SHA256(cryptox.SHA1("1b2dccd1-a262-470f-bb8c-7fc73192e9bb")),
 			CreatedUnix: 1588568886,
 		},
+		&AccessToken{
+			UserID:      2,
+			Name:        "My App",
+			Sha1:        cryptox.SHA256(cryptox.SHA1("5f3a0d1e-8f3b-4b1e-9d2c-3f1b2a4c5d6e"))[:40],
+			SHA256:      cryptox.SHA256(cryptox.SHA1("5f3a0d1e-8f3b-4b1e-9d2c-3f1b2a4c5d6e")),
+			GrantID:     1,
+			ExpiresUnix: 1588572486,
+			CreatedUnix: 1588568886,
+		},
 
 		&Action{
 			ID:           1,
@@ -152,6 +161,28 @@ func setupDBToDump(t *testing.T, db *gorm.DB) {
 			FollowID: 1,
 		},
 
+		&GPGKey{
+			ID:          1,
+			OwnerID:     1,
+			KeyID:       "3AA5C34371567BD2",
+			Fingerprint: "4AEE18F83AFDEB23C6F6F2C33AA5C34371567BD2",
+			Emails:      "alice@example.com",
+			Content:     "-----BEGIN PGP PUBLIC KEY BLOCK-----",
+			CanSign:     true,
+			CreatedUnix: 1588568886,
+		},
+		&GPGKey{
+			ID:          2,
+			OwnerID:     2,
+			KeyID:       "4BB6D45482678BE3",
+			Fingerprint: "5BFF29094BFEEC34D7F7F3D44BB6D45482678BE3",
+			Emails:      "bob@example.com,bob@example.org",
+			Content:     "-----BEGIN PGP PUBLIC KEY BLOCK-----",
+			CanSign:     true,
+			CreatedUnix: 1588568886,
+			ExpiredUnix: 1620104886,
+		},
+
 		&LFSObject{
 			RepoID:    1,
 			OID:       "ef797c8118f02dfb649607dd5d3f8c7623048c9c063d532cc95c5ed7a898a64f",
@@ -193,6 +224,51 @@ func setupDBToDump(t *testing.T, db *gorm.DB) {
 			Description: "This is a notice",
 			CreatedUnix: 1588568886,
 		},
+
+		&OAuth2Application{
+			ID:                 1,
+			UserID:             1,
+			Name:               "My App",
+			ClientID:           "0a1b2c3d-4e5f-6789-abcd-ef0123456789",
+			ClientSecretSHA256: cryptox.SHA256("client-secret"),
+			RedirectURIs:       "https://example.com/callback\nhttp://localhost:8080/callback",
+			CreatedUnix:        1588568886,
+			UpdatedUnix:        1588572486,
+		},
+
+		&OAuth2AuthorizationCode{
+			ID:                  1,
+			GrantID:             1,
+			CodeSHA256:          cryptox.SHA256("authorization-code"),
+			RedirectURI:         "https://example.com/callback",
+			CodeChallenge:       "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM",
+			CodeChallengeMethod: OAuth2CodeChallengeS256,
+			ExpiresUnix:         1588569486,
+		},
+
+		&OAuth2Grant{
+			ID:            1,
+			UserID:        2,
+			ApplicationID: 1,
+			CreatedUnix:   1588568886,
+			UpdatedUnix:   1588568886,
+		},
+
+		&OAuth2RefreshToken{
+			ID:            1,
+			GrantID:       1,
+			AccessTokenID: 5,
+			SHA256:        cryptox.SHA256("refresh-token"),
+			ExpiresUnix:   1591160886,
+			CreatedUnix:   1588568886,
+		},
+
+		&UserBlock{
+			ID:          1,
+			UserID:      1,
+			BlockID:     2,
+			CreatedUnix: 1588568886,
+		},
 	}
 	for _, val := range vals {
 		err := db.Create(val).Error
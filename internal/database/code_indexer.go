@@ -0,0 +1,248 @@
+package database
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gogs/git-module"
+	log "unknwon.dev/clog/v2"
+	"xorm.io/builder"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/gitx"
+	"gogs.io/gogs/internal/indexer"
+	"gogs.io/gogs/internal/linguist"
+	"gogs.io/gogs/internal/sync"
+	"gogs.io/gogs/internal/tool"
+)
+
+// CodeIndexerQueue is the queue of repositories whose default branches need to
+// be (re)indexed.
+var CodeIndexerQueue = sync.NewUniqueQueue(1000)
+
+// codeIndexer is nil when code search is disabled.
+var codeIndexer indexer.CodeIndexer
+
+// IsCodeIndexerEnabled returns true if code search is enabled.
+func IsCodeIndexerEnabled() bool {
+	return codeIndexer != nil
+}
+
+// UpdateCodeIndexer queues the repository to be indexed with the latest content
+// of its default branch.
+func UpdateCodeIndexer(repoID int64) {
+	if codeIndexer == nil {
+		return
+	}
+	go CodeIndexerQueue.Add(repoID)
+}
+
+// deleteRepoFromCodeIndexer removes the repository from the index.
+func deleteRepoFromCodeIndexer(repoID int64) {
+	if codeIndexer == nil {
+		return
+	}
+	if err := codeIndexer.Delete(repoID); err != nil {
+		log.Error("Failed to delete repository [%d] from code indexer: %v", repoID, err)
+	}
+}
+
+// indexRepositoryCode indexes text files of the default branch of the
+// repository, or deletes the repository from the index if it no longer exists
+// or has nothing to index. Nothing is done if the indexed commit is already the
+// latest.
+func indexRepositoryCode(repoID int64) error {
+	repo, err := getRepositoryByID(x, repoID)
+	if err != nil {
+		if IsErrRepoNotExist(err) {
+			return codeIndexer.Delete(repoID)
+		}
+		return errors.Newf("get repository by ID: %v", err)
+	} else if repo.IsBare {
+		return codeIndexer.Delete(repoID)
+	}
+
+	repoPath := repo.RepoPath()
+	commitID, err := git.ShowRefVerify(repoPath, git.RefsHeads+repo.DefaultBranch)
+	if err != nil {
+		if err == git.ErrReferenceNotExist {
+			return codeIndexer.Delete(repoID)
+		}
+		return errors.Newf("get commit ID of default branch: %v", err)
+	} else if commitID == codeIndexer.IndexedCommitID(repoID) {
+		return nil
+	}
+
+	// Reading all files of a tree is as expensive as cloning the repository.
+	timeout := time.Duration(conf.Git.Timeout.Clone) * time.Second
+	files, err := gitx.ListFiles(repoPath, commitID, timeout)
+	if err != nil {
+		return errors.Newf("list files: %v", err)
+	}
+
+	// The same blob can be referenced by multiple paths.
+	blobPaths := make(map[string][]string, len(files))
+	blobIDs := make([]string, 0, len(files))
+	for _, f := range files {
+		if f.Size > conf.Indexer.CodeMaxFileSize {
+			continue
+		}
+		if _, ok := blobPaths[f.ID]; !ok {
+			blobIDs = append(blobIDs, f.ID)
+		}
+		blobPaths[f.ID] = append(blobPaths[f.ID], f.Path)
+	}
+
+	docs := make([]*indexer.CodeDocument, 0, len(blobIDs))
+	err = gitx.ReadBlobs(repoPath, blobIDs, timeout, func(id string, content []byte) error {
+		if !tool.IsTextFile(content) {
+			return nil
+		}
+
+		for _, p := range blobPaths[id] {
+			docs = append(docs, &indexer.CodeDocument{
+				Path:     p,
+				Language: linguist.DetectLanguage(p),
+				Content:  string(content),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Newf("read blobs: %v", err)
+	}
+	return codeIndexer.Index(repoID, commitID, docs)
+}
+
+func processCodeIndexerQueue() {
+	for repoID := range CodeIndexerQueue.Queue() {
+		CodeIndexerQueue.Remove(repoID)
+
+		id, _ := strconv.ParseInt(repoID, 10, 64)
+		if err := indexRepositoryCode(id); err != nil {
+			log.Error("Failed to index code of repository [%d]: %v", id, err)
+		}
+	}
+}
+
+// queueAllRepositoriesForCodeIndexer queues all repositories that may need to
+// be (re)indexed, including indexed ones that may have been deleted.
+func queueAllRepositoriesForCodeIndexer() {
+	for _, id := range codeIndexer.RepoIDs() {
+		CodeIndexerQueue.Add(id)
+	}
+
+	var lastID int64
+	for {
+		repos := make([]*Repository, 0, 100)
+		if err := x.Cols("id").Where("id > ? AND is_bare = ?", lastID, false).Asc("id").Limit(100).Find(&repos); err != nil {
+			log.Error("Failed to find repositories to populate code indexer: %v", err)
+			return
+		} else if len(repos) == 0 {
+			break
+		}
+
+		for _, repo := range repos {
+			CodeIndexerQueue.Add(repo.ID)
+		}
+		lastID = repos[len(repos)-1].ID
+	}
+}
+
+// InitCodeIndexer opens the code indexer and starts processing the queue, all
+// repositories are checked for changes made while the server was down.
+func InitCodeIndexer() {
+	if conf.Indexer.CodeType == "" {
+		return
+	}
+
+	var err error
+	codeIndexer, err = indexer.NewCodeIndexer(conf.Indexer.CodeType, conf.Indexer.CodePath)
+	if err != nil {
+		log.Fatal("Failed to open code indexer: %v", err)
+	}
+
+	go processCodeIndexerQueue()
+	go queueAllRepositoriesForCodeIndexer()
+}
+
+// CodeMatch is a matched file in a repository.
+type CodeMatch struct {
+	Repo *Repository
+	*indexer.CodeMatch
+}
+
+// SearchCode returns a page of files that match the code search options, and
+// the number of all matched files. Only repositories that the doer has read
+// access to are searched. When no repository is given, unlisted repositories
+// are excluded like they are in exploring.
+func SearchCode(ctx context.Context, doerID int64, opts indexer.CodeSearchOptions) ([]*CodeMatch, int, error) {
+	if codeIndexer == nil {
+		return []*CodeMatch{}, 0, nil
+	}
+
+	isExplore := len(opts.RepoIDs) == 0
+	repoIDs := opts.RepoIDs
+	if isExplore {
+		repoIDs = codeIndexer.RepoIDs()
+	}
+	if len(repoIDs) == 0 {
+		return []*CodeMatch{}, 0, nil
+	}
+
+	cond := builder.NewCond()
+	if isExplore {
+		cond = builder.Eq{"is_unlisted": false}
+	}
+	repos := make([]*Repository, 0, len(repoIDs))
+	for start := 0; start < len(repoIDs); start += 500 {
+		end := min(start+500, len(repoIDs))
+		if err := x.Context(ctx).Where(cond).In("id", repoIDs[start:end]).Find(&repos); err != nil {
+			return nil, 0, errors.Newf("find repositories: %v", err)
+		}
+	}
+
+	reposByID := make(map[int64]*Repository, len(repos))
+	accessOpts := make(map[int64]AccessModeOptions, len(repos))
+	for _, repo := range repos {
+		reposByID[repo.ID] = repo
+		accessOpts[repo.ID] = AccessModeOptions{
+			OwnerID: repo.OwnerID,
+			Private: repo.IsPrivate,
+		}
+	}
+	var err error
+	opts.RepoIDs, err = Handle.Permissions().AuthorizedRepoIDs(ctx, doerID, AccessModeRead, accessOpts)
+	if err != nil {
+		return nil, 0, errors.Newf("get authorized repository IDs: %v", err)
+	}
+	if len(opts.RepoIDs) == 0 {
+		return []*CodeMatch{}, 0, nil
+	}
+
+	result, err := codeIndexer.Search(opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	matchedRepos := make(RepositoryList, 0, len(result.Matches))
+	seen := make(map[int64]bool)
+	matches := make([]*CodeMatch, len(result.Matches))
+	for i := range result.Matches {
+		repo := reposByID[result.Matches[i].RepoID]
+		if !seen[repo.ID] {
+			matchedRepos = append(matchedRepos, repo)
+			seen[repo.ID] = true
+		}
+		matches[i] = &CodeMatch{
+			Repo:      repo,
+			CodeMatch: result.Matches[i],
+		}
+	}
+	if err = matchedRepos.LoadAttributes(); err != nil {
+		return nil, 0, errors.Newf("load attributes: %v", err)
+	}
+	return matches, result.Total, nil
+}
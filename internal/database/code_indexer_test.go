@@ -0,0 +1,78 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/indexer"
+)
+
+func TestSearchCode(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	newLegacyTestDB(t, "TestSearchCode")
+
+	var err error
+	codeIndexer, err = indexer.NewCodeIndexer(indexer.TypeBuiltin, t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = codeIndexer.Close()
+		codeIndexer = nil
+	})
+
+	alice := createLegacyTestUser(t, "alice")
+	bob := createLegacyTestUser(t, "bob")
+	cindy := createLegacyTestUser(t, "cindy")
+	public := createLegacyTestRepo(t, alice, "public", false)
+	unlisted := createLegacyTestRepo(t, alice, "unlisted", false)
+	shared := createLegacyTestRepo(t, alice, "shared", true)
+	private := createLegacyTestRepo(t, alice, "private", true)
+
+	unlisted.IsUnlisted = true
+	_, err = x.ID(unlisted.ID).Cols("is_unlisted").Update(unlisted)
+	require.NoError(t, err)
+	err = Handle.Permissions().SetRepoPerms(t.Context(), shared.ID, map[int64]AccessMode{bob.ID: AccessModeRead})
+	require.NoError(t, err)
+
+	for _, repo := range []*Repository{public, unlisted, shared, private} {
+		docs := []*indexer.CodeDocument{{Path: "main.go", Language: "Go", Content: "func main() {}"}}
+		require.NoError(t, codeIndexer.Index(repo.ID, "abc", docs))
+	}
+
+	search := func(t *testing.T, doerID int64, repoIDs ...int64) []string {
+		t.Helper()
+
+		matches, total, err := SearchCode(t.Context(), doerID, indexer.CodeSearchOptions{Keyword: "main", RepoIDs: repoIDs, PageSize: 10})
+		require.NoError(t, err)
+		assert.Equal(t, len(matches), total)
+		names := make([]string, 0, len(matches))
+		for _, m := range matches {
+			names = append(names, m.Repo.Name)
+		}
+		return names
+	}
+
+	tests := []struct {
+		name    string
+		doerID  int64
+		repoIDs []int64
+		want    []string
+	}{
+		{name: "anonymous", want: []string{"public"}},
+		{name: "outsider", doerID: cindy.ID, want: []string{"public"}},
+		{name: "collaborator", doerID: bob.ID, want: []string{"public", "shared"}},
+		{name: "owner", doerID: alice.ID, want: []string{"public", "shared", "private"}},
+		{name: "anonymous in unlisted repository", repoIDs: []int64{unlisted.ID}, want: []string{"unlisted"}},
+		{name: "anonymous in private repository", repoIDs: []int64{shared.ID, private.ID}, want: []string{}},
+		{name: "collaborator in private repositories", doerID: bob.ID, repoIDs: []int64{shared.ID, private.ID}, want: []string{"shared"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.ElementsMatch(t, test.want, search(t, test.doerID, test.repoIDs...))
+		})
+	}
+}
@@ -33,6 +33,23 @@ const (
 	CommentTypeCommentRef
 	// Reference from a pull request
 	CommentTypePullRef
+
+	// Auto-merge of a pull request.
+	CommentTypeAutoMergeSchedule
+	CommentTypeAutoMergeCancel
+	CommentTypeAutoMergeSuccess
+	CommentTypeAutoMergeFail
+
+	// Locking of the conversation.
+	CommentTypeLock
+	CommentTypeUnlock
+
+	// Transfer from another repository.
+	CommentTypeTransfer
+
+	// Sign-off of a code owner on a pull request.
+	CommentTypeCodeOwnerSignOff
+	CommentTypeCodeOwnerSignOffWithdraw
 )
 
 type CommentTag int
@@ -66,6 +83,7 @@ type Comment struct {
 	CommitSHA string `xorm:"VARCHAR(40)"`
 
 	Attachments []*Attachment `xorm:"-" json:"-" gorm:"-"`
+	Reactions   ReactionList  `xorm:"-" json:"-" gorm:"-"`
 
 	// For view issue page.
 	ShowTag CommentTag `xorm:"-" json:"-" gorm:"-"`
@@ -164,7 +182,7 @@ func (c *Comment) EventTag() string {
 // and mentioned people.
 func (c *Comment) mailParticipants(e Engine, opType ActionType, issue *Issue) (err error) {
 	mentions := markup.FindAllMentions(c.Content)
-	if err = updateIssueMentions(e, c.IssueID, mentions); err != nil {
+	if err = updateIssueMentions(e, c.PosterID, c.IssueID, mentions); err != nil {
 		return errors.Newf("UpdateIssueMentions [%d]: %v", c.IssueID, err)
 	}
 
@@ -331,8 +349,14 @@ func CreateComment(opts *CreateCommentOptions) (comment *Comment, err error) {
 	return comment, sess.Commit()
 }
 
-// CreateIssueComment creates a plain issue comment.
+// CreateIssueComment creates a plain issue comment. It returns ErrBlockedByUser
+// if the owner of the repository has blocked the doer.
 func CreateIssueComment(doer *User, repo *Repository, issue *Issue, content string, attachments []string) (*Comment, error) {
+	err := Handle.UserBlocks().EnsureNotBlocked(context.TODO(), repo.OwnerID, doer.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	comment, err := CreateComment(&CreateCommentOptions{
 		Type:        CommentTypeComment,
 		Doer:        doer,
@@ -344,6 +368,7 @@ func CreateIssueComment(doer *User, repo *Repository, issue *Issue, content stri
 	if err != nil {
 		return nil, errors.Newf("CreateComment: %v", err)
 	}
+	updateIssueIndexer(issue.ID)
 
 	comment.Issue = issue
 	if err = PrepareWebhooks(repo, HookEventTypeIssueComment, &apiv1types.WebhookIssueCommentPayload{
@@ -479,6 +504,9 @@ func UpdateComment(doer *User, c *Comment, oldContent string) (err error) {
 	if _, err = x.Id(c.ID).AllCols().Update(c); err != nil {
 		return err
 	}
+	if c.Type == CommentTypeComment {
+		updateIssueIndexer(c.IssueID)
+	}
 
 	if err = c.Issue.LoadAttributes(); err != nil {
 		log.Error("Issue.LoadAttributes [issue_id: %d]: %v", c.IssueID, err)
@@ -526,9 +554,16 @@ func DeleteCommentByID(doer *User, id int64) error {
 		}
 	}
 
+	if _, err = sess.Delete(&Reaction{CommentID: comment.ID}); err != nil {
+		return err
+	}
+
 	if err = sess.Commit(); err != nil {
 		return errors.Newf("commit: %v", err)
 	}
+	if comment.Type == CommentTypeComment {
+		updateIssueIndexer(comment.IssueID)
+	}
 
 	_, err = DeleteAttachmentsByComment(comment.ID, true)
 	if err != nil {
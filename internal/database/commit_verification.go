@@ -0,0 +1,245 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	pgperrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+	"github.com/cockroachdb/errors"
+	"github.com/gogs/git-module"
+	"golang.org/x/crypto/ssh"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/cryptox"
+	"gogs.io/gogs/internal/gitx"
+	apiv1types "gogs.io/gogs/internal/route/api/v1/types"
+)
+
+// The reasons of a CommitVerification.
+const (
+	VerificationReasonValid           = "valid"
+	VerificationReasonUnsigned        = "unsigned"
+	VerificationReasonUnknownType     = "unknown_signature_type"
+	VerificationReasonNoUser          = "no_user"
+	VerificationReasonUnknownKey      = "unknown_key"
+	VerificationReasonBadSignature    = "bad_signature"
+	VerificationReasonUnverifiedEmail = "unverified_email"
+	VerificationReasonInternalError   = "internal_error"
+)
+
+// sshSignatureNamespace is the namespace used by Git for SSH signatures.
+const sshSignatureNamespace = "git"
+
+// CommitVerification is the result of verifying the signature of a commit or a
+// tag.
+type CommitVerification struct {
+	// Whether the signature is made by a key of the user that the committer (or
+	// tagger) email belongs to.
+	Verified bool
+	// One of the VerificationReason* constants.
+	Reason string
+	// The type of the signature, i.e. gitx.SignatureTypeGPG or
+	// gitx.SignatureTypeSSH.
+	SignatureType string
+	// The ID of the GPG key or the fingerprint of the SSH key that made the
+	// signature, when known.
+	SigningKey string
+	// The user that the signing key belongs to, only set when verified.
+	Signer *User
+
+	Signature string
+	Payload   string
+}
+
+// APIFormat returns the API format of the verification.
+func (v *CommitVerification) APIFormat() *apiv1types.CommitVerification {
+	return &apiv1types.CommitVerification{
+		Verified:   v.Verified,
+		Reason:     v.Reason,
+		Signature:  v.Signature,
+		Payload:    v.Payload,
+		SigningKey: v.SigningKey,
+	}
+}
+
+// VerifyObjectSignature verifies the signature of a commit or a tag against the
+// GPG and SSH keys of the user that owns the given email as a verified email
+// address. The signature may be nil for unsigned objects.
+func VerifyObjectSignature(ctx context.Context, sig *gitx.ObjectSignature, email string) *CommitVerification {
+	if sig == nil {
+		return &CommitVerification{Reason: VerificationReasonUnsigned}
+	}
+
+	v := &CommitVerification{
+		SignatureType: sig.Type(),
+		Signature:     sig.Signature,
+		Payload:       sig.Payload,
+	}
+	if v.SignatureType == gitx.SignatureTypeGPG {
+		v.SigningKey = gpgSignatureIssuer(sig.Signature)
+	}
+
+	// NOTE: Only users with the email verified can be found.
+	user, err := Handle.Users().GetByEmail(ctx, email)
+	if err != nil {
+		if IsErrUserNotExist(err) {
+			v.Reason = VerificationReasonNoUser
+			return v
+		}
+		log.Error("Failed to get user by email %q: %v", email, err)
+		v.Reason = VerificationReasonInternalError
+		return v
+	}
+
+	switch v.SignatureType {
+	case gitx.SignatureTypeGPG:
+		verifyGPGSignature(ctx, v, user, email)
+	case gitx.SignatureTypeSSH:
+		verifySSHSignature(v, user)
+	default:
+		v.Reason = VerificationReasonUnknownType
+	}
+	if v.Verified {
+		v.Reason = VerificationReasonValid
+		v.Signer = user
+	}
+	return v
+}
+
+func verifyGPGSignature(ctx context.Context, v *CommitVerification, user *User, email string) {
+	keyring, err := Handle.GPGKeys().keyring(ctx, user.ID)
+	if err != nil {
+		log.Error("Failed to get GPG key ring of user %d: %v", user.ID, err)
+		v.Reason = VerificationReasonInternalError
+		return
+	}
+
+	entity, err := openpgp.CheckArmoredDetachedSignature(keyring, strings.NewReader(v.Payload), strings.NewReader(v.Signature), nil)
+	if err != nil {
+		if errors.Is(err, pgperrors.ErrUnknownIssuer) {
+			v.Reason = VerificationReasonUnknownKey
+		} else {
+			v.Reason = VerificationReasonBadSignature
+		}
+		return
+	}
+
+	// The email must be one of the identities of the signing key, so that a key
+	// does not vouch for email addresses it has never claimed.
+	if !gpgEntityHasEmail(entity, email) {
+		v.Reason = VerificationReasonUnverifiedEmail
+		return
+	}
+	v.Verified = true
+}
+
+func verifySSHSignature(v *CommitVerification, user *User) {
+	publicKey, err := cryptox.VerifySSHSignature(v.Signature, []byte(v.Payload), sshSignatureNamespace)
+	if err != nil {
+		v.Reason = VerificationReasonBadSignature
+		return
+	}
+	v.SigningKey = ssh.FingerprintSHA256(publicKey)
+
+	key, err := SearchPublicKeyByContent(strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey))))
+	if err != nil {
+		if IsErrKeyNotExist(err) {
+			v.Reason = VerificationReasonUnknownKey
+			return
+		}
+		log.Error("Failed to search public key by content: %v", err)
+		v.Reason = VerificationReasonInternalError
+		return
+	}
+
+	if key.IsDeployKey() || key.OwnerID != user.ID {
+		v.Reason = VerificationReasonUnknownKey
+		return
+	}
+	v.Verified = true
+}
+
+// VerifyCommit verifies the signature of the commit in the repository against
+// the committer email.
+func VerifyCommit(ctx context.Context, repoPath string, commit *git.Commit) *CommitVerification {
+	sig, err := gitx.CommitSignature(repoPath, commit.ID.String())
+	if err != nil {
+		log.Error("Failed to get signature of commit %q in %q: %v", commit.ID, repoPath, err)
+		return &CommitVerification{Reason: VerificationReasonInternalError}
+	}
+	return VerifyObjectSignature(ctx, sig, commit.Committer.Email)
+}
+
+// VerifyTag verifies the signature of the annotated tag in the repository
+// against the tagger email.
+func VerifyTag(ctx context.Context, gitRepo *git.Repository, tagName string) *CommitVerification {
+	sig, err := gitx.TagSignature(gitRepo.Path(), tagName)
+	if err != nil {
+		log.Error("Failed to get signature of tag %q in %q: %v", tagName, gitRepo.Path(), err)
+		return &CommitVerification{Reason: VerificationReasonInternalError}
+	} else if sig == nil {
+		return &CommitVerification{Reason: VerificationReasonUnsigned}
+	}
+
+	tag, err := gitRepo.Tag(tagName)
+	if err != nil {
+		log.Error("Failed to get tag %q in %q: %v", tagName, gitRepo.Path(), err)
+		return &CommitVerification{Reason: VerificationReasonInternalError}
+	} else if tag.Tagger() == nil {
+		return &CommitVerification{Reason: VerificationReasonNoUser}
+	}
+	return VerifyObjectSignature(ctx, sig, tag.Tagger().Email)
+}
+
+// VerifyCommits verifies signatures of the list of commits in the repository,
+// and returns results keyed by commit IDs. Signatures of all commits are read
+// with a single Git process.
+func VerifyCommits(ctx context.Context, repoPath string, commits []*git.Commit) map[string]*CommitVerification {
+	verifications := make(map[string]*CommitVerification, len(commits))
+	commitIDs := make([]string, len(commits))
+	for i, commit := range commits {
+		commitIDs[i] = commit.ID.String()
+	}
+
+	sigs, err := gitx.CommitSignatures(repoPath, commitIDs, time.Duration(conf.Git.Timeout.Diff)*time.Second)
+	if err != nil {
+		log.Error("Failed to get signatures of commits in %q: %v", repoPath, err)
+		for _, commitID := range commitIDs {
+			verifications[commitID] = &CommitVerification{Reason: VerificationReasonInternalError}
+		}
+		return verifications
+	}
+
+	for i, commit := range commits {
+		verifications[commitIDs[i]] = VerifyObjectSignature(ctx, sigs[commitIDs[i]], commit.Committer.Email)
+	}
+	return verifications
+}
+
+// FindUnverifiedCommit lists commits of given refspecs in the repository and
+// returns the first one that does not have a verified signature along with its
+// verification, or nil if all of them are verified.
+func FindUnverifiedCommit(ctx context.Context, repoPath string, refspecs []string, opts ...git.RevListOptions) (*git.Commit, *CommitVerification, error) {
+	gitRepo, err := git.Open(repoPath)
+	if err != nil {
+		return nil, nil, errors.Newf("open repository: %v", err)
+	}
+	commits, err := gitRepo.RevList(refspecs, opts...)
+	if err != nil {
+		return nil, nil, errors.Newf("list commits: %v", err)
+	}
+
+	verifications := VerifyCommits(ctx, repoPath, commits)
+	for _, commit := range commits {
+		v := verifications[commit.ID.String()]
+		if v.Reason == VerificationReasonInternalError {
+			return nil, nil, errors.Newf("verify signature of commit %q", commit.ID)
+		} else if !v.Verified {
+			return commit, v, nil
+		}
+	}
+	return nil, nil, nil
+}
@@ -42,8 +42,11 @@ var Tables = []any{
 	new(Access), new(AccessToken), new(Action),
 	new(EmailAddress),
 	new(Follow),
+	new(GPGKey),
 	new(LFSObject), new(LoginSource),
 	new(Notice),
+	new(OAuth2Application), new(OAuth2AuthorizationCode), new(OAuth2Grant), new(OAuth2RefreshToken),
+	new(UserBlock),
 }
 
 // NewConnection returns a new database connection with the given logger.
@@ -146,6 +149,10 @@ func (db *DB) Actions() *ActionsStore {
 	return newActionsStore(db.db)
 }
 
+func (db *DB) GPGKeys() *GPGKeysStore {
+	return newGPGKeysStore(db.db)
+}
+
 func (db *DB) LFS() *LFSStore {
 	return newLFSStore(db.db)
 }
@@ -162,6 +169,10 @@ func (db *DB) Notices() *NoticesStore {
 	return newNoticesStore(db.db)
 }
 
+func (db *DB) OAuth2() *OAuth2Store {
+	return newOAuth2Store(db.db)
+}
+
 func (db *DB) Organizations() *OrganizationsStore {
 	return newOrganizationsStoreStore(db.db)
 }
@@ -182,6 +193,10 @@ func (db *DB) TwoFactors() *TwoFactorsStore {
 	return newTwoFactorsStore(db.db)
 }
 
+func (db *DB) UserBlocks() *UserBlocksStore {
+	return newUserBlocksStore(db.db)
+}
+
 func (db *DB) Users() *UsersStore {
 	return newUsersStore(db.db)
 }
@@ -0,0 +1,290 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/cockroachdb/errors"
+	"gorm.io/gorm"
+
+	"gogs.io/gogs/internal/errx"
+)
+
+// GPGKey is a GPG public key of a user, which is used to verify signatures of
+// commits and tags.
+type GPGKey struct {
+	ID          int64  `gorm:"primaryKey"`
+	OwnerID     int64  `gorm:"index;not null"`
+	KeyID       string `gorm:"type:VARCHAR(16);not null"`
+	Fingerprint string `gorm:"type:VARCHAR(40);unique;not null"`
+	// The comma-separated list of email addresses of all identities of the key.
+	Emails  string `gorm:"type:TEXT;not null"`
+	Content string `gorm:"type:TEXT;not null"`
+	CanSign bool   `gorm:"not null"`
+
+	Created     time.Time `gorm:"-" json:"-"`
+	CreatedUnix int64
+	Expired     time.Time `gorm:"-" json:"-"`
+	ExpiredUnix int64
+}
+
+// BeforeCreate implements the GORM create hook.
+func (k *GPGKey) BeforeCreate(tx *gorm.DB) error {
+	if k.CreatedUnix == 0 {
+		k.CreatedUnix = tx.NowFunc().Unix()
+	}
+	return nil
+}
+
+// AfterFind implements the GORM query hook.
+func (k *GPGKey) AfterFind(_ *gorm.DB) error {
+	k.Created = time.Unix(k.CreatedUnix, 0).Local()
+	if k.ExpiredUnix > 0 {
+		k.Expired = time.Unix(k.ExpiredUnix, 0).Local()
+	}
+	return nil
+}
+
+// EmailList returns the list of email addresses of all identities of the key.
+func (k *GPGKey) EmailList() []string {
+	if k.Emails == "" {
+		return nil
+	}
+	return strings.Split(k.Emails, ",")
+}
+
+// HasExpired returns true if the key has an expiration time and has expired.
+func (k *GPGKey) HasExpired() bool {
+	return k.ExpiredUnix > 0 && time.Now().Unix() >= k.ExpiredUnix
+}
+
+// Entity returns the parsed OpenPGP entity of the key.
+func (k *GPGKey) Entity() (*openpgp.Entity, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(k.Content))
+	if err != nil {
+		return nil, errors.Wrap(err, "read armored key ring")
+	} else if len(entities) != 1 {
+		return nil, errors.Newf("expect exactly one key but got %d", len(entities))
+	}
+	return entities[0], nil
+}
+
+// GPGKeysStore is the storage layer for GPG keys.
+type GPGKeysStore struct {
+	db *gorm.DB
+}
+
+func newGPGKeysStore(db *gorm.DB) *GPGKeysStore {
+	return &GPGKeysStore{db: db}
+}
+
+type ErrGPGKeyInvalid struct {
+	args errx.Args
+}
+
+// IsErrGPGKeyInvalid returns true if the underlying error has the type
+// ErrGPGKeyInvalid.
+func IsErrGPGKeyInvalid(err error) bool {
+	return errors.As(err, &ErrGPGKeyInvalid{})
+}
+
+func (err ErrGPGKeyInvalid) Error() string {
+	return fmt.Sprintf("GPG key is invalid: %v", err.args)
+}
+
+type ErrGPGKeyAlreadyExist struct {
+	args errx.Args
+}
+
+// IsErrGPGKeyAlreadyExist returns true if the underlying error has the type
+// ErrGPGKeyAlreadyExist.
+func IsErrGPGKeyAlreadyExist(err error) bool {
+	return errors.As(err, &ErrGPGKeyAlreadyExist{})
+}
+
+func (err ErrGPGKeyAlreadyExist) Error() string {
+	return fmt.Sprintf("GPG key already exists: %v", err.args)
+}
+
+// parseGPGKey parses the armored public key and returns a GPGKey with metadata
+// of the key filled.
+func parseGPGKey(ownerID int64, content string) (*GPGKey, error) {
+	content = strings.TrimSpace(content)
+	key := &GPGKey{
+		OwnerID: ownerID,
+		Content: content,
+	}
+	entity, err := key.Entity()
+	if err != nil {
+		return nil, ErrGPGKeyInvalid{args: errx.Args{"reason": err.Error()}}
+	} else if entity.PrivateKey != nil {
+		return nil, ErrGPGKeyInvalid{args: errx.Args{"reason": "private key is not allowed"}}
+	}
+
+	key.KeyID = fmt.Sprintf("%016X", entity.PrimaryKey.KeyId)
+	key.Fingerprint = fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint)
+
+	emails := make([]string, 0, len(entity.Identities))
+	for _, identity := range entity.Identities {
+		if identity.UserId == nil || identity.UserId.Email == "" {
+			continue
+		}
+		emails = append(emails, strings.ToLower(identity.UserId.Email))
+
+		sig := identity.SelfSignature
+		if sig != nil && sig.KeyLifetimeSecs != nil && *sig.KeyLifetimeSecs > 0 {
+			key.ExpiredUnix = entity.PrimaryKey.CreationTime.Add(time.Duration(*sig.KeyLifetimeSecs) * time.Second).Unix()
+		}
+		if sig != nil && sig.FlagsValid && sig.FlagSign {
+			key.CanSign = true
+		}
+	}
+	key.Emails = strings.Join(emails, ",")
+
+	for _, subkey := range entity.Subkeys {
+		if subkey.Sig != nil && subkey.Sig.FlagsValid && subkey.Sig.FlagSign {
+			key.CanSign = true
+		}
+	}
+	if !key.CanSign && entity.PrimaryKey.PubKeyAlgo.CanSign() {
+		// Keys without key flags are allowed to sign by default.
+		key.CanSign = !hasGPGKeyFlags(entity)
+	}
+	return key, nil
+}
+
+func hasGPGKeyFlags(entity *openpgp.Entity) bool {
+	for _, identity := range entity.Identities {
+		if identity.SelfSignature != nil && identity.SelfSignature.FlagsValid {
+			return true
+		}
+	}
+	return false
+}
+
+// Create parses the armored public key and persists it for the user. It
+// returns ErrGPGKeyInvalid when the key cannot be parsed, and
+// ErrGPGKeyAlreadyExist when the key has already been added by any user.
+func (s *GPGKeysStore) Create(ctx context.Context, ownerID int64, content string) (*GPGKey, error) {
+	key, err := parseGPGKey(ownerID, content)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.WithContext(ctx).Where("fingerprint = ?", key.Fingerprint).First(new(GPGKey)).Error
+	if err == nil {
+		return nil, ErrGPGKeyAlreadyExist{args: errx.Args{"fingerprint": key.Fingerprint}}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	err = s.db.WithContext(ctx).Create(key).Error
+	if err != nil {
+		return nil, err
+	}
+	return key, s.db.WithContext(ctx).First(key, key.ID).Error
+}
+
+var _ errx.NotFound = (*ErrGPGKeyNotExist)(nil)
+
+type ErrGPGKeyNotExist struct {
+	args errx.Args
+}
+
+// IsErrGPGKeyNotExist returns true if the underlying error has the type
+// ErrGPGKeyNotExist.
+func IsErrGPGKeyNotExist(err error) bool {
+	return errors.As(err, &ErrGPGKeyNotExist{})
+}
+
+func (err ErrGPGKeyNotExist) Error() string {
+	return fmt.Sprintf("GPG key does not exist: %v", err.args)
+}
+
+func (ErrGPGKeyNotExist) NotFound() bool {
+	return true
+}
+
+// GetByID returns the GPG key with given ID of the user. It returns
+// ErrGPGKeyNotExist when not found.
+func (s *GPGKeysStore) GetByID(ctx context.Context, ownerID, id int64) (*GPGKey, error) {
+	key := new(GPGKey)
+	err := s.db.WithContext(ctx).Where("id = ? AND owner_id = ?", id, ownerID).First(key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGPGKeyNotExist{args: errx.Args{"ownerID": ownerID, "id": id}}
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+// List returns all GPG keys of the user.
+func (s *GPGKeysStore) List(ctx context.Context, ownerID int64) ([]*GPGKey, error) {
+	keys := make([]*GPGKey, 0, 5)
+	return keys, s.db.WithContext(ctx).Where("owner_id = ?", ownerID).Order("id ASC").Find(&keys).Error
+}
+
+// DeleteByID deletes the GPG key with given ID.
+//
+// 🚨 SECURITY: The "ownerID" is required to prevent attacker deletes arbitrary
+// GPG key that belongs to another user.
+func (s *GPGKeysStore) DeleteByID(ctx context.Context, ownerID, id int64) error {
+	return s.db.WithContext(ctx).Where("id = ? AND owner_id = ?", id, ownerID).Delete(new(GPGKey)).Error
+}
+
+// keyring returns the key ring made of all non-expired signing keys of the user.
+func (s *GPGKeysStore) keyring(ctx context.Context, ownerID int64) (openpgp.EntityList, error) {
+	keys, err := s.List(ctx, ownerID)
+	if err != nil {
+		return nil, errors.Wrap(err, "list keys")
+	}
+
+	keyring := make(openpgp.EntityList, 0, len(keys))
+	for _, key := range keys {
+		if !key.CanSign || key.HasExpired() {
+			continue
+		}
+
+		entity, err := key.Entity()
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse key %d", key.ID)
+		}
+		keyring = append(keyring, entity)
+	}
+	return keyring, nil
+}
+
+// gpgEntityHasEmail returns true if any identity of the entity has the email.
+func gpgEntityHasEmail(entity *openpgp.Entity, email string) bool {
+	for _, identity := range entity.Identities {
+		if identity.UserId != nil && strings.EqualFold(identity.UserId.Email, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// gpgSignatureIssuer returns the hexadecimal key ID that issued the armored
+// signature, or an empty string if unknown.
+func gpgSignatureIssuer(signature string) string {
+	block, err := armor.Decode(strings.NewReader(signature))
+	if err != nil {
+		return ""
+	}
+
+	p, err := packet.Read(block.Body)
+	if err != nil {
+		return ""
+	}
+	sig, ok := p.(*packet.Signature)
+	if !ok || sig.IssuerKeyId == nil {
+		return ""
+	}
+	return fmt.Sprintf("%016X", *sig.IssuerKeyId)
+}
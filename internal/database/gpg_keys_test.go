@@ -0,0 +1,176 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/errx"
+)
+
+// newTestGPGEntity generates a new GPG key with the email and returns the
+// entity and its armored public key.
+func newTestGPGEntity(t *testing.T, email string) (*openpgp.Entity, string) {
+	entity, err := openpgp.NewEntity("Alice", "", email, nil)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	err = entity.Serialize(w)
+	require.NoError(t, err)
+	err = w.Close()
+	require.NoError(t, err)
+	return entity, buf.String()
+}
+
+func TestGPGKeys(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+
+	ctx := context.Background()
+	s := &GPGKeysStore{
+		db: newTestDB(t, "GPGKeysStore"),
+	}
+
+	for _, tc := range []struct {
+		name string
+		test func(t *testing.T, ctx context.Context, s *GPGKeysStore)
+	}{
+		{"Create", gpgKeysCreate},
+		{"GetByID", gpgKeysGetByID},
+		{"List", gpgKeysList},
+		{"DeleteByID", gpgKeysDeleteByID},
+		{"Keyring", gpgKeysKeyring},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Cleanup(func() {
+				err := clearTables(t, s.db)
+				require.NoError(t, err)
+			})
+			tc.test(t, ctx, s)
+		})
+		if t.Failed() {
+			break
+		}
+	}
+}
+
+func gpgKeysCreate(t *testing.T, ctx context.Context, s *GPGKeysStore) {
+	entity, content := newTestGPGEntity(t, "Alice@example.com")
+
+	key, err := s.Create(ctx, 1, content)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), key.OwnerID)
+	assert.Len(t, key.KeyID, 16)
+	assert.Equal(t, strings.ToUpper(entity.PrimaryKey.KeyIdString()), key.KeyID)
+	assert.Equal(t, []string{"alice@example.com"}, key.EmailList())
+	assert.True(t, key.CanSign)
+	assert.False(t, key.HasExpired())
+	assert.Equal(t, s.db.NowFunc().Unix(), key.Created.Unix())
+
+	// Try to create the same key again
+	_, err = s.Create(ctx, 2, content)
+	wantErr := ErrGPGKeyAlreadyExist{args: errx.Args{"fingerprint": key.Fingerprint}}
+	assert.Equal(t, wantErr, err)
+
+	// Try to create an invalid key
+	_, err = s.Create(ctx, 1, "not a key")
+	assert.True(t, IsErrGPGKeyInvalid(err))
+
+	// Try to create a private key
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PrivateKeyType, nil)
+	require.NoError(t, err)
+	err = entity.SerializePrivate(w, nil)
+	require.NoError(t, err)
+	err = w.Close()
+	require.NoError(t, err)
+	_, err = s.Create(ctx, 1, buf.String())
+	assert.True(t, IsErrGPGKeyInvalid(err))
+}
+
+func gpgKeysGetByID(t *testing.T, ctx context.Context, s *GPGKeysStore) {
+	_, content := newTestGPGEntity(t, "alice@example.com")
+	key, err := s.Create(ctx, 1, content)
+	require.NoError(t, err)
+
+	got, err := s.GetByID(ctx, 1, key.ID)
+	require.NoError(t, err)
+	assert.Equal(t, key.Fingerprint, got.Fingerprint)
+
+	// Should not be able to get other user's key
+	_, err = s.GetByID(ctx, 2, key.ID)
+	wantErr := ErrGPGKeyNotExist{args: errx.Args{"ownerID": int64(2), "id": key.ID}}
+	assert.Equal(t, wantErr, err)
+}
+
+func gpgKeysList(t *testing.T, ctx context.Context, s *GPGKeysStore) {
+	_, content1 := newTestGPGEntity(t, "alice@example.com")
+	_, err := s.Create(ctx, 1, content1)
+	require.NoError(t, err)
+	_, content2 := newTestGPGEntity(t, "alice@example.com")
+	_, err = s.Create(ctx, 1, content2)
+	require.NoError(t, err)
+	_, content3 := newTestGPGEntity(t, "bob@example.com")
+	_, err = s.Create(ctx, 2, content3)
+	require.NoError(t, err)
+
+	keys, err := s.List(ctx, 1)
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+
+	keys, err = s.List(ctx, 3)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func gpgKeysDeleteByID(t *testing.T, ctx context.Context, s *GPGKeysStore) {
+	_, content := newTestGPGEntity(t, "alice@example.com")
+	key, err := s.Create(ctx, 1, content)
+	require.NoError(t, err)
+
+	// Deleting with a wrong owner should be noop
+	err = s.DeleteByID(ctx, 2, key.ID)
+	require.NoError(t, err)
+	_, err = s.GetByID(ctx, 1, key.ID)
+	require.NoError(t, err)
+
+	err = s.DeleteByID(ctx, 1, key.ID)
+	require.NoError(t, err)
+	_, err = s.GetByID(ctx, 1, key.ID)
+	assert.True(t, IsErrGPGKeyNotExist(err))
+}
+
+func gpgKeysKeyring(t *testing.T, ctx context.Context, s *GPGKeysStore) {
+	entity, content := newTestGPGEntity(t, "alice@example.com")
+	_, err := s.Create(ctx, 1, content)
+	require.NoError(t, err)
+
+	payload := "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n\nInitial commit\n"
+	var sig bytes.Buffer
+	err = openpgp.ArmoredDetachSign(&sig, entity, strings.NewReader(payload), nil)
+	require.NoError(t, err)
+	assert.Equal(t, strings.ToUpper(entity.PrimaryKey.KeyIdString()), gpgSignatureIssuer(sig.String()))
+
+	keyring, err := s.keyring(ctx, 1)
+	require.NoError(t, err)
+	signer, err := openpgp.CheckArmoredDetachedSignature(keyring, strings.NewReader(payload), strings.NewReader(sig.String()), nil)
+	require.NoError(t, err)
+	assert.True(t, gpgEntityHasEmail(signer, "Alice@Example.com"))
+	assert.False(t, gpgEntityHasEmail(signer, "bob@example.com"))
+
+	// Other user's key ring should not verify the signature
+	keyring, err = s.keyring(ctx, 2)
+	require.NoError(t, err)
+	_, err = openpgp.CheckArmoredDetachedSignature(keyring, strings.NewReader(payload), strings.NewReader(sig.String()), nil)
+	assert.Error(t, err)
+}
@@ -35,13 +35,19 @@ type Issue struct {
 	MilestoneID     int64       `gorm:"index"`
 	Milestone       *Milestone  `xorm:"-" json:"-" gorm:"-"`
 	Priority        int
-	AssigneeID      int64 `gorm:"index"`
-	Assignee        *User `xorm:"-" json:"-" gorm:"-"`
+	AssigneeID      int64   `gorm:"index"` // The first assignee, kept for compatibility.
+	Assignee        *User   `xorm:"-" json:"-" gorm:"-"`
+	Assignees       []*User `xorm:"-" json:"-" gorm:"-"`
 	IsClosed        bool
 	IsRead          bool         `xorm:"-" json:"-" gorm:"-"`
 	IsPull          bool         // Indicates whether is a pull request or not.
 	PullRequest     *PullRequest `xorm:"-" json:"-" gorm:"-"`
 	NumComments     int
+	NumReactions    int   // Number of reactions on the issue itself.
+	TimeEstimate    int64 // Estimated time to complete in seconds.
+	IsLocked        bool
+	LockReason      string
+	PinOrder        int // Zero if the issue is not pinned.
 
 	Deadline     time.Time `xorm:"-" json:"-" gorm:"-"`
 	DeadlineUnix int64
@@ -52,6 +58,7 @@ type Issue struct {
 
 	Attachments []*Attachment `xorm:"-" json:"-" gorm:"-"`
 	Comments    []*Comment    `xorm:"-" json:"-" gorm:"-"`
+	Reactions   ReactionList  `xorm:"-" json:"-" gorm:"-"`
 }
 
 func (issue *Issue) BeforeInsert() {
@@ -131,6 +138,13 @@ func (issue *Issue) loadAttributes(e Engine) (err error) {
 		}
 	}
 
+	if issue.Assignees == nil {
+		issue.Assignees, err = getAssigneesByIssueID(e, issue.ID)
+		if err != nil {
+			return errors.Newf("getAssigneesByIssueID [%d]: %v", issue.ID, err)
+		}
+	}
+
 	if issue.IsPull && issue.PullRequest == nil {
 		// It is possible pull request is not yet created.
 		issue.PullRequest, err = getPullRequestByIssueID(e, issue.ID)
@@ -180,24 +194,34 @@ func (issue *Issue) State() apiv1types.IssueStateType {
 
 // This method assumes some fields assigned with values:
 // Required - Poster, Labels,
-// Optional - Milestone, Assignee, PullRequest
+// Optional - Milestone, Assignee, Assignees, PullRequest
 func (issue *Issue) APIFormat() *apiv1types.Issue {
 	apiLabels := make([]*apiv1types.IssueLabel, len(issue.Labels))
 	for i := range issue.Labels {
 		apiLabels[i] = issue.Labels[i].APIFormat()
 	}
+	apiAssignees := make([]*apiv1types.User, len(issue.Assignees))
+	for i := range issue.Assignees {
+		apiAssignees[i] = issue.Assignees[i].APIFormat()
+	}
 
 	apiIssue := &apiv1types.Issue{
-		ID:       issue.ID,
-		Index:    issue.Index,
-		Poster:   issue.Poster.APIFormat(),
-		Title:    issue.Title,
-		Body:     issue.Content,
-		Labels:   apiLabels,
-		State:    issue.State(),
-		Comments: issue.NumComments,
-		Created:  issue.Created,
-		Updated:  issue.Updated,
+		ID:           issue.ID,
+		Index:        issue.Index,
+		Poster:       issue.Poster.APIFormat(),
+		Title:        issue.Title,
+		Body:         issue.Content,
+		Labels:       apiLabels,
+		Assignees:    apiAssignees,
+		State:        issue.State(),
+		Comments:     issue.NumComments,
+		Reactions:    issue.NumReactions,
+		TimeEstimate: issue.TimeEstimate,
+		Locked:       issue.IsLocked,
+		LockReason:   issue.LockReason,
+		Pinned:       issue.IsPinned(),
+		Created:      issue.Created,
+		Updated:      issue.Updated,
 	}
 
 	if issue.Milestone != nil {
@@ -467,8 +491,19 @@ func (issue *Issue) changeStatus(e *xorm.Session, doer *User, repo *Repository,
 	return nil
 }
 
-// ChangeStatus changes issue status to open or closed.
+// ChangeStatus changes issue status to open or closed. It returns
+// ErrIssueHasOpenDependencies if an issue that is not a pull request is to be
+// closed while still blocked by open issues.
 func (issue *Issue) ChangeStatus(doer *User, repo *Repository, isClosed bool) (err error) {
+	if isClosed && !issue.IsClosed && !issue.IsPull {
+		blocked, err := issue.HasOpenDependencies()
+		if err != nil {
+			return errors.Newf("check open dependencies: %v", err)
+		} else if blocked {
+			return ErrIssueHasOpenDependencies{args: map[string]any{"issueID": issue.ID}}
+		}
+	}
+
 	sess := x.NewSession()
 	defer sess.Close()
 	if err = sess.Begin(); err != nil {
@@ -516,6 +551,9 @@ func (issue *Issue) ChangeStatus(doer *User, repo *Repository, isClosed bool) (e
 		log.Error("PrepareWebhooks [is_pull: %v, is_closed: %v]: %v", issue.IsPull, isClosed, err)
 	}
 
+	if isClosed {
+		issue.queueUnblockedDependents()
+	}
 	return nil
 }
 
@@ -525,6 +563,7 @@ func (issue *Issue) ChangeTitle(doer *User, title string) (err error) {
 	if err = UpdateIssueCols(issue, "name"); err != nil {
 		return errors.Newf("UpdateIssueCols: %v", err)
 	}
+	updateIssueIndexer(issue.ID)
 
 	if issue.IsPull {
 		issue.PullRequest.Issue = issue
@@ -567,6 +606,7 @@ func (issue *Issue) ChangeContent(doer *User, content string) (err error) {
 	if err = UpdateIssueCols(issue, "content"); err != nil {
 		return errors.Newf("UpdateIssueCols: %v", err)
 	}
+	updateIssueIndexer(issue.ID)
 
 	if issue.IsPull {
 		issue.PullRequest.Issue = issue
@@ -603,59 +643,11 @@ func (issue *Issue) ChangeContent(doer *User, content string) (err error) {
 	return nil
 }
 
-func (issue *Issue) ChangeAssignee(doer *User, assigneeID int64) (err error) {
-	issue.AssigneeID = assigneeID
-	if err = UpdateIssueUserByAssignee(issue); err != nil {
-		return errors.Newf("UpdateIssueUserByAssignee: %v", err)
-	}
-
-	issue.Assignee, err = Handle.Users().GetByID(context.TODO(), issue.AssigneeID)
-	if err != nil && !IsErrUserNotExist(err) {
-		log.Error("Failed to get user by ID: %v", err)
-		return nil
-	}
-
-	// Error not nil here means user does not exist, which is remove assignee.
-	isRemoveAssignee := err != nil
-	if issue.IsPull {
-		issue.PullRequest.Issue = issue
-		apiPullRequest := &apiv1types.WebhookPullRequestPayload{
-			Index:       issue.Index,
-			PullRequest: issue.PullRequest.APIFormat(),
-			Repository:  issue.Repo.APIFormatLegacy(nil),
-			Sender:      doer.APIFormat(),
-		}
-		if isRemoveAssignee {
-			apiPullRequest.Action = apiv1types.WebhookIssueUnassigned
-		} else {
-			apiPullRequest.Action = apiv1types.WebhookIssueAssigned
-		}
-		err = PrepareWebhooks(issue.Repo, HookEventTypePullRequest, apiPullRequest)
-	} else {
-		apiIssues := &apiv1types.WebhookIssuesPayload{
-			Index:      issue.Index,
-			Issue:      issue.APIFormat(),
-			Repository: issue.Repo.APIFormatLegacy(nil),
-			Sender:     doer.APIFormat(),
-		}
-		if isRemoveAssignee {
-			apiIssues.Action = apiv1types.WebhookIssueUnassigned
-		} else {
-			apiIssues.Action = apiv1types.WebhookIssueAssigned
-		}
-		err = PrepareWebhooks(issue.Repo, HookEventTypeIssues, apiIssues)
-	}
-	if err != nil {
-		log.Error("PrepareWebhooks [is_pull: %v, remove_assignee: %v]: %v", issue.IsPull, isRemoveAssignee, err)
-	}
-
-	return nil
-}
-
 type NewIssueOptions struct {
 	Repo        *Repository
 	Issue       *Issue
 	LableIDs    []int64
+	AssigneeIDs []int64
 	Attachments []string // In UUID format.
 	IsPull      bool
 }
@@ -681,19 +673,30 @@ func newIssue(e *xorm.Session, opts NewIssueOptions) (err error) {
 		}
 	}
 
-	if opts.Issue.AssigneeID > 0 {
-		assignee, err := getUserByID(e, opts.Issue.AssigneeID)
-		if err != nil && !IsErrUserNotExist(err) {
-			return errors.Newf("get user by ID: %v", err)
+	// Assignees that do not exist are dropped silently.
+	assignees := make([]*User, 0, len(opts.AssigneeIDs))
+	seen := make(map[int64]bool, len(opts.AssigneeIDs))
+	for _, assigneeID := range opts.AssigneeIDs {
+		if seen[assigneeID] {
+			continue
 		}
+		seen[assigneeID] = true
 
-		if assignee != nil {
-			opts.Issue.AssigneeID = assignee.ID
-			opts.Issue.Assignee = assignee
-		} else {
-			// The assignee does not exist, drop it
-			opts.Issue.AssigneeID = 0
+		assignee, err := getUserByID(e, assigneeID)
+		if err != nil {
+			if IsErrUserNotExist(err) {
+				continue
+			}
+			return errors.Newf("get user by ID: %v", err)
 		}
+		assignees = append(assignees, assignee)
+	}
+	opts.Issue.AssigneeID = 0
+	opts.Issue.Assignee = nil
+	opts.Issue.Assignees = make([]*User, 0, len(assignees))
+	if len(assignees) > 0 {
+		opts.Issue.AssigneeID = assignees[0].ID
+		opts.Issue.Assignee = assignees[0]
 	}
 
 	// Milestone and assignee validation should happen before insert actual object.
@@ -734,6 +737,12 @@ func newIssue(e *xorm.Session, opts NewIssueOptions) (err error) {
 		return err
 	}
 
+	for _, assignee := range assignees {
+		if err = opts.Issue.addAssignee(e, assignee); err != nil {
+			return errors.Newf("addAssignee [id: %d]: %v", assignee.ID, err)
+		}
+	}
+
 	if len(opts.Attachments) > 0 {
 		attachments, err := getAttachmentsByUUIDs(e, opts.Attachments)
 		if err != nil {
@@ -751,8 +760,15 @@ func newIssue(e *xorm.Session, opts NewIssueOptions) (err error) {
 	return opts.Issue.loadAttributes(e)
 }
 
-// NewIssue creates new issue with labels and attachments for repository.
-func NewIssue(repo *Repository, issue *Issue, labelIDs []int64, uuids []string) (err error) {
+// NewIssue creates new issue with labels, assignees and attachments for
+// repository. It returns ErrBlockedByUser if the owner of the repository has
+// blocked the poster.
+func NewIssue(repo *Repository, issue *Issue, labelIDs, assigneeIDs []int64, uuids []string) (err error) {
+	err = Handle.UserBlocks().EnsureNotBlocked(context.TODO(), repo.OwnerID, issue.PosterID)
+	if err != nil {
+		return err
+	}
+
 	sess := x.NewSession()
 	defer sess.Close()
 	if err = sess.Begin(); err != nil {
@@ -763,6 +779,7 @@ func NewIssue(repo *Repository, issue *Issue, labelIDs []int64, uuids []string)
 		Repo:        repo,
 		Issue:       issue,
 		LableIDs:    labelIDs,
+		AssigneeIDs: assigneeIDs,
 		Attachments: uuids,
 	}); err != nil {
 		return errors.Newf("new issue: %v", err)
@@ -771,6 +788,7 @@ func NewIssue(repo *Repository, issue *Issue, labelIDs []int64, uuids []string)
 	if err = sess.Commit(); err != nil {
 		return errors.Newf("commit: %v", err)
 	}
+	updateIssueIndexer(issue.ID)
 
 	if err = NotifyWatchers(&Action{
 		ActUserID:    issue.Poster.ID,
@@ -896,18 +914,31 @@ func GetIssueByID(id int64) (*Issue, error) {
 type IssuesOptions struct {
 	UserID      int64
 	AssigneeID  int64
+	ReviewerID  int64
 	RepoID      int64
 	PosterID    int64
 	MilestoneID int64
 	RepoIDs     []int64
-	Page        int
-	IsClosed    bool
-	IsMention   bool
-	IsPull      bool
-	Labels      string
-	SortType    string
+	// IssueIDs restricts results to given issues, e.g. matched by a keyword. No
+	// issue is returned if it is not nil but empty.
+	IssueIDs  []int64
+	Page      int
+	IsClosed  bool
+	IsMention bool
+	IsPull    bool
+	Labels    string
+	SortType  string
 }
 
+const (
+	// assignedIssuesCond is the query condition of issues assigned to the user.
+	assignedIssuesCond = "issue.id IN (SELECT issue_id FROM issue_assignee WHERE assignee_id = ?)"
+	// reviewRequestedIssuesCond is the query condition of pull requests that
+	// review has been requested from the user or any team the user belongs to,
+	// the user ID is expected to be given twice.
+	reviewRequestedIssuesCond = "issue.id IN (SELECT issue_id FROM review_request WHERE reviewer_id = ? OR team_id IN (SELECT team_id FROM team_user WHERE uid = ?))"
+)
+
 // buildIssuesQuery returns nil if it foresees there won't be any value returned.
 func buildIssuesQuery(opts *IssuesOptions) *xorm.Session {
 	sess := x.NewSession()
@@ -928,37 +959,32 @@ func buildIssuesQuery(opts *IssuesOptions) *xorm.Session {
 		sess.Where("issue.is_closed=?", opts.IsClosed)
 	}
 
+	if opts.IssueIDs != nil {
+		if len(opts.IssueIDs) == 0 {
+			return nil
+		}
+		sess.In("issue.id", opts.IssueIDs)
+	}
+
 	if opts.AssigneeID > 0 {
-		sess.And("issue.assignee_id=?", opts.AssigneeID)
-	} else if opts.PosterID > 0 {
+		sess.And(assignedIssuesCond, opts.AssigneeID)
+	}
+	if opts.PosterID > 0 {
 		sess.And("issue.poster_id=?", opts.PosterID)
 	}
 
+	if opts.ReviewerID > 0 {
+		sess.And(reviewRequestedIssuesCond, opts.ReviewerID, opts.ReviewerID)
+	}
+
 	if opts.MilestoneID > 0 {
 		sess.And("issue.milestone_id=?", opts.MilestoneID)
 	}
 
 	sess.And("issue.is_pull=?", opts.IsPull)
 
-	switch opts.SortType {
-	case "oldest":
-		sess.Asc("issue.created_unix")
-	case "recentupdate":
-		sess.Desc("issue.updated_unix")
-	case "leastupdate":
-		sess.Asc("issue.updated_unix")
-	case "mostcomment":
-		sess.Desc("issue.num_comments")
-	case "leastcomment":
-		sess.Asc("issue.num_comments")
-	case "priority":
-		sess.Desc("issue.priority")
-	default:
-		sess.Desc("issue.created_unix")
-	}
-
 	if len(opts.Labels) > 0 && opts.Labels != "0" {
-		labelIDs := strings.Split(opts.Labels, ",")
+		labelIDs := tool.StringsToInt64s(strings.Split(opts.Labels, ","))
 		if len(labelIDs) > 0 {
 			sess.Join("INNER", "issue_label", "issue.id = issue_label.issue_id").In("issue_label.label_id", labelIDs)
 		}
@@ -975,6 +1001,31 @@ func buildIssuesQuery(opts *IssuesOptions) *xorm.Session {
 	return sess
 }
 
+// sortIssuesQuery orders the issues query by given sort type, issues are
+// ordered by newest first by default.
+func sortIssuesQuery(sess *xorm.Session, sortType string) {
+	switch sortType {
+	case "oldest":
+		sess.Asc("issue.created_unix")
+	case "recentupdate":
+		sess.Desc("issue.updated_unix")
+	case "leastupdate":
+		sess.Asc("issue.updated_unix")
+	case "mostcomment":
+		sess.Desc("issue.num_comments")
+	case "leastcomment":
+		sess.Asc("issue.num_comments")
+	case "mostreactions":
+		sess.Desc("issue.num_reactions")
+	case "leastreactions":
+		sess.Asc("issue.num_reactions")
+	case "priority":
+		sess.Desc("issue.priority")
+	default:
+		sess.Desc("issue.created_unix")
+	}
+}
+
 // IssuesCount returns the number of issues by given conditions.
 func IssuesCount(opts *IssuesOptions) (int64, error) {
 	sess := buildIssuesQuery(opts)
@@ -985,6 +1036,21 @@ func IssuesCount(opts *IssuesOptions) (int64, error) {
 	return sess.Count(&Issue{})
 }
 
+// IssueRepoIDs returns IDs of repositories that have issues matching given
+// conditions, the issue IDs and pagination are ignored. It is used to restrict
+// keyword search to repositories that may have results.
+func IssueRepoIDs(opts *IssuesOptions) ([]int64, error) {
+	repoOpts := *opts
+	repoOpts.IssueIDs = nil
+	sess := buildIssuesQuery(&repoOpts)
+	if sess == nil {
+		return []int64{}, nil
+	}
+
+	repoIDs := make([]int64, 0, 10)
+	return repoIDs, sess.Table("issue").Distinct("issue.repo_id").Find(&repoIDs)
+}
+
 // Issues returns a list of issues by given conditions.
 func Issues(opts *IssuesOptions) ([]*Issue, error) {
 	sess := buildIssuesQuery(opts)
@@ -992,6 +1058,7 @@ func Issues(opts *IssuesOptions) ([]*Issue, error) {
 		return make([]*Issue, 0), nil
 	}
 
+	sortIssuesQuery(sess, opts.SortType)
 	sess.Limit(conf.UI.IssuePagingNum, (opts.Page-1)*conf.UI.IssuePagingNum)
 
 	issues := make([]*Issue, 0, conf.UI.IssuePagingNum)
@@ -1026,6 +1093,24 @@ func GetParticipantsByIssueID(issueID int64) ([]*User, error) {
 	return users, x.In("id", userIDs).Find(&users)
 }
 
+// GetIssuePosters returns all users who have opened issues or pull requests in
+// the repository.
+func GetIssuePosters(repoID int64, isPull bool) ([]*User, error) {
+	userIDs := make([]int64, 0, 10)
+	if err := x.Table("issue").Cols("poster_id").
+		Where("repo_id = ? AND is_pull = ?", repoID, isPull).
+		Distinct("poster_id").
+		Find(&userIDs); err != nil {
+		return nil, errors.Newf("get poster IDs: %v", err)
+	}
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	users := make([]*User, 0, len(userIDs))
+	return users, x.In("id", userIDs).Asc("lower_name").Find(&users)
+}
+
 // .___                             ____ ___
 // |   | ______ ________ __   ____ |    |   \______ ___________
 // |   |/  ___//  ___/  |  \_/ __ \|    |   /  ___// __ \_  __ \
@@ -1062,11 +1147,10 @@ func newIssueUsers(e *xorm.Session, repo *Repository, issue *Issue) error {
 	for _, assignee := range assignees {
 		isPoster := assignee.ID == issue.PosterID
 		issueUsers = append(issueUsers, &IssueUser{
-			IssueID:    issue.ID,
-			RepoID:     repo.ID,
-			UserID:     assignee.ID,
-			IsPoster:   isPoster,
-			IsAssigned: assignee.ID == issue.AssigneeID,
+			IssueID:  issue.ID,
+			RepoID:   repo.ID,
+			UserID:   assignee.ID,
+			IsPoster: isPoster,
 		})
 		if !isPosterAssignee && isPoster {
 			isPosterAssignee = true
@@ -1153,23 +1237,37 @@ func GetIssueUserPairsByMode(userID, repoID int64, filterMode FilterMode, isClos
 }
 
 // updateIssueMentions extracts mentioned people from content and
-// updates issue-user relations for them.
-func updateIssueMentions(e Engine, issueID int64, mentions []string) error {
+// updates issue-user relations for them. Users who have blocked the doer are
+// excluded.
+func updateIssueMentions(e Engine, doerID, issueID int64, mentions []string) error {
 	if len(mentions) == 0 {
 		return nil
 	}
 
+	blockers, err := Handle.UserBlocks().ListBlockers(context.TODO(), doerID)
+	if err != nil {
+		return errors.Newf("list blockers: %v", err)
+	}
+	blockerIDs := make(map[int64]bool, len(blockers))
+	for _, blocker := range blockers {
+		blockerIDs[blocker.ID] = true
+	}
+
 	for i := range mentions {
 		mentions[i] = strings.ToLower(mentions[i])
 	}
 	users := make([]*User, 0, len(mentions))
 
-	if err := e.In("lower_name", mentions).Asc("lower_name").Find(&users); err != nil {
+	if err = e.In("lower_name", mentions).Asc("lower_name").Find(&users); err != nil {
 		return errors.Newf("find mentioned users: %v", err)
 	}
 
 	ids := make([]int64, 0, len(mentions))
 	for _, user := range users {
+		if blockerIDs[user.ID] {
+			continue
+		}
+
 		ids = append(ids, user.ID)
 		if !user.IsOrganization() || user.NumMembers == 0 {
 			continue
@@ -1182,6 +1280,9 @@ func updateIssueMentions(e Engine, issueID int64, mentions []string) error {
 		}
 
 		for _, orgUser := range orgUsers {
+			if blockerIDs[orgUser.ID] {
+				continue
+			}
 			memberIDs = append(memberIDs, orgUser.ID)
 		}
 
@@ -1202,6 +1303,7 @@ type IssueStats struct {
 	AssignCount            int64
 	CreateCount            int64
 	MentionCount           int64
+	ReviewRequestedCount   int64
 }
 
 type FilterMode string
@@ -1211,6 +1313,8 @@ const (
 	FilterModeAssign    FilterMode = "assigned"
 	FilterModeCreate    FilterMode = "created_by"
 	FilterModeMention   FilterMode = "mentioned"
+
+	FilterModeReviewRequested FilterMode = "review_requested"
 )
 
 func parseCountResult(results []map[string][]byte) int64 {
@@ -1230,13 +1334,19 @@ type IssueStatsOptions struct {
 	Labels      string
 	MilestoneID int64
 	AssigneeID  int64
-	FilterMode  FilterMode
-	IsPull      bool
+	PosterID    int64
+	// IssueIDs restricts counting to given issues, e.g. matched by a keyword.
+	IssueIDs   []int64
+	FilterMode FilterMode
+	IsPull     bool
 }
 
 // GetIssueStats returns issue statistic information by given conditions.
 func GetIssueStats(opts *IssueStatsOptions) *IssueStats {
 	stats := &IssueStats{}
+	if opts.IssueIDs != nil && len(opts.IssueIDs) == 0 {
+		return stats
+	}
 
 	countSession := func(opts *IssueStatsOptions) *xorm.Session {
 		sess := x.Where("issue.repo_id = ?", opts.RepoID).And("is_pull = ?", opts.IsPull)
@@ -1253,7 +1363,15 @@ func GetIssueStats(opts *IssueStatsOptions) *IssueStats {
 		}
 
 		if opts.AssigneeID > 0 {
-			sess.And("assignee_id = ?", opts.AssigneeID)
+			sess.And(assignedIssuesCond, opts.AssigneeID)
+		}
+
+		if opts.PosterID > 0 {
+			sess.And("issue.poster_id = ?", opts.PosterID)
+		}
+
+		if opts.IssueIDs != nil {
+			sess.In("issue.id", opts.IssueIDs)
 		}
 
 		return sess
@@ -1292,6 +1410,16 @@ func GetIssueStats(opts *IssueStatsOptions) *IssueStats {
 			And("issue_user.is_mentioned = ?", true).
 			And("issue.is_closed = ?", true).
 			Count(new(Issue))
+	case FilterModeReviewRequested:
+		stats.OpenCount, _ = countSession(opts).
+			And(reviewRequestedIssuesCond, opts.UserID, opts.UserID).
+			And("issue.is_closed = ?", false).
+			Count(new(Issue))
+
+		stats.ClosedCount, _ = countSession(opts).
+			And(reviewRequestedIssuesCond, opts.UserID, opts.UserID).
+			And("issue.is_closed = ?", true).
+			Count(new(Issue))
 	}
 	return stats
 }
@@ -1313,9 +1441,15 @@ func GetUserIssueStats(repoID, userID int64, repoIDs []int64, filterMode FilterM
 	}
 
 	stats.AssignCount, _ = countSession(false, isPull, repoID, nil).
-		And("assignee_id = ?", userID).
+		And(assignedIssuesCond, userID).
 		Count(new(Issue))
 
+	if isPull {
+		stats.ReviewRequestedCount, _ = countSession(false, isPull, repoID, nil).
+			And(reviewRequestedIssuesCond, userID, userID).
+			Count(new(Issue))
+	}
+
 	stats.CreateCount, _ = countSession(false, isPull, repoID, nil).
 		And("poster_id = ?", userID).
 		Count(new(Issue))
@@ -1337,10 +1471,17 @@ func GetUserIssueStats(repoID, userID int64, repoIDs []int64, filterMode FilterM
 			Count(new(Issue))
 	case FilterModeAssign:
 		stats.OpenCount, _ = countSession(false, isPull, repoID, nil).
-			And("assignee_id = ?", userID).
+			And(assignedIssuesCond, userID).
+			Count(new(Issue))
+		stats.ClosedCount, _ = countSession(true, isPull, repoID, nil).
+			And(assignedIssuesCond, userID).
+			Count(new(Issue))
+	case FilterModeReviewRequested:
+		stats.OpenCount, _ = countSession(false, isPull, repoID, nil).
+			And(reviewRequestedIssuesCond, userID, userID).
 			Count(new(Issue))
 		stats.ClosedCount, _ = countSession(true, isPull, repoID, nil).
-			And("assignee_id = ?", userID).
+			And(reviewRequestedIssuesCond, userID, userID).
 			Count(new(Issue))
 	case FilterModeCreate:
 		stats.OpenCount, _ = countSession(false, isPull, repoID, nil).
@@ -1369,8 +1510,11 @@ func GetRepoIssueStats(repoID, userID int64, filterMode FilterMode, isPull bool)
 
 	switch filterMode {
 	case FilterModeAssign:
-		openCountSession.And("assignee_id = ?", userID)
-		closedCountSession.And("assignee_id = ?", userID)
+		openCountSession.And(assignedIssuesCond, userID)
+		closedCountSession.And(assignedIssuesCond, userID)
+	case FilterModeReviewRequested:
+		openCountSession.And(reviewRequestedIssuesCond, userID, userID)
+		closedCountSession.And(reviewRequestedIssuesCond, userID, userID)
 	case FilterModeCreate:
 		openCountSession.And("poster_id = ?", userID)
 		closedCountSession.And("poster_id = ?", userID)
@@ -1402,36 +1546,6 @@ func UpdateIssueUsersByStatus(issueID int64, isClosed bool) error {
 	return updateIssueUsersByStatus(x, issueID, isClosed)
 }
 
-func updateIssueUserByAssignee(e *xorm.Session, issue *Issue) (err error) {
-	if _, err = e.Exec("UPDATE `issue_user` SET is_assigned = ? WHERE issue_id = ?", false, issue.ID); err != nil {
-		return err
-	}
-
-	// Assignee ID equals to 0 means clear assignee.
-	if issue.AssigneeID > 0 {
-		if _, err = e.Exec("UPDATE `issue_user` SET is_assigned = ? WHERE uid = ? AND issue_id = ?", true, issue.AssigneeID, issue.ID); err != nil {
-			return err
-		}
-	}
-
-	return updateIssue(e, issue)
-}
-
-// UpdateIssueUserByAssignee updates issue-user relation for assignee.
-func UpdateIssueUserByAssignee(issue *Issue) (err error) {
-	sess := x.NewSession()
-	defer sess.Close()
-	if err = sess.Begin(); err != nil {
-		return err
-	}
-
-	if err = updateIssueUserByAssignee(sess, issue); err != nil {
-		return err
-	}
-
-	return sess.Commit()
-}
-
 // UpdateIssueUserByRead updates issue-user relation for reading.
 func UpdateIssueUserByRead(uid, issueID int64) error {
 	_, err := x.Exec("UPDATE `issue_user` SET is_read=? WHERE uid=? AND issue_id=?", true, uid, issueID)
@@ -0,0 +1,302 @@
+package database
+
+import (
+	"github.com/cockroachdb/errors"
+	log "unknwon.dev/clog/v2"
+	"xorm.io/xorm"
+
+	"gogs.io/gogs/internal/markup"
+	apiv1types "gogs.io/gogs/internal/route/api/v1/types"
+)
+
+// IssueAssignee represents an issue-assignee relation.
+type IssueAssignee struct {
+	ID         int64
+	IssueID    int64 `xorm:"UNIQUE(s)"`
+	AssigneeID int64 `xorm:"UNIQUE(s) INDEX"`
+}
+
+func hasIssueAssignee(e Engine, issueID, assigneeID int64) bool {
+	has, _ := e.Where("issue_id = ? AND assignee_id = ?", issueID, assigneeID).Get(new(IssueAssignee))
+	return has
+}
+
+// getAssigneesByIssueID returns all assignees of the issue in the order of
+// assignment.
+func getAssigneesByIssueID(e Engine, issueID int64) ([]*User, error) {
+	issueAssignees := make([]*IssueAssignee, 0, 2)
+	if err := e.Where("issue_id = ?", issueID).Asc("id").Find(&issueAssignees); err != nil {
+		return nil, err
+	} else if len(issueAssignees) == 0 {
+		return []*User{}, nil
+	}
+
+	assigneeIDs := make([]int64, len(issueAssignees))
+	for i := range issueAssignees {
+		assigneeIDs[i] = issueAssignees[i].AssigneeID
+	}
+
+	users := make([]*User, 0, len(assigneeIDs))
+	if err := e.In("id", assigneeIDs).Find(&users); err != nil {
+		return nil, err
+	}
+
+	userMap := make(map[int64]*User, len(users))
+	for _, u := range users {
+		// TODO(unknwon): Rely on AfterFind hook to sanitize user full name.
+		u.FullName = markup.Sanitize(u.FullName)
+		userMap[u.ID] = u
+	}
+
+	assignees := make([]*User, 0, len(users))
+	for _, id := range assigneeIDs {
+		if u, ok := userMap[id]; ok {
+			assignees = append(assignees, u)
+		}
+	}
+	return assignees, nil
+}
+
+// GetAssigneesByIssueID returns all assignees of the issue by given ID.
+func GetAssigneesByIssueID(issueID int64) ([]*User, error) {
+	return getAssigneesByIssueID(x, issueID)
+}
+
+// updateIssueUserByAssignee updates the issue-user relation of the user to
+// reflect whether the user is assigned to the issue.
+func updateIssueUserByAssignee(e Engine, issue *Issue, userID int64, isAssigned bool) error {
+	iu := &IssueUser{
+		UserID:  userID,
+		IssueID: issue.ID,
+	}
+	has, err := e.Get(iu)
+	if err != nil {
+		return err
+	}
+
+	iu.IsAssigned = isAssigned
+	if has {
+		_, err = e.ID(iu.ID).AllCols().Update(iu)
+	} else if isAssigned {
+		iu.RepoID = issue.RepoID
+		iu.MilestoneID = issue.MilestoneID
+		iu.IsClosed = issue.IsClosed
+		_, err = e.Insert(iu)
+	}
+	return err
+}
+
+// IsAssignee returns true if given user by ID is one of the assignees.
+// This method assumes following fields have been assigned with valid values:
+// Required - Assignees
+func (issue *Issue) IsAssignee(userID int64) bool {
+	for _, assignee := range issue.Assignees {
+		if assignee.ID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func (issue *Issue) addAssignee(e *xorm.Session, assignee *User) (err error) {
+	if hasIssueAssignee(e, issue.ID, assignee.ID) {
+		return nil
+	}
+
+	if _, err = e.Insert(&IssueAssignee{
+		IssueID:    issue.ID,
+		AssigneeID: assignee.ID,
+	}); err != nil {
+		return err
+	}
+
+	if err = updateIssueUserByAssignee(e, issue, assignee.ID, true); err != nil {
+		return errors.Newf("updateIssueUserByAssignee: %v", err)
+	}
+
+	// The first assignee is kept in the issue for compatibility.
+	if issue.AssigneeID == 0 {
+		issue.AssigneeID = assignee.ID
+		issue.Assignee = assignee
+		if _, err = e.ID(issue.ID).Cols("assignee_id").Update(issue); err != nil {
+			return errors.Newf("update issue: %v", err)
+		}
+	}
+
+	issue.Assignees = append(issue.Assignees, assignee)
+	return nil
+}
+
+func (issue *Issue) removeAssignee(e *xorm.Session, assigneeID int64) (err error) {
+	if _, err = e.Delete(&IssueAssignee{
+		IssueID:    issue.ID,
+		AssigneeID: assigneeID,
+	}); err != nil {
+		return err
+	}
+
+	if err = updateIssueUserByAssignee(e, issue, assigneeID, false); err != nil {
+		return errors.Newf("updateIssueUserByAssignee: %v", err)
+	}
+
+	issue.Assignees, err = getAssigneesByIssueID(e, issue.ID)
+	if err != nil {
+		return errors.Newf("getAssigneesByIssueID: %v", err)
+	}
+
+	if issue.AssigneeID == assigneeID {
+		issue.AssigneeID = 0
+		issue.Assignee = nil
+		if len(issue.Assignees) > 0 {
+			issue.AssigneeID = issue.Assignees[0].ID
+			issue.Assignee = issue.Assignees[0]
+		}
+		if _, err = e.ID(issue.ID).Cols("assignee_id").Update(issue); err != nil {
+			return errors.Newf("update issue: %v", err)
+		}
+	}
+	return nil
+}
+
+func (issue *Issue) sendAssigneeUpdatedWebhook(doer, assignee *User, isRemove bool) {
+	action := apiv1types.WebhookIssueAssigned
+	if isRemove {
+		action = apiv1types.WebhookIssueUnassigned
+	}
+
+	var err error
+	if issue.IsPull {
+		issue.PullRequest.Issue = issue
+		err = PrepareWebhooks(issue.Repo, HookEventTypePullRequest, &apiv1types.WebhookPullRequestPayload{
+			Action:      action,
+			Index:       issue.Index,
+			PullRequest: issue.PullRequest.APIFormat(),
+			Assignee:    assignee.APIFormat(),
+			Repository:  issue.Repo.APIFormatLegacy(nil),
+			Sender:      doer.APIFormat(),
+		})
+	} else {
+		err = PrepareWebhooks(issue.Repo, HookEventTypeIssues, &apiv1types.WebhookIssuesPayload{
+			Action:     action,
+			Index:      issue.Index,
+			Issue:      issue.APIFormat(),
+			Assignee:   assignee.APIFormat(),
+			Repository: issue.Repo.APIFormatLegacy(nil),
+			Sender:     doer.APIFormat(),
+		})
+	}
+	if err != nil {
+		log.Error("PrepareWebhooks [is_pull: %v, remove_assignee: %v]: %v", issue.IsPull, isRemove, err)
+	}
+}
+
+// AddAssignee assigns the issue to the user in addition to existing assignees.
+func (issue *Issue) AddAssignee(doer, assignee *User) (err error) {
+	if hasIssueAssignee(x, issue.ID, assignee.ID) {
+		return nil
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if err = issue.addAssignee(sess, assignee); err != nil {
+		return err
+	}
+
+	if err = sess.Commit(); err != nil {
+		return err
+	}
+
+	issue.sendAssigneeUpdatedWebhook(doer, assignee, false)
+	return nil
+}
+
+// RemoveAssignee removes the user from assignees of the issue.
+func (issue *Issue) RemoveAssignee(doer, assignee *User) (err error) {
+	if !hasIssueAssignee(x, issue.ID, assignee.ID) {
+		return nil
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if err = issue.removeAssignee(sess, assignee.ID); err != nil {
+		return err
+	}
+
+	if err = sess.Commit(); err != nil {
+		return err
+	}
+
+	issue.sendAssigneeUpdatedWebhook(doer, assignee, true)
+	return nil
+}
+
+// ReplaceAssignees changes assignees of the issue to exactly the given users in
+// a single transaction.
+func (issue *Issue) ReplaceAssignees(doer *User, assignees []*User) (err error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	current, err := getAssigneesByIssueID(sess, issue.ID)
+	if err != nil {
+		return errors.Newf("getAssigneesByIssueID: %v", err)
+	}
+	issue.Assignees = current
+
+	want := make(map[int64]bool, len(assignees))
+	for _, assignee := range assignees {
+		want[assignee.ID] = true
+	}
+	have := make(map[int64]bool, len(current))
+	var removed, added []*User
+	for _, assignee := range current {
+		have[assignee.ID] = true
+		if want[assignee.ID] {
+			continue
+		}
+
+		if err = issue.removeAssignee(sess, assignee.ID); err != nil {
+			return errors.Newf("removeAssignee [%d]: %v", assignee.ID, err)
+		}
+		removed = append(removed, assignee)
+	}
+
+	for _, assignee := range assignees {
+		if have[assignee.ID] {
+			continue
+		}
+		have[assignee.ID] = true
+
+		if err = issue.addAssignee(sess, assignee); err != nil {
+			return errors.Newf("addAssignee [%d]: %v", assignee.ID, err)
+		}
+		added = append(added, assignee)
+	}
+
+	if err = sess.Commit(); err != nil {
+		return err
+	}
+
+	for _, assignee := range removed {
+		issue.sendAssigneeUpdatedWebhook(doer, assignee, true)
+	}
+	for _, assignee := range added {
+		issue.sendAssigneeUpdatedWebhook(doer, assignee, false)
+	}
+	return nil
+}
+
+// ClearAssignees removes all assignees of the issue.
+func (issue *Issue) ClearAssignees(doer *User) error {
+	return issue.ReplaceAssignees(doer, nil)
+}
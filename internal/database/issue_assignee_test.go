@@ -0,0 +1,124 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+)
+
+func TestIssue_Assignees(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	newLegacyTestDB(t, "TestIssue_Assignees")
+
+	alice := createLegacyTestUser(t, "alice")
+	bob := createLegacyTestUser(t, "bob")
+	cindy := createLegacyTestUser(t, "cindy")
+	repo := createLegacyTestRepo(t, alice, "repo", false)
+	issue := createLegacyTestIssue(t, repo, alice, "Fix bug")
+
+	// assertAssignees checks assignees of the issue in the database, the first
+	// one of which is expected to be kept in the issue.
+	assertAssignees := func(t *testing.T, want ...*User) {
+		t.Helper()
+
+		assignees, err := GetAssigneesByIssueID(issue.ID)
+		require.NoError(t, err)
+		wantIDs := make([]int64, 0, len(want))
+		for _, u := range want {
+			wantIDs = append(wantIDs, u.ID)
+		}
+		gotIDs := make([]int64, 0, len(assignees))
+		for _, u := range assignees {
+			gotIDs = append(gotIDs, u.ID)
+		}
+		assert.Equal(t, wantIDs, gotIDs)
+
+		got, err := GetIssueByID(issue.ID)
+		require.NoError(t, err)
+		wantAssigneeID := int64(0)
+		if len(want) > 0 {
+			wantAssigneeID = want[0].ID
+		}
+		assert.Equal(t, wantAssigneeID, got.AssigneeID)
+		assert.Equal(t, wantAssigneeID, issue.AssigneeID)
+	}
+	// isAssigned returns whether the issue-user relation of the user says the
+	// user is assigned.
+	isAssigned := func(t *testing.T, user *User) bool {
+		iu := &IssueUser{UserID: user.ID, IssueID: issue.ID}
+		has, err := x.Get(iu)
+		require.NoError(t, err)
+		return has && iu.IsAssigned
+	}
+
+	t.Run("AddAssignee", func(t *testing.T) {
+		require.NoError(t, issue.AddAssignee(alice, bob))
+		require.NoError(t, issue.AddAssignee(alice, cindy))
+		assertAssignees(t, bob, cindy)
+		assert.True(t, isAssigned(t, bob))
+		assert.True(t, isAssigned(t, cindy))
+
+		// Adding an existing assignee is a no-op
+		require.NoError(t, issue.AddAssignee(alice, bob))
+		assertAssignees(t, bob, cindy)
+	})
+
+	t.Run("RemoveAssignee", func(t *testing.T) {
+		// The next assignee is kept in the issue after removing the first one
+		require.NoError(t, issue.RemoveAssignee(alice, bob))
+		assertAssignees(t, cindy)
+		assert.False(t, isAssigned(t, bob))
+
+		// Removing a user who is not assigned is a no-op
+		require.NoError(t, issue.RemoveAssignee(alice, alice))
+		assertAssignees(t, cindy)
+	})
+
+	t.Run("ReplaceAssignees", func(t *testing.T) {
+		require.NoError(t, issue.ReplaceAssignees(alice, []*User{alice, cindy, alice}))
+		assertAssignees(t, cindy, alice)
+
+		require.NoError(t, issue.ReplaceAssignees(alice, []*User{bob}))
+		assertAssignees(t, bob)
+		assert.True(t, isAssigned(t, bob))
+		assert.False(t, isAssigned(t, alice))
+		assert.False(t, isAssigned(t, cindy))
+		assert.Equal(t, []*User{bob}, issue.Assignees)
+	})
+
+	t.Run("ClearAssignees", func(t *testing.T) {
+		require.NoError(t, issue.ClearAssignees(alice))
+		assertAssignees(t)
+		assert.False(t, isAssigned(t, bob))
+		assert.Empty(t, issue.Assignees)
+	})
+
+	t.Run("delete user", func(t *testing.T) {
+		conf.SetMockRepository(t, conf.RepositoryOpts{Root: filepath.Join(t.TempDir(), "repositories")})
+
+		dave := createLegacyTestUser(t, "dave")
+		require.NoError(t, issue.ReplaceAssignees(alice, []*User{bob, cindy, dave}))
+		otherIssue := createLegacyTestIssue(t, repo, alice, "Add feature")
+		require.NoError(t, otherIssue.AddAssignee(alice, bob))
+
+		// The next assignee is kept in the issue after deleting the first one
+		require.NoError(t, Handle.Users().DeleteByID(t.Context(), bob.ID, true))
+		issue.AssigneeID = cindy.ID
+		assertAssignees(t, cindy, dave)
+
+		got, err := GetIssueByID(otherIssue.ID)
+		require.NoError(t, err)
+		assert.Zero(t, got.AssigneeID)
+
+		// Deleting an assignee other than the first one does not change the issue
+		require.NoError(t, Handle.Users().DeleteByID(t.Context(), dave.ID, true))
+		assertAssignees(t, cindy)
+	})
+}
@@ -0,0 +1,195 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	log "unknwon.dev/clog/v2"
+)
+
+// IssueDependency represents a relation that an issue is blocked by another
+// issue, which may be in a different repository.
+type IssueDependency struct {
+	ID           int64
+	IssueID      int64 `xorm:"UNIQUE(s) INDEX"`
+	DependencyID int64 `xorm:"UNIQUE(s) INDEX"`
+	CreatedUnix  int64
+}
+
+func (d *IssueDependency) BeforeInsert() {
+	d.CreatedUnix = time.Now().Unix()
+}
+
+// getIssuesByDependency returns issues joined on given column of the
+// issue-dependency relations that match the condition, with repositories
+// loaded.
+func getIssuesByDependency(joinCol, cond string, args ...any) ([]*Issue, error) {
+	issues := make([]*Issue, 0, 5)
+	if err := x.Join("INNER", "issue_dependency", "issue.id = issue_dependency."+joinCol).
+		Where(cond, args...).
+		Asc("issue_dependency.id").
+		Find(&issues); err != nil {
+		return nil, err
+	}
+
+	for _, issue := range issues {
+		repo, err := getRepositoryByID(x, issue.RepoID)
+		if err != nil {
+			return nil, errors.Newf("get repository by ID [%d]: %v", issue.RepoID, err)
+		}
+		issue.Repo = repo
+	}
+	return issues, nil
+}
+
+// GetDependencies returns all issues that block the issue.
+func (issue *Issue) GetDependencies() ([]*Issue, error) {
+	return getIssuesByDependency("dependency_id", "issue_dependency.issue_id = ?", issue.ID)
+}
+
+// GetDependents returns all issues that are blocked by the issue.
+func (issue *Issue) GetDependents() ([]*Issue, error) {
+	return getIssuesByDependency("issue_id", "issue_dependency.dependency_id = ?", issue.ID)
+}
+
+// HasOpenDependencies returns true if the issue is blocked by any open issue.
+func (issue *Issue) HasOpenDependencies() (bool, error) {
+	count, err := x.Join("INNER", "issue_dependency", "issue.id = issue_dependency.dependency_id").
+		Where("issue_dependency.issue_id = ? AND issue.is_closed = ?", issue.ID, false).
+		Count(new(Issue))
+	return count > 0, err
+}
+
+// isDependencyOf returns true if the issue is blocked by the target issue either
+// directly or through a chain of dependencies.
+func isDependencyOf(e Engine, issueID, targetID int64) (bool, error) {
+	visited := map[int64]bool{issueID: true}
+	queue := []int64{issueID}
+	for len(queue) > 0 {
+		deps := make([]*IssueDependency, 0, 5)
+		if err := e.In("issue_id", queue).Find(&deps); err != nil {
+			return false, err
+		}
+
+		queue = queue[:0]
+		for _, dep := range deps {
+			if dep.DependencyID == targetID {
+				return true, nil
+			} else if visited[dep.DependencyID] {
+				continue
+			}
+			visited[dep.DependencyID] = true
+			queue = append(queue, dep.DependencyID)
+		}
+	}
+	return false, nil
+}
+
+// AddDependency marks the issue as blocked by the dependency. It returns
+// ErrCircularIssueDependency if the dependency is the issue itself or is
+// already blocked by the issue.
+func (issue *Issue) AddDependency(dependency *Issue) error {
+	if issue.ID == dependency.ID {
+		return ErrCircularIssueDependency{args: map[string]any{"issueID": issue.ID, "dependencyID": dependency.ID}}
+	}
+
+	has, err := x.Get(&IssueDependency{IssueID: issue.ID, DependencyID: dependency.ID})
+	if err != nil {
+		return err
+	} else if has {
+		return nil
+	}
+
+	circular, err := isDependencyOf(x, dependency.ID, issue.ID)
+	if err != nil {
+		return errors.Newf("check circular dependency: %v", err)
+	} else if circular {
+		return ErrCircularIssueDependency{args: map[string]any{"issueID": issue.ID, "dependencyID": dependency.ID}}
+	}
+
+	_, err = x.Insert(&IssueDependency{
+		IssueID:      issue.ID,
+		DependencyID: dependency.ID,
+	})
+	return err
+}
+
+// RemoveDependency removes the dependency from issues that block the issue.
+func (issue *Issue) RemoveDependency(dependency *Issue) error {
+	_, err := x.Delete(&IssueDependency{
+		IssueID:      issue.ID,
+		DependencyID: dependency.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if issue.IsPull && !issue.IsClosed {
+		issue.queueAutoMergeIfUnblocked()
+	}
+	return nil
+}
+
+// queueAutoMergeIfUnblocked adds the pull request of the issue to the
+// auto-merge queue if it has been scheduled for auto-merge, is mergeable and is
+// no longer blocked by any open issue.
+func (issue *Issue) queueAutoMergeIfUnblocked() {
+	pr, err := GetPullRequestByIssueID(issue.ID)
+	if err != nil {
+		log.Error("Failed to get pull request [issue_id: %d]: %v", issue.ID, err)
+		return
+	} else if !pr.IsAutoMergeScheduled() || pr.Status != PullRequestStatusMergeable {
+		return
+	}
+
+	blocked, err := issue.HasOpenDependencies()
+	if err != nil {
+		log.Error("Failed to check open dependencies [issue_id: %d]: %v", issue.ID, err)
+		return
+	} else if !blocked {
+		go AutoMergeQueue.Add(pr.ID)
+	}
+}
+
+// queueUnblockedDependents adds open pull requests that are blocked by the
+// issue to the auto-merge queue if they are no longer blocked by any open issue.
+// It should be called when the issue is closed.
+func (issue *Issue) queueUnblockedDependents() {
+	dependents, err := getIssuesByDependency("issue_id",
+		"issue_dependency.dependency_id = ? AND issue.is_pull = ? AND issue.is_closed = ?", issue.ID, true, false)
+	if err != nil {
+		log.Error("Failed to get dependents [issue_id: %d]: %v", issue.ID, err)
+		return
+	}
+
+	for _, dependent := range dependents {
+		dependent.queueAutoMergeIfUnblocked()
+	}
+}
+
+type ErrCircularIssueDependency struct {
+	args map[string]any
+}
+
+func IsErrCircularIssueDependency(err error) bool {
+	_, ok := err.(ErrCircularIssueDependency)
+	return ok
+}
+
+func (err ErrCircularIssueDependency) Error() string {
+	return fmt.Sprintf("issue dependency is circular: %v", err.args)
+}
+
+type ErrIssueHasOpenDependencies struct {
+	args map[string]any
+}
+
+func IsErrIssueHasOpenDependencies(err error) bool {
+	_, ok := err.(ErrIssueHasOpenDependencies)
+	return ok
+}
+
+func (err ErrIssueHasOpenDependencies) Error() string {
+	return fmt.Sprintf("issue is blocked by open issues: %v", err.args)
+}
@@ -0,0 +1,124 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssue_Dependencies(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	newLegacyTestDB(t, "TestIssue_Dependencies")
+
+	owner := createLegacyTestUser(t, "alice")
+	repo := createLegacyTestRepo(t, owner, "repo", false)
+	otherRepo := createLegacyTestRepo(t, owner, "other", false)
+	issues := make([]*Issue, 4)
+	for i := range issues {
+		issues[i] = createLegacyTestIssue(t, repo, owner, "Issue")
+	}
+	crossRepoIssue := createLegacyTestIssue(t, otherRepo, owner, "Issue")
+
+	// Each issue is blocked by the next one, and the last one is blocked by the
+	// issue in the other repository.
+	require.NoError(t, issues[0].AddDependency(issues[1]))
+	require.NoError(t, issues[1].AddDependency(issues[2]))
+	require.NoError(t, issues[2].AddDependency(crossRepoIssue))
+
+	t.Run("isDependencyOf", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			issue  *Issue
+			target *Issue
+			want   bool
+		}{
+			{name: "direct", issue: issues[0], target: issues[1], want: true},
+			{name: "transitive", issue: issues[0], target: issues[2], want: true},
+			{name: "cross-repository", issue: issues[0], target: crossRepoIssue, want: true},
+			{name: "reverse", issue: issues[1], target: issues[0], want: false},
+			{name: "unrelated", issue: issues[3], target: issues[0], want: false},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				got, err := isDependencyOf(x, test.issue.ID, test.target.ID)
+				require.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			})
+		}
+	})
+
+	t.Run("AddDependency", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			issue      *Issue
+			dependency *Issue
+			wantErr    bool
+		}{
+			{name: "self", issue: issues[0], dependency: issues[0], wantErr: true},
+			{name: "direct cycle", issue: issues[1], dependency: issues[0], wantErr: true},
+			{name: "transitive cycle", issue: issues[2], dependency: issues[0], wantErr: true},
+			{name: "cross-repository cycle", issue: crossRepoIssue, dependency: issues[0], wantErr: true},
+			{name: "existing", issue: issues[0], dependency: issues[1]},
+			{name: "cross-repository", issue: issues[3], dependency: crossRepoIssue},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				err := test.issue.AddDependency(test.dependency)
+				if test.wantErr {
+					wantErr := ErrCircularIssueDependency{args: map[string]any{"issueID": test.issue.ID, "dependencyID": test.dependency.ID}}
+					assert.Equal(t, wantErr, err)
+					return
+				}
+				assert.NoError(t, err)
+			})
+		}
+
+		dependencies, err := issues[0].GetDependencies()
+		require.NoError(t, err)
+		require.Len(t, dependencies, 1)
+		assert.Equal(t, issues[1].ID, dependencies[0].ID)
+
+		dependents, err := crossRepoIssue.GetDependents()
+		require.NoError(t, err)
+		require.Len(t, dependents, 2)
+		assert.Equal(t, issues[2].ID, dependents[0].ID)
+		assert.Equal(t, issues[3].ID, dependents[1].ID)
+	})
+
+	t.Run("close with open dependencies", func(t *testing.T) {
+		for _, issue := range issues[:3] {
+			blocked, err := issue.HasOpenDependencies()
+			require.NoError(t, err)
+			assert.True(t, blocked)
+
+			err = issue.ChangeStatus(owner, repo, true)
+			wantErr := ErrIssueHasOpenDependencies{args: map[string]any{"issueID": issue.ID}}
+			assert.Equal(t, wantErr, err)
+		}
+
+		// Issues can be closed once all of their dependencies are closed.
+		require.NoError(t, crossRepoIssue.ChangeStatus(owner, otherRepo, true))
+		for i := 2; i >= 0; i-- {
+			blocked, err := issues[i].HasOpenDependencies()
+			require.NoError(t, err)
+			assert.False(t, blocked)
+			require.NoError(t, issues[i].ChangeStatus(owner, repo, true))
+		}
+	})
+
+	t.Run("RemoveDependency", func(t *testing.T) {
+		require.NoError(t, crossRepoIssue.ChangeStatus(owner, otherRepo, false))
+		blocked, err := issues[3].HasOpenDependencies()
+		require.NoError(t, err)
+		assert.True(t, blocked)
+
+		require.NoError(t, issues[3].RemoveDependency(crossRepoIssue))
+		blocked, err = issues[3].HasOpenDependencies()
+		require.NoError(t, err)
+		assert.False(t, blocked)
+	})
+}
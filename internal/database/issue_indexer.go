@@ -0,0 +1,178 @@
+package database
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/indexer"
+	"gogs.io/gogs/internal/sync"
+)
+
+// IssueIndexerQueue is the queue of issues that need to be (re)indexed.
+var IssueIndexerQueue = sync.NewUniqueQueue(1000)
+
+// issueIndexer is nil when full-text search is disabled.
+var issueIndexer indexer.IssueIndexer
+
+// updateIssueIndexer queues the issue to be indexed with its latest content.
+func updateIssueIndexer(issueID int64) {
+	if issueIndexer == nil {
+		return
+	}
+	go IssueIndexerQueue.Add(issueID)
+}
+
+// deleteIssuesFromIndexer removes issues of given IDs from the index.
+func deleteIssuesFromIndexer(issueIDs ...int64) {
+	if issueIndexer == nil || len(issueIDs) == 0 {
+		return
+	}
+	if err := issueIndexer.Delete(issueIDs...); err != nil {
+		log.Error("Failed to delete issues from issue indexer: %v", err)
+	}
+}
+
+// newIssueDocument returns the document to be indexed of the issue.
+func newIssueDocument(issue *Issue) (*indexer.IssueDocument, error) {
+	comments := make([]*Comment, 0, 10)
+	if err := x.Where("issue_id = ? AND type = ?", issue.ID, CommentTypeComment).Asc("id").Find(&comments); err != nil {
+		return nil, errors.Newf("find comments: %v", err)
+	}
+
+	doc := &indexer.IssueDocument{
+		ID:       issue.ID,
+		RepoID:   issue.RepoID,
+		Title:    issue.Title,
+		Body:     issue.Content,
+		Comments: make([]string, len(comments)),
+	}
+	for i := range comments {
+		doc.Comments[i] = comments[i].Content
+	}
+	return doc, nil
+}
+
+// indexIssue indexes the issue of given ID, or deletes it from the index if the
+// issue no longer exists.
+func indexIssue(issueID int64) error {
+	issue, err := getRawIssueByID(x, issueID)
+	if err != nil {
+		if IsErrIssueNotExist(err) {
+			return issueIndexer.Delete(issueID)
+		}
+		return errors.Newf("get issue by ID: %v", err)
+	}
+
+	doc, err := newIssueDocument(issue)
+	if err != nil {
+		return err
+	}
+	return issueIndexer.Index(doc)
+}
+
+// populateIssueIndexer indexes all existing issues.
+func populateIssueIndexer() {
+	log.Info("Populating issue indexer...")
+
+	var lastID int64
+	for {
+		issues := make([]*Issue, 0, 100)
+		if err := x.Where("id > ?", lastID).Asc("id").Limit(100).Find(&issues); err != nil {
+			log.Error("Failed to find issues to populate issue indexer: %v", err)
+			return
+		} else if len(issues) == 0 {
+			break
+		}
+
+		docs := make([]*indexer.IssueDocument, 0, len(issues))
+		for _, issue := range issues {
+			doc, err := newIssueDocument(issue)
+			if err != nil {
+				log.Error("Failed to create document of issue [%d]: %v", issue.ID, err)
+				continue
+			}
+			docs = append(docs, doc)
+		}
+		if err := issueIndexer.Index(docs...); err != nil {
+			log.Error("Failed to index issues: %v", err)
+			return
+		}
+		lastID = issues[len(issues)-1].ID
+	}
+
+	log.Info("Issue indexer has been populated")
+}
+
+func processIssueIndexerQueue() {
+	for issueID := range IssueIndexerQueue.Queue() {
+		IssueIndexerQueue.Remove(issueID)
+
+		id, _ := strconv.ParseInt(issueID, 10, 64)
+		if err := indexIssue(id); err != nil {
+			log.Error("Failed to index issue [%d]: %v", id, err)
+		}
+	}
+}
+
+// InitIssueIndexer opens the issue indexer and starts processing the queue,
+// the index is populated with existing issues if it is empty.
+func InitIssueIndexer() {
+	if conf.Indexer.IssueType == "" {
+		return
+	}
+
+	var err error
+	issueIndexer, err = indexer.NewIssueIndexer(conf.Indexer.IssueType, conf.Indexer.IssuePath)
+	if err != nil {
+		log.Fatal("Failed to open issue indexer: %v", err)
+	}
+
+	go func() {
+		if issueIndexer.IsEmpty() {
+			populateIssueIndexer()
+		}
+		processIssueIndexerQueue()
+	}()
+}
+
+// SearchIssueIDs returns IDs of all issues that match the keyword in given
+// repositories, or all repositories if nil, in the order of relevance. No issue
+// is matched if repository IDs are not nil but empty. Results are not limited
+// so that callers can apply other filters and paginate afterwards. Only titles
+// and bodies are matched as a whole if the issue indexer is disabled.
+func SearchIssueIDs(keyword string, repoIDs []int64) ([]int64, error) {
+	if repoIDs != nil && len(repoIDs) == 0 {
+		return []int64{}, nil
+	}
+
+	if issueIndexer != nil {
+		return issueIndexer.Search(indexer.SearchOptions{
+			Keyword: keyword,
+			RepoIDs: repoIDs,
+		})
+	}
+
+	keyword = strings.TrimSpace(keyword)
+	if keyword == "" {
+		return []int64{}, nil
+	}
+
+	sess := x.Cols("id").Where("name LIKE ? OR content LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+	if len(repoIDs) > 0 {
+		sess.In("repo_id", repoIDs)
+	}
+	issues := make([]*Issue, 0, 10)
+	if err := sess.Desc("id").Find(&issues); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, len(issues))
+	for i := range issues {
+		ids[i] = issues[i].ID
+	}
+	return ids, nil
+}
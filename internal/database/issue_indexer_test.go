@@ -0,0 +1,96 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+)
+
+func TestSearchIssueIDs(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	newLegacyTestDB(t, "TestSearchIssueIDs")
+	conf.SetMockUI(t, conf.UIOpts{IssuePagingNum: 10})
+
+	alice := createLegacyTestUser(t, "alice")
+	bob := createLegacyTestUser(t, "bob")
+	repo := createLegacyTestRepo(t, alice, "repo", false)
+	otherRepo := createLegacyTestRepo(t, bob, "repo", false)
+
+	// More issues than a page match the keyword, and only the last one is
+	// assigned to bob.
+	var (
+		want  []int64
+		issue *Issue
+	)
+	for range 25 {
+		issue = createLegacyTestIssue(t, repo, alice, "Crash on startup")
+		want = append([]int64{issue.ID}, want...)
+	}
+	assigned := issue.ID
+	require.NoError(t, issue.AddAssignee(alice, bob))
+	otherIssue := createLegacyTestIssue(t, otherRepo, bob, "Crash on exit")
+	createLegacyTestIssue(t, otherRepo, bob, "Improve docs")
+
+	t.Run("SearchIssueIDs", func(t *testing.T) {
+		got, err := SearchIssueIDs("crash", []int64{repo.ID})
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+
+		// All repositories are searched if repository IDs are nil
+		got, err = SearchIssueIDs("crash", nil)
+		require.NoError(t, err)
+		assert.Equal(t, append([]int64{otherIssue.ID}, want...), got)
+
+		got, err = SearchIssueIDs("crash", []int64{})
+		require.NoError(t, err)
+		assert.Empty(t, got)
+
+		got, err = SearchIssueIDs("  ", nil)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("IssueRepoIDs", func(t *testing.T) {
+		opts := &IssuesOptions{AssigneeID: bob.ID, IssueIDs: []int64{}}
+		got, err := IssueRepoIDs(opts)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{repo.ID}, got)
+		assert.NotNil(t, opts.IssueIDs)
+
+		got, err = IssueRepoIDs(&IssuesOptions{PosterID: bob.ID})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{otherRepo.ID}, got)
+
+		got, err = IssueRepoIDs(&IssuesOptions{PosterID: bob.ID, IsClosed: true})
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("filter after search", func(t *testing.T) {
+		// Other filters are applied to all matched issues before pagination.
+		opts := &IssuesOptions{AssigneeID: bob.ID}
+		repoIDs, err := IssueRepoIDs(opts)
+		require.NoError(t, err)
+		opts.IssueIDs, err = SearchIssueIDs("crash", repoIDs)
+		require.NoError(t, err)
+
+		issues, err := Issues(opts)
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		assert.Equal(t, assigned, issues[0].ID)
+
+		opts = &IssuesOptions{RepoID: repo.ID, IssueIDs: want, Page: 3}
+		issues, err = Issues(opts)
+		require.NoError(t, err)
+		assert.Len(t, issues, 5)
+		count, err := IssuesCount(opts)
+		require.NoError(t, err)
+		assert.Equal(t, int64(25), count)
+	})
+}
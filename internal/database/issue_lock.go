@@ -0,0 +1,87 @@
+package database
+
+import (
+	"slices"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Reasons of locking the conversation of an issue.
+const (
+	IssueLockReasonOffTopic  = "off_topic"
+	IssueLockReasonTooHeated = "too_heated"
+	IssueLockReasonResolved  = "resolved"
+	IssueLockReasonSpam      = "spam"
+)
+
+// IssueLockReasons is the list of all valid reasons of locking the
+// conversation of an issue.
+var IssueLockReasons = []string{
+	IssueLockReasonOffTopic,
+	IssueLockReasonTooHeated,
+	IssueLockReasonResolved,
+	IssueLockReasonSpam,
+}
+
+// IsValidIssueLockReason returns true if given reason is empty or one of the
+// valid reasons.
+func IsValidIssueLockReason(reason string) bool {
+	return reason == "" || slices.Contains(IssueLockReasons, reason)
+}
+
+// setLocked saves the lock status of the issue along with a timeline comment.
+// This method assumes following fields have been assigned with valid values:
+// Required - Repo
+func (issue *Issue) setLocked(doer *User, isLocked bool, reason string) (err error) {
+	if issue.IsLocked == isLocked {
+		return nil
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if err = issue.Repo.getOwner(sess); err != nil {
+		return errors.Newf("get owner: %v", err)
+	}
+
+	issue.IsLocked = isLocked
+	issue.LockReason = reason
+	if err = updateIssueCols(sess, issue, "is_locked", "lock_reason"); err != nil {
+		return errors.Newf("update issue: %v", err)
+	}
+
+	cmtType := CommentTypeLock
+	if !isLocked {
+		cmtType = CommentTypeUnlock
+	}
+	if _, err = createComment(sess, &CreateCommentOptions{
+		Type:    cmtType,
+		Doer:    doer,
+		Repo:    issue.Repo,
+		Issue:   issue,
+		Content: reason,
+	}); err != nil {
+		return errors.Newf("create comment: %v", err)
+	}
+
+	return sess.Commit()
+}
+
+// Lock locks the conversation of the issue to collaborators with an optional
+// reason. It is a no-op if the issue is already locked.
+// This method assumes following fields have been assigned with valid values:
+// Required - Repo
+func (issue *Issue) Lock(doer *User, reason string) error {
+	return issue.setLocked(doer, true, reason)
+}
+
+// Unlock unlocks the conversation of the issue. It is a no-op if the issue is
+// not locked.
+// This method assumes following fields have been assigned with valid values:
+// Required - Repo
+func (issue *Issue) Unlock(doer *User) error {
+	return issue.setLocked(doer, false, "")
+}
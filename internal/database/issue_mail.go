@@ -101,9 +101,32 @@ func NewMailerIssue(issue *Issue) email.Issue {
 	return mailerIssue{issue}
 }
 
+// notifiedAssignees returns all assignees of the issue, and for a pull request
+// also users and members of teams that review has been requested from.
+func (issue *Issue) notifiedAssignees() ([]*User, error) {
+	users := make([]*User, 0, len(issue.Assignees))
+	users = append(users, issue.Assignees...)
+	if !issue.IsPull || issue.PullRequest == nil {
+		return users, nil
+	}
+
+	if err := issue.PullRequest.LoadAttributes(); err != nil {
+		return nil, errors.Wrap(err, "load pull request attributes")
+	}
+	users = append(users, issue.PullRequest.RequestedReviewers...)
+	for _, team := range issue.PullRequest.RequestedTeams {
+		if err := team.GetMembers(); err != nil {
+			return nil, errors.Wrapf(err, "get members of team %d", team.ID)
+		}
+		users = append(users, team.Members...)
+	}
+	return users, nil
+}
+
 // mailIssueCommentToParticipants can be used for both new issue creation and comment.
 // This functions sends two list of emails:
-// 1. Repository watchers, users who participated in comments and the assignee.
+// 1. Repository watchers, users who participated in comments, assignees and
+// requested reviewers.
 // 2. Users who are not in 1. but get mentioned in current issue/comment.
 func mailIssueCommentToParticipants(issue *Issue, doer *User, mentions []string) error {
 	ctx := context.TODO()
@@ -155,18 +178,34 @@ func mailIssueCommentToParticipants(issue *Issue, doer *User, mentions []string)
 		tos = append(tos, participants[i].Email)
 		names = append(names, participants[i].Name)
 	}
-	if issue.Assignee != nil && issue.Assignee.ID != doer.ID {
-		if !strx.ContainsFold(names, issue.Assignee.Name) {
-			tos = append(tos, issue.Assignee.Email)
-			names = append(names, issue.Assignee.Name)
+	assignees, err := issue.notifiedAssignees()
+	if err != nil {
+		return errors.Wrap(err, "get notified assignees")
+	}
+	for _, assignee := range assignees {
+		if assignee.ID == doer.ID || !assignee.IsActive {
+			continue
+		} else if strx.ContainsFold(names, assignee.Name) {
+			continue
 		}
+
+		tos = append(tos, assignee.Email)
+		names = append(names, assignee.Name)
 	}
 	if err = email.SendIssueCommentMail(NewMailerIssue(issue), NewMailerRepo(issue.Repo), NewMailerUser(doer), tos); err != nil {
 		return errors.Wrap(err, "send issue comment mail")
 	}
 
-	// Mail mentioned people and exclude watchers.
+	// Mail mentioned people and exclude watchers and users who have blocked the
+	// doer.
 	names = append(names, doer.Name)
+	blockers, err := Handle.UserBlocks().ListBlockers(ctx, doer.ID)
+	if err != nil {
+		return errors.Wrap(err, "list blockers")
+	}
+	for _, blocker := range blockers {
+		names = append(names, blocker.Name)
+	}
 	toUsernames := make([]string, 0, len(mentions)) // list of user names.
 	for i := range mentions {
 		if strx.ContainsFold(names, mentions[i]) {
@@ -190,7 +229,7 @@ func mailIssueCommentToParticipants(issue *Issue, doer *User, mentions []string)
 // and mentioned people.
 func (issue *Issue) MailParticipants() (err error) {
 	mentions := markup.FindAllMentions(issue.Content)
-	if err = updateIssueMentions(x, issue.ID, mentions); err != nil {
+	if err = updateIssueMentions(x, issue.PosterID, issue.ID, mentions); err != nil {
 		return errors.Newf("UpdateIssueMentions [%d]: %v", issue.ID, err)
 	}
 
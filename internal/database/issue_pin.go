@@ -0,0 +1,89 @@
+package database
+
+import (
+	"fmt"
+
+	"gogs.io/gogs/internal/errx"
+)
+
+// MaxPinnedIssues is the maximum number of issues (or pull requests) that can
+// be pinned in a repository.
+const MaxPinnedIssues = 3
+
+// IsPinned returns true if the issue is pinned to the top of the issue list.
+func (issue *Issue) IsPinned() bool {
+	return issue.PinOrder > 0
+}
+
+// Pin pins the issue after all other pinned issues of the repository. It
+// returns ErrPinnedIssuesLimit if the number of pinned issues has reached the
+// limit, and is a no-op if the issue is already pinned.
+func (issue *Issue) Pin() (err error) {
+	if issue.IsPinned() {
+		return nil
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	pinned := make([]*Issue, 0, MaxPinnedIssues)
+	if err = sess.Where("repo_id = ? AND is_pull = ? AND pin_order > 0", issue.RepoID, issue.IsPull).Find(&pinned); err != nil {
+		return err
+	} else if len(pinned) >= MaxPinnedIssues {
+		return ErrPinnedIssuesLimit{args: errx.Args{"repoID": issue.RepoID, "limit": MaxPinnedIssues}}
+	}
+
+	issue.PinOrder = 1
+	for _, p := range pinned {
+		if p.PinOrder >= issue.PinOrder {
+			issue.PinOrder = p.PinOrder + 1
+		}
+	}
+	if err = updateIssueCols(sess, issue, "pin_order"); err != nil {
+		return err
+	}
+
+	return sess.Commit()
+}
+
+// Unpin unpins the issue, it is a no-op if the issue is not pinned.
+func (issue *Issue) Unpin() error {
+	if !issue.IsPinned() {
+		return nil
+	}
+
+	issue.PinOrder = 0
+	return updateIssueCols(x, issue, "pin_order")
+}
+
+// GetPinnedIssues returns pinned issues or pull requests of the repository in
+// the order of pinning.
+func GetPinnedIssues(repoID int64, isPull bool) ([]*Issue, error) {
+	issues := make([]*Issue, 0, MaxPinnedIssues)
+	if err := x.Where("repo_id = ? AND is_pull = ? AND pin_order > 0", repoID, isPull).Asc("pin_order").Find(&issues); err != nil {
+		return nil, err
+	}
+
+	for _, issue := range issues {
+		if err := issue.LoadAttributes(); err != nil {
+			return nil, err
+		}
+	}
+	return issues, nil
+}
+
+type ErrPinnedIssuesLimit struct {
+	args errx.Args
+}
+
+func IsErrPinnedIssuesLimit(err error) bool {
+	_, ok := err.(ErrPinnedIssuesLimit)
+	return ok
+}
+
+func (err ErrPinnedIssuesLimit) Error() string {
+	return fmt.Sprintf("maximum number of pinned issues reached: %v", err.args)
+}
@@ -0,0 +1,334 @@
+package database
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	log "unknwon.dev/clog/v2"
+	"xorm.io/xorm"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/errx"
+	apiv1types "gogs.io/gogs/internal/route/api/v1/types"
+)
+
+// Reaction represents an emoji reaction of a user on an issue or a comment.
+type Reaction struct {
+	ID          int64
+	Type        string    `xorm:"VARCHAR(32) UNIQUE(s) NOT NULL"`
+	IssueID     int64     `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	CommentID   int64     `xorm:"UNIQUE(s) INDEX"` // Zero for reactions on the issue itself.
+	UserID      int64     `xorm:"UNIQUE(s) NOT NULL"`
+	User        *User     `xorm:"-" json:"-" gorm:"-"`
+	Created     time.Time `xorm:"-" json:"-" gorm:"-"`
+	CreatedUnix int64
+}
+
+func (r *Reaction) BeforeInsert() {
+	r.CreatedUnix = time.Now().Unix()
+}
+
+func (r *Reaction) AfterSet(colName string, _ xorm.Cell) {
+	switch colName {
+	case "created_unix":
+		r.Created = time.Unix(r.CreatedUnix, 0).Local()
+	}
+}
+
+// APIFormat returns the API format of the reaction, the user must be loaded in
+// advance.
+func (r *Reaction) APIFormat() *apiv1types.Reaction {
+	return &apiv1types.Reaction{
+		ID:      r.ID,
+		User:    r.User.APIFormat(),
+		Content: r.Type,
+		Created: r.Created,
+	}
+}
+
+// IsAllowedReaction returns true if given type is in the configured set of
+// allowed reactions.
+func IsAllowedReaction(typ string) bool {
+	return slices.Contains(conf.UI.Reactions, typ)
+}
+
+// ReactionList is a list of reactions.
+type ReactionList []*Reaction
+
+// LoadUsers loads users of all reactions in the list, reactions of deleted
+// users are assigned to the ghost user.
+func (list ReactionList) LoadUsers() error {
+	users := make(map[int64]*User)
+	for _, r := range list {
+		if r.User != nil {
+			continue
+		}
+
+		u, ok := users[r.UserID]
+		if !ok {
+			var err error
+			u, err = getUserByID(x, r.UserID)
+			if IsErrUserNotExist(err) {
+				u = NewGhostUser()
+			} else if err != nil {
+				return errors.Newf("get user by ID [%d]: %v", r.UserID, err)
+			}
+			users[r.UserID] = u
+		}
+		r.User = u
+	}
+	return nil
+}
+
+// ReactionGroup represents reactions of the same type.
+type ReactionGroup struct {
+	Type  string
+	Users []*User
+}
+
+// HasUser returns true if given user is one of the users who reacted.
+func (g *ReactionGroup) HasUser(userID int64) bool {
+	for _, u := range g.Users {
+		if u.ID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// UserNames returns comma-separated names of users who reacted.
+func (g *ReactionGroup) UserNames() string {
+	names := make([]string, len(g.Users))
+	for i := range g.Users {
+		names[i] = g.Users[i].Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// Groups returns reactions grouped by type in the order of their first
+// appearance, users must be loaded in advance.
+func (list ReactionList) Groups() []*ReactionGroup {
+	groups := make([]*ReactionGroup, 0, len(list))
+	indexes := make(map[string]int)
+	for _, r := range list {
+		i, ok := indexes[r.Type]
+		if !ok {
+			i = len(groups)
+			indexes[r.Type] = i
+			groups = append(groups, &ReactionGroup{Type: r.Type})
+		}
+		groups[i].Users = append(groups[i].Users, r.User)
+	}
+	return groups
+}
+
+func getReactions(e Engine, issueID, commentID int64) (ReactionList, error) {
+	reactions := make(ReactionList, 0, 5)
+	return reactions, e.Where("issue_id = ? AND comment_id = ?", issueID, commentID).Asc("id").Find(&reactions)
+}
+
+// GetIssueReactions returns reactions on the issue itself.
+func GetIssueReactions(issueID int64) (ReactionList, error) {
+	return getReactions(x, issueID, 0)
+}
+
+// GetCommentReactions returns reactions on the comment.
+func GetCommentReactions(comment *Comment) (ReactionList, error) {
+	return getReactions(x, comment.IssueID, comment.ID)
+}
+
+// GetReactionsByIssueID returns reactions on the issue and all of its
+// comments.
+func GetReactionsByIssueID(issueID int64) (ReactionList, error) {
+	reactions := make(ReactionList, 0, 10)
+	return reactions, x.Where("issue_id = ?", issueID).Asc("id").Find(&reactions)
+}
+
+// getReaction fills the reaction that matches the type, issue, comment and
+// user of given reaction. The comment ID is matched explicitly because zero
+// values are ignored as conditions.
+func getReaction(e Engine, r *Reaction) (bool, error) {
+	return e.Where("type = ? AND issue_id = ? AND comment_id = ? AND user_id = ?", r.Type, r.IssueID, r.CommentID, r.UserID).Get(r)
+}
+
+func (issue *Issue) sendReactionWebhook(doer *User, comment *Comment, reaction *Reaction, isRemove bool) {
+	var err error
+	if comment != nil {
+		action := apiv1types.WebhookIssueCommentReacted
+		if isRemove {
+			action = apiv1types.WebhookIssueCommentUnreacted
+		}
+		err = PrepareWebhooks(issue.Repo, HookEventTypeIssueComment, &apiv1types.WebhookIssueCommentPayload{
+			Action:     action,
+			Issue:      issue.APIFormat(),
+			Comment:    comment.APIFormat(),
+			Reaction:   reaction.APIFormat(),
+			Repository: issue.Repo.APIFormatLegacy(nil),
+			Sender:     doer.APIFormat(),
+		})
+		if err != nil {
+			log.Error("PrepareWebhooks [comment_id: %d]: %v", comment.ID, err)
+		}
+		return
+	}
+
+	action := apiv1types.WebhookIssueReacted
+	if isRemove {
+		action = apiv1types.WebhookIssueUnreacted
+	}
+	if issue.IsPull {
+		issue.PullRequest.Issue = issue
+		err = PrepareWebhooks(issue.Repo, HookEventTypePullRequest, &apiv1types.WebhookPullRequestPayload{
+			Action:      action,
+			Index:       issue.Index,
+			PullRequest: issue.PullRequest.APIFormat(),
+			Reaction:    reaction.APIFormat(),
+			Repository:  issue.Repo.APIFormatLegacy(nil),
+			Sender:      doer.APIFormat(),
+		})
+	} else {
+		err = PrepareWebhooks(issue.Repo, HookEventTypeIssues, &apiv1types.WebhookIssuesPayload{
+			Action:     action,
+			Index:      issue.Index,
+			Issue:      issue.APIFormat(),
+			Reaction:   reaction.APIFormat(),
+			Repository: issue.Repo.APIFormatLegacy(nil),
+			Sender:     doer.APIFormat(),
+		})
+	}
+	if err != nil {
+		log.Error("PrepareWebhooks [is_pull: %v, remove_reaction: %v]: %v", issue.IsPull, isRemove, err)
+	}
+}
+
+func (issue *Issue) addReaction(doer *User, comment *Comment, typ string) (_ *Reaction, err error) {
+	if !IsAllowedReaction(typ) {
+		return nil, ErrInvalidReaction{args: errx.Args{"type": typ}}
+	}
+	if err = ensureRepoOwnerNotBlocked(issue.RepoID, doer.ID); err != nil {
+		return nil, err
+	}
+
+	r := &Reaction{
+		Type:    typ,
+		IssueID: issue.ID,
+		UserID:  doer.ID,
+	}
+	if comment != nil {
+		r.CommentID = comment.ID
+	}
+	has, err := getReaction(x, r)
+	if err != nil {
+		return nil, err
+	} else if has {
+		r.User = doer
+		return r, nil
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	if _, err = sess.Insert(r); err != nil {
+		return nil, err
+	}
+	if comment == nil {
+		if _, err = sess.Exec("UPDATE `issue` SET num_reactions = num_reactions + 1 WHERE id = ?", issue.ID); err != nil {
+			return nil, err
+		}
+		issue.NumReactions++
+	}
+
+	if err = sess.Commit(); err != nil {
+		return nil, errors.Newf("commit: %v", err)
+	}
+
+	r.User = doer
+	issue.sendReactionWebhook(doer, comment, r, false)
+	return r, nil
+}
+
+func (issue *Issue) removeReaction(doer *User, comment *Comment, typ string) (err error) {
+	r := &Reaction{
+		Type:    typ,
+		IssueID: issue.ID,
+		UserID:  doer.ID,
+	}
+	if comment != nil {
+		r.CommentID = comment.ID
+	}
+	has, err := getReaction(x, r)
+	if err != nil {
+		return err
+	} else if !has {
+		return nil
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err = sess.ID(r.ID).Delete(new(Reaction)); err != nil {
+		return err
+	}
+	if comment == nil {
+		if _, err = sess.Exec("UPDATE `issue` SET num_reactions = num_reactions - 1 WHERE id = ?", issue.ID); err != nil {
+			return err
+		}
+		issue.NumReactions--
+	}
+
+	if err = sess.Commit(); err != nil {
+		return errors.Newf("commit: %v", err)
+	}
+
+	r.User = doer
+	issue.sendReactionWebhook(doer, comment, r, true)
+	return nil
+}
+
+// AddReaction adds a reaction of given type to the issue on behalf of the
+// doer, it is a no-op if the doer has already reacted with the same type. It
+// returns ErrInvalidReaction if the type is not allowed.
+func (issue *Issue) AddReaction(doer *User, typ string) (*Reaction, error) {
+	return issue.addReaction(doer, nil, typ)
+}
+
+// RemoveReaction removes the reaction of given type from the issue on behalf
+// of the doer.
+func (issue *Issue) RemoveReaction(doer *User, typ string) error {
+	return issue.removeReaction(doer, nil, typ)
+}
+
+// AddCommentReaction adds a reaction of given type to the comment of the
+// issue on behalf of the doer, it is a no-op if the doer has already reacted
+// with the same type. It returns ErrInvalidReaction if the type is not allowed.
+func (issue *Issue) AddCommentReaction(doer *User, comment *Comment, typ string) (*Reaction, error) {
+	return issue.addReaction(doer, comment, typ)
+}
+
+// RemoveCommentReaction removes the reaction of given type from the comment of
+// the issue on behalf of the doer.
+func (issue *Issue) RemoveCommentReaction(doer *User, comment *Comment, typ string) error {
+	return issue.removeReaction(doer, comment, typ)
+}
+
+type ErrInvalidReaction struct {
+	args errx.Args
+}
+
+func IsErrInvalidReaction(err error) bool {
+	_, ok := err.(ErrInvalidReaction)
+	return ok
+}
+
+func (err ErrInvalidReaction) Error() string {
+	return fmt.Sprintf("reaction is not allowed: %v", err.args)
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/errx"
+)
+
+func TestIssue_Reactions(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	newLegacyTestDB(t, "TestIssue_Reactions")
+	conf.SetMockUI(t, conf.UIOpts{Reactions: []string{"+1", "-1", "heart"}})
+
+	alice := createLegacyTestUser(t, "alice")
+	bob := createLegacyTestUser(t, "bob")
+	cindy := createLegacyTestUser(t, "cindy")
+	repo := createLegacyTestRepo(t, alice, "repo", false)
+	issue := createLegacyTestIssue(t, repo, alice, "Fix bug")
+	comment := &Comment{Type: CommentTypeComment, IssueID: issue.ID, Issue: issue, PosterID: bob.ID, Poster: bob, Content: "Me too"}
+	_, err := x.Insert(comment)
+	require.NoError(t, err)
+
+	// assertNumReactions checks the number of reactions on the issue itself is
+	// consistent between the issue in memory, the counter in the database and
+	// the actual reactions.
+	assertNumReactions := func(t *testing.T, want int) {
+		t.Helper()
+
+		assert.Equal(t, want, issue.NumReactions)
+		got, err := GetIssueByID(issue.ID)
+		require.NoError(t, err)
+		assert.Equal(t, want, got.NumReactions)
+		reactions, err := GetIssueReactions(issue.ID)
+		require.NoError(t, err)
+		assert.Len(t, reactions, want)
+	}
+
+	t.Run("not allowed", func(t *testing.T) {
+		for _, typ := range []string{"", "laugh", "HEART"} {
+			_, err := issue.AddReaction(bob, typ)
+			wantErr := ErrInvalidReaction{args: errx.Args{"type": typ}}
+			assert.Equal(t, wantErr, err)
+
+			_, err = issue.AddCommentReaction(bob, comment, typ)
+			assert.Equal(t, wantErr, err)
+		}
+		assertNumReactions(t, 0)
+	})
+
+	t.Run("blocked by repository owner", func(t *testing.T) {
+		require.NoError(t, Handle.UserBlocks().Block(t.Context(), alice.ID, cindy.ID))
+		_, err := issue.AddReaction(cindy, "+1")
+		assert.True(t, IsErrBlockedByUser(err))
+		assertNumReactions(t, 0)
+		require.NoError(t, Handle.UserBlocks().Unblock(t.Context(), alice.ID, cindy.ID))
+	})
+
+	t.Run("add to issue", func(t *testing.T) {
+		r, err := issue.AddReaction(bob, "+1")
+		require.NoError(t, err)
+		assert.Equal(t, bob, r.User)
+		_, err = issue.AddReaction(bob, "heart")
+		require.NoError(t, err)
+		_, err = issue.AddReaction(cindy, "+1")
+		require.NoError(t, err)
+		assertNumReactions(t, 3)
+
+		// Adding a duplicate reaction is a no-op and returns the existing one
+		got, err := issue.AddReaction(bob, "+1")
+		require.NoError(t, err)
+		assert.Equal(t, r.ID, got.ID)
+		assertNumReactions(t, 3)
+
+		reactions, err := GetIssueReactions(issue.ID)
+		require.NoError(t, err)
+		require.NoError(t, reactions.LoadUsers())
+		groups := reactions.Groups()
+		require.Len(t, groups, 2)
+		assert.Equal(t, "+1", groups[0].Type)
+		assert.Equal(t, "bob, cindy", groups[0].UserNames())
+		assert.Equal(t, "heart", groups[1].Type)
+		assert.True(t, groups[1].HasUser(bob.ID))
+		assert.False(t, groups[1].HasUser(cindy.ID))
+	})
+
+	t.Run("add to comment", func(t *testing.T) {
+		r, err := issue.AddCommentReaction(bob, comment, "+1")
+		require.NoError(t, err)
+		assert.Equal(t, comment.ID, r.CommentID)
+
+		got, err := issue.AddCommentReaction(bob, comment, "+1")
+		require.NoError(t, err)
+		assert.Equal(t, r.ID, got.ID)
+
+		// Reactions on comments are not counted for the issue
+		reactions, err := GetCommentReactions(comment)
+		require.NoError(t, err)
+		assert.Len(t, reactions, 1)
+		assertNumReactions(t, 3)
+
+		reactions, err = GetReactionsByIssueID(issue.ID)
+		require.NoError(t, err)
+		assert.Len(t, reactions, 4)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		require.NoError(t, issue.RemoveReaction(bob, "+1"))
+		assertNumReactions(t, 2)
+
+		// Removing a reaction that does not exist is a no-op
+		require.NoError(t, issue.RemoveReaction(bob, "+1"))
+		require.NoError(t, issue.RemoveReaction(alice, "heart"))
+		assertNumReactions(t, 2)
+
+		// The same type of reaction on the comment is kept
+		reactions, err := GetCommentReactions(comment)
+		require.NoError(t, err)
+		assert.Len(t, reactions, 1)
+
+		require.NoError(t, issue.RemoveCommentReaction(bob, comment, "+1"))
+		reactions, err = GetCommentReactions(comment)
+		require.NoError(t, err)
+		assert.Empty(t, reactions)
+		assertNumReactions(t, 2)
+	})
+
+	t.Run("LoadUsers", func(t *testing.T) {
+		_, err := x.ID(cindy.ID).Delete(new(User))
+		require.NoError(t, err)
+
+		reactions, err := GetIssueReactions(issue.ID)
+		require.NoError(t, err)
+		require.NoError(t, reactions.LoadUsers())
+		names := make([]string, 0, len(reactions))
+		for _, r := range reactions {
+			names = append(names, r.User.Name)
+		}
+		assert.Equal(t, []string{"bob", NewGhostUser().Name}, names)
+	})
+}
@@ -0,0 +1,491 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+
+	"gogs.io/gogs/internal/errx"
+	apiv1types "gogs.io/gogs/internal/route/api/v1/types"
+)
+
+// TrackedTime represents a period of time that a user has spent on an issue.
+type TrackedTime struct {
+	ID          int64
+	IssueID     int64     `xorm:"INDEX"`
+	Issue       *Issue    `xorm:"-" json:"-" gorm:"-"`
+	UserID      int64     `xorm:"INDEX"`
+	User        *User     `xorm:"-" json:"-" gorm:"-"`
+	Time        int64     // Duration in seconds.
+	Created     time.Time `xorm:"-" json:"-" gorm:"-"`
+	CreatedUnix int64     `xorm:"INDEX"`
+}
+
+func (t *TrackedTime) BeforeInsert() {
+	if t.CreatedUnix == 0 {
+		t.CreatedUnix = time.Now().Unix()
+	}
+}
+
+func (t *TrackedTime) AfterSet(colName string, _ xorm.Cell) {
+	switch colName {
+	case "created_unix":
+		t.Created = time.Unix(t.CreatedUnix, 0).Local()
+	}
+}
+
+// LoadAttributes loads the issue with its repository and the user of the
+// tracked time.
+func (t *TrackedTime) LoadAttributes() (err error) {
+	if t.Issue == nil {
+		t.Issue, err = getRawIssueByID(x, t.IssueID)
+		if err != nil {
+			return errors.Newf("get issue by ID [%d]: %v", t.IssueID, err)
+		}
+	}
+	if t.Issue.Repo == nil {
+		t.Issue.Repo, err = getRepositoryByID(x, t.Issue.RepoID)
+		if err != nil {
+			return errors.Newf("get repository by ID [%d]: %v", t.Issue.RepoID, err)
+		}
+	}
+
+	if t.User == nil {
+		t.User, err = getUserByID(x, t.UserID)
+		if IsErrUserNotExist(err) {
+			t.User = NewGhostUser()
+		} else if err != nil {
+			return errors.Newf("get user by ID [%d]: %v", t.UserID, err)
+		}
+	}
+	return nil
+}
+
+// TrackedTimeList is a list of tracked times.
+type TrackedTimeList []*TrackedTime
+
+// LoadAttributes loads issues with their repositories and users of all tracked
+// times in the list, tracked times of deleted users are assigned to the ghost
+// user.
+func (times TrackedTimeList) LoadAttributes() error {
+	if len(times) == 0 {
+		return nil
+	}
+
+	// Load issues
+	issueSet := make(map[int64]*Issue)
+	for _, t := range times {
+		if t.Issue != nil {
+			issueSet[t.IssueID] = t.Issue
+		}
+	}
+	issueIDs := make([]int64, 0, len(times))
+	for _, t := range times {
+		if _, ok := issueSet[t.IssueID]; !ok {
+			issueSet[t.IssueID] = nil
+			issueIDs = append(issueIDs, t.IssueID)
+		}
+	}
+	if len(issueIDs) > 0 {
+		issues := make([]*Issue, 0, len(issueIDs))
+		if err := x.In("id", issueIDs).Find(&issues); err != nil {
+			return errors.Newf("find issues: %v", err)
+		}
+		for i := range issues {
+			issueSet[issues[i].ID] = issues[i]
+		}
+	}
+	for _, t := range times {
+		t.Issue = issueSet[t.IssueID]
+		if t.Issue == nil {
+			return ErrIssueNotExist{args: errx.Args{"issueID": t.IssueID}}
+		}
+	}
+
+	// Load repositories
+	repoSet := make(map[int64]*Repository)
+	for _, issue := range issueSet {
+		if issue.Repo != nil {
+			repoSet[issue.RepoID] = issue.Repo
+		}
+	}
+	repoIDs := make([]int64, 0, len(issueSet))
+	for _, issue := range issueSet {
+		if _, ok := repoSet[issue.RepoID]; !ok {
+			repoSet[issue.RepoID] = nil
+			repoIDs = append(repoIDs, issue.RepoID)
+		}
+	}
+	if len(repoIDs) > 0 {
+		repos := make([]*Repository, 0, len(repoIDs))
+		if err := x.In("id", repoIDs).Find(&repos); err != nil {
+			return errors.Newf("find repositories: %v", err)
+		}
+		if err := RepositoryList(repos).loadAttributes(x); err != nil {
+			return errors.Newf("load attributes of repositories: %v", err)
+		}
+		for i := range repos {
+			repoSet[repos[i].ID] = repos[i]
+		}
+	}
+	for _, issue := range issueSet {
+		issue.Repo = repoSet[issue.RepoID]
+		if issue.Repo == nil {
+			return ErrRepoNotExist{args: errx.Args{"repoID": issue.RepoID}}
+		}
+	}
+
+	// Load users
+	userSet := make(map[int64]*User)
+	userIDs := make([]int64, 0, len(times))
+	for _, t := range times {
+		if t.User != nil {
+			continue
+		}
+		if _, ok := userSet[t.UserID]; !ok {
+			userSet[t.UserID] = nil
+			userIDs = append(userIDs, t.UserID)
+		}
+	}
+	if len(userIDs) > 0 {
+		users := make([]*User, 0, len(userIDs))
+		if err := x.In("id", userIDs).Find(&users); err != nil {
+			return errors.Newf("find users: %v", err)
+		}
+		for i := range users {
+			userSet[users[i].ID] = users[i]
+		}
+	}
+	for _, t := range times {
+		if t.User != nil {
+			continue
+		}
+		t.User = userSet[t.UserID]
+		if t.User == nil {
+			t.User = NewGhostUser()
+		}
+	}
+	return nil
+}
+
+// APIFormat returns the API format of the tracked time, attributes must be
+// loaded in advance.
+func (t *TrackedTime) APIFormat() *apiv1types.TrackedTime {
+	return &apiv1types.TrackedTime{
+		ID:         t.ID,
+		Created:    t.Created,
+		Time:       t.Time,
+		UserID:     t.UserID,
+		UserName:   t.User.Name,
+		IssueID:    t.IssueID,
+		IssueIndex: t.Issue.Index,
+		Repo:       t.Issue.Repo.FullName(),
+	}
+}
+
+// AddTrackedTime adds a period of time that the user has spent on the issue.
+// The time is considered to be spent at now if created is zero.
+func (issue *Issue) AddTrackedTime(doer *User, seconds int64, created time.Time) (*TrackedTime, error) {
+	if seconds <= 0 {
+		return nil, ErrInvalidTrackedTime{args: errx.Args{"issueID": issue.ID, "time": seconds}}
+	}
+
+	t := &TrackedTime{
+		IssueID: issue.ID,
+		UserID:  doer.ID,
+		Time:    seconds,
+	}
+	if !created.IsZero() {
+		t.CreatedUnix = created.Unix()
+	}
+	if _, err := x.Insert(t); err != nil {
+		return nil, err
+	}
+	t.Created = time.Unix(t.CreatedUnix, 0).Local()
+	return t, nil
+}
+
+// GetTrackedTimeByID returns the tracked time of the issue by given ID.
+func (issue *Issue) GetTrackedTimeByID(id int64) (*TrackedTime, error) {
+	t := new(TrackedTime)
+	has, err := x.Where("id = ? AND issue_id = ?", id, issue.ID).Get(t)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrTrackedTimeNotExist{args: errx.Args{"issueID": issue.ID, "trackedTimeID": id}}
+	}
+	return t, nil
+}
+
+// DeleteTrackedTime deletes the tracked time of the issue by given ID.
+func (issue *Issue) DeleteTrackedTime(id int64) error {
+	_, err := x.Delete(&TrackedTime{ID: id, IssueID: issue.ID})
+	return err
+}
+
+// TrackedTimesOptions contains options to filter tracked times, zero values
+// are ignored.
+type TrackedTimesOptions struct {
+	IssueID     int64
+	UserID      int64
+	RepoID      int64
+	MilestoneID int64
+	Since       time.Time
+	Before      time.Time
+	Page        int
+	PageSize    int // All tracked times are returned if zero.
+}
+
+func (opts *TrackedTimesOptions) toCond() builder.Cond {
+	cond := builder.NewCond()
+	if opts.IssueID > 0 {
+		cond = cond.And(builder.Eq{"tracked_time.issue_id": opts.IssueID})
+	}
+	if opts.UserID > 0 {
+		cond = cond.And(builder.Eq{"tracked_time.user_id": opts.UserID})
+	}
+	if opts.RepoID > 0 {
+		cond = cond.And(builder.Eq{"issue.repo_id": opts.RepoID})
+	}
+	if opts.MilestoneID > 0 {
+		cond = cond.And(builder.Eq{"issue.milestone_id": opts.MilestoneID})
+	}
+	if !opts.Since.IsZero() {
+		cond = cond.And(builder.Gte{"tracked_time.created_unix": opts.Since.Unix()})
+	}
+	if !opts.Before.IsZero() {
+		cond = cond.And(builder.Lt{"tracked_time.created_unix": opts.Before.Unix()})
+	}
+	return cond
+}
+
+// GetTrackedTimes returns tracked times that match the options in the order
+// of time spent.
+func GetTrackedTimes(opts TrackedTimesOptions) (TrackedTimeList, error) {
+	sess := x.Join("INNER", "issue", "issue.id = tracked_time.issue_id").
+		Where(opts.toCond()).
+		Asc("tracked_time.created_unix", "tracked_time.id")
+	if opts.PageSize > 0 {
+		if opts.Page <= 0 {
+			opts.Page = 1
+		}
+		sess.Limit(opts.PageSize, (opts.Page-1)*opts.PageSize)
+	}
+
+	times := make([]*TrackedTime, 0, 10)
+	return times, sess.Find(&times)
+}
+
+// CountTrackedTimes returns the number of tracked times that match the
+// options, pagination options are ignored.
+func CountTrackedTimes(opts TrackedTimesOptions) (int64, error) {
+	return x.Join("INNER", "issue", "issue.id = tracked_time.issue_id").
+		Where(opts.toCond()).
+		Count(new(TrackedTime))
+}
+
+// TotalTrackedTime returns the total seconds of tracked times that match the
+// options.
+func TotalTrackedTime(opts TrackedTimesOptions) (int64, error) {
+	total, err := x.Join("INNER", "issue", "issue.id = tracked_time.issue_id").
+		Where(opts.toCond()).
+		SumInt(new(TrackedTime), "tracked_time.time")
+	return total, err
+}
+
+// TrackedTimeByUser is the total seconds of tracked times of a user.
+type TrackedTimeByUser struct {
+	User *User
+	Time int64
+}
+
+// GetTrackedTimesByUser returns the total seconds of tracked times of each user
+// on the issue in the order of first tracked.
+func (issue *Issue) GetTrackedTimesByUser() ([]*TrackedTimeByUser, error) {
+	times, err := GetTrackedTimes(TrackedTimesOptions{IssueID: issue.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make([]*TrackedTimeByUser, 0, 2)
+	userTotals := make(map[int64]*TrackedTimeByUser)
+	for _, t := range times {
+		if total, ok := userTotals[t.UserID]; ok {
+			total.Time += t.Time
+			continue
+		}
+
+		total := &TrackedTimeByUser{Time: t.Time}
+		total.User, err = getUserByID(x, t.UserID)
+		if IsErrUserNotExist(err) {
+			total.User = NewGhostUser()
+		} else if err != nil {
+			return nil, errors.Newf("get user by ID [%d]: %v", t.UserID, err)
+		}
+		userTotals[t.UserID] = total
+		totals = append(totals, total)
+	}
+	return totals, nil
+}
+
+// ChangeTimeEstimate changes the estimated time to complete the issue in
+// seconds, zero means no estimate.
+func (issue *Issue) ChangeTimeEstimate(seconds int64) error {
+	if seconds < 0 {
+		return ErrInvalidTrackedTime{args: errx.Args{"issueID": issue.ID, "time": seconds}}
+	}
+
+	issue.TimeEstimate = seconds
+	_, err := x.ID(issue.ID).Cols("time_estimate").Update(issue)
+	return err
+}
+
+// Stopwatch represents a running timer of a user on an issue.
+type Stopwatch struct {
+	ID          int64
+	IssueID     int64     `xorm:"UNIQUE(s)"`
+	UserID      int64     `xorm:"UNIQUE(s) INDEX"`
+	Created     time.Time `xorm:"-" json:"-" gorm:"-"`
+	CreatedUnix int64
+}
+
+func (s *Stopwatch) BeforeInsert() {
+	s.CreatedUnix = time.Now().Unix()
+}
+
+func (s *Stopwatch) AfterSet(colName string, _ xorm.Cell) {
+	switch colName {
+	case "created_unix":
+		s.Created = time.Unix(s.CreatedUnix, 0).Local()
+	}
+}
+
+// Seconds returns the seconds elapsed since the stopwatch was started.
+func (s *Stopwatch) Seconds() int64 {
+	return time.Now().Unix() - s.CreatedUnix
+}
+
+// GetStopwatch returns the running stopwatch of the user on the issue.
+func (issue *Issue) GetStopwatch(userID int64) (*Stopwatch, error) {
+	s := new(Stopwatch)
+	has, err := x.Where("issue_id = ? AND user_id = ?", issue.ID, userID).Get(s)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrStopwatchNotExist{args: errx.Args{"issueID": issue.ID, "userID": userID}}
+	}
+	return s, nil
+}
+
+// StartStopwatch starts a stopwatch of the user on the issue, it does nothing
+// if the stopwatch is already running.
+func (issue *Issue) StartStopwatch(doer *User) error {
+	_, err := issue.GetStopwatch(doer.ID)
+	if err == nil {
+		return nil
+	} else if !IsErrStopwatchNotExist(err) {
+		return err
+	}
+
+	_, err = x.Insert(&Stopwatch{
+		IssueID: issue.ID,
+		UserID:  doer.ID,
+	})
+	return err
+}
+
+// StopStopwatch stops the running stopwatch of the user on the issue and adds
+// the elapsed time as a tracked time. It returns ErrStopwatchNotExist if no
+// stopwatch is running.
+func (issue *Issue) StopStopwatch(doer *User) (*TrackedTime, error) {
+	s, err := issue.GetStopwatch(doer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	if _, err = sess.ID(s.ID).Delete(new(Stopwatch)); err != nil {
+		return nil, errors.Newf("delete stopwatch: %v", err)
+	}
+
+	// Stopwatches stopped in less than a second are discarded.
+	var t *TrackedTime
+	if seconds := s.Seconds(); seconds > 0 {
+		t = &TrackedTime{
+			IssueID: issue.ID,
+			UserID:  doer.ID,
+			Time:    seconds,
+		}
+		if _, err = sess.Insert(t); err != nil {
+			return nil, errors.Newf("insert tracked time: %v", err)
+		}
+		t.Created = time.Unix(t.CreatedUnix, 0).Local()
+	}
+	return t, sess.Commit()
+}
+
+// CancelStopwatch stops the running stopwatch of the user on the issue without
+// adding a tracked time.
+func (issue *Issue) CancelStopwatch(doer *User) error {
+	_, err := x.Delete(&Stopwatch{IssueID: issue.ID, UserID: doer.ID})
+	return err
+}
+
+var _ errx.NotFound = (*ErrTrackedTimeNotExist)(nil)
+
+type ErrTrackedTimeNotExist struct {
+	args errx.Args
+}
+
+func IsErrTrackedTimeNotExist(err error) bool {
+	_, ok := err.(ErrTrackedTimeNotExist)
+	return ok
+}
+
+func (err ErrTrackedTimeNotExist) Error() string {
+	return fmt.Sprintf("tracked time does not exist: %v", err.args)
+}
+
+func (ErrTrackedTimeNotExist) NotFound() bool {
+	return true
+}
+
+type ErrInvalidTrackedTime struct {
+	args errx.Args
+}
+
+func IsErrInvalidTrackedTime(err error) bool {
+	_, ok := err.(ErrInvalidTrackedTime)
+	return ok
+}
+
+func (err ErrInvalidTrackedTime) Error() string {
+	return fmt.Sprintf("tracked time is invalid: %v", err.args)
+}
+
+var _ errx.NotFound = (*ErrStopwatchNotExist)(nil)
+
+type ErrStopwatchNotExist struct {
+	args errx.Args
+}
+
+func IsErrStopwatchNotExist(err error) bool {
+	_, ok := err.(ErrStopwatchNotExist)
+	return ok
+}
+
+func (err ErrStopwatchNotExist) Error() string {
+	return fmt.Sprintf("stopwatch does not exist: %v", err.args)
+}
+
+func (ErrStopwatchNotExist) NotFound() bool {
+	return true
+}
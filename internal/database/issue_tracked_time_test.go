@@ -0,0 +1,147 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/errx"
+)
+
+func TestIssue_TrackedTimes(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	newLegacyTestDB(t, "TestIssue_TrackedTimes")
+
+	alice := createLegacyTestUser(t, "alice")
+	bob := createLegacyTestUser(t, "bob")
+	repo := createLegacyTestRepo(t, alice, "repo", false)
+	issue := createLegacyTestIssue(t, repo, alice, "Fix bug")
+	otherIssue := createLegacyTestIssue(t, repo, alice, "Add feature")
+
+	created := time.Unix(time.Now().Unix()-3600, 0)
+
+	t.Run("AddTrackedTime", func(t *testing.T) {
+		for _, seconds := range []int64{0, -60} {
+			_, err := issue.AddTrackedTime(alice, seconds, time.Time{})
+			wantErr := ErrInvalidTrackedTime{args: errx.Args{"issueID": issue.ID, "time": seconds}}
+			assert.Equal(t, wantErr, err)
+		}
+
+		got, err := issue.AddTrackedTime(alice, 90, created)
+		require.NoError(t, err)
+		assert.Equal(t, created.Unix(), got.CreatedUnix)
+		assert.Equal(t, created.Unix(), got.Created.Unix())
+
+		// Time is considered to be spent at now when created is zero
+		got, err = issue.AddTrackedTime(bob, 60, time.Time{})
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), got.Created, time.Minute)
+
+		_, err = otherIssue.AddTrackedTime(alice, 30, created)
+		require.NoError(t, err)
+	})
+
+	t.Run("StopStopwatch", func(t *testing.T) {
+		_, err := issue.StopStopwatch(alice)
+		wantErr := ErrStopwatchNotExist{args: errx.Args{"issueID": issue.ID, "userID": alice.ID}}
+		assert.Equal(t, wantErr, err)
+
+		// Stopwatches stopped in less than a second are discarded
+		require.NoError(t, issue.StartStopwatch(alice))
+		_, err = x.Where("issue_id = ? AND user_id = ?", issue.ID, alice.ID).Cols("created_unix").Update(&Stopwatch{CreatedUnix: time.Now().Unix() + 60})
+		require.NoError(t, err)
+		got, err := issue.StopStopwatch(alice)
+		require.NoError(t, err)
+		assert.Nil(t, got)
+
+		require.NoError(t, issue.StartStopwatch(alice))
+		// Starting a running stopwatch is a no-op
+		require.NoError(t, issue.StartStopwatch(alice))
+		_, err = x.Where("issue_id = ? AND user_id = ?", issue.ID, alice.ID).Cols("created_unix").Update(&Stopwatch{CreatedUnix: time.Now().Unix() - 120})
+		require.NoError(t, err)
+		got, err = issue.StopStopwatch(alice)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, alice.ID, got.UserID)
+		assert.InDelta(t, 120, got.Time, 5)
+
+		_, err = issue.GetStopwatch(alice.ID)
+		assert.True(t, IsErrStopwatchNotExist(err))
+
+		// The stopped time is recorded as a tracked time
+		total, err := TotalTrackedTime(TrackedTimesOptions{IssueID: issue.ID, UserID: alice.ID})
+		require.NoError(t, err)
+		assert.Equal(t, 90+got.Time, total)
+
+		require.NoError(t, issue.DeleteTrackedTime(got.ID))
+	})
+
+	t.Run("GetTrackedTimes", func(t *testing.T) {
+		opts := TrackedTimesOptions{RepoID: repo.ID, PageSize: 2}
+		count, err := CountTrackedTimes(opts)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+
+		var times []int64
+		for opts.Page = 1; opts.Page <= 2; opts.Page++ {
+			got, err := GetTrackedTimes(opts)
+			require.NoError(t, err)
+			for _, tt := range got {
+				times = append(times, tt.Time)
+			}
+		}
+		// Tracked times are in the order of time spent
+		assert.Equal(t, []int64{90, 30, 60}, times)
+
+		got, err := GetTrackedTimes(TrackedTimesOptions{UserID: alice.ID, Before: created.Add(time.Second)})
+		require.NoError(t, err)
+		assert.Len(t, got, 2)
+	})
+
+	t.Run("LoadAttributes", func(t *testing.T) {
+		times, err := GetTrackedTimes(TrackedTimesOptions{RepoID: repo.ID})
+		require.NoError(t, err)
+		require.Len(t, times, 3)
+
+		_, err = x.ID(bob.ID).Delete(new(User))
+		require.NoError(t, err)
+		require.NoError(t, times.LoadAttributes())
+
+		got := make([]string, 0, len(times))
+		for _, tt := range times {
+			apiTime := tt.APIFormat()
+			got = append(got, apiTime.UserName)
+			assert.Equal(t, "alice/repo", apiTime.Repo)
+		}
+		assert.Equal(t, []string{"alice", "alice", NewGhostUser().Name}, got)
+		assert.Equal(t, issue.Index, times[0].Issue.Index)
+		assert.Equal(t, otherIssue.Index, times[1].Issue.Index)
+		// The same issue is loaded once
+		assert.Same(t, times[0].Issue, times[2].Issue)
+	})
+
+	t.Run("Milestone", func(t *testing.T) {
+		milestone := &Milestone{RepoID: repo.ID, Name: "v1.0"}
+		_, err := x.Insert(milestone)
+		require.NoError(t, err)
+
+		issue.MilestoneID = milestone.ID
+		issue.TimeEstimate = 600
+		_, err = x.ID(issue.ID).Cols("milestone_id", "time_estimate").Update(issue)
+		require.NoError(t, err)
+		require.NoError(t, otherIssue.ChangeTimeEstimate(300))
+
+		// Only tracked times and estimates of issues in the milestone are summed
+		assert.Equal(t, int64(150), milestone.SumTrackedTime())
+		assert.Equal(t, int64(600), milestone.SumTimeEstimate())
+
+		err = otherIssue.ChangeTimeEstimate(-1)
+		wantErr := ErrInvalidTrackedTime{args: errx.Args{"issueID": otherIssue.ID, "time": int64(-1)}}
+		assert.Equal(t, wantErr, err)
+	})
+}
@@ -0,0 +1,238 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+
+	"gogs.io/gogs/internal/errx"
+)
+
+// IssueRedirect represents a redirect from the old index of an issue that has
+// been transferred to another repository.
+type IssueRedirect struct {
+	ID        int64
+	OldRepoID int64 `xorm:"UNIQUE(s)"`
+	OldIndex  int64 `xorm:"UNIQUE(s)"`
+	IssueID   int64 `xorm:"INDEX"`
+}
+
+var _ errx.NotFound = (*ErrIssueRedirectNotExist)(nil)
+
+type ErrIssueRedirectNotExist struct {
+	args errx.Args
+}
+
+func IsErrIssueRedirectNotExist(err error) bool {
+	_, ok := err.(ErrIssueRedirectNotExist)
+	return ok
+}
+
+func (err ErrIssueRedirectNotExist) Error() string {
+	return fmt.Sprintf("issue redirect does not exist: %v", err.args)
+}
+
+func (ErrIssueRedirectNotExist) NotFound() bool {
+	return true
+}
+
+// GetIssueRedirect returns the issue that was transferred from given index of
+// the repository.
+func GetIssueRedirect(repoID, index int64) (*Issue, error) {
+	redirect := new(IssueRedirect)
+	has, err := x.Where("old_repo_id = ? AND old_index = ?", repoID, index).Get(redirect)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrIssueRedirectNotExist{args: errx.Args{"repoID": repoID, "index": index}}
+	}
+
+	issue, err := GetIssueByID(redirect.IssueID)
+	if err != nil {
+		if IsErrIssueNotExist(err) {
+			return nil, ErrIssueRedirectNotExist{args: errx.Args{"repoID": repoID, "index": index}}
+		}
+		return nil, err
+	}
+	return issue, nil
+}
+
+// TransferIssue moves the issue to another repository along with its comments
+// and attachments. Labels are mapped to labels of the new repository by name,
+// and assignees who do not have access to the new repository are removed. The
+// old index of the issue redirects to the new one.
+// This method assumes following fields have been assigned with valid values:
+// Required - Repo
+func TransferIssue(doer *User, issue *Issue, newRepo *Repository) (err error) {
+	if issue.IsPull {
+		return ErrTransferIssue{args: errx.Args{"issueID": issue.ID, "reason": "pull requests cannot be transferred"}}
+	} else if issue.RepoID == newRepo.ID {
+		return ErrTransferIssue{args: errx.Args{"issueID": issue.ID, "reason": "issue is already in the repository"}}
+	}
+	if err = newRepo.GetOwner(); err != nil {
+		return errors.Newf("get owner of new repository: %v", err)
+	}
+
+	oldRepo := issue.Repo
+	oldIndex := issue.Index
+
+	oldLabels, err := getLabelsByIssueID(x, issue.ID)
+	if err != nil {
+		return errors.Newf("get labels by issue ID: %v", err)
+	}
+	newRepoLabels, err := GetLabelsByRepoID(newRepo.ID)
+	if err != nil {
+		return errors.Newf("get labels by repository ID: %v", err)
+	}
+	labelsByName := make(map[string]*Label, len(newRepoLabels))
+	for _, label := range newRepoLabels {
+		labelsByName[strings.ToLower(label.Name)] = label
+	}
+	newLabels := make([]*Label, 0, len(oldLabels))
+	for _, label := range oldLabels {
+		if l, ok := labelsByName[strings.ToLower(label.Name)]; ok {
+			newLabels = append(newLabels, l)
+		}
+	}
+
+	assignees, err := getAssigneesByIssueID(x, issue.ID)
+	if err != nil {
+		return errors.Newf("get assignees by issue ID: %v", err)
+	}
+	keptAssignees := make([]*User, 0, len(assignees))
+	removedAssigneeIDs := make([]int64, 0, len(assignees))
+	for _, assignee := range assignees {
+		if Handle.Permissions().Authorize(
+			context.TODO(),
+			assignee.ID,
+			newRepo.ID,
+			AccessModeRead,
+			AccessModeOptions{
+				OwnerID: newRepo.OwnerID,
+				Private: newRepo.IsPrivate,
+			},
+		) {
+			keptAssignees = append(keptAssignees, assignee)
+		} else {
+			removedAssigneeIDs = append(removedAssigneeIDs, assignee.ID)
+		}
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	// Labels and milestone belong to the old repository. The labels of the issue
+	// are copied because deleting a label removes it from the slice in place.
+	issue.Labels = append([]*Label(nil), oldLabels...)
+	for _, label := range oldLabels {
+		if err = deleteIssueLabel(sess, issue, label); err != nil {
+			return errors.Newf("delete issue label: %v", err)
+		}
+	}
+	if oldMilestoneID := issue.MilestoneID; oldMilestoneID > 0 {
+		issue.MilestoneID = 0
+		if err = changeMilestoneAssign(sess, issue, oldMilestoneID); err != nil {
+			return errors.Newf("clear milestone: %v", err)
+		}
+	}
+	issue.Assignees = assignees
+	for _, assigneeID := range removedAssigneeIDs {
+		if err = issue.removeAssignee(sess, assigneeID); err != nil {
+			return errors.Newf("remove assignee [%d]: %v", assigneeID, err)
+		}
+	}
+
+	if _, err = sess.Exec("UPDATE `repository` SET num_issues = num_issues - 1, num_transferred_issues = num_transferred_issues + 1 WHERE id = ?", oldRepo.ID); err != nil {
+		return err
+	}
+	if _, err = sess.Exec("UPDATE `repository` SET num_issues = num_issues + 1 WHERE id = ?", newRepo.ID); err != nil {
+		return err
+	}
+	if issue.IsClosed {
+		if _, err = sess.Exec("UPDATE `repository` SET num_closed_issues = num_closed_issues - 1 WHERE id = ?", oldRepo.ID); err != nil {
+			return err
+		}
+		if _, err = sess.Exec("UPDATE `repository` SET num_closed_issues = num_closed_issues + 1 WHERE id = ?", newRepo.ID); err != nil {
+			return err
+		}
+	}
+
+	issue.RepoID = newRepo.ID
+	issue.Repo = newRepo
+	issue.Index = newRepo.NextIssueIndex()
+	issue.PinOrder = 0
+	if err = updateIssueCols(sess, issue, "repo_id", "index", "pin_order"); err != nil {
+		return errors.Newf("update issue: %v", err)
+	}
+
+	// Recreate issue-user relations for users of the new repository.
+	if _, err = sess.Delete(&IssueUser{IssueID: issue.ID}); err != nil {
+		return err
+	}
+	if err = newIssueUsers(sess, newRepo, issue); err != nil {
+		return errors.Newf("new issue users: %v", err)
+	}
+	for _, assignee := range keptAssignees {
+		if err = updateIssueUserByAssignee(sess, issue, assignee.ID, true); err != nil {
+			return errors.Newf("update issue user by assignee [%d]: %v", assignee.ID, err)
+		}
+	}
+	if err = updateIssueUsersByStatus(sess, issue.ID, issue.IsClosed); err != nil {
+		return errors.Newf("update issue users by status: %v", err)
+	}
+
+	if err = newIssueLabels(sess, issue, newLabels); err != nil {
+		return errors.Newf("new issue labels: %v", err)
+	}
+
+	if _, err = sess.Insert(&IssueRedirect{
+		OldRepoID: oldRepo.ID,
+		OldIndex:  oldIndex,
+		IssueID:   issue.ID,
+	}); err != nil {
+		return errors.Newf("insert issue redirect: %v", err)
+	}
+
+	if _, err = createComment(sess, &CreateCommentOptions{
+		Type:    CommentTypeTransfer,
+		Doer:    doer,
+		Repo:    newRepo,
+		Issue:   issue,
+		Content: fmt.Sprintf("%s#%d", oldRepo.FullName(), oldIndex),
+	}); err != nil {
+		return errors.Newf("create comment: %v", err)
+	}
+
+	if err = sess.Commit(); err != nil {
+		return errors.Newf("commit: %v", err)
+	}
+	updateIssueIndexer(issue.ID)
+
+	// Update the cached counters of given repositories.
+	oldRepo.NumIssues--
+	oldRepo.NumTransferredIssues++
+	newRepo.NumIssues++
+	if issue.IsClosed {
+		oldRepo.NumClosedIssues--
+		newRepo.NumClosedIssues++
+	}
+	return nil
+}
+
+type ErrTransferIssue struct {
+	args errx.Args
+}
+
+func IsErrTransferIssue(err error) bool {
+	_, ok := err.(ErrTransferIssue)
+	return ok
+}
+
+func (err ErrTransferIssue) Error() string {
+	return fmt.Sprintf("issue cannot be transferred: %v", err.args)
+}
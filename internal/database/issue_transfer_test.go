@@ -0,0 +1,155 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/errx"
+)
+
+func TestTransferIssue(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	newLegacyTestDB(t, "TestTransferIssue")
+
+	alice := createLegacyTestUser(t, "alice")
+	bob := createLegacyTestUser(t, "bob")
+	cindy := createLegacyTestUser(t, "cindy")
+	oldRepo := createLegacyTestRepo(t, alice, "old", false)
+	newRepo := createLegacyTestRepo(t, alice, "new", true)
+	err := Handle.Permissions().SetRepoPerms(t.Context(), oldRepo.ID, map[int64]AccessMode{bob.ID: AccessModeWrite, cindy.ID: AccessModeWrite})
+	require.NoError(t, err)
+	err = Handle.Permissions().SetRepoPerms(t.Context(), newRepo.ID, map[int64]AccessMode{bob.ID: AccessModeRead})
+	require.NoError(t, err)
+
+	// The new repository has an issue already, so transferred issues get the
+	// next index.
+	createLegacyTestIssue(t, newRepo, alice, "Existing")
+
+	bug := &Label{RepoID: oldRepo.ID, Name: "bug"}
+	duplicate := &Label{RepoID: oldRepo.ID, Name: "duplicate"}
+	oldOnly := &Label{RepoID: oldRepo.ID, Name: "wontfix"}
+	newBug := &Label{RepoID: newRepo.ID, Name: "Bug"}
+	_, err = x.Insert(bug, duplicate, oldOnly, newBug)
+	require.NoError(t, err)
+
+	milestone := &Milestone{RepoID: oldRepo.ID, Name: "v1.0"}
+	require.NoError(t, NewMilestone(milestone))
+
+	issue := createLegacyTestIssue(t, oldRepo, alice, "Fix bug")
+	oldIndex := issue.Index
+	require.NoError(t, NewIssueLabels(issue, []*Label{bug, duplicate, oldOnly}))
+	issue.MilestoneID = milestone.ID
+	require.NoError(t, ChangeMilestoneAssign(alice, issue, 0))
+	require.NoError(t, issue.AddAssignee(alice, cindy))
+	require.NoError(t, issue.AddAssignee(alice, bob))
+
+	t.Run("invalid", func(t *testing.T) {
+		pr := createLegacyTestPullRequest(t, oldRepo, alice, "feature")
+		err := TransferIssue(alice, pr.Issue, newRepo)
+		wantErr := ErrTransferIssue{args: errx.Args{"issueID": pr.IssueID, "reason": "pull requests cannot be transferred"}}
+		assert.Equal(t, wantErr, err)
+
+		err = TransferIssue(alice, issue, oldRepo)
+		wantErr = ErrTransferIssue{args: errx.Args{"issueID": issue.ID, "reason": "issue is already in the repository"}}
+		assert.Equal(t, wantErr, err)
+	})
+
+	t.Run("transfer", func(t *testing.T) {
+		require.NoError(t, TransferIssue(alice, issue, newRepo))
+		assert.Equal(t, newRepo.ID, issue.RepoID)
+		assert.Equal(t, int64(2), issue.Index)
+
+		got, err := GetIssueByIndex(newRepo.ID, 2)
+		require.NoError(t, err)
+		assert.Equal(t, issue.ID, got.ID)
+		assert.Zero(t, got.MilestoneID)
+		_, err = GetIssueByIndex(oldRepo.ID, oldIndex)
+		assert.True(t, IsErrIssueNotExist(err))
+
+		// Counters of both repositories are updated in memory and in the database
+		for _, repo := range []*Repository{oldRepo, newRepo} {
+			got, err := GetRepositoryByID(repo.ID)
+			require.NoError(t, err)
+			assert.Equal(t, repo.NumIssues, got.NumIssues)
+			assert.Equal(t, repo.NumTransferredIssues, got.NumTransferredIssues)
+		}
+		assert.Zero(t, oldRepo.NumIssues)
+		assert.Equal(t, 1, oldRepo.NumTransferredIssues)
+		assert.Equal(t, 2, newRepo.NumIssues)
+		// The old index is not reused
+		assert.Equal(t, int64(oldIndex+2), oldRepo.NextIssueIndex())
+
+		// Labels are mapped by name, case-insensitively
+		labels, err := GetLabelsByIssueID(issue.ID)
+		require.NoError(t, err)
+		require.Len(t, labels, 1)
+		assert.Equal(t, newBug.ID, labels[0].ID)
+		for _, label := range []*Label{bug, duplicate, oldOnly, newBug} {
+			got, err := GetLabelByID(label.ID)
+			require.NoError(t, err)
+			wantNumIssues := 0
+			if label.ID == newBug.ID {
+				wantNumIssues = 1
+			}
+			assert.Equal(t, wantNumIssues, got.NumIssues, label.Name)
+		}
+
+		// The milestone belongs to the old repository
+		gotMilestone, err := GetMilestoneByRepoID(oldRepo.ID, milestone.ID)
+		require.NoError(t, err)
+		assert.Zero(t, gotMilestone.NumIssues)
+
+		// Assignees without access to the new repository are removed
+		assignees, err := GetAssigneesByIssueID(issue.ID)
+		require.NoError(t, err)
+		require.Len(t, assignees, 1)
+		assert.Equal(t, bob.ID, assignees[0].ID)
+		assert.Equal(t, bob.ID, got.AssigneeID)
+
+		issueUser := &IssueUser{IssueID: issue.ID, UserID: bob.ID}
+		has, err := x.Get(issueUser)
+		require.NoError(t, err)
+		require.True(t, has)
+		assert.Equal(t, newRepo.ID, issueUser.RepoID)
+		assert.True(t, issueUser.IsAssigned)
+		has, err = x.Get(&IssueUser{IssueID: issue.ID, UserID: cindy.ID})
+		require.NoError(t, err)
+		assert.False(t, has)
+
+		// The old index redirects to the issue
+		redirected, err := GetIssueRedirect(oldRepo.ID, oldIndex)
+		require.NoError(t, err)
+		assert.Equal(t, issue.ID, redirected.ID)
+		assert.Equal(t, newRepo.ID, redirected.Repo.ID)
+		_, err = GetIssueRedirect(oldRepo.ID, oldIndex+1)
+		assert.True(t, IsErrIssueRedirectNotExist(err))
+
+		comment := lastComment(t, issue.ID)
+		assert.Equal(t, CommentTypeTransfer, comment.Type)
+		assert.Equal(t, "alice/old#1", comment.Content)
+	})
+
+	t.Run("closed issue", func(t *testing.T) {
+		closed := createLegacyTestIssue(t, oldRepo, alice, "Closed")
+		require.NoError(t, closed.ChangeStatus(alice, oldRepo, true))
+		oldRepo, err := GetRepositoryByID(oldRepo.ID)
+		require.NoError(t, err)
+		closed.Repo = oldRepo
+		require.Equal(t, 1, oldRepo.NumClosedIssues)
+
+		require.NoError(t, TransferIssue(alice, closed, newRepo))
+		for _, repo := range []*Repository{oldRepo, newRepo} {
+			got, err := GetRepositoryByID(repo.ID)
+			require.NoError(t, err)
+			assert.Equal(t, repo.NumClosedIssues, got.NumClosedIssues)
+		}
+		assert.Zero(t, oldRepo.NumClosedIssues)
+		assert.Equal(t, 1, newRepo.NumClosedIssues)
+		assert.Equal(t, 2, oldRepo.NumTransferredIssues)
+	})
+}
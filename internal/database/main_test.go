@@ -4,11 +4,15 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"os/exec"
+	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 	log "unknwon.dev/clog/v2"
+	"xorm.io/core"
 
 	"gogs.io/gogs/internal/conf"
 	"gogs.io/gogs/internal/dbtest"
@@ -50,6 +54,163 @@ func newTestDB(t *testing.T, suite string) *gorm.DB {
 	return dbtest.NewDB(t, suite, append(Tables, legacyTables...)...)
 }
 
+// newLegacyTestDB creates a new test database like newTestDB, and points the
+// XORM engine and Handle to it for testing functions that still use them.
+// Tests using it must not run in parallel because both are global, and must
+// wait for goroutines they started to finish.
+func newLegacyTestDB(t *testing.T, suite string) *gorm.DB {
+	db := newTestDB(t, suite)
+
+	opts := conf.Database
+	switch {
+	case conf.UseMySQL:
+		conf.Database = conf.DatabaseOpts{
+			Type:     "mysql",
+			Host:     os.ExpandEnv("$MYSQL_HOST:$MYSQL_PORT"),
+			User:     os.Getenv("MYSQL_USER"),
+			Password: os.Getenv("MYSQL_PASSWORD"),
+		}
+		require.NoError(t, db.Raw("SELECT DATABASE()").Scan(&conf.Database.Name).Error)
+	case conf.UsePostgreSQL:
+		conf.Database = conf.DatabaseOpts{
+			Type:     "postgres",
+			Host:     os.ExpandEnv("$PGHOST:$PGPORT"),
+			Schema:   "public",
+			User:     os.Getenv("PGUSER"),
+			Password: os.Getenv("PGPASSWORD"),
+			SSLMode:  os.Getenv("PGSSLMODE"),
+		}
+		require.NoError(t, db.Raw("SELECT current_database()").Scan(&conf.Database.Name).Error)
+	default:
+		conf.Database = conf.DatabaseOpts{Type: "sqlite3"}
+		require.NoError(t, db.Raw("SELECT file FROM pragma_database_list WHERE name = 'main'").Scan(&conf.Database.Path).Error)
+	}
+	engine, err := getEngine()
+	conf.Database = opts
+	require.NoError(t, err)
+	if conf.UsePostgreSQL {
+		engine.SetSchema("public")
+	}
+	engine.SetMapper(core.GonicMapper{})
+	engine.ShowSQL(testing.Verbose())
+
+	// Legacy tables are created by XORM in production, whose column names are
+	// not always the same as GORM would use, e.g. "whitelist_user_ids".
+	require.NoError(t, db.Migrator().DropTable(legacyTables...))
+	require.NoError(t, engine.StoreEngine("InnoDB").Sync2(legacyTables...))
+
+	oldX, oldHandle := x, Handle
+	x, Handle = engine, &DB{db: db}
+	t.Cleanup(func() {
+		x, Handle = oldX, oldHandle
+		_ = engine.Close()
+	})
+	return db
+}
+
+// runTestGit runs the Git command in given directory as a fixed identity and
+// returns its output.
+func runTestGit(t *testing.T, dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=alice", "GIT_AUTHOR_EMAIL=alice@example.com",
+		"GIT_COMMITTER_NAME=alice", "GIT_COMMITTER_EMAIL=alice@example.com",
+	)
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+	return string(output)
+}
+
+// createLegacyTestUser creates a user with given name via the XORM engine.
+func createLegacyTestUser(t *testing.T, name string) *User {
+	user := &User{
+		Name:      name,
+		LowerName: strings.ToLower(name),
+		Email:     name + "@example.com",
+	}
+	_, err := x.Insert(user)
+	require.NoError(t, err)
+	return user
+}
+
+// createLegacyTestRepo creates a repository of the owner with given name via
+// the XORM engine.
+func createLegacyTestRepo(t *testing.T, owner *User, name string, private bool) *Repository {
+	repo := &Repository{
+		OwnerID:         owner.ID,
+		Owner:           owner,
+		Name:            name,
+		LowerName:       strings.ToLower(name),
+		IsPrivate:       private,
+		EnableIssues:    true,
+		EnablePulls:     true,
+		PullsAllowMerge: true,
+	}
+	_, err := x.Insert(repo)
+	require.NoError(t, err)
+	return repo
+}
+
+// createLegacyTestIssue creates the next issue of the repository posted by the
+// poster via the XORM engine.
+func createLegacyTestIssue(t *testing.T, repo *Repository, poster *User, title string) *Issue {
+	issue := &Issue{
+		RepoID:   repo.ID,
+		Repo:     repo,
+		Index:    repo.NextIssueIndex(),
+		PosterID: poster.ID,
+		Poster:   poster,
+		Title:    title,
+	}
+	_, err := x.Insert(issue)
+	require.NoError(t, err)
+
+	repo.NumIssues++
+	_, err = x.ID(repo.ID).Cols("num_issues").Update(repo)
+	require.NoError(t, err)
+	return issue
+}
+
+// createLegacyTestPullRequest creates the next pull request of the repository
+// posted by the poster from the head branch to the "main" branch via the XORM
+// engine.
+func createLegacyTestPullRequest(t *testing.T, repo *Repository, poster *User, headBranch string) *PullRequest {
+	issue := &Issue{
+		RepoID:   repo.ID,
+		Repo:     repo,
+		Index:    repo.NextIssueIndex(),
+		PosterID: poster.ID,
+		Poster:   poster,
+		Title:    "Update " + headBranch,
+		IsPull:   true,
+	}
+	_, err := x.Insert(issue)
+	require.NoError(t, err)
+
+	repo.NumPulls++
+	_, err = x.ID(repo.ID).Cols("num_pulls").Update(repo)
+	require.NoError(t, err)
+
+	pr := &PullRequest{
+		Status:       PullRequestStatusMergeable,
+		IssueID:      issue.ID,
+		Issue:        issue,
+		Index:        issue.Index,
+		HeadRepoID:   repo.ID,
+		HeadRepo:     repo,
+		BaseRepoID:   repo.ID,
+		BaseRepo:     repo,
+		HeadUserName: repo.Owner.Name,
+		HeadBranch:   headBranch,
+		BaseBranch:   "main",
+	}
+	_, err = x.Insert(pr)
+	require.NoError(t, err)
+	issue.PullRequest = pr
+	return pr
+}
+
 func clearTables(t *testing.T, db *gorm.DB) error {
 	if t.Failed() {
 		return nil
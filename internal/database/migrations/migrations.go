@@ -59,6 +59,10 @@ var migrations = []Migration{
 	// on v22. Let's make a noop v22 to make sure every instance will not miss a
 	// real future migration.
 	NewMigration("noop", func(*gorm.DB) error { return nil }),
+	// v22 -> v23:v0.15.0+dev
+	NewMigration("add OAuth2 columns to access_token", addOAuth2ColumnsToAccessToken),
+	// v23 -> v24:v0.15.0+dev
+	NewMigration("migrate issue assignees to issue_assignee", migrateIssueAssignees),
 }
 
 var errMigrationSkipped = errors.New("the migration has been skipped")
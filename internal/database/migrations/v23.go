@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"github.com/cockroachdb/errors"
+	"gorm.io/gorm"
+)
+
+func addOAuth2ColumnsToAccessToken(db *gorm.DB) error {
+	type accessToken struct {
+		GrantID     int64 `gorm:"index;not null;default:0"`
+		ExpiresUnix int64 `gorm:"not null;default:0"`
+	}
+	if db.Migrator().HasColumn(&accessToken{}, "GrantID") {
+		return errMigrationSkipped
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, field := range []string{"GrantID", "ExpiresUnix"} {
+			err := tx.Migrator().AddColumn(&accessToken{}, field)
+			if err != nil {
+				return errors.Wrapf(err, "add column %q", field)
+			}
+		}
+
+		err := tx.Migrator().CreateIndex(&accessToken{}, "GrantID")
+		if err != nil {
+			return errors.Wrap(err, "create index")
+		}
+		return nil
+	})
+}
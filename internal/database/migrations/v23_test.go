@@ -0,0 +1,78 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/dbtest"
+)
+
+type accessTokenPreV23 struct {
+	ID          int64
+	UserID      int64 `gorm:"column:uid;index"`
+	Name        string
+	Sha1        string `gorm:"type:VARCHAR(40);unique"`
+	SHA256      string `gorm:"type:VARCHAR(64);unique;not null"`
+	CreatedUnix int64
+	UpdatedUnix int64
+}
+
+func (*accessTokenPreV23) TableName() string {
+	return "access_token"
+}
+
+type accessTokenV23 struct {
+	ID          int64
+	UserID      int64 `gorm:"column:uid;index"`
+	Name        string
+	Sha1        string `gorm:"type:VARCHAR(40);unique"`
+	SHA256      string `gorm:"type:VARCHAR(64);unique;not null"`
+	GrantID     int64  `gorm:"index;not null;default:0"`
+	ExpiresUnix int64  `gorm:"not null;default:0"`
+	CreatedUnix int64
+	UpdatedUnix int64
+}
+
+func (*accessTokenV23) TableName() string {
+	return "access_token"
+}
+
+func TestAddOAuth2ColumnsToAccessToken(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+
+	db := dbtest.NewDB(t, "addOAuth2ColumnsToAccessToken", new(accessTokenPreV23))
+	err := db.Create(
+		&accessTokenPreV23{
+			ID:          1,
+			UserID:      1,
+			Name:        "test",
+			Sha1:        "73da7bb9d2a475bbc2ab79da7d4e94940cb9f9d5",
+			SHA256:      "ab144c7bd170691cb6b84a2acbf0f2a1f2bbd1c4a4b2dd9fee2e4b6c4f11f1c0",
+			CreatedUnix: db.NowFunc().Unix(),
+			UpdatedUnix: db.NowFunc().Unix(),
+		},
+	).Error
+	require.NoError(t, err)
+	assert.False(t, db.Migrator().HasColumn(&accessTokenV23{}, "GrantID"))
+
+	err = addOAuth2ColumnsToAccessToken(db)
+	require.NoError(t, err)
+	assert.True(t, db.Migrator().HasColumn(&accessTokenV23{}, "GrantID"))
+	assert.True(t, db.Migrator().HasColumn(&accessTokenV23{}, "ExpiresUnix"))
+	assert.True(t, db.Migrator().HasIndex(&accessTokenV23{}, "GrantID"))
+
+	var got accessTokenV23
+	err = db.Where("id = ?", 1).First(&got).Error
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), got.GrantID)
+	assert.Equal(t, int64(0), got.ExpiresUnix)
+
+	// Re-run should be skipped
+	err = addOAuth2ColumnsToAccessToken(db)
+	require.Equal(t, errMigrationSkipped, err)
+}
@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"github.com/cockroachdb/errors"
+	"gorm.io/gorm"
+)
+
+func migrateIssueAssignees(db *gorm.DB) error {
+	type issueAssignee struct {
+		ID         int64
+		IssueID    int64 `gorm:"uniqueIndex:UQE_issue_assignee_s"`
+		AssigneeID int64 `gorm:"uniqueIndex:UQE_issue_assignee_s;index:IDX_issue_assignee_assignee_id"`
+	}
+	if db.Migrator().HasTable(&issueAssignee{}) {
+		return errMigrationSkipped
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Migrator().CreateTable(&issueAssignee{})
+		if err != nil {
+			return errors.Wrap(err, "create table")
+		}
+
+		err = tx.Exec("INSERT INTO issue_assignee (issue_id, assignee_id) SELECT id, assignee_id FROM issue WHERE assignee_id > 0").Error
+		if err != nil {
+			return errors.Wrap(err, "copy assignees")
+		}
+		return nil
+	})
+}
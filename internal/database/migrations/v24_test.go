@@ -0,0 +1,64 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/dbtest"
+)
+
+type issuePreV24 struct {
+	ID         int64 `gorm:"primaryKey"`
+	RepoID     int64
+	Index      int64
+	AssigneeID int64 `gorm:"index"`
+}
+
+func (*issuePreV24) TableName() string {
+	return "issue"
+}
+
+type issueAssigneeV24 struct {
+	ID         int64
+	IssueID    int64 `gorm:"uniqueIndex:UQE_issue_assignee_s"`
+	AssigneeID int64 `gorm:"uniqueIndex:UQE_issue_assignee_s;index:IDX_issue_assignee_assignee_id"`
+}
+
+func (*issueAssigneeV24) TableName() string {
+	return "issue_assignee"
+}
+
+func TestMigrateIssueAssignees(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+
+	db := dbtest.NewDB(t, "migrateIssueAssignees", new(issuePreV24))
+	err := db.Create(
+		[]*issuePreV24{
+			{ID: 1, RepoID: 1, Index: 1, AssigneeID: 2},
+			{ID: 2, RepoID: 1, Index: 2},
+		},
+	).Error
+	require.NoError(t, err)
+	assert.False(t, db.Migrator().HasTable(&issueAssigneeV24{}))
+
+	err = migrateIssueAssignees(db)
+	require.NoError(t, err)
+	assert.True(t, db.Migrator().HasTable(&issueAssigneeV24{}))
+	assert.True(t, db.Migrator().HasIndex(&issueAssigneeV24{}, "UQE_issue_assignee_s"))
+
+	var got []*issueAssigneeV24
+	err = db.Order("id").Find(&got).Error
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, int64(1), got[0].IssueID)
+	assert.Equal(t, int64(2), got[0].AssigneeID)
+
+	// Re-run should be skipped
+	err = migrateIssueAssignees(db)
+	require.Equal(t, errMigrationSkipped, err)
+}
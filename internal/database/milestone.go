@@ -33,6 +33,9 @@ type Milestone struct {
 	DeadlineUnix   int64
 	ClosedDate     time.Time `xorm:"-" json:"-" gorm:"-"`
 	ClosedDateUnix int64
+
+	TrackedTime  int64 `xorm:"-" json:"-" gorm:"-"` // Total seconds tracked on issues.
+	TimeEstimate int64 `xorm:"-" json:"-" gorm:"-"` // Total seconds estimated for issues.
 }
 
 func (m *Milestone) BeforeInsert() {
@@ -101,6 +104,20 @@ func (m *Milestone) APIFormat() *apiv1types.IssueMilestone {
 	return apiMilestone
 }
 
+// SumTrackedTime returns the total seconds of tracked times on issues and pull
+// requests in the milestone.
+func (m *Milestone) SumTrackedTime() int64 {
+	total, _ := TotalTrackedTime(TrackedTimesOptions{MilestoneID: m.ID})
+	return total
+}
+
+// SumTimeEstimate returns the total seconds of estimates of issues and pull
+// requests in the milestone.
+func (m *Milestone) SumTimeEstimate() int64 {
+	total, _ := x.Where("milestone_id = ?", m.ID).SumInt(new(Issue), "time_estimate")
+	return total
+}
+
 func (m *Milestone) CountIssues(isClosed, includePulls bool) int64 {
 	sess := x.Where("milestone_id = ?", m.ID).And("is_closed = ?", isClosed)
 	if !includePulls {
@@ -415,6 +415,9 @@ func SyncMirrors() {
 
 		if len(results) == 0 {
 			log.Trace("SyncMirrors [repo_id: %d]: no commits fetched", m.RepoID)
+		} else {
+			UpdateCodeIndexer(m.RepoID)
+			UpdateLanguageStats(m.RepoID)
 		}
 
 		gitRepo, err := git.Open(m.Repo.RepoPath())
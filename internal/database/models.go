@@ -53,9 +53,13 @@ func init() {
 		new(Repository), new(DeployKey), new(Collaboration), new(Upload),
 		new(Watch), new(Star),
 		new(Issue), new(PullRequest), new(Comment), new(Attachment), new(IssueUser),
+		new(IssueAssignee), new(ReviewRequest), new(IssueDependency),
+		new(CodeOwner), new(CodeOwnerSignOff),
+		new(TrackedTime), new(Stopwatch), new(Reaction), new(IssueRedirect),
 		new(Label), new(IssueLabel), new(Milestone),
-		new(Mirror), new(Release), new(Webhook), new(HookTask),
-		new(ProtectBranch), new(ProtectBranchWhitelist),
+		new(Mirror), new(Release), new(Webhook), new(HookTask), new(LanguageStat),
+		new(Topic), new(RepoTopic),
+		new(ProtectBranch), new(ProtectBranchWhitelist), new(ProtectTag),
 		new(Team), new(OrgUser), new(TeamUser), new(TeamRepo),
 	)
 
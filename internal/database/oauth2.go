@@ -0,0 +1,790 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/cryptox"
+	"gogs.io/gogs/internal/errx"
+	"gogs.io/gogs/internal/strx"
+)
+
+// OAuth2Application is an application registered by a user to act on behalf of
+// other users through the OAuth2 authorization code flow.
+type OAuth2Application struct {
+	ID                 int64  `gorm:"primaryKey"`
+	UserID             int64  `gorm:"index;not null"`
+	Name               string `gorm:"not null"`
+	ClientID           string `gorm:"type:VARCHAR(36);unique;not null"`
+	ClientSecretSHA256 string `gorm:"type:VARCHAR(64);not null"`
+	// The newline-separated list of allowed redirect URIs.
+	RedirectURIs string `gorm:"type:TEXT;not null"`
+
+	// The raw client secret, only set right after the secret is generated.
+	ClientSecret string `gorm:"-" json:"-"`
+
+	Created     time.Time `gorm:"-" json:"-"`
+	CreatedUnix int64
+	Updated     time.Time `gorm:"-" json:"-"`
+	UpdatedUnix int64
+}
+
+func (*OAuth2Application) TableName() string {
+	return "oauth2_application"
+}
+
+// BeforeCreate implements the GORM create hook.
+func (app *OAuth2Application) BeforeCreate(tx *gorm.DB) error {
+	if app.CreatedUnix == 0 {
+		app.CreatedUnix = tx.NowFunc().Unix()
+		app.UpdatedUnix = app.CreatedUnix
+	}
+	return nil
+}
+
+// AfterFind implements the GORM query hook.
+func (app *OAuth2Application) AfterFind(_ *gorm.DB) error {
+	app.Created = time.Unix(app.CreatedUnix, 0).Local()
+	app.Updated = time.Unix(app.UpdatedUnix, 0).Local()
+	return nil
+}
+
+// RedirectURIList returns the list of allowed redirect URIs of the application.
+func (app *OAuth2Application) RedirectURIList() []string {
+	if app.RedirectURIs == "" {
+		return nil
+	}
+	return strings.Split(app.RedirectURIs, "\n")
+}
+
+// HasRedirectURI returns true if the URI is one of the allowed redirect URIs of
+// the application.
+func (app *OAuth2Application) HasRedirectURI(uri string) bool {
+	for _, u := range app.RedirectURIList() {
+		if u == uri {
+			return true
+		}
+	}
+	return false
+}
+
+// OAuth2Grant is the authorization that a user has given to an application.
+type OAuth2Grant struct {
+	ID            int64 `gorm:"primaryKey"`
+	UserID        int64 `gorm:"uniqueIndex:oauth2_grant_user_application_unique;not null"`
+	ApplicationID int64 `gorm:"uniqueIndex:oauth2_grant_user_application_unique;index;not null"`
+
+	Application *OAuth2Application `gorm:"-" json:"-"`
+
+	Created     time.Time `gorm:"-" json:"-"`
+	CreatedUnix int64
+	Updated     time.Time `gorm:"-" json:"-"`
+	UpdatedUnix int64
+}
+
+func (*OAuth2Grant) TableName() string {
+	return "oauth2_grant"
+}
+
+// BeforeCreate implements the GORM create hook.
+func (g *OAuth2Grant) BeforeCreate(tx *gorm.DB) error {
+	if g.CreatedUnix == 0 {
+		g.CreatedUnix = tx.NowFunc().Unix()
+		g.UpdatedUnix = g.CreatedUnix
+	}
+	return nil
+}
+
+// AfterFind implements the GORM query hook.
+func (g *OAuth2Grant) AfterFind(_ *gorm.DB) error {
+	g.Created = time.Unix(g.CreatedUnix, 0).Local()
+	g.Updated = time.Unix(g.UpdatedUnix, 0).Local()
+	return nil
+}
+
+// OAuth2AuthorizationCode is a short-lived and single-use code that can be
+// exchanged for tokens of a grant.
+type OAuth2AuthorizationCode struct {
+	ID         int64  `gorm:"primaryKey"`
+	GrantID    int64  `gorm:"index;not null"`
+	CodeSHA256 string `gorm:"type:VARCHAR(64);unique;not null"`
+	// The redirect URI given in the authorization request, which must be given
+	// again when exchanging the code.
+	RedirectURI         string `gorm:"type:TEXT;not null"`
+	CodeChallenge       string `gorm:"type:VARCHAR(128);not null"`
+	CodeChallengeMethod string `gorm:"type:VARCHAR(10);not null"`
+	ExpiresUnix         int64  `gorm:"not null"`
+}
+
+func (*OAuth2AuthorizationCode) TableName() string {
+	return "oauth2_authorization_code"
+}
+
+// OAuth2RefreshToken is a token that can be used to obtain a new access token
+// of a grant.
+type OAuth2RefreshToken struct {
+	ID      int64 `gorm:"primaryKey"`
+	GrantID int64 `gorm:"index;not null"`
+	// The ID of the access token that is issued along with the refresh token.
+	AccessTokenID int64  `gorm:"not null"`
+	SHA256        string `gorm:"type:VARCHAR(64);unique;not null"`
+	ExpiresUnix   int64  `gorm:"not null"`
+	CreatedUnix   int64
+}
+
+func (*OAuth2RefreshToken) TableName() string {
+	return "oauth2_refresh_token"
+}
+
+// BeforeCreate implements the GORM create hook.
+func (t *OAuth2RefreshToken) BeforeCreate(tx *gorm.DB) error {
+	if t.CreatedUnix == 0 {
+		t.CreatedUnix = tx.NowFunc().Unix()
+	}
+	return nil
+}
+
+// The supported methods of PKCE code challenges.
+const (
+	OAuth2CodeChallengePlain = "plain"
+	OAuth2CodeChallengeS256  = "S256"
+)
+
+// OAuth2Token is the set of tokens issued to an application.
+type OAuth2Token struct {
+	AccessToken  string
+	RefreshToken string
+	// The number of seconds before the access token expires.
+	ExpiresIn int64
+}
+
+// OAuth2Store is the storage layer for the OAuth2 authorization server.
+type OAuth2Store struct {
+	db *gorm.DB
+}
+
+func newOAuth2Store(db *gorm.DB) *OAuth2Store {
+	return &OAuth2Store{db: db}
+}
+
+type ErrOAuth2RedirectURIInvalid struct {
+	args errx.Args
+}
+
+// IsErrOAuth2RedirectURIInvalid returns true if the underlying error has the
+// type ErrOAuth2RedirectURIInvalid.
+func IsErrOAuth2RedirectURIInvalid(err error) bool {
+	return errors.As(err, &ErrOAuth2RedirectURIInvalid{})
+}
+
+func (err ErrOAuth2RedirectURIInvalid) Error() string {
+	return fmt.Sprintf("redirect URI is invalid: %v", err.args)
+}
+
+// normalizeRedirectURIs trims and validates the list of redirect URIs, which
+// must be absolute URLs without fragments.
+func normalizeRedirectURIs(uris []string) ([]string, error) {
+	normalized := make([]string, 0, len(uris))
+	for _, uri := range uris {
+		uri = strings.TrimSpace(uri)
+		if uri == "" {
+			continue
+		}
+
+		u, err := url.Parse(uri)
+		if err != nil || !u.IsAbs() || u.Host == "" || u.Fragment != "" {
+			return nil, ErrOAuth2RedirectURIInvalid{args: errx.Args{"uri": uri}}
+		}
+		normalized = append(normalized, uri)
+	}
+	if len(normalized) == 0 {
+		return nil, ErrOAuth2RedirectURIInvalid{args: errx.Args{"reason": "at least one redirect URI is required"}}
+	}
+	return normalized, nil
+}
+
+// generateOAuth2Secret returns a new random secret and its SHA256 hash.
+func generateOAuth2Secret() (secret, hash string, err error) {
+	secret, err = strx.RandomChars(40)
+	if err != nil {
+		return "", "", err
+	}
+	return secret, cryptox.SHA256(secret), nil
+}
+
+// CreateOAuth2ApplicationOptions contains options for creating an OAuth2
+// application.
+type CreateOAuth2ApplicationOptions struct {
+	Name         string
+	RedirectURIs []string
+}
+
+// CreateApplication creates a new OAuth2 application for the user with a
+// generated client ID and secret. The raw client secret is only available in
+// the ClientSecret field of the returned application. It returns
+// ErrOAuth2RedirectURIInvalid when any of the redirect URIs is invalid.
+func (s *OAuth2Store) CreateApplication(ctx context.Context, userID int64, opts CreateOAuth2ApplicationOptions) (*OAuth2Application, error) {
+	redirectURIs, err := normalizeRedirectURIs(opts.RedirectURIs)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, hash, err := generateOAuth2Secret()
+	if err != nil {
+		return nil, errors.Wrap(err, "generate client secret")
+	}
+
+	app := &OAuth2Application{
+		UserID:             userID,
+		Name:               opts.Name,
+		ClientID:           uuid.New().String(),
+		ClientSecretSHA256: hash,
+		RedirectURIs:       strings.Join(redirectURIs, "\n"),
+	}
+	err = s.db.WithContext(ctx).Create(app).Error
+	if err != nil {
+		return nil, err
+	}
+
+	app, err = s.GetApplicationByID(ctx, userID, app.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get application")
+	}
+	app.ClientSecret = secret
+	return app, nil
+}
+
+var _ errx.NotFound = (*ErrOAuth2ApplicationNotExist)(nil)
+
+type ErrOAuth2ApplicationNotExist struct {
+	args errx.Args
+}
+
+// IsErrOAuth2ApplicationNotExist returns true if the underlying error has the
+// type ErrOAuth2ApplicationNotExist.
+func IsErrOAuth2ApplicationNotExist(err error) bool {
+	return errors.As(err, &ErrOAuth2ApplicationNotExist{})
+}
+
+func (err ErrOAuth2ApplicationNotExist) Error() string {
+	return fmt.Sprintf("OAuth2 application does not exist: %v", err.args)
+}
+
+func (ErrOAuth2ApplicationNotExist) NotFound() bool {
+	return true
+}
+
+// GetApplicationByID returns the OAuth2 application with given ID of the user.
+// It returns ErrOAuth2ApplicationNotExist when not found.
+func (s *OAuth2Store) GetApplicationByID(ctx context.Context, userID, id int64) (*OAuth2Application, error) {
+	app := new(OAuth2Application)
+	err := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(app).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOAuth2ApplicationNotExist{args: errx.Args{"userID": userID, "id": id}}
+		}
+		return nil, err
+	}
+	return app, nil
+}
+
+// GetApplicationByClientID returns the OAuth2 application with given client ID.
+// It returns ErrOAuth2ApplicationNotExist when not found.
+func (s *OAuth2Store) GetApplicationByClientID(ctx context.Context, clientID string) (*OAuth2Application, error) {
+	app := new(OAuth2Application)
+	err := s.db.WithContext(ctx).Where("client_id = ?", clientID).First(app).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOAuth2ApplicationNotExist{args: errx.Args{"clientID": clientID}}
+		}
+		return nil, err
+	}
+	return app, nil
+}
+
+// ListApplications returns all OAuth2 applications registered by the user.
+func (s *OAuth2Store) ListApplications(ctx context.Context, userID int64) ([]*OAuth2Application, error) {
+	apps := make([]*OAuth2Application, 0, 5)
+	return apps, s.db.WithContext(ctx).Where("user_id = ?", userID).Order("id ASC").Find(&apps).Error
+}
+
+// UpdateOAuth2ApplicationOptions contains options for updating an OAuth2
+// application.
+type UpdateOAuth2ApplicationOptions struct {
+	Name         string
+	RedirectURIs []string
+}
+
+// UpdateApplication updates the OAuth2 application with given ID of the user.
+// It returns ErrOAuth2RedirectURIInvalid when any of the redirect URIs is
+// invalid.
+func (s *OAuth2Store) UpdateApplication(ctx context.Context, userID, id int64, opts UpdateOAuth2ApplicationOptions) error {
+	redirectURIs, err := normalizeRedirectURIs(opts.RedirectURIs)
+	if err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).
+		Model(new(OAuth2Application)).
+		Where("id = ? AND user_id = ?", id, userID).
+		Updates(map[string]any{
+			"name":          opts.Name,
+			"redirect_uris": strings.Join(redirectURIs, "\n"),
+			"updated_unix":  s.db.NowFunc().Unix(),
+		}).
+		Error
+}
+
+// RegenerateClientSecret generates a new client secret for the OAuth2
+// application with given ID of the user, and returns the raw secret. The old
+// secret stops working immediately.
+func (s *OAuth2Store) RegenerateClientSecret(ctx context.Context, userID, id int64) (string, error) {
+	_, err := s.GetApplicationByID(ctx, userID, id)
+	if err != nil {
+		return "", err
+	}
+
+	secret, hash, err := generateOAuth2Secret()
+	if err != nil {
+		return "", errors.Wrap(err, "generate client secret")
+	}
+
+	err = s.db.WithContext(ctx).
+		Model(new(OAuth2Application)).
+		Where("id = ? AND user_id = ?", id, userID).
+		Updates(map[string]any{
+			"client_secret_sha256": hash,
+			"updated_unix":         s.db.NowFunc().Unix(),
+		}).
+		Error
+	if err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// deleteOAuth2Grants deletes grants matching the condition and all of
+// authorization codes, refresh tokens and access tokens issued for them.
+func deleteOAuth2Grants(tx *gorm.DB, query any, args ...any) error {
+	var grantIDs []int64
+	err := tx.Model(new(OAuth2Grant)).Where(query, args...).Pluck("id", &grantIDs).Error
+	if err != nil {
+		return errors.Wrap(err, "list grant IDs")
+	}
+	if len(grantIDs) == 0 {
+		return nil
+	}
+
+	for _, table := range []any{
+		&OAuth2AuthorizationCode{},
+		&OAuth2RefreshToken{},
+		&AccessToken{},
+	} {
+		err = tx.Where("grant_id IN (?)", grantIDs).Delete(table).Error
+		if err != nil {
+			return errors.Wrapf(err, "clean up table %T", table)
+		}
+	}
+	return tx.Where("id IN (?)", grantIDs).Delete(new(OAuth2Grant)).Error
+}
+
+// DeleteApplication deletes the OAuth2 application with given ID of the user,
+// and revokes all grants given to it.
+//
+// 🚨 SECURITY: The "userID" is required to prevent attacker deletes arbitrary
+// OAuth2 application that belongs to another user.
+func (s *OAuth2Store) DeleteApplication(ctx context.Context, userID, id int64) error {
+	_, err := s.GetApplicationByID(ctx, userID, id)
+	if err != nil {
+		if IsErrOAuth2ApplicationNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := deleteOAuth2Grants(tx, "application_id = ?", id)
+		if err != nil {
+			return errors.Wrap(err, "delete grants")
+		}
+		return tx.Where("id = ?", id).Delete(new(OAuth2Application)).Error
+	})
+}
+
+type ErrOAuth2InvalidClient struct {
+	args errx.Args
+}
+
+// IsErrOAuth2InvalidClient returns true if the underlying error has the type
+// ErrOAuth2InvalidClient.
+func IsErrOAuth2InvalidClient(err error) bool {
+	return errors.As(err, &ErrOAuth2InvalidClient{})
+}
+
+func (err ErrOAuth2InvalidClient) Error() string {
+	return fmt.Sprintf("OAuth2 client authentication failed: %v", err.args)
+}
+
+// AuthenticateClient returns the OAuth2 application with given client ID when
+// the client secret matches. It returns ErrOAuth2InvalidClient when the client
+// does not exist or the secret does not match.
+func (s *OAuth2Store) AuthenticateClient(ctx context.Context, clientID, clientSecret string) (*OAuth2Application, error) {
+	app, err := s.GetApplicationByClientID(ctx, clientID)
+	if err != nil {
+		if IsErrOAuth2ApplicationNotExist(err) {
+			return nil, ErrOAuth2InvalidClient{args: errx.Args{"clientID": clientID}}
+		}
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(cryptox.SHA256(clientSecret)), []byte(app.ClientSecretSHA256)) != 1 {
+		return nil, ErrOAuth2InvalidClient{args: errx.Args{"clientID": clientID}}
+	}
+	return app, nil
+}
+
+// HasGrant returns true if the user has authorized the application.
+func (s *OAuth2Store) HasGrant(ctx context.Context, userID, applicationID int64) (bool, error) {
+	var count int64
+	err := s.db.WithContext(ctx).
+		Model(new(OAuth2Grant)).
+		Where("user_id = ? AND application_id = ?", userID, applicationID).
+		Count(&count).
+		Error
+	return count > 0, err
+}
+
+// ListGrants returns all grants that the user has given, with the Application
+// field set.
+func (s *OAuth2Store) ListGrants(ctx context.Context, userID int64) ([]*OAuth2Grant, error) {
+	grants := make([]*OAuth2Grant, 0, 5)
+	err := s.db.WithContext(ctx).Where("user_id = ?", userID).Order("id ASC").Find(&grants).Error
+	if err != nil {
+		return nil, err
+	} else if len(grants) == 0 {
+		return grants, nil
+	}
+
+	appIDs := make([]int64, 0, len(grants))
+	for _, g := range grants {
+		appIDs = append(appIDs, g.ApplicationID)
+	}
+	var apps []*OAuth2Application
+	err = s.db.WithContext(ctx).Where("id IN (?)", appIDs).Find(&apps).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "list applications")
+	}
+
+	appsByID := make(map[int64]*OAuth2Application, len(apps))
+	for _, app := range apps {
+		appsByID[app.ID] = app
+	}
+	for _, g := range grants {
+		g.Application = appsByID[g.ApplicationID]
+	}
+	return grants, nil
+}
+
+// RevokeGrant revokes the grant with given ID that the user has given, and all
+// tokens issued for it.
+//
+// 🚨 SECURITY: The "userID" is required to prevent attacker revokes arbitrary
+// grant that belongs to another user.
+func (s *OAuth2Store) RevokeGrant(ctx context.Context, userID, id int64) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return deleteOAuth2Grants(tx, "id = ? AND user_id = ?", id, userID)
+	})
+}
+
+// CreateAuthorizationCodeOptions contains options for creating an OAuth2
+// authorization code.
+type CreateAuthorizationCodeOptions struct {
+	// The redirect URI given in the authorization request, may be empty.
+	RedirectURI         string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+// CreateAuthorizationCode records the grant that the user gives to the
+// application and returns a new authorization code for it.
+func (s *OAuth2Store) CreateAuthorizationCode(ctx context.Context, userID, applicationID int64, opts CreateAuthorizationCodeOptions) (string, error) {
+	if opts.CodeChallenge != "" && opts.CodeChallengeMethod == "" {
+		opts.CodeChallengeMethod = OAuth2CodeChallengePlain
+	}
+
+	code, hash, err := generateOAuth2Secret()
+	if err != nil {
+		return "", errors.Wrap(err, "generate code")
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		grant := new(OAuth2Grant)
+		err := tx.Where("user_id = ? AND application_id = ?", userID, applicationID).First(grant).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			grant = &OAuth2Grant{
+				UserID:        userID,
+				ApplicationID: applicationID,
+			}
+			err = tx.Create(grant).Error
+			if err != nil {
+				return errors.Wrap(err, "create grant")
+			}
+		} else if err != nil {
+			return errors.Wrap(err, "get grant")
+		} else {
+			err = tx.Model(grant).UpdateColumn("updated_unix", tx.NowFunc().Unix()).Error
+			if err != nil {
+				return errors.Wrap(err, "touch grant")
+			}
+		}
+
+		return tx.Create(&OAuth2AuthorizationCode{
+			GrantID:             grant.ID,
+			CodeSHA256:          hash,
+			RedirectURI:         opts.RedirectURI,
+			CodeChallenge:       opts.CodeChallenge,
+			CodeChallengeMethod: opts.CodeChallengeMethod,
+			ExpiresUnix:         tx.NowFunc().Add(conf.OAuth2.AuthorizationCodeLifetime).Unix(),
+		}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+type ErrOAuth2InvalidGrant struct {
+	args errx.Args
+}
+
+// IsErrOAuth2InvalidGrant returns true if the underlying error has the type
+// ErrOAuth2InvalidGrant.
+func IsErrOAuth2InvalidGrant(err error) bool {
+	return errors.As(err, &ErrOAuth2InvalidGrant{})
+}
+
+func (err ErrOAuth2InvalidGrant) Error() string {
+	return fmt.Sprintf("OAuth2 grant is invalid: %v", err.args)
+}
+
+// verifyCodeChallenge returns true if the PKCE code verifier matches the code
+// challenge.
+func verifyCodeChallenge(method, challenge, verifier string) bool {
+	switch method {
+	case OAuth2CodeChallengeS256:
+		sum := sha256.Sum256([]byte(verifier))
+		return subtle.ConstantTimeCompare([]byte(base64.RawURLEncoding.EncodeToString(sum[:])), []byte(challenge)) == 1
+	case OAuth2CodeChallengePlain:
+		return subtle.ConstantTimeCompare([]byte(verifier), []byte(challenge)) == 1
+	}
+	return false
+}
+
+// getGrant returns the grant with given ID when it is given to the application.
+func getGrant(tx *gorm.DB, applicationID, id int64) (*OAuth2Grant, error) {
+	grant := new(OAuth2Grant)
+	err := tx.Where("id = ? AND application_id = ?", id, applicationID).First(grant).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOAuth2InvalidGrant{args: errx.Args{"reason": "grant does not exist"}}
+		}
+		return nil, err
+	}
+	return grant, nil
+}
+
+// issueTokens creates a new pair of access token and refresh token for the
+// grant.
+func issueTokens(tx *gorm.DB, app *OAuth2Application, grant *OAuth2Grant) (*OAuth2Token, error) {
+	now := tx.NowFunc()
+	token := cryptox.SHA1(uuid.New().String())
+	sha256 := cryptox.SHA256(token)
+	accessToken := &AccessToken{
+		UserID:      grant.UserID,
+		Name:        app.Name,
+		Sha1:        sha256[:40], // To pass the column unique constraint, keep the length of SHA1.
+		SHA256:      sha256,
+		GrantID:     grant.ID,
+		ExpiresUnix: now.Add(conf.OAuth2.AccessTokenLifetime).Unix(),
+	}
+	err := tx.Create(accessToken).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "create access token")
+	}
+
+	refreshToken, hash, err := generateOAuth2Secret()
+	if err != nil {
+		return nil, errors.Wrap(err, "generate refresh token")
+	}
+	err = tx.Create(&OAuth2RefreshToken{
+		GrantID:       grant.ID,
+		AccessTokenID: accessToken.ID,
+		SHA256:        hash,
+		ExpiresUnix:   now.Add(conf.OAuth2.RefreshTokenLifetime).Unix(),
+	}).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "create refresh token")
+	}
+
+	return &OAuth2Token{
+		AccessToken:  token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(conf.OAuth2.AccessTokenLifetime / time.Second),
+	}, nil
+}
+
+// ExchangeAuthorizationCode exchanges the authorization code for a new pair of
+// access token and refresh token. The code can only be used once. It returns
+// ErrOAuth2InvalidGrant when the code is invalid, expired, not issued to the
+// application, or the redirect URI or PKCE code verifier does not match.
+func (s *OAuth2Store) ExchangeAuthorizationCode(ctx context.Context, app *OAuth2Application, code, redirectURI, codeVerifier string) (*OAuth2Token, error) {
+	authCode := new(OAuth2AuthorizationCode)
+	err := s.db.WithContext(ctx).Where("code_sha256 = ?", cryptox.SHA256(code)).First(authCode).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOAuth2InvalidGrant{args: errx.Args{"reason": "authorization code does not exist"}}
+		}
+		return nil, errors.Wrap(err, "get authorization code")
+	}
+
+	// Other applications must not be able to burn the code of the application.
+	if _, err = getGrant(s.db.WithContext(ctx), app.ID, authCode.GrantID); err != nil {
+		return nil, err
+	}
+
+	// Delete the code before any other check, so it can never be used twice even
+	// under concurrent requests.
+	result := s.db.WithContext(ctx).Where("id = ?", authCode.ID).Delete(new(OAuth2AuthorizationCode))
+	if result.Error != nil {
+		return nil, errors.Wrap(result.Error, "delete authorization code")
+	} else if result.RowsAffected == 0 {
+		return nil, ErrOAuth2InvalidGrant{args: errx.Args{"reason": "authorization code does not exist"}}
+	}
+
+	if authCode.ExpiresUnix <= s.db.NowFunc().Unix() {
+		return nil, ErrOAuth2InvalidGrant{args: errx.Args{"reason": "authorization code has expired"}}
+	} else if authCode.RedirectURI != redirectURI {
+		return nil, ErrOAuth2InvalidGrant{args: errx.Args{"reason": "redirect URI does not match"}}
+	} else if authCode.CodeChallenge != "" && !verifyCodeChallenge(authCode.CodeChallengeMethod, authCode.CodeChallenge, codeVerifier) {
+		return nil, ErrOAuth2InvalidGrant{args: errx.Args{"reason": "code verifier does not match"}}
+	}
+
+	var token *OAuth2Token
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		grant, err := getGrant(tx, app.ID, authCode.GrantID)
+		if err != nil {
+			return err
+		}
+
+		token, err = issueTokens(tx, app, grant)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// RefreshToken exchanges the refresh token for a new pair of access token and
+// refresh token. The old pair of tokens is revoked. It returns
+// ErrOAuth2InvalidGrant when the refresh token is invalid, expired or not
+// issued to the application.
+func (s *OAuth2Store) RefreshToken(ctx context.Context, app *OAuth2Application, refreshToken string) (*OAuth2Token, error) {
+	var token *OAuth2Token
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		oldToken := new(OAuth2RefreshToken)
+		err := tx.Where("sha256 = ?", cryptox.SHA256(refreshToken)).First(oldToken).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrOAuth2InvalidGrant{args: errx.Args{"reason": "refresh token does not exist"}}
+			}
+			return errors.Wrap(err, "get refresh token")
+		} else if oldToken.ExpiresUnix <= tx.NowFunc().Unix() {
+			return ErrOAuth2InvalidGrant{args: errx.Args{"reason": "refresh token has expired"}}
+		}
+
+		grant, err := getGrant(tx, app.ID, oldToken.GrantID)
+		if err != nil {
+			return err
+		}
+
+		// Only one of concurrent requests with the same refresh token can delete
+		// it, others must fail instead of being issued another pair of tokens.
+		result := tx.Where("id = ? AND sha256 = ?", oldToken.ID, oldToken.SHA256).Delete(new(OAuth2RefreshToken))
+		if result.Error != nil {
+			return errors.Wrap(result.Error, "delete refresh token")
+		} else if result.RowsAffected == 0 {
+			return ErrOAuth2InvalidGrant{args: errx.Args{"reason": "refresh token does not exist"}}
+		}
+		err = tx.Where("id = ?", oldToken.AccessTokenID).Delete(new(AccessToken)).Error
+		if err != nil {
+			return errors.Wrap(err, "delete access token")
+		}
+
+		token, err = issueTokens(tx, app, grant)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// RevokeToken revokes the access token or refresh token that is issued to the
+// application, along with its paired token. It is a noop when the token does
+// not exist or is not issued to the application.
+func (s *OAuth2Store) RevokeToken(ctx context.Context, app *OAuth2Application, token string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		hash := cryptox.SHA256(token)
+
+		refreshToken := new(OAuth2RefreshToken)
+		err := tx.Where("sha256 = ?", hash).First(refreshToken).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.Wrap(err, "get refresh token")
+		}
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			accessToken := new(AccessToken)
+			err = tx.Where("sha256 = ? AND grant_id > 0", hash).First(accessToken).Error
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil
+				}
+				return errors.Wrap(err, "get access token")
+			}
+
+			err = tx.Where("access_token_id = ?", accessToken.ID).First(refreshToken).Error
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil
+				}
+				return errors.Wrap(err, "get refresh token by access token")
+			}
+		}
+
+		_, err = getGrant(tx, app.ID, refreshToken.GrantID)
+		if err != nil {
+			if IsErrOAuth2InvalidGrant(err) {
+				return nil
+			}
+			return err
+		}
+
+		err = tx.Where("id = ?", refreshToken.AccessTokenID).Delete(new(AccessToken)).Error
+		if err != nil {
+			return errors.Wrap(err, "delete access token")
+		}
+		return tx.Where("id = ?", refreshToken.ID).Delete(new(OAuth2RefreshToken)).Error
+	})
+}
@@ -0,0 +1,388 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/errx"
+)
+
+func TestOAuth2(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+
+	conf.SetMockOAuth2(t, conf.OAuth2Opts{
+		Enabled:                   true,
+		AuthorizationCodeLifetime: 10 * time.Minute,
+		AccessTokenLifetime:       time.Hour,
+		RefreshTokenLifetime:      720 * time.Hour,
+	})
+
+	ctx := context.Background()
+	s := &OAuth2Store{
+		db: newTestDB(t, "OAuth2Store"),
+	}
+
+	for _, tc := range []struct {
+		name string
+		test func(t *testing.T, ctx context.Context, s *OAuth2Store)
+	}{
+		{"CreateApplication", oauth2CreateApplication},
+		{"UpdateApplication", oauth2UpdateApplication},
+		{"RegenerateClientSecret", oauth2RegenerateClientSecret},
+		{"DeleteApplication", oauth2DeleteApplication},
+		{"ExchangeAuthorizationCode", oauth2ExchangeAuthorizationCode},
+		{"ExchangeAuthorizationCodeWithPKCE", oauth2ExchangeAuthorizationCodeWithPKCE},
+		{"RefreshToken", oauth2RefreshToken},
+		{"RevokeToken", oauth2RevokeToken},
+		{"RevokeGrant", oauth2RevokeGrant},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Cleanup(func() {
+				err := clearTables(t, s.db)
+				require.NoError(t, err)
+			})
+			tc.test(t, ctx, s)
+		})
+		if t.Failed() {
+			break
+		}
+	}
+}
+
+func createTestOAuth2Application(t *testing.T, ctx context.Context, s *OAuth2Store) *OAuth2Application {
+	app, err := s.CreateApplication(ctx, 1,
+		CreateOAuth2ApplicationOptions{
+			Name:         "My App",
+			RedirectURIs: []string{"https://example.com/callback", " ", "http://localhost:8080/callback "},
+		},
+	)
+	require.NoError(t, err)
+	return app
+}
+
+func oauth2CreateApplication(t *testing.T, ctx context.Context, s *OAuth2Store) {
+	app := createTestOAuth2Application(t, ctx, s)
+	assert.Equal(t, int64(1), app.UserID)
+	assert.Equal(t, "My App", app.Name)
+	assert.NotEmpty(t, app.ClientID)
+	assert.Len(t, app.ClientSecret, 40)
+	assert.Equal(t, []string{"https://example.com/callback", "http://localhost:8080/callback"}, app.RedirectURIList())
+	assert.True(t, app.HasRedirectURI("https://example.com/callback"))
+	assert.False(t, app.HasRedirectURI("https://example.com/callback/evil"))
+	assert.Equal(t, s.db.NowFunc().Unix(), app.Created.Unix())
+
+	got, err := s.AuthenticateClient(ctx, app.ClientID, app.ClientSecret)
+	require.NoError(t, err)
+	assert.Equal(t, app.ID, got.ID)
+
+	_, err = s.AuthenticateClient(ctx, app.ClientID, "bad_secret")
+	assert.True(t, IsErrOAuth2InvalidClient(err))
+	_, err = s.AuthenticateClient(ctx, "bad_client_id", app.ClientSecret)
+	assert.True(t, IsErrOAuth2InvalidClient(err))
+
+	// Try to create with invalid redirect URIs
+	for _, uris := range [][]string{
+		nil,
+		{"/callback"},
+		{"https://example.com/callback#fragment"},
+	} {
+		_, err = s.CreateApplication(ctx, 1, CreateOAuth2ApplicationOptions{Name: "Bad App", RedirectURIs: uris})
+		assert.True(t, IsErrOAuth2RedirectURIInvalid(err), "redirect URIs %v", uris)
+	}
+
+	apps, err := s.ListApplications(ctx, 1)
+	require.NoError(t, err)
+	assert.Len(t, apps, 1)
+}
+
+func oauth2UpdateApplication(t *testing.T, ctx context.Context, s *OAuth2Store) {
+	app := createTestOAuth2Application(t, ctx, s)
+
+	err := s.UpdateApplication(ctx, 1, app.ID,
+		UpdateOAuth2ApplicationOptions{
+			Name:         "Your App",
+			RedirectURIs: []string{"https://example.org/callback"},
+		},
+	)
+	require.NoError(t, err)
+
+	got, err := s.GetApplicationByID(ctx, 1, app.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Your App", got.Name)
+	assert.Equal(t, []string{"https://example.org/callback"}, got.RedirectURIList())
+
+	// Should not be able to get other user's application
+	_, err = s.GetApplicationByID(ctx, 2, app.ID)
+	wantErr := ErrOAuth2ApplicationNotExist{args: errx.Args{"userID": int64(2), "id": app.ID}}
+	assert.Equal(t, wantErr, err)
+}
+
+func oauth2RegenerateClientSecret(t *testing.T, ctx context.Context, s *OAuth2Store) {
+	app := createTestOAuth2Application(t, ctx, s)
+
+	secret, err := s.RegenerateClientSecret(ctx, 1, app.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, app.ClientSecret, secret)
+
+	_, err = s.AuthenticateClient(ctx, app.ClientID, app.ClientSecret)
+	assert.True(t, IsErrOAuth2InvalidClient(err))
+	_, err = s.AuthenticateClient(ctx, app.ClientID, secret)
+	require.NoError(t, err)
+
+	// Should not be able to regenerate for other user's application
+	_, err = s.RegenerateClientSecret(ctx, 2, app.ID)
+	assert.True(t, IsErrOAuth2ApplicationNotExist(err))
+}
+
+func oauth2DeleteApplication(t *testing.T, ctx context.Context, s *OAuth2Store) {
+	app := createTestOAuth2Application(t, ctx, s)
+	code, err := s.CreateAuthorizationCode(ctx, 2, app.ID, CreateAuthorizationCodeOptions{})
+	require.NoError(t, err)
+	token, err := s.ExchangeAuthorizationCode(ctx, app, code, "", "")
+	require.NoError(t, err)
+
+	// Deleting with a wrong owner should be noop
+	err = s.DeleteApplication(ctx, 2, app.ID)
+	require.NoError(t, err)
+	_, err = s.GetApplicationByID(ctx, 1, app.ID)
+	require.NoError(t, err)
+
+	err = s.DeleteApplication(ctx, 1, app.ID)
+	require.NoError(t, err)
+	_, err = s.GetApplicationByID(ctx, 1, app.ID)
+	assert.True(t, IsErrOAuth2ApplicationNotExist(err))
+
+	// Tokens issued to the application should be revoked
+	_, err = newAccessTokensStore(s.db).GetBySHA1(ctx, token.AccessToken)
+	assert.True(t, IsErrAccessTokenNotExist(err))
+	grants, err := s.ListGrants(ctx, 2)
+	require.NoError(t, err)
+	assert.Empty(t, grants)
+}
+
+func oauth2ExchangeAuthorizationCode(t *testing.T, ctx context.Context, s *OAuth2Store) {
+	app := createTestOAuth2Application(t, ctx, s)
+
+	has, err := s.HasGrant(ctx, 2, app.ID)
+	require.NoError(t, err)
+	assert.False(t, has)
+
+	code, err := s.CreateAuthorizationCode(ctx, 2, app.ID,
+		CreateAuthorizationCodeOptions{
+			RedirectURI: "https://example.com/callback",
+		},
+	)
+	require.NoError(t, err)
+
+	has, err = s.HasGrant(ctx, 2, app.ID)
+	require.NoError(t, err)
+	assert.True(t, has)
+
+	// Redirect URI must match
+	_, err = s.ExchangeAuthorizationCode(ctx, app, code, "http://localhost:8080/callback", "")
+	assert.True(t, IsErrOAuth2InvalidGrant(err))
+
+	// The code has been used by the failed attempt
+	_, err = s.ExchangeAuthorizationCode(ctx, app, code, "https://example.com/callback", "")
+	assert.True(t, IsErrOAuth2InvalidGrant(err))
+
+	code, err = s.CreateAuthorizationCode(ctx, 2, app.ID,
+		CreateAuthorizationCodeOptions{
+			RedirectURI: "https://example.com/callback",
+		},
+	)
+	require.NoError(t, err)
+
+	// Should not be able to exchange the code for another application
+	otherApp, err := s.CreateApplication(ctx, 3, CreateOAuth2ApplicationOptions{Name: "Other App", RedirectURIs: []string{"https://example.com/callback"}})
+	require.NoError(t, err)
+	_, err = s.ExchangeAuthorizationCode(ctx, otherApp, code, "https://example.com/callback", "")
+	wantErr := ErrOAuth2InvalidGrant{args: errx.Args{"reason": "grant does not exist"}}
+	assert.Equal(t, wantErr, err)
+
+	// The code is not burned by the other application
+	token, err := s.ExchangeAuthorizationCode(ctx, app, code, "https://example.com/callback", "")
+	require.NoError(t, err)
+	assert.NotEmpty(t, token.RefreshToken)
+	assert.Equal(t, int64(3600), token.ExpiresIn)
+
+	// The access token authenticates as the user but is not listed as a personal
+	// access token.
+	accessTokensStore := newAccessTokensStore(s.db)
+	accessToken, err := accessTokensStore.GetBySHA1(ctx, token.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), accessToken.UserID)
+	assert.NotZero(t, accessToken.GrantID)
+	assert.Equal(t, s.db.NowFunc().Add(time.Hour).Unix(), accessToken.ExpiresUnix)
+
+	tokens, err := accessTokensStore.List(ctx, 2)
+	require.NoError(t, err)
+	assert.Empty(t, tokens)
+
+	// Expired code should not be exchangeable
+	code, err = s.CreateAuthorizationCode(ctx, 2, app.ID, CreateAuthorizationCodeOptions{})
+	require.NoError(t, err)
+	err = s.db.Model(new(OAuth2AuthorizationCode)).Where("TRUE").UpdateColumn("expires_unix", s.db.NowFunc().Unix()).Error
+	require.NoError(t, err)
+	_, err = s.ExchangeAuthorizationCode(ctx, app, code, "", "")
+	assert.True(t, IsErrOAuth2InvalidGrant(err))
+}
+
+func oauth2ExchangeAuthorizationCodeWithPKCE(t *testing.T, ctx context.Context, s *OAuth2Store) {
+	app := createTestOAuth2Application(t, ctx, s)
+
+	const verifier = "dBjftJeZ4CVP-mJ92K9TsZ0xGQ-ub-UmcpsF0DCTLmo"
+	sum := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	for _, method := range []string{OAuth2CodeChallengeS256, OAuth2CodeChallengePlain} {
+		t.Run(method, func(t *testing.T) {
+			opts := CreateAuthorizationCodeOptions{
+				CodeChallenge:       challenge,
+				CodeChallengeMethod: method,
+			}
+			if method == OAuth2CodeChallengePlain {
+				opts.CodeChallenge = verifier
+			}
+
+			code, err := s.CreateAuthorizationCode(ctx, 2, app.ID, opts)
+			require.NoError(t, err)
+			_, err = s.ExchangeAuthorizationCode(ctx, app, code, "", "bad_verifier")
+			assert.True(t, IsErrOAuth2InvalidGrant(err))
+
+			code, err = s.CreateAuthorizationCode(ctx, 2, app.ID, opts)
+			require.NoError(t, err)
+			_, err = s.ExchangeAuthorizationCode(ctx, app, code, "", "")
+			assert.True(t, IsErrOAuth2InvalidGrant(err))
+
+			code, err = s.CreateAuthorizationCode(ctx, 2, app.ID, opts)
+			require.NoError(t, err)
+			_, err = s.ExchangeAuthorizationCode(ctx, app, code, "", verifier)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func oauth2RefreshToken(t *testing.T, ctx context.Context, s *OAuth2Store) {
+	app := createTestOAuth2Application(t, ctx, s)
+	code, err := s.CreateAuthorizationCode(ctx, 2, app.ID, CreateAuthorizationCodeOptions{})
+	require.NoError(t, err)
+	token, err := s.ExchangeAuthorizationCode(ctx, app, code, "", "")
+	require.NoError(t, err)
+
+	newToken, err := s.RefreshToken(ctx, app, token.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, token.AccessToken, newToken.AccessToken)
+	assert.NotEqual(t, token.RefreshToken, newToken.RefreshToken)
+
+	// The old pair of tokens should be revoked
+	accessTokensStore := newAccessTokensStore(s.db)
+	_, err = accessTokensStore.GetBySHA1(ctx, token.AccessToken)
+	assert.True(t, IsErrAccessTokenNotExist(err))
+	_, err = s.RefreshToken(ctx, app, token.RefreshToken)
+	assert.True(t, IsErrOAuth2InvalidGrant(err))
+
+	_, err = accessTokensStore.GetBySHA1(ctx, newToken.AccessToken)
+	require.NoError(t, err)
+
+	// Should not be able to refresh with another application
+	otherApp, err := s.CreateApplication(ctx, 3, CreateOAuth2ApplicationOptions{Name: "Other App", RedirectURIs: []string{"https://example.com/callback"}})
+	require.NoError(t, err)
+	_, err = s.RefreshToken(ctx, otherApp, newToken.RefreshToken)
+	assert.True(t, IsErrOAuth2InvalidGrant(err))
+
+	t.Run("concurrent refreshes", func(t *testing.T) {
+		var (
+			wg        sync.WaitGroup
+			succeeded atomic.Int32
+		)
+		for range 5 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := s.RefreshToken(ctx, app, newToken.RefreshToken); err == nil {
+					succeeded.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+		assert.LessOrEqual(t, succeeded.Load(), int32(1))
+	})
+}
+
+func oauth2RevokeToken(t *testing.T, ctx context.Context, s *OAuth2Store) {
+	app := createTestOAuth2Application(t, ctx, s)
+	accessTokensStore := newAccessTokensStore(s.db)
+
+	// Revoke by the access token
+	code, err := s.CreateAuthorizationCode(ctx, 2, app.ID, CreateAuthorizationCodeOptions{})
+	require.NoError(t, err)
+	token, err := s.ExchangeAuthorizationCode(ctx, app, code, "", "")
+	require.NoError(t, err)
+
+	err = s.RevokeToken(ctx, app, token.AccessToken)
+	require.NoError(t, err)
+	_, err = accessTokensStore.GetBySHA1(ctx, token.AccessToken)
+	assert.True(t, IsErrAccessTokenNotExist(err))
+	_, err = s.RefreshToken(ctx, app, token.RefreshToken)
+	assert.True(t, IsErrOAuth2InvalidGrant(err))
+
+	// Revoke by the refresh token
+	code, err = s.CreateAuthorizationCode(ctx, 2, app.ID, CreateAuthorizationCodeOptions{})
+	require.NoError(t, err)
+	token, err = s.ExchangeAuthorizationCode(ctx, app, code, "", "")
+	require.NoError(t, err)
+
+	err = s.RevokeToken(ctx, app, token.RefreshToken)
+	require.NoError(t, err)
+	_, err = accessTokensStore.GetBySHA1(ctx, token.AccessToken)
+	assert.True(t, IsErrAccessTokenNotExist(err))
+
+	// Revoking unknown token is noop
+	err = s.RevokeToken(ctx, app, "bad_token")
+	require.NoError(t, err)
+}
+
+func oauth2RevokeGrant(t *testing.T, ctx context.Context, s *OAuth2Store) {
+	app := createTestOAuth2Application(t, ctx, s)
+	code, err := s.CreateAuthorizationCode(ctx, 2, app.ID, CreateAuthorizationCodeOptions{})
+	require.NoError(t, err)
+	token, err := s.ExchangeAuthorizationCode(ctx, app, code, "", "")
+	require.NoError(t, err)
+
+	grants, err := s.ListGrants(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, grants, 1)
+	assert.Equal(t, app.ID, grants[0].Application.ID)
+	assert.Equal(t, "My App", grants[0].Application.Name)
+
+	// Revoking with a wrong user should be noop
+	err = s.RevokeGrant(ctx, 3, grants[0].ID)
+	require.NoError(t, err)
+	has, err := s.HasGrant(ctx, 2, app.ID)
+	require.NoError(t, err)
+	assert.True(t, has)
+
+	err = s.RevokeGrant(ctx, 2, grants[0].ID)
+	require.NoError(t, err)
+	has, err = s.HasGrant(ctx, 2, app.ID)
+	require.NoError(t, err)
+	assert.False(t, has)
+
+	_, err = newAccessTokensStore(s.db).GetBySHA1(ctx, token.AccessToken)
+	assert.True(t, IsErrAccessTokenNotExist(err))
+	_, err = s.RefreshToken(ctx, app, token.RefreshToken)
+	assert.True(t, IsErrOAuth2InvalidGrant(err))
+}
@@ -226,6 +226,7 @@ func DeleteOrganization(org *User) error {
 		&Team{OrgID: org.ID},
 		&OrgUser{OrgID: org.ID},
 		&TeamUser{OrgID: org.ID},
+		&ProtectBranch{OrgID: org.ID},
 	); err != nil {
 		return errors.Newf("deleteBeans: %v", err)
 	}
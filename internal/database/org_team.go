@@ -9,6 +9,7 @@ import (
 	"xorm.io/xorm"
 
 	"gogs.io/gogs/internal/errx"
+	apiv1types "gogs.io/gogs/internal/route/api/v1/types"
 )
 
 const ownerTeamName = "Owners"
@@ -47,6 +48,15 @@ func (t *Team) HasWriteAccess() bool {
 	return t.Authorize >= AccessModeWrite
 }
 
+func (t *Team) APIFormat() *apiv1types.OrganizationTeam {
+	return &apiv1types.OrganizationTeam{
+		ID:          t.ID,
+		Name:        t.Name,
+		Description: t.Description,
+		Permission:  t.Authorize.String(),
+	}
+}
+
 // IsTeamMember returns true if given user is a member of team.
 func (t *Team) IsMember(userID int64) bool {
 	return IsTeamMember(t.OrgID, t.ID, userID)
@@ -437,6 +447,11 @@ func DeleteTeam(t *Team) error {
 		return err
 	}
 
+	// Delete review requests.
+	if _, err = sess.Where("team_id=?", t.ID).Delete(new(ReviewRequest)); err != nil {
+		return err
+	}
+
 	// Delete team.
 	if _, err = sess.ID(t.ID).Delete(new(Team)); err != nil {
 		return err
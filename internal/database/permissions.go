@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"slices"
 
 	"github.com/cockroachdb/errors"
 	"gorm.io/gorm"
@@ -71,12 +72,9 @@ type AccessModeOptions struct {
 	Private bool  // Whether the repository is private.
 }
 
-// AccessMode returns the access mode of given user has to the repository.
-func (s *PermissionsStore) AccessMode(ctx context.Context, userID, repoID int64, opts AccessModeOptions) (mode AccessMode) {
-	if repoID <= 0 {
-		return AccessModeNone
-	}
-
+// accessMode returns the access mode of given user has to the repository with
+// the access record of the user, which is nil if there is none.
+func accessMode(userID int64, opts AccessModeOptions, access *Access) (mode AccessMode) {
 	// Everyone has read access to public repository.
 	if !opts.Private {
 		mode = AccessModeRead
@@ -91,15 +89,32 @@ func (s *PermissionsStore) AccessMode(ctx context.Context, userID, repoID int64,
 		return AccessModeOwner
 	}
 
+	if access != nil {
+		return access.Mode
+	}
+	return mode
+}
+
+// AccessMode returns the access mode of given user has to the repository.
+func (s *PermissionsStore) AccessMode(ctx context.Context, userID, repoID int64, opts AccessModeOptions) (mode AccessMode) {
+	if repoID <= 0 {
+		return AccessModeNone
+	}
+
+	// No need to waste a query when the access record does not matter.
+	if userID <= 0 || userID == opts.OwnerID {
+		return accessMode(userID, opts, nil)
+	}
+
 	access := new(Access)
 	err := s.db.WithContext(ctx).Where("user_id = ? AND repo_id = ?", userID, repoID).First(access).Error
 	if err != nil {
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
 			log.Error("Failed to get access [user_id: %d, repo_id: %d]: %v", userID, repoID, err)
 		}
-		return mode
+		return accessMode(userID, opts, nil)
 	}
-	return access.Mode
+	return accessMode(userID, opts, access)
 }
 
 // Authorize returns true if the user has as good as desired access mode to the
@@ -108,6 +123,47 @@ func (s *PermissionsStore) Authorize(ctx context.Context, userID, repoID int64,
 	return desired <= s.AccessMode(ctx, userID, repoID, opts)
 }
 
+// AuthorizedRepoIDs returns IDs of repositories that the user has as good as
+// desired access mode to, in ascending order. Keys of the "repos" are
+// repository IDs. It follows the same rules as Authorize, but loads access
+// records of all repositories at once.
+func (s *PermissionsStore) AuthorizedRepoIDs(ctx context.Context, userID int64, desired AccessMode, repos map[int64]AccessModeOptions) ([]int64, error) {
+	repoIDs := make([]int64, 0, len(repos))
+	for repoID := range repos {
+		if repoID > 0 {
+			repoIDs = append(repoIDs, repoID)
+		}
+	}
+	slices.Sort(repoIDs)
+
+	accesses := make(map[int64]*Access)
+	if userID > 0 {
+		// Query in batches to stay within limits of bound parameters of databases.
+		const batchSize = 500
+		for i := 0; i < len(repoIDs); i += batchSize {
+			var records []*Access
+			err := s.db.WithContext(ctx).
+				Where("user_id = ? AND repo_id IN (?)", userID, repoIDs[i:min(i+batchSize, len(repoIDs))]).
+				Find(&records).
+				Error
+			if err != nil {
+				return nil, errors.Wrap(err, "find accesses")
+			}
+			for _, access := range records {
+				accesses[access.RepoID] = access
+			}
+		}
+	}
+
+	authorized := make([]int64, 0, len(repoIDs))
+	for _, repoID := range repoIDs {
+		if desired <= accessMode(userID, repos[repoID], accesses[repoID]) {
+			authorized = append(authorized, repoID)
+		}
+	}
+	return authorized, nil
+}
+
 // SetRepoPerms does a full update to which users have which level of access to
 // given repository. Keys of the "accessMap" are user IDs.
 func (s *PermissionsStore) SetRepoPerms(ctx context.Context, repoID int64, accessMap map[int64]AccessMode) error {
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -25,6 +26,7 @@ func TestPerms(t *testing.T) {
 	}{
 		{"AccessMode", permsAccessMode},
 		{"Authorize", permsAuthorize},
+		{"AuthorizedRepoIDs", permsAuthorizedRepoIDs},
 		{"SetRepoPerms", permsSetRepoPerms},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
@@ -237,6 +239,47 @@ func permsAuthorize(t *testing.T, ctx context.Context, s *PermissionsStore) {
 	}
 }
 
+func permsAuthorizedRepoIDs(t *testing.T, ctx context.Context, s *PermissionsStore) {
+	// Set up permissions
+	err := s.SetRepoPerms(ctx, 2, map[int64]AccessMode{1: AccessModeRead, 2: AccessModeWrite})
+	require.NoError(t, err)
+	err = s.SetRepoPerms(ctx, 3, map[int64]AccessMode{2: AccessModeRead})
+	require.NoError(t, err)
+
+	repos := map[int64]AccessModeOptions{
+		1: {OwnerID: 98},                // Public
+		2: {OwnerID: 98, Private: true}, // Private with accesses
+		3: {OwnerID: 98, Private: true},
+		4: {OwnerID: 2, Private: true}, // Owned by user 2
+	}
+
+	tests := []struct {
+		name    string
+		userID  int64
+		desired AccessMode
+		want    []int64
+	}{
+		{name: "anonymous wants read", userID: 0, desired: AccessModeRead, want: []int64{1}},
+		{name: "user 1 wants read", userID: 1, desired: AccessModeRead, want: []int64{1, 2}},
+		{name: "user 1 wants write", userID: 1, desired: AccessModeWrite, want: []int64{}},
+		{name: "user 2 wants read", userID: 2, desired: AccessModeRead, want: []int64{1, 2, 3, 4}},
+		{name: "user 2 wants write", userID: 2, desired: AccessModeWrite, want: []int64{2, 4}},
+		{name: "user 3 wants read", userID: 3, desired: AccessModeRead, want: []int64{1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := s.AuthorizedRepoIDs(ctx, test.userID, test.desired, repos)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+
+			// Results should be consistent with Authorize
+			for repoID, opts := range repos {
+				assert.Equal(t, s.Authorize(ctx, test.userID, repoID, test.desired, opts), slices.Contains(got, repoID), "repo %d", repoID)
+			}
+		})
+	}
+}
+
 func permsSetRepoPerms(t *testing.T, ctx context.Context, s *PermissionsStore) {
 	for _, update := range []struct {
 		repoID    int64
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cockroachdb/errors"
@@ -57,6 +59,9 @@ type PullRequest struct {
 	HeadBranch   string
 	BaseBranch   string
 	MergeBase    string `xorm:"VARCHAR(40)" gorm:"type:VARCHAR(40)"`
+	// Whether users with write access to the base repository are allowed to
+	// update the head branch.
+	AllowMaintainerEdit bool `xorm:"NOT NULL DEFAULT false" gorm:"not null;default:FALSE"`
 
 	HasMerged      bool
 	MergedCommitID string `xorm:"VARCHAR(40)" gorm:"type:VARCHAR(40)"`
@@ -64,6 +69,15 @@ type PullRequest struct {
 	Merger         *User     `xorm:"-" json:"-" gorm:"-"`
 	Merged         time.Time `xorm:"-" json:"-" gorm:"-"`
 	MergedUnix     int64
+
+	// The merge style to merge the pull request automatically once it becomes
+	// mergeable, auto-merge is not scheduled when it is empty.
+	AutoMergeStyle       MergeStyle `xorm:"VARCHAR(30)" gorm:"type:VARCHAR(30)"`
+	AutoMergerID         int64
+	AutoMergeDescription string `xorm:"TEXT" gorm:"type:TEXT"`
+
+	RequestedReviewers []*User `xorm:"-" json:"-" gorm:"-"`
+	RequestedTeams     []*Team `xorm:"-" json:"-" gorm:"-"`
 }
 
 func (pr *PullRequest) BeforeUpdate() {
@@ -108,6 +122,12 @@ func (pr *PullRequest) loadAttributes(e Engine) (err error) {
 		}
 	}
 
+	if pr.RequestedReviewers == nil || pr.RequestedTeams == nil {
+		if err = pr.loadReviewRequests(e); err != nil {
+			return errors.Newf("load review requests: %v", err)
+		}
+	}
+
 	return nil
 }
 
@@ -126,7 +146,7 @@ func (pr *PullRequest) LoadIssue() (err error) {
 
 // This method assumes following fields have been assigned with valid values:
 // Required - Issue, BaseRepo
-// Optional - HeadRepo, Merger
+// Optional - HeadRepo, Merger, RequestedReviewers, RequestedTeams
 func (pr *PullRequest) APIFormat() *apiv1types.PullRequest {
 	// In case of head repo has been deleted.
 	var apiHeadRepo *apiv1types.Repository
@@ -140,22 +160,31 @@ func (pr *PullRequest) APIFormat() *apiv1types.PullRequest {
 
 	apiIssue := pr.Issue.APIFormat()
 	apiPullRequest := &apiv1types.PullRequest{
-		ID:         pr.ID,
-		Index:      pr.Index,
-		Poster:     apiIssue.Poster,
-		Title:      apiIssue.Title,
-		Body:       apiIssue.Body,
-		Labels:     apiIssue.Labels,
-		Milestone:  apiIssue.Milestone,
-		Assignee:   apiIssue.Assignee,
-		State:      apiIssue.State,
-		Comments:   apiIssue.Comments,
-		HeadBranch: pr.HeadBranch,
-		HeadRepo:   apiHeadRepo,
-		BaseBranch: pr.BaseBranch,
-		BaseRepo:   pr.BaseRepo.APIFormatLegacy(nil),
-		HTMLURL:    pr.Issue.HTMLURL(),
-		HasMerged:  pr.HasMerged,
+		ID:                 pr.ID,
+		Index:              pr.Index,
+		Poster:             apiIssue.Poster,
+		Title:              apiIssue.Title,
+		Body:               apiIssue.Body,
+		Labels:             apiIssue.Labels,
+		Milestone:          apiIssue.Milestone,
+		Assignee:           apiIssue.Assignee,
+		Assignees:          apiIssue.Assignees,
+		State:              apiIssue.State,
+		Comments:           apiIssue.Comments,
+		HeadBranch:         pr.HeadBranch,
+		HeadRepo:           apiHeadRepo,
+		BaseBranch:         pr.BaseBranch,
+		BaseRepo:           pr.BaseRepo.APIFormatLegacy(nil),
+		HTMLURL:            pr.Issue.HTMLURL(),
+		HasMerged:          pr.HasMerged,
+		RequestedReviewers: make([]*apiv1types.User, len(pr.RequestedReviewers)),
+		RequestedTeams:     make([]*apiv1types.OrganizationTeam, len(pr.RequestedTeams)),
+	}
+	for i := range pr.RequestedReviewers {
+		apiPullRequest.RequestedReviewers[i] = pr.RequestedReviewers[i].APIFormat()
+	}
+	for i := range pr.RequestedTeams {
+		apiPullRequest.RequestedTeams[i] = pr.RequestedTeams[i].APIFormat()
 	}
 
 	if pr.Status != PullRequestStatusChecking {
@@ -185,10 +214,141 @@ func (pr *PullRequest) CanAutoMerge() bool {
 type MergeStyle string
 
 const (
-	MergeStyleRegular MergeStyle = "create_merge_commit"
-	MergeStyleRebase  MergeStyle = "rebase_before_merging"
+	MergeStyleRegular         MergeStyle = "create_merge_commit"
+	MergeStyleRebase          MergeStyle = "rebase_before_merging"
+	MergeStyleSquash          MergeStyle = "squash_and_merge"
+	MergeStyleFastForwardOnly MergeStyle = "fast_forward_only"
 )
 
+// SquashCommitMessage returns the default message of the squash commit for
+// merging the pull request of given issue. It combines messages of all commits
+// and appends co-author trailers for commit authors other than the doer.
+// Commits are expected to be in reverse chronological order.
+func SquashCommitMessage(issue *Issue, doer *User, commits []*git.Commit) string {
+	var buf strings.Builder
+	buf.WriteString(fmt.Sprintf("%s (#%d)\n", issue.Title, issue.Index))
+
+	seen := map[string]bool{
+		strings.ToLower(doer.Email): true,
+	}
+	var coAuthors []string
+	for i := len(commits) - 1; i >= 0; i-- {
+		c := commits[i]
+		for j, line := range strings.Split(strings.TrimSpace(c.Message), "\n") {
+			switch {
+			case j == 0:
+				buf.WriteString("\n* ")
+			case line != "":
+				buf.WriteString("  ")
+			}
+			buf.WriteString(line)
+			buf.WriteString("\n")
+		}
+
+		if c.Author == nil || c.Author.Email == "" || seen[strings.ToLower(c.Author.Email)] {
+			continue
+		}
+		seen[strings.ToLower(c.Author.Email)] = true
+		coAuthors = append(coAuthors, fmt.Sprintf("Co-authored-by: %s <%s>", c.Author.Name, c.Author.Email))
+	}
+
+	if len(coAuthors) > 0 {
+		buf.WriteString("\n")
+		buf.WriteString(strings.Join(coAuthors, "\n"))
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+type ErrMergeStyleNotAllowed struct {
+	args map[string]any
+}
+
+func IsErrMergeStyleNotAllowed(err error) bool {
+	_, ok := err.(ErrMergeStyleNotAllowed)
+	return ok
+}
+
+func (err ErrMergeStyleNotAllowed) Error() string {
+	return fmt.Sprintf("merge style is not allowed: %v", err.args)
+}
+
+type ErrPullRequestNotFastForward struct {
+	args map[string]any
+}
+
+func IsErrPullRequestNotFastForward(err error) bool {
+	_, ok := err.(ErrPullRequestNotFastForward)
+	return ok
+}
+
+func (err ErrPullRequestNotFastForward) Error() string {
+	return fmt.Sprintf("pull request cannot be fast-forwarded: %v", err.args)
+}
+
+type ErrPullRequestHasMergeCommits struct {
+	args map[string]any
+}
+
+func IsErrPullRequestHasMergeCommits(err error) bool {
+	_, ok := err.(ErrPullRequestHasMergeCommits)
+	return ok
+}
+
+func (err ErrPullRequestHasMergeCommits) Error() string {
+	return fmt.Sprintf("pull request has merge commits: %v", err.args)
+}
+
+type ErrPullRequestHasUnverifiedCommits struct {
+	args map[string]any
+}
+
+func IsErrPullRequestHasUnverifiedCommits(err error) bool {
+	_, ok := err.(ErrPullRequestHasUnverifiedCommits)
+	return ok
+}
+
+func (err ErrPullRequestHasUnverifiedCommits) Error() string {
+	return fmt.Sprintf("pull request has unverified commits: %v", err.args)
+}
+
+// checkFastForwardCommits returns an error if any of the commits in the range
+// that would be fast-forwarded onto the base branch violates its protection.
+// Pushes of the server itself skip the pre-receive hook, so checks of the hook
+// are repeated here.
+func (pr *PullRequest) checkFastForwardCommits(repoPath, revRange string) error {
+	protectBranch, err := GetProtectBranchOfRepoByName(pr.BaseRepoID, pr.BaseBranch)
+	if err != nil {
+		if IsErrBranchNotExist(err) {
+			return nil
+		}
+		return errors.Newf("get protect branch of repository by name: %v", err)
+	} else if !protectBranch.Protected {
+		return nil
+	}
+
+	if protectBranch.RequireLinearHistory {
+		stdout, stderr, err := process.ExecDir(-1, repoPath,
+			fmt.Sprintf("PullRequest.Merge (git rev-list --min-parents=2): %s", repoPath),
+			"git", "rev-list", "--min-parents=2", "--max-count=1", "--end-of-options", revRange)
+		if err != nil {
+			return errors.Newf("git rev-list --min-parents=2 [%s]: %v - %s", repoPath, err, stderr)
+		} else if commitID := strings.TrimSpace(stdout); commitID != "" {
+			return ErrPullRequestHasMergeCommits{args: map[string]any{"pullRequestID": pr.ID, "commitID": commitID}}
+		}
+	}
+
+	if protectBranch.RequireSignedCommits {
+		commit, _, err := FindUnverifiedCommit(context.TODO(), repoPath, []string{revRange})
+		if err != nil {
+			return errors.Newf("find unverified commit: %v", err)
+		} else if commit != nil {
+			return ErrPullRequestHasUnverifiedCommits{args: map[string]any{"pullRequestID": pr.ID, "commitID": commit.ID.String()}}
+		}
+	}
+	return nil
+}
+
 // Merge merges pull request to base repository.
 // FIXME: add repoWorkingPull make sure two merges does not happen at same time.
 func (pr *PullRequest) Merge(doer *User, baseGitRepo *git.Repository, mergeStyle MergeStyle, commitDescription string) (err error) {
@@ -199,6 +359,24 @@ func (pr *PullRequest) Merge(doer *User, baseGitRepo *git.Repository, mergeStyle
 		go AddTestPullRequestTask(doer, pr.BaseRepo.ID, pr.BaseBranch, false)
 	}()
 
+	mergeStyles, err := pr.BaseRepo.AllowedMergeStyles(pr.BaseBranch)
+	if err != nil {
+		return errors.Newf("get allowed merge styles: %v", err)
+	} else if !slices.Contains(mergeStyles, mergeStyle) {
+		return ErrMergeStyleNotAllowed{args: map[string]any{"repoID": pr.BaseRepoID, "branch": pr.BaseBranch, "mergeStyle": mergeStyle}}
+	}
+
+	blocked, err := pr.Issue.HasOpenDependencies()
+	if err != nil {
+		return errors.Newf("check open dependencies: %v", err)
+	} else if blocked {
+		return ErrIssueHasOpenDependencies{args: map[string]any{"issueID": pr.IssueID}}
+	}
+
+	if err = pr.checkCodeOwnerSignOffs(); err != nil {
+		return err
+	}
+
 	sess := x.NewSession()
 	defer sess.Close()
 	if err = sess.Begin(); err != nil {
@@ -251,11 +429,6 @@ func (pr *PullRequest) Merge(doer *User, baseGitRepo *git.Repository, mergeStyle
 
 	remoteHeadBranch := "head_repo/" + pr.HeadBranch
 
-	// Check if merge style is allowed, reset to default style if not
-	if mergeStyle == MergeStyleRebase && !pr.BaseRepo.PullsAllowRebase {
-		mergeStyle = MergeStyleRegular
-	}
-
 	switch mergeStyle {
 	case MergeStyleRegular: // Create merge commit
 
@@ -276,6 +449,8 @@ func (pr *PullRequest) Merge(doer *User, baseGitRepo *git.Repository, mergeStyle
 		}
 
 	case MergeStyleRebase: // Rebase before merging
+		// NOTE: Merge commits of the head branch are dropped by the rebase, thus
+		// the history stays linear.
 
 		// Rebase head branch based on base branch, this creates a non-branch commit state.
 		if _, stderr, err = process.ExecDir(-1, tmpBasePath,
@@ -306,6 +481,53 @@ func (pr *PullRequest) Merge(doer *User, baseGitRepo *git.Repository, mergeStyle
 			return errors.Newf("git merge [%s]: %v - %s", tmpBasePath, err, stderr)
 		}
 
+	case MergeStyleSquash: // Squash all commits into a single commit
+		commitMessage := commitDescription
+		if strings.TrimSpace(commitMessage) == "" {
+			tmpGitRepo, err := git.Open(tmpBasePath)
+			if err != nil {
+				return errors.Newf("open repository: %v", err)
+			}
+			commits, err := tmpGitRepo.RevList([]string{pr.BaseBranch + ".." + remoteHeadBranch})
+			if err != nil {
+				return errors.Newf("list commits: %v", err)
+			}
+			commitMessage = SquashCommitMessage(pr.Issue, doer, commits)
+		}
+
+		// Stage changes from head branch without creating a commit.
+		if _, stderr, err = process.ExecDir(-1, tmpBasePath,
+			fmt.Sprintf("PullRequest.Merge (git merge --squash): %s", tmpBasePath),
+			"git", "merge", "--squash", "--end-of-options", remoteHeadBranch); err != nil {
+			return errors.Newf("git merge --squash [%s]: %v - %s", tmpBasePath, err, stderr)
+		}
+
+		// Create the squash commit for the base branch.
+		if _, stderr, err = process.ExecDir(-1, tmpBasePath,
+			fmt.Sprintf("PullRequest.Merge (git commit): %s", tmpBasePath),
+			"git", "commit", fmt.Sprintf("--author=%s <%s>", doer.DisplayName(), doer.Email),
+			"-m", commitMessage); err != nil {
+			return errors.Newf("git commit [%s]: %v - %s", tmpBasePath, err, stderr)
+		}
+
+	case MergeStyleFastForwardOnly: // Fast-forward base branch to head branch
+		// Base branch must be an ancestor of head branch to be fast-forwarded.
+		if _, _, err = process.ExecDir(-1, tmpBasePath,
+			fmt.Sprintf("PullRequest.Merge (git merge-base --is-ancestor): %s", tmpBasePath),
+			"git", "merge-base", "--is-ancestor", "--end-of-options", pr.BaseBranch, remoteHeadBranch); err != nil {
+			return ErrPullRequestNotFastForward{args: map[string]any{"pullRequestID": pr.ID}}
+		}
+
+		if err = pr.checkFastForwardCommits(tmpBasePath, pr.BaseBranch+".."+remoteHeadBranch); err != nil {
+			return err
+		}
+
+		if _, stderr, err = process.ExecDir(-1, tmpBasePath,
+			fmt.Sprintf("PullRequest.Merge (git merge --ff-only): %s", tmpBasePath),
+			"git", "merge", "--ff-only", "--end-of-options", remoteHeadBranch); err != nil {
+			return errors.Newf("git merge --ff-only [%s]: %v - %s", tmpBasePath, err, stderr)
+		}
+
 	default:
 		return errors.Newf("unknown merge style: %s", mergeStyle)
 	}
@@ -334,6 +556,7 @@ func (pr *PullRequest) Merge(doer *User, baseGitRepo *git.Repository, mergeStyle
 	if err = Handle.Actions().MergePullRequest(ctx, doer, pr.Issue.Repo.Owner, pr.Issue.Repo, pr.Issue); err != nil {
 		log.Error("Failed to create action for merge pull request, pull_request_id: %d, error: %v", pr.ID, err)
 	}
+	pr.Issue.queueUnblockedDependents()
 
 	// Reload pull request information.
 	if err = pr.LoadAttributes(); err != nil {
@@ -365,8 +588,11 @@ func (pr *PullRequest) Merge(doer *User, baseGitRepo *git.Repository, mergeStyle
 		log.Error("Failed to get base branch %q commit: %v", pr.BaseBranch, err)
 		return nil
 	}
-	if mergeStyle == MergeStyleRegular {
+	switch mergeStyle {
+	case MergeStyleRegular:
 		commits = append([]*git.Commit{mergeCommit}, commits...)
+	case MergeStyleSquash:
+		commits = []*git.Commit{mergeCommit}
 	}
 
 	pcs, err := CommitsToPushCommits(commits).APIFormat(ctx, Handle.Users(), pr.BaseRepo.RepoPath(), pr.BaseRepo.HTMLURL())
@@ -440,8 +666,15 @@ func (pr *PullRequest) testPatch() (err error) {
 	return nil
 }
 
-// NewPullRequest creates new pull request with labels for repository.
-func NewPullRequest(repo *Repository, pull *Issue, labelIDs []int64, uuids []string, pr *PullRequest, patch []byte) (err error) {
+// NewPullRequest creates new pull request with labels and assignees for
+// repository. It returns ErrBlockedByUser if the owner of the repository has
+// blocked the poster.
+func NewPullRequest(repo *Repository, pull *Issue, labelIDs, assigneeIDs []int64, uuids []string, pr *PullRequest, patch []byte) (err error) {
+	err = Handle.UserBlocks().EnsureNotBlocked(context.TODO(), repo.OwnerID, pull.PosterID)
+	if err != nil {
+		return err
+	}
+
 	sess := x.NewSession()
 	defer sess.Close()
 	if err = sess.Begin(); err != nil {
@@ -452,6 +685,7 @@ func NewPullRequest(repo *Repository, pull *Issue, labelIDs []int64, uuids []str
 		Repo:        repo,
 		Issue:       pull,
 		LableIDs:    labelIDs,
+		AssigneeIDs: assigneeIDs,
 		Attachments: uuids,
 		IsPull:      true,
 	}); err != nil {
@@ -480,6 +714,7 @@ func NewPullRequest(repo *Repository, pull *Issue, labelIDs []int64, uuids []str
 	if err = sess.Commit(); err != nil {
 		return errors.Newf("commit: %v", err)
 	}
+	updateIssueIndexer(pull.ID)
 
 	if err = NotifyWatchers(&Action{
 		ActUserID:    pull.Poster.ID,
@@ -499,6 +734,10 @@ func NewPullRequest(repo *Repository, pull *Issue, labelIDs []int64, uuids []str
 
 	pr.Issue = pull
 	pull.PullRequest = pr
+	if err = pr.updateCodeOwners(); err != nil {
+		log.Error("Failed to update code owners [pull_request_id: %d]: %v", pr.ID, err)
+	}
+
 	if err = PrepareWebhooks(repo, HookEventTypePullRequest, &apiv1types.WebhookPullRequestPayload{
 		Action:      apiv1types.WebhookIssueOpened,
 		Index:       pull.Index,
@@ -791,6 +1030,26 @@ func AddTestPullRequestTask(doer *User, repoID int64, branch string, isSync bool
 					log.Error("LoadAttributes: %v", err)
 					continue
 				}
+
+				// Pushes from users without write access should not be merged
+				// without another review.
+				if pr.IsAutoMergeScheduled() &&
+					!Handle.Permissions().Authorize(context.TODO(), doer.ID, pr.BaseRepoID, AccessModeWrite,
+						AccessModeOptions{
+							OwnerID: pr.BaseRepo.OwnerID,
+							Private: pr.BaseRepo.IsPrivate,
+						},
+					) {
+					if err = pr.CancelAutoMerge(doer, AutoMergeReasonNewCommits); err != nil {
+						log.Error("Failed to cancel auto-merge [pull_id: %v]: %v", pr.ID, err)
+					}
+				}
+
+				// Sign-offs of code owners only cover the reviewed commits.
+				if err = pr.dismissCodeOwnerSignOffs(); err != nil {
+					log.Error("Failed to dismiss sign-offs of code owners [pull_id: %v]: %v", pr.ID, err)
+				}
+
 				if err = PrepareWebhooks(pr.Issue.Repo, HookEventTypePullRequest, &apiv1types.WebhookPullRequestPayload{
 					Action:      apiv1types.WebhookIssueSynchronized,
 					Index:       pr.Issue.Index,
@@ -830,6 +1089,11 @@ func (pr *PullRequest) checkAndUpdateStatus() {
 	if !PullRequestQueue.Exist(pr.ID) {
 		if err := pr.UpdateCols("status"); err != nil {
 			log.Error("Update[%d]: %v", pr.ID, err)
+			return
+		}
+
+		if pr.Status == PullRequestStatusMergeable && pr.IsAutoMergeScheduled() {
+			go AutoMergeQueue.Add(pr.ID)
 		}
 	}
 }
@@ -859,6 +1123,9 @@ func TestPullRequests() {
 
 	// Update pull request status.
 	for _, pr := range prs {
+		if err := pr.updateCodeOwners(); err != nil {
+			log.Error("Failed to update code owners [pull_request_id: %d]: %v", pr.ID, err)
+		}
 		pr.checkAndUpdateStatus()
 	}
 
@@ -877,6 +1144,11 @@ func TestPullRequests() {
 			continue
 		}
 
+		// Changed files or the CODEOWNERS file of the base branch might have
+		// been changed.
+		if err = pr.updateCodeOwners(); err != nil {
+			log.Error("Failed to update code owners [pull_request_id: %d]: %v", pr.ID, err)
+		}
 		pr.checkAndUpdateStatus()
 	}
 }
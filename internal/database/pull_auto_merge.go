@@ -0,0 +1,259 @@
+package database
+
+import (
+	"context"
+	"slices"
+	"strconv"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gogs/git-module"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/sync"
+)
+
+// AutoMergeQueue is the queue of pull requests that have been scheduled for
+// auto-merge and become mergeable.
+var AutoMergeQueue = sync.NewUniqueQueue(1000)
+
+// Reasons of cancelled or failed auto-merges, which are stored as content of
+// timeline comments.
+const (
+	AutoMergeReasonNewCommits       = "new_commits"
+	AutoMergeReasonPermissionDenied = "permission_denied"
+	AutoMergeReasonNotWhitelisted   = "not_whitelisted"
+	AutoMergeReasonStyleNotAllowed  = "merge_style_not_allowed"
+	AutoMergeReasonNotFastForward   = "not_fast_forward"
+	AutoMergeReasonMergeCommits     = "merge_commits"
+	AutoMergeReasonUnverified       = "unverified_commits"
+	AutoMergeReasonInternalError    = "internal_error"
+)
+
+// IsAutoMergeScheduled returns true if the pull request has been scheduled to
+// be merged automatically.
+func (pr *PullRequest) IsAutoMergeScheduled() bool {
+	return pr.AutoMergeStyle != ""
+}
+
+// AutoMerger returns the user who scheduled the auto-merge.
+func (pr *PullRequest) AutoMerger() (*User, error) {
+	return Handle.Users().GetByID(context.TODO(), pr.AutoMergerID)
+}
+
+// updateAutoMerge saves auto-merge options of the pull request along with a
+// timeline comment of given type and content.
+// This method assumes following fields have been assigned with valid values:
+// Required - Issue, Issue.Repo
+func (pr *PullRequest) updateAutoMerge(doer *User, cmtType CommentType, content string) (err error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err = sess.ID(pr.ID).Cols("auto_merge_style", "auto_merger_id", "auto_merge_description").Update(pr); err != nil {
+		return errors.Newf("update pull request: %v", err)
+	}
+
+	if _, err = createComment(sess, &CreateCommentOptions{
+		Type:    cmtType,
+		Doer:    doer,
+		Repo:    pr.Issue.Repo,
+		Issue:   pr.Issue,
+		Content: content,
+	}); err != nil {
+		return errors.Newf("create comment: %v", err)
+	}
+
+	return sess.Commit()
+}
+
+// ScheduleAutoMerge schedules the pull request to be merged automatically by
+// the doer with given merge style once it becomes mergeable. It returns
+// ErrMergeStyleNotAllowed if the merge style is not allowed for the base
+// branch.
+// This method assumes following fields have been assigned with valid values:
+// Required - Issue, Issue.Repo, BaseRepo
+func (pr *PullRequest) ScheduleAutoMerge(doer *User, mergeStyle MergeStyle, commitDescription string) error {
+	mergeStyles, err := pr.BaseRepo.AllowedMergeStyles(pr.BaseBranch)
+	if err != nil {
+		return errors.Newf("get allowed merge styles: %v", err)
+	} else if !slices.Contains(mergeStyles, mergeStyle) {
+		return ErrMergeStyleNotAllowed{args: map[string]any{"repoID": pr.BaseRepoID, "branch": pr.BaseBranch, "mergeStyle": mergeStyle}}
+	}
+
+	pr.AutoMergeStyle = mergeStyle
+	pr.AutoMergerID = doer.ID
+	pr.AutoMergeDescription = commitDescription
+	if err = pr.updateAutoMerge(doer, CommentTypeAutoMergeSchedule, string(mergeStyle)); err != nil {
+		return err
+	}
+
+	if pr.Status == PullRequestStatusMergeable {
+		go AutoMergeQueue.Add(pr.ID)
+	}
+	return nil
+}
+
+// CancelAutoMerge cancels the scheduled auto-merge of the pull request with an
+// optional reason.
+// This method assumes following fields have been assigned with valid values:
+// Required - Issue, Issue.Repo
+func (pr *PullRequest) CancelAutoMerge(doer *User, reason string) error {
+	pr.AutoMergeStyle = ""
+	pr.AutoMergerID = 0
+	pr.AutoMergeDescription = ""
+	return pr.updateAutoMerge(doer, CommentTypeAutoMergeCancel, reason)
+}
+
+// failAutoMerge cancels the scheduled auto-merge of the pull request because of
+// the failure with given reason.
+func (pr *PullRequest) failAutoMerge(merger *User, reason string) {
+	pr.AutoMergeStyle = ""
+	pr.AutoMergerID = 0
+	pr.AutoMergeDescription = ""
+	if err := pr.updateAutoMerge(merger, CommentTypeAutoMergeFail, reason); err != nil {
+		log.Error("Failed to cancel auto-merge [pull_request_id: %d]: %v", pr.ID, err)
+	}
+}
+
+// autoMerge merges the pull request with the scheduled options when the merger
+// still has write access and protection rules of the base branch allow the
+// merge. The result is reported by a timeline comment.
+func (pr *PullRequest) autoMerge() {
+	// The auto-merge might have been cancelled, or the pull request might have
+	// been merged or received new commits since added to the queue.
+	if !pr.IsAutoMergeScheduled() || pr.HasMerged || pr.Status != PullRequestStatusMergeable {
+		return
+	}
+
+	if err := pr.LoadIssue(); err != nil {
+		log.Error("Failed to load issue [pull_request_id: %d]: %v", pr.ID, err)
+		return
+	} else if pr.Issue.IsClosed {
+		return
+	}
+
+	// The pull request stays scheduled while blocked by open issues, and is added
+	// to the queue again once all of them are closed.
+	blocked, err := pr.Issue.HasOpenDependencies()
+	if err != nil {
+		log.Error("Failed to check open dependencies [pull_request_id: %d]: %v", pr.ID, err)
+		return
+	} else if blocked {
+		return
+	}
+
+	// The pull request stays scheduled until code owners have signed off, and is
+	// added to the queue again on each sign-off.
+	if err = pr.checkCodeOwnerSignOffs(); err != nil {
+		if !IsErrCodeOwnerSignOffRequired(err) {
+			log.Error("Failed to check sign-offs of code owners [pull_request_id: %d]: %v", pr.ID, err)
+		}
+		return
+	}
+
+	ctx := context.TODO()
+	merger, err := pr.AutoMerger()
+	if err != nil {
+		if IsErrUserNotExist(err) {
+			pr.failAutoMerge(NewGhostUser(), AutoMergeReasonPermissionDenied)
+		} else {
+			log.Error("Failed to get auto-merger [pull_request_id: %d, user_id: %d]: %v", pr.ID, pr.AutoMergerID, err)
+		}
+		return
+	}
+
+	if !Handle.Permissions().Authorize(ctx, merger.ID, pr.BaseRepoID, AccessModeWrite,
+		AccessModeOptions{
+			OwnerID: pr.BaseRepo.OwnerID,
+			Private: pr.BaseRepo.IsPrivate,
+		},
+	) {
+		pr.failAutoMerge(merger, AutoMergeReasonPermissionDenied)
+		return
+	}
+
+	protectBranch, err := GetProtectBranchOfRepoByName(pr.BaseRepoID, pr.BaseBranch)
+	if err != nil {
+		if !IsErrBranchNotExist(err) {
+			log.Error("Failed to get protect branch [repo_id: %d, branch: %s]: %v", pr.BaseRepoID, pr.BaseBranch, err)
+			return
+		}
+	} else if protectBranch.Protected && protectBranch.EnableWhitelist &&
+		!protectBranch.IsUserWhitelisted(merger.ID) {
+		pr.failAutoMerge(merger, AutoMergeReasonNotWhitelisted)
+		return
+	}
+
+	baseGitRepo, err := git.Open(pr.BaseRepo.RepoPath())
+	if err != nil {
+		log.Error("Failed to open repository [repo_id: %d]: %v", pr.BaseRepoID, err)
+		pr.failAutoMerge(merger, AutoMergeReasonInternalError)
+		return
+	}
+
+	// Clear auto-merge options so they are saved along with the merge.
+	mergeStyle, commitDescription := pr.AutoMergeStyle, pr.AutoMergeDescription
+	pr.AutoMergeStyle = ""
+	pr.AutoMergerID = 0
+	pr.AutoMergeDescription = ""
+
+	err = pr.Merge(merger, baseGitRepo, mergeStyle, commitDescription)
+	if err != nil {
+		reason := AutoMergeReasonInternalError
+		switch {
+		case IsErrMergeStyleNotAllowed(err):
+			reason = AutoMergeReasonStyleNotAllowed
+		case IsErrPullRequestNotFastForward(err):
+			reason = AutoMergeReasonNotFastForward
+		case IsErrPullRequestHasMergeCommits(err):
+			reason = AutoMergeReasonMergeCommits
+		case IsErrPullRequestHasUnverifiedCommits(err):
+			reason = AutoMergeReasonUnverified
+		default:
+			log.Error("Failed to auto-merge pull request [id: %d]: %v", pr.ID, err)
+		}
+		pr.failAutoMerge(merger, reason)
+		return
+	}
+	log.Trace("Pull request auto-merged: %d", pr.ID)
+
+	if _, err = CreateComment(&CreateCommentOptions{
+		Type:    CommentTypeAutoMergeSuccess,
+		Doer:    merger,
+		Repo:    pr.Issue.Repo,
+		Issue:   pr.Issue,
+		Content: string(mergeStyle),
+	}); err != nil {
+		log.Error("Failed to create auto-merge comment [pull_request_id: %d]: %v", pr.ID, err)
+	}
+}
+
+// AutoMergePullRequests merges pull requests that have been scheduled for
+// auto-merge once they become mergeable.
+func AutoMergePullRequests() {
+	// Pick up pull requests that became mergeable before the start.
+	_ = x.Where("status = ? AND auto_merge_style <> ''", PullRequestStatusMergeable).Iterate(new(PullRequest),
+		func(idx int, bean any) error {
+			go AutoMergeQueue.Add(bean.(*PullRequest).ID)
+			return nil
+		})
+
+	for prID := range AutoMergeQueue.Queue() {
+		log.Trace("AutoMergePullRequests[%v]: processing auto-merge task", prID)
+		AutoMergeQueue.Remove(prID)
+
+		id, _ := strconv.ParseInt(prID, 10, 64)
+		pr, err := GetPullRequestByID(id)
+		if err != nil {
+			log.Error("GetPullRequestByID[%s]: %v", prID, err)
+			continue
+		}
+		pr.autoMerge()
+	}
+}
+
+func InitAutoMergePullRequests() {
+	go AutoMergePullRequests()
+}
@@ -0,0 +1,149 @@
+package database
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiveAutoMergeQueue returns the next ID in the auto-merge queue, or an
+// empty string if nothing is added within the timeout.
+func receiveAutoMergeQueue(timeout time.Duration) string {
+	select {
+	case id := <-AutoMergeQueue.Queue():
+		AutoMergeQueue.Remove(id)
+		return id
+	case <-time.After(timeout):
+		return ""
+	}
+}
+
+// lastComment returns the latest comment of the issue.
+func lastComment(t *testing.T, issueID int64) *Comment {
+	comment := new(Comment)
+	has, err := x.Where("issue_id = ?", issueID).Desc("id").Get(comment)
+	require.NoError(t, err)
+	require.True(t, has)
+	return comment
+}
+
+func TestPullRequest_AutoMerge(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	newLegacyTestDB(t, "TestPullRequest_AutoMerge")
+
+	owner := createLegacyTestUser(t, "alice")
+	outsider := createLegacyTestUser(t, "bob")
+	repo := createLegacyTestRepo(t, owner, "repo", true)
+	pr := createLegacyTestPullRequest(t, repo, owner, "feature")
+	prID := strconv.FormatInt(pr.ID, 10)
+
+	// reload returns the pull request as saved in the database.
+	reload := func(t *testing.T) *PullRequest {
+		got, err := GetPullRequestByID(pr.ID)
+		require.NoError(t, err)
+		return got
+	}
+	// schedule saves auto-merge options of the pull request directly.
+	schedule := func(t *testing.T, merger *User, status PullRequestStatus) {
+		pr.AutoMergeStyle = MergeStyleRegular
+		pr.AutoMergerID = merger.ID
+		pr.AutoMergeDescription = ""
+		pr.Status = status
+		_, err := x.ID(pr.ID).Cols("status", "auto_merge_style", "auto_merger_id", "auto_merge_description").Update(pr)
+		require.NoError(t, err)
+	}
+
+	t.Run("schedule with a merge style not allowed", func(t *testing.T) {
+		err := pr.ScheduleAutoMerge(owner, MergeStyleSquash, "")
+		wantErr := ErrMergeStyleNotAllowed{args: map[string]any{"repoID": repo.ID, "branch": "main", "mergeStyle": MergeStyleSquash}}
+		assert.Equal(t, wantErr, err)
+		assert.False(t, reload(t).IsAutoMergeScheduled())
+	})
+
+	t.Run("schedule", func(t *testing.T) {
+		err := pr.ScheduleAutoMerge(owner, MergeStyleRegular, "Merge it")
+		require.NoError(t, err)
+
+		got := reload(t)
+		assert.Equal(t, MergeStyleRegular, got.AutoMergeStyle)
+		assert.Equal(t, owner.ID, got.AutoMergerID)
+		assert.Equal(t, "Merge it", got.AutoMergeDescription)
+
+		comment := lastComment(t, pr.IssueID)
+		assert.Equal(t, CommentTypeAutoMergeSchedule, comment.Type)
+		assert.Equal(t, string(MergeStyleRegular), comment.Content)
+
+		// The pull request is already mergeable
+		assert.Equal(t, prID, receiveAutoMergeQueue(time.Second))
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		err := pr.CancelAutoMerge(owner, AutoMergeReasonNewCommits)
+		require.NoError(t, err)
+
+		got := reload(t)
+		assert.False(t, got.IsAutoMergeScheduled())
+		assert.Zero(t, got.AutoMergerID)
+
+		comment := lastComment(t, pr.IssueID)
+		assert.Equal(t, CommentTypeAutoMergeCancel, comment.Type)
+		assert.Equal(t, AutoMergeReasonNewCommits, comment.Content)
+	})
+
+	t.Run("queue when becoming mergeable", func(t *testing.T) {
+		schedule(t, owner, PullRequestStatusChecking)
+		pr.checkAndUpdateStatus()
+		assert.Equal(t, PullRequestStatusMergeable, reload(t).Status)
+		assert.Equal(t, prID, receiveAutoMergeQueue(time.Second))
+
+		// Conflicts do not queue the pull request
+		schedule(t, owner, PullRequestStatusConflict)
+		pr.checkAndUpdateStatus()
+		assert.Empty(t, receiveAutoMergeQueue(100*time.Millisecond))
+	})
+
+	t.Run("skip when not mergeable", func(t *testing.T) {
+		schedule(t, owner, PullRequestStatusConflict)
+		comment := lastComment(t, pr.IssueID)
+
+		pr.autoMerge()
+		assert.True(t, reload(t).IsAutoMergeScheduled())
+		assert.Equal(t, comment.ID, lastComment(t, pr.IssueID).ID)
+	})
+
+	t.Run("merger lost write access", func(t *testing.T) {
+		schedule(t, outsider, PullRequestStatusMergeable)
+
+		pr.autoMerge()
+		assert.False(t, reload(t).IsAutoMergeScheduled())
+
+		comment := lastComment(t, pr.IssueID)
+		assert.Equal(t, CommentTypeAutoMergeFail, comment.Type)
+		assert.Equal(t, outsider.ID, comment.PosterID)
+		assert.Equal(t, AutoMergeReasonPermissionDenied, comment.Content)
+	})
+
+	t.Run("merger not in whitelist", func(t *testing.T) {
+		_, err := x.Insert(&ProtectBranch{
+			RepoID:          repo.ID,
+			Name:            "main",
+			Protected:       true,
+			EnableWhitelist: true,
+		})
+		require.NoError(t, err)
+		schedule(t, owner, PullRequestStatusMergeable)
+
+		pr.autoMerge()
+		assert.False(t, reload(t).IsAutoMergeScheduled())
+
+		comment := lastComment(t, pr.IssueID)
+		assert.Equal(t, CommentTypeAutoMergeFail, comment.Type)
+		assert.Equal(t, AutoMergeReasonNotWhitelisted, comment.Content)
+	})
+}
@@ -0,0 +1,637 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gogs/git-module"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/codeowners"
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/email"
+	"gogs.io/gogs/internal/gitx"
+	"gogs.io/gogs/internal/tool"
+)
+
+// CodeOwner represents code owners of a changed file of a pull request, which
+// are mapped by the CODEOWNERS file of the base branch. Files without owners
+// are not stored.
+type CodeOwner struct {
+	ID      int64
+	IssueID int64  `xorm:"INDEX"`
+	Path    string `xorm:"TEXT"`
+	// Pattern is the pattern of the rule in the CODEOWNERS file that maps the
+	// file to the owners.
+	Pattern  string `xorm:"TEXT"`
+	OwnerIDs string `xorm:"TEXT"`
+	TeamIDs  string `xorm:"TEXT"`
+
+	Owners    []*User `xorm:"-" json:"-" gorm:"-"`
+	Teams     []*Team `xorm:"-" json:"-" gorm:"-"`
+	SignedOff bool    `xorm:"-" json:"-" gorm:"-"`
+}
+
+// CodeOwnerSignOff represents a sign-off on a pull request by a code owner.
+type CodeOwnerSignOff struct {
+	ID          int64
+	IssueID     int64 `xorm:"UNIQUE(s)"`
+	UserID      int64 `xorm:"UNIQUE(s)"`
+	CreatedUnix int64
+}
+
+func (s *CodeOwnerSignOff) BeforeInsert() {
+	s.CreatedUnix = time.Now().Unix()
+}
+
+// isOwnedBy returns true if the user is an owner of the file either directly
+// or through any of given teams of the user.
+func (o *CodeOwner) isOwnedBy(userID int64, teamIDs []int64) bool {
+	if slices.Contains(parseIDs(o.OwnerIDs), userID) {
+		return true
+	}
+	for _, teamID := range parseIDs(o.TeamIDs) {
+		if slices.Contains(teamIDs, teamID) {
+			return true
+		}
+	}
+	return false
+}
+
+// getUserTeamIDs returns IDs of all teams that the user is a member of.
+func getUserTeamIDs(e Engine, userID int64) ([]int64, error) {
+	teamUsers := make([]*TeamUser, 0, 5)
+	if err := e.Where("uid = ?", userID).Find(&teamUsers); err != nil {
+		return nil, err
+	}
+	teamIDs := make([]int64, len(teamUsers))
+	for i := range teamUsers {
+		teamIDs[i] = teamUsers[i].TeamID
+	}
+	return teamIDs, nil
+}
+
+// GetCodeOwners returns code owners of changed files of the pull request with
+// owners, teams and sign-off status loaded, ordered by paths. Sign-offs by the
+// author of the pull request are not counted.
+func (pr *PullRequest) GetCodeOwners() ([]*CodeOwner, error) {
+	if err := pr.LoadIssue(); err != nil {
+		return nil, errors.Newf("load issue: %v", err)
+	}
+
+	codeOwners := make([]*CodeOwner, 0, 10)
+	if err := x.Where("issue_id = ?", pr.IssueID).Asc("id").Find(&codeOwners); err != nil {
+		return nil, errors.Newf("find code owners: %v", err)
+	}
+
+	signOffs := make([]*CodeOwnerSignOff, 0, 2)
+	if err := x.Where("issue_id = ? AND user_id != ?", pr.IssueID, pr.Issue.PosterID).Find(&signOffs); err != nil {
+		return nil, errors.Newf("find sign-offs: %v", err)
+	}
+	signOffTeamIDs := make([][]int64, len(signOffs))
+	for i, s := range signOffs {
+		teamIDs, err := getUserTeamIDs(x, s.UserID)
+		if err != nil {
+			return nil, errors.Newf("get team IDs of user [%d]: %v", s.UserID, err)
+		}
+		signOffTeamIDs[i] = teamIDs
+	}
+
+	users := make(map[int64]*User)
+	teams := make(map[int64]*Team)
+	for _, o := range codeOwners {
+		for _, id := range parseIDs(o.OwnerIDs) {
+			if users[id] == nil {
+				u, err := getUserByID(x, id)
+				if err != nil {
+					if IsErrUserNotExist(err) {
+						continue
+					}
+					return nil, errors.Newf("get user by ID [%d]: %v", id, err)
+				}
+				users[id] = u
+			}
+			o.Owners = append(o.Owners, users[id])
+		}
+		for _, id := range parseIDs(o.TeamIDs) {
+			if teams[id] == nil {
+				t, err := getTeamByID(x, id)
+				if err != nil {
+					if IsErrTeamNotExist(err) {
+						continue
+					}
+					return nil, errors.Newf("get team by ID [%d]: %v", id, err)
+				}
+				teams[id] = t
+			}
+			o.Teams = append(o.Teams, teams[id])
+		}
+
+		for i, s := range signOffs {
+			if o.isOwnedBy(s.UserID, signOffTeamIDs[i]) {
+				o.SignedOff = true
+				break
+			}
+		}
+	}
+	return codeOwners, nil
+}
+
+// IsCodeOwner returns true if the user owns any changed file of the pull
+// request.
+func (pr *PullRequest) IsCodeOwner(userID int64) (bool, error) {
+	codeOwners := make([]*CodeOwner, 0, 10)
+	if err := x.Where("issue_id = ?", pr.IssueID).Find(&codeOwners); err != nil {
+		return false, errors.Newf("find code owners: %v", err)
+	}
+	teamIDs, err := getUserTeamIDs(x, userID)
+	if err != nil {
+		return false, errors.Newf("get team IDs of user: %v", err)
+	}
+	for _, o := range codeOwners {
+		if o.isOwnedBy(userID, teamIDs) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// HasSignedOff returns true if the user has signed off on the pull request as
+// a code owner.
+func (pr *PullRequest) HasSignedOff(userID int64) (bool, error) {
+	return x.Where("issue_id = ? AND user_id = ?", pr.IssueID, userID).Exist(new(CodeOwnerSignOff))
+}
+
+// GetSignedOffUsers returns users who have signed off on the pull request as
+// code owners, in the order of sign-offs. The author of the pull request is
+// excluded.
+func (pr *PullRequest) GetSignedOffUsers() ([]*User, error) {
+	if err := pr.LoadIssue(); err != nil {
+		return nil, errors.Newf("load issue: %v", err)
+	}
+
+	users := make([]*User, 0, 2)
+	return users, x.Join("INNER", "code_owner_sign_off", "code_owner_sign_off.user_id = `user`.id").
+		Where("code_owner_sign_off.issue_id = ? AND code_owner_sign_off.user_id != ?", pr.IssueID, pr.Issue.PosterID).
+		Asc("code_owner_sign_off.id").
+		Find(&users)
+}
+
+// IsCodeOwnerSignOffRequired returns true if protection rules of the base
+// branch require sign-offs of code owners to merge the pull request.
+func (pr *PullRequest) IsCodeOwnerSignOffRequired() (bool, error) {
+	protectBranch, err := GetProtectBranchOfRepoByName(pr.BaseRepoID, pr.BaseBranch)
+	if err != nil {
+		if IsErrBranchNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return protectBranch.Protected && protectBranch.RequireCodeOwnerSignOff, nil
+}
+
+// HasAllCodeOwnerSignOffs returns true if a code owner of every owned file of
+// the pull request has signed off.
+func (pr *PullRequest) HasAllCodeOwnerSignOffs() (bool, error) {
+	codeOwners, err := pr.GetCodeOwners()
+	if err != nil {
+		return false, err
+	}
+	for _, o := range codeOwners {
+		if !o.SignedOff {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// checkCodeOwnerSignOffs returns ErrCodeOwnerSignOffRequired if protection
+// rules of the base branch require sign-offs of code owners and any owned file
+// has not been signed off.
+func (pr *PullRequest) checkCodeOwnerSignOffs() error {
+	required, err := pr.IsCodeOwnerSignOffRequired()
+	if err != nil {
+		return errors.Newf("check if sign-offs are required: %v", err)
+	} else if !required {
+		return nil
+	}
+
+	signedOff, err := pr.HasAllCodeOwnerSignOffs()
+	if err != nil {
+		return errors.Newf("check sign-offs: %v", err)
+	} else if !signedOff {
+		return ErrCodeOwnerSignOffRequired{args: map[string]any{"pullRequestID": pr.ID}}
+	}
+	return nil
+}
+
+// setCodeOwnerSignOff saves or removes the sign-off of the doer along with a
+// timeline comment.
+// This method assumes following fields have been assigned with valid values:
+// Required - Issue, Issue.Repo
+func (pr *PullRequest) setCodeOwnerSignOff(doer *User, signOff bool) (err error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	cmtType := CommentTypeCodeOwnerSignOff
+	if signOff {
+		if _, err = sess.Insert(&CodeOwnerSignOff{
+			IssueID: pr.IssueID,
+			UserID:  doer.ID,
+		}); err != nil {
+			return errors.Newf("insert sign-off: %v", err)
+		}
+	} else {
+		cmtType = CommentTypeCodeOwnerSignOffWithdraw
+		if _, err = sess.Delete(&CodeOwnerSignOff{
+			IssueID: pr.IssueID,
+			UserID:  doer.ID,
+		}); err != nil {
+			return errors.Newf("delete sign-off: %v", err)
+		}
+	}
+
+	if _, err = createComment(sess, &CreateCommentOptions{
+		Type:  cmtType,
+		Doer:  doer,
+		Repo:  pr.Issue.Repo,
+		Issue: pr.Issue,
+	}); err != nil {
+		return errors.Newf("create comment: %v", err)
+	}
+
+	return sess.Commit()
+}
+
+// SignOffAsCodeOwner records a sign-off on the pull request by the doer. It
+// returns ErrSelfCodeOwnerSignOff if the doer is the author of the pull
+// request, or ErrNotCodeOwner if the doer does not own any changed file.
+// This method assumes following fields have been assigned with valid values:
+// Required - Issue, Issue.Repo
+func (pr *PullRequest) SignOffAsCodeOwner(doer *User) error {
+	if doer.ID == pr.Issue.PosterID {
+		return ErrSelfCodeOwnerSignOff{args: map[string]any{"pullRequestID": pr.ID, "userID": doer.ID}}
+	}
+
+	isOwner, err := pr.IsCodeOwner(doer.ID)
+	if err != nil {
+		return errors.Newf("check code owner: %v", err)
+	} else if !isOwner {
+		return ErrNotCodeOwner{args: map[string]any{"pullRequestID": pr.ID, "userID": doer.ID}}
+	}
+
+	signedOff, err := pr.HasSignedOff(doer.ID)
+	if err != nil {
+		return errors.Newf("check sign-off: %v", err)
+	} else if signedOff {
+		return nil
+	}
+
+	if err = pr.setCodeOwnerSignOff(doer, true); err != nil {
+		return err
+	}
+
+	// The pull request might be waiting for sign-offs to be merged automatically.
+	if pr.IsAutoMergeScheduled() && pr.Status == PullRequestStatusMergeable {
+		go AutoMergeQueue.Add(pr.ID)
+	}
+	return nil
+}
+
+// WithdrawCodeOwnerSignOff removes the sign-off on the pull request by the
+// doer.
+// This method assumes following fields have been assigned with valid values:
+// Required - Issue, Issue.Repo
+func (pr *PullRequest) WithdrawCodeOwnerSignOff(doer *User) error {
+	signedOff, err := pr.HasSignedOff(doer.ID)
+	if err != nil {
+		return errors.Newf("check sign-off: %v", err)
+	} else if !signedOff {
+		return nil
+	}
+	return pr.setCodeOwnerSignOff(doer, false)
+}
+
+// dismissCodeOwnerSignOffs removes all sign-offs on the pull request, which is
+// done when new commits are pushed to the head branch.
+func (pr *PullRequest) dismissCodeOwnerSignOffs() error {
+	_, err := x.Delete(&CodeOwnerSignOff{IssueID: pr.IssueID})
+	return err
+}
+
+// readCodeOwners returns the parsed CODEOWNERS file in given commit, or nil if
+// there is none.
+func readCodeOwners(commit *git.Commit) (*codeowners.File, error) {
+	for _, p := range codeowners.Paths {
+		blob, err := commit.Blob(p)
+		if err != nil {
+			if gitx.IsErrRevisionNotExist(err) || err == git.ErrNotBlob {
+				continue
+			}
+			return nil, errors.Newf("get blob %q: %v", p, err)
+		}
+
+		content, err := blob.Bytes()
+		if err != nil {
+			return nil, errors.Newf("read blob %q: %v", p, err)
+		}
+		return codeowners.Parse(content), nil
+	}
+	return nil, nil
+}
+
+// changedFiles returns paths of changed files of the pull request, including
+// old paths of renamed files, parsed from the saved patch.
+func (pr *PullRequest) changedFiles() ([]string, error) {
+	patchPath, err := pr.BaseRepo.PatchPath(pr.Index)
+	if err != nil {
+		return nil, errors.Newf("get patch path: %v", err)
+	}
+
+	f, err := os.Open(patchPath)
+	if err != nil {
+		return nil, errors.Newf("open patch: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	// Only file headers are needed, parse as few lines as possible.
+	diff, err := gitx.ParseDiff(f, 0, 1, 1)
+	if err != nil {
+		return nil, errors.Newf("parse diff: %v", err)
+	}
+
+	paths := make([]string, 0, len(diff.Files))
+	for _, file := range diff.Files {
+		paths = append(paths, file.Name)
+		if file.IsRenamed() && file.OldName() != file.Name {
+			paths = append(paths, file.OldName())
+		}
+	}
+	return paths, nil
+}
+
+// codeOwnerResolver resolves owners in CODEOWNERS files to users and teams
+// that have write access to the repository. Owners that cannot be resolved are
+// ignored.
+type codeOwnerResolver struct {
+	repo  *Repository
+	users map[string]int64
+	teams map[string]int64
+}
+
+func newCodeOwnerResolver(repo *Repository) *codeOwnerResolver {
+	return &codeOwnerResolver{
+		repo:  repo,
+		users: make(map[string]int64),
+		teams: make(map[string]int64),
+	}
+}
+
+// resolve returns the ID of the user or the team of the owner, where one of
+// them is 0. Both are 0 if the owner cannot be resolved.
+func (r *codeOwnerResolver) resolve(owner string) (userID, teamID int64) {
+	owner = strings.ToLower(owner)
+	if id, ok := r.users[owner]; ok {
+		return id, 0
+	} else if id, ok = r.teams[owner]; ok {
+		return 0, id
+	}
+
+	ctx := context.TODO()
+	orgName, teamName, isTeam := strings.Cut(strings.TrimPrefix(owner, "@"), "/")
+	switch {
+	case strings.HasPrefix(owner, "@") && isTeam:
+		if orgName == r.repo.Owner.LowerName && r.repo.Owner.IsOrganization() {
+			team, err := GetTeamOfOrgByName(r.repo.OwnerID, teamName)
+			if err == nil && team.HasWriteAccess() && team.HasRepository(r.repo.ID) {
+				teamID = team.ID
+			}
+		}
+		r.teams[owner] = teamID
+		return 0, teamID
+
+	case strings.HasPrefix(owner, "@"):
+		u, err := Handle.Users().GetByUsername(ctx, orgName)
+		if err == nil && !u.IsOrganization() {
+			userID = u.ID
+		}
+	default:
+		u, err := Handle.Users().GetByEmail(ctx, owner)
+		if err == nil {
+			userID = u.ID
+		}
+	}
+
+	if userID > 0 && !Handle.Permissions().Authorize(ctx, userID, r.repo.ID, AccessModeWrite,
+		AccessModeOptions{
+			OwnerID: r.repo.OwnerID,
+			Private: r.repo.IsPrivate,
+		},
+	) {
+		userID = 0
+	}
+	r.users[owner] = userID
+	return userID, 0
+}
+
+// mapCodeOwners maps changed files in given paths to their owners by given
+// CODEOWNERS file.
+func mapCodeOwners(issueID int64, file *codeowners.File, paths []string, resolve func(owner string) (userID, teamID int64)) []*CodeOwner {
+	codeOwners := make([]*CodeOwner, 0, len(paths))
+	for _, p := range paths {
+		rule := file.Match(p)
+		if rule == nil {
+			continue
+		}
+
+		var ownerIDs, teamIDs []int64
+		for _, owner := range rule.Owners {
+			userID, teamID := resolve(owner)
+			if userID > 0 && !slices.Contains(ownerIDs, userID) {
+				ownerIDs = append(ownerIDs, userID)
+			} else if teamID > 0 && !slices.Contains(teamIDs, teamID) {
+				teamIDs = append(teamIDs, teamID)
+			}
+		}
+		if len(ownerIDs) == 0 && len(teamIDs) == 0 {
+			continue
+		}
+
+		codeOwners = append(codeOwners, &CodeOwner{
+			IssueID:  issueID,
+			Path:     p,
+			Pattern:  rule.Pattern,
+			OwnerIDs: strings.Join(tool.Int64sToStrings(ownerIDs), ","),
+			TeamIDs:  strings.Join(tool.Int64sToStrings(teamIDs), ","),
+		})
+	}
+	return codeOwners
+}
+
+// updateCodeOwners maps changed files of the pull request to code owners by the
+// CODEOWNERS file of the base branch, and notifies owners that are newly
+// mapped.
+func (pr *PullRequest) updateCodeOwners() (err error) {
+	if err = pr.LoadAttributes(); err != nil {
+		return errors.Newf("load attributes: %v", err)
+	} else if err = pr.BaseRepo.GetOwner(); err != nil {
+		return errors.Newf("get owner: %v", err)
+	}
+
+	var codeOwners []*CodeOwner
+	baseGitRepo, err := git.Open(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return errors.Newf("open repository: %v", err)
+	}
+	commit, err := baseGitRepo.BranchCommit(pr.BaseBranch)
+	if err != nil {
+		return errors.Newf("get commit of base branch: %v", err)
+	}
+	file, err := readCodeOwners(commit)
+	if err != nil {
+		return errors.Newf("read CODEOWNERS: %v", err)
+	} else if file != nil {
+		paths, err := pr.changedFiles()
+		if err != nil {
+			return errors.Newf("get changed files: %v", err)
+		}
+		codeOwners = mapCodeOwners(pr.IssueID, file, paths, newCodeOwnerResolver(pr.BaseRepo).resolve)
+	}
+
+	oldCodeOwners := make([]*CodeOwner, 0, 10)
+	if err = x.Where("issue_id = ?", pr.IssueID).Find(&oldCodeOwners); err != nil {
+		return errors.Newf("find code owners: %v", err)
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err = sess.Delete(&CodeOwner{IssueID: pr.IssueID}); err != nil {
+		return errors.Newf("delete code owners: %v", err)
+	}
+	// Insert in batches to stay within limits of bound parameters of databases.
+	const batchSize = 100
+	for i := 0; i < len(codeOwners); i += batchSize {
+		batch := codeOwners[i:min(i+batchSize, len(codeOwners))]
+		if _, err = sess.Insert(&batch); err != nil {
+			return errors.Newf("insert code owners: %v", err)
+		}
+	}
+
+	if err = sess.Commit(); err != nil {
+		return err
+	}
+
+	pr.mailNewCodeOwners(oldCodeOwners, codeOwners)
+	return nil
+}
+
+// mailNewCodeOwners notifies users that own files in new code owners but none
+// in old code owners, either directly or through teams.
+func (pr *PullRequest) mailNewCodeOwners(oldCodeOwners, newCodeOwners []*CodeOwner) {
+	if !conf.User.EnableEmailNotification {
+		return
+	}
+
+	collect := func(codeOwners []*CodeOwner) (userIDs []int64) {
+		teamIDs := make([]int64, 0, 2)
+		for _, o := range codeOwners {
+			userIDs = append(userIDs, parseIDs(o.OwnerIDs)...)
+			teamIDs = append(teamIDs, parseIDs(o.TeamIDs)...)
+		}
+		slices.Sort(teamIDs)
+		for _, teamID := range slices.Compact(teamIDs) {
+			members, err := GetTeamMembers(teamID)
+			if err != nil {
+				log.Error("Failed to get team members [team_id: %d]: %v", teamID, err)
+				continue
+			}
+			for _, member := range members {
+				userIDs = append(userIDs, member.ID)
+			}
+		}
+		return userIDs
+	}
+
+	oldUserIDs := collect(oldCodeOwners)
+	newUserIDs := collect(newCodeOwners)
+	slices.Sort(newUserIDs)
+
+	if err := pr.LoadIssue(); err != nil {
+		log.Error("Failed to load issue [pull_request_id: %d]: %v", pr.ID, err)
+		return
+	} else if err = pr.Issue.LoadAttributes(); err != nil {
+		log.Error("Failed to load issue attributes [pull_request_id: %d]: %v", pr.ID, err)
+		return
+	}
+
+	tos := make([]string, 0, len(newUserIDs))
+	for _, userID := range slices.Compact(newUserIDs) {
+		if userID == pr.Issue.PosterID || slices.Contains(oldUserIDs, userID) {
+			continue
+		}
+
+		u, err := Handle.Users().GetByID(context.TODO(), userID)
+		if err != nil {
+			log.Error("Failed to get user [user_id: %d]: %v", userID, err)
+			continue
+		} else if !u.IsActive {
+			continue
+		}
+		tos = append(tos, u.Email)
+	}
+
+	if err := email.SendIssueCodeOwnerMail(NewMailerIssue(pr.Issue), NewMailerRepo(pr.Issue.Repo), NewMailerUser(pr.Issue.Poster), tos); err != nil {
+		log.Error("Failed to send code owner mail [pull_request_id: %d]: %v", pr.ID, err)
+	}
+}
+
+type ErrCodeOwnerSignOffRequired struct {
+	args map[string]any
+}
+
+func IsErrCodeOwnerSignOffRequired(err error) bool {
+	_, ok := err.(ErrCodeOwnerSignOffRequired)
+	return ok
+}
+
+func (err ErrCodeOwnerSignOffRequired) Error() string {
+	return fmt.Sprintf("sign-offs of code owners are required: %v", err.args)
+}
+
+type ErrNotCodeOwner struct {
+	args map[string]any
+}
+
+func IsErrNotCodeOwner(err error) bool {
+	_, ok := err.(ErrNotCodeOwner)
+	return ok
+}
+
+func (err ErrNotCodeOwner) Error() string {
+	return fmt.Sprintf("user is not a code owner: %v", err.args)
+}
+
+type ErrSelfCodeOwnerSignOff struct {
+	args map[string]any
+}
+
+func IsErrSelfCodeOwnerSignOff(err error) bool {
+	_, ok := err.(ErrSelfCodeOwnerSignOff)
+	return ok
+}
+
+func (err ErrSelfCodeOwnerSignOff) Error() string {
+	return fmt.Sprintf("author cannot sign off on own pull request: %v", err.args)
+}
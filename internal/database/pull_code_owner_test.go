@@ -0,0 +1,170 @@
+package database
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/codeowners"
+	"gogs.io/gogs/internal/tool"
+)
+
+func TestMapCodeOwners(t *testing.T) {
+	file := codeowners.Parse([]byte(`*          @alice
+*.go       @bob alice@example.com @unknown @bob
+/docs/     @org/writers @org/unknown @org/writers
+/build/    @nobody
+vendor/
+`))
+	resolve := func(owner string) (userID, teamID int64) {
+		switch owner {
+		case "@alice", "alice@example.com":
+			return 1, 0
+		case "@bob":
+			return 2, 0
+		case "@org/writers":
+			return 0, 10
+		}
+		return 0, 0
+	}
+
+	paths := []string{"README.md", "cmd/main.go", "docs/index.md", "build/out.log", "vendor/lib/lib.go"}
+	got := mapCodeOwners(7, file, paths, resolve)
+	want := []*CodeOwner{
+		{IssueID: 7, Path: "README.md", Pattern: "*", OwnerIDs: "1"},
+		{IssueID: 7, Path: "cmd/main.go", Pattern: "*.go", OwnerIDs: "2,1"},
+		{IssueID: 7, Path: "docs/index.md", Pattern: "/docs/", TeamIDs: "10"},
+	}
+	assert.Equal(t, want, got)
+
+	assert.Empty(t, mapCodeOwners(7, file, nil, resolve))
+}
+
+func TestPullRequest_CodeOwnerSignOffs(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	newLegacyTestDB(t, "TestPullRequest_CodeOwnerSignOffs")
+
+	alice := createLegacyTestUser(t, "alice")
+	bob := createLegacyTestUser(t, "bob")
+	cindy := createLegacyTestUser(t, "cindy")
+	dave := createLegacyTestUser(t, "dave")
+	repo := createLegacyTestRepo(t, alice, "repo", false)
+	pr := createLegacyTestPullRequest(t, repo, bob, "feature")
+
+	team := &Team{OrgID: alice.ID, Name: "writers", LowerName: "writers", Authorize: AccessModeWrite}
+	_, err := x.Insert(team)
+	require.NoError(t, err)
+	_, err = x.Insert(&TeamUser{OrgID: alice.ID, TeamID: team.ID, UID: cindy.ID})
+	require.NoError(t, err)
+	_, err = x.Insert(
+		&CodeOwner{IssueID: pr.IssueID, Path: "main.go", Pattern: "*.go", OwnerIDs: joinIDs(alice.ID, bob.ID)},
+		&CodeOwner{IssueID: pr.IssueID, Path: "docs/index.md", Pattern: "/docs/", TeamIDs: joinIDs(team.ID)},
+	)
+	require.NoError(t, err)
+
+	// signedOffPaths returns paths of owned files that have been signed off.
+	signedOffPaths := func(t *testing.T) []string {
+		t.Helper()
+
+		codeOwners, err := pr.GetCodeOwners()
+		require.NoError(t, err)
+		paths := make([]string, 0, len(codeOwners))
+		for _, o := range codeOwners {
+			if o.SignedOff {
+				paths = append(paths, o.Path)
+			}
+		}
+		return paths
+	}
+	wantErr := ErrCodeOwnerSignOffRequired{args: map[string]any{"pullRequestID": pr.ID}}
+
+	t.Run("not required", func(t *testing.T) {
+		assert.NoError(t, pr.checkCodeOwnerSignOffs())
+
+		_, err := x.Insert(&ProtectBranch{RepoID: repo.ID, Name: "main", Protected: true, RequireCodeOwnerSignOff: true})
+		require.NoError(t, err)
+		assert.Equal(t, wantErr, pr.checkCodeOwnerSignOffs())
+	})
+
+	t.Run("not allowed", func(t *testing.T) {
+		err := pr.SignOffAsCodeOwner(bob)
+		assert.Equal(t, ErrSelfCodeOwnerSignOff{args: map[string]any{"pullRequestID": pr.ID, "userID": bob.ID}}, err)
+
+		err = pr.SignOffAsCodeOwner(dave)
+		assert.Equal(t, ErrNotCodeOwner{args: map[string]any{"pullRequestID": pr.ID, "userID": dave.ID}}, err)
+
+		// Sign-offs by the author saved before are not counted
+		_, err = x.Insert(&CodeOwnerSignOff{IssueID: pr.IssueID, UserID: bob.ID})
+		require.NoError(t, err)
+		assert.Empty(t, signedOffPaths(t))
+		users, err := pr.GetSignedOffUsers()
+		require.NoError(t, err)
+		assert.Empty(t, users)
+	})
+
+	t.Run("sign off", func(t *testing.T) {
+		require.NoError(t, pr.SignOffAsCodeOwner(alice))
+		assert.Equal(t, CommentTypeCodeOwnerSignOff, lastComment(t, pr.IssueID).Type)
+		assert.Equal(t, []string{"main.go"}, signedOffPaths(t))
+		assert.Equal(t, wantErr, pr.checkCodeOwnerSignOffs())
+
+		// Signing off again is a no-op
+		comment := lastComment(t, pr.IssueID)
+		require.NoError(t, pr.SignOffAsCodeOwner(alice))
+		assert.Equal(t, comment.ID, lastComment(t, pr.IssueID).ID)
+
+		// Members of owning teams are code owners
+		require.NoError(t, pr.SignOffAsCodeOwner(cindy))
+		assert.Equal(t, []string{"main.go", "docs/index.md"}, signedOffPaths(t))
+		assert.NoError(t, pr.checkCodeOwnerSignOffs())
+
+		users, err := pr.GetSignedOffUsers()
+		require.NoError(t, err)
+		require.Len(t, users, 2)
+		assert.Equal(t, alice.ID, users[0].ID)
+		assert.Equal(t, cindy.ID, users[1].ID)
+	})
+
+	t.Run("withdraw", func(t *testing.T) {
+		require.NoError(t, pr.WithdrawCodeOwnerSignOff(cindy))
+		assert.Equal(t, CommentTypeCodeOwnerSignOffWithdraw, lastComment(t, pr.IssueID).Type)
+		assert.Equal(t, []string{"main.go"}, signedOffPaths(t))
+		assert.Equal(t, wantErr, pr.checkCodeOwnerSignOffs())
+
+		require.NoError(t, pr.SignOffAsCodeOwner(cindy))
+		assert.NoError(t, pr.checkCodeOwnerSignOffs())
+	})
+
+	t.Run("dismiss on push", func(t *testing.T) {
+		// Pushes to the base branch keep sign-offs, but the pull request is
+		// queued to be tested again.
+		AddTestPullRequestTask(alice, repo.ID, "main", true)
+		select {
+		case id := <-PullRequestQueue.Queue():
+			PullRequestQueue.Remove(id)
+			assert.Equal(t, strconv.FormatInt(pr.ID, 10), id)
+		case <-time.After(time.Second):
+			t.Fatal("pull request is not queued")
+		}
+		assert.NoError(t, pr.checkCodeOwnerSignOffs())
+
+		AddTestPullRequestTask(bob, repo.ID, "feature", true)
+		count, err := x.Where("issue_id = ?", pr.IssueID).Count(new(CodeOwnerSignOff))
+		require.NoError(t, err)
+		assert.Zero(t, count)
+		assert.Empty(t, signedOffPaths(t))
+		assert.Equal(t, wantErr, pr.checkCodeOwnerSignOffs())
+	})
+}
+
+// joinIDs returns given IDs in the format that code owners are saved in.
+func joinIDs(ids ...int64) string {
+	return strings.Join(tool.Int64sToStrings(ids), ",")
+}
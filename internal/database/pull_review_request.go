@@ -0,0 +1,276 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	log "unknwon.dev/clog/v2"
+
+	apiv1types "gogs.io/gogs/internal/route/api/v1/types"
+)
+
+// ReviewRequest represents a request for review of a pull request from either a
+// user or a team.
+type ReviewRequest struct {
+	ID          int64
+	IssueID     int64 `xorm:"INDEX"`
+	ReviewerID  int64 `xorm:"INDEX"`
+	TeamID      int64 `xorm:"INDEX"`
+	CreatedUnix int64
+}
+
+func (r *ReviewRequest) BeforeInsert() {
+	r.CreatedUnix = time.Now().Unix()
+}
+
+func (pr *PullRequest) loadReviewRequests(e Engine) (err error) {
+	requests := make([]*ReviewRequest, 0, 2)
+	if err = e.Where("issue_id = ?", pr.IssueID).Asc("id").Find(&requests); err != nil {
+		return errors.Newf("find review requests: %v", err)
+	}
+
+	pr.RequestedReviewers = make([]*User, 0, len(requests))
+	pr.RequestedTeams = make([]*Team, 0, len(requests))
+	for _, r := range requests {
+		if r.TeamID > 0 {
+			team, err := getTeamByID(e, r.TeamID)
+			if err != nil {
+				if IsErrTeamNotExist(err) {
+					continue
+				}
+				return errors.Newf("get team by ID [%d]: %v", r.TeamID, err)
+			}
+			pr.RequestedTeams = append(pr.RequestedTeams, team)
+			continue
+		}
+
+		reviewer, err := getUserByID(e, r.ReviewerID)
+		if err != nil {
+			if IsErrUserNotExist(err) {
+				continue
+			}
+			return errors.Newf("get user by ID [%d]: %v", r.ReviewerID, err)
+		}
+		pr.RequestedReviewers = append(pr.RequestedReviewers, reviewer)
+	}
+	return nil
+}
+
+// IsReviewRequested returns true if review has been requested from given user
+// by ID.
+// This method assumes following fields have been assigned with valid values:
+// Required - RequestedReviewers
+func (pr *PullRequest) IsReviewRequested(userID int64) bool {
+	for _, reviewer := range pr.RequestedReviewers {
+		if reviewer.ID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTeamReviewRequested returns true if review has been requested from given
+// team by ID.
+// This method assumes following fields have been assigned with valid values:
+// Required - RequestedTeams
+func (pr *PullRequest) IsTeamReviewRequested(teamID int64) bool {
+	for _, team := range pr.RequestedTeams {
+		if team.ID == teamID {
+			return true
+		}
+	}
+	return false
+}
+
+// GetReviewableTeams returns all teams that review can be requested from for
+// the pull request.
+// This method assumes following fields have been assigned with valid values:
+// Required - BaseRepo
+func (pr *PullRequest) GetReviewableTeams() ([]*Team, error) {
+	if err := pr.BaseRepo.GetOwner(); err != nil {
+		return nil, errors.Newf("get owner: %v", err)
+	} else if !pr.BaseRepo.Owner.IsOrganization() {
+		return []*Team{}, nil
+	}
+	return GetTeamsHaveAccessToRepo(pr.BaseRepo.OwnerID, pr.BaseRepoID, AccessModeRead)
+}
+
+func (pr *PullRequest) sendReviewRequestWebhook(doer, reviewer *User, team *Team, isRemove bool) {
+	payload := &apiv1types.WebhookPullRequestPayload{
+		Action:      apiv1types.WebhookIssueReviewRequested,
+		Index:       pr.Index,
+		PullRequest: pr.APIFormat(),
+		Repository:  pr.BaseRepo.APIFormatLegacy(nil),
+		Sender:      doer.APIFormat(),
+	}
+	if isRemove {
+		payload.Action = apiv1types.WebhookIssueReviewRequestRemoved
+	}
+	if reviewer != nil {
+		payload.RequestedReviewer = reviewer.APIFormat()
+	} else {
+		payload.RequestedTeam = team.APIFormat()
+	}
+
+	if err := PrepareWebhooks(pr.BaseRepo, HookEventTypePullRequest, payload); err != nil {
+		log.Error("PrepareWebhooks [pull_request_id: %d, remove_review_request: %v]: %v", pr.ID, isRemove, err)
+	}
+}
+
+// reviewRequestTarget returns the name of the user or team that the review
+// request of the webhook payload is about.
+func reviewRequestTarget(p *apiv1types.WebhookPullRequestPayload) string {
+	if p.RequestedReviewer != nil {
+		return p.RequestedReviewer.UserName
+	} else if p.RequestedTeam != nil {
+		return p.RequestedTeam.Name
+	}
+	return ""
+}
+
+// RequestReview requests review of the pull request from the user. It returns
+// ErrInvalidReviewRequest if the user is the poster or does not have read access
+// to the base repository.
+// This method assumes following fields have been assigned with valid values:
+// Required - Issue, BaseRepo
+func (pr *PullRequest) RequestReview(doer, reviewer *User) error {
+	if reviewer.ID == pr.Issue.PosterID || reviewer.IsOrganization() ||
+		!Handle.Permissions().Authorize(context.TODO(), reviewer.ID, pr.BaseRepoID, AccessModeRead,
+			AccessModeOptions{
+				OwnerID: pr.BaseRepo.OwnerID,
+				Private: pr.BaseRepo.IsPrivate,
+			},
+		) {
+		return ErrInvalidReviewRequest{args: map[string]any{"pullRequestID": pr.ID, "reviewerID": reviewer.ID}}
+	}
+
+	has, err := x.Where("issue_id = ? AND reviewer_id = ?", pr.IssueID, reviewer.ID).Get(new(ReviewRequest))
+	if err != nil {
+		return err
+	} else if has {
+		return nil
+	}
+
+	if _, err = x.Insert(&ReviewRequest{
+		IssueID:    pr.IssueID,
+		ReviewerID: reviewer.ID,
+	}); err != nil {
+		return err
+	}
+	pr.RequestedReviewers = append(pr.RequestedReviewers, reviewer)
+
+	pr.sendReviewRequestWebhook(doer, reviewer, nil, false)
+	return nil
+}
+
+// RemoveReviewRequest removes the review request of the pull request from the
+// user.
+// This method assumes following fields have been assigned with valid values:
+// Required - Issue, BaseRepo
+func (pr *PullRequest) RemoveReviewRequest(doer, reviewer *User) error {
+	affected, err := x.Delete(&ReviewRequest{
+		IssueID:    pr.IssueID,
+		ReviewerID: reviewer.ID,
+	})
+	if err != nil {
+		return err
+	} else if affected == 0 {
+		return nil
+	}
+
+	if err = pr.loadReviewRequests(x); err != nil {
+		return err
+	}
+
+	pr.sendReviewRequestWebhook(doer, reviewer, nil, true)
+	return nil
+}
+
+// RequestTeamReview requests review of the pull request from the team. It
+// returns ErrInvalidReviewRequest if the team does not have access to the base
+// repository.
+// This method assumes following fields have been assigned with valid values:
+// Required - Issue, BaseRepo
+func (pr *PullRequest) RequestTeamReview(doer *User, team *Team) error {
+	if team.OrgID != pr.BaseRepo.OwnerID || !team.HasRepository(pr.BaseRepoID) {
+		return ErrInvalidReviewRequest{args: map[string]any{"pullRequestID": pr.ID, "teamID": team.ID}}
+	}
+
+	has, err := x.Where("issue_id = ? AND team_id = ?", pr.IssueID, team.ID).Get(new(ReviewRequest))
+	if err != nil {
+		return err
+	} else if has {
+		return nil
+	}
+
+	if _, err = x.Insert(&ReviewRequest{
+		IssueID: pr.IssueID,
+		TeamID:  team.ID,
+	}); err != nil {
+		return err
+	}
+	pr.RequestedTeams = append(pr.RequestedTeams, team)
+
+	pr.sendReviewRequestWebhook(doer, nil, team, false)
+	return nil
+}
+
+// RemoveTeamReviewRequest removes the review request of the pull request from
+// the team.
+// This method assumes following fields have been assigned with valid values:
+// Required - Issue, BaseRepo
+func (pr *PullRequest) RemoveTeamReviewRequest(doer *User, team *Team) error {
+	affected, err := x.Delete(&ReviewRequest{
+		IssueID: pr.IssueID,
+		TeamID:  team.ID,
+	})
+	if err != nil {
+		return err
+	} else if affected == 0 {
+		return nil
+	}
+
+	if err = pr.loadReviewRequests(x); err != nil {
+		return err
+	}
+
+	pr.sendReviewRequestWebhook(doer, nil, team, true)
+	return nil
+}
+
+// ClearReviewRequests removes all review requests of the pull request.
+// This method assumes following fields have been assigned with valid values:
+// Required - Issue, BaseRepo
+func (pr *PullRequest) ClearReviewRequests(doer *User) (err error) {
+	if err = pr.loadReviewRequests(x); err != nil {
+		return err
+	}
+
+	reviewers, teams := pr.RequestedReviewers, pr.RequestedTeams
+	for _, reviewer := range reviewers {
+		if err = pr.RemoveReviewRequest(doer, reviewer); err != nil {
+			return errors.Newf("remove review request [reviewer_id: %d]: %v", reviewer.ID, err)
+		}
+	}
+	for _, team := range teams {
+		if err = pr.RemoveTeamReviewRequest(doer, team); err != nil {
+			return errors.Newf("remove review request [team_id: %d]: %v", team.ID, err)
+		}
+	}
+	return nil
+}
+
+type ErrInvalidReviewRequest struct {
+	args map[string]any
+}
+
+func IsErrInvalidReviewRequest(err error) bool {
+	_, ok := err.(ErrInvalidReviewRequest)
+	return ok
+}
+
+func (err ErrInvalidReviewRequest) Error() string {
+	return fmt.Sprintf("review cannot be requested: %v", err.args)
+}
@@ -0,0 +1,131 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPullRequest_ReviewRequests(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	newLegacyTestDB(t, "TestPullRequest_ReviewRequests")
+
+	org := createLegacyTestUser(t, "acme")
+	org.Type = UserTypeOrganization
+	_, err := x.ID(org.ID).Cols("type").Update(org)
+	require.NoError(t, err)
+	otherOrg := createLegacyTestUser(t, "other")
+	otherOrg.Type = UserTypeOrganization
+	_, err = x.ID(otherOrg.ID).Cols("type").Update(otherOrg)
+	require.NoError(t, err)
+
+	poster := createLegacyTestUser(t, "alice")
+	reviewer := createLegacyTestUser(t, "bob")
+	outsider := createLegacyTestUser(t, "cindy")
+	repo := createLegacyTestRepo(t, org, "repo", true)
+	err = Handle.Permissions().SetRepoPerms(t.Context(), repo.ID,
+		map[int64]AccessMode{
+			poster.ID:   AccessModeWrite,
+			reviewer.ID: AccessModeRead,
+		},
+	)
+	require.NoError(t, err)
+
+	team := &Team{OrgID: org.ID, Name: "Reviewers", LowerName: "reviewers", Authorize: AccessModeRead}
+	teamWithoutRepo := &Team{OrgID: org.ID, Name: "Others", LowerName: "others", Authorize: AccessModeRead}
+	otherTeam := &Team{OrgID: otherOrg.ID, Name: "Reviewers", LowerName: "reviewers", Authorize: AccessModeRead}
+	_, err = x.Insert(team, teamWithoutRepo, otherTeam)
+	require.NoError(t, err)
+	_, err = x.Insert(&TeamRepo{OrgID: org.ID, TeamID: team.ID, RepoID: repo.ID})
+	require.NoError(t, err)
+
+	pr := createLegacyTestPullRequest(t, repo, poster, "feature")
+
+	// assertRequests checks review requests of the pull request in the
+	// database.
+	assertRequests := func(t *testing.T, wantReviewers []*User, wantTeams []*Team) {
+		t.Helper()
+
+		got := &PullRequest{IssueID: pr.IssueID}
+		require.NoError(t, got.loadReviewRequests(x))
+
+		reviewerIDs := make([]int64, 0, len(got.RequestedReviewers))
+		for _, u := range got.RequestedReviewers {
+			reviewerIDs = append(reviewerIDs, u.ID)
+		}
+		wantReviewerIDs := make([]int64, 0, len(wantReviewers))
+		for _, u := range wantReviewers {
+			wantReviewerIDs = append(wantReviewerIDs, u.ID)
+		}
+		assert.Equal(t, wantReviewerIDs, reviewerIDs)
+
+		teamIDs := make([]int64, 0, len(got.RequestedTeams))
+		for _, team := range got.RequestedTeams {
+			teamIDs = append(teamIDs, team.ID)
+		}
+		wantTeamIDs := make([]int64, 0, len(wantTeams))
+		for _, team := range wantTeams {
+			wantTeamIDs = append(wantTeamIDs, team.ID)
+		}
+		assert.Equal(t, wantTeamIDs, teamIDs)
+	}
+
+	t.Run("RequestReview", func(t *testing.T) {
+		for _, test := range []struct {
+			name     string
+			reviewer *User
+		}{
+			{name: "poster", reviewer: poster},
+			{name: "no read access", reviewer: outsider},
+			{name: "organization", reviewer: org},
+		} {
+			t.Run(test.name, func(t *testing.T) {
+				err := pr.RequestReview(poster, test.reviewer)
+				wantErr := ErrInvalidReviewRequest{args: map[string]any{"pullRequestID": pr.ID, "reviewerID": test.reviewer.ID}}
+				assert.Equal(t, wantErr, err)
+			})
+		}
+		assertRequests(t, nil, nil)
+
+		require.NoError(t, pr.RequestReview(poster, reviewer))
+		assert.True(t, pr.IsReviewRequested(reviewer.ID))
+
+		// Requesting again is a no-op
+		require.NoError(t, pr.RequestReview(poster, reviewer))
+		assertRequests(t, []*User{reviewer}, nil)
+	})
+
+	t.Run("RequestTeamReview", func(t *testing.T) {
+		for _, test := range []struct {
+			name string
+			team *Team
+		}{
+			{name: "team of another organization", team: otherTeam},
+			{name: "team without access", team: teamWithoutRepo},
+		} {
+			t.Run(test.name, func(t *testing.T) {
+				err := pr.RequestTeamReview(poster, test.team)
+				wantErr := ErrInvalidReviewRequest{args: map[string]any{"pullRequestID": pr.ID, "teamID": test.team.ID}}
+				assert.Equal(t, wantErr, err)
+			})
+		}
+
+		require.NoError(t, pr.RequestTeamReview(poster, team))
+		assert.True(t, pr.IsTeamReviewRequested(team.ID))
+
+		// Requesting again is a no-op
+		require.NoError(t, pr.RequestTeamReview(poster, team))
+		assertRequests(t, []*User{reviewer}, []*Team{team})
+	})
+
+	t.Run("ClearReviewRequests", func(t *testing.T) {
+		require.NoError(t, pr.ClearReviewRequests(poster))
+		assertRequests(t, nil, nil)
+		assert.Empty(t, pr.RequestedReviewers)
+		assert.Empty(t, pr.RequestedTeams)
+	})
+}
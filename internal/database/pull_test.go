@@ -0,0 +1,192 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/gogs/git-module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSquashCommitMessage(t *testing.T) {
+	issue := &Issue{
+		Index: 12,
+		Title: "Add feature",
+	}
+	doer := &User{
+		Name:  "alice",
+		Email: "alice@example.com",
+	}
+
+	t.Run("no co-authors", func(t *testing.T) {
+		commits := []*git.Commit{
+			{
+				Author:  &git.Signature{Name: "alice", Email: "Alice@example.com"},
+				Message: "Second commit\n",
+			},
+			{
+				Author:  &git.Signature{Name: "alice", Email: "alice@example.com"},
+				Message: "First commit\n\nWith details\n",
+			},
+		}
+		got := SquashCommitMessage(issue, doer, commits)
+		want := `Add feature (#12)
+
+* First commit
+
+  With details
+
+* Second commit
+`
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("with co-authors", func(t *testing.T) {
+		commits := []*git.Commit{
+			{
+				Author:  &git.Signature{Name: "cindy", Email: "cindy@example.com"},
+				Message: "Third commit",
+			},
+			{
+				Author:  &git.Signature{Name: "bob", Email: "bob@example.com"},
+				Message: "Second commit",
+			},
+			{
+				Author:  &git.Signature{Name: "Bob", Email: "BOB@example.com"},
+				Message: "First commit",
+			},
+		}
+		got := SquashCommitMessage(issue, doer, commits)
+		want := `Add feature (#12)
+
+* First commit
+
+* Second commit
+
+* Third commit
+
+Co-authored-by: Bob <BOB@example.com>
+Co-authored-by: cindy <cindy@example.com>
+`
+		assert.Equal(t, want, got)
+	})
+}
+
+func TestRepository_AllowedMergeStyles(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	newLegacyTestDB(t, "TestRepository_AllowedMergeStyles")
+
+	repo := &Repository{
+		ID:                    1,
+		OwnerID:               1,
+		PullsAllowMerge:       true,
+		PullsAllowRebase:      true,
+		PullsAllowSquash:      true,
+		PullsAllowFastForward: true,
+	}
+	for _, protectBranch := range []*ProtectBranch{
+		{RepoID: repo.ID, Name: "linear", Protected: true, RequireLinearHistory: true},
+		{RepoID: repo.ID, Name: "signed", Protected: true, RequireSignedCommits: true},
+		{RepoID: repo.ID, Name: "unprotected", RequireLinearHistory: true, RequireSignedCommits: true},
+	} {
+		_, err := x.Insert(protectBranch)
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name   string
+		branch string
+		want   []MergeStyle
+	}{
+		{
+			name:   "not protected",
+			branch: "main",
+			want:   []MergeStyle{MergeStyleRegular, MergeStyleRebase, MergeStyleSquash, MergeStyleFastForwardOnly},
+		},
+		{
+			name:   "protection disabled",
+			branch: "unprotected",
+			want:   []MergeStyle{MergeStyleRegular, MergeStyleRebase, MergeStyleSquash, MergeStyleFastForwardOnly},
+		},
+		{
+			name:   "require linear history",
+			branch: "linear",
+			want:   []MergeStyle{MergeStyleRebase, MergeStyleSquash, MergeStyleFastForwardOnly},
+		},
+		{
+			name:   "require signed commits",
+			branch: "signed",
+			want:   []MergeStyle{MergeStyleFastForwardOnly},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := repo.AllowedMergeStyles(test.branch)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+
+	t.Run("merge commits disabled", func(t *testing.T) {
+		repo := &Repository{ID: repo.ID, PullsAllowFastForward: true}
+		got, err := repo.AllowedMergeStyles("main")
+		require.NoError(t, err)
+		assert.Equal(t, []MergeStyle{MergeStyleFastForwardOnly}, got)
+	})
+}
+
+func TestPullRequest_checkFastForwardCommits(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	newLegacyTestDB(t, "TestPullRequest_checkFastForwardCommits")
+
+	repoPath := t.TempDir()
+	run := func(args ...string) { runTestGit(t, repoPath, args...) }
+	run("init", "--initial-branch=main")
+	run("commit", "--allow-empty", "-m", "Initial commit")
+	run("checkout", "-b", "topic")
+	run("commit", "--allow-empty", "-m", "Topic commit")
+	run("checkout", "-b", "linear", "main")
+	run("commit", "--allow-empty", "-m", "Linear commit")
+	run("checkout", "-b", "merged", "linear")
+	run("merge", "--no-ff", "-m", "Merge branch 'topic'", "topic")
+
+	const repoID = 1
+	pr := &PullRequest{ID: 1, BaseRepoID: repoID, BaseBranch: "main"}
+	protectBranch := &ProtectBranch{RepoID: repoID, Name: "main", Protected: true}
+	_, err := x.Insert(protectBranch)
+	require.NoError(t, err)
+	update := func(t *testing.T, requireLinearHistory, requireSignedCommits bool) {
+		protectBranch.RequireLinearHistory = requireLinearHistory
+		protectBranch.RequireSignedCommits = requireSignedCommits
+		_, err := x.ID(protectBranch.ID).AllCols().Update(protectBranch)
+		require.NoError(t, err)
+	}
+
+	t.Run("no requirements", func(t *testing.T) {
+		update(t, false, false)
+		assert.NoError(t, pr.checkFastForwardCommits(repoPath, "main..merged"))
+	})
+
+	t.Run("require linear history", func(t *testing.T) {
+		update(t, true, false)
+		assert.NoError(t, pr.checkFastForwardCommits(repoPath, "main..linear"))
+
+		err := pr.checkFastForwardCommits(repoPath, "main..merged")
+		assert.True(t, IsErrPullRequestHasMergeCommits(err), "%v", err)
+	})
+
+	t.Run("require signed commits", func(t *testing.T) {
+		update(t, false, true)
+		err := pr.checkFastForwardCommits(repoPath, "main..linear")
+		assert.True(t, IsErrPullRequestHasUnverifiedCommits(err), "%v", err)
+
+		// Nothing to be fast-forwarded
+		assert.NoError(t, pr.checkFastForwardCommits(repoPath, "linear..main"))
+	})
+}
@@ -0,0 +1,182 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gogs/git-module"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/process"
+)
+
+// UpdateStyle represents the approach to update the head branch of a pull
+// request with changes of the base branch.
+type UpdateStyle string
+
+const (
+	UpdateStyleMerge  UpdateStyle = "merge"
+	UpdateStyleRebase UpdateStyle = "rebase"
+)
+
+// CanUpdateBranch returns true if the user is allowed to update the head branch
+// of the pull request. The user must have write access to the head repository,
+// or write access to the base repository when the poster allows edits by
+// maintainers. The head branch must not be protected.
+// This method assumes following fields have been assigned with valid values:
+// Required - HeadRepo, BaseRepo
+func (pr *PullRequest) CanUpdateBranch(userID int64) bool {
+	if pr.HeadRepo == nil || userID <= 0 {
+		return false
+	}
+	protectBranch, err := GetProtectBranchOfRepoByName(pr.HeadRepoID, pr.HeadBranch)
+	if err == nil && protectBranch.Protected {
+		return false
+	}
+
+	ctx := context.TODO()
+	if Handle.Permissions().Authorize(ctx, userID, pr.HeadRepoID, AccessModeWrite,
+		AccessModeOptions{
+			OwnerID: pr.HeadRepo.OwnerID,
+			Private: pr.HeadRepo.IsPrivate,
+		},
+	) {
+		return true
+	}
+	return pr.AllowMaintainerEdit && Handle.Permissions().Authorize(ctx, userID, pr.BaseRepoID, AccessModeWrite,
+		AccessModeOptions{
+			OwnerID: pr.BaseRepo.OwnerID,
+			Private: pr.BaseRepo.IsPrivate,
+		},
+	)
+}
+
+// UpdateBranch merges or rebases changes of the base branch into the head
+// branch of the pull request. It returns ErrPullRequestUpToDate if the head
+// branch already contains the base branch, or ErrPullRequestUpdateConflict if
+// changes cannot be applied without conflicts. Callers should check
+// permissions with CanUpdateBranch beforehand.
+// This method assumes following fields have been assigned with valid values:
+// Required - HeadRepo, BaseRepo
+func (pr *PullRequest) UpdateBranch(doer *User, style UpdateStyle) (err error) {
+	if style != UpdateStyleMerge && style != UpdateStyleRebase {
+		return errors.Newf("unknown update style: %s", style)
+	}
+
+	repoWorkingPool.CheckIn(strconv.FormatInt(pr.HeadRepoID, 10))
+	defer repoWorkingPool.CheckOut(strconv.FormatInt(pr.HeadRepoID, 10))
+
+	// Create temporary directory to store temporary copy of the head repository,
+	// and clean it up when operation finished regardless of succeed or not.
+	tmpHeadPath := filepath.Join(conf.Server.AppDataPath, "tmp", "repos", strconv.Itoa(time.Now().Nanosecond())+".git")
+	if err = os.MkdirAll(filepath.Dir(tmpHeadPath), os.ModePerm); err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.RemoveAll(filepath.Dir(tmpHeadPath))
+	}()
+
+	// Clone the head repository to the defined temporary directory,
+	// and checks out to head branch directly.
+	headRepoPath := pr.HeadRepo.RepoPath()
+	if err = git.Clone(headRepoPath, tmpHeadPath, git.CloneOptions{
+		Branch:  pr.HeadBranch,
+		Timeout: 5 * time.Minute,
+	}); err != nil {
+		return errors.Newf("git clone: %v", err)
+	}
+
+	// Add remote which points to the base repository.
+	var stderr string
+	baseRepoPath := pr.BaseRepo.RepoPath()
+	if _, stderr, err = process.ExecDir(-1, tmpHeadPath,
+		fmt.Sprintf("PullRequest.UpdateBranch (git remote add): %s", tmpHeadPath),
+		"git", "remote", "add", "base_repo", baseRepoPath); err != nil {
+		return errors.Newf("git remote add [%s -> %s]: %s", baseRepoPath, tmpHeadPath, stderr)
+	}
+
+	// Fetch information from base repository to the temporary copy.
+	if _, stderr, err = process.ExecDir(-1, tmpHeadPath,
+		fmt.Sprintf("PullRequest.UpdateBranch (git fetch): %s", tmpHeadPath),
+		"git", "fetch", "base_repo"); err != nil {
+		return errors.Newf("git fetch [%s -> %s]: %s", baseRepoPath, tmpHeadPath, stderr)
+	}
+
+	// Nothing to update when the head branch is not behind the base branch.
+	remoteBaseBranch := "base_repo/" + pr.BaseBranch
+	var stdout string
+	if stdout, stderr, err = process.ExecDir(-1, tmpHeadPath,
+		fmt.Sprintf("PullRequest.UpdateBranch (git rev-list): %s", tmpHeadPath),
+		"git", "rev-list", "--count", "HEAD.."+remoteBaseBranch, "--"); err != nil {
+		return errors.Newf("git rev-list [%s]: %v - %s", tmpHeadPath, err, stderr)
+	} else if strings.TrimSpace(stdout) == "0" {
+		return ErrPullRequestUpToDate{args: map[string]any{"pullRequestID": pr.ID}}
+	}
+
+	refspec := pr.HeadBranch
+	switch style {
+	case UpdateStyleMerge:
+		if _, _, err = process.ExecDir(-1, tmpHeadPath,
+			fmt.Sprintf("PullRequest.UpdateBranch (git merge --no-ff --no-commit): %s", tmpHeadPath),
+			"git", "merge", "--no-ff", "--no-commit", "--end-of-options", remoteBaseBranch); err != nil {
+			return ErrPullRequestUpdateConflict{args: map[string]any{"pullRequestID": pr.ID, "style": style}}
+		}
+
+		if _, stderr, err = process.ExecDir(-1, tmpHeadPath,
+			fmt.Sprintf("PullRequest.UpdateBranch (git commit): %s", tmpHeadPath),
+			"git", "commit", fmt.Sprintf("--author=%s <%s>", doer.DisplayName(), doer.Email),
+			"-m", fmt.Sprintf("Merge branch '%s' of %s into %s", pr.BaseBranch, pr.BaseRepo.FullName(), pr.HeadBranch)); err != nil {
+			return errors.Newf("git commit [%s]: %v - %s", tmpHeadPath, err, stderr)
+		}
+
+	case UpdateStyleRebase:
+		if _, _, err = process.ExecDir(-1, tmpHeadPath,
+			fmt.Sprintf("PullRequest.UpdateBranch (git rebase): %s", tmpHeadPath),
+			"git", "rebase", "--quiet", "--end-of-options", remoteBaseBranch); err != nil {
+			return ErrPullRequestUpdateConflict{args: map[string]any{"pullRequestID": pr.ID, "style": style}}
+		}
+
+		// Rebasing rewrites the history, thus requires a force push.
+		refspec = "+" + pr.HeadBranch
+	}
+
+	// Push changes on head branch to upstream.
+	if err = git.Push(tmpHeadPath, headRepoPath, refspec); err != nil {
+		return errors.Newf("git push: %v", err)
+	}
+
+	go AddTestPullRequestTask(doer, pr.HeadRepoID, pr.HeadBranch, true)
+	return nil
+}
+
+type ErrPullRequestUpdateConflict struct {
+	args map[string]any
+}
+
+func IsErrPullRequestUpdateConflict(err error) bool {
+	_, ok := err.(ErrPullRequestUpdateConflict)
+	return ok
+}
+
+func (err ErrPullRequestUpdateConflict) Error() string {
+	return fmt.Sprintf("pull request head branch cannot be updated without conflicts: %v", err.args)
+}
+
+type ErrPullRequestUpToDate struct {
+	args map[string]any
+}
+
+func IsErrPullRequestUpToDate(err error) bool {
+	_, ok := err.(ErrPullRequestUpToDate)
+	return ok
+}
+
+func (err ErrPullRequestUpToDate) Error() string {
+	return fmt.Sprintf("pull request head branch is already up to date: %v", err.args)
+}
@@ -0,0 +1,202 @@
+package database
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+)
+
+func TestPullRequest_CanUpdateBranch(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	newLegacyTestDB(t, "TestPullRequest_CanUpdateBranch")
+
+	maintainer := createLegacyTestUser(t, "alice")
+	contributor := createLegacyTestUser(t, "bob")
+	outsider := createLegacyTestUser(t, "cindy")
+	baseRepo := createLegacyTestRepo(t, maintainer, "repo", true)
+	headRepo := createLegacyTestRepo(t, contributor, "repo", true)
+
+	err := Handle.Permissions().SetRepoPerms(t.Context(), headRepo.ID, map[int64]AccessMode{outsider.ID: AccessModeRead})
+	require.NoError(t, err)
+
+	pr := createLegacyTestPullRequest(t, baseRepo, contributor, "feature")
+	pr.HeadRepoID = headRepo.ID
+	pr.HeadRepo = headRepo
+
+	t.Run("anonymous", func(t *testing.T) {
+		assert.False(t, pr.CanUpdateBranch(0))
+	})
+
+	t.Run("head repository deleted", func(t *testing.T) {
+		pr := *pr
+		pr.HeadRepo = nil
+		assert.False(t, pr.CanUpdateBranch(contributor.ID))
+	})
+
+	t.Run("write access to head repository", func(t *testing.T) {
+		assert.True(t, pr.CanUpdateBranch(contributor.ID))
+	})
+
+	t.Run("read access to head repository", func(t *testing.T) {
+		assert.False(t, pr.CanUpdateBranch(outsider.ID))
+	})
+
+	t.Run("maintainer", func(t *testing.T) {
+		pr := *pr
+		pr.AllowMaintainerEdit = false
+		assert.False(t, pr.CanUpdateBranch(maintainer.ID))
+
+		pr.AllowMaintainerEdit = true
+		assert.True(t, pr.CanUpdateBranch(maintainer.ID))
+		assert.False(t, pr.CanUpdateBranch(outsider.ID))
+	})
+
+	t.Run("protected head branch", func(t *testing.T) {
+		_, err := x.Insert(&ProtectBranch{RepoID: headRepo.ID, Name: "feature", Protected: true})
+		require.NoError(t, err)
+		assert.False(t, pr.CanUpdateBranch(contributor.ID))
+	})
+}
+
+func TestPullRequest_UpdateBranch(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	newLegacyTestDB(t, "TestPullRequest_UpdateBranch")
+	conf.SetMockRepository(t, conf.RepositoryOpts{Root: filepath.Join(t.TempDir(), "repositories")})
+	conf.SetMockServer(t, conf.ServerOpts{AppDataPath: t.TempDir()})
+
+	owner := createLegacyTestUser(t, "alice")
+	repo := createLegacyTestRepo(t, owner, "repo", false)
+
+	// Create the repository with the base branch and three head branches, of
+	// which "conflict" changes the same file as the base branch.
+	repoPath := repo.RepoPath()
+	runTestGit(t, t.TempDir(), "init", "--bare", "--initial-branch=main", repoPath)
+	workPath := t.TempDir()
+	run := func(args ...string) string { return runTestGit(t, workPath, args...) }
+	commit := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(workPath, name), []byte(content), 0o644))
+		run("add", name)
+		run("commit", "-m", "Update "+name)
+	}
+	run("init", "--initial-branch=main")
+	commit("README.md", "# repo\n")
+	for _, branch := range []string{"merge", "rebase", "conflict"} {
+		run("checkout", "-b", branch, "main")
+		commit(branch+".txt", branch+"\n")
+	}
+	run("checkout", "conflict")
+	commit("README.md", "# conflict\n")
+	run("checkout", "main")
+	commit("README.md", "# main\n")
+	run("push", repoPath, "main", "merge", "rebase", "conflict")
+
+	// Pull requests based on head branches are tested last by test tasks started
+	// by updates, and are waited for to know when tasks have finished.
+	prs := make(map[string]*PullRequest)
+	dependents := make(map[string]*PullRequest)
+	for _, branch := range []string{"merge", "rebase", "conflict"} {
+		prs[branch] = createLegacyTestPullRequest(t, repo, owner, branch)
+
+		dependent := createLegacyTestPullRequest(t, repo, owner, "dependent")
+		dependent.BaseBranch = branch
+		_, err := x.ID(dependent.ID).Cols("base_branch").Update(dependent)
+		require.NoError(t, err)
+		dependents[branch] = dependent
+	}
+
+	isUpToDate := func(t *testing.T, branch string) bool {
+		err := exec.Command("git", "-C", repoPath, "merge-base", "--is-ancestor", "main", branch).Run()
+		return err == nil
+	}
+	// waitForTestTasks waits for the test task started by the update of the head
+	// branch to add pull requests of the branch to the queue.
+	waitForTestTasks := func(t *testing.T, branch string) {
+		want := []string{
+			strconv.FormatInt(prs[branch].ID, 10),
+			strconv.FormatInt(dependents[branch].ID, 10),
+		}
+		var got []string
+		for range want {
+			select {
+			case id := <-PullRequestQueue.Queue():
+				PullRequestQueue.Remove(id)
+				got = append(got, id)
+			case <-time.After(5 * time.Second):
+				t.Fatal("test task not added")
+			}
+		}
+		assert.ElementsMatch(t, want, got)
+	}
+
+	t.Run("unknown style", func(t *testing.T) {
+		err := prs["merge"].UpdateBranch(owner, "squash")
+		assert.Error(t, err)
+		assert.False(t, isUpToDate(t, "merge"))
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		pr := prs["merge"]
+		require.NoError(t, pr.UpdateBranch(owner, UpdateStyleMerge))
+		waitForTestTasks(t, "merge")
+		assert.True(t, isUpToDate(t, "merge"))
+
+		// The head branch is updated with a merge commit.
+		parents := runTestGit(t, repoPath, "rev-list", "--parents", "--max-count=1", "merge")
+		assert.Len(t, strings.Fields(parents), 3)
+	})
+
+	t.Run("rebase", func(t *testing.T) {
+		pr := prs["rebase"]
+		require.NoError(t, pr.UpdateBranch(owner, UpdateStyleRebase))
+		waitForTestTasks(t, "rebase")
+		assert.True(t, isUpToDate(t, "rebase"))
+
+		// The head branch is rebased without a merge commit.
+		merges := runTestGit(t, repoPath, "rev-list", "--min-parents=2", "rebase")
+		assert.Empty(t, merges)
+	})
+
+	t.Run("up to date", func(t *testing.T) {
+		pr := prs["merge"]
+		head := runTestGit(t, repoPath, "rev-parse", "merge")
+		for _, style := range []UpdateStyle{UpdateStyleMerge, UpdateStyleRebase} {
+			err := pr.UpdateBranch(owner, style)
+			wantErr := ErrPullRequestUpToDate{args: map[string]any{"pullRequestID": pr.ID}}
+			assert.Equal(t, wantErr, err)
+		}
+
+		// The head branch is left untouched and no test task is started.
+		assert.Equal(t, head, runTestGit(t, repoPath, "rev-parse", "merge"))
+		select {
+		case id := <-PullRequestQueue.Queue():
+			PullRequestQueue.Remove(id)
+			t.Fatalf("unexpected test task for pull request %s", id)
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		pr := prs["conflict"]
+		for _, style := range []UpdateStyle{UpdateStyleMerge, UpdateStyleRebase} {
+			err := pr.UpdateBranch(owner, style)
+			wantErr := ErrPullRequestUpdateConflict{args: map[string]any{"pullRequestID": pr.ID, "style": style}}
+			assert.Equal(t, wantErr, err)
+		}
+		assert.False(t, isUpToDate(t, "conflict"))
+	})
+}
@@ -37,7 +37,8 @@ type Release struct {
 	Created     time.Time `xorm:"-" json:"-" gorm:"-"`
 	CreatedUnix int64
 
-	Attachments []*Attachment `xorm:"-" json:"-" gorm:"-"`
+	Attachments  []*Attachment       `xorm:"-" json:"-" gorm:"-"`
+	Verification *CommitVerification `xorm:"-" json:"-" gorm:"-"`
 }
 
 func (r *Release) BeforeInsert() {
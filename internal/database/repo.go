@@ -32,6 +32,7 @@ import (
 	"gogs.io/gogs/internal/conf"
 	"gogs.io/gogs/internal/dbx"
 	"gogs.io/gogs/internal/errx"
+	"gogs.io/gogs/internal/gitx"
 	"gogs.io/gogs/internal/markup"
 	"gogs.io/gogs/internal/osx"
 	"gogs.io/gogs/internal/process"
@@ -177,6 +178,9 @@ type Repository struct {
 	DefaultBranch   string
 	Size            int64 `xorm:"NOT NULL DEFAULT 0" gorm:"not null;default:0"`
 	UseCustomAvatar bool
+	// PrimaryLanguage is the language with the most bytes in the default branch.
+	PrimaryLanguage string   `xorm:"INDEX" gorm:"index"`
+	Topics          []string `xorm:"-" gorm:"-" json:"-"`
 
 	// Counters
 	NumWatches          int
@@ -193,6 +197,10 @@ type Repository struct {
 	NumOpenMilestones   int `xorm:"-" gorm:"-" json:"-"`
 	NumTags             int `xorm:"-" gorm:"-" json:"-"`
 
+	// Number of issues transferred to other repositories, their indexes are
+	// never reused.
+	NumTransferredIssues int `xorm:"NOT NULL DEFAULT 0" gorm:"not null;default:0"`
+
 	IsPrivate bool
 	// TODO: When migrate to GORM, make sure to do a loose migration with `HasColumn` and `AddColumn`,
 	// see docs in https://gorm.io/docs/migration.html.
@@ -216,7 +224,10 @@ type Repository struct {
 	ExternalMetas         map[string]string `xorm:"-" gorm:"-" json:"-"`
 	EnablePulls           bool              `xorm:"NOT NULL DEFAULT true" gorm:"not null;default:TRUE"`
 	PullsIgnoreWhitespace bool              `xorm:"NOT NULL DEFAULT false" gorm:"not null;default:FALSE"`
+	PullsAllowMerge       bool              `xorm:"NOT NULL DEFAULT true" gorm:"not null;default:TRUE"`
 	PullsAllowRebase      bool              `xorm:"NOT NULL DEFAULT false" gorm:"not null;default:FALSE"`
+	PullsAllowSquash      bool              `xorm:"NOT NULL DEFAULT false" gorm:"not null;default:FALSE"`
+	PullsAllowFastForward bool              `xorm:"NOT NULL DEFAULT false" gorm:"not null;default:FALSE"`
 
 	IsFork   bool `xorm:"NOT NULL DEFAULT false" gorm:"not null;default:FALSE"`
 	ForkID   int64
@@ -639,6 +650,39 @@ func (r *Repository) IsBranchRequirePullRequest(name string) bool {
 	return IsBranchOfRepoRequirePullRequest(r.ID, name)
 }
 
+// AllowedMergeStyles returns the list of merge styles that are allowed to merge
+// pull requests into the branch, the first one being the default. Styles that
+// produce merge commits are excluded when the branch requires linear history,
+// and styles that create commits on the server are excluded when the branch
+// requires signed commits because the server cannot sign them.
+func (r *Repository) AllowedMergeStyles(branch string) ([]MergeStyle, error) {
+	requireLinearHistory, requireSignedCommits := false, false
+	protectBranch, err := GetProtectBranchOfRepoByName(r.ID, branch)
+	if err != nil {
+		if !IsErrBranchNotExist(err) {
+			return nil, errors.Newf("get protect branch of repository by name: %v", err)
+		}
+	} else if protectBranch.Protected {
+		requireLinearHistory = protectBranch.RequireLinearHistory
+		requireSignedCommits = protectBranch.RequireSignedCommits
+	}
+
+	styles := make([]MergeStyle, 0, 4)
+	if r.PullsAllowMerge && !requireLinearHistory && !requireSignedCommits {
+		styles = append(styles, MergeStyleRegular)
+	}
+	if r.PullsAllowRebase && !requireSignedCommits {
+		styles = append(styles, MergeStyleRebase)
+	}
+	if r.PullsAllowSquash && !requireSignedCommits {
+		styles = append(styles, MergeStyleSquash)
+	}
+	if r.PullsAllowFastForward {
+		styles = append(styles, MergeStyleFastForwardOnly)
+	}
+	return styles, nil
+}
+
 // CanEnableEditor returns true if repository meets the requirements of web editor.
 func (r *Repository) CanEnableEditor() bool {
 	return !r.IsMirror
@@ -647,7 +691,7 @@ func (r *Repository) CanEnableEditor() bool {
 // FIXME: should have a mutex to prevent producing same index for two issues that are created
 // closely enough.
 func (r *Repository) NextIssueIndex() int64 {
-	return int64(r.NumIssues+r.NumPulls) + 1
+	return int64(r.NumIssues+r.NumPulls+r.NumTransferredIssues) + 1
 }
 
 func (r *Repository) LocalCopyPath() string {
@@ -885,10 +929,15 @@ func MigrateRepository(doer, owner *User, opts MigrateRepoOptions) (*Repository,
 		}
 
 		repo.IsMirror = true
-		return repo, UpdateRepository(repo, false)
+		if err = UpdateRepository(repo, false); err != nil {
+			return repo, err
+		}
+	} else if repo, err = CleanUpMigrateInfo(repo); err != nil {
+		return repo, err
 	}
 
-	return CleanUpMigrateInfo(repo)
+	UpdateCodeIndexer(repo.ID)
+	return repo, nil
 }
 
 // cleanUpMigrateGitConfig removes mirror info which prevents "push --all".
@@ -1228,16 +1277,17 @@ func CreateRepository(doer, owner *User, opts CreateRepoOptionsLegacy) (_ *Repos
 	}
 
 	repo := &Repository{
-		OwnerID:      owner.ID,
-		Owner:        owner,
-		Name:         opts.Name,
-		LowerName:    strings.ToLower(opts.Name),
-		Description:  opts.Description,
-		IsPrivate:    opts.IsPrivate,
-		IsUnlisted:   opts.IsUnlisted,
-		EnableWiki:   true,
-		EnableIssues: true,
-		EnablePulls:  true,
+		OwnerID:         owner.ID,
+		Owner:           owner,
+		Name:            opts.Name,
+		LowerName:       strings.ToLower(opts.Name),
+		Description:     opts.Description,
+		IsPrivate:       opts.IsPrivate,
+		IsUnlisted:      opts.IsUnlisted,
+		EnableWiki:      true,
+		EnableIssues:    true,
+		EnablePulls:     true,
+		PullsAllowMerge: true,
 	}
 
 	sess := x.NewSession()
@@ -1697,9 +1747,13 @@ func DeleteRepository(ownerID, repoID int64) error {
 		&PullRequest{BaseRepoID: repoID},
 		&ProtectBranch{RepoID: repoID},
 		&ProtectBranchWhitelist{RepoID: repoID},
+		&ProtectTag{RepoID: repoID},
 		&Webhook{RepoID: repoID},
 		&HookTask{RepoID: repoID},
 		&LFSObject{RepoID: repoID},
+		&LanguageStat{RepoID: repoID},
+		&RepoTopic{RepoID: repoID},
+		&IssueRedirect{OldRepoID: repoID},
 	); err != nil {
 		return errors.Newf("deleteBeans: %v", err)
 	}
@@ -1714,6 +1768,33 @@ func DeleteRepository(ownerID, repoID int64) error {
 		if _, err = sess.Delete(&Comment{IssueID: issues[i].ID}); err != nil {
 			return err
 		}
+		if _, err = sess.Delete(&IssueAssignee{IssueID: issues[i].ID}); err != nil {
+			return err
+		}
+		if _, err = sess.Delete(&ReviewRequest{IssueID: issues[i].ID}); err != nil {
+			return err
+		}
+		if _, err = sess.Delete(&CodeOwner{IssueID: issues[i].ID}); err != nil {
+			return err
+		}
+		if _, err = sess.Delete(&CodeOwnerSignOff{IssueID: issues[i].ID}); err != nil {
+			return err
+		}
+		if _, err = sess.Where("issue_id = ? OR dependency_id = ?", issues[i].ID, issues[i].ID).Delete(new(IssueDependency)); err != nil {
+			return err
+		}
+		if _, err = sess.Delete(&TrackedTime{IssueID: issues[i].ID}); err != nil {
+			return err
+		}
+		if _, err = sess.Delete(&Stopwatch{IssueID: issues[i].ID}); err != nil {
+			return err
+		}
+		if _, err = sess.Delete(&Reaction{IssueID: issues[i].ID}); err != nil {
+			return err
+		}
+		if _, err = sess.Delete(&IssueRedirect{IssueID: issues[i].ID}); err != nil {
+			return err
+		}
 
 		attachments := make([]*Attachment, 0, 5)
 		if err = sess.Where("issue_id=?", issues[i].ID).Find(&attachments); err != nil {
@@ -1757,6 +1838,13 @@ func DeleteRepository(ownerID, repoID int64) error {
 		RemoveAllWithNotice("Delete attachment", attachmentPaths[i])
 	}
 
+	issueIDs := make([]int64, len(issues))
+	for i := range issues {
+		issueIDs[i] = issues[i].ID
+	}
+	deleteIssuesFromIndexer(issueIDs...)
+	deleteRepoFromCodeIndexer(repo.ID)
+
 	if repo.NumForks > 0 {
 		if _, err = x.Exec("UPDATE `repository` SET fork_id=0,is_fork=? WHERE fork_id=?", false, repo.ID); err != nil {
 			log.Error("reset 'fork_id' and 'is_fork': %v", err)
@@ -1874,7 +1962,9 @@ type SearchRepoOptions struct {
 	OwnerID  int64
 	UserID   int64 // When set results will contain all public/private repositories user has access to
 	OrderBy  string
-	Private  bool // Include private repositories in results
+	Private  bool   // Include private repositories in results
+	Language string // Primary language of repositories
+	Topic    string // Name of a topic of repositories
 	Page     int
 	PageSize int // Can be smaller than or equal to setting.ExplorePagingNum
 }
@@ -1905,6 +1995,12 @@ func SearchRepositoryByName(opts *SearchRepoOptions) (repos []*Repository, count
 	if opts.OwnerID > 0 {
 		sess.And("repo.owner_id = ?", opts.OwnerID)
 	}
+	if opts.Language != "" {
+		sess.And("repo.primary_language = ?", opts.Language)
+	}
+	if opts.Topic != "" {
+		sess.And("repo.id IN (SELECT repo_topic.repo_id FROM repo_topic INNER JOIN topic ON topic.id = repo_topic.topic_id WHERE topic.name = ?)", NormalizeTopic(opts.Topic))
+	}
 
 	// We need all fields (repo.*) in final list but only ID (repo.id) is good enough for counting.
 	count, err = sess.Clone().Distinct("repo.id").Count(new(Repository))
@@ -1927,14 +2023,12 @@ func DeleteOldRepositoryArchives() {
 
 	log.Trace("Doing: DeleteOldRepositoryArchives")
 
-	formats := []string{"zip", "targz"}
 	oldestTime := time.Now().Add(-conf.Cron.RepoArchiveCleanup.OlderThan)
 	if err := x.Where("id > 0").Iterate(new(Repository),
 		func(idx int, bean any) error {
 			repo := bean.(*Repository)
-			basePath := filepath.Join(repo.RepoPath(), "archives")
-			for _, format := range formats {
-				dirPath := filepath.Join(basePath, format)
+			for _, format := range gitx.ArchiveFormats {
+				dirPath := archiveDir(repo.RepoPath(), format)
 				if !osx.IsDir(dirPath) {
 					continue
 				}
@@ -2303,6 +2397,9 @@ func (repos RepositoryList) loadAttributes(e Engine) error {
 		}
 	}
 
+	if err := loadRepoTopics(e, repos); err != nil {
+		return errors.Newf("load topics: %v", err)
+	}
 	return nil
 }
 
@@ -2393,13 +2490,29 @@ func watchRepo(e Engine, userID, repoID int64, watch bool) (err error) {
 	return err
 }
 
-// Watch or unwatch repository.
+// Watch or unwatch repository. It returns ErrBlockedByUser if the owner of the
+// repository has blocked the user from watching.
 //
 // Deprecated: Use Watches.Watch instead.
 func WatchRepo(userID, repoID int64, watch bool) (err error) {
+	if watch {
+		if err = ensureRepoOwnerNotBlocked(repoID, userID); err != nil {
+			return err
+		}
+	}
 	return watchRepo(x, userID, repoID, watch)
 }
 
+// ensureRepoOwnerNotBlocked returns ErrBlockedByUser if the owner of the
+// repository has blocked the user.
+func ensureRepoOwnerNotBlocked(repoID, userID int64) error {
+	repo, err := getRepositoryByID(x, repoID)
+	if err != nil {
+		return err
+	}
+	return Handle.UserBlocks().EnsureNotBlocked(context.TODO(), repo.OwnerID, userID)
+}
+
 // Deprecated: Use Repos.ListByRepo instead.
 func getWatchers(e Engine, repoID int64) ([]*Watch, error) {
 	watches := make([]*Watch, 0, 10)
@@ -2480,7 +2593,8 @@ type Star struct {
 	RepoID int64 `xorm:"UNIQUE(s)" gorm:"uniqueIndex:star_user_repo_unique;not null"`
 }
 
-// Star or unstar repository.
+// Star or unstar repository. It returns ErrBlockedByUser if the owner of the
+// repository has blocked the user from starring.
 //
 // Deprecated: Use Stars.Star instead.
 func StarRepo(userID, repoID int64, star bool) (err error) {
@@ -2488,6 +2602,9 @@ func StarRepo(userID, repoID int64, star bool) (err error) {
 		if IsStarring(userID, repoID) {
 			return nil
 		}
+		if err = ensureRepoOwnerNotBlocked(repoID, userID); err != nil {
+			return err
+		}
 		if _, err = x.Insert(&Star{UserID: userID, RepoID: repoID}); err != nil {
 			return err
 		} else if _, err = x.Exec("UPDATE `repository` SET num_stars = num_stars + 1 WHERE id = ?", repoID); err != nil {
@@ -2555,16 +2672,17 @@ func ForkRepository(doer, owner *User, baseRepo *Repository, name, desc string)
 	}
 
 	repo := &Repository{
-		OwnerID:       owner.ID,
-		Owner:         owner,
-		Name:          name,
-		LowerName:     strings.ToLower(name),
-		Description:   desc,
-		DefaultBranch: baseRepo.DefaultBranch,
-		IsPrivate:     baseRepo.IsPrivate,
-		IsUnlisted:    baseRepo.IsUnlisted,
-		IsFork:        true,
-		ForkID:        baseRepo.ID,
+		OwnerID:         owner.ID,
+		Owner:           owner,
+		Name:            name,
+		LowerName:       strings.ToLower(name),
+		Description:     desc,
+		DefaultBranch:   baseRepo.DefaultBranch,
+		IsPrivate:       baseRepo.IsPrivate,
+		IsUnlisted:      baseRepo.IsUnlisted,
+		IsFork:          true,
+		ForkID:          baseRepo.ID,
+		PullsAllowMerge: true,
 	}
 
 	sess := x.NewSession()
@@ -2613,6 +2731,7 @@ func ForkRepository(doer, owner *User, baseRepo *Repository, name, desc string)
 	if err = repo.UpdateSize(); err != nil {
 		log.Error("UpdateSize [repo_id: %d]: %v", repo.ID, err)
 	}
+	UpdateCodeIndexer(repo.ID)
 	if err = PrepareWebhooks(baseRepo, HookEventTypeFork, &apiv1types.WebhookForkPayload{
 		Forkee: repo.APIFormatLegacy(nil),
 		Repo:   baseRepo.APIFormatLegacy(nil),
@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gogs/git-module"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/gitx"
+	"gogs.io/gogs/internal/lfsx"
+	"gogs.io/gogs/internal/osx"
+	"gogs.io/gogs/internal/sync"
+	"gogs.io/gogs/internal/tool"
+)
+
+// archivePool makes concurrent requests for the same archive wait for a single
+// generation instead of each running "git archive".
+var archivePool = sync.NewExclusivePool()
+
+// archiveDir returns the directory that stores archives of the repository in
+// given format, e.g. "archives/targz" for "tar.gz".
+func archiveDir(repoPath, format string) string {
+	return filepath.Join(repoPath, "archives", strings.ReplaceAll(format, ".", ""))
+}
+
+// Archive returns the path of the archive of the commit in given format,
+// creating it if it does not exist yet. Contents of LFS objects are included
+// instead of pointer files when enabled.
+func (r *Repository) Archive(commit *git.Commit, format string) (string, error) {
+	name := tool.ShortSHA1(commit.ID.String())
+	if conf.Repository.Archive.IncludeLFS {
+		name += "-lfs"
+	}
+	dir := archiveDir(r.RepoPath(), format)
+	archivePath := filepath.Join(dir, name+"."+format)
+
+	archivePool.CheckIn(archivePath)
+	defer archivePool.CheckOut(archivePath)
+
+	if osx.IsFile(archivePath) {
+		return archivePath, nil
+	}
+
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", errors.Wrap(err, "create archive directory")
+	}
+
+	// Write to a temporary file first so that an incomplete archive is never
+	// served.
+	f, err := os.CreateTemp(dir, name+".*.tmp")
+	if err != nil {
+		return "", errors.Wrap(err, "create temporary file")
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+
+	opts := gitx.ArchiveOptions{
+		Prefix:  strings.ToLower(r.Name) + "/",
+		Timeout: time.Duration(conf.Git.Timeout.Clone) * time.Second,
+	}
+	if conf.Repository.Archive.IncludeLFS {
+		opts.LFSObject = r.archiveLFSObject
+	}
+
+	// The generation is shared with other requests waiting in the pool, thus it
+	// must not be canceled by the current one.
+	err = gitx.CreateArchive(context.Background(), r.RepoPath(), commit.ID.String(), format, f, opts)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "create archive")
+	}
+
+	if err = os.Rename(f.Name(), archivePath); err != nil {
+		return "", errors.Wrap(err, "rename archive")
+	}
+	return archivePath, nil
+}
+
+// archiveLFSObject returns the LFS object that the pointer refers to if it
+// belongs to the repository.
+func (r *Repository) archiveLFSObject(pointer *lfsx.Pointer) (int64, func(w io.Writer) error, bool) {
+	object, err := Handle.LFS().GetObjectByOID(context.Background(), r.ID, pointer.OID)
+	if err != nil {
+		if !IsErrLFSObjectNotExist(err) {
+			log.Error("Failed to get LFS object %q of repository [%d]: %v", pointer.OID, r.ID, err)
+		}
+		return 0, nil, false
+	} else if object.Storage != lfsx.StorageLocal {
+		return 0, nil, false
+	}
+
+	storage := &lfsx.LocalStorage{
+		Root:    conf.LFS.ObjectsPath,
+		TempDir: conf.LFS.ObjectsTempPath,
+	}
+	return object.Size, func(w io.Writer) error {
+		return storage.Download(object.OID, w)
+	}, true
+}
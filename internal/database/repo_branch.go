@@ -3,7 +3,9 @@ package database
 import (
 	"context"
 	"fmt"
+	"path"
 	"slices"
+	"sort"
 	"strings"
 
 	"github.com/cockroachdb/errors"
@@ -91,35 +93,190 @@ type ProtectBranchWhitelist struct {
 	UserID          int64  `xorm:"UNIQUE(protect_branch_whitelist)"`
 }
 
-// IsUserInProtectBranchWhitelist returns true if given user is in the whitelist of a branch in a repository.
-func IsUserInProtectBranchWhitelist(repoID, userID int64, branch string) bool {
-	has, err := x.Where("repo_id = ?", repoID).And("user_id = ?", userID).And("name = ?", branch).Get(new(ProtectBranchWhitelist))
+// ProtectBranch contains options of a protected branch.
+//
+// A rule either belongs to a repository, or is a default rule of an
+// organization (RepoID is 0) which is inherited by all repositories of the
+// organization.
+type ProtectBranch struct {
+	ID     int64
+	RepoID int64 `xorm:"UNIQUE(protect_branch)"`
+	OrgID  int64 `xorm:"UNIQUE(protect_branch) INDEX NOT NULL DEFAULT 0"`
+	// Name is the branch name or a glob pattern of branch names, e.g.
+	// "release/*", see path.Match for the syntax.
+	Name                 string `xorm:"UNIQUE(protect_branch)"`
+	Protected            bool
+	RequirePullRequest   bool
+	RequireSignedCommits bool `xorm:"NOT NULL DEFAULT false"`
+	RequireLinearHistory bool `xorm:"NOT NULL DEFAULT false"`
+	// Whether a code owner of every changed file has to sign off on pull
+	// requests before merging.
+	RequireCodeOwnerSignOff bool `xorm:"NOT NULL DEFAULT false"`
+	EnableWhitelist         bool
+	WhitelistUserIDs        string `xorm:"TEXT"`
+	WhitelistTeamIDs        string `xorm:"TEXT"`
+}
+
+// IsValidBranchPattern returns true if given pattern is a valid branch name or
+// glob pattern of branch names.
+func IsValidBranchPattern(pattern string) bool {
+	return isValidRefPattern(pattern)
+}
+
+// IsPattern returns true if the name of the rule is a glob pattern rather than
+// a branch name.
+func (p *ProtectBranch) IsPattern() bool {
+	return strings.ContainsAny(p.Name, "*?[")
+}
+
+// IsOrgDefault returns true if the rule is a default rule of an organization.
+func (p *ProtectBranch) IsOrgDefault() bool {
+	return p.RepoID == 0 && p.OrgID > 0
+}
+
+// Match returns true if the branch name matches the name of the rule.
+func (p *ProtectBranch) Match(branch string) bool {
+	if p.Name == branch {
+		return true
+	} else if !p.IsPattern() {
+		return false
+	}
+	matched, _ := path.Match(p.Name, branch)
+	return matched
+}
+
+// specificity returns the number of characters of a branch name that the name
+// of the rule pins down, a character class counts as one character and
+// wildcards count as none.
+func (p *ProtectBranch) specificity() int {
+	n := 0
+	for i := 0; i < len(p.Name); i++ {
+		switch p.Name[i] {
+		case '*', '?':
+			continue
+		case '\\':
+			i++
+		case '[':
+			for i < len(p.Name) && p.Name[i] != ']' {
+				i++
+			}
+		}
+		n++
+	}
+	return n
+}
+
+// sortProtectBranches sorts rules in the order of precedence:
+//  1. Rules of the repository before default rules of the organization.
+//  2. Branch names before glob patterns.
+//  3. More specific patterns before less specific ones, i.e. patterns that pin
+//     down more characters of branch names.
+//  4. Names in alphabetical order.
+func sortProtectBranches(protectBranches []*ProtectBranch) {
+	sort.SliceStable(protectBranches, func(i, j int) bool {
+		a, b := protectBranches[i], protectBranches[j]
+		if a.IsOrgDefault() != b.IsOrgDefault() {
+			return !a.IsOrgDefault()
+		} else if a.IsPattern() != b.IsPattern() {
+			return !a.IsPattern()
+		} else if a.specificity() != b.specificity() {
+			return a.specificity() > b.specificity()
+		}
+		return a.Name < b.Name
+	})
+}
+
+// MatchProtectBranch returns the rule that applies to the branch, i.e. the
+// first matching rule in given rules which are sorted in the order of
+// precedence. It returns nil if no rule matches.
+func MatchProtectBranch(protectBranches []*ProtectBranch, branch string) *ProtectBranch {
+	for _, protectBranch := range protectBranches {
+		if protectBranch.Match(branch) {
+			return protectBranch
+		}
+	}
+	return nil
+}
+
+// IsUserWhitelisted returns true if given user is in the whitelist of the rule.
+// Whitelists of rules of repositories are stored with members of teams merged,
+// while default rules of organizations check membership of teams on the fly.
+func (p *ProtectBranch) IsUserWhitelisted(userID int64) bool {
+	if !p.IsOrgDefault() {
+		has, err := x.Where("protect_branch_id = ? AND user_id = ?", p.ID, userID).Exist(new(ProtectBranchWhitelist))
+		return has && err == nil
+	}
+
+	if slices.Contains(parseIDs(p.WhitelistUserIDs), userID) {
+		return true
+	}
+	teamIDs := parseIDs(p.WhitelistTeamIDs)
+	if len(teamIDs) == 0 {
+		return false
+	}
+	has, err := x.Where("uid = ?", userID).In("team_id", teamIDs).Exist(new(TeamUser))
 	return has && err == nil
 }
 
-// ProtectBranch contains options of a protected branch.
-type ProtectBranch struct {
-	ID                 int64
-	RepoID             int64  `xorm:"UNIQUE(protect_branch)"`
-	Name               string `xorm:"UNIQUE(protect_branch)"`
-	Protected          bool
-	RequirePullRequest bool
-	EnableWhitelist    bool
-	WhitelistUserIDs   string `xorm:"TEXT"`
-	WhitelistTeamIDs   string `xorm:"TEXT"`
+// GetProtectBranchRulesOfRepo returns all rules of the repository along with
+// default rules of the organization that owns the repository, sorted in the
+// order of precedence.
+func GetProtectBranchRulesOfRepo(repoID int64) ([]*ProtectBranch, error) {
+	protectBranches := make([]*ProtectBranch, 0, 2)
+	err := x.Where("repo_id = ?", repoID).
+		Or("repo_id = 0 AND org_id IN (SELECT owner_id FROM repository WHERE id = ?)", repoID).
+		Find(&protectBranches)
+	if err != nil {
+		return nil, err
+	}
+	sortProtectBranches(protectBranches)
+	return protectBranches, nil
 }
 
-// GetProtectBranchOfRepoByName returns *ProtectBranch by branch name in given repository.
+// GetProtectBranchOfRepoByName returns the rule that applies to the branch in
+// given repository, see sortProtectBranches for the order of precedence when
+// multiple rules match the branch. The rule applies even if it is not
+// protected, thus an unprotected rule exempts matching branches from rules of
+// lower precedence. It returns ErrBranchNotExist if no rule matches.
 func GetProtectBranchOfRepoByName(repoID int64, name string) (*ProtectBranch, error) {
+	protectBranches, err := GetProtectBranchRulesOfRepo(repoID)
+	if err != nil {
+		return nil, err
+	}
+
+	protectBranch := MatchProtectBranch(protectBranches, name)
+	if protectBranch == nil {
+		return nil, ErrBranchNotExist{args: map[string]any{"name": name}}
+	}
+	return protectBranch, nil
+}
+
+// GetProtectBranchByID returns the rule with given ID. It returns
+// ErrBranchNotExist when not found.
+func GetProtectBranchByID(id int64) (*ProtectBranch, error) {
+	protectBranch := new(ProtectBranch)
+	has, err := x.ID(id).Get(protectBranch)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrBranchNotExist{args: map[string]any{"protectBranchID": id}}
+	}
+	return protectBranch, nil
+}
+
+// GetProtectBranchOfRepoByPattern returns the rule of given repository whose
+// name is exactly given branch name or pattern. It returns ErrBranchNotExist
+// when not found.
+func GetProtectBranchOfRepoByPattern(repoID int64, pattern string) (*ProtectBranch, error) {
 	protectBranch := &ProtectBranch{
 		RepoID: repoID,
-		Name:   name,
+		Name:   pattern,
 	}
-	has, err := x.Get(protectBranch)
+	has, err := x.Where("org_id = 0").Get(protectBranch)
 	if err != nil {
 		return nil, err
 	} else if !has {
-		return nil, ErrBranchNotExist{args: map[string]any{"name": name}}
+		return nil, ErrBranchNotExist{args: map[string]any{"name": pattern}}
 	}
 	return protectBranch, nil
 }
@@ -274,3 +431,84 @@ func GetProtectBranchesByRepoID(repoID int64) ([]*ProtectBranch, error) {
 	protectBranches := make([]*ProtectBranch, 0, 2)
 	return protectBranches, x.Where("repo_id = ? and protected = ?", repoID, true).Asc("name").Find(&protectBranches)
 }
+
+// GetProtectBranchesByOrgID returns all default rules of the organization
+// sorted in the order of precedence.
+func GetProtectBranchesByOrgID(orgID int64) ([]*ProtectBranch, error) {
+	protectBranches := make([]*ProtectBranch, 0, 2)
+	if err := x.Where("repo_id = 0 AND org_id = ?", orgID).Find(&protectBranches); err != nil {
+		return nil, err
+	}
+	sortProtectBranches(protectBranches)
+	return protectBranches, nil
+}
+
+// GetProtectBranchOfOrgByPattern returns the default rule of the organization
+// whose name is exactly given branch name or pattern. It returns
+// ErrBranchNotExist when not found.
+func GetProtectBranchOfOrgByPattern(orgID int64, pattern string) (*ProtectBranch, error) {
+	protectBranch := &ProtectBranch{
+		OrgID: orgID,
+		Name:  pattern,
+	}
+	has, err := x.Where("repo_id = 0").Get(protectBranch)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrBranchNotExist{args: map[string]any{"name": pattern}}
+	}
+	return protectBranch, nil
+}
+
+// UpdateOrgDefaultProtectBranch saves the default rule of the organization,
+// users who are not members of the organization and teams of other
+// organizations are dropped from the whitelist. If ID is 0, it creates a new
+// record. Otherwise, updates existing record.
+func UpdateOrgDefaultProtectBranch(org *User, protectBranch *ProtectBranch) (err error) {
+	userIDs := make([]int64, 0, 5)
+	for _, userID := range parseIDs(protectBranch.WhitelistUserIDs) {
+		if !slices.Contains(userIDs, userID) && IsOrganizationMember(org.ID, userID) {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	protectBranch.WhitelistUserIDs = strings.Join(tool.Int64sToStrings(userIDs), ",")
+
+	teamIDs := make([]int64, 0, 5)
+	if whitelistTeamIDs := parseIDs(protectBranch.WhitelistTeamIDs); len(whitelistTeamIDs) > 0 {
+		teams, err := GetTeamsByOrgID(org.ID)
+		if err != nil {
+			return errors.Newf("get teams of organization: %v", err)
+		}
+		for _, team := range teams {
+			if slices.Contains(whitelistTeamIDs, team.ID) {
+				teamIDs = append(teamIDs, team.ID)
+			}
+		}
+	}
+	protectBranch.WhitelistTeamIDs = strings.Join(tool.Int64sToStrings(teamIDs), ",")
+
+	protectBranch.RepoID = 0
+	protectBranch.OrgID = org.ID
+	if protectBranch.ID == 0 {
+		_, err = x.Insert(protectBranch)
+	} else {
+		_, err = x.ID(protectBranch.ID).AllCols().Update(protectBranch)
+	}
+	return err
+}
+
+// DeleteProtectBranch deletes the rule along with its whitelist.
+func DeleteProtectBranch(protectBranch *ProtectBranch) (err error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err = sess.ID(protectBranch.ID).Delete(new(ProtectBranch)); err != nil {
+		return errors.Newf("delete protect branch: %v", err)
+	} else if _, err = sess.Delete(&ProtectBranchWhitelist{ProtectBranchID: protectBranch.ID}); err != nil {
+		return errors.Newf("delete protect branch whitelists: %v", err)
+	}
+	return sess.Commit()
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchProtectBranch(t *testing.T) {
+	protectBranches := []*ProtectBranch{
+		{ID: 1, OrgID: 1, Name: "main"},
+		{ID: 2, OrgID: 1, Name: "*"},
+		{ID: 3, RepoID: 1, Name: "release/*"},
+		{ID: 4, RepoID: 1, Name: "release/v1.*"},
+		{ID: 5, RepoID: 1, Name: "release/v1.0"},
+		{ID: 6, RepoID: 1, Name: "release/v[0-9].*"},
+		{ID: 7, RepoID: 1, Name: "feature/*"},
+		{ID: 8, RepoID: 1, Name: "feat*/*"},
+	}
+	sortProtectBranches(protectBranches)
+
+	tests := []struct {
+		branch string
+		wantID int64
+	}{
+		{branch: "main", wantID: 1},
+		{branch: "develop", wantID: 2},
+		{branch: "release/v1.0", wantID: 5},
+		{branch: "release/v1.1", wantID: 4},
+		{branch: "release/v2.0", wantID: 6},
+		{branch: "release/next", wantID: 3},
+		{branch: "feature/login", wantID: 7},
+		{branch: "feat/login", wantID: 8},
+		{branch: "release/v1.0/hotfix", wantID: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.branch, func(t *testing.T) {
+			got := MatchProtectBranch(protectBranches, test.branch)
+			if test.wantID == 0 {
+				assert.Nil(t, got)
+				return
+			}
+			if assert.NotNil(t, got) {
+				assert.Equal(t, test.wantID, got.ID)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package database
 
 import (
+	"context"
+
 	"github.com/cockroachdb/errors"
 	log "unknwon.dev/clog/v2"
 
@@ -47,7 +49,13 @@ func (r *Repository) IsCollaborator(userID int64) bool {
 }
 
 // AddCollaborator adds new collaboration to a repository with default access mode.
+// It returns ErrBlockedByUser if the owner of the repository has blocked the user.
 func (r *Repository) AddCollaborator(u *User) error {
+	err := Handle.UserBlocks().EnsureNotBlocked(context.TODO(), r.OwnerID, u.ID)
+	if err != nil {
+		return err
+	}
+
 	collaboration := &Collaboration{
 		RepoID: r.ID,
 		UserID: u.ID,
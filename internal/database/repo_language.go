@@ -0,0 +1,243 @@
+package database
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gogs/git-module"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/gitx"
+	"gogs.io/gogs/internal/linguist"
+	"gogs.io/gogs/internal/sync"
+)
+
+// LanguageStat is the number of bytes of a language in the default branch of a
+// repository.
+type LanguageStat struct {
+	ID       int64
+	RepoID   int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Language string `xorm:"UNIQUE(s) NOT NULL"`
+	Size     int64  `xorm:"NOT NULL DEFAULT 0"`
+	// CommitID is the ID of the commit that the statistics are computed at.
+	CommitID string `xorm:"VARCHAR(40)"`
+
+	Percentage float64 `xorm:"-" json:"-" gorm:"-"`
+}
+
+// Color returns the color of the language in the form of "#rrggbb".
+func (s *LanguageStat) Color() string {
+	return linguist.LanguageColor(s.Language)
+}
+
+// PrimaryLanguageColor returns the color of the primary language of the
+// repository in the form of "#rrggbb".
+func (r *Repository) PrimaryLanguageColor() string {
+	return linguist.LanguageColor(r.PrimaryLanguage)
+}
+
+// GetLanguageStats returns language statistics of the repository ordered by
+// size in descending order.
+func GetLanguageStats(repoID int64) ([]*LanguageStat, error) {
+	stats := make([]*LanguageStat, 0, 5)
+	if err := x.Where("repo_id = ?", repoID).Desc("size").Asc("language").Find(&stats); err != nil {
+		return nil, err
+	}
+
+	var total int64
+	for _, s := range stats {
+		total += s.Size
+	}
+	for _, s := range stats {
+		if total > 0 {
+			s.Percentage = float64(s.Size) * 100 / float64(total)
+		}
+	}
+	return stats, nil
+}
+
+// GetPrimaryLanguages returns all distinct primary languages of repositories
+// that are visible to the user in exploring, in alphabetical order. The user ID
+// is zero for anonymous visitors.
+func GetPrimaryLanguages(userID int64) ([]string, error) {
+	langs := make([]string, 0, 10)
+	sess := x.Table("repository").Alias("repo").Where("repo.primary_language != ''")
+	// Use the same visibility rules as SearchRepositoryByName
+	if userID > 0 {
+		sess.Join("LEFT", "access", "access.repo_id = repo.id").
+			And("repo.owner_id = ? OR access.user_id = ? OR (repo.is_private = ? AND repo.is_unlisted = ?) OR (repo.is_private = ? AND (repo.allow_public_wiki = ? OR repo.allow_public_issues = ?))", userID, userID, false, false, true, true, true)
+	} else {
+		sess.And("(repo.is_private = ? AND repo.is_unlisted = ?) OR (repo.is_private = ? AND (repo.allow_public_wiki = ? OR repo.allow_public_issues = ?))", false, false, true, true, true)
+	}
+	err := sess.Distinct("repo.primary_language").Find(&langs)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(langs)
+	return langs, nil
+}
+
+// LanguageStatsQueue is the queue of repositories whose language statistics
+// need to be (re)computed.
+var LanguageStatsQueue = sync.NewUniqueQueue(1000)
+
+// UpdateLanguageStats queues the repository to compute language statistics of
+// the latest commit of its default branch.
+func UpdateLanguageStats(repoID int64) {
+	go LanguageStatsQueue.Add(repoID)
+}
+
+// computeLanguageStats returns the commit ID of the default branch of the
+// repository and language statistics of the commit. The commit ID is empty if
+// the default branch does not exist.
+func computeLanguageStats(repo *Repository) (string, map[string]int64, error) {
+	repoPath := repo.RepoPath()
+	commitID, err := git.ShowRefVerify(repoPath, git.RefsHeads+repo.DefaultBranch)
+	if err != nil {
+		if err == git.ErrReferenceNotExist {
+			return "", nil, nil
+		}
+		return "", nil, errors.Newf("get commit ID of default branch: %v", err)
+	}
+
+	timeout := time.Duration(conf.Git.Timeout.Clone) * time.Second
+	files, err := gitx.ListFiles(repoPath, commitID, timeout)
+	if err != nil {
+		return "", nil, errors.Newf("list files: %v", err)
+	}
+
+	sizes := make(map[string]int64, len(files))
+	attrDirs := make(map[string]string) // Blob ID -> directory
+	attrIDs := make([]string, 0, 1)
+	for _, f := range files {
+		sizes[f.Path] = f.Size
+		if dir, ok := linguist.GitAttributesDir(f.Path); ok {
+			attrDirs[f.ID] = dir
+			attrIDs = append(attrIDs, f.ID)
+		}
+	}
+
+	// Rules of .gitattributes files in parent directories must be added first.
+	sort.SliceStable(attrIDs, func(i, j int) bool {
+		return len(attrDirs[attrIDs[i]]) < len(attrDirs[attrIDs[j]])
+	})
+	contents := make(map[string][]byte, len(attrIDs))
+	err = gitx.ReadBlobs(repoPath, attrIDs, timeout, func(id string, content []byte) error {
+		contents[id] = content
+		return nil
+	})
+	if err != nil {
+		return "", nil, errors.Newf("read .gitattributes: %v", err)
+	}
+	var attrs linguist.GitAttributes
+	for _, id := range attrIDs {
+		attrs.Add(attrDirs[id], contents[id])
+	}
+
+	return commitID, linguist.Stats(sizes, &attrs), nil
+}
+
+// updateLanguageStats computes and saves language statistics of the default
+// branch of the repository, and updates the primary language of the
+// repository. Nothing is done if statistics of the latest commit exist.
+func updateLanguageStats(repoID int64) error {
+	repo, err := getRepositoryByID(x, repoID)
+	if err != nil {
+		if IsErrRepoNotExist(err) {
+			return nil
+		}
+		return errors.Newf("get repository by ID: %v", err)
+	}
+
+	var commitID string
+	var sizes map[string]int64
+	if !repo.IsBare {
+		commitID, sizes, err = computeLanguageStats(repo)
+		if err != nil {
+			return err
+		}
+	}
+
+	if commitID != "" {
+		has, err := x.Where("repo_id = ? AND commit_id = ?", repoID, commitID).Exist(new(LanguageStat))
+		if err != nil {
+			return errors.Newf("check existence: %v", err)
+		} else if has {
+			return nil
+		}
+	}
+
+	primary := ""
+	stats := make([]*LanguageStat, 0, len(sizes))
+	for lang, size := range sizes {
+		stats = append(stats, &LanguageStat{
+			RepoID:   repoID,
+			Language: lang,
+			Size:     size,
+			CommitID: commitID,
+		})
+		if primary == "" || size > sizes[primary] || (size == sizes[primary] && lang < primary) {
+			primary = lang
+		}
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err = sess.Delete(&LanguageStat{RepoID: repoID}); err != nil {
+		return errors.Newf("delete old stats: %v", err)
+	}
+	if len(stats) > 0 {
+		if _, err = sess.Insert(&stats); err != nil {
+			return errors.Newf("insert stats: %v", err)
+		}
+	}
+	if _, err = sess.ID(repoID).Cols("primary_language").Update(&Repository{PrimaryLanguage: primary}); err != nil {
+		return errors.Newf("update primary language: %v", err)
+	}
+	return sess.Commit()
+}
+
+func processLanguageStatsQueue() {
+	for repoID := range LanguageStatsQueue.Queue() {
+		LanguageStatsQueue.Remove(repoID)
+
+		id, _ := strconv.ParseInt(repoID, 10, 64)
+		if err := updateLanguageStats(id); err != nil {
+			log.Error("Failed to update language stats of repository [%d]: %v", id, err)
+		}
+	}
+}
+
+// queueAllRepositoriesForLanguageStats queues all non-bare repositories to
+// compute language statistics of pushes made while the server was down.
+func queueAllRepositoriesForLanguageStats() {
+	var lastID int64
+	for {
+		repos := make([]*Repository, 0, 100)
+		if err := x.Cols("id").Where("id > ? AND is_bare = ?", lastID, false).Asc("id").Limit(100).Find(&repos); err != nil {
+			log.Error("Failed to find repositories to compute language stats: %v", err)
+			return
+		} else if len(repos) == 0 {
+			break
+		}
+
+		for _, repo := range repos {
+			LanguageStatsQueue.Add(repo.ID)
+		}
+		lastID = repos[len(repos)-1].ID
+	}
+}
+
+// InitLanguageStats starts processing the queue of language statistics, all
+// repositories are checked for changes made while the server was down.
+func InitLanguageStats() {
+	go processLanguageStatsQueue()
+	go queueAllRepositoriesForLanguageStats()
+}
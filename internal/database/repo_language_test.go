@@ -0,0 +1,63 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPrimaryLanguages(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	newLegacyTestDB(t, "TestGetPrimaryLanguages")
+
+	alice := createLegacyTestUser(t, "alice")
+	bob := createLegacyTestUser(t, "bob")
+	cindy := createLegacyTestUser(t, "cindy")
+
+	setLanguage := func(t *testing.T, repo *Repository, lang string) {
+		t.Helper()
+
+		repo.PrimaryLanguage = lang
+		_, err := x.ID(repo.ID).Cols("primary_language", "is_unlisted", "allow_public_issues").Update(repo)
+		require.NoError(t, err)
+	}
+
+	setLanguage(t, createLegacyTestRepo(t, alice, "public", false), "Go")
+	setLanguage(t, createLegacyTestRepo(t, alice, "other", false), "Go")
+	setLanguage(t, createLegacyTestRepo(t, alice, "empty", false), "")
+	unlisted := createLegacyTestRepo(t, alice, "unlisted", false)
+	unlisted.IsUnlisted = true
+	setLanguage(t, unlisted, "Rust")
+	publicIssues := createLegacyTestRepo(t, alice, "issues", true)
+	publicIssues.AllowPublicIssues = true
+	setLanguage(t, publicIssues, "C")
+	shared := createLegacyTestRepo(t, alice, "shared", true)
+	setLanguage(t, shared, "Python")
+	setLanguage(t, createLegacyTestRepo(t, alice, "private", true), "Haskell")
+	setLanguage(t, createLegacyTestRepo(t, cindy, "private", true), "Elixir")
+
+	err := Handle.Permissions().SetRepoPerms(t.Context(), shared.ID, map[int64]AccessMode{bob.ID: AccessModeRead})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		userID int64
+		want   []string
+	}{
+		{name: "anonymous", want: []string{"C", "Go"}},
+		{name: "collaborator", userID: bob.ID, want: []string{"C", "Go", "Python"}},
+		{name: "owner", userID: alice.ID, want: []string{"C", "Go", "Haskell", "Python", "Rust"}},
+		{name: "other owner", userID: cindy.ID, want: []string{"C", "Elixir", "Go"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := GetPrimaryLanguages(test.userID)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -1,8 +1,19 @@
 package database
 
 import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/cockroachdb/errors"
 	"github.com/gogs/git-module"
+	"xorm.io/xorm"
+
+	"gogs.io/gogs/internal/errx"
+	"gogs.io/gogs/internal/tool"
 )
 
 type Tag struct {
@@ -45,3 +56,268 @@ func GetTagsByPath(path string) ([]*Tag, error) {
 func (r *Repository) GetTags() ([]*Tag, error) {
 	return GetTagsByPath(r.RepoPath())
 }
+
+// ProtectTag is a rule that only allows whitelisted users and members of
+// whitelisted teams to create, update or delete tags matching the pattern.
+type ProtectTag struct {
+	ID     int64
+	RepoID int64 `xorm:"UNIQUE(protect_tag) INDEX"`
+	// Pattern is a glob pattern of tag names, e.g. "v*", see path.Match for the
+	// syntax.
+	Pattern          string `xorm:"UNIQUE(protect_tag)"`
+	WhitelistUserIDs string `xorm:"TEXT"`
+	WhitelistTeamIDs string `xorm:"TEXT"`
+
+	Users []*User `xorm:"-" json:"-" gorm:"-"`
+	Teams []*Team `xorm:"-" json:"-" gorm:"-"`
+
+	Created     time.Time `xorm:"-" json:"-" gorm:"-"`
+	CreatedUnix int64
+}
+
+func (t *ProtectTag) BeforeInsert() {
+	t.CreatedUnix = time.Now().Unix()
+}
+
+func (t *ProtectTag) AfterSet(colName string, _ xorm.Cell) {
+	switch colName {
+	case "created_unix":
+		t.Created = time.Unix(t.CreatedUnix, 0).Local()
+	}
+}
+
+// isValidRefPattern returns true if given pattern is a valid glob pattern of
+// reference names.
+func isValidRefPattern(pattern string) bool {
+	if pattern == "" || strings.ContainsAny(pattern, " \t\n") {
+		return false
+	}
+	_, err := path.Match(pattern, "")
+	return err == nil
+}
+
+// IsValidTagPattern returns true if given pattern is a valid glob pattern of
+// tag names.
+func IsValidTagPattern(pattern string) bool {
+	return isValidRefPattern(pattern)
+}
+
+// Match returns true if the tag name matches the pattern of the rule.
+func (t *ProtectTag) Match(tagName string) bool {
+	matched, _ := path.Match(t.Pattern, tagName)
+	return matched
+}
+
+// parseIDs returns positive IDs in the comma-separated list.
+func parseIDs(list string) []int64 {
+	ids := make([]int64, 0, 5)
+	for _, id := range tool.StringsToInt64s(strings.Split(list, ",")) {
+		if id > 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// WhitelistUsers returns IDs of whitelisted users.
+func (t *ProtectTag) WhitelistUsers() []int64 {
+	return parseIDs(t.WhitelistUserIDs)
+}
+
+// WhitelistTeams returns IDs of whitelisted teams.
+func (t *ProtectTag) WhitelistTeams() []int64 {
+	return parseIDs(t.WhitelistTeamIDs)
+}
+
+// LoadAttributes loads whitelisted users and teams of the rule.
+func (t *ProtectTag) LoadAttributes() error {
+	t.Users = make([]*User, 0, 5)
+	if userIDs := t.WhitelistUsers(); len(userIDs) > 0 {
+		if err := x.In("id", userIDs).Asc("lower_name").Find(&t.Users); err != nil {
+			return errors.Newf("find users: %v", err)
+		}
+	}
+
+	t.Teams = make([]*Team, 0, 5)
+	if teamIDs := t.WhitelistTeams(); len(teamIDs) > 0 {
+		if err := x.In("id", teamIDs).Asc("lower_name").Find(&t.Teams); err != nil {
+			return errors.Newf("find teams: %v", err)
+		}
+	}
+	return nil
+}
+
+// isUserWhitelisted returns true if the user or any team of the user is in the
+// whitelist of the rule.
+func (t *ProtectTag) isUserWhitelisted(e Engine, userID int64) (bool, error) {
+	if slices.Contains(t.WhitelistUsers(), userID) {
+		return true, nil
+	}
+
+	teamIDs := t.WhitelistTeams()
+	if len(teamIDs) == 0 {
+		return false, nil
+	}
+	return e.Where("uid = ?", userID).In("team_id", teamIDs).Exist(new(TeamUser))
+}
+
+// filterWhitelist drops users and teams that do not have write access to the
+// repository from the whitelist.
+func (t *ProtectTag) filterWhitelist(repo *Repository) error {
+	userIDs := make([]int64, 0, 5)
+	for _, userID := range t.WhitelistUsers() {
+		if slices.Contains(userIDs, userID) {
+			continue
+		}
+		if Handle.Permissions().Authorize(context.TODO(), userID, repo.ID, AccessModeWrite,
+			AccessModeOptions{
+				OwnerID: repo.OwnerID,
+				Private: repo.IsPrivate,
+			},
+		) {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	t.WhitelistUserIDs = strings.Join(tool.Int64sToStrings(userIDs), ",")
+
+	teamIDs := make([]int64, 0, 5)
+	if whitelistTeamIDs := t.WhitelistTeams(); len(whitelistTeamIDs) > 0 {
+		teams, err := GetTeamsHaveAccessToRepo(repo.OwnerID, repo.ID, AccessModeWrite)
+		if err != nil {
+			return errors.Newf("get teams have access to repository: %v", err)
+		}
+		for _, team := range teams {
+			if slices.Contains(whitelistTeamIDs, team.ID) {
+				teamIDs = append(teamIDs, team.ID)
+			}
+		}
+	}
+	t.WhitelistTeamIDs = strings.Join(tool.Int64sToStrings(teamIDs), ",")
+	return nil
+}
+
+// GetProtectTagsByRepoID returns all tag protection rules of the repository
+// ordered by patterns.
+func GetProtectTagsByRepoID(repoID int64) ([]*ProtectTag, error) {
+	protectTags := make([]*ProtectTag, 0, 2)
+	return protectTags, x.Where("repo_id = ?", repoID).Asc("pattern").Find(&protectTags)
+}
+
+// GetProtectTagByID returns the tag protection rule with given ID in the
+// repository. It returns ErrProtectTagNotExist when not found.
+func GetProtectTagByID(repoID, id int64) (*ProtectTag, error) {
+	protectTag := new(ProtectTag)
+	has, err := x.Where("id = ? AND repo_id = ?", id, repoID).Get(protectTag)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrProtectTagNotExist{args: errx.Args{"repoID": repoID, "id": id}}
+	}
+	return protectTag, nil
+}
+
+// SaveProtectTag validates and saves the tag protection rule of the
+// repository, users and teams without write access are dropped from the
+// whitelist. If ID is 0, it creates a new rule. Otherwise, updates the existing
+// rule. It returns ErrInvalidTagPattern if the pattern is not valid, or
+// ErrProtectTagAlreadyExist if another rule has the same pattern.
+func SaveProtectTag(repo *Repository, protectTag *ProtectTag) error {
+	if !IsValidTagPattern(protectTag.Pattern) {
+		return ErrInvalidTagPattern{args: errx.Args{"pattern": protectTag.Pattern}}
+	}
+
+	has, err := x.Where("repo_id = ? AND pattern = ? AND id != ?", repo.ID, protectTag.Pattern, protectTag.ID).Exist(new(ProtectTag))
+	if err != nil {
+		return err
+	} else if has {
+		return ErrProtectTagAlreadyExist{args: errx.Args{"repoID": repo.ID, "pattern": protectTag.Pattern}}
+	}
+
+	if err = protectTag.filterWhitelist(repo); err != nil {
+		return errors.Newf("filter whitelist: %v", err)
+	}
+
+	protectTag.RepoID = repo.ID
+	if protectTag.ID == 0 {
+		_, err = x.Insert(protectTag)
+	} else {
+		_, err = x.ID(protectTag.ID).AllCols().Update(protectTag)
+	}
+	return err
+}
+
+// DeleteProtectTagByID deletes the tag protection rule with given ID in the
+// repository.
+func DeleteProtectTagByID(repoID, id int64) error {
+	_, err := x.Delete(&ProtectTag{ID: id, RepoID: repoID})
+	return err
+}
+
+// CanUserChangeTag returns true if the user is allowed to create, update or
+// delete the tag in the repository, i.e. the user is whitelisted by every tag
+// protection rule that matches the tag name.
+func CanUserChangeTag(repoID, userID int64, tagName string) (bool, error) {
+	protectTags, err := GetProtectTagsByRepoID(repoID)
+	if err != nil {
+		return false, errors.Newf("get protect tags: %v", err)
+	}
+
+	for _, protectTag := range protectTags {
+		if !protectTag.Match(tagName) {
+			continue
+		}
+
+		whitelisted, err := protectTag.isUserWhitelisted(x, userID)
+		if err != nil {
+			return false, errors.Newf("check whitelist: %v", err)
+		} else if !whitelisted {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+var _ errx.NotFound = (*ErrProtectTagNotExist)(nil)
+
+type ErrProtectTagNotExist struct {
+	args errx.Args
+}
+
+func IsErrProtectTagNotExist(err error) bool {
+	_, ok := err.(ErrProtectTagNotExist)
+	return ok
+}
+
+func (err ErrProtectTagNotExist) Error() string {
+	return fmt.Sprintf("tag protection rule does not exist: %v", err.args)
+}
+
+func (ErrProtectTagNotExist) NotFound() bool {
+	return true
+}
+
+type ErrProtectTagAlreadyExist struct {
+	args errx.Args
+}
+
+func IsErrProtectTagAlreadyExist(err error) bool {
+	_, ok := err.(ErrProtectTagAlreadyExist)
+	return ok
+}
+
+func (err ErrProtectTagAlreadyExist) Error() string {
+	return fmt.Sprintf("tag protection rule already exists: %v", err.args)
+}
+
+type ErrInvalidTagPattern struct {
+	args errx.Args
+}
+
+func IsErrInvalidTagPattern(err error) bool {
+	_, ok := err.(ErrInvalidTagPattern)
+	return ok
+}
+
+func (err ErrInvalidTagPattern) Error() string {
+	return fmt.Sprintf("tag pattern is not valid: %v", err.args)
+}
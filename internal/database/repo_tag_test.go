@@ -0,0 +1,46 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidTagPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    bool
+	}{
+		{pattern: "v*", want: true},
+		{pattern: "release-[0-9]*", want: true},
+		{pattern: "v1.0.0", want: true},
+		{pattern: "", want: false},
+		{pattern: "v *", want: false},
+		{pattern: "v[", want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.pattern, func(t *testing.T) {
+			assert.Equal(t, test.want, IsValidTagPattern(test.pattern))
+		})
+	}
+}
+
+func TestProtectTag_Match(t *testing.T) {
+	tests := []struct {
+		pattern string
+		tagName string
+		want    bool
+	}{
+		{pattern: "v*", tagName: "v1.0.0", want: true},
+		{pattern: "v*", tagName: "release-1", want: false},
+		{pattern: "release-[0-9]*", tagName: "release-1.2", want: true},
+		{pattern: "release-[0-9]*", tagName: "release-rc", want: false},
+		{pattern: "v1.0.0", tagName: "v1.0.0", want: true},
+		{pattern: "v1.0.0", tagName: "v1.0.01", want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.pattern+"/"+test.tagName, func(t *testing.T) {
+			assert.Equal(t, test.want, (&ProtectTag{Pattern: test.pattern}).Match(test.tagName))
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+
+	"gogs.io/gogs/internal/errx"
+	"gogs.io/gogs/internal/lazyregexp"
+)
+
+// MaxRepoTopics is the maximum number of topics of a repository.
+const MaxRepoTopics = 20
+
+var topicPattern = lazyregexp.New(`^[a-z0-9][a-z0-9-]{0,34}$`)
+
+// Topic is a short tag to classify repositories.
+type Topic struct {
+	ID   int64
+	Name string `xorm:"UNIQUE NOT NULL"`
+}
+
+// RepoTopic represents a topic of a repository.
+type RepoTopic struct {
+	ID      int64
+	RepoID  int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	TopicID int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+}
+
+// NormalizeTopic returns the name of the topic in lower case with spaces
+// replaced by dashes.
+func NormalizeTopic(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), "-"))
+}
+
+// NormalizeTopics normalizes given names of topics and removes empty and
+// duplicated ones. It returns ErrInvalidTopic if any of the names is not a valid
+// topic, or ErrRepoTopicsLimit if there are more than MaxRepoTopics topics.
+func NormalizeTopics(names []string) ([]string, error) {
+	topics := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = NormalizeTopic(name)
+		if name == "" || seen[name] {
+			continue
+		} else if !topicPattern.MatchString(name) {
+			return nil, ErrInvalidTopic{args: errx.Args{"name": name}}
+		}
+		seen[name] = true
+		topics = append(topics, name)
+	}
+
+	if len(topics) > MaxRepoTopics {
+		return nil, ErrRepoTopicsLimit{args: errx.Args{"limit": MaxRepoTopics}}
+	}
+	sort.Strings(topics)
+	return topics, nil
+}
+
+// GetRepoTopics returns names of topics of the repository in alphabetical
+// order.
+func GetRepoTopics(repoID int64) ([]string, error) {
+	topics := make([]string, 0, 5)
+	return topics, x.Table("topic").
+		Join("INNER", "repo_topic", "repo_topic.topic_id = topic.id").
+		Where("repo_topic.repo_id = ?", repoID).
+		Cols("topic.name").
+		Asc("topic.name").
+		Find(&topics)
+}
+
+// SaveRepoTopics replaces all topics of the repository with given names of
+// topics. It returns errors of NormalizeTopics for invalid names.
+func SaveRepoTopics(repoID int64, names []string) (err error) {
+	names, err = NormalizeTopics(names)
+	if err != nil {
+		return err
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err = sess.Delete(&RepoTopic{RepoID: repoID}); err != nil {
+		return errors.Newf("delete repository topics: %v", err)
+	}
+	for _, name := range names {
+		topic := &Topic{Name: name}
+		has, err := sess.Get(topic)
+		if err != nil {
+			return errors.Newf("get topic: %v", err)
+		} else if !has {
+			if _, err = sess.Insert(topic); err != nil {
+				return errors.Newf("insert topic: %v", err)
+			}
+		}
+
+		if _, err = sess.Insert(&RepoTopic{RepoID: repoID, TopicID: topic.ID}); err != nil {
+			return errors.Newf("insert repository topic: %v", err)
+		}
+	}
+
+	return sess.Commit()
+}
+
+// loadRepoTopics assigns topics of all given repositories in one query.
+func loadRepoTopics(e Engine, repos []*Repository) error {
+	repoIDs := make([]int64, 0, len(repos))
+	for _, repo := range repos {
+		repoIDs = append(repoIDs, repo.ID)
+	}
+
+	rows := make([]*struct {
+		RepoID int64
+		Name   string
+	}, 0, len(repos))
+	if err := e.Table("repo_topic").
+		Join("INNER", "topic", "topic.id = repo_topic.topic_id").
+		In("repo_topic.repo_id", repoIDs).
+		Select("repo_topic.repo_id, topic.name").
+		Asc("topic.name").
+		Find(&rows); err != nil {
+		return err
+	}
+
+	topics := make(map[int64][]string, len(repos))
+	for _, row := range rows {
+		topics[row.RepoID] = append(topics[row.RepoID], row.Name)
+	}
+	for _, repo := range repos {
+		repo.Topics = topics[repo.ID]
+	}
+	return nil
+}
+
+// TopicCount is a topic with the number of public repositories of the topic.
+type TopicCount struct {
+	Name     string `json:"name"`
+	NumRepos int64  `json:"repo_count"`
+}
+
+type SearchTopicOptions struct {
+	Keyword  string // Part of names of topics
+	Page     int
+	PageSize int
+}
+
+// SearchTopics returns topics that are used by at least one public repository,
+// along with the number of public repositories of each topic, ordered by the
+// number of repositories in descending order. It also returns the number of
+// total results.
+func SearchTopics(opts *SearchTopicOptions) (topics []*TopicCount, count int64, err error) {
+	if opts.Page <= 0 {
+		opts.Page = 1
+	}
+
+	sess := x.Table("repo_topic").
+		Join("INNER", "topic", "topic.id = repo_topic.topic_id").
+		Join("INNER", "repository", "repository.id = repo_topic.repo_id").
+		Where("repository.is_private = ? AND repository.is_unlisted = ?", false, false)
+	if opts.Keyword != "" {
+		sess.And("topic.name LIKE ?", "%"+NormalizeTopic(opts.Keyword)+"%")
+	}
+
+	count, err = sess.Clone().Distinct("topic.name").Count()
+	if err != nil {
+		return nil, 0, errors.Newf("count: %v", err)
+	}
+
+	topics = make([]*TopicCount, 0, opts.PageSize)
+	return topics, count, sess.
+		Select("topic.name AS name, COUNT(*) AS num_repos").
+		GroupBy("topic.name").
+		OrderBy("num_repos DESC, topic.name ASC").
+		Limit(opts.PageSize, (opts.Page-1)*opts.PageSize).
+		Find(&topics)
+}
+
+type ErrInvalidTopic struct {
+	args errx.Args
+}
+
+func IsErrInvalidTopic(err error) bool {
+	_, ok := err.(ErrInvalidTopic)
+	return ok
+}
+
+func (err ErrInvalidTopic) Error() string {
+	return fmt.Sprintf("topic is not valid: %v", err.args)
+}
+
+// Topic returns the name of the invalid topic.
+func (err ErrInvalidTopic) Topic() string {
+	name, _ := err.args["name"].(string)
+	return name
+}
+
+type ErrRepoTopicsLimit struct {
+	args errx.Args
+}
+
+func IsErrRepoTopicsLimit(err error) bool {
+	_, ok := err.(ErrRepoTopicsLimit)
+	return ok
+}
+
+func (err ErrRepoTopicsLimit) Error() string {
+	return fmt.Sprintf("maximum number of topics of a repository reached: %v", err.args)
+}
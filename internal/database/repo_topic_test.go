@@ -0,0 +1,64 @@
+package database
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTopics(t *testing.T) {
+	tooMany := make([]string, MaxRepoTopics+1)
+	for i := range tooMany {
+		tooMany[i] = "topic-" + strconv.Itoa(i)
+	}
+
+	tests := []struct {
+		name    string
+		topics  []string
+		want    []string
+		wantErr func(error) bool
+	}{
+		{
+			name:   "empty",
+			topics: []string{"", "  "},
+			want:   []string{},
+		},
+		{
+			name:   "normalized and deduplicated",
+			topics: []string{"Go", " web  Framework ", "go", "cli"},
+			want:   []string{"cli", "go", "web-framework"},
+		},
+		{
+			name:    "invalid characters",
+			topics:  []string{"go", "c++"},
+			wantErr: IsErrInvalidTopic,
+		},
+		{
+			name:    "starts with a dash",
+			topics:  []string{"-go"},
+			wantErr: IsErrInvalidTopic,
+		},
+		{
+			name:    "too long",
+			topics:  []string{"abcdefghijklmnopqrstuvwxyz0123456789"},
+			wantErr: IsErrInvalidTopic,
+		},
+		{
+			name:    "too many",
+			topics:  tooMany,
+			wantErr: IsErrRepoTopicsLimit,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := NormalizeTopics(test.topics)
+			if test.wantErr != nil {
+				assert.True(t, test.wantErr(err), "unexpected error: %v", err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
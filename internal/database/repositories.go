@@ -319,8 +319,27 @@ func (s *RepositoriesStore) recountStars(tx *gorm.DB, userID, repoID int64) erro
 	return nil
 }
 
-// Star marks the user to star the repository.
+// ensureOwnerNotBlocked returns ErrBlockedByUser if the owner of the
+// repository has blocked the user.
+func (s *RepositoriesStore) ensureOwnerNotBlocked(ctx context.Context, repoID, userID int64) error {
+	var ownerIDs []int64
+	err := s.db.WithContext(ctx).Model(new(Repository)).Where("id = ?", repoID).Pluck("owner_id", &ownerIDs).Error
+	if err != nil {
+		return errors.Wrap(err, "get owner")
+	} else if len(ownerIDs) == 0 {
+		return nil
+	}
+	return newUserBlocksStore(s.db).EnsureNotBlocked(ctx, ownerIDs[0], userID)
+}
+
+// Star marks the user to star the repository. It returns ErrBlockedByUser if
+// the owner of the repository has blocked the user.
 func (s *RepositoriesStore) Star(ctx context.Context, userID, repoID int64) error {
+	err := s.ensureOwnerNotBlocked(ctx, repoID, userID)
+	if err != nil {
+		return err
+	}
+
 	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		star := &Star{
 			UserID: userID,
@@ -375,8 +394,14 @@ func (s *RepositoriesStore) recountWatches(tx *gorm.DB, repoID int64) error {
 		Error
 }
 
-// Watch marks the user to watch the repository.
+// Watch marks the user to watch the repository. It returns ErrBlockedByUser if
+// the owner of the repository has blocked the user.
 func (s *RepositoriesStore) Watch(ctx context.Context, userID, repoID int64) error {
+	err := s.ensureOwnerNotBlocked(ctx, repoID, userID)
+	if err != nil {
+		return err
+	}
+
 	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		w := &Watch{
 			UserID: userID,
@@ -255,6 +255,19 @@ func reposStar(t *testing.T, ctx context.Context, s *RepositoriesStore) {
 	alice, err = usersStore.GetByID(ctx, alice.ID)
 	require.NoError(t, err)
 	assert.Equal(t, 1, alice.NumStars)
+
+	t.Run("blocked by owner", func(t *testing.T) {
+		err := newUserBlocksStore(s.db).Block(ctx, repo1.OwnerID, 3)
+		require.NoError(t, err)
+
+		err = s.Star(ctx, 3, repo1.ID)
+		wantErr := ErrBlockedByUser{args: errx.Args{"userID": repo1.OwnerID, "blockID": int64(3)}}
+		assert.Equal(t, wantErr, err)
+
+		repo1, err = s.GetByID(ctx, repo1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, repo1.NumStars)
+	})
 }
 
 func reposTouch(t *testing.T, ctx context.Context, s *RepositoriesStore) {
@@ -319,6 +332,19 @@ func reposWatch(t *testing.T, ctx context.Context, s *RepositoriesStore) {
 	repo1, err = reposStore.GetByID(ctx, repo1.ID)
 	require.NoError(t, err)
 	assert.Equal(t, 2, repo1.NumWatches) // The owner is watching the repo by default.
+
+	t.Run("blocked by owner", func(t *testing.T) {
+		err := newUserBlocksStore(s.db).Block(ctx, repo1.OwnerID, 3)
+		require.NoError(t, err)
+
+		err = s.Watch(ctx, 3, repo1.ID)
+		wantErr := ErrBlockedByUser{args: errx.Args{"userID": repo1.OwnerID, "blockID": int64(3)}}
+		assert.Equal(t, wantErr, err)
+
+		repo1, err = reposStore.GetByID(ctx, repo1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, repo1.NumWatches)
+	})
 }
 
 func reposHasForkedBy(t *testing.T, ctx context.Context, s *RepositoriesStore) {
@@ -0,0 +1,175 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"golang.org/x/crypto/ssh"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/errx"
+)
+
+const (
+	tplPrincipal = `command="%s serv user-%d --config='%s'",no-port-forwarding,no-X11-forwarding,no-agent-forwarding,no-pty %s` + "\n"
+
+	// sshCertSourceAddressOption is the only critical option that we know how to
+	// honor, certificates with any other critical option are rejected.
+	sshCertSourceAddressOption = "source-address"
+)
+
+// LoadTrustedUserCAKeys parses the public keys of trusted user certificate
+// authorities from the file at given path, which is in the same format as the
+// "authorized_keys" file. It returns nil without error when the path is empty.
+func LoadTrustedUserCAKeys(path string) ([]ssh.PublicKey, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "read file")
+	}
+
+	var keys []ssh.PublicKey
+	for len(bytes.TrimSpace(data)) > 0 {
+		var key ssh.PublicKey
+		key, _, _, data, err = ssh.ParseAuthorizedKey(data)
+		if err != nil {
+			return nil, errors.Wrap(err, "parse authorized key")
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+type ErrSSHCertificateInvalid struct {
+	args errx.Args
+}
+
+// IsErrSSHCertificateInvalid returns true if the underlying error has the type
+// ErrSSHCertificateInvalid.
+func IsErrSSHCertificateInvalid(err error) bool {
+	return errors.As(err, &ErrSSHCertificateInvalid{})
+}
+
+func (err ErrSSHCertificateInvalid) Error() string {
+	return fmt.Sprintf("SSH certificate is invalid: %v", err.args)
+}
+
+// CheckSSHUserCertificate checks whether the given user certificate is signed
+// by one of the given trusted authorities, is within its validity window, and
+// carries only critical options that we know how to honor. The "source-address"
+// critical option is verified against the given remote address when it is not
+// nil. It returns ErrSSHCertificateInvalid when any of checks fails.
+func CheckSSHUserCertificate(cert *ssh.Certificate, authorities []ssh.PublicKey, remoteAddr net.Addr) error {
+	if len(cert.ValidPrincipals) == 0 {
+		return ErrSSHCertificateInvalid{args: errx.Args{"keyID": cert.KeyId, "reason": "no principals"}}
+	}
+
+	if cert.CertType != ssh.UserCert {
+		return ErrSSHCertificateInvalid{args: errx.Args{"keyID": cert.KeyId, "reason": "not a user certificate"}}
+	}
+
+	trusted := false
+	for _, key := range authorities {
+		if bytes.Equal(key.Marshal(), cert.SignatureKey.Marshal()) {
+			trusted = true
+			break
+		}
+	}
+	if !trusted {
+		return ErrSSHCertificateInvalid{args: errx.Args{"keyID": cert.KeyId, "reason": "untrusted authority"}}
+	}
+
+	// NOTE: "CheckCert" verifies the signature, validity window and critical
+	// options, but not the authority nor the certificate type. It also accepts
+	// only principals listed in the certificate, thus checking against any one of
+	// them is sufficient.
+	checker := &ssh.CertChecker{
+		SupportedCriticalOptions: []string{sshCertSourceAddressOption},
+	}
+	err := checker.CheckCert(cert.ValidPrincipals[0], cert)
+	if err != nil {
+		return ErrSSHCertificateInvalid{args: errx.Args{"keyID": cert.KeyId, "reason": err.Error()}}
+	}
+
+	if remoteAddr == nil {
+		return nil
+	}
+	sourceAddress, ok := cert.CriticalOptions[sshCertSourceAddressOption]
+	if !ok {
+		return nil
+	}
+	if !sshCertAllowsSourceAddress(sourceAddress, remoteAddr) {
+		return ErrSSHCertificateInvalid{args: errx.Args{"keyID": cert.KeyId, "reason": "source address not allowed", "remoteAddr": remoteAddr.String()}}
+	}
+	return nil
+}
+
+// sshCertAllowsSourceAddress returns true if the IP of given remote address is
+// listed in the comma-separated list of addresses and CIDR ranges.
+func sshCertAllowsSourceAddress(sourceAddress string, remoteAddr net.Addr) bool {
+	tcpAddr, ok := remoteAddr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+
+	for _, addr := range strings.Split(sourceAddress, ",") {
+		addr = strings.TrimSpace(addr)
+		if ip := net.ParseIP(addr); ip != nil {
+			if ip.Equal(tcpAddr.IP) {
+				return true
+			}
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(addr)
+		if err == nil && ipNet.Contains(tcpAddr.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// sshCertPrincipalUsername returns the Gogs username that the given certificate
+// principal is mapped to.
+func sshCertPrincipalUsername(principal string) string {
+	if username, ok := conf.SSH.CertificatePrincipals[principal]; ok {
+		return username
+	}
+	return principal
+}
+
+// GetUserBySSHCertificate returns the first active user that any principal of
+// the given certificate is mapped to, along with the matched principal. The
+// certificate itself should have been verified by the caller. It returns
+// ErrUserNotExist when no principal is mapped to an active user.
+func GetUserBySSHCertificate(ctx context.Context, cert *ssh.Certificate) (*User, string, error) {
+	for _, principal := range cert.ValidPrincipals {
+		user, err := Handle.Users().GetByUsername(ctx, sshCertPrincipalUsername(principal))
+		if err != nil {
+			if IsErrUserNotExist(err) {
+				continue
+			}
+			return nil, "", errors.Wrapf(err, "get user by principal %q", principal)
+		}
+
+		if user.IsOrganization() || !user.IsActive || user.ProhibitLogin {
+			continue
+		}
+		return user, principal, nil
+	}
+	return nil, "", ErrUserNotExist{args: errx.Args{"principals": cert.ValidPrincipals}}
+}
+
+// AuthorizedPrincipalsString returns the content to be printed by the
+// "AuthorizedPrincipalsCommand" of OpenSSH for the given user and principal.
+func AuthorizedPrincipalsString(userID int64, principal string) string {
+	return fmt.Sprintf(tplPrincipal, conf.AppPath(), userID, conf.CustomConf, principal)
+}
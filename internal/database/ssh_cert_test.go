@@ -0,0 +1,262 @@
+package database
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"gogs.io/gogs/internal/conf"
+)
+
+func newTestSSHSigner(t *testing.T) ssh.Signer {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(priv)
+	require.NoError(t, err)
+	return signer
+}
+
+func newTestSSHUserCertificate(t *testing.T, ca ssh.Signer, mutate func(cert *ssh.Certificate)) *ssh.Certificate {
+	cert := &ssh.Certificate{
+		Key:             newTestSSHSigner(t).PublicKey(),
+		CertType:        ssh.UserCert,
+		KeyId:           "alice@laptop",
+		ValidPrincipals: []string{"alice"},
+		ValidAfter:      uint64(time.Now().Add(-time.Hour).Unix()),
+		ValidBefore:     uint64(time.Now().Add(time.Hour).Unix()),
+	}
+	if mutate != nil {
+		mutate(cert)
+	}
+	require.NoError(t, cert.SignCert(rand.Reader, ca))
+	return cert
+}
+
+func TestLoadTrustedUserCAKeys(t *testing.T) {
+	keys, err := LoadTrustedUserCAKeys("")
+	require.NoError(t, err)
+	assert.Nil(t, keys)
+
+	ca1 := newTestSSHSigner(t)
+	ca2 := newTestSSHSigner(t)
+	path := filepath.Join(t.TempDir(), "ca.pub")
+	content := string(ssh.MarshalAuthorizedKey(ca1.PublicKey())) + "\n" + string(ssh.MarshalAuthorizedKey(ca2.PublicKey()))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	keys, err = LoadTrustedUserCAKeys(path)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, ca1.PublicKey().Marshal(), keys[0].Marshal())
+	assert.Equal(t, ca2.PublicKey().Marshal(), keys[1].Marshal())
+}
+
+func TestCheckSSHUserCertificate(t *testing.T) {
+	ca := newTestSSHSigner(t)
+	authorities := []ssh.PublicKey{ca.PublicKey()}
+	remoteAddr := &net.TCPAddr{IP: net.ParseIP("10.0.0.5"), Port: 22}
+
+	tests := []struct {
+		name    string
+		signer  ssh.Signer
+		mutate  func(cert *ssh.Certificate)
+		wantErr bool
+	}{
+		{
+			name: "valid",
+		},
+		{
+			name:    "untrusted authority",
+			signer:  newTestSSHSigner(t),
+			wantErr: true,
+		},
+		{
+			name: "expired",
+			mutate: func(cert *ssh.Certificate) {
+				cert.ValidBefore = uint64(time.Now().Add(-time.Minute).Unix())
+			},
+			wantErr: true,
+		},
+		{
+			name: "not yet valid",
+			mutate: func(cert *ssh.Certificate) {
+				cert.ValidAfter = uint64(time.Now().Add(time.Minute).Unix())
+			},
+			wantErr: true,
+		},
+		{
+			name: "host certificate",
+			mutate: func(cert *ssh.Certificate) {
+				cert.CertType = ssh.HostCert
+			},
+			wantErr: true,
+		},
+		{
+			name: "no principals",
+			mutate: func(cert *ssh.Certificate) {
+				cert.ValidPrincipals = nil
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported critical option",
+			mutate: func(cert *ssh.Certificate) {
+				cert.CriticalOptions = map[string]string{"force-command": "/bin/true"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "source address allowed",
+			mutate: func(cert *ssh.Certificate) {
+				cert.CriticalOptions = map[string]string{"source-address": "192.168.1.1,10.0.0.0/24"}
+			},
+		},
+		{
+			name: "source address not allowed",
+			mutate: func(cert *ssh.Certificate) {
+				cert.CriticalOptions = map[string]string{"source-address": "192.168.1.0/24"}
+			},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			signer := test.signer
+			if signer == nil {
+				signer = ca
+			}
+			cert := newTestSSHUserCertificate(t, signer, test.mutate)
+
+			err := CheckSSHUserCertificate(cert, authorities, remoteAddr)
+			if test.wantErr {
+				assert.True(t, IsErrSSHCertificateInvalid(err), "want ErrSSHCertificateInvalid but got %v", err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSSHCertPrincipalUsername(t *testing.T) {
+	conf.SetMockSSH(t, conf.SSHOpts{
+		CertificatePrincipals: map[string]string{
+			"alice@corp.example.com": "alice",
+			"root":                   "admin",
+		},
+	})
+
+	tests := []struct {
+		principal string
+		want      string
+	}{
+		{principal: "alice@corp.example.com", want: "alice"},
+		{principal: "root", want: "admin"},
+		{principal: "bob", want: "bob"},
+		{principal: "Alice@corp.example.com", want: "Alice@corp.example.com"},
+	}
+	for _, test := range tests {
+		t.Run(test.principal, func(t *testing.T) {
+			assert.Equal(t, test.want, sshCertPrincipalUsername(test.principal))
+		})
+	}
+}
+
+func TestGetUserBySSHCertificate(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	newLegacyTestDB(t, "TestGetUserBySSHCertificate")
+	conf.SetMockSSH(t, conf.SSHOpts{
+		CertificatePrincipals: map[string]string{
+			"alice@corp.example.com": "alice",
+			"org@corp.example.com":   "org",
+		},
+	})
+
+	createUser := func(t *testing.T, name string, update func(u *User)) *User {
+		t.Helper()
+
+		u := createLegacyTestUser(t, name)
+		u.IsActive = true
+		if update != nil {
+			update(u)
+		}
+		_, err := x.ID(u.ID).Cols("type", "is_active", "prohibit_login").Update(u)
+		require.NoError(t, err)
+		return u
+	}
+	alice := createUser(t, "alice", nil)
+	bob := createUser(t, "bob", nil)
+	createUser(t, "org", func(u *User) { u.Type = UserTypeOrganization })
+	createUser(t, "inactive", func(u *User) { u.IsActive = false })
+	createUser(t, "prohibited", func(u *User) { u.ProhibitLogin = true })
+
+	tests := []struct {
+		name          string
+		principals    []string
+		wantUserID    int64
+		wantPrincipal string
+	}{
+		{
+			name:          "mapped principal",
+			principals:    []string{"alice@corp.example.com"},
+			wantUserID:    alice.ID,
+			wantPrincipal: "alice@corp.example.com",
+		},
+		{
+			name:          "unmapped principal",
+			principals:    []string{"bob"},
+			wantUserID:    bob.ID,
+			wantPrincipal: "bob",
+		},
+		{
+			name:          "fall through to later principals",
+			principals:    []string{"nobody", "bob", "alice"},
+			wantUserID:    bob.ID,
+			wantPrincipal: "bob",
+		},
+		{
+			name:          "skip organization",
+			principals:    []string{"org@corp.example.com", "alice"},
+			wantUserID:    alice.ID,
+			wantPrincipal: "alice",
+		},
+		{
+			name:          "skip inactive user",
+			principals:    []string{"inactive", "bob"},
+			wantUserID:    bob.ID,
+			wantPrincipal: "bob",
+		},
+		{
+			name:          "skip login prohibited user",
+			principals:    []string{"prohibited", "alice@corp.example.com"},
+			wantUserID:    alice.ID,
+			wantPrincipal: "alice@corp.example.com",
+		},
+		{
+			name:       "no matched user",
+			principals: []string{"nobody", "org", "inactive", "prohibited"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cert := &ssh.Certificate{ValidPrincipals: test.principals}
+			user, principal, err := GetUserBySSHCertificate(t.Context(), cert)
+			if test.wantUserID == 0 {
+				assert.True(t, IsErrUserNotExist(err), "want ErrUserNotExist but got %v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.wantUserID, user.ID)
+			assert.Equal(t, test.wantPrincipal, principal)
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"gorm.io/gorm"
+
+	"gogs.io/gogs/internal/dbx"
+	"gogs.io/gogs/internal/errx"
+)
+
+// UserBlock is a relation of a user or an organization blocking another user.
+// A blocked user cannot follow the blocker, star, watch, open issues or pull
+// requests, comment or be added as a collaborator in repositories owned by the
+// blocker, nor notify the blocker by mentions.
+type UserBlock struct {
+	ID      int64 `gorm:"primaryKey"`
+	UserID  int64 `gorm:"uniqueIndex:user_block_user_block_unique;not null"`
+	BlockID int64 `gorm:"uniqueIndex:user_block_user_block_unique;index;not null"`
+
+	Created     time.Time `gorm:"-" json:"-"`
+	CreatedUnix int64
+}
+
+// BeforeCreate implements the GORM create hook.
+func (b *UserBlock) BeforeCreate(tx *gorm.DB) error {
+	if b.CreatedUnix == 0 {
+		b.CreatedUnix = tx.NowFunc().Unix()
+	}
+	return nil
+}
+
+// AfterFind implements the GORM query hook.
+func (b *UserBlock) AfterFind(_ *gorm.DB) error {
+	b.Created = time.Unix(b.CreatedUnix, 0).Local()
+	return nil
+}
+
+// UserBlocksStore is the storage layer for user blocks.
+type UserBlocksStore struct {
+	db *gorm.DB
+}
+
+func newUserBlocksStore(db *gorm.DB) *UserBlocksStore {
+	return &UserBlocksStore{db: db}
+}
+
+// Block marks the user to block the other user, and removes existing follows
+// between them in both directions.
+func (s *UserBlocksStore) Block(ctx context.Context, userID, blockID int64) error {
+	if userID == blockID {
+		return nil
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		b := &UserBlock{
+			UserID:  userID,
+			BlockID: blockID,
+		}
+		err := tx.FirstOrCreate(b, b).Error
+		if err != nil {
+			return errors.Wrap(err, "upsert")
+		}
+
+		err = tx.Where("(user_id = ? AND follow_id = ?) OR (user_id = ? AND follow_id = ?)", userID, blockID, blockID, userID).Delete(&Follow{}).Error
+		if err != nil {
+			return errors.Wrap(err, "delete follows")
+		}
+
+		users := newUsersStore(tx)
+		err = users.recountFollows(tx, userID, blockID)
+		if err != nil {
+			return errors.Wrap(err, "recount follows")
+		}
+		err = users.recountFollows(tx, blockID, userID)
+		if err != nil {
+			return errors.Wrap(err, "recount follows")
+		}
+		return nil
+	})
+}
+
+// Unblock removes the mark the user to block the other user.
+func (s *UserBlocksStore) Unblock(ctx context.Context, userID, blockID int64) error {
+	return s.db.WithContext(ctx).Where("user_id = ? AND block_id = ?", userID, blockID).Delete(&UserBlock{}).Error
+}
+
+// IsBlocked returns true if the user has blocked the other user.
+func (s *UserBlocksStore) IsBlocked(ctx context.Context, userID, blockID int64) bool {
+	if userID <= 0 || blockID <= 0 {
+		return false
+	}
+	return s.db.WithContext(ctx).Where("user_id = ? AND block_id = ?", userID, blockID).First(&UserBlock{}).Error == nil
+}
+
+// List returns the list of users blocked by the user, sorted by the time of
+// block in descending order.
+func (s *UserBlocksStore) List(ctx context.Context, userID int64) ([]*User, error) {
+	/*
+		Equivalent SQL for PostgreSQL:
+
+		SELECT * FROM "user"
+		LEFT JOIN user_block ON user_block.block_id = "user".id
+		WHERE user_block.user_id = @userID
+		ORDER BY user_block.id DESC
+	*/
+	users := make([]*User, 0, 5)
+	return users, s.db.WithContext(ctx).
+		Joins(dbx.Quote("LEFT JOIN user_block ON user_block.block_id = %s.id", "user")).
+		Where("user_block.user_id = ?", userID).
+		Order("user_block.id DESC").
+		Find(&users).
+		Error
+}
+
+// ListBlockers returns the list of users and organizations that have blocked
+// the user.
+func (s *UserBlocksStore) ListBlockers(ctx context.Context, userID int64) ([]*User, error) {
+	/*
+		Equivalent SQL for PostgreSQL:
+
+		SELECT * FROM "user"
+		LEFT JOIN user_block ON user_block.user_id = "user".id
+		WHERE user_block.block_id = @userID
+	*/
+	users := make([]*User, 0, 5)
+	return users, s.db.WithContext(ctx).
+		Joins(dbx.Quote("LEFT JOIN user_block ON user_block.user_id = %s.id", "user")).
+		Where("user_block.block_id = ?", userID).
+		Find(&users).
+		Error
+}
+
+// EnsureNotBlocked returns ErrBlockedByUser if the user has blocked the other
+// user.
+func (s *UserBlocksStore) EnsureNotBlocked(ctx context.Context, userID, blockID int64) error {
+	if s.IsBlocked(ctx, userID, blockID) {
+		return ErrBlockedByUser{
+			args: errx.Args{
+				"userID":  userID,
+				"blockID": blockID,
+			},
+		}
+	}
+	return nil
+}
+
+type ErrBlockedByUser struct {
+	args errx.Args
+}
+
+// IsErrBlockedByUser returns true if the underlying error has the type
+// ErrBlockedByUser.
+func IsErrBlockedByUser(err error) bool {
+	return errors.As(err, &ErrBlockedByUser{})
+}
+
+func (err ErrBlockedByUser) Error() string {
+	return fmt.Sprintf("blocked by user: %v", err.args)
+}
@@ -0,0 +1,155 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/errx"
+)
+
+func TestUserBlocks(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+
+	ctx := context.Background()
+	s := &UserBlocksStore{
+		db: newTestDB(t, "UserBlocksStore"),
+	}
+
+	for _, tc := range []struct {
+		name string
+		test func(t *testing.T, ctx context.Context, s *UserBlocksStore)
+	}{
+		{"Block", userBlocksBlock},
+		{"Unblock", userBlocksUnblock},
+		{"List", userBlocksList},
+		{"ListBlockers", userBlocksListBlockers},
+		{"EnsureNotBlocked", userBlocksEnsureNotBlocked},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Cleanup(func() {
+				err := clearTables(t, s.db)
+				require.NoError(t, err)
+			})
+			tc.test(t, ctx, s)
+		})
+		if t.Failed() {
+			break
+		}
+	}
+}
+
+func userBlocksBlock(t *testing.T, ctx context.Context, s *UserBlocksStore) {
+	usersStore := newUsersStore(s.db)
+	alice, err := usersStore.Create(ctx, "alice", "alice@example.com", CreateUserOptions{})
+	require.NoError(t, err)
+	bob, err := usersStore.Create(ctx, "bob", "bob@example.com", CreateUserOptions{})
+	require.NoError(t, err)
+
+	err = usersStore.Follow(ctx, alice.ID, bob.ID)
+	require.NoError(t, err)
+	err = usersStore.Follow(ctx, bob.ID, alice.ID)
+	require.NoError(t, err)
+
+	err = s.Block(ctx, alice.ID, bob.ID)
+	require.NoError(t, err)
+
+	// It is OK to block multiple times and just be noop.
+	err = s.Block(ctx, alice.ID, bob.ID)
+	require.NoError(t, err)
+
+	assert.True(t, s.IsBlocked(ctx, alice.ID, bob.ID))
+	assert.False(t, s.IsBlocked(ctx, bob.ID, alice.ID))
+
+	// Follows in both directions should be removed.
+	assert.False(t, usersStore.IsFollowing(ctx, alice.ID, bob.ID))
+	assert.False(t, usersStore.IsFollowing(ctx, bob.ID, alice.ID))
+
+	alice, err = usersStore.GetByID(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, alice.NumFollowers)
+	assert.Equal(t, 0, alice.NumFollowing)
+
+	bob, err = usersStore.GetByID(ctx, bob.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, bob.NumFollowers)
+	assert.Equal(t, 0, bob.NumFollowing)
+}
+
+func userBlocksUnblock(t *testing.T, ctx context.Context, s *UserBlocksStore) {
+	err := s.Block(ctx, 1, 2)
+	require.NoError(t, err)
+
+	err = s.Unblock(ctx, 1, 2)
+	require.NoError(t, err)
+	assert.False(t, s.IsBlocked(ctx, 1, 2))
+
+	// It is OK to unblock multiple times and just be noop.
+	err = s.Unblock(ctx, 1, 2)
+	require.NoError(t, err)
+}
+
+func userBlocksList(t *testing.T, ctx context.Context, s *UserBlocksStore) {
+	usersStore := newUsersStore(s.db)
+	alice, err := usersStore.Create(ctx, "alice", "alice@example.com", CreateUserOptions{})
+	require.NoError(t, err)
+	bob, err := usersStore.Create(ctx, "bob", "bob@example.com", CreateUserOptions{})
+	require.NoError(t, err)
+	cindy, err := usersStore.Create(ctx, "cindy", "cindy@example.com", CreateUserOptions{})
+	require.NoError(t, err)
+
+	err = s.Block(ctx, alice.ID, bob.ID)
+	require.NoError(t, err)
+	err = s.Block(ctx, alice.ID, cindy.ID)
+	require.NoError(t, err)
+
+	got, err := s.List(ctx, alice.ID)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, cindy.ID, got[0].ID)
+	assert.Equal(t, bob.ID, got[1].ID)
+
+	got, err = s.List(ctx, bob.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func userBlocksListBlockers(t *testing.T, ctx context.Context, s *UserBlocksStore) {
+	usersStore := newUsersStore(s.db)
+	alice, err := usersStore.Create(ctx, "alice", "alice@example.com", CreateUserOptions{})
+	require.NoError(t, err)
+	bob, err := usersStore.Create(ctx, "bob", "bob@example.com", CreateUserOptions{})
+	require.NoError(t, err)
+
+	err = s.Block(ctx, alice.ID, bob.ID)
+	require.NoError(t, err)
+
+	got, err := s.ListBlockers(ctx, bob.ID)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, alice.ID, got[0].ID)
+
+	got, err = s.ListBlockers(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func userBlocksEnsureNotBlocked(t *testing.T, ctx context.Context, s *UserBlocksStore) {
+	err := s.EnsureNotBlocked(ctx, 1, 2)
+	require.NoError(t, err)
+
+	err = s.Block(ctx, 1, 2)
+	require.NoError(t, err)
+
+	err = s.EnsureNotBlocked(ctx, 1, 2)
+	wantErr := ErrBlockedByUser{args: errx.Args{"userID": int64(1), "blockID": int64(2)}}
+	assert.Equal(t, wantErr, err)
+
+	err = s.EnsureNotBlocked(ctx, 2, 1)
+	require.NoError(t, err)
+}
@@ -5,11 +5,13 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 	"unicode/utf8"
 
 	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 	log "unknwon.dev/clog/v2"
 
@@ -503,9 +505,22 @@ func (s *UsersStore) DeleteByID(ctx context.Context, userID int64, skipRewriteAu
 			needsRewriteAuthorizedKeys = tx.Where("owner_id = ?", userID).First(&PublicKey{}).Error != gorm.ErrRecordNotFound
 		}
 
-		err = tx.Model(&Issue{}).Where("assignee_id = ?", userID).Update("assignee_id", 0).Error
+		// Keep the next remaining assignee in issues assigned to the user, the same
+		// as removing the user from assignees.
+		err = tx.Model(&Issue{}).
+			Where("assignee_id = ?", userID).
+			Update("assignee_id", gorm.Expr(
+				"COALESCE((SELECT issue_assignee.assignee_id FROM issue_assignee WHERE issue_assignee.issue_id = issue.id AND issue_assignee.assignee_id != ? ORDER BY issue_assignee.id LIMIT 1), 0)",
+				userID,
+			)).
+			Error
+		if err != nil {
+			return errors.Wrap(err, "update assignees")
+		}
+
+		err = deleteOAuth2Grants(tx, "user_id = ? OR application_id IN (?)", userID, tx.Model(&OAuth2Application{}).Select("id").Where("user_id = ?", userID))
 		if err != nil {
-			return errors.Wrap(err, "clear assignees")
+			return errors.Wrap(err, "delete OAuth2 grants")
 		}
 
 		for _, t := range []struct {
@@ -515,13 +530,20 @@ func (s *UsersStore) DeleteByID(ctx context.Context, userID int64, skipRewriteAu
 			{&Watch{}, "user_id = @userID"},
 			{&Star{}, "uid = @userID"},
 			{&Follow{}, "user_id = @userID OR follow_id = @userID"},
+			{&UserBlock{}, "user_id = @userID OR block_id = @userID"},
 			{&PublicKey{}, "owner_id = @userID"},
+			{&GPGKey{}, "owner_id = @userID"},
+			{&OAuth2Application{}, "user_id = @userID"},
 
 			{&AccessToken{}, "uid = @userID"},
 			{&Collaboration{}, "user_id = @userID"},
 			{&Access{}, "user_id = @userID"},
 			{&Action{}, "user_id = @userID"},
 			{&IssueUser{}, "uid = @userID"},
+			{&IssueAssignee{}, "assignee_id = @userID"},
+			{&ReviewRequest{}, "reviewer_id = @userID"},
+			{&CodeOwnerSignOff{}, "user_id = @userID"},
+			{&Stopwatch{}, "user_id = @userID"},
 			{&EmailAddress{}, "uid = @userID"},
 			{&User{}, "id = @userID"},
 		} {
@@ -619,12 +641,18 @@ func (*UsersStore) recountFollows(tx *gorm.DB, userID, followID int64) error {
 	return nil
 }
 
-// Follow marks the user to follow the other user.
+// Follow marks the user to follow the other user. It returns
+// ErrBlockedByUser if the other user has blocked the user.
 func (s *UsersStore) Follow(ctx context.Context, userID, followID int64) error {
 	if userID == followID {
 		return nil
 	}
 
+	err := newUserBlocksStore(s.db).EnsureNotBlocked(ctx, followID, userID)
+	if err != nil {
+		return err
+	}
+
 	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		f := &Follow{
 			UserID:   userID,
@@ -749,6 +777,26 @@ func (s *UsersStore) GetByUsername(ctx context.Context, username string) (*User,
 	return user, nil
 }
 
+// GetByFeedToken returns the user with given feed token. It returns
+// ErrUserNotExist when not found. The token is never included in the error as
+// it is a credential.
+func (s *UsersStore) GetByFeedToken(ctx context.Context, token string) (*User, error) {
+	// Users without a feed token should never be matched.
+	if token == "" {
+		return nil, ErrUserNotExist{args: errx.Args{}}
+	}
+
+	user := new(User)
+	err := s.db.WithContext(ctx).Where("feed_token_sha256 = ?", cryptox.SHA256(token)).First(user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotExist{args: errx.Args{}}
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
 // GetByKeyID returns the owner of given public key ID. It returns
 // ErrUserNotExist when not found.
 func (s *UsersStore) GetByKeyID(ctx context.Context, keyID int64) (*User, error) {
@@ -879,6 +927,132 @@ func (s *UsersStore) SearchByName(ctx context.Context, keyword string, page, pag
 	return searchUserByName(ctx, s.db, UserTypeIndividual, keyword, page, pageSize, orderBy)
 }
 
+// UserField is a field of users that can be compared in a UserComparison.
+type UserField string
+
+const (
+	UserFieldID       UserField = "id"
+	UserFieldUsername UserField = "username"
+	UserFieldFullName UserField = "full_name"
+	// The full name, or the username when the full name is empty.
+	UserFieldDisplayName UserField = "display_name"
+	UserFieldEmail       UserField = "email"
+	// Whether the user is allowed to sign in, compared as "true" or "false".
+	UserFieldActive UserField = "active"
+)
+
+// UserComparison is a case-insensitive comparison on a field of users. An
+// empty full name is treated as absent.
+type UserComparison struct {
+	Field UserField
+	// One of "eq" (equal), "ne" (not equal), "co" (contains), "sw" (starts
+	// with), "ew" (ends with) and "pr" (present). The ID and active fields only
+	// support "eq", "ne" and "pr".
+	Op    string
+	Value string
+}
+
+// where returns the SQL condition and its arguments of the comparison.
+func (cmp UserComparison) where() (string, []any, error) {
+	if cmp.Op == "ne" {
+		cmp.Op = "eq"
+		query, args, err := cmp.where()
+		return "NOT (" + query + ")", args, err
+	}
+
+	switch cmp.Field {
+	case UserFieldID, UserFieldActive:
+		switch cmp.Op {
+		case "eq":
+		case "pr":
+			return "1 = 1", nil, nil
+		default:
+			return "", nil, errors.Newf("unsupported operator %q on field %q", cmp.Op, cmp.Field)
+		}
+
+		if cmp.Field == UserFieldID {
+			id, err := strconv.ParseInt(cmp.Value, 10, 64)
+			if err != nil {
+				return "1 = 0", nil, nil
+			}
+			return "id = ?", []any{id}, nil
+		}
+		active, err := strconv.ParseBool(strings.ToLower(cmp.Value))
+		if err != nil {
+			return "1 = 0", nil, nil
+		}
+		return "prohibit_login = ?", []any{!active}, nil
+	}
+
+	var column string
+	switch cmp.Field {
+	case UserFieldUsername:
+		column = "lower_name"
+	case UserFieldFullName:
+		column = "LOWER(full_name)"
+	case UserFieldDisplayName:
+		column = "LOWER(CASE WHEN full_name <> '' THEN full_name ELSE name END)"
+	case UserFieldEmail:
+		column = "LOWER(email)"
+	default:
+		return "", nil, errors.Newf("unsupported field %q", cmp.Field)
+	}
+
+	value := strings.ToLower(cmp.Value)
+	var query string
+	var args []any
+	switch cmp.Op {
+	case "eq":
+		query, args = column+" = ?", []any{value}
+	case "co":
+		query, args = column+" LIKE ?", []any{"%" + value + "%"}
+	case "sw":
+		query, args = column+" LIKE ?", []any{value + "%"}
+	case "ew":
+		query, args = column+" LIKE ?", []any{"%" + value}
+	case "pr":
+		query = column + " <> ''"
+	default:
+		return "", nil, errors.Newf("unsupported operator %q", cmp.Op)
+	}
+	if cmp.Field == UserFieldFullName {
+		query = "full_name <> '' AND " + query
+	}
+	return query, args, nil
+}
+
+type SearchUsersOptions struct {
+	// Comparisons that all must match.
+	Comparisons []UserComparison
+	// The number of matched users to skip.
+	Offset int
+	// The maximum number of users to return.
+	Limit int
+}
+
+// Search returns a list of individual users that match all comparisons of the
+// given options, sorted by ID in ascending order. A total count of all matched
+// users is also returned.
+func (s *UsersStore) Search(ctx context.Context, opts SearchUsersOptions) ([]*User, int64, error) {
+	tx := s.db.WithContext(ctx).Where("type = ?", UserTypeIndividual)
+	for _, cmp := range opts.Comparisons {
+		query, args, err := cmp.where()
+		if err != nil {
+			return nil, 0, err
+		}
+		tx = tx.Where(query, args...)
+	}
+
+	var count int64
+	err := tx.Model(&User{}).Count(&count).Error
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "count")
+	}
+
+	users := make([]*User, 0, opts.Limit)
+	return users, count, tx.Order("id ASC").Limit(opts.Limit).Offset(opts.Offset).Find(&users).Error
+}
+
 type UpdateUserOptions struct {
 	LoginSource *int64
 	LoginName   *string
@@ -994,6 +1168,25 @@ func (s *UsersStore) Update(ctx context.Context, userID int64, opts UpdateUserOp
 	return s.db.WithContext(ctx).Model(&User{}).Where("id = ?", userID).Updates(updates).Error
 }
 
+// RegenerateFeedToken generates and saves a new feed token for the user, the
+// previous token becomes invalid immediately. Only the SHA256 of the token is
+// saved, thus the returned token cannot be retrieved again.
+func (s *UsersStore) RegenerateFeedToken(ctx context.Context, userID int64) (string, error) {
+	token := cryptox.SHA1(uuid.New().String())
+	err := s.db.WithContext(ctx).
+		Model(&User{}).
+		Where("id = ?", userID).
+		Updates(map[string]any{
+			"feed_token_sha256": cryptox.SHA256(token),
+			"updated_unix":      s.db.NowFunc().Unix(),
+		}).
+		Error
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
 // UseCustomAvatar uses the given avatar as the user custom avatar.
 func (s *UsersStore) UseCustomAvatar(ctx context.Context, userID int64, avatar []byte) error {
 	err := userx.SaveAvatar(userID, avatar)
@@ -1226,6 +1419,9 @@ type User struct {
 	Website     string
 	Rands       string `xorm:"VARCHAR(10)" gorm:"type:VARCHAR(10)"`
 	Salt        string `xorm:"VARCHAR(10)" gorm:"type:VARCHAR(10)"`
+	// FeedTokenSHA256 is the SHA256 of the token that authenticates Atom and
+	// RSS feeds of private activity.
+	FeedTokenSHA256 string `xorm:"feed_token_sha256 VARCHAR(64) INDEX" gorm:"column:feed_token_sha256;type:VARCHAR(64);index"`
 
 	Created     time.Time `xorm:"-" gorm:"-" json:"-"`
 	CreatedUnix int64
@@ -1488,7 +1684,7 @@ var (
 		".":        {},
 		"..":       {},
 	}
-	reservedUsernamePatterns = []string{"*.keys"}
+	reservedUsernamePatterns = []string{"*.keys", "*.atom", "*.rss"}
 )
 
 type ErrNameNotAllowed struct {
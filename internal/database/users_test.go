@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -15,6 +16,7 @@ import (
 
 	"gogs.io/gogs/internal/auth"
 	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/cryptox"
 	"gogs.io/gogs/internal/dbx"
 	"gogs.io/gogs/internal/errx"
 	"gogs.io/gogs/internal/osx"
@@ -96,6 +98,7 @@ func TestUsers(t *testing.T) {
 		{"DeleteByID", usersDeleteByID},
 		{"DeleteInactivated", usersDeleteInactivated},
 		{"GetByEmail", usersGetByEmail},
+		{"GetByFeedToken", usersGetByFeedToken},
 		{"GetByID", usersGetByID},
 		{"GetByUsername", usersGetByUsername},
 		{"GetByKeyID", usersGetByKeyID},
@@ -104,6 +107,8 @@ func TestUsers(t *testing.T) {
 		{"List", usersList},
 		{"ListFollowers", usersListFollowers},
 		{"ListFollowings", usersListFollowings},
+		{"RegenerateFeedToken", usersRegenerateFeedToken},
+		{"Search", usersSearch},
 		{"SearchByName", usersSearchByName},
 		{"Update", usersUpdate},
 		{"UseCustomAvatar", usersUseCustomAvatar},
@@ -547,11 +552,19 @@ func usersDeleteByID(t *testing.T, ctx context.Context, s *UsersStore) {
 
 	// Mock random entries in related tables
 	for _, table := range []any{
+		&GPGKey{OwnerID: testUser.ID, KeyID: "3AA5C34371567BD2", Fingerprint: "4AEE18F83AFDEB23C6F6F2C33AA5C34371567BD2"},
+		&OAuth2Application{UserID: testUser.ID, ClientID: "test-client-id"},
+		&OAuth2Grant{UserID: testUser.ID, ApplicationID: 1},
+		&UserBlock{UserID: testUser.ID, BlockID: cindy.ID},
 		&AccessToken{UserID: testUser.ID},
 		&Collaboration{UserID: testUser.ID},
 		&Access{UserID: testUser.ID},
 		&Action{UserID: testUser.ID},
 		&IssueUser{UserID: testUser.ID},
+		&IssueAssignee{IssueID: issue.ID, AssigneeID: testUser.ID},
+		&ReviewRequest{IssueID: issue.ID, ReviewerID: testUser.ID},
+		&CodeOwnerSignOff{IssueID: issue.ID, UserID: testUser.ID},
+		&Stopwatch{IssueID: issue.ID, UserID: testUser.ID},
 		&EmailAddress{UserID: testUser.ID},
 	} {
 		err = s.db.Create(table).Error
@@ -602,11 +615,19 @@ func usersDeleteByID(t *testing.T, ctx context.Context, s *UsersStore) {
 		&Star{UserID: testUser.ID},
 		&Follow{UserID: testUser.ID},
 		&PublicKey{OwnerID: testUser.ID},
+		&GPGKey{OwnerID: testUser.ID},
+		&OAuth2Application{UserID: testUser.ID},
+		&OAuth2Grant{UserID: testUser.ID},
+		&UserBlock{UserID: testUser.ID},
 		&AccessToken{UserID: testUser.ID},
 		&Collaboration{UserID: testUser.ID},
 		&Access{UserID: testUser.ID},
 		&Action{UserID: testUser.ID},
 		&IssueUser{UserID: testUser.ID},
+		&IssueAssignee{IssueID: issue.ID, AssigneeID: testUser.ID},
+		&ReviewRequest{IssueID: issue.ID, ReviewerID: testUser.ID},
+		&CodeOwnerSignOff{IssueID: issue.ID, UserID: testUser.ID},
+		&Stopwatch{IssueID: issue.ID, UserID: testUser.ID},
 		&EmailAddress{UserID: testUser.ID},
 	}
 	for _, table := range relatedTables {
@@ -650,11 +671,19 @@ func usersDeleteByID(t *testing.T, ctx context.Context, s *UsersStore) {
 		&Star{UserID: testUser.ID},
 		&Follow{UserID: testUser.ID},
 		&PublicKey{OwnerID: testUser.ID},
+		&GPGKey{OwnerID: testUser.ID},
+		&OAuth2Application{UserID: testUser.ID},
+		&OAuth2Grant{UserID: testUser.ID},
+		&UserBlock{UserID: testUser.ID},
 		&AccessToken{UserID: testUser.ID},
 		&Collaboration{UserID: testUser.ID},
 		&Access{UserID: testUser.ID},
 		&Action{UserID: testUser.ID},
 		&IssueUser{UserID: testUser.ID},
+		&IssueAssignee{IssueID: issue.ID, AssigneeID: testUser.ID},
+		&ReviewRequest{IssueID: issue.ID, ReviewerID: testUser.ID},
+		&CodeOwnerSignOff{IssueID: issue.ID, UserID: testUser.ID},
+		&Stopwatch{IssueID: issue.ID, UserID: testUser.ID},
 		&EmailAddress{UserID: testUser.ID},
 	} {
 		var count int64
@@ -804,6 +833,34 @@ func usersGetByUsername(t *testing.T, ctx context.Context, s *UsersStore) {
 	assert.Equal(t, wantErr, err)
 }
 
+func usersGetByFeedToken(t *testing.T, ctx context.Context, s *UsersStore) {
+	alice, err := s.Create(ctx, "alice", "alice@example.com", CreateUserOptions{})
+	require.NoError(t, err)
+
+	// Users without a feed token should never be matched
+	_, err = s.GetByFeedToken(ctx, "")
+	wantErr := ErrUserNotExist{args: errx.Args{}}
+	assert.Equal(t, wantErr, err)
+
+	token, err := s.RegenerateFeedToken(ctx, alice.ID)
+	require.NoError(t, err)
+
+	user, err := s.GetByFeedToken(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, alice.Name, user.Name)
+
+	// The token is a credential and should not be leaked through errors
+	_, err = s.GetByFeedToken(ctx, "bad_token")
+	assert.Equal(t, wantErr, err)
+	assert.NotContains(t, err.Error(), "bad_token")
+
+	// Only the SHA256 of the token is saved, which does not work as a token
+	alice, err = s.GetByID(ctx, alice.ID)
+	require.NoError(t, err)
+	_, err = s.GetByFeedToken(ctx, alice.FeedTokenSHA256)
+	assert.True(t, IsErrUserNotExist(err))
+}
+
 func usersGetByKeyID(t *testing.T, ctx context.Context, s *UsersStore) {
 	alice, err := s.Create(ctx, "alice", "alice@example.com", CreateUserOptions{})
 	require.NoError(t, err)
@@ -988,6 +1045,160 @@ func usersListFollowings(t *testing.T, ctx context.Context, s *UsersStore) {
 	assert.Equal(t, alice.ID, got[0].ID)
 }
 
+func usersRegenerateFeedToken(t *testing.T, ctx context.Context, s *UsersStore) {
+	alice, err := s.Create(ctx, "alice", "alice@example.com", CreateUserOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, alice.FeedTokenSHA256)
+
+	token1, err := s.RegenerateFeedToken(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Len(t, token1, 40)
+
+	token2, err := s.RegenerateFeedToken(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, token1, token2)
+
+	// The previous token should no longer work
+	_, err = s.GetByFeedToken(ctx, token1)
+	assert.True(t, IsErrUserNotExist(err))
+
+	alice, err = s.GetByID(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, cryptox.SHA256(token2), alice.FeedTokenSHA256)
+}
+
+func usersSearch(t *testing.T, ctx context.Context, s *UsersStore) {
+	alice, err := s.Create(ctx, "alice", "alice@example.com", CreateUserOptions{FullName: "Alice Jordan"})
+	require.NoError(t, err)
+	bob, err := s.Create(ctx, "bob", "bob@example.com", CreateUserOptions{FullName: "Bob Jordan"})
+	require.NoError(t, err)
+	cindy, err := s.Create(ctx, "cindy", "cindy@example.org", CreateUserOptions{})
+	require.NoError(t, err)
+	prohibitLogin := true
+	err = s.Update(ctx, bob.ID, UpdateUserOptions{ProhibitLogin: &prohibitLogin})
+	require.NoError(t, err)
+
+	// Create an organization shouldn't count
+	// TODO: Use Orgs.Create to replace SQL hack when the method is available.
+	org1, err := s.Create(ctx, "org1", "org1@example.com", CreateUserOptions{})
+	require.NoError(t, err)
+	err = s.db.Exec(
+		dbx.Quote("UPDATE %s SET type = ? WHERE id = ?", "user"),
+		UserTypeOrganization, org1.ID,
+	).Error
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		comparisons []UserComparison
+		offset      int
+		limit       int
+		wantIDs     []int64
+		wantCount   int64
+	}{
+		{
+			name:      "all",
+			limit:     10,
+			wantIDs:   []int64{alice.ID, bob.ID, cindy.ID},
+			wantCount: 3,
+		},
+		{
+			name:      "paginated",
+			offset:    1,
+			limit:     1,
+			wantIDs:   []int64{bob.ID},
+			wantCount: 3,
+		},
+		{
+			name:      "count only",
+			wantIDs:   []int64{},
+			wantCount: 3,
+		},
+		{
+			name:        "username",
+			comparisons: []UserComparison{{Field: UserFieldUsername, Op: "eq", Value: "Alice"}},
+			limit:       10,
+			wantIDs:     []int64{alice.ID},
+			wantCount:   1,
+		},
+		{
+			name:        "not username",
+			comparisons: []UserComparison{{Field: UserFieldUsername, Op: "ne", Value: "alice"}},
+			limit:       10,
+			wantIDs:     []int64{bob.ID, cindy.ID},
+			wantCount:   2,
+		},
+		{
+			name:        "ID",
+			comparisons: []UserComparison{{Field: UserFieldID, Op: "eq", Value: strconv.FormatInt(cindy.ID, 10)}},
+			limit:       10,
+			wantIDs:     []int64{cindy.ID},
+			wantCount:   1,
+		},
+		{
+			name:        "invalid ID",
+			comparisons: []UserComparison{{Field: UserFieldID, Op: "eq", Value: "alice"}},
+			limit:       10,
+			wantIDs:     []int64{},
+			wantCount:   0,
+		},
+		{
+			name:        "full name present",
+			comparisons: []UserComparison{{Field: UserFieldFullName, Op: "pr"}},
+			limit:       10,
+			wantIDs:     []int64{alice.ID, bob.ID},
+			wantCount:   2,
+		},
+		{
+			name: "display name",
+			comparisons: []UserComparison{
+				{Field: UserFieldDisplayName, Op: "sw", Value: "CIN"},
+			},
+			limit:     10,
+			wantIDs:   []int64{cindy.ID},
+			wantCount: 1,
+		},
+		{
+			name: "email and active",
+			comparisons: []UserComparison{
+				{Field: UserFieldEmail, Op: "ew", Value: "@Example.com"},
+				{Field: UserFieldActive, Op: "eq", Value: "True"},
+			},
+			limit:     10,
+			wantIDs:   []int64{alice.ID},
+			wantCount: 1,
+		},
+		{
+			name:        "full name contains",
+			comparisons: []UserComparison{{Field: UserFieldFullName, Op: "co", Value: "jordan"}},
+			offset:      1,
+			limit:       10,
+			wantIDs:     []int64{bob.ID},
+			wantCount:   2,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			users, count, err := s.Search(ctx, SearchUsersOptions{
+				Comparisons: test.comparisons,
+				Offset:      test.offset,
+				Limit:       test.limit,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, test.wantCount, count)
+
+			ids := make([]int64, 0, len(users))
+			for _, u := range users {
+				ids = append(ids, u.ID)
+			}
+			assert.Equal(t, test.wantIDs, ids)
+		})
+	}
+
+	_, _, err = s.Search(ctx, SearchUsersOptions{Comparisons: []UserComparison{{Field: UserFieldActive, Op: "co", Value: "t"}}})
+	assert.Error(t, err)
+}
+
 func usersSearchByName(t *testing.T, ctx context.Context, s *UsersStore) {
 	alice, err := s.Create(ctx, "alice", "alice@example.com", CreateUserOptions{FullName: "Alice Jordan"})
 	require.NoError(t, err)
@@ -1343,6 +1554,12 @@ func usersFollow(t *testing.T, ctx context.Context, s *UsersStore) {
 	bob, err = usersStore.GetByID(ctx, bob.ID)
 	require.NoError(t, err)
 	assert.Equal(t, 1, bob.NumFollowers)
+
+	// Blocked users are not allowed to follow.
+	err = newUserBlocksStore(s.db).Block(ctx, alice.ID, bob.ID)
+	require.NoError(t, err)
+	err = s.Follow(ctx, bob.ID, alice.ID)
+	assert.True(t, IsErrBlockedByUser(err))
 }
 
 func usersIsFollowing(t *testing.T, ctx context.Context, s *UsersStore) {
@@ -160,9 +160,11 @@ func getDingtalkIssuesPayload(p *apiv1types.WebhookIssuesPayload) *DingtalkPaylo
 
 	switch p.Action {
 	case apiv1types.WebhookIssueAssigned:
-		actionCard.Text += "\n- New Assignee: **" + p.Issue.Assignee.UserName + "**"
+		actionCard.Text += "\n- New Assignee: **" + p.Assignee.UserName + "**"
 	case apiv1types.WebhookIssueMilestoned:
 		actionCard.Text += "\n- New Milestone: **" + p.Issue.Milestone.Title + "**"
+	case apiv1types.WebhookIssueReacted, apiv1types.WebhookIssueUnreacted:
+		actionCard.Text += "\n- Reaction: **" + p.Reaction.Content + "**"
 	case apiv1types.WebhookIssueLabelUpdated:
 		if len(p.Issue.Labels) > 0 {
 			labels := make([]string, len(p.Issue.Labels))
@@ -197,6 +199,9 @@ func getDingtalkIssueCommentPayload(p *apiv1types.WebhookIssueCommentPayload) *D
 	actionCard := NewDingtalkActionCard("View Issue Comment", commentURL)
 	actionCard.Text += "# Issue Comment " + strings.Title(string(p.Action))
 	actionCard.Text += "\n- Issue: " + MarkdownLinkFormatter(issueURL, issueName)
+	if p.Reaction != nil {
+		actionCard.Text += "\n- Reaction: **" + p.Reaction.Content + "**"
+	}
 	actionCard.Text += "\n- Comment content: "
 	actionCard.Text += "\n> " + p.Comment.Body
 
@@ -217,7 +222,11 @@ func getDingtalkPullRequestPayload(p *apiv1types.WebhookPullRequestPayload) *Din
 	content := "- PR: " + MarkdownLinkFormatter(pullRequestURL, fmt.Sprintf("#%d %s", p.Index, p.PullRequest.Title))
 	switch p.Action {
 	case apiv1types.WebhookIssueAssigned:
-		content += "\n- New Assignee: **" + p.PullRequest.Assignee.UserName + "**"
+		content += "\n- New Assignee: **" + p.Assignee.UserName + "**"
+	case apiv1types.WebhookIssueReviewRequested, apiv1types.WebhookIssueReviewRequestRemoved:
+		content += "\n- Reviewer: **" + reviewRequestTarget(p) + "**"
+	case apiv1types.WebhookIssueReacted, apiv1types.WebhookIssueUnreacted:
+		content += "\n- Reaction: **" + p.Reaction.Content + "**"
 	case apiv1types.WebhookIssueMilestoned:
 		content += "\n- New Milestone: *" + p.PullRequest.Milestone.Title + "*"
 	case apiv1types.WebhookIssueLabelUpdated:
@@ -187,7 +187,7 @@ func getDiscordIssuesPayload(p *apiv1types.WebhookIssuesPayload, slack *SlackMet
 		title = "Issue assigned: " + title
 		fields = []*DiscordEmbedFieldObject{{
 			Name:  "New Assignee",
-			Value: p.Issue.Assignee.UserName,
+			Value: p.Assignee.UserName,
 		}}
 	case apiv1types.WebhookIssueUnassigned:
 		title = "Issue unassigned: " + title
@@ -216,6 +216,18 @@ func getDiscordIssuesPayload(p *apiv1types.WebhookIssuesPayload, slack *SlackMet
 		}}
 	case apiv1types.WebhookIssueDemilestoned:
 		title = "Issue demilestoned: " + title
+	case apiv1types.WebhookIssueReacted:
+		title = "Issue reacted: " + title
+		fields = []*DiscordEmbedFieldObject{{
+			Name:  "Reaction",
+			Value: ":" + p.Reaction.Content + ":",
+		}}
+	case apiv1types.WebhookIssueUnreacted:
+		title = "Issue reaction removed: " + title
+		fields = []*DiscordEmbedFieldObject{{
+			Name:  "Reaction",
+			Value: ":" + p.Reaction.Content + ":",
+		}}
 	}
 
 	color, _ := strconv.ParseInt(strings.TrimLeft(slack.Color, "#"), 16, 32)
@@ -251,6 +263,18 @@ func getDiscordIssueCommentPayload(p *apiv1types.WebhookIssueCommentPayload, sla
 	case apiv1types.WebhookIssueCommentEdited:
 		title = "Comment edited: " + title
 		content = p.Comment.Body
+	case apiv1types.WebhookIssueCommentReacted:
+		title = "Comment reacted: " + title
+		fields = []*DiscordEmbedFieldObject{{
+			Name:  "Reaction",
+			Value: ":" + p.Reaction.Content + ":",
+		}}
+	case apiv1types.WebhookIssueCommentUnreacted:
+		title = "Comment reaction removed: " + title
+		fields = []*DiscordEmbedFieldObject{{
+			Name:  "Reaction",
+			Value: ":" + p.Reaction.Content + ":",
+		}}
 	case apiv1types.WebhookIssueCommentDeleted:
 		title = "Comment deleted: " + title
 		url = fmt.Sprintf("%s/issues/%d", p.Repository.HTMLURL, p.Issue.Index)
@@ -302,10 +326,22 @@ func getDiscordPullRequestPayload(p *apiv1types.WebhookPullRequestPayload, slack
 		title = "Pull request assigned: " + title
 		fields = []*DiscordEmbedFieldObject{{
 			Name:  "New Assignee",
-			Value: p.PullRequest.Assignee.UserName,
+			Value: p.Assignee.UserName,
 		}}
 	case apiv1types.WebhookIssueUnassigned:
 		title = "Pull request unassigned: " + title
+	case apiv1types.WebhookIssueReviewRequested:
+		title = "Pull request review requested: " + title
+		fields = []*DiscordEmbedFieldObject{{
+			Name:  "Reviewer",
+			Value: reviewRequestTarget(p),
+		}}
+	case apiv1types.WebhookIssueReviewRequestRemoved:
+		title = "Pull request review request removed: " + title
+		fields = []*DiscordEmbedFieldObject{{
+			Name:  "Reviewer",
+			Value: reviewRequestTarget(p),
+		}}
 	case apiv1types.WebhookIssueLabelUpdated:
 		title = "Pull request labels updated: " + title
 		labels := make([]string, len(p.PullRequest.Labels))
@@ -328,6 +364,18 @@ func getDiscordPullRequestPayload(p *apiv1types.WebhookPullRequestPayload, slack
 		}}
 	case apiv1types.WebhookIssueDemilestoned:
 		title = "Pull request demilestoned: " + title
+	case apiv1types.WebhookIssueReacted:
+		title = "Pull request reacted: " + title
+		fields = []*DiscordEmbedFieldObject{{
+			Name:  "Reaction",
+			Value: ":" + p.Reaction.Content + ":",
+		}}
+	case apiv1types.WebhookIssueUnreacted:
+		title = "Pull request reaction removed: " + title
+		fields = []*DiscordEmbedFieldObject{{
+			Name:  "Reaction",
+			Value: ":" + p.Reaction.Content + ":",
+		}}
 	}
 
 	color, _ := strconv.ParseInt(strings.TrimLeft(slack.Color, "#"), 16, 32)
@@ -161,7 +161,7 @@ func getSlackIssuesPayload(p *apiv1types.WebhookIssuesPayload, slack *SlackMeta)
 		attachmentText = SlackTextFormatter(p.Issue.Body)
 	case apiv1types.WebhookIssueAssigned:
 		text = fmt.Sprintf("[%s] Issue assigned to %s: %s by %s", p.Repository.FullName,
-			SlackLinkFormatter(conf.Server.ExternalURL+p.Issue.Assignee.UserName, p.Issue.Assignee.UserName),
+			SlackLinkFormatter(conf.Server.ExternalURL+p.Assignee.UserName, p.Assignee.UserName),
 			titleLink, senderLink)
 	case apiv1types.WebhookIssueUnassigned:
 		text = fmt.Sprintf("[%s] Issue unassigned: %s by %s", p.Repository.FullName, titleLink, senderLink)
@@ -173,6 +173,10 @@ func getSlackIssuesPayload(p *apiv1types.WebhookIssuesPayload, slack *SlackMeta)
 		text = fmt.Sprintf("[%s] Issue milestoned: %s by %s", p.Repository.FullName, titleLink, senderLink)
 	case apiv1types.WebhookIssueDemilestoned:
 		text = fmt.Sprintf("[%s] Issue demilestoned: %s by %s", p.Repository.FullName, titleLink, senderLink)
+	case apiv1types.WebhookIssueReacted:
+		text = fmt.Sprintf("[%s] Issue reacted with :%s:: %s by %s", p.Repository.FullName, p.Reaction.Content, titleLink, senderLink)
+	case apiv1types.WebhookIssueUnreacted:
+		text = fmt.Sprintf("[%s] Issue reaction :%s: removed: %s by %s", p.Repository.FullName, p.Reaction.Content, titleLink, senderLink)
 	}
 
 	return &SlackPayload{
@@ -202,6 +206,12 @@ func getSlackIssueCommentPayload(p *apiv1types.WebhookIssueCommentPayload, slack
 		text = fmt.Sprintf("[%s] Comment edited by %s", p.Repository.FullName, senderLink)
 		title = titleLink
 		attachmentText = SlackTextFormatter(p.Comment.Body)
+	case apiv1types.WebhookIssueCommentReacted:
+		text = fmt.Sprintf("[%s] Comment reacted with :%s: by %s", p.Repository.FullName, p.Reaction.Content, senderLink)
+		title = titleLink
+	case apiv1types.WebhookIssueCommentUnreacted:
+		text = fmt.Sprintf("[%s] Comment reaction :%s: removed by %s", p.Repository.FullName, p.Reaction.Content, senderLink)
+		title = titleLink
 	case apiv1types.WebhookIssueCommentDeleted:
 		text = fmt.Sprintf("[%s] Comment deleted by %s", p.Repository.FullName, senderLink)
 		title = SlackLinkFormatter(fmt.Sprintf("%s/issues/%d", p.Repository.HTMLURL, p.Issue.Index),
@@ -245,10 +255,16 @@ func getSlackPullRequestPayload(p *apiv1types.WebhookPullRequestPayload, slack *
 		attachmentText = SlackTextFormatter(p.PullRequest.Body)
 	case apiv1types.WebhookIssueAssigned:
 		text = fmt.Sprintf("[%s] Pull request assigned to %s: %s by %s", p.Repository.FullName,
-			SlackLinkFormatter(conf.Server.ExternalURL+p.PullRequest.Assignee.UserName, p.PullRequest.Assignee.UserName),
+			SlackLinkFormatter(conf.Server.ExternalURL+p.Assignee.UserName, p.Assignee.UserName),
 			titleLink, senderLink)
 	case apiv1types.WebhookIssueUnassigned:
 		text = fmt.Sprintf("[%s] Pull request unassigned: %s by %s", p.Repository.FullName, titleLink, senderLink)
+	case apiv1types.WebhookIssueReviewRequested:
+		text = fmt.Sprintf("[%s] Pull request review requested from %s: %s by %s", p.Repository.FullName,
+			reviewRequestTarget(p), titleLink, senderLink)
+	case apiv1types.WebhookIssueReviewRequestRemoved:
+		text = fmt.Sprintf("[%s] Pull request review request removed from %s: %s by %s", p.Repository.FullName,
+			reviewRequestTarget(p), titleLink, senderLink)
 	case apiv1types.WebhookIssueLabelUpdated:
 		text = fmt.Sprintf("[%s] Pull request labels updated: %s by %s", p.Repository.FullName, titleLink, senderLink)
 	case apiv1types.WebhookIssueLabelCleared:
@@ -259,6 +275,10 @@ func getSlackPullRequestPayload(p *apiv1types.WebhookPullRequestPayload, slack *
 		text = fmt.Sprintf("[%s] Pull request milestoned: %s by %s", p.Repository.FullName, titleLink, senderLink)
 	case apiv1types.WebhookIssueDemilestoned:
 		text = fmt.Sprintf("[%s] Pull request demilestoned: %s by %s", p.Repository.FullName, titleLink, senderLink)
+	case apiv1types.WebhookIssueReacted:
+		text = fmt.Sprintf("[%s] Pull request reacted with :%s:: %s by %s", p.Repository.FullName, p.Reaction.Content, titleLink, senderLink)
+	case apiv1types.WebhookIssueUnreacted:
+		text = fmt.Sprintf("[%s] Pull request reaction :%s: removed: %s by %s", p.Repository.FullName, p.Reaction.Content, titleLink, senderLink)
 	}
 
 	return &SlackPayload{
@@ -34,8 +34,9 @@ const (
 	tmplAuthResetPassword  = "auth/reset_passwd"
 	tmplAuthRegisterNotify = "auth/register_notify"
 
-	tmplIssueComment = "issue/comment"
-	tmplIssueMention = "issue/mention"
+	tmplIssueComment   = "issue/comment"
+	tmplIssueMention   = "issue/mention"
+	tmplIssueCodeOwner = "issue/code_owner"
 
 	tmplNotifyCollaborator = "notify/collaborator"
 )
@@ -345,3 +346,17 @@ func SendIssueMentionMail(issue Issue, repo Repository, doer User, tos []string)
 	send(msg)
 	return nil
 }
+
+// SendIssueCodeOwnerMail composes and sends emails to code owners of changed
+// files of a pull request.
+func SendIssueCodeOwnerMail(issue Issue, repo Repository, doer User, tos []string) error {
+	if len(tos) == 0 {
+		return nil
+	}
+	msg, err := composeIssueMessage(issue, repo, doer, tmplIssueCodeOwner, tos, "issue code owner")
+	if err != nil {
+		return errors.Wrap(err, "compose issue message")
+	}
+	send(msg)
+	return nil
+}
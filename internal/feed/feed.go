@@ -0,0 +1,219 @@
+// Package feed encodes Atom and RSS feeds.
+package feed
+
+import (
+	"encoding/xml"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Format is the format of a feed.
+type Format string
+
+const (
+	FormatAtom Format = "atom"
+	FormatRSS  Format = "rss"
+)
+
+// ContentType returns the MIME type of the format.
+func (f Format) ContentType() string {
+	if f == FormatRSS {
+		return "application/rss+xml; charset=utf-8"
+	}
+	return "application/atom+xml; charset=utf-8"
+}
+
+// Feed is a list of items with metadata, which can be encoded in any format.
+type Feed struct {
+	Title       string
+	Link        string // The absolute URL of the HTML page of the feed
+	Description string
+	// Updated is the time of the latest item when not set, or the current time
+	// when there is no item.
+	Updated time.Time
+	Items   []*Item
+}
+
+// Item is an entry of a feed.
+type Item struct {
+	// ID is the permanent and unique identifier of the item, Link is used when
+	// not set.
+	ID      string
+	Title   string
+	Link    string // The absolute URL of the item
+	Content string // HTML content of the item
+	Author  string
+	Updated time.Time
+}
+
+func (item *Item) id() string {
+	if item.ID != "" {
+		return item.ID
+	}
+	return item.Link
+}
+
+func (f *Feed) updated() time.Time {
+	if !f.Updated.IsZero() {
+		return f.Updated
+	}
+
+	var updated time.Time
+	for _, item := range f.Items {
+		if item.Updated.After(updated) {
+			updated = item.Updated
+		}
+	}
+	if updated.IsZero() {
+		return time.Now()
+	}
+	return updated
+}
+
+// Encode returns the feed encoded in given format.
+func (f *Feed) Encode(format Format) ([]byte, error) {
+	var v any
+	switch format {
+	case FormatAtom:
+		v = f.toAtom()
+	case FormatRSS:
+		v = f.toRSS()
+	default:
+		return nil, errors.Newf("unsupported format %q", format)
+	}
+
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID      string       `xml:"id"`
+	Title   string       `xml:"title"`
+	Updated string       `xml:"updated"`
+	Link    *atomLink    `xml:"link,omitempty"`
+	Author  *atomPerson  `xml:"author,omitempty"`
+	Content *atomContent `xml:"content,omitempty"`
+}
+
+type atomFeed struct {
+	XMLName  xml.Name     `xml:"feed"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	ID       string       `xml:"id"`
+	Title    string       `xml:"title"`
+	Subtitle string       `xml:"subtitle,omitempty"`
+	Updated  string       `xml:"updated"`
+	Link     *atomLink    `xml:"link"`
+	Entries  []*atomEntry `xml:"entry"`
+}
+
+func (f *Feed) toAtom() *atomFeed {
+	feed := &atomFeed{
+		Xmlns:    "http://www.w3.org/2005/Atom",
+		ID:       f.Link,
+		Title:    f.Title,
+		Subtitle: f.Description,
+		Updated:  f.updated().UTC().Format(time.RFC3339),
+		Link:     &atomLink{Href: f.Link, Rel: "alternate"},
+		Entries:  make([]*atomEntry, len(f.Items)),
+	}
+	for i, item := range f.Items {
+		entry := &atomEntry{
+			ID:      item.id(),
+			Title:   item.Title,
+			Updated: item.Updated.UTC().Format(time.RFC3339),
+		}
+		if item.Link != "" {
+			entry.Link = &atomLink{Href: item.Link, Rel: "alternate"}
+		}
+		if item.Author != "" {
+			entry.Author = &atomPerson{Name: item.Author}
+		}
+		if item.Content != "" {
+			entry.Content = &atomContent{Type: "html", Body: item.Content}
+		}
+		feed.Entries[i] = entry
+	}
+	return feed
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link,omitempty"`
+	Description string   `xml:"description,omitempty"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	GUID        *rssGUID `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+}
+
+type rssChannel struct {
+	Title         string     `xml:"title"`
+	Link          string     `xml:"link"`
+	Description   string     `xml:"description"`
+	LastBuildDate string     `xml:"lastBuildDate"`
+	Items         []*rssItem `xml:"item"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name    `xml:"rss"`
+	Version string      `xml:"version,attr"`
+	XmlnsDC string      `xml:"xmlns:dc,attr"`
+	Channel *rssChannel `xml:"channel"`
+}
+
+func (f *Feed) toRSS() *rssFeed {
+	// The description of a channel is required.
+	description := f.Description
+	if description == "" {
+		description = f.Title
+	}
+
+	channel := &rssChannel{
+		Title:         f.Title,
+		Link:          f.Link,
+		Description:   description,
+		LastBuildDate: f.updated().UTC().Format(time.RFC1123Z),
+		Items:         make([]*rssItem, len(f.Items)),
+	}
+	for i, item := range f.Items {
+		id := item.id()
+		channel.Items[i] = &rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Content,
+			Creator:     item.Author,
+			GUID: &rssGUID{
+				IsPermaLink: id == item.Link,
+				Value:       id,
+			},
+			PubDate: item.Updated.UTC().Format(time.RFC1123Z),
+		}
+	}
+	return &rssFeed{
+		Version: "2.0",
+		XmlnsDC: "http://purl.org/dc/elements/1.1/",
+		Channel: channel,
+	}
+}
@@ -0,0 +1,96 @@
+package feed
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeed_Encode(t *testing.T) {
+	f := &Feed{
+		Title: "alice/example releases",
+		Link:  "https://gogs.example.com/alice/example/releases",
+		Items: []*Item{
+			{
+				Title:   "v1.1 <beta>",
+				Link:    "https://gogs.example.com/alice/example/src/v1.1",
+				Content: "<p>Fixes &amp; features</p>",
+				Author:  "alice",
+				Updated: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+			},
+			{
+				ID:      "https://gogs.example.com/alice/example/src/v1.0#1",
+				Title:   "v1.0",
+				Link:    "https://gogs.example.com/alice/example/src/v1.0",
+				Updated: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+			},
+		},
+	}
+
+	t.Run("atom", func(t *testing.T) {
+		got, err := f.Encode(FormatAtom)
+		require.NoError(t, err)
+
+		want := `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <id>https://gogs.example.com/alice/example/releases</id>
+  <title>alice/example releases</title>
+  <updated>2026-01-02T03:04:05Z</updated>
+  <link href="https://gogs.example.com/alice/example/releases" rel="alternate"></link>
+  <entry>
+    <id>https://gogs.example.com/alice/example/src/v1.1</id>
+    <title>v1.1 &lt;beta&gt;</title>
+    <updated>2026-01-02T03:04:05Z</updated>
+    <link href="https://gogs.example.com/alice/example/src/v1.1" rel="alternate"></link>
+    <author>
+      <name>alice</name>
+    </author>
+    <content type="html">&lt;p&gt;Fixes &amp;amp; features&lt;/p&gt;</content>
+  </entry>
+  <entry>
+    <id>https://gogs.example.com/alice/example/src/v1.0#1</id>
+    <title>v1.0</title>
+    <updated>2025-01-02T03:04:05Z</updated>
+    <link href="https://gogs.example.com/alice/example/src/v1.0" rel="alternate"></link>
+  </entry>
+</feed>`
+		assert.Equal(t, want, string(got))
+	})
+
+	t.Run("rss", func(t *testing.T) {
+		got, err := f.Encode(FormatRSS)
+		require.NoError(t, err)
+
+		want := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel>
+    <title>alice/example releases</title>
+    <link>https://gogs.example.com/alice/example/releases</link>
+    <description>alice/example releases</description>
+    <lastBuildDate>Fri, 02 Jan 2026 03:04:05 +0000</lastBuildDate>
+    <item>
+      <title>v1.1 &lt;beta&gt;</title>
+      <link>https://gogs.example.com/alice/example/src/v1.1</link>
+      <description>&lt;p&gt;Fixes &amp;amp; features&lt;/p&gt;</description>
+      <dc:creator>alice</dc:creator>
+      <guid isPermaLink="true">https://gogs.example.com/alice/example/src/v1.1</guid>
+      <pubDate>Fri, 02 Jan 2026 03:04:05 +0000</pubDate>
+    </item>
+    <item>
+      <title>v1.0</title>
+      <link>https://gogs.example.com/alice/example/src/v1.0</link>
+      <guid isPermaLink="false">https://gogs.example.com/alice/example/src/v1.0#1</guid>
+      <pubDate>Thu, 02 Jan 2025 03:04:05 +0000</pubDate>
+    </item>
+  </channel>
+</rss>`
+		assert.Equal(t, want, string(got))
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		_, err := f.Encode("json")
+		assert.Error(t, err)
+	})
+}
@@ -100,6 +100,7 @@ type RepoSetting struct {
 	RepoName      string `binding:"Required;AlphaDashDot;MaxSize(100)"`
 	Description   string `binding:"MaxSize(512)"`
 	Website       string `binding:"Url;MaxSize(100)"`
+	Topics        string `binding:"MaxSize(1000)"`
 	Branch        string
 	Interval      int
 	MirrorAddress string
@@ -120,7 +121,10 @@ type RepoSetting struct {
 	TrackerIssueStyle     string
 	EnablePulls           bool
 	PullsIgnoreWhitespace bool
+	PullsAllowMerge       bool
 	PullsAllowRebase      bool
+	PullsAllowSquash      bool
+	PullsAllowFastForward bool
 }
 
 func (f *RepoSetting) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
@@ -135,17 +139,30 @@ func (f *RepoSetting) Validate(ctx *macaron.Context, errs binding.Errors) bindin
 //         \/             \/     \/     \/     \/
 
 type ProtectBranch struct {
-	Protected          bool
-	RequirePullRequest bool
-	EnableWhitelist    bool
-	WhitelistUsers     string
-	WhitelistTeams     string
+	Protected               bool
+	RequirePullRequest      bool
+	RequireSignedCommits    bool
+	RequireLinearHistory    bool
+	RequireCodeOwnerSignOff bool
+	EnableWhitelist         bool
+	WhitelistUsers          string
+	WhitelistTeams          string
 }
 
 func (f *ProtectBranch) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+type ProtectTag struct {
+	Pattern        string `binding:"Required;MaxSize(255)"`
+	WhitelistUsers string
+	WhitelistTeams string
+}
+
+func (f *ProtectTag) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 //  __      __      ___.   .__    .__            __
 // /  \    /  \ ____\_ |__ |  |__ |  |__   ____ |  | __
 // \   \/\/   // __ \| __ \|  |  \|  |  \ /  _ \|  |/ /
@@ -234,9 +251,12 @@ type NewIssue struct {
 	Title       string `binding:"Required;MaxSize(255)"`
 	LabelIDs    string `form:"label_ids"`
 	MilestoneID int64
-	AssigneeID  int64
+	AssigneeIDs string `form:"assignee_ids"`
 	Content     string
 	Files       []string
+	Template    string
+
+	AllowMaintainerEdit bool
 }
 
 func (f *NewIssue) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
@@ -253,6 +273,21 @@ func (f *CreateComment) Validate(ctx *macaron.Context, errs binding.Errors) bind
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+type TrackedTime struct {
+	Hours   int64
+	Minutes int64
+	Date    string
+}
+
+func (f *TrackedTime) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// Seconds returns the duration of the tracked time in seconds.
+func (f TrackedTime) Seconds() int64 {
+	return f.Hours*3600 + f.Minutes*60
+}
+
 //    _____  .__.__                   __
 //   /     \ |__|  |   ____   _______/  |_  ____   ____   ____
 //  /  \ /  \|  |  | _/ __ \ /  ___/\   __\/  _ \ /    \_/ __ \
@@ -68,6 +68,14 @@ func (f *AddSSHKey) Validate(ctx *macaron.Context, errs binding.Errors) binding.
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+type AddGPGKey struct {
+	Content string `binding:"Required"`
+}
+
+func (f *AddGPGKey) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 type NewAccessToken struct {
 	Name string `binding:"Required"`
 }
@@ -75,3 +83,12 @@ type NewAccessToken struct {
 func (f *NewAccessToken) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
+
+type OAuth2Application struct {
+	Name         string `binding:"Required;MaxSize(255)"`
+	RedirectURIs string `form:"redirect_uris" binding:"Required"`
+}
+
+func (f *OAuth2Application) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
@@ -0,0 +1,265 @@
+package gitx
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/gogs/git-module"
+
+	"gogs.io/gogs/internal/lfsx"
+)
+
+// ArchiveFormats are all formats of archives that can be created.
+var ArchiveFormats = []string{"zip", "tar", "tar.gz", "tar.bz2", "tar.xz"}
+
+// archiveCompressors are commands to compress tar archives for formats that
+// are not supported by Git out of the box.
+var archiveCompressors = map[string][]string{
+	"tar.bz2": {"bzip2", "-c"},
+	"tar.xz":  {"xz", "-c"},
+}
+
+// ArchiveOptions contains optional arguments for creating an archive.
+type ArchiveOptions struct {
+	// Prefix is prepended to paths of all files in the archive, e.g. "gogs/".
+	Prefix  string
+	Timeout time.Duration
+	// LFSObject is called for every file in the archive that is an LFS pointer.
+	// It returns the size of the object and a function to stream its content,
+	// or false to keep the pointer file as is. Pointer files are always kept
+	// when it is nil.
+	LFSObject func(pointer *lfsx.Pointer) (size int64, download func(w io.Writer) error, ok bool)
+}
+
+// CreateArchive writes the archive of the tree of given revision of the
+// repository in given path in given format to the io.Writer.
+func CreateArchive(ctx context.Context, repoPath, rev, format string, w io.Writer, opts ArchiveOptions) error {
+	if opts.LFSObject == nil {
+		var args []string
+		for _, f := range ArchiveFormats {
+			if cmd, ok := archiveCompressors[f]; ok {
+				args = append(args, "-c", "tar."+f+".command="+strings.Join(cmd, " "))
+			}
+		}
+		args = append(args, "archive", "--prefix="+opts.Prefix, "--format="+format, "--end-of-options", rev)
+
+		stderr := new(bytes.Buffer)
+		err := git.NewCommandWithContext(ctx, args...).
+			WithTimeout(opts.Timeout).
+			RunInDirWithOptions(repoPath, git.RunInDirOptions{Stdout: w, Stderr: stderr})
+		if err != nil {
+			return errors.Newf("run git archive: %v - %s", err, stderr.String())
+		}
+		return nil
+	}
+
+	// Contents of LFS objects are only known to us, so we read the tar archive
+	// from Git and write every file to an archive in the requested format.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	aw, err := newArchiveWriter(ctx, format, w)
+	if err != nil {
+		return errors.Wrap(err, "new archive writer")
+	}
+
+	stdout, pw := io.Pipe()
+	result := make(chan error, 1)
+	go func() {
+		stderr := new(bytes.Buffer)
+		err := git.NewCommandWithContext(ctx, "archive", "--prefix="+opts.Prefix, "--format=tar", "--end-of-options", rev).
+			WithTimeout(opts.Timeout).
+			RunInDirWithOptions(repoPath, git.RunInDirOptions{Stdout: pw, Stderr: stderr})
+		if err != nil {
+			err = errors.Newf("run git archive: %v - %s", err, stderr.String())
+		}
+		_ = pw.CloseWithError(err) // Reader gets io.EOF when err is nil
+		result <- err
+	}()
+
+	err = copyArchive(aw, tar.NewReader(stdout), opts.LFSObject)
+	if err == nil {
+		// Git pads the tar archive after the end-of-archive marker.
+		_, err = io.Copy(io.Discard, stdout)
+	}
+	if err != nil {
+		cancel() // Stop Git and the compressor
+		_ = stdout.CloseWithError(err)
+		_ = aw.Close()
+		if gitErr := <-result; gitErr != nil {
+			return gitErr
+		}
+		return err
+	}
+	if err = <-result; err != nil {
+		return err
+	}
+	return aw.Close()
+}
+
+// copyArchive copies all files from the tar archive to the archive writer,
+// replacing pointer files with contents of LFS objects.
+func copyArchive(aw archiveWriter, tr *tar.Reader, lfsObject func(pointer *lfsx.Pointer) (int64, func(w io.Writer) error, bool)) error {
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.Wrap(err, "read tar header")
+		}
+
+		if hdr.Typeflag != tar.TypeReg || hdr.Size > lfsx.MaxPointerSize {
+			err = aw.WriteFile(hdr, func(w io.Writer) error {
+				_, err := io.Copy(w, tr)
+				return err
+			})
+			if err != nil {
+				return errors.Wrapf(err, "write %q", hdr.Name)
+			}
+			continue
+		}
+
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return errors.Wrapf(err, "read %q", hdr.Name)
+		}
+		write := func(w io.Writer) error {
+			_, err := w.Write(content)
+			return err
+		}
+		if pointer, ok := lfsx.ParsePointer(content); ok {
+			if size, download, ok := lfsObject(pointer); ok {
+				hdr.Size = size
+				write = download
+			}
+		}
+		if err = aw.WriteFile(hdr, write); err != nil {
+			return errors.Wrapf(err, "write %q", hdr.Name)
+		}
+	}
+}
+
+// archiveWriter writes files to an archive.
+type archiveWriter interface {
+	// WriteFile writes the file with given header, the content is written by
+	// the callback.
+	WriteFile(hdr *tar.Header, write func(w io.Writer) error) error
+	// Close finishes writing the archive.
+	Close() error
+}
+
+func newArchiveWriter(ctx context.Context, format string, w io.Writer) (archiveWriter, error) {
+	switch format {
+	case "zip":
+		return &zipArchiveWriter{zw: zip.NewWriter(w)}, nil
+	case "tar":
+		return &tarArchiveWriter{tw: tar.NewWriter(w)}, nil
+	case "tar.gz":
+		gw := gzip.NewWriter(w)
+		return &tarArchiveWriter{tw: tar.NewWriter(gw), compressor: gw}, nil
+	}
+
+	args, ok := archiveCompressors[format]
+	if !ok {
+		return nil, errors.Newf("unsupported format %q", format)
+	}
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdout = w
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, errors.Wrap(err, "get stdin pipe")
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, errors.Wrapf(err, "start %q", args[0])
+	}
+	return &tarArchiveWriter{
+		tw: tar.NewWriter(stdin),
+		compressor: &commandCloser{
+			stdin: stdin,
+			cmd:   cmd,
+		},
+	}, nil
+}
+
+// commandCloser closes the stdin of the command and waits for it to exit.
+type commandCloser struct {
+	stdin io.Closer
+	cmd   *exec.Cmd
+}
+
+func (c *commandCloser) Close() error {
+	_ = c.stdin.Close()
+	return c.cmd.Wait()
+}
+
+type tarArchiveWriter struct {
+	tw         *tar.Writer
+	compressor io.Closer
+}
+
+func (w *tarArchiveWriter) WriteFile(hdr *tar.Header, write func(w io.Writer) error) error {
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	return write(w.tw)
+}
+
+func (w *tarArchiveWriter) Close() error {
+	err := w.tw.Close()
+	if w.compressor != nil {
+		if cerr := w.compressor.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+type zipArchiveWriter struct {
+	zw *zip.Writer
+}
+
+func (w *zipArchiveWriter) WriteFile(hdr *tar.Header, write func(w io.Writer) error) error {
+	switch hdr.Typeflag {
+	case tar.TypeXGlobalHeader:
+		// Git puts the commit ID in the comment of both tar and zip archives.
+		return w.zw.SetComment(hdr.PAXRecords["comment"])
+	case tar.TypeDir, tar.TypeReg, tar.TypeSymlink:
+	default:
+		return nil
+	}
+
+	fh := &zip.FileHeader{
+		Name:     hdr.Name,
+		Modified: hdr.ModTime,
+	}
+	fh.SetMode(hdr.FileInfo().Mode())
+	if hdr.Typeflag == tar.TypeReg {
+		fh.Method = zip.Deflate
+	}
+	fw, err := w.zw.CreateHeader(fh)
+	if err != nil {
+		return err
+	}
+
+	switch hdr.Typeflag {
+	case tar.TypeReg:
+		return write(fw)
+	case tar.TypeSymlink:
+		_, err = io.WriteString(fw, hdr.Linkname)
+		return err
+	}
+	return nil
+}
+
+func (w *zipArchiveWriter) Close() error {
+	return w.zw.Close()
+}
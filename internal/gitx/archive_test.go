@@ -0,0 +1,106 @@
+package gitx
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/lfsx"
+)
+
+func TestCopyArchive(t *testing.T) {
+	const (
+		oid     = "ef797c8118f02dfb649607dd5d3f8c7623048c9c063d532cc95c5ed7a898a64f"
+		pointer = "version https://git-lfs.github.com/spec/v1\noid sha256:" + oid + "\nsize 5\n"
+	)
+
+	src := new(bytes.Buffer)
+	tw := tar.NewWriter(src)
+	for _, f := range []struct {
+		hdr     *tar.Header
+		content string
+	}{
+		{hdr: &tar.Header{Typeflag: tar.TypeXGlobalHeader, PAXRecords: map[string]string{"comment": "8a4862c2525d0a297eeb6b321923b086fa4d3732"}}},
+		{hdr: &tar.Header{Typeflag: tar.TypeDir, Name: "repo/", Mode: 0o775}},
+		{hdr: &tar.Header{Typeflag: tar.TypeReg, Name: "repo/README.md", Mode: 0o664}, content: "# repo\n"},
+		{hdr: &tar.Header{Typeflag: tar.TypeReg, Name: "repo/image.png", Mode: 0o664}, content: pointer},
+		{hdr: &tar.Header{Typeflag: tar.TypeSymlink, Name: "repo/link", Linkname: "README.md", Mode: 0o777}},
+	} {
+		f.hdr.Size = int64(len(f.content))
+		require.NoError(t, tw.WriteHeader(f.hdr))
+		_, err := tw.Write([]byte(f.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+
+	lfsObject := func(p *lfsx.Pointer) (int64, func(w io.Writer) error, bool) {
+		if p.OID != oid {
+			return 0, nil, false
+		}
+		return 5, func(w io.Writer) error {
+			_, err := io.WriteString(w, "image")
+			return err
+		}, true
+	}
+	want := map[string]string{
+		"repo/":          "",
+		"repo/README.md": "# repo\n",
+		"repo/image.png": "image",
+		"repo/link":      "README.md",
+	}
+
+	t.Run("tar", func(t *testing.T) {
+		dst := new(bytes.Buffer)
+		aw := &tarArchiveWriter{tw: tar.NewWriter(dst)}
+		require.NoError(t, copyArchive(aw, tar.NewReader(bytes.NewReader(src.Bytes())), lfsObject))
+		require.NoError(t, aw.Close())
+
+		got := make(map[string]string)
+		tr := tar.NewReader(dst)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			if hdr.Typeflag == tar.TypeXGlobalHeader {
+				continue
+			}
+
+			content, err := io.ReadAll(tr)
+			require.NoError(t, err)
+			if hdr.Typeflag == tar.TypeSymlink {
+				content = []byte(hdr.Linkname)
+			}
+			got[hdr.Name] = string(content)
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("zip", func(t *testing.T) {
+		dst := new(bytes.Buffer)
+		aw := &zipArchiveWriter{zw: zip.NewWriter(dst)}
+		require.NoError(t, copyArchive(aw, tar.NewReader(bytes.NewReader(src.Bytes())), lfsObject))
+		require.NoError(t, aw.Close())
+
+		zr, err := zip.NewReader(bytes.NewReader(dst.Bytes()), int64(dst.Len()))
+		require.NoError(t, err)
+		assert.Equal(t, "8a4862c2525d0a297eeb6b321923b086fa4d3732", zr.Comment)
+
+		got := make(map[string]string)
+		for _, f := range zr.File {
+			rc, err := f.Open()
+			require.NoError(t, err)
+			content, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			_ = rc.Close()
+			got[f.Name] = string(content)
+		}
+		assert.Equal(t, want, got)
+	})
+}
@@ -0,0 +1,182 @@
+package gitx
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/gogs/git-module"
+)
+
+// BlameCommit contains information of a commit that last modified some lines
+// of the blamed file.
+type BlameCommit struct {
+	ID        string
+	Author    *git.Signature
+	Committer *git.Signature
+	Summary   string
+	// PreviousID is the ID of the parent commit that the lines are blamed
+	// against, it is empty when the commit is a boundary (e.g. the root commit).
+	PreviousID string
+	// PreviousPath is the path of the file in the previous commit.
+	PreviousPath string
+}
+
+// BlameLine is a line of the blamed file.
+type BlameLine struct {
+	// Number is the line number in the blamed revision.
+	Number int
+	// OriginalNumber is the line number in the commit that last modified it.
+	OriginalNumber int
+	Content        string
+}
+
+// BlamePart is a group of consecutive lines last modified by the same commit.
+type BlamePart struct {
+	Commit *BlameCommit
+	Lines  []*BlameLine
+}
+
+// parseBlameTimezone parses the timezone offset (e.g. "+0800") in the porcelain
+// output.
+func parseBlameTimezone(tz string) *time.Location {
+	if len(tz) != 5 {
+		return time.UTC
+	}
+
+	hours, _ := strconv.Atoi(tz[1:3])
+	minutes, _ := strconv.Atoi(tz[3:5])
+	offset := hours*60*60 + minutes*60
+	if tz[0] == '-' {
+		offset = -offset
+	}
+	return time.FixedZone("", offset)
+}
+
+// ParseBlame parses the output of "git blame --porcelain" from given
+// io.Reader.
+func ParseBlame(r io.Reader) ([]*BlamePart, error) {
+	var (
+		parts   []*BlamePart
+		commits = make(map[string]*BlameCommit)
+		commit  *BlameCommit
+		line    *BlameLine
+	)
+	reader := bufio.NewReader(r)
+	for {
+		text, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, errors.Wrap(err, "read")
+		}
+		text = strings.TrimSuffix(text, "\n")
+
+		switch {
+		case text == "":
+		case text[0] == '\t':
+			// The content line ends a group of headers of the line.
+			if commit == nil || line == nil {
+				return nil, errors.Newf("unexpected content line before header")
+			}
+
+			line.Content = text[1:]
+			if len(parts) == 0 || parts[len(parts)-1].Commit != commit {
+				parts = append(parts, &BlamePart{Commit: commit})
+			}
+			last := parts[len(parts)-1]
+			last.Lines = append(last.Lines, line)
+			line = nil
+
+		case line == nil:
+			// The header line looks like "<sha> <orig_line> <final_line> [<num_lines>]".
+			fields := strings.Fields(text)
+			if len(fields) < 3 {
+				return nil, errors.Newf("malformed header line %q", text)
+			}
+
+			commit = commits[fields[0]]
+			if commit == nil {
+				commit = &BlameCommit{
+					ID:        fields[0],
+					Author:    new(git.Signature),
+					Committer: new(git.Signature),
+				}
+				commits[fields[0]] = commit
+			}
+			line = &BlameLine{}
+			line.OriginalNumber, _ = strconv.Atoi(fields[1])
+			line.Number, _ = strconv.Atoi(fields[2])
+
+		default:
+			key, value, _ := strings.Cut(text, " ")
+			switch key {
+			case "author":
+				commit.Author.Name = value
+			case "author-mail":
+				commit.Author.Email = strings.TrimSuffix(strings.TrimPrefix(value, "<"), ">")
+			case "author-time":
+				sec, _ := strconv.ParseInt(value, 10, 64)
+				commit.Author.When = time.Unix(sec, 0)
+			case "author-tz":
+				commit.Author.When = commit.Author.When.In(parseBlameTimezone(value))
+			case "committer":
+				commit.Committer.Name = value
+			case "committer-mail":
+				commit.Committer.Email = strings.TrimSuffix(strings.TrimPrefix(value, "<"), ">")
+			case "committer-time":
+				sec, _ := strconv.ParseInt(value, 10, 64)
+				commit.Committer.When = time.Unix(sec, 0)
+			case "committer-tz":
+				commit.Committer.When = commit.Committer.When.In(parseBlameTimezone(value))
+			case "summary":
+				commit.Summary = value
+			case "previous":
+				commit.PreviousID, commit.PreviousPath, _ = strings.Cut(value, " ")
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+	return parts, nil
+}
+
+// RepoBlame returns the blame of the file in given revision of the repository
+// in given path. The revision must be a commit ID or a reference name.
+func RepoBlame(repoPath, rev, file string, timeout time.Duration) ([]*BlamePart, error) {
+	// 🚨 SECURITY: Prevent the revision from being interpreted as an option,
+	// "git blame" does not support "--end-of-options".
+	if strings.HasPrefix(rev, "-") {
+		return nil, errors.Newf("invalid revision %q", rev)
+	}
+
+	type result struct {
+		parts []*BlamePart
+		err   error
+	}
+	stdout, w := io.Pipe()
+	done := make(chan result)
+	go func() {
+		parts, err := ParseBlame(stdout)
+		// Drain the rest of output so the command won't be blocked on writing.
+		_, _ = io.Copy(io.Discard, stdout)
+		done <- result{parts: parts, err: err}
+	}()
+
+	stderr := new(bytes.Buffer)
+	err := git.NewCommand("blame", "--porcelain", rev, "--", file).
+		RunInDirPipelineWithTimeout(timeout, w, stderr, repoPath)
+	_ = w.Close() // Close writer to exit parsing goroutine
+	res := <-done
+	if err != nil {
+		return nil, errors.Newf("run git blame: %v - %s", err, stderr.String())
+	} else if res.err != nil {
+		return nil, errors.Wrap(res.err, "parse blame")
+	}
+	return res.parts, nil
+}
@@ -0,0 +1,107 @@
+package gitx
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gogs/git-module"
+)
+
+func TestParseBlame(t *testing.T) {
+	const output = `77a44326a1bb502f19b62f665b72aba632bac5f3 1 1 1
+author Alice
+author-mail <alice@example.com>
+author-time 1577836800
+author-tz +0800
+committer Alice
+committer-mail <alice@example.com>
+committer-time 1577836800
+committer-tz +0800
+summary Initial commit
+boundary
+filename README.md
+	# Title
+8a4862c2525d0a297eeb6b321923b086fa4d3732 2 2 2
+author Bob
+author-mail <bob@example.com>
+author-time 1577923200
+author-tz -0130
+committer Bob
+committer-mail <bob@example.com>
+committer-time 1577923200
+committer-tz -0130
+summary Update README
+previous 77a44326a1bb502f19b62f665b72aba632bac5f3 README
+filename README.md
+	Hello
+8a4862c2525d0a297eeb6b321923b086fa4d3732 3 3
+	
+77a44326a1bb502f19b62f665b72aba632bac5f3 2 4 1
+filename README.md
+	World
+`
+	parts, err := ParseBlame(strings.NewReader(output))
+	require.NoError(t, err)
+	require.Len(t, parts, 3)
+
+	initial := &BlameCommit{
+		ID: "77a44326a1bb502f19b62f665b72aba632bac5f3",
+		Author: &git.Signature{
+			Name:  "Alice",
+			Email: "alice@example.com",
+			When:  time.Unix(1577836800, 0).In(time.FixedZone("", 8*60*60)),
+		},
+		Committer: &git.Signature{
+			Name:  "Alice",
+			Email: "alice@example.com",
+			When:  time.Unix(1577836800, 0).In(time.FixedZone("", 8*60*60)),
+		},
+		Summary: "Initial commit",
+	}
+	update := &BlameCommit{
+		ID: "8a4862c2525d0a297eeb6b321923b086fa4d3732",
+		Author: &git.Signature{
+			Name:  "Bob",
+			Email: "bob@example.com",
+			When:  time.Unix(1577923200, 0).In(time.FixedZone("", -90*60)),
+		},
+		Committer: &git.Signature{
+			Name:  "Bob",
+			Email: "bob@example.com",
+			When:  time.Unix(1577923200, 0).In(time.FixedZone("", -90*60)),
+		},
+		Summary:      "Update README",
+		PreviousID:   "77a44326a1bb502f19b62f665b72aba632bac5f3",
+		PreviousPath: "README",
+	}
+	want := []*BlamePart{
+		{
+			Commit: initial,
+			Lines:  []*BlameLine{{Number: 1, OriginalNumber: 1, Content: "# Title"}},
+		},
+		{
+			Commit: update,
+			Lines: []*BlameLine{
+				{Number: 2, OriginalNumber: 2, Content: "Hello"},
+				{Number: 3, OriginalNumber: 3, Content: ""},
+			},
+		},
+		{
+			Commit: initial,
+			Lines:  []*BlameLine{{Number: 4, OriginalNumber: 2, Content: "World"}},
+		},
+	}
+	assert.Equal(t, want, parts)
+
+	// The same commit should be shared by all of its parts.
+	assert.Same(t, parts[0].Commit, parts[2].Commit)
+
+	t.Run("malformed header", func(t *testing.T) {
+		_, err := ParseBlame(strings.NewReader("bad\n"))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,144 @@
+package gitx
+
+import (
+	"bufio"
+	"bytes"
+	gocontext "context"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/gogs/git-module"
+)
+
+// TreeFile is a regular file in a Git tree.
+type TreeFile struct {
+	// Path is the full path of the file relative to the root of the tree.
+	Path string
+	// ID is the object ID of the blob.
+	ID   string
+	Size int64
+}
+
+// ListFiles returns all regular files in the tree of given revision of the
+// repository in given path recursively. Symbolic links and submodules are
+// excluded.
+func ListFiles(repoPath, rev string, timeout time.Duration) ([]*TreeFile, error) {
+	stdout, err := git.NewCommand("ls-tree", "-r", "-l", "-z", "--full-tree", "--end-of-options", rev).
+		RunInDirWithTimeout(timeout, repoPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "list tree")
+	}
+
+	var files []*TreeFile
+	for _, entry := range bytes.Split(stdout, []byte{0}) {
+		// Each entry looks like "<mode> SP <type> SP <object> SP+ <size> TAB <path>".
+		meta, path, ok := bytes.Cut(entry, []byte{'\t'})
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(string(meta))
+		if len(fields) != 4 || fields[1] != "blob" || fields[0] == "120000" {
+			continue
+		}
+
+		size, _ := strconv.ParseInt(fields[3], 10, 64)
+		files = append(files, &TreeFile{
+			Path: string(path),
+			ID:   fields[2],
+			Size: size,
+		})
+	}
+	return files, nil
+}
+
+// ReadBlobs reads contents of blobs of given IDs in the repository in given
+// path with a single "git cat-file --batch" process. The callback is invoked
+// for each blob in the same order as given IDs, and missing blobs are skipped.
+func ReadBlobs(repoPath string, ids []string, timeout time.Duration, fn func(id string, content []byte) error) error {
+	return readObjects(repoPath, "blob", ids, timeout, fn)
+}
+
+// readObjects reads contents of objects of given IDs in the repository in given
+// path with a single "git cat-file --batch" process. The callback is invoked
+// for each object in the same order as given IDs, and missing objects or
+// objects that are not of given type are skipped.
+func readObjects(repoPath, typ string, ids []string, timeout time.Duration, fn func(id string, content []byte) error) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if timeout <= 0 {
+		timeout = git.DefaultTimeout
+	}
+	ctx, cancel := gocontext.WithTimeout(gocontext.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", "cat-file", "--batch")
+	cmd.Dir = repoPath
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return errors.Wrap(err, "get stdin pipe")
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return errors.Wrap(err, "get stdout pipe")
+	}
+	if err = cmd.Start(); err != nil {
+		return errors.Wrap(err, "start")
+	}
+
+	go func() {
+		for _, id := range ids {
+			if _, err := io.WriteString(stdin, id+"\n"); err != nil {
+				break
+			}
+		}
+		_ = stdin.Close()
+	}()
+
+	err = readBatchObjects(bufio.NewReader(stdout), typ, len(ids), fn)
+	if err != nil {
+		cancel()
+		_ = cmd.Wait()
+		return err
+	}
+	return cmd.Wait()
+}
+
+// readBatchObjects reads given number of objects from the output of "git
+// cat-file --batch", and invokes the callback for objects of given type.
+func readBatchObjects(r *bufio.Reader, typ string, n int, fn func(id string, content []byte) error) error {
+	for i := 0; i < n; i++ {
+		// The header looks like "<oid> SP <type> SP <size> LF" or "<object> SP missing LF".
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return errors.Wrap(err, "read header")
+		}
+		fields := strings.Fields(header)
+		if len(fields) != 3 {
+			continue
+		}
+
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return errors.Newf("malformed header %q", header)
+		}
+		content := make([]byte, size+1) // Including the trailing LF
+		if _, err = io.ReadFull(r, content); err != nil {
+			return errors.Wrap(err, "read content")
+		}
+
+		if fields[1] != typ {
+			continue
+		}
+		if err = fn(fields[0], content[:size]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package gitx
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadBatchObjects(t *testing.T) {
+	const output = "0c1e6990d7b5a8b3d66d0a8a4b3c9b3c0f7b9e1a blob 6\nhello\n\n" +
+		"deadbeef missing\n" +
+		"4b825dc642cb6eb9a060e54bf8d69288fbee4904 tree 0\n\n" +
+		"1f7a7a472abf3dd9643fd615f6da379c4acb3e3a blob 0\n\n"
+
+	got := make(map[string]string)
+	err := readBatchObjects(bufio.NewReader(strings.NewReader(output)), "blob", 4, func(id string, content []byte) error {
+		got[id] = string(content)
+		return nil
+	})
+	require.NoError(t, err)
+
+	want := map[string]string{
+		"0c1e6990d7b5a8b3d66d0a8a4b3c9b3c0f7b9e1a": "hello\n",
+		"1f7a7a472abf3dd9643fd615f6da379c4acb3e3a": "",
+	}
+	assert.Equal(t, want, got)
+
+	t.Run("truncated content", func(t *testing.T) {
+		err := readBatchObjects(bufio.NewReader(strings.NewReader("abc blob 10\nshort")), "blob", 1, func(string, []byte) error { return nil })
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,335 @@
+package gitx
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/gogs/git-module"
+)
+
+// GraphColors is the number of distinct colors of lanes in the commit graph.
+const GraphColors = 8
+
+// GraphCommit is a commit in the commit graph.
+type GraphCommit struct {
+	ID        string
+	ParentIDs []string
+	Author    *git.Signature
+	Subject   string
+	// Branches are names of branches that point to the commit.
+	Branches []string
+}
+
+// GraphLine is a line of the commit graph that connects a lane in the upper
+// edge of a row to a lane in the lower edge. Lanes are zero-based columns.
+type GraphLine struct {
+	From  int
+	To    int
+	Color int
+}
+
+// GraphRow is a row of the commit graph that contains one commit. The commit
+// is drawn in the middle of the row, lines in Upper go from the top of the row
+// to the middle, and lines in Lower go from the middle to the bottom. Lines in
+// Lower of a row are continued by lines in Upper of the next row.
+type GraphRow struct {
+	Commit *GraphCommit
+	Column int
+	Color  int
+	Upper  []GraphLine
+	Lower  []GraphLine
+}
+
+// Graph is a page of the commit graph.
+type Graph struct {
+	Rows []*GraphRow
+	// Width is the number of lanes needed to draw rows of the page.
+	Width int
+	// HasMore indicates whether there are more commits after the page.
+	HasMore bool
+}
+
+type graphLane struct {
+	id    string // The ID of the commit that is expected next in the lane
+	color int
+}
+
+// graphLayout assigns commits to lanes. Commits must be added in an order that
+// every commit comes before its parents (e.g. "git log --date-order").
+type graphLayout struct {
+	lanes     []graphLane
+	nextColor int
+}
+
+// allocate returns the first free lane, appending a new lane when all lanes
+// are occupied.
+func (l *graphLayout) allocate() int {
+	for i := range l.lanes {
+		if l.lanes[i].id == "" {
+			return i
+		}
+	}
+	l.lanes = append(l.lanes, graphLane{})
+	return len(l.lanes) - 1
+}
+
+func (l *graphLayout) newColor() int {
+	color := l.nextColor % GraphColors
+	l.nextColor++
+	return color
+}
+
+// add places the commit in the graph and returns its row. Lines of the row
+// are only computed when withLines is true, which is not needed for rows that
+// are not displayed.
+func (l *graphLayout) add(commit *GraphCommit, withLines bool) *GraphRow {
+	row := &GraphRow{
+		Commit: commit,
+		Column: -1,
+	}
+
+	// All lanes that expect the commit converge to it, the leftmost one is
+	// continued by the first parent. Other lanes pass through.
+	var passed map[int]bool
+	if withLines {
+		passed = make(map[int]bool, len(l.lanes))
+	}
+	for i, lane := range l.lanes {
+		if lane.id == "" {
+			continue
+		}
+		if lane.id != commit.ID {
+			if withLines {
+				row.Upper = append(row.Upper, GraphLine{From: i, To: i, Color: lane.color})
+				passed[i] = true
+			}
+			continue
+		}
+
+		if row.Column == -1 {
+			row.Column = i
+			row.Color = lane.color
+		}
+		if withLines {
+			row.Upper = append(row.Upper, GraphLine{From: i, To: row.Column, Color: lane.color})
+		}
+		l.lanes[i].id = ""
+	}
+	// Nothing expects the commit, it is the tip of a branch.
+	if row.Column == -1 {
+		row.Column = l.allocate()
+		row.Color = l.newColor()
+	}
+
+	// The first parent continues the lane of the commit, other parents are
+	// connected to their existing lanes or new ones.
+	for i, parentID := range commit.ParentIDs {
+		if i == 0 {
+			l.lanes[row.Column] = graphLane{id: parentID, color: row.Color}
+			continue
+		}
+
+		lane := -1
+		for j := range l.lanes {
+			if l.lanes[j].id == parentID {
+				lane = j
+				break
+			}
+		}
+		if lane == -1 {
+			lane = l.allocate()
+			l.lanes[lane] = graphLane{id: parentID, color: l.newColor()}
+		}
+		if withLines {
+			row.Lower = append(row.Lower, GraphLine{From: row.Column, To: lane, Color: l.lanes[lane].color})
+		}
+	}
+
+	if withLines {
+		for i, lane := range l.lanes {
+			// Lanes that existed before the commit pass through, including the ones
+			// that a parent of the commit is connected to.
+			if lane.id != "" && (i == row.Column || passed[i]) {
+				row.Lower = append(row.Lower, GraphLine{From: i, To: i, Color: lane.color})
+			}
+		}
+	}
+
+	// Drop free lanes at the end so the graph does not grow wider than needed.
+	for len(l.lanes) > 0 && l.lanes[len(l.lanes)-1].id == "" {
+		l.lanes = l.lanes[:len(l.lanes)-1]
+	}
+	return row
+}
+
+// width returns the number of lanes that the row spans.
+func (r *GraphRow) width() int {
+	width := r.Column + 1
+	for _, lines := range [][]GraphLine{r.Upper, r.Lower} {
+		for _, line := range lines {
+			width = max(width, line.From+1, line.To+1)
+		}
+	}
+	return width
+}
+
+// parseGraphCommit parses a line of "git log" output in the format of
+// graphLogFormat.
+func parseGraphCommit(line string) (*GraphCommit, error) {
+	fields := strings.SplitN(line, "\x00", 6)
+	if len(fields) != 6 {
+		return nil, errors.Newf("malformed line %q", line)
+	}
+
+	sec, err := strconv.ParseInt(fields[4], 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse author time")
+	}
+	return &GraphCommit{
+		ID:        fields[0],
+		ParentIDs: strings.Fields(fields[1]),
+		Author: &git.Signature{
+			Name:  fields[2],
+			Email: fields[3],
+			When:  time.Unix(sec, 0),
+		},
+		Subject: fields[5],
+	}, nil
+}
+
+const graphLogFormat = "--format=%H%x00%P%x00%an%x00%ae%x00%at%x00%s"
+
+// GraphOptions contains options for building the commit graph.
+type GraphOptions struct {
+	// Branches are names of branches to include, all branches are included if
+	// empty. Names of branches that do not exist are ignored.
+	Branches []string
+	// Skip is the number of commits to skip before the page.
+	Skip int
+	// Limit is the maximum number of commits of the page.
+	Limit int
+	// Timeout is the timeout duration of each git command.
+	Timeout time.Duration
+}
+
+// branchHeads returns names of branches that point to each commit.
+func branchHeads(ctx context.Context, repoPath string, timeout time.Duration) (map[string][]string, error) {
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	err := git.NewCommandWithContext(ctx, "for-each-ref", "--format=%(objectname) %(refname:lstrip=2)", "refs/heads/").
+		WithTimeout(timeout).
+		RunInDirWithOptions(repoPath, git.RunInDirOptions{Stdout: stdout, Stderr: stderr})
+	if err != nil {
+		return nil, errors.Newf("run git for-each-ref: %v - %s", err, stderr.String())
+	}
+
+	heads := make(map[string][]string)
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		id, name, ok := strings.Cut(line, " ")
+		if ok {
+			heads[id] = append(heads[id], name)
+		}
+	}
+	return heads, nil
+}
+
+// RepoGraph returns a page of the commit graph of branches in the repository
+// in given path. Commits are ordered by date with every commit shown before
+// its parents.
+//
+// Lanes of the page depend on all commits before it, so commits that are
+// skipped are still laid out but not kept.
+func RepoGraph(ctx context.Context, repoPath string, opts GraphOptions) (*Graph, error) {
+	heads, err := branchHeads(ctx, repoPath, opts.Timeout)
+	if err != nil {
+		return nil, errors.Wrap(err, "get branch heads")
+	}
+
+	// Revisions are passed through stdin so that the number of branches is not
+	// limited by the maximum length of command line.
+	args := []string{"log", "--date-order", graphLogFormat, "--max-count=" + strconv.Itoa(opts.Skip+opts.Limit+1)}
+	stdin := new(bytes.Buffer)
+	if len(opts.Branches) == 0 {
+		args = append(args, "--branches")
+	} else {
+		exists := make(map[string]bool, len(heads))
+		for _, names := range heads {
+			for _, name := range names {
+				exists[name] = true
+			}
+		}
+		for _, branch := range opts.Branches {
+			if exists[branch] {
+				stdin.WriteString("refs/heads/" + branch + "\n")
+			}
+		}
+		if stdin.Len() == 0 {
+			return &Graph{}, nil
+		}
+		args = append(args, "--stdin")
+	}
+
+	type result struct {
+		graph *Graph
+		err   error
+	}
+	stdout, w := io.Pipe()
+	done := make(chan result)
+	go func() {
+		graph := &Graph{
+			Rows: make([]*GraphRow, 0, opts.Limit),
+		}
+		layout := new(graphLayout)
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 0, 4096), 1024*1024)
+		var (
+			n   int
+			err error
+		)
+		for scanner.Scan() {
+			var commit *GraphCommit
+			commit, err = parseGraphCommit(scanner.Text())
+			if err != nil {
+				break
+			}
+
+			if n >= opts.Skip+opts.Limit {
+				graph.HasMore = true
+				break
+			}
+			row := layout.add(commit, n >= opts.Skip)
+			if n >= opts.Skip {
+				commit.Branches = heads[commit.ID]
+				graph.Rows = append(graph.Rows, row)
+				graph.Width = max(graph.Width, row.width())
+			}
+			n++
+		}
+		if err == nil {
+			err = scanner.Err()
+		}
+		// Drain the rest of output so the command won't be blocked on writing.
+		_, _ = io.Copy(io.Discard, stdout)
+		done <- result{graph: graph, err: err}
+	}()
+
+	stderr := new(bytes.Buffer)
+	err = git.NewCommandWithContext(ctx, args...).
+		WithTimeout(opts.Timeout).
+		RunInDirWithOptions(repoPath, git.RunInDirOptions{Stdin: stdin, Stdout: w, Stderr: stderr})
+	_ = w.Close() // Close writer to exit parsing goroutine
+	res := <-done
+	if err != nil {
+		return nil, errors.Newf("run git log: %v - %s", err, stderr.String())
+	} else if res.err != nil {
+		return nil, errors.Wrap(res.err, "parse log")
+	}
+	return res.graph, nil
+}
@@ -0,0 +1,100 @@
+package gitx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gogs/git-module"
+)
+
+func TestGraphLayout(t *testing.T) {
+	// "topic" branches off "a", "main" merges "feature" that also branches off "a".
+	commits := []*GraphCommit{
+		{ID: "topic", ParentIDs: []string{"a"}},
+		{ID: "main", ParentIDs: []string{"a", "feature"}},
+		{ID: "feature", ParentIDs: []string{"a"}},
+		{ID: "a"},
+	}
+	want := []*GraphRow{
+		{
+			Column: 0,
+			Color:  0,
+			Lower:  []GraphLine{{From: 0, To: 0, Color: 0}},
+		},
+		{
+			Column: 1,
+			Color:  1,
+			Upper:  []GraphLine{{From: 0, To: 0, Color: 0}},
+			Lower: []GraphLine{
+				{From: 1, To: 2, Color: 2},
+				{From: 0, To: 0, Color: 0},
+				{From: 1, To: 1, Color: 1},
+			},
+		},
+		{
+			Column: 2,
+			Color:  2,
+			Upper: []GraphLine{
+				{From: 0, To: 0, Color: 0},
+				{From: 1, To: 1, Color: 1},
+				{From: 2, To: 2, Color: 2},
+			},
+			Lower: []GraphLine{
+				{From: 0, To: 0, Color: 0},
+				{From: 1, To: 1, Color: 1},
+				{From: 2, To: 2, Color: 2},
+			},
+		},
+		{
+			Column: 0,
+			Color:  0,
+			Upper: []GraphLine{
+				{From: 0, To: 0, Color: 0},
+				{From: 1, To: 0, Color: 1},
+				{From: 2, To: 0, Color: 2},
+			},
+		},
+	}
+
+	layout := new(graphLayout)
+	for i, commit := range commits {
+		want[i].Commit = commit
+		assert.Equal(t, want[i], layout.add(commit, true), "row %d", i)
+	}
+	assert.Empty(t, layout.lanes)
+
+	assert.Equal(t, 3, want[1].width())
+
+	t.Run("reuse free lanes", func(t *testing.T) {
+		layout := new(graphLayout)
+		layout.add(&GraphCommit{ID: "x", ParentIDs: []string{"a"}}, false)
+		layout.add(&GraphCommit{ID: "y", ParentIDs: []string{"b"}}, false)
+		layout.add(&GraphCommit{ID: "a"}, false)
+		row := layout.add(&GraphCommit{ID: "z", ParentIDs: []string{"b"}}, false)
+		assert.Equal(t, 0, row.Column)
+		assert.Equal(t, 2, row.Color)
+	})
+}
+
+func TestParseGraphCommit(t *testing.T) {
+	got, err := parseGraphCommit("8a4862c2525d0a297eeb6b321923b086fa4d3732\x0077a44326a1bb502f19b62f665b72aba632bac5f3 1f7a7a472abf3dd9643fd615f6da379c4acb3e3a\x00Bob\x00bob@example.com\x001577923200\x00Merge branch 'feature'")
+	require.NoError(t, err)
+
+	want := &GraphCommit{
+		ID:        "8a4862c2525d0a297eeb6b321923b086fa4d3732",
+		ParentIDs: []string{"77a44326a1bb502f19b62f665b72aba632bac5f3", "1f7a7a472abf3dd9643fd615f6da379c4acb3e3a"},
+		Author: &git.Signature{
+			Name:  "Bob",
+			Email: "bob@example.com",
+			When:  time.Unix(1577923200, 0),
+		},
+		Subject: "Merge branch 'feature'",
+	}
+	assert.Equal(t, want, got)
+
+	_, err = parseGraphCommit("8a4862c2525d0a297eeb6b321923b086fa4d3732")
+	assert.Error(t, err)
+}
@@ -0,0 +1,169 @@
+package gitx
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gogs/git-module"
+)
+
+const (
+	// SignatureTypeGPG is the type of OpenPGP signatures.
+	SignatureTypeGPG = "gpg"
+	// SignatureTypeSSH is the type of SSH signatures.
+	SignatureTypeSSH = "ssh"
+
+	pgpSignatureBegin = "-----BEGIN PGP SIGNATURE-----"
+	sshSignatureBegin = "-----BEGIN SSH SIGNATURE-----"
+)
+
+// ObjectSignature contains the signature of a signed Git object and the payload
+// that the signature is computed for.
+type ObjectSignature struct {
+	// The armored signature.
+	Signature string
+	// The content of the object without the signature.
+	Payload string
+}
+
+// Type returns the type of the signature, i.e. SignatureTypeGPG or
+// SignatureTypeSSH. It returns an empty string when the type is unknown.
+func (s *ObjectSignature) Type() string {
+	switch {
+	case strings.HasPrefix(s.Signature, pgpSignatureBegin):
+		return SignatureTypeGPG
+	case strings.HasPrefix(s.Signature, sshSignatureBegin):
+		return SignatureTypeSSH
+	}
+	return ""
+}
+
+// commitSignatureHeaders are headers of commit signatures keyed by the length
+// of object IDs of the hash algorithm that the signature is made for.
+var commitSignatureHeaders = map[int]string{
+	40: "gpgsig",
+	64: "gpgsig-sha256",
+}
+
+// ParseCommitSignature extracts the signature from the raw content of the
+// commit object of given ID, i.e. the "gpgsig" header in SHA-1 repositories or
+// the "gpgsig-sha256" header in SHA-256 repositories. Like Git, signatures made
+// for the other hash algorithm are excluded from the payload. It returns nil
+// when the commit is not signed.
+func ParseCommitSignature(commitID string, data []byte) *ObjectSignature {
+	header, ok := commitSignatureHeaders[len(commitID)]
+	if !ok {
+		header = commitSignatureHeaders[40]
+	}
+	header += " "
+
+	var signature, payload bytes.Buffer
+	inHeaders := true
+	inSignature := false
+	inOtherSignature := false
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if !inHeaders {
+			payload.Write(line)
+			continue
+		}
+
+		// Continuation lines of a multi-line header are prefixed by a single space.
+		isContinuation := bytes.HasPrefix(line, []byte(" "))
+		switch {
+		case inSignature && isContinuation:
+			signature.Write(line[1:])
+			continue
+		case inOtherSignature && isContinuation:
+			continue
+		case bytes.HasPrefix(line, []byte(header)):
+			inSignature = true
+			signature.Write(line[len(header):])
+			continue
+		case bytes.HasPrefix(line, []byte("gpgsig")):
+			inSignature = false
+			inOtherSignature = true
+			continue
+		case bytes.Equal(line, []byte("\n")):
+			inHeaders = false
+		}
+		inSignature = false
+		inOtherSignature = false
+		payload.Write(line)
+	}
+
+	if signature.Len() == 0 {
+		return nil
+	}
+	return &ObjectSignature{
+		Signature: signature.String(),
+		Payload:   payload.String(),
+	}
+}
+
+// ParseTagSignature extracts the signature from the raw content of an annotated
+// tag object, which is appended to the end of the tag message. It returns nil
+// when the tag is not signed.
+func ParseTagSignature(data []byte) *ObjectSignature {
+	idx := -1
+	for _, begin := range []string{pgpSignatureBegin, sshSignatureBegin} {
+		i := bytes.LastIndex(data, []byte("\n"+begin))
+		if i > idx {
+			idx = i
+		}
+	}
+	if idx == -1 {
+		return nil
+	}
+
+	idx++ // Skip the leading newline
+	return &ObjectSignature{
+		Signature: string(data[idx:]),
+		Payload:   string(data[:idx]),
+	}
+}
+
+// CommitSignature returns the signature of the given commit in the repository.
+// It returns nil when the commit is not signed.
+func CommitSignature(repoPath, commitID string) (*ObjectSignature, error) {
+	data, err := git.NewCommand("cat-file", "commit", commitID).RunInDir(repoPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "cat-file commit")
+	}
+	return ParseCommitSignature(commitID, data), nil
+}
+
+// CommitSignatures returns signatures of given commits in the repository with a
+// single "git cat-file --batch" process, keyed by commit IDs. Unsigned and
+// missing commits are not included.
+func CommitSignatures(repoPath string, commitIDs []string, timeout time.Duration) (map[string]*ObjectSignature, error) {
+	sigs := make(map[string]*ObjectSignature, len(commitIDs))
+	err := readObjects(repoPath, "commit", commitIDs, timeout, func(id string, content []byte) error {
+		if sig := ParseCommitSignature(id, content); sig != nil {
+			sigs[id] = sig
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "read commits")
+	}
+	return sigs, nil
+}
+
+// TagSignature returns the signature of the given tag in the repository. It
+// returns nil when the tag is a lightweight tag or not signed.
+func TagSignature(repoPath, tagName string) (*ObjectSignature, error) {
+	typ, err := git.NewCommand("cat-file", "-t", "refs/tags/"+tagName).RunInDir(repoPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "get object type")
+	} else if string(bytes.TrimSpace(typ)) != "tag" {
+		return nil, nil
+	}
+
+	data, err := git.NewCommand("cat-file", "tag", "refs/tags/"+tagName).RunInDir(repoPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "cat-file tag")
+	}
+	return ParseTagSignature(data), nil
+}
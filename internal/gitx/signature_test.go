@@ -0,0 +1,175 @@
+package gitx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCommitSignature(t *testing.T) {
+	const (
+		sha1ID   = "8a4862c2525d0a297eeb6b321923b086fa4d3732"
+		sha256ID = "ef797c8118f02dfb649607dd5d3f8c7623048c9c063d532cc95c5ed7a898a64f"
+	)
+	tests := []struct {
+		name     string
+		commitID string
+		data     string
+		expSig   *ObjectSignature
+	}{
+		{
+			name:     "unsigned",
+			commitID: sha1ID,
+			data: `tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904
+author Alice <alice@example.com> 1700000000 +0000
+committer Alice <alice@example.com> 1700000000 +0000
+
+Initial commit
+`,
+			expSig: nil,
+		},
+		{
+			name:     "signed",
+			commitID: sha1ID,
+			data: `tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904
+author Alice <alice@example.com> 1700000000 +0000
+committer Alice <alice@example.com> 1700000000 +0000
+gpgsig -----BEGIN SSH SIGNATURE-----
+ U1NIU0lHAAAAAQ==
+ -----END SSH SIGNATURE-----
+
+Initial commit
+
+ indented body line
+`,
+			expSig: &ObjectSignature{
+				Signature: `-----BEGIN SSH SIGNATURE-----
+U1NIU0lHAAAAAQ==
+-----END SSH SIGNATURE-----
+`,
+				Payload: `tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904
+author Alice <alice@example.com> 1700000000 +0000
+committer Alice <alice@example.com> 1700000000 +0000
+
+Initial commit
+
+ indented body line
+`,
+			},
+		},
+		{
+			name:     "signed for both hash algorithms in SHA-1",
+			commitID: sha1ID,
+			data: `tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904
+author Alice <alice@example.com> 1700000000 +0000
+committer Alice <alice@example.com> 1700000000 +0000
+gpgsig -----BEGIN SSH SIGNATURE-----
+ U1NIU0lHAAAAAQ==
+ -----END SSH SIGNATURE-----
+gpgsig-sha256 -----BEGIN SSH SIGNATURE-----
+ U1NIU0lHAAAAAg==
+ -----END SSH SIGNATURE-----
+
+Initial commit
+`,
+			expSig: &ObjectSignature{
+				Signature: `-----BEGIN SSH SIGNATURE-----
+U1NIU0lHAAAAAQ==
+-----END SSH SIGNATURE-----
+`,
+				Payload: `tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904
+author Alice <alice@example.com> 1700000000 +0000
+committer Alice <alice@example.com> 1700000000 +0000
+
+Initial commit
+`,
+			},
+		},
+		{
+			name:     "signed for both hash algorithms in SHA-256",
+			commitID: sha256ID,
+			data: `tree 6ef19b41225c5369f1c104d45d8d85efa9b057b53b14b4b9b939dd74decc5321
+author Alice <alice@example.com> 1700000000 +0000
+committer Alice <alice@example.com> 1700000000 +0000
+gpgsig -----BEGIN SSH SIGNATURE-----
+ U1NIU0lHAAAAAQ==
+ -----END SSH SIGNATURE-----
+gpgsig-sha256 -----BEGIN SSH SIGNATURE-----
+ U1NIU0lHAAAAAg==
+ -----END SSH SIGNATURE-----
+
+Initial commit
+`,
+			expSig: &ObjectSignature{
+				Signature: `-----BEGIN SSH SIGNATURE-----
+U1NIU0lHAAAAAg==
+-----END SSH SIGNATURE-----
+`,
+				Payload: `tree 6ef19b41225c5369f1c104d45d8d85efa9b057b53b14b4b9b939dd74decc5321
+author Alice <alice@example.com> 1700000000 +0000
+committer Alice <alice@example.com> 1700000000 +0000
+
+Initial commit
+`,
+			},
+		},
+		{
+			name:     "only signed for the other hash algorithm",
+			commitID: sha256ID,
+			data: `tree 6ef19b41225c5369f1c104d45d8d85efa9b057b53b14b4b9b939dd74decc5321
+author Alice <alice@example.com> 1700000000 +0000
+committer Alice <alice@example.com> 1700000000 +0000
+gpgsig -----BEGIN SSH SIGNATURE-----
+ U1NIU0lHAAAAAQ==
+ -----END SSH SIGNATURE-----
+
+Initial commit
+`,
+			expSig: nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := ParseCommitSignature(test.commitID, []byte(test.data))
+			assert.Equal(t, test.expSig, got)
+			if got != nil {
+				assert.Equal(t, SignatureTypeSSH, got.Type())
+			}
+		})
+	}
+}
+
+func TestParseTagSignature(t *testing.T) {
+	data := `object 4b825dc642cb6eb9a060e54bf8d69288fbee4904
+type commit
+tag v1.0.0
+tagger Alice <alice@example.com> 1700000000 +0000
+
+Release v1.0.0
+-----BEGIN PGP SIGNATURE-----
+
+iQEzBAABCAAdFiEE
+-----END PGP SIGNATURE-----
+`
+	got := ParseTagSignature([]byte(data))
+	assert.Equal(t,
+		&ObjectSignature{
+			Signature: `-----BEGIN PGP SIGNATURE-----
+
+iQEzBAABCAAdFiEE
+-----END PGP SIGNATURE-----
+`,
+			Payload: `object 4b825dc642cb6eb9a060e54bf8d69288fbee4904
+type commit
+tag v1.0.0
+tagger Alice <alice@example.com> 1700000000 +0000
+
+Release v1.0.0
+`,
+		},
+		got,
+	)
+	assert.Equal(t, SignatureTypeGPG, got.Type())
+
+	assert.Nil(t, ParseTagSignature([]byte("object abc\ntype commit\ntag v1\n\nmessage\n")))
+}
@@ -0,0 +1,373 @@
+package indexer
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/osx"
+)
+
+const (
+	builtinSnapshotFile = "snapshot.gob"
+	builtinJournalFile  = "journal.jsonl"
+
+	// builtinCompactThreshold is the number of journal entries that triggers
+	// rewriting the snapshot.
+	builtinCompactThreshold = 1000
+)
+
+// fieldBoosts is the weight of a term occurrence in each field.
+var fieldBoosts = map[Field]int{
+	FieldTitle:   3,
+	FieldBody:    2,
+	FieldComment: 1,
+}
+
+// builtinDoc is a document stored in the built-in index.
+type builtinDoc struct {
+	RepoID int64
+	// Terms is the number of occurrences of each term in each field.
+	Terms map[Field]map[string]int
+}
+
+func newBuiltinDoc(doc *IssueDocument) *builtinDoc {
+	d := &builtinDoc{
+		RepoID: doc.RepoID,
+		Terms:  make(map[Field]map[string]int, 3),
+	}
+	count := func(field Field, text string) {
+		for _, term := range tokenize(text) {
+			if d.Terms[field] == nil {
+				d.Terms[field] = make(map[string]int)
+			}
+			d.Terms[field][term]++
+		}
+	}
+	count(FieldTitle, doc.Title)
+	count(FieldBody, doc.Body)
+	for _, comment := range doc.Comments {
+		count(FieldComment, comment)
+	}
+	return d
+}
+
+// score returns the weighted number of occurrences of the term in given field,
+// or in all fields if the field is empty.
+func (d *builtinDoc) score(term string, field Field) int {
+	score := 0
+	for f, terms := range d.Terms {
+		if field != "" && f != field {
+			continue
+		}
+		score += terms[term] * fieldBoosts[f]
+	}
+	return score
+}
+
+// builtinJournalEntry is an operation recorded in the journal since the last
+// snapshot.
+type builtinJournalEntry struct {
+	Op  string      `json:"op"`
+	ID  int64       `json:"id"`
+	Doc *builtinDoc `json:"doc,omitempty"`
+}
+
+const (
+	builtinOpIndex  = "index"
+	builtinOpDelete = "delete"
+)
+
+// builtinIndexer is an inverted index kept in memory and persisted on the file
+// system as a snapshot with a journal of changes made after it.
+type builtinIndexer struct {
+	lock sync.RWMutex
+	path string
+
+	docs     map[int64]*builtinDoc
+	postings map[string]map[int64]struct{} // Term -> document IDs
+
+	journal        *os.File
+	journalEntries int
+}
+
+var _ IssueIndexer = (*builtinIndexer)(nil)
+
+func openBuiltinIndexer(path string) (_ *builtinIndexer, err error) {
+	if err = os.MkdirAll(path, os.ModePerm); err != nil {
+		return nil, errors.Wrap(err, "create directory")
+	}
+
+	idx := &builtinIndexer{
+		path:     path,
+		docs:     make(map[int64]*builtinDoc),
+		postings: make(map[string]map[int64]struct{}),
+	}
+
+	snapshotPath := filepath.Join(path, builtinSnapshotFile)
+	if osx.IsFile(snapshotPath) {
+		f, err := os.Open(snapshotPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "open snapshot")
+		}
+		err = gob.NewDecoder(f).Decode(&idx.docs)
+		_ = f.Close()
+		if err != nil {
+			return nil, errors.Wrap(err, "decode snapshot")
+		}
+	}
+	for id, doc := range idx.docs {
+		idx.addPostings(id, doc)
+	}
+
+	journalPath := filepath.Join(path, builtinJournalFile)
+	if osx.IsFile(journalPath) {
+		f, err := os.Open(journalPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "open journal")
+		}
+		decoder := json.NewDecoder(f)
+		for {
+			var entry builtinJournalEntry
+			if err = decoder.Decode(&entry); err != nil {
+				if err != io.EOF {
+					// The last entry may be incomplete if the process was killed while
+					// writing it, the issue will be indexed again on next update.
+					log.Warn("Failed to decode journal of issue indexer, the rest is skipped: %v", err)
+				}
+				break
+			}
+			idx.apply(&entry)
+			idx.journalEntries++
+		}
+		_ = f.Close()
+	}
+
+	idx.journal, err = os.OpenFile(journalPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, errors.Wrap(err, "open journal for writing")
+	}
+
+	if idx.journalEntries >= builtinCompactThreshold {
+		if err = idx.compact(); err != nil {
+			return nil, errors.Wrap(err, "compact")
+		}
+	}
+	return idx, nil
+}
+
+func (idx *builtinIndexer) addPostings(id int64, doc *builtinDoc) {
+	for _, terms := range doc.Terms {
+		for term := range terms {
+			if idx.postings[term] == nil {
+				idx.postings[term] = make(map[int64]struct{})
+			}
+			idx.postings[term][id] = struct{}{}
+		}
+	}
+}
+
+func (idx *builtinIndexer) removeDoc(id int64) {
+	doc, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+
+	for _, terms := range doc.Terms {
+		for term := range terms {
+			delete(idx.postings[term], id)
+			if len(idx.postings[term]) == 0 {
+				delete(idx.postings, term)
+			}
+		}
+	}
+	delete(idx.docs, id)
+}
+
+// apply applies the journal entry to the in-memory index.
+func (idx *builtinIndexer) apply(entry *builtinJournalEntry) {
+	idx.removeDoc(entry.ID)
+	if entry.Op == builtinOpIndex && entry.Doc != nil {
+		idx.docs[entry.ID] = entry.Doc
+		idx.addPostings(entry.ID, entry.Doc)
+	}
+}
+
+// write applies and records journal entries, the snapshot is rewritten when the
+// journal grows too large.
+func (idx *builtinIndexer) write(entries []*builtinJournalEntry) error {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	encoder := json.NewEncoder(idx.journal)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return errors.Wrap(err, "write journal")
+		}
+		idx.apply(entry)
+		idx.journalEntries++
+	}
+
+	if idx.journalEntries >= builtinCompactThreshold {
+		return idx.compact()
+	}
+	return nil
+}
+
+// compact writes all documents to a new snapshot and truncates the journal.
+// The caller must hold the write lock.
+func (idx *builtinIndexer) compact() error {
+	tmpPath := filepath.Join(idx.path, builtinSnapshotFile+".tmp")
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return errors.Wrap(err, "create snapshot")
+	}
+	if err = gob.NewEncoder(f).Encode(idx.docs); err != nil {
+		_ = f.Close()
+		return errors.Wrap(err, "encode snapshot")
+	}
+	if err = f.Sync(); err != nil {
+		_ = f.Close()
+		return errors.Wrap(err, "sync snapshot")
+	}
+	if err = f.Close(); err != nil {
+		return errors.Wrap(err, "close snapshot")
+	}
+	if err = os.Rename(tmpPath, filepath.Join(idx.path, builtinSnapshotFile)); err != nil {
+		return errors.Wrap(err, "rename snapshot")
+	}
+
+	if err = idx.journal.Truncate(0); err != nil {
+		return errors.Wrap(err, "truncate journal")
+	}
+	idx.journalEntries = 0
+	return nil
+}
+
+func (idx *builtinIndexer) Index(docs ...*IssueDocument) error {
+	entries := make([]*builtinJournalEntry, len(docs))
+	for i, doc := range docs {
+		entries[i] = &builtinJournalEntry{
+			Op:  builtinOpIndex,
+			ID:  doc.ID,
+			Doc: newBuiltinDoc(doc),
+		}
+	}
+	return idx.write(entries)
+}
+
+func (idx *builtinIndexer) Delete(ids ...int64) error {
+	entries := make([]*builtinJournalEntry, len(ids))
+	for i, id := range ids {
+		entries[i] = &builtinJournalEntry{
+			Op: builtinOpDelete,
+			ID: id,
+		}
+	}
+	return idx.write(entries)
+}
+
+func (idx *builtinIndexer) Search(opts SearchOptions) ([]int64, error) {
+	terms := parseKeyword(opts.Keyword)
+	if len(terms) == 0 {
+		return []int64{}, nil
+	}
+
+	var repoIDs map[int64]bool
+	if len(opts.RepoIDs) > 0 {
+		repoIDs = make(map[int64]bool, len(opts.RepoIDs))
+		for _, id := range opts.RepoIDs {
+			repoIDs[id] = true
+		}
+	}
+
+	idx.lock.RLock()
+	defer idx.lock.RUnlock()
+
+	// Every term must be matched, the score of a document is the sum of scores of
+	// all terms.
+	var scores map[int64]int
+	for i, qt := range terms {
+		// Only prefix terms need to scan the vocabulary, exact terms are looked up
+		// directly.
+		indexTerms := []string{qt.text}
+		if qt.isPrefix {
+			indexTerms = indexTerms[:0]
+			for term := range idx.postings {
+				if strings.HasPrefix(term, qt.text) {
+					indexTerms = append(indexTerms, term)
+				}
+			}
+		}
+
+		matched := make(map[int64]int)
+		for _, term := range indexTerms {
+			for id := range idx.postings[term] {
+				if i > 0 {
+					if _, ok := scores[id]; !ok {
+						continue
+					}
+				}
+
+				doc := idx.docs[id]
+				if repoIDs != nil && !repoIDs[doc.RepoID] {
+					continue
+				}
+				if score := doc.score(term, qt.field); score > 0 {
+					matched[id] += score
+				}
+			}
+		}
+
+		for id, score := range scores {
+			if _, ok := matched[id]; ok {
+				matched[id] += score
+			}
+		}
+		scores = matched
+		if len(scores) == 0 {
+			break
+		}
+	}
+
+	ids := make([]int64, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] > ids[j]
+	})
+	if opts.Limit > 0 && len(ids) > opts.Limit {
+		ids = ids[:opts.Limit]
+	}
+	return ids, nil
+}
+
+func (idx *builtinIndexer) IsEmpty() bool {
+	idx.lock.RLock()
+	defer idx.lock.RUnlock()
+	return len(idx.docs) == 0
+}
+
+func (idx *builtinIndexer) Close() error {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	if idx.journalEntries > 0 {
+		if err := idx.compact(); err != nil {
+			return err
+		}
+	}
+	return idx.journal.Close()
+}
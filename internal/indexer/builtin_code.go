@@ -0,0 +1,304 @@
+package indexer
+
+import (
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+	log "unknwon.dev/clog/v2"
+)
+
+// trigram is three consecutive bytes of lowercase content.
+type trigram [3]byte
+
+// trigramsOf returns the set of trigrams of the lowercase text.
+func trigramsOf(text string) map[trigram]struct{} {
+	trigrams := make(map[trigram]struct{})
+	for i := 0; i+3 <= len(text); i++ {
+		trigrams[trigram{text[i], text[i+1], text[i+2]}] = struct{}{}
+	}
+	return trigrams
+}
+
+// builtinCodeRepo is the persisted index of a repository.
+type builtinCodeRepo struct {
+	CommitID string
+	Docs     []*CodeDocument
+
+	postings map[trigram][]int // Trigram -> indexes of documents
+}
+
+func (r *builtinCodeRepo) buildPostings() {
+	r.postings = make(map[trigram][]int)
+	for i, doc := range r.Docs {
+		for t := range trigramsOf(strings.ToLower(doc.Content)) {
+			r.postings[t] = append(r.postings[t], i)
+		}
+	}
+}
+
+// candidates returns indexes of documents that contain all trigrams of given
+// literals, or nil if all documents are candidates.
+func (r *builtinCodeRepo) candidates(literals []string) []int {
+	var (
+		result  []int
+		checked bool
+	)
+	for _, literal := range literals {
+		for t := range trigramsOf(literal) {
+			if !checked {
+				result = append([]int{}, r.postings[t]...)
+				checked = true
+				continue
+			}
+			result = intersectSorted(result, r.postings[t])
+		}
+	}
+	if !checked {
+		return nil
+	} else if result == nil {
+		return []int{}
+	}
+	return result
+}
+
+// intersectSorted returns the intersection of two sorted slices.
+func intersectSorted(a, b []int) []int {
+	result := a[:0]
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			result = append(result, a[i])
+			i++
+			j++
+		}
+	}
+	return result
+}
+
+// builtinCodeIndexer is a trigram index kept in memory and persisted on the
+// file system as one file per repository.
+type builtinCodeIndexer struct {
+	lock  sync.RWMutex
+	path  string
+	repos map[int64]*builtinCodeRepo
+}
+
+var _ CodeIndexer = (*builtinCodeIndexer)(nil)
+
+func openBuiltinCodeIndexer(path string) (*builtinCodeIndexer, error) {
+	if err := os.MkdirAll(path, os.ModePerm); err != nil {
+		return nil, errors.Wrap(err, "create directory")
+	}
+
+	idx := &builtinCodeIndexer{
+		path:  path,
+		repos: make(map[int64]*builtinCodeRepo),
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "read directory")
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".gob" {
+			continue
+		}
+		repoID, err := strconv.ParseInt(strings.TrimSuffix(name, ".gob"), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		repo, err := idx.load(filepath.Join(path, name))
+		if err != nil {
+			// The repository will be indexed again on next update.
+			log.Warn("Failed to load code index of repository [%d], it is skipped: %v", repoID, err)
+			continue
+		}
+		idx.repos[repoID] = repo
+	}
+	return idx, nil
+}
+
+func (*builtinCodeIndexer) load(path string) (*builtinCodeRepo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "open")
+	}
+	defer func() { _ = f.Close() }()
+
+	repo := new(builtinCodeRepo)
+	if err = gob.NewDecoder(f).Decode(repo); err != nil {
+		return nil, errors.Wrap(err, "decode")
+	}
+	repo.buildPostings()
+	return repo, nil
+}
+
+func (idx *builtinCodeIndexer) repoPath(repoID int64) string {
+	return filepath.Join(idx.path, strconv.FormatInt(repoID, 10)+".gob")
+}
+
+func (idx *builtinCodeIndexer) Index(repoID int64, commitID string, docs []*CodeDocument) error {
+	repo := &builtinCodeRepo{
+		CommitID: commitID,
+		Docs:     docs,
+	}
+	repo.buildPostings()
+
+	path := idx.repoPath(repoID)
+	tmpPath := path + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return errors.Wrap(err, "create")
+	}
+	if err = gob.NewEncoder(f).Encode(repo); err != nil {
+		_ = f.Close()
+		return errors.Wrap(err, "encode")
+	}
+	if err = f.Close(); err != nil {
+		return errors.Wrap(err, "close")
+	}
+
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	if err = os.Rename(tmpPath, path); err != nil {
+		return errors.Wrap(err, "rename")
+	}
+	idx.repos[repoID] = repo
+	return nil
+}
+
+func (idx *builtinCodeIndexer) Delete(repoID int64) error {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	err := os.Remove(idx.repoPath(repoID))
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "remove")
+	}
+	delete(idx.repos, repoID)
+	return nil
+}
+
+func (idx *builtinCodeIndexer) IndexedCommitID(repoID int64) string {
+	idx.lock.RLock()
+	defer idx.lock.RUnlock()
+
+	repo, ok := idx.repos[repoID]
+	if !ok {
+		return ""
+	}
+	return repo.CommitID
+}
+
+func (idx *builtinCodeIndexer) RepoIDs() []int64 {
+	idx.lock.RLock()
+	defer idx.lock.RUnlock()
+
+	ids := make([]int64, 0, len(idx.repos))
+	for id := range idx.repos {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func (idx *builtinCodeIndexer) Search(opts CodeSearchOptions) (*CodeSearchResult, error) {
+	q, err := parseCodeQuery(opts.Keyword, opts.IsRegexp)
+	if err != nil {
+		return nil, err
+	} else if q == nil {
+		return &CodeSearchResult{}, nil
+	}
+
+	idx.lock.RLock()
+	defer idx.lock.RUnlock()
+
+	repoIDs := opts.RepoIDs
+	if len(repoIDs) == 0 {
+		repoIDs = make([]int64, 0, len(idx.repos))
+		for id := range idx.repos {
+			repoIDs = append(repoIDs, id)
+		}
+	}
+
+	var matches []*CodeMatch
+	for _, repoID := range repoIDs {
+		repo, ok := idx.repos[repoID]
+		if !ok {
+			continue
+		}
+
+		docIndexes := repo.candidates(q.literals)
+		if docIndexes == nil {
+			docIndexes = make([]int, len(repo.Docs))
+			for i := range docIndexes {
+				docIndexes[i] = i
+			}
+		}
+		for _, i := range docIndexes {
+			doc := repo.Docs[i]
+			if !matchCodePath(opts.Path, doc.Path) ||
+				(opts.Language != "" && !strings.EqualFold(opts.Language, doc.Language)) {
+				continue
+			}
+
+			lines, numMatches := q.matchLines(doc.Content)
+			if numMatches == 0 {
+				continue
+			}
+			matches = append(matches, &CodeMatch{
+				RepoID:     repoID,
+				Path:       doc.Path,
+				Language:   doc.Language,
+				NumMatches: numMatches,
+				Lines:      lines,
+			})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].NumMatches != matches[j].NumMatches {
+			return matches[i].NumMatches > matches[j].NumMatches
+		} else if matches[i].RepoID != matches[j].RepoID {
+			return matches[i].RepoID < matches[j].RepoID
+		}
+		return matches[i].Path < matches[j].Path
+	})
+
+	result := &CodeSearchResult{Total: len(matches)}
+	if opts.PageSize > 0 {
+		page := opts.Page
+		if page <= 0 {
+			page = 1
+		}
+		start := (page - 1) * opts.PageSize
+		if start >= len(matches) {
+			return result, nil
+		}
+		end := start + opts.PageSize
+		if end > len(matches) {
+			end = len(matches)
+		}
+		matches = matches[start:end]
+	}
+	result.Matches = matches
+	return result, nil
+}
+
+func (*builtinCodeIndexer) Close() error {
+	// All changes are persisted as soon as they are made.
+	return nil
+}
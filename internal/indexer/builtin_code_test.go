@@ -0,0 +1,180 @@
+package indexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchCodePath(t *testing.T) {
+	tests := []struct {
+		filter   string
+		filePath string
+		want     bool
+	}{
+		{filter: "", filePath: "main.go", want: true},
+		{filter: "cmd", filePath: "cmd/gogs/main.go", want: true},
+		{filter: "cmd/", filePath: "cmd/gogs/main.go", want: true},
+		{filter: "cmd", filePath: "cmdx/main.go", want: false},
+		{filter: "*.go", filePath: "cmd/gogs/main.go", want: true},
+		{filter: "*.go", filePath: "README.md", want: false},
+		{filter: "cmd/*/main.go", filePath: "cmd/gogs/main.go", want: true},
+		{filter: "cmd/*.go", filePath: "cmd/gogs/main.go", want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.filter+" "+test.filePath, func(t *testing.T) {
+			assert.Equal(t, test.want, matchCodePath(test.filter, test.filePath))
+		})
+	}
+}
+
+func TestBuiltinCodeIndexer(t *testing.T) {
+	path := t.TempDir()
+	idx, err := openBuiltinCodeIndexer(path)
+	require.NoError(t, err)
+	assert.Empty(t, idx.RepoIDs())
+
+	err = idx.Index(1, "c1", []*CodeDocument{
+		{Path: "main.go", Language: "Go", Content: "package main\n\nfunc main() {\n\tprintln(\"Hello\")\n}\n"},
+		{Path: "docs/README.md", Language: "Markdown", Content: "# Hello\n\nSay hello to the world, hello!\n"},
+	})
+	require.NoError(t, err)
+	err = idx.Index(2, "c2", []*CodeDocument{
+		{Path: "hello.py", Language: "Python", Content: "def greet():\n    print('hi')\n"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, idx.RepoIDs())
+	assert.Equal(t, "c1", idx.IndexedCommitID(1))
+	assert.Empty(t, idx.IndexedCommitID(3))
+
+	search := func(t *testing.T, idx *builtinCodeIndexer, opts CodeSearchOptions) *CodeSearchResult {
+		result, err := idx.Search(opts)
+		require.NoError(t, err)
+		return result
+	}
+	paths := func(result *CodeSearchResult) []string {
+		paths := make([]string, len(result.Matches))
+		for i := range result.Matches {
+			paths[i] = result.Matches[i].Path
+		}
+		return paths
+	}
+
+	tests := []struct {
+		name      string
+		opts      CodeSearchOptions
+		wantTotal int
+		wantPaths []string
+	}{
+		{
+			name:      "empty keyword",
+			opts:      CodeSearchOptions{Keyword: " "},
+			wantTotal: 0,
+			wantPaths: []string{},
+		},
+		{
+			name:      "exact ranked by matches",
+			opts:      CodeSearchOptions{Keyword: "HELLO"},
+			wantTotal: 2,
+			wantPaths: []string{"docs/README.md", "main.go"},
+		},
+		{
+			name:      "short keyword",
+			opts:      CodeSearchOptions{Keyword: "hi"},
+			wantTotal: 1,
+			wantPaths: []string{"hello.py"},
+		},
+		{
+			name:      "regexp",
+			opts:      CodeSearchOptions{Keyword: `func \w+\(\)`, IsRegexp: true},
+			wantTotal: 1,
+			wantPaths: []string{"main.go"},
+		},
+		{
+			name:      "regexp is case-sensitive",
+			opts:      CodeSearchOptions{Keyword: "Say", IsRegexp: true},
+			wantTotal: 1,
+			wantPaths: []string{"docs/README.md"},
+		},
+		{
+			name:      "path",
+			opts:      CodeSearchOptions{Keyword: "hello", Path: "docs"},
+			wantTotal: 1,
+			wantPaths: []string{"docs/README.md"},
+		},
+		{
+			name:      "language",
+			opts:      CodeSearchOptions{Keyword: "hello", Language: "go"},
+			wantTotal: 1,
+			wantPaths: []string{"main.go"},
+		},
+		{
+			name:      "repositories",
+			opts:      CodeSearchOptions{Keyword: "print", RepoIDs: []int64{2}},
+			wantTotal: 1,
+			wantPaths: []string{"hello.py"},
+		},
+		{
+			name:      "paging",
+			opts:      CodeSearchOptions{Keyword: "hello", Page: 2, PageSize: 1},
+			wantTotal: 2,
+			wantPaths: []string{"main.go"},
+		},
+		{
+			name:      "no match",
+			opts:      CodeSearchOptions{Keyword: "nothing"},
+			wantTotal: 0,
+			wantPaths: []string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := search(t, idx, test.opts)
+			assert.Equal(t, test.wantTotal, result.Total)
+			assert.Equal(t, test.wantPaths, paths(result))
+		})
+	}
+
+	t.Run("invalid regexp", func(t *testing.T) {
+		_, err := idx.Search(CodeSearchOptions{Keyword: "(", IsRegexp: true})
+		assert.True(t, IsErrInvalidRegexp(err))
+	})
+
+	t.Run("matched lines", func(t *testing.T) {
+		result := search(t, idx, CodeSearchOptions{Keyword: "hello", Path: "docs"})
+		require.Len(t, result.Matches, 1)
+		match := result.Matches[0]
+		assert.Equal(t, 3, match.NumMatches)
+		want := []*CodeMatchLine{
+			{Number: 1, Content: "# Hello", Ranges: [][2]int{{2, 7}}},
+			{Number: 3, Content: "Say hello to the world, hello!", Ranges: [][2]int{{4, 9}, {24, 29}}},
+		}
+		assert.Equal(t, want, match.Lines)
+
+		wantSegments := []*CodeSegment{
+			{Text: "Say "},
+			{Text: "hello", IsMatched: true},
+			{Text: " to the world, "},
+			{Text: "hello", IsMatched: true},
+			{Text: "!"},
+		}
+		assert.Equal(t, wantSegments, match.Lines[1].Segments())
+	})
+
+	// Replacing and deleting repositories should be reflected after reopening.
+	err = idx.Index(1, "c3", []*CodeDocument{
+		{Path: "main.go", Language: "Go", Content: "package main\n"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, idx.Delete(2))
+	require.NoError(t, idx.Close())
+
+	idx, err = openBuiltinCodeIndexer(path)
+	require.NoError(t, err)
+	defer func() { _ = idx.Close() }()
+	assert.Equal(t, []int64{1}, idx.RepoIDs())
+	assert.Equal(t, "c3", idx.IndexedCommitID(1))
+	assert.Equal(t, 0, search(t, idx, CodeSearchOptions{Keyword: "hello"}).Total)
+	assert.Equal(t, 1, search(t, idx, CodeSearchOptions{Keyword: "package"}).Total)
+}
@@ -0,0 +1,123 @@
+package indexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "", want: nil},
+		{text: "Fix the Login-page crash!", want: []string{"fix", "the", "login", "page", "crash"}},
+		{text: "v1.2 release", want: []string{"v1", "2", "release"}},
+		{text: "中文 search", want: []string{"中", "文", "search"}},
+	}
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			assert.Equal(t, test.want, tokenize(test.text))
+		})
+	}
+}
+
+func TestBuiltinIndexer(t *testing.T) {
+	path := t.TempDir()
+	idx, err := openBuiltinIndexer(path)
+	require.NoError(t, err)
+	assert.True(t, idx.IsEmpty())
+
+	err = idx.Index(
+		&IssueDocument{ID: 1, RepoID: 1, Title: "Login page crash", Body: "Stack trace attached"},
+		&IssueDocument{ID: 2, RepoID: 1, Title: "Improve docs", Body: "The login section is outdated", Comments: []string{"Also the crash section"}},
+		&IssueDocument{ID: 3, RepoID: 2, Title: "Crash on startup", Comments: []string{"Happens after login"}},
+	)
+	require.NoError(t, err)
+
+	search := func(t *testing.T, idx *builtinIndexer, opts SearchOptions) []int64 {
+		ids, err := idx.Search(opts)
+		require.NoError(t, err)
+		return ids
+	}
+
+	tests := []struct {
+		name string
+		opts SearchOptions
+		want []int64
+	}{
+		{
+			name: "empty keyword",
+			opts: SearchOptions{Keyword: "  "},
+			want: []int64{},
+		},
+		{
+			name: "ranked by fields",
+			opts: SearchOptions{Keyword: "login"},
+			want: []int64{1, 2, 3},
+		},
+		{
+			name: "all terms must match",
+			opts: SearchOptions{Keyword: "login crash"},
+			want: []int64{1, 3, 2},
+		},
+		{
+			name: "field restriction",
+			opts: SearchOptions{Keyword: "title:crash"},
+			want: []int64{3, 1},
+		},
+		{
+			name: "comment restriction",
+			opts: SearchOptions{Keyword: "comment:login"},
+			want: []int64{3},
+		},
+		{
+			name: "prefix",
+			opts: SearchOptions{Keyword: "out*"},
+			want: []int64{2},
+		},
+		{
+			name: "prefix includes exact term",
+			opts: SearchOptions{Keyword: "login*"},
+			want: []int64{1, 2, 3},
+		},
+		{
+			name: "exact term is not a prefix",
+			opts: SearchOptions{Keyword: "log"},
+			want: []int64{},
+		},
+		{
+			name: "repositories",
+			opts: SearchOptions{Keyword: "crash", RepoIDs: []int64{2}},
+			want: []int64{3},
+		},
+		{
+			name: "limit",
+			opts: SearchOptions{Keyword: "login", Limit: 1},
+			want: []int64{1},
+		},
+		{
+			name: "no match",
+			opts: SearchOptions{Keyword: "login nothing"},
+			want: []int64{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, search(t, idx, test.opts))
+		})
+	}
+
+	// Replacing and deleting documents should be reflected after reopening.
+	require.NoError(t, idx.Index(&IssueDocument{ID: 1, RepoID: 1, Title: "Logout button"}))
+	require.NoError(t, idx.Delete(3))
+	require.NoError(t, idx.Close())
+
+	idx, err = openBuiltinIndexer(path)
+	require.NoError(t, err)
+	defer func() { _ = idx.Close() }()
+	assert.Equal(t, []int64{2}, search(t, idx, SearchOptions{Keyword: "crash"}))
+	assert.Equal(t, []int64{1}, search(t, idx, SearchOptions{Keyword: "logout"}))
+}
@@ -0,0 +1,253 @@
+package indexer
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+
+	"gogs.io/gogs/internal/errx"
+)
+
+// CodeDocument is the indexed content of a file in a repository.
+type CodeDocument struct {
+	Path     string
+	Language string
+	Content  string
+}
+
+// CodeSearchOptions contains options for searching code.
+type CodeSearchOptions struct {
+	// Keyword is matched case-insensitively as a whole, or as a regular
+	// expression if IsRegexp is true. Matching is done line by line.
+	Keyword  string
+	IsRegexp bool
+	// RepoIDs restricts results to files of given repositories, all repositories
+	// are searched if it is empty.
+	RepoIDs []int64
+	// Path restricts results to files under the path, or files matching the path
+	// if it contains glob characters (e.g. "*.go" or "cmd/*/main.go").
+	Path string
+	// Language restricts results to files of the language (case-insensitive).
+	Language string
+	Page     int
+	PageSize int
+}
+
+// CodeMatchLine is a line that matches the keyword.
+type CodeMatchLine struct {
+	Number  int
+	Content string
+	// Ranges are byte offsets of matched parts in the content, each is a pair of
+	// start (inclusive) and end (exclusive) offsets.
+	Ranges [][2]int
+}
+
+// CodeSegment is a part of a matched line.
+type CodeSegment struct {
+	Text      string
+	IsMatched bool
+}
+
+// Segments splits the line into matched and unmatched parts in order, which is
+// useful for rendering highlighted snippets.
+func (l *CodeMatchLine) Segments() []*CodeSegment {
+	segments := make([]*CodeSegment, 0, len(l.Ranges)*2+1)
+	last := 0
+	for _, r := range l.Ranges {
+		if r[0] > last {
+			segments = append(segments, &CodeSegment{Text: l.Content[last:r[0]]})
+		}
+		segments = append(segments, &CodeSegment{Text: l.Content[r[0]:r[1]], IsMatched: true})
+		last = r[1]
+	}
+	if last < len(l.Content) {
+		segments = append(segments, &CodeSegment{Text: l.Content[last:]})
+	}
+	return segments
+}
+
+// CodeMatch is a file that matches the keyword.
+type CodeMatch struct {
+	RepoID   int64
+	Path     string
+	Language string
+	// NumMatches is the number of matched parts in the whole file, it can be
+	// larger than the number of lines returned.
+	NumMatches int
+	Lines      []*CodeMatchLine
+}
+
+// CodeSearchResult is a page of matched files.
+type CodeSearchResult struct {
+	// Total is the number of all matched files.
+	Total   int
+	Matches []*CodeMatch
+}
+
+// CodeIndexer is an index of code of the default branch of repositories.
+type CodeIndexer interface {
+	// Index replaces all documents of the repository with given documents of
+	// the commit.
+	Index(repoID int64, commitID string, docs []*CodeDocument) error
+	// Delete removes all documents of the repository from the index.
+	Delete(repoID int64) error
+	// IndexedCommitID returns the ID of the commit that the repository is
+	// indexed at, or an empty string if the repository is not indexed.
+	IndexedCommitID(repoID int64) string
+	// RepoIDs returns IDs of all indexed repositories.
+	RepoIDs() []int64
+	// Search returns matched files ordered by relevance.
+	Search(opts CodeSearchOptions) (*CodeSearchResult, error)
+	// Close releases resources held by the index.
+	Close() error
+}
+
+// NewCodeIndexer opens the code indexer of given type with data stored in given
+// path.
+func NewCodeIndexer(typ, path string) (CodeIndexer, error) {
+	switch typ {
+	case TypeBuiltin:
+		return openBuiltinCodeIndexer(path)
+	default:
+		return nil, errors.Newf("unsupported code indexer type %q", typ)
+	}
+}
+
+type ErrInvalidRegexp struct {
+	args errx.Args
+}
+
+func IsErrInvalidRegexp(err error) bool {
+	return errors.As(err, &ErrInvalidRegexp{})
+}
+
+func (err ErrInvalidRegexp) Error() string {
+	return fmt.Sprintf("invalid regular expression: %v", err.args)
+}
+
+const (
+	// maxCodeMatchLines is the maximum number of matched lines returned for
+	// each file.
+	maxCodeMatchLines = 5
+	// maxCodeLineLength is the maximum length in bytes of a matched line
+	// returned, longer lines (e.g. minified files) are truncated.
+	maxCodeLineLength = 500
+)
+
+// codeQuery is a parsed keyword of code search.
+type codeQuery struct {
+	re *regexp.Regexp
+	// literals are lowercase strings that must appear in every matched file, the
+	// index is used to find candidates that contain all of them.
+	literals []string
+}
+
+// parseCodeQuery parses the keyword into a query. It returns nil if the keyword
+// is empty, and ErrInvalidRegexp if the keyword is not a valid regular
+// expression.
+func parseCodeQuery(keyword string, isRegexp bool) (*codeQuery, error) {
+	if !isRegexp {
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" {
+			return nil, nil
+		}
+		return &codeQuery{
+			re:       regexp.MustCompile("(?i)" + regexp.QuoteMeta(keyword)),
+			literals: []string{strings.ToLower(keyword)},
+		}, nil
+	}
+
+	if keyword == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(keyword)
+	if err != nil {
+		return nil, ErrInvalidRegexp{args: errx.Args{"keyword": keyword, "error": err.Error()}}
+	}
+	q := &codeQuery{re: re}
+
+	// Collect literals that are required by the regular expression, the index
+	// is only a prefilter so it is fine to miss some of them.
+	parsed, err := syntax.Parse(keyword, syntax.Perl)
+	if err != nil {
+		return q, nil
+	}
+	parsed = parsed.Simplify()
+	var subs []*syntax.Regexp
+	switch parsed.Op {
+	case syntax.OpLiteral:
+		subs = []*syntax.Regexp{parsed}
+	case syntax.OpConcat:
+		subs = parsed.Sub
+	}
+	for _, sub := range subs {
+		if sub.Op == syntax.OpLiteral {
+			q.literals = append(q.literals, strings.ToLower(string(sub.Rune)))
+		}
+	}
+	return q, nil
+}
+
+// matchLines returns matched lines of the content and the number of matched
+// parts in total.
+func (q *codeQuery) matchLines(content string) ([]*CodeMatchLine, int) {
+	var (
+		lines      []*CodeMatchLine
+		numMatches int
+	)
+	for i, line := range strings.Split(content, "\n") {
+		locs := q.re.FindAllStringIndex(line, -1)
+		if len(locs) == 0 {
+			continue
+		}
+		numMatches += len(locs)
+		if len(lines) >= maxCodeMatchLines {
+			continue
+		}
+
+		line = strings.TrimSuffix(line, "\r")
+		if len(line) > maxCodeLineLength {
+			line = strings.ToValidUTF8(line[:maxCodeLineLength], "")
+		}
+		ranges := make([][2]int, 0, len(locs))
+		for _, loc := range locs {
+			if loc[0] >= len(line) || loc[0] == loc[1] {
+				continue
+			}
+			end := loc[1]
+			if end > len(line) {
+				end = len(line)
+			}
+			ranges = append(ranges, [2]int{loc[0], end})
+		}
+		lines = append(lines, &CodeMatchLine{
+			Number:  i + 1,
+			Content: line,
+			Ranges:  ranges,
+		})
+	}
+	return lines, numMatches
+}
+
+// matchCodePath returns true if the file path matches the path filter.
+func matchCodePath(filter, filePath string) bool {
+	if filter == "" {
+		return true
+	}
+
+	if strings.ContainsAny(filter, "*?[") {
+		matched, _ := path.Match(filter, filePath)
+		if !matched && !strings.Contains(filter, "/") {
+			// Patterns without a slash match the base name, e.g. "*.go".
+			matched, _ = path.Match(filter, path.Base(filePath))
+		}
+		return matched
+	}
+
+	filter = strings.Trim(filter, "/")
+	return filePath == filter || strings.HasPrefix(filePath, filter+"/")
+}
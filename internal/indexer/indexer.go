@@ -0,0 +1,134 @@
+// Package indexer provides full-text indexing and searching of issues, pull
+// requests and their comments, and code of repositories.
+package indexer
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Field is a searchable field of an issue document.
+type Field string
+
+const (
+	FieldTitle   Field = "title"
+	FieldBody    Field = "body"
+	FieldComment Field = "comment"
+)
+
+// IssueDocument is the indexed content of an issue or a pull request.
+type IssueDocument struct {
+	ID       int64
+	RepoID   int64
+	Title    string
+	Body     string
+	Comments []string
+}
+
+// SearchOptions contains options for searching issues.
+type SearchOptions struct {
+	// Keyword is a list of space-separated terms that must all match. A term can
+	// be restricted to a field with a prefix like "title:", "body:" or
+	// "comment:", and a trailing "*" matches any term with the prefix.
+	Keyword string
+	// RepoIDs restricts results to issues of given repositories, all
+	// repositories are searched if it is empty.
+	RepoIDs []int64
+	// Limit is the maximum number of results, zero means no limit.
+	Limit int
+}
+
+// IssueIndexer is an index of issues that supports full-text search.
+type IssueIndexer interface {
+	// Index adds or replaces given documents in the index.
+	Index(docs ...*IssueDocument) error
+	// Delete removes documents of given issue IDs from the index.
+	Delete(ids ...int64) error
+	// Search returns IDs of matched issues ordered by relevance.
+	Search(opts SearchOptions) ([]int64, error)
+	// IsEmpty returns true if there is no document in the index, e.g. when the
+	// index is just created and needs to be populated.
+	IsEmpty() bool
+	// Close flushes and releases resources held by the index.
+	Close() error
+}
+
+// Types of indexers.
+const (
+	TypeBuiltin = "builtin"
+)
+
+// NewIssueIndexer opens the issue indexer of given type with data stored in
+// given path.
+func NewIssueIndexer(typ, path string) (IssueIndexer, error) {
+	switch typ {
+	case TypeBuiltin:
+		return openBuiltinIndexer(path)
+	default:
+		return nil, errors.Newf("unsupported issue indexer type %q", typ)
+	}
+}
+
+// tokenize splits the text into lowercase terms of letters and digits. Each
+// ideographic character (e.g. Chinese) is a term by itself because there is no
+// space between words.
+func tokenize(text string) []string {
+	var (
+		terms   []string
+		current strings.Builder
+	)
+	flush := func() {
+		if current.Len() > 0 {
+			terms = append(terms, current.String())
+			current.Reset()
+		}
+	}
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r) || unicode.In(r, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			flush()
+			terms = append(terms, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			current.WriteRune(unicode.ToLower(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+	return terms
+}
+
+// queryTerm is a term of the search keyword.
+type queryTerm struct {
+	text     string
+	field    Field // Empty to match any field.
+	isPrefix bool
+}
+
+// parseKeyword parses the search keyword into query terms.
+func parseKeyword(keyword string) []queryTerm {
+	var terms []queryTerm
+	for _, word := range strings.Fields(keyword) {
+		var field Field
+		if before, after, ok := strings.Cut(word, ":"); ok {
+			switch Field(strings.ToLower(before)) {
+			case FieldTitle, FieldBody, FieldComment:
+				field = Field(strings.ToLower(before))
+				word = after
+			}
+		}
+
+		isPrefix := strings.HasSuffix(word, "*")
+		tokens := tokenize(word)
+		for i, token := range tokens {
+			terms = append(terms, queryTerm{
+				text:     token,
+				field:    field,
+				isPrefix: isPrefix && i == len(tokens)-1,
+			})
+		}
+	}
+	return terms
+}
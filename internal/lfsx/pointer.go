@@ -0,0 +1,62 @@
+package lfsx
+
+import (
+	"strconv"
+	"strings"
+)
+
+// MaxPointerSize is the maximum size of a pointer file in bytes, larger files
+// are never pointers.
+const MaxPointerSize = 1024
+
+const pointerVersion = "version https://git-lfs.github.com/spec/v1"
+
+// Pointer is the content of a pointer file that refers to an LFS object.
+type Pointer struct {
+	OID  OID
+	Size int64
+}
+
+// ParsePointer parses the content of a pointer file. It returns false if the
+// content is not a valid pointer.
+// Spec: https://github.com/git-lfs/git-lfs/blob/master/docs/spec.md
+func ParsePointer(content []byte) (*Pointer, bool) {
+	if len(content) > MaxPointerSize {
+		return nil, false
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	if len(lines) < 3 || lines[0] != pointerVersion {
+		return nil, false
+	}
+
+	p := new(Pointer)
+	var hasOID, hasSize bool
+	for _, line := range lines[1:] {
+		key, value, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, false
+		}
+
+		switch key {
+		case "oid":
+			oid, ok := strings.CutPrefix(value, "sha256:")
+			if !ok || !ValidOID(OID(oid)) {
+				return nil, false
+			}
+			p.OID = OID(oid)
+			hasOID = true
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || size < 0 {
+				return nil, false
+			}
+			p.Size = size
+			hasSize = true
+		}
+	}
+	if !hasOID || !hasSize {
+		return nil, false
+	}
+	return p, true
+}
@@ -0,0 +1,50 @@
+package lfsx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePointer(t *testing.T) {
+	const oid = "ef797c8118f02dfb649607dd5d3f8c7623048c9c063d532cc95c5ed7a898a64f"
+	tests := []struct {
+		name    string
+		content string
+		want    *Pointer
+	}{
+		{
+			name:    "valid",
+			content: "version https://git-lfs.github.com/spec/v1\noid sha256:" + oid + "\nsize 12345\n",
+			want:    &Pointer{OID: oid, Size: 12345},
+		},
+		{
+			name:    "with extension",
+			content: "version https://git-lfs.github.com/spec/v1\next-0-foo sha256:" + oid + "\noid sha256:" + oid + "\nsize 1\n",
+			want:    &Pointer{OID: oid, Size: 1},
+		},
+		{
+			name:    "unknown version",
+			content: "version https://hawser.github.com/spec/v1\noid sha256:" + oid + "\nsize 12345\n",
+		},
+		{
+			name:    "invalid OID",
+			content: "version https://git-lfs.github.com/spec/v1\noid sha256:" + oid[:10] + "\nsize 12345\n",
+		},
+		{
+			name:    "missing size",
+			content: "version https://git-lfs.github.com/spec/v1\noid sha256:" + oid + "\n",
+		},
+		{
+			name:    "not a pointer",
+			content: "package main\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := ParsePointer([]byte(test.content))
+			assert.Equal(t, test.want != nil, ok)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
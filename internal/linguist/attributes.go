@@ -0,0 +1,203 @@
+package linguist
+
+import (
+	"bufio"
+	"bytes"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Names of Git attributes that override detection of linguist.
+const (
+	AttrVendored  = "linguist-vendored"
+	AttrGenerated = "linguist-generated"
+	AttrLanguage  = "linguist-language"
+)
+
+// Attributes are overrides of a file set by Git attributes.
+type Attributes struct {
+	// Vendored and Generated are nil when not specified, and detection by path
+	// should be used.
+	Vendored  *bool
+	Generated *bool
+	// Language is empty when not specified.
+	Language string
+}
+
+// attributeRule is a line of a .gitattributes file.
+type attributeRule struct {
+	re *regexp.Regexp
+	// values are values of mentioned linguist attributes, a nil value means the
+	// attribute is explicitly unspecified (i.e. "!attr").
+	values map[string]*string
+}
+
+// GitAttributes is a set of rules of .gitattributes files in a tree. Only
+// linguist attributes are recognized.
+type GitAttributes struct {
+	rules []*attributeRule
+}
+
+// Add parses and adds rules of the .gitattributes file in given directory, which
+// is relative to the root of the tree (empty for the root). Files in parent
+// directories must be added before files in their subdirectories so that
+// deeper rules take precedence.
+func (a *GitAttributes) Add(dir string, content []byte) {
+	dir = strings.Trim(dir, "/")
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// Skip blank lines, comments, macros and lines without attributes.
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], "[") {
+			continue
+		}
+
+		re := patternToRegexp(dir, fields[0])
+		if re == nil {
+			continue
+		}
+
+		values := make(map[string]*string)
+		for _, field := range fields[1:] {
+			var (
+				name  string
+				value *string
+			)
+			switch {
+			case strings.HasPrefix(field, "!"):
+				name = field[1:]
+			case strings.HasPrefix(field, "-"):
+				name = field[1:]
+				value = ptr("false")
+			default:
+				var v string
+				var ok bool
+				name, v, ok = strings.Cut(field, "=")
+				if !ok {
+					v = "true"
+				}
+				value = &v
+			}
+
+			switch name {
+			case AttrVendored, AttrGenerated, AttrLanguage:
+				values[name] = value
+			}
+		}
+		if len(values) > 0 {
+			a.rules = append(a.rules, &attributeRule{re: re, values: values})
+		}
+	}
+}
+
+// Match returns linguist overrides of the file in given path relative to the
+// root of the tree.
+func (a *GitAttributes) Match(filePath string) Attributes {
+	values := make(map[string]*string)
+	for _, rule := range a.rules {
+		if !rule.re.MatchString(filePath) {
+			continue
+		}
+		for name, value := range rule.values {
+			values[name] = value
+		}
+	}
+
+	var attrs Attributes
+	if v := values[AttrVendored]; v != nil {
+		attrs.Vendored = ptr(isTrue(*v))
+	}
+	if v := values[AttrGenerated]; v != nil {
+		attrs.Generated = ptr(isTrue(*v))
+	}
+	if v := values[AttrLanguage]; v != nil && *v != "true" && *v != "false" {
+		attrs.Language = NormalizeLanguage(*v)
+	}
+	return attrs
+}
+
+func isTrue(v string) bool {
+	return v != "false" && v != "0"
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// patternToRegexp converts the pattern of a .gitattributes file in given
+// directory to a regular expression that matches full paths. It returns nil if
+// the pattern can never match a file.
+func patternToRegexp(dir, pattern string) *regexp.Regexp {
+	// Patterns that only match directories do not match files inside them.
+	if strings.HasSuffix(pattern, "/") {
+		return nil
+	}
+
+	var prefix string
+	if dir != "" {
+		prefix = regexp.QuoteMeta(dir) + "/"
+	}
+
+	// A pattern without a slash matches the base name at any level.
+	if !strings.Contains(pattern, "/") {
+		prefix += "(.*/)?"
+	}
+	re, err := regexp.Compile("^" + prefix + globToRegexp(strings.TrimPrefix(pattern, "/")) + "$")
+	if err != nil {
+		// Malformed patterns (e.g. invalid character classes) never match.
+		return nil
+	}
+	return re
+}
+
+// globToRegexp converts the glob pattern to a regular expression without
+// anchors, where "**" matches across directories.
+func globToRegexp(pattern string) string {
+	var buf strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			buf.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			buf.WriteString(".*")
+			i++
+		case c == '*':
+			buf.WriteString("[^/]*")
+		case c == '?':
+			buf.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				buf.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			buf.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c >= 0x80:
+			// Bytes of multibyte characters are literal.
+			buf.WriteByte(c)
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return buf.String()
+}
+
+// GitAttributesDir returns the directory of the .gitattributes file in given
+// path, or false if the path is not a .gitattributes file.
+func GitAttributesDir(filePath string) (string, bool) {
+	if path.Base(filePath) != ".gitattributes" {
+		return "", false
+	}
+	dir := path.Dir(filePath)
+	if dir == "." {
+		dir = ""
+	}
+	return dir, true
+}
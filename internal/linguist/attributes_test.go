@@ -0,0 +1,55 @@
+package linguist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitAttributes(t *testing.T) {
+	var attrs GitAttributes
+	attrs.Add("", []byte(`# Comment
+* text=auto
+*.tmpl linguist-language=go-template
+docs/** linguist-documentation
+/assets/** linguist-vendored
+third_party/** -linguist-vendored
+*.gen.go linguist-generated=true
+[attr]binary -diff -merge -text
+`))
+	attrs.Add("assets/own", []byte(`
+*.js !linguist-vendored
+`))
+
+	tests := []struct {
+		path string
+		want Attributes
+	}{
+		{path: "main.go", want: Attributes{}},
+		{path: "templates/home.tmpl", want: Attributes{Language: "Go Template"}},
+		{path: "assets/lib.js", want: Attributes{Vendored: ptr(true)}},
+		{path: "web/assets/lib.js", want: Attributes{}},
+		{path: "assets/own/app.js", want: Attributes{}},
+		{path: "assets/own/app.css", want: Attributes{Vendored: ptr(true)}},
+		{path: "third_party/lib.c", want: Attributes{Vendored: ptr(false)}},
+		{path: "internal/types.gen.go", want: Attributes{Generated: ptr(true)}},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			assert.Equal(t, test.want, attrs.Match(test.path))
+		})
+	}
+}
+
+func TestGitAttributesDir(t *testing.T) {
+	dir, ok := GitAttributesDir(".gitattributes")
+	assert.True(t, ok)
+	assert.Equal(t, "", dir)
+
+	dir, ok = GitAttributesDir("web/.gitattributes")
+	assert.True(t, ok)
+	assert.Equal(t, "web", dir)
+
+	_, ok = GitAttributesDir("web/gitattributes")
+	assert.False(t, ok)
+}
@@ -0,0 +1,231 @@
+// Package linguist detects programming languages of files and computes
+// language statistics of repositories.
+package linguist
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// languageFileNames maps lowercase file names that determine languages
+// regardless of extensions.
+var languageFileNames = map[string]string{
+	"cmakelists.txt": "CMake",
+	"dockerfile":     "Dockerfile",
+	"gnumakefile":    "Makefile",
+	"makefile":       "Makefile",
+	"gemfile":        "Ruby",
+	"rakefile":       "Ruby",
+	"vagrantfile":    "Ruby",
+	"jenkinsfile":    "Groovy",
+}
+
+// languageExts maps lowercase file extensions to languages.
+var languageExts = map[string]string{
+	".as":       "ActionScript",
+	".bat":      "Batchfile",
+	".cmd":      "Batchfile",
+	".c":        "C",
+	".h":        "C",
+	".cs":       "C#",
+	".cc":       "C++",
+	".cpp":      "C++",
+	".cxx":      "C++",
+	".hh":       "C++",
+	".hpp":      "C++",
+	".clj":      "Clojure",
+	".cljs":     "Clojure",
+	".cmake":    "CMake",
+	".coffee":   "CoffeeScript",
+	".css":      "CSS",
+	".d":        "D",
+	".dart":     "Dart",
+	".ex":       "Elixir",
+	".exs":      "Elixir",
+	".elm":      "Elm",
+	".erl":      "Erlang",
+	".hrl":      "Erlang",
+	".fs":       "F#",
+	".fsx":      "F#",
+	".f90":      "Fortran",
+	".go":       "Go",
+	".groovy":   "Groovy",
+	".gradle":   "Groovy",
+	".hs":       "Haskell",
+	".htm":      "HTML",
+	".html":     "HTML",
+	".ini":      "INI",
+	".java":     "Java",
+	".js":       "JavaScript",
+	".jsx":      "JavaScript",
+	".mjs":      "JavaScript",
+	".json":     "JSON",
+	".jl":       "Julia",
+	".kt":       "Kotlin",
+	".kts":      "Kotlin",
+	".less":     "Less",
+	".lua":      "Lua",
+	".md":       "Markdown",
+	".markdown": "Markdown",
+	".m":        "Objective-C",
+	".mm":       "Objective-C++",
+	".ml":       "OCaml",
+	".pas":      "Pascal",
+	".pl":       "Perl",
+	".pm":       "Perl",
+	".php":      "PHP",
+	".ps1":      "PowerShell",
+	".proto":    "Protocol Buffer",
+	".py":       "Python",
+	".r":        "R",
+	".rb":       "Ruby",
+	".rs":       "Rust",
+	".sass":     "Sass",
+	".scala":    "Scala",
+	".scss":     "SCSS",
+	".sh":       "Shell",
+	".bash":     "Shell",
+	".zsh":      "Shell",
+	".sql":      "SQL",
+	".swift":    "Swift",
+	".tex":      "TeX",
+	".tf":       "HCL",
+	".hcl":      "HCL",
+	".tmpl":     "Go Template",
+	".toml":     "TOML",
+	".ts":       "TypeScript",
+	".tsx":      "TypeScript",
+	".vb":       "Visual Basic",
+	".vue":      "Vue",
+	".xml":      "XML",
+	".yaml":     "YAML",
+	".yml":      "YAML",
+	".zig":      "Zig",
+}
+
+// DetectLanguage returns the name of the language of given file name, or an
+// empty string if the language is unknown.
+func DetectLanguage(filename string) string {
+	filename = strings.ToLower(path.Base(filename))
+	if lang, ok := languageFileNames[filename]; ok {
+		return lang
+	}
+	return languageExts[path.Ext(filename)]
+}
+
+// languageColors maps languages to colors used in language bars.
+var languageColors = map[string]string{
+	"ActionScript":  "#882b0f",
+	"Batchfile":     "#c1f12e",
+	"C":             "#555555",
+	"C#":            "#178600",
+	"C++":           "#f34b7d",
+	"Clojure":       "#db5855",
+	"CMake":         "#da3434",
+	"CoffeeScript":  "#244776",
+	"CSS":           "#563d7c",
+	"D":             "#ba595e",
+	"Dart":          "#00b4ab",
+	"Dockerfile":    "#384d54",
+	"Elixir":        "#6e4a7e",
+	"Elm":           "#60b5cc",
+	"Erlang":        "#b83998",
+	"F#":            "#b845fc",
+	"Fortran":       "#4d41b1",
+	"Go":            "#00add8",
+	"Go Template":   "#00add8",
+	"Groovy":        "#4298b8",
+	"Haskell":       "#5e5086",
+	"HCL":           "#844fba",
+	"HTML":          "#e34c26",
+	"Java":          "#b07219",
+	"JavaScript":    "#f1e05a",
+	"Julia":         "#a270ba",
+	"Kotlin":        "#a97bff",
+	"Less":          "#1d365d",
+	"Lua":           "#000080",
+	"Makefile":      "#427819",
+	"Objective-C":   "#438eff",
+	"Objective-C++": "#6866fb",
+	"OCaml":         "#3be133",
+	"Pascal":        "#e3f171",
+	"Perl":          "#0298c3",
+	"PHP":           "#4f5d95",
+	"PowerShell":    "#012456",
+	"Python":        "#3572a5",
+	"R":             "#198ce7",
+	"Ruby":          "#701516",
+	"Rust":          "#dea584",
+	"Sass":          "#a53b70",
+	"Scala":         "#c22d40",
+	"SCSS":          "#c6538c",
+	"Shell":         "#89e051",
+	"Swift":         "#f05138",
+	"TeX":           "#3d6117",
+	"TypeScript":    "#3178c6",
+	"Visual Basic":  "#945db7",
+	"Vue":           "#41b883",
+	"Zig":           "#ec915c",
+}
+
+// defaultLanguageColor is the color of languages without a specific color.
+const defaultLanguageColor = "#cccccc"
+
+// LanguageColor returns the color of the language in the form of "#rrggbb".
+func LanguageColor(lang string) string {
+	if color, ok := languageColors[lang]; ok {
+		return color
+	}
+	return defaultLanguageColor
+}
+
+// nonProgrammingLanguages are data and prose languages that are not counted in
+// language statistics unless explicitly set by "linguist-language".
+var nonProgrammingLanguages = map[string]bool{
+	"INI":      true,
+	"JSON":     true,
+	"Markdown": true,
+	"SQL":      true,
+	"TOML":     true,
+	"XML":      true,
+	"YAML":     true,
+}
+
+// IsProgrammingLanguage returns true if the language is a programming or markup
+// language, i.e. not data or prose.
+func IsProgrammingLanguage(lang string) bool {
+	return !nonProgrammingLanguages[lang]
+}
+
+// NormalizeLanguage returns the canonical name of the language that matches
+// given name case-insensitively, or the name itself if it is unknown. Spaces can
+// be written as dashes since values of Git attributes cannot contain spaces,
+// e.g. "go-template" is "Go Template".
+func NormalizeLanguage(name string) string {
+	name = strings.TrimSpace(name)
+	for _, lang := range Languages() {
+		if strings.EqualFold(lang, name) || strings.EqualFold(strings.ReplaceAll(lang, " ", "-"), name) {
+			return lang
+		}
+	}
+	return name
+}
+
+// Languages returns names of all known languages in alphabetical order.
+func Languages() []string {
+	seen := make(map[string]bool)
+	for _, lang := range languageFileNames {
+		seen[lang] = true
+	}
+	for _, lang := range languageExts {
+		seen[lang] = true
+	}
+
+	langs := make([]string, 0, len(seen))
+	for lang := range seen {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
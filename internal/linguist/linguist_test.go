@@ -0,0 +1,46 @@
+package linguist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     string
+	}{
+		{filename: "main.go", want: "Go"},
+		{filename: "cmd/gogs/MAIN.GO", want: "Go"},
+		{filename: "web/src/App.tsx", want: "TypeScript"},
+		{filename: "Dockerfile", want: "Dockerfile"},
+		{filename: "docker/Makefile", want: "Makefile"},
+		{filename: "LICENSE", want: ""},
+		{filename: "data.unknown", want: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.filename, func(t *testing.T) {
+			assert.Equal(t, test.want, DetectLanguage(test.filename))
+		})
+	}
+}
+
+func TestNormalizeLanguage(t *testing.T) {
+	assert.Equal(t, "Go", NormalizeLanguage("go"))
+	assert.Equal(t, "Go Template", NormalizeLanguage("go-template"))
+	assert.Equal(t, "Brainfuck", NormalizeLanguage("Brainfuck"))
+}
+
+func TestIsVendored(t *testing.T) {
+	assert.True(t, IsVendored("vendor/github.com/foo/bar.go"))
+	assert.True(t, IsVendored("web/node_modules/react/index.js"))
+	assert.True(t, IsVendored("public/js/app.min.js"))
+	assert.False(t, IsVendored("internal/vendoring/vendor.go"))
+}
+
+func TestIsGenerated(t *testing.T) {
+	assert.True(t, IsGenerated("api/v1/service.pb.go"))
+	assert.True(t, IsGenerated("web/package-lock.json"))
+	assert.False(t, IsGenerated("cmd/gogs/main.go"))
+}
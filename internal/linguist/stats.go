@@ -0,0 +1,37 @@
+package linguist
+
+// Stats returns the number of bytes of each language of given files, which is
+// a map from paths to sizes. Vendored and generated files, files of unknown
+// languages, and files of data and prose languages are excluded unless
+// overridden by Git attributes.
+func Stats(files map[string]int64, attrs *GitAttributes) map[string]int64 {
+	stats := make(map[string]int64)
+	for path, size := range files {
+		var override Attributes
+		if attrs != nil {
+			override = attrs.Match(path)
+		}
+
+		vendored := IsVendored(path)
+		if override.Vendored != nil {
+			vendored = *override.Vendored
+		}
+		generated := IsGenerated(path)
+		if override.Generated != nil {
+			generated = *override.Generated
+		}
+		if vendored || generated {
+			continue
+		}
+
+		lang := override.Language
+		if lang == "" {
+			lang = DetectLanguage(path)
+			if lang == "" || !IsProgrammingLanguage(lang) {
+				continue
+			}
+		}
+		stats[lang] += size
+	}
+	return stats
+}
@@ -0,0 +1,54 @@
+package linguist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	files := map[string]int64{
+		"main.go":                  100,
+		"internal/service.pb.go":   1000,
+		"vendor/lib/lib.go":        1000,
+		"web/app.ts":               50,
+		"web/app.min.js":           1000,
+		"templates/home.tmpl":      30,
+		"README.md":                500,
+		"config.yaml":              500,
+		"schema.sql":               20,
+		"third_party/lib/lib.c":    40,
+		"LICENSE":                  500,
+		"assets/generated/gen.go":  10,
+		"assets/generated/keep.go": 10,
+	}
+
+	t.Run("detection by path", func(t *testing.T) {
+		want := map[string]int64{
+			"Go":          120,
+			"TypeScript":  50,
+			"Go Template": 30,
+		}
+		assert.Equal(t, want, Stats(files, nil))
+	})
+
+	t.Run("overrides by attributes", func(t *testing.T) {
+		var attrs GitAttributes
+		attrs.Add("", []byte(`
+*.sql linguist-language=PLpgSQL
+third_party/** -linguist-vendored
+assets/generated/** linguist-generated
+*.tmpl linguist-vendored
+`))
+		attrs.Add("assets/generated", []byte(`
+keep.go -linguist-generated
+`))
+		want := map[string]int64{
+			"Go":         110,
+			"TypeScript": 50,
+			"PLpgSQL":    20,
+			"C":          40,
+		}
+		assert.Equal(t, want, Stats(files, &attrs))
+	})
+}
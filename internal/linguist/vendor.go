@@ -0,0 +1,47 @@
+package linguist
+
+import (
+	"regexp"
+	"strings"
+)
+
+// vendoredPatterns match paths of third-party files, e.g. dependencies
+// checked into the repository.
+var vendoredPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(^|/)(vendor|vendors|third[-_]?party|node_modules|bower_components|Godeps)/`),
+	regexp.MustCompile(`(^|/)\.(yarn|git|github|gitlab)/`),
+	regexp.MustCompile(`(^|/)(jquery|bootstrap)([^/]*)\.js$`),
+	regexp.MustCompile(`\.min\.(js|css)$`),
+}
+
+// generatedPatterns match paths of files that are generated by tools.
+var generatedPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\.pb\.(go|cc|h)$`),
+	regexp.MustCompile(`_pb2(_grpc)?\.py$`),
+	regexp.MustCompile(`(^|/)(zz_generated|bindata)[^/]*\.go$`),
+	regexp.MustCompile(`(^|/)mock_[^/]*\.go$`),
+	regexp.MustCompile(`\.designer\.(cs|vb)$`),
+	regexp.MustCompile(`(^|/)(package-lock\.json|yarn\.lock|pnpm-lock\.yaml|go\.sum|Cargo\.lock|composer\.lock|Gemfile\.lock)$`),
+	regexp.MustCompile(`\.(js|css)\.map$`),
+}
+
+func matchAny(patterns []*regexp.Regexp, path string) bool {
+	for _, p := range patterns {
+		if p.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsVendored returns true if the file in given path is considered as a
+// third-party file by its path.
+func IsVendored(path string) bool {
+	return matchAny(vendoredPatterns, strings.TrimPrefix(path, "/"))
+}
+
+// IsGenerated returns true if the file in given path is considered as
+// generated by its path.
+func IsGenerated(path string) bool {
+	return matchAny(generatedPatterns, strings.TrimPrefix(path, "/"))
+}
@@ -59,6 +59,24 @@ func toTag(b *database.Tag, c *git.Commit) *tag {
 	}
 }
 
+func toProtectedTag(t *database.ProtectTag) *protectedTag {
+	users := make([]string, len(t.Users))
+	for i := range t.Users {
+		users[i] = t.Users[i].Name
+	}
+	teams := make([]string, len(t.Teams))
+	for i := range t.Teams {
+		teams[i] = t.Teams[i].Name
+	}
+	return &protectedTag{
+		ID:             t.ID,
+		Pattern:        t.Pattern,
+		WhitelistUsers: users,
+		WhitelistTeams: teams,
+		Created:        t.Created,
+	}
+}
+
 func toPayloadCommit(c *git.Commit) *types.WebhookPayloadCommit {
 	authorUsername := ""
 	author, err := database.Handle.Users().GetByEmail(context.TODO(), c.Author.Email)
@@ -98,6 +116,19 @@ func toUserPublicKey(apiLink string, key *database.PublicKey) *types.UserPublicK
 	}
 }
 
+func toUserGPGKey(key *database.GPGKey) *types.UserGPGKey {
+	return &types.UserGPGKey{
+		ID:          key.ID,
+		KeyID:       key.KeyID,
+		Fingerprint: key.Fingerprint,
+		PublicKey:   key.Content,
+		Emails:      key.EmailList(),
+		CanSign:     key.CanSign,
+		Created:     key.Created,
+		Expires:     key.Expired,
+	}
+}
+
 func toRepositoryHook(repoLink string, w *database.Webhook) *types.RepositoryHook {
 	config := map[string]string{
 		"url":          w.URL,
@@ -173,24 +204,35 @@ func issueState(isClosed bool) types.IssueStateType {
 // toIssue converts a database issue to an API issue.
 // It assumes the following fields have been assigned with valid values:
 // Required - Poster, Labels
-// Optional - Milestone, Assignee, PullRequest
+// Optional - Milestone, Assignee, Assignees, PullRequest
 func toIssue(issue *database.Issue) *types.Issue {
 	labels := make([]*types.IssueLabel, len(issue.Labels))
 	for i := range issue.Labels {
 		labels[i] = toIssueLabel(issue.Labels[i])
 	}
 
+	assignees := make([]*types.User, len(issue.Assignees))
+	for i := range issue.Assignees {
+		assignees[i] = toUser(issue.Assignees[i])
+	}
+
 	apiIssue := &types.Issue{
-		ID:       issue.ID,
-		Index:    issue.Index,
-		Poster:   toUser(issue.Poster),
-		Title:    issue.Title,
-		Body:     issue.Content,
-		Labels:   labels,
-		State:    issueState(issue.IsClosed),
-		Comments: issue.NumComments,
-		Created:  issue.Created,
-		Updated:  issue.Updated,
+		ID:           issue.ID,
+		Index:        issue.Index,
+		Poster:       toUser(issue.Poster),
+		Title:        issue.Title,
+		Body:         issue.Content,
+		Labels:       labels,
+		Assignees:    assignees,
+		State:        issueState(issue.IsClosed),
+		Comments:     issue.NumComments,
+		Reactions:    issue.NumReactions,
+		TimeEstimate: issue.TimeEstimate,
+		Locked:       issue.IsLocked,
+		LockReason:   issue.LockReason,
+		Pinned:       issue.IsPinned(),
+		Created:      issue.Created,
+		Updated:      issue.Updated,
 	}
 
 	if issue.Milestone != nil {
@@ -222,6 +264,15 @@ func toIssueComment(c *database.Comment) *types.IssueComment {
 	}
 }
 
+func toReaction(r *database.Reaction) *types.Reaction {
+	return &types.Reaction{
+		ID:      r.ID,
+		User:    toUser(r.User),
+		Content: r.Type,
+		Created: r.Created,
+	}
+}
+
 func toIssueMilestone(m *database.Milestone) *types.IssueMilestone {
 	ms := &types.IssueMilestone{
 		ID:           m.ID,
@@ -0,0 +1,75 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/database"
+)
+
+// parseTimeQuery parses the query value of given name in RFC 3339 format, it
+// responses with 422 if the value is invalid. It returns zero time if the
+// query value is empty.
+func parseTimeQuery(c *context.APIContext, name string) time.Time {
+	value := c.Query(name)
+	if value == "" {
+		return time.Time{}
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		c.ErrorStatus(http.StatusUnprocessableEntity, errors.Newf("%s must be in RFC 3339 format", name))
+		return time.Time{}
+	}
+	return t
+}
+
+func adminListTrackedTimes(c *context.APIContext) {
+	opts := database.TrackedTimesOptions{
+		Since:    parseTimeQuery(c, "since"),
+		Page:     c.QueryInt("page"),
+		PageSize: toAllowedPageSize(c.QueryInt("limit")),
+	}
+	if c.Written() {
+		return
+	}
+	opts.Before = parseTimeQuery(c, "before")
+	if c.Written() {
+		return
+	}
+
+	if username := c.Query("user"); username != "" {
+		u, err := database.Handle.Users().GetByUsername(c.Req.Context(), username)
+		if err != nil {
+			if database.IsErrUserNotExist(err) {
+				c.ErrorStatus(http.StatusUnprocessableEntity, err)
+			} else {
+				c.Error(err, "get user by name")
+			}
+			return
+		}
+		opts.UserID = u.ID
+	}
+
+	count, err := database.CountTrackedTimes(opts)
+	if err != nil {
+		c.Error(err, "count tracked times")
+		return
+	}
+	times, err := database.GetTrackedTimes(opts)
+	if err != nil {
+		c.Error(err, "get tracked times")
+		return
+	}
+
+	apiTimes, err := toTrackedTimes(times)
+	if err != nil {
+		c.Error(err, "convert tracked times")
+		return
+	}
+	c.SetLinkHeader(int(count), opts.PageSize)
+	c.JSONSuccess(&apiTimes)
+}
@@ -164,6 +164,13 @@ func mustEnableIssues(c *context.APIContext) {
 	}
 }
 
+func mustAllowPulls(c *context.APIContext) {
+	if !c.Repo.Repository.AllowsPulls() {
+		c.NotFound()
+		return
+	}
+}
+
 // RegisterRoutes registers all route in API v1 to the web application.
 // FIXME: custom form error response
 func RegisterRoutes(m *macaron.Macaron) {
@@ -176,6 +183,8 @@ func RegisterRoutes(m *macaron.Macaron) {
 		// Miscellaneous
 		m.Post("/markdown", bind(markdownRequest{}), markdown)
 		m.Post("/markdown/raw", markdownRaw)
+		m.Get("/search/code", searchAllCode)
+		m.Get("/topics/search", searchTopics)
 
 		// Users
 		m.Group("/users", func() {
@@ -196,6 +205,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 		m.Group("/users", func() {
 			m.Group("/:username", func() {
 				m.Get("/keys", listPublicKeys)
+				m.Get("/gpg_keys", listGPGKeys)
 
 				m.Get("/followers", listFollowers)
 				m.Group("/following", func() {
@@ -230,6 +240,15 @@ func RegisterRoutes(m *macaron.Macaron) {
 					Delete(deletePublicKey)
 			})
 
+			m.Group("/gpg_keys", func() {
+				m.Combo("").
+					Get(listMyGPGKeys).
+					Post(bind(createGPGKeyRequest{}), createGPGKey)
+				m.Combo("/:id").
+					Get(getGPGKey).
+					Delete(deleteGPGKey)
+			})
+
 			m.Get("/issues", listUserIssues)
 		}, reqToken())
 
@@ -246,6 +265,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 
 			m.Get("/:username/:reponame", repoAssignment(), getRepo)
 			m.Get("/:username/:reponame/releases", repoAssignment(), releases)
+			m.Get("/:username/:reponame/search/code", repoAssignment(), searchRepoCode)
 		})
 
 		m.Group("/repos", func() {
@@ -271,6 +291,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 				}, reqRepoAdmin())
 
 				m.Get("/raw/*", context.RepoRef(), getRawFile)
+				m.Get("/blame/*", context.RepoRef(), getBlame)
 				m.Group("/contents", func() {
 					m.Get("", getContents)
 					m.Combo("/*").
@@ -287,7 +308,23 @@ func RegisterRoutes(m *macaron.Macaron) {
 					})
 				})
 				m.Get("/forks", listForks)
-				m.Get("/tags", listTags)
+				m.Get("/languages", listLanguages)
+				m.Combo("/topics").
+					Get(listTopics).
+					Put(reqRepoAdmin(), bind(replaceTopicsRequest{}), replaceTopics)
+				m.Group("/tags", func() {
+					m.Get("", listTags)
+					m.Delete("/*", reqRepoWriter(), deleteTag)
+				})
+				m.Group("/protected_tags", func() {
+					m.Combo("").
+						Get(listProtectedTags).
+						Post(bind(protectedTagRequest{}), createProtectedTag)
+					m.Combo("/:id").
+						Get(getProtectedTag).
+						Put(bind(protectedTagRequest{}), editProtectedTag).
+						Delete(deleteProtectedTag)
+				}, reqRepoAdmin())
 				m.Group("/branches", func() {
 					m.Get("", listBranches)
 					m.Get("/*", getBranch)
@@ -314,6 +351,10 @@ func RegisterRoutes(m *macaron.Macaron) {
 					m.Group("/comments", func() {
 						m.Get("", listRepoIssueComments)
 						m.Patch("/:id", bind(editIssueCommentRequest{}), editIssueComment)
+						m.Combo("/:id/reactions").
+							Get(listIssueCommentReactions).
+							Post(bind(issueReactionRequest{}), createIssueCommentReaction).
+							Delete(bind(issueReactionRequest{}), deleteIssueCommentReaction)
 					})
 					m.Group("/:index", func() {
 						m.Combo("").
@@ -337,9 +378,48 @@ func RegisterRoutes(m *macaron.Macaron) {
 								Delete(clearIssueLabels)
 							m.Delete("/:id", deleteIssueLabel)
 						}, reqRepoWriter())
+
+						m.Combo("/dependencies").
+							Get(listIssueDependencies).
+							Post(reqRepoWriter(), bind(issueDependencyRequest{}), createIssueDependency).
+							Delete(reqRepoWriter(), bind(issueDependencyRequest{}), deleteIssueDependency)
+						m.Get("/blocks", listIssueDependents)
+
+						m.Combo("/reactions").
+							Get(listIssueReactions).
+							Post(bind(issueReactionRequest{}), createIssueReaction).
+							Delete(bind(issueReactionRequest{}), deleteIssueReaction)
+
+						m.Group("", func() {
+							m.Combo("/lock").
+								Put(bind(lockIssueRequest{}), lockIssue).
+								Delete(unlockIssue)
+							m.Combo("/pin").
+								Put(pinIssue).
+								Delete(unpinIssue)
+							m.Post("/transfer", bind(transferIssueRequest{}), transferIssue)
+						}, reqRepoWriter())
+
+						m.Combo("/times").
+							Get(listIssueTrackedTimes).
+							Post(reqRepoWriter(), bind(addTrackedTimeRequest{}), addIssueTrackedTime)
+						m.Delete("/times/:id", reqRepoWriter(), deleteIssueTrackedTime)
+						m.Group("/stopwatch", func() {
+							m.Post("/start", startIssueStopwatch)
+							m.Post("/stop", stopIssueStopwatch)
+							m.Delete("", deleteIssueStopwatch)
+						}, reqRepoWriter())
 					})
 				}, mustEnableIssues)
 
+				m.Group("/pulls/:index", func() {
+					m.Post("/update", updatePullRequestBranch)
+					m.Combo("/requested_reviewers").
+						Get(listPullRequestReviewRequests).
+						Post(reqRepoWriter(), bind(pullRequestReviewRequestsRequest{}), createPullRequestReviewRequests).
+						Delete(reqRepoWriter(), bind(pullRequestReviewRequestsRequest{}), deletePullRequestReviewRequests)
+				}, mustAllowPulls)
+
 				m.Group("/labels", func() {
 					m.Get("", listLabels)
 					m.Get("/:id", getLabel)
@@ -385,6 +465,8 @@ func RegisterRoutes(m *macaron.Macaron) {
 		}, reqToken(), orgAssignment(true))
 
 		m.Group("/admin", func() {
+			m.Get("/times", adminListTrackedTimes)
+
 			m.Group("/users", func() {
 				m.Post("", bind(adminCreateUserRequest{}), adminCreateUser)
 
@@ -0,0 +1,92 @@
+package v1
+
+import (
+	"net/http"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/database"
+	"gogs.io/gogs/internal/indexer"
+	"gogs.io/gogs/internal/route/api/v1/types"
+)
+
+type codeSearchLine struct {
+	Number  int      `json:"number"`
+	Content string   `json:"content"`
+	Ranges  [][2]int `json:"ranges"`
+}
+
+type codeSearchItem struct {
+	Repository *types.Repository `json:"repository"`
+	Path       string            `json:"path"`
+	Language   string            `json:"language"`
+	HTMLURL    string            `json:"html_url"`
+	NumMatches int               `json:"num_matches"`
+	Lines      []*codeSearchLine `json:"lines"`
+}
+
+type codeSearchResult struct {
+	TotalCount int               `json:"total_count"`
+	Items      []*codeSearchItem `json:"items"`
+}
+
+// searchCode searches code in given repositories, or all repositories the
+// current user has access to if none is given.
+func searchCode(c *context.APIContext, repoIDs []int64) {
+	if !database.IsCodeIndexerEnabled() {
+		c.NotFound()
+		return
+	}
+
+	pageSize := toAllowedPageSize(c.QueryInt("limit"))
+	matches, total, err := database.SearchCode(c.Req.Context(), c.UserID(), indexer.CodeSearchOptions{
+		Keyword:  c.Query("q"),
+		IsRegexp: c.QueryBool("regex"),
+		RepoIDs:  repoIDs,
+		Path:     c.Query("path"),
+		Language: c.Query("language"),
+		Page:     c.QueryInt("page"),
+		PageSize: pageSize,
+	})
+	if err != nil {
+		if indexer.IsErrInvalidRegexp(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, err)
+		} else {
+			c.Error(err, "search code")
+		}
+		return
+	}
+
+	items := make([]*codeSearchItem, len(matches))
+	for i, match := range matches {
+		lines := make([]*codeSearchLine, len(match.Lines))
+		for j, line := range match.Lines {
+			lines[j] = &codeSearchLine{
+				Number:  line.Number,
+				Content: line.Content,
+				Ranges:  line.Ranges,
+			}
+		}
+		items[i] = &codeSearchItem{
+			Repository: toRepository(match.Repo, nil),
+			Path:       match.Path,
+			Language:   match.Language,
+			HTMLURL:    match.Repo.HTMLURL() + "/src/" + match.Repo.DefaultBranch + "/" + match.Path,
+			NumMatches: match.NumMatches,
+			Lines:      lines,
+		}
+	}
+
+	c.SetLinkHeader(total, pageSize)
+	c.JSONSuccess(&codeSearchResult{
+		TotalCount: total,
+		Items:      items,
+	})
+}
+
+func searchAllCode(c *context.APIContext) {
+	searchCode(c, nil)
+}
+
+func searchRepoCode(c *context.APIContext) {
+	searchCode(c, []int64{c.Repo.Repository.ID})
+}
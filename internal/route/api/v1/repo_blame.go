@@ -0,0 +1,111 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/gitx"
+	"gogs.io/gogs/internal/route/api/v1/types"
+)
+
+type blameCommit struct {
+	SHA          string            `json:"sha"`
+	URL          string            `json:"url"`
+	HTMLURL      string            `json:"html_url"`
+	Author       *types.CommitUser `json:"author"`
+	Committer    *types.CommitUser `json:"committer"`
+	Message      string            `json:"message"`
+	PreviousSHA  string            `json:"previous_sha,omitempty"`
+	PreviousPath string            `json:"previous_path,omitempty"`
+}
+
+type blameRange struct {
+	Commit    *blameCommit `json:"commit"`
+	StartLine int          `json:"start_line"`
+	EndLine   int          `json:"end_line"`
+	Lines     []string     `json:"lines"`
+}
+
+type blame struct {
+	Path   string        `json:"path"`
+	SHA    string        `json:"sha"`
+	Ranges []*blameRange `json:"ranges"`
+}
+
+func getBlame(c *context.APIContext) {
+	if !c.Repo.HasAccess() {
+		c.NotFound()
+		return
+	}
+
+	if c.Repo.Repository.IsBare {
+		c.NotFound()
+		return
+	}
+
+	blob, err := c.Repo.Commit.Blob(c.Repo.TreePath)
+	if err != nil {
+		c.NotFoundOrError(gitx.NewError(err), "get blob")
+		return
+	}
+	if blob.Size() >= conf.UI.MaxDisplayFileSize {
+		c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("The file is too large to be blamed."))
+		return
+	}
+
+	parts, err := gitx.RepoBlame(c.Repo.Repository.RepoPath(), c.Repo.CommitID, c.Repo.TreePath, time.Duration(conf.Git.Timeout.Diff)*time.Second)
+	if err != nil {
+		c.Error(err, "blame file")
+		return
+	}
+
+	repoURL := fmt.Sprintf("%s/repos/%s", c.BaseURL, c.Repo.Repository.FullName())
+	commits := make(map[string]*blameCommit)
+	ranges := make([]*blameRange, len(parts))
+	for i, part := range parts {
+		commit, ok := commits[part.Commit.ID]
+		if !ok {
+			commit = &blameCommit{
+				SHA:     part.Commit.ID,
+				URL:     repoURL + "/commits/" + part.Commit.ID,
+				HTMLURL: c.Repo.Repository.HTMLURL() + "/commit/" + part.Commit.ID,
+				Author: &types.CommitUser{
+					Name:  part.Commit.Author.Name,
+					Email: part.Commit.Author.Email,
+					Date:  part.Commit.Author.When.Format(time.RFC3339),
+				},
+				Committer: &types.CommitUser{
+					Name:  part.Commit.Committer.Name,
+					Email: part.Commit.Committer.Email,
+					Date:  part.Commit.Committer.When.Format(time.RFC3339),
+				},
+				Message:      part.Commit.Summary,
+				PreviousSHA:  part.Commit.PreviousID,
+				PreviousPath: part.Commit.PreviousPath,
+			}
+			commits[part.Commit.ID] = commit
+		}
+
+		lines := make([]string, len(part.Lines))
+		for j := range part.Lines {
+			lines[j] = part.Lines[j].Content
+		}
+		ranges[i] = &blameRange{
+			Commit:    commit,
+			StartLine: part.Lines[0].Number,
+			EndLine:   part.Lines[len(part.Lines)-1].Number,
+			Lines:     lines,
+		}
+	}
+
+	c.JSONSuccess(&blame{
+		Path:   c.Repo.TreePath,
+		SHA:    c.Repo.CommitID,
+		Ranges: ranges,
+	})
+}
@@ -3,6 +3,8 @@ package v1
 import (
 	"net/http"
 
+	"github.com/cockroachdb/errors"
+
 	"gogs.io/gogs/internal/context"
 	"gogs.io/gogs/internal/database"
 	"gogs.io/gogs/internal/route/api/v1/types"
@@ -38,7 +40,11 @@ func addCollaborator(c *context.APIContext, form addCollaboratorRequest) {
 	}
 
 	if err := c.Repo.Repository.AddCollaborator(collaborator); err != nil {
-		c.Error(err, "add collaborator")
+		if database.IsErrBlockedByUser(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("The repository owner has blocked the user."))
+		} else {
+			c.Error(err, "add collaborator")
+		}
 		return
 	}
 
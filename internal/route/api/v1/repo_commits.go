@@ -171,6 +171,7 @@ func gitCommitToAPICommit(commit *git.Commit, c *context.APIContext) (*types.Com
 				URL: c.BaseURL + "/repos/" + c.Repo.Repository.FullName() + "/tree/" + commit.ID.String(),
 				SHA: commit.ID.String(),
 			},
+			Verification: database.VerifyCommit(c.Req.Context(), c.Repo.Repository.RepoPath(), commit).APIFormat(),
 		},
 		Author:    apiAuthor,
 		Committer: apiCommitter,
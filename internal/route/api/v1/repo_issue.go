@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -39,11 +40,60 @@ func queryIssues(c *context.APIContext, opts *database.IssuesOptions) {
 	c.JSONSuccess(&apiIssues)
 }
 
+// searchIssues restricts issues to those matched by the keyword in the "q"
+// query parameter within given repositories, or repositories that have issues
+// matching other options if nil.
+func searchIssues(c *context.APIContext, opts *database.IssuesOptions, repoIDs []int64) {
+	keyword := strings.TrimSpace(c.Query("q"))
+	if keyword == "" {
+		return
+	}
+
+	var err error
+	if repoIDs == nil {
+		repoIDs, err = database.IssueRepoIDs(opts)
+		if err != nil {
+			c.Error(err, "get issue repository IDs")
+			return
+		}
+	}
+	opts.IssueIDs, err = database.SearchIssueIDs(keyword, repoIDs)
+	if err != nil {
+		c.Error(err, "search issue IDs")
+	}
+}
+
+// getUserIDByQuery returns the ID of the user whose name is given by the query
+// parameter, or zero if the parameter is empty. It responses with 422 if the
+// user does not exist.
+func getUserIDByQuery(c *context.APIContext, name string) int64 {
+	username := c.Query(name)
+	if username == "" {
+		return 0
+	}
+
+	user, err := database.Handle.Users().GetByUsername(c.Req.Context(), username)
+	if err != nil {
+		if database.IsErrUserNotExist(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.Newf("%s does not exist: [name: %s]", name, username))
+		} else {
+			c.Error(err, "get user by name")
+		}
+		return 0
+	}
+	return user.ID
+}
+
 func listUserIssues(c *context.APIContext) {
 	opts := database.IssuesOptions{
 		AssigneeID: c.User.ID,
 		Page:       c.QueryInt("page"),
 		IsClosed:   types.IssueStateType(c.Query("state")) == types.IssueStateClosed,
+		SortType:   c.Query("sort"),
+	}
+	searchIssues(c, &opts, nil)
+	if c.Written() {
+		return
 	}
 
 	queryIssues(c, &opts)
@@ -51,30 +101,85 @@ func listUserIssues(c *context.APIContext) {
 
 func listIssues(c *context.APIContext) {
 	opts := database.IssuesOptions{
-		RepoID:   c.Repo.Repository.ID,
-		Page:     c.QueryInt("page"),
-		IsClosed: types.IssueStateType(c.Query("state")) == types.IssueStateClosed,
+		RepoID:      c.Repo.Repository.ID,
+		MilestoneID: c.QueryInt64("milestone"),
+		Labels:      c.Query("labels"),
+		Page:        c.QueryInt("page"),
+		IsClosed:    types.IssueStateType(c.Query("state")) == types.IssueStateClosed,
+		SortType:    c.Query("sort"),
+	}
+	opts.AssigneeID = getUserIDByQuery(c, "assignee")
+	if c.Written() {
+		return
+	}
+	opts.PosterID = getUserIDByQuery(c, "creator")
+	if c.Written() {
+		return
+	}
+	searchIssues(c, &opts, []int64{c.Repo.Repository.ID})
+	if c.Written() {
+		return
 	}
 
 	queryIssues(c, &opts)
 }
 
 func getIssue(c *context.APIContext) {
-	issue, err := database.GetIssueByIndex(c.Repo.Repository.ID, c.ParamsInt64(":index"))
+	index := c.ParamsInt64(":index")
+	issue, err := database.GetIssueByIndex(c.Repo.Repository.ID, index)
 	if err != nil {
+		if database.IsErrIssueNotExist(err) {
+			// Redirect to the new location if the issue has been transferred to a
+			// repository that the user has read access to.
+			transferred, err := database.GetIssueRedirect(c.Repo.Repository.ID, index)
+			if err == nil {
+				if database.Handle.Permissions().Authorize(c.Req.Context(), c.UserID(), transferred.RepoID, database.AccessModeRead,
+					database.AccessModeOptions{
+						OwnerID: transferred.Repo.OwnerID,
+						Private: transferred.Repo.IsPrivate,
+					},
+				) {
+					c.Redirect(fmt.Sprintf("%s/repos/%s/issues/%d", c.BaseURL, transferred.Repo.FullName(), transferred.Index), http.StatusMovedPermanently)
+					return
+				}
+			} else if !database.IsErrIssueRedirectNotExist(err) {
+				c.Error(err, "get issue redirect")
+				return
+			}
+		}
 		c.NotFoundOrError(err, "get issue by index")
 		return
 	}
 	c.JSONSuccess(toIssue(issue))
 }
 
+// getAssigneesByNames returns users of given names, it responses with 422 if
+// any of them does not exist.
+func getAssigneesByNames(c *context.APIContext, names []string) []*database.User {
+	assignees := make([]*database.User, 0, len(names))
+	for _, name := range names {
+		assignee, err := database.Handle.Users().GetByUsername(c.Req.Context(), name)
+		if err != nil {
+			if database.IsErrUserNotExist(err) {
+				c.ErrorStatus(http.StatusUnprocessableEntity, errors.Newf("assignee does not exist: [name: %s]", name))
+			} else {
+				c.Error(err, "get user by name")
+			}
+			return nil
+		}
+		assignees = append(assignees, assignee)
+	}
+	return assignees
+}
+
 type createIssueRequest struct {
-	Title     string  `json:"title" binding:"Required"`
-	Body      string  `json:"body"`
-	Assignee  string  `json:"assignee"`
-	Milestone int64   `json:"milestone"`
-	Labels    []int64 `json:"labels"`
-	Closed    bool    `json:"closed"`
+	Title     string   `json:"title" binding:"Required"`
+	Body      string   `json:"body"`
+	Assignee  string   `json:"assignee"`
+	Assignees []string `json:"assignees"`
+	Milestone int64    `json:"milestone"`
+	Labels    []int64  `json:"labels"`
+	Closed    bool     `json:"closed"`
 }
 
 func createIssue(c *context.APIContext, form createIssueRequest) {
@@ -86,26 +191,30 @@ func createIssue(c *context.APIContext, form createIssueRequest) {
 		Content:  form.Body,
 	}
 
+	var assigneeIDs []int64
 	if c.Repo.IsWriter() {
+		names := form.Assignees
 		if len(form.Assignee) > 0 {
-			assignee, err := database.Handle.Users().GetByUsername(c.Req.Context(), form.Assignee)
-			if err != nil {
-				if database.IsErrUserNotExist(err) {
-					c.ErrorStatus(http.StatusUnprocessableEntity, errors.Newf("assignee does not exist: [name: %s]", form.Assignee))
-				} else {
-					c.Error(err, "get user by name")
-				}
-				return
-			}
-			issue.AssigneeID = assignee.ID
+			names = append([]string{form.Assignee}, names...)
+		}
+		assignees := getAssigneesByNames(c, names)
+		if c.Written() {
+			return
+		}
+		for _, assignee := range assignees {
+			assigneeIDs = append(assigneeIDs, assignee.ID)
 		}
 		issue.MilestoneID = form.Milestone
 	} else {
 		form.Labels = nil
 	}
 
-	if err := database.NewIssue(c.Repo.Repository, issue, form.Labels, nil); err != nil {
-		c.Error(err, "new issue")
+	if err := database.NewIssue(c.Repo.Repository, issue, form.Labels, assigneeIDs, nil); err != nil {
+		if database.IsErrBlockedByUser(err) {
+			c.ErrorStatus(http.StatusForbidden, errors.New("The repository owner has blocked you."))
+		} else {
+			c.Error(err, "new issue")
+		}
 		return
 	}
 
@@ -127,11 +236,13 @@ func createIssue(c *context.APIContext, form createIssueRequest) {
 }
 
 type editIssueRequest struct {
-	Title     string  `json:"title"`
-	Body      *string `json:"body"`
-	Assignee  *string `json:"assignee"`
-	Milestone *int64  `json:"milestone"`
-	State     *string `json:"state"`
+	Title        string   `json:"title"`
+	Body         *string  `json:"body"`
+	Assignee     *string  `json:"assignee"`
+	Assignees    []string `json:"assignees"`
+	Milestone    *int64   `json:"milestone"`
+	State        *string  `json:"state"`
+	TimeEstimate *int64   `json:"time_estimate"` // In seconds.
 }
 
 func editIssue(c *context.APIContext, form editIssueRequest) {
@@ -153,25 +264,18 @@ func editIssue(c *context.APIContext, form editIssueRequest) {
 		issue.Content = *form.Body
 	}
 
-	if c.Repo.IsWriter() && form.Assignee != nil &&
-		(issue.Assignee == nil || issue.Assignee.LowerName != strings.ToLower(*form.Assignee)) {
-		if *form.Assignee == "" {
-			issue.AssigneeID = 0
-		} else {
-			assignee, err := database.Handle.Users().GetByUsername(c.Req.Context(), *form.Assignee)
-			if err != nil {
-				if database.IsErrUserNotExist(err) {
-					c.ErrorStatus(http.StatusUnprocessableEntity, errors.Newf("assignee does not exist: [name: %s]", *form.Assignee))
-				} else {
-					c.Error(err, "get user by name")
-				}
-				return
-			}
-			issue.AssigneeID = assignee.ID
+	if c.Repo.IsWriter() && (form.Assignee != nil || form.Assignees != nil) {
+		names := form.Assignees
+		if form.Assignee != nil && *form.Assignee != "" {
+			names = append([]string{*form.Assignee}, names...)
+		}
+		assignees := getAssigneesByNames(c, names)
+		if c.Written() {
+			return
 		}
 
-		if err = database.UpdateIssueUserByAssignee(issue); err != nil {
-			c.Error(err, "update issue user by assignee")
+		if err = issue.ReplaceAssignees(c.User, assignees); err != nil {
+			c.Error(err, "replace assignees")
 			return
 		}
 	}
@@ -185,13 +289,25 @@ func editIssue(c *context.APIContext, form editIssueRequest) {
 		}
 	}
 
+	if c.Repo.IsWriter() && form.TimeEstimate != nil {
+		if *form.TimeEstimate < 0 {
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("Time estimate cannot be negative."))
+			return
+		}
+		issue.TimeEstimate = *form.TimeEstimate
+	}
+
 	if err = database.UpdateIssue(issue); err != nil {
 		c.Error(err, "update issue")
 		return
 	}
 	if form.State != nil {
 		if err = issue.ChangeStatus(c.User, c.Repo.Repository, types.IssueStateClosed == types.IssueStateType(*form.State)); err != nil {
-			c.Error(err, "change status")
+			if database.IsErrIssueHasOpenDependencies(err) {
+				c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("The issue is blocked by open issues."))
+			} else {
+				c.Error(err, "change status")
+			}
 			return
 		}
 	}
@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/cockroachdb/errors"
+
 	"gogs.io/gogs/internal/context"
 	"gogs.io/gogs/internal/database"
 	"gogs.io/gogs/internal/route/api/v1/types"
@@ -75,9 +77,18 @@ func createIssueComment(c *context.APIContext, form createIssueCommentRequest) {
 		return
 	}
 
+	ensureCanComment(c, issue)
+	if c.Written() {
+		return
+	}
+
 	comment, err := database.CreateIssueComment(c.User, c.Repo.Repository, issue, form.Body, nil)
 	if err != nil {
-		c.Error(err, "create issue comment")
+		if database.IsErrBlockedByUser(err) {
+			c.ErrorStatus(http.StatusForbidden, errors.New("The repository owner has blocked you."))
+		} else {
+			c.Error(err, "create issue comment")
+		}
 		return
 	}
 
@@ -0,0 +1,152 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/cockroachdb/errors"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/database"
+	"gogs.io/gogs/internal/route/api/v1/types"
+)
+
+// toReadableIssues converts issues that are readable by the signed in user to
+// API issues.
+func toReadableIssues(c *context.APIContext, issues []*database.Issue) ([]*types.Issue, error) {
+	apiIssues := make([]*types.Issue, 0, len(issues))
+	for _, issue := range issues {
+		if issue.RepoID != c.Repo.Repository.ID &&
+			!database.Handle.Permissions().Authorize(c.Req.Context(), c.User.ID, issue.RepoID, database.AccessModeRead,
+				database.AccessModeOptions{
+					OwnerID: issue.Repo.OwnerID,
+					Private: issue.Repo.IsPrivate,
+				},
+			) {
+			continue
+		}
+
+		if err := issue.LoadAttributes(); err != nil {
+			return nil, errors.Wrap(err, "load attributes")
+		}
+		apiIssues = append(apiIssues, toIssue(issue))
+	}
+	return apiIssues, nil
+}
+
+func listIssueDependencies(c *context.APIContext) {
+	issue, err := database.GetIssueByIndex(c.Repo.Repository.ID, c.ParamsInt64(":index"))
+	if err != nil {
+		c.NotFoundOrError(err, "get issue by index")
+		return
+	}
+
+	dependencies, err := issue.GetDependencies()
+	if err != nil {
+		c.Error(err, "get dependencies")
+		return
+	}
+
+	apiIssues, err := toReadableIssues(c, dependencies)
+	if err != nil {
+		c.Error(err, "convert issues")
+		return
+	}
+	c.JSONSuccess(&apiIssues)
+}
+
+func listIssueDependents(c *context.APIContext) {
+	issue, err := database.GetIssueByIndex(c.Repo.Repository.ID, c.ParamsInt64(":index"))
+	if err != nil {
+		c.NotFoundOrError(err, "get issue by index")
+		return
+	}
+
+	dependents, err := issue.GetDependents()
+	if err != nil {
+		c.Error(err, "get dependents")
+		return
+	}
+
+	apiIssues, err := toReadableIssues(c, dependents)
+	if err != nil {
+		c.Error(err, "convert issues")
+		return
+	}
+	c.JSONSuccess(&apiIssues)
+}
+
+type issueDependencyRequest struct {
+	Owner string `json:"owner"`
+	Repo  string `json:"repo"`
+	Index int64  `json:"index" binding:"Required"`
+}
+
+// getDependencyByRequest returns the issue and the dependency referenced by the
+// request, it responses with 422 if the dependency does not exist or is not
+// readable by the signed in user.
+func getDependencyByRequest(c *context.APIContext, form issueDependencyRequest) (issue, dependency *database.Issue) {
+	issue, err := database.GetIssueByIndex(c.Repo.Repository.ID, c.ParamsInt64(":index"))
+	if err != nil {
+		c.NotFoundOrError(err, "get issue by index")
+		return nil, nil
+	}
+
+	repo := c.Repo.Repository
+	if form.Owner != "" || form.Repo != "" {
+		repo, err = database.GetRepositoryByRef(form.Owner + "/" + form.Repo)
+		if err != nil && !database.IsErrRepoNotExist(err) {
+			c.Error(err, "get repository by reference")
+			return nil, nil
+		}
+	}
+	if err == nil {
+		dependency, err = database.GetIssueByIndex(repo.ID, form.Index)
+		if err != nil && !database.IsErrIssueNotExist(err) {
+			c.Error(err, "get issue by index")
+			return nil, nil
+		}
+	}
+	if err != nil || (repo.ID != c.Repo.Repository.ID &&
+		!database.Handle.Permissions().Authorize(c.Req.Context(), c.User.ID, repo.ID, database.AccessModeRead,
+			database.AccessModeOptions{
+				OwnerID: repo.OwnerID,
+				Private: repo.IsPrivate,
+			},
+		)) {
+		c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("The dependency does not exist."))
+		return nil, nil
+	}
+	return issue, dependency
+}
+
+func createIssueDependency(c *context.APIContext, form issueDependencyRequest) {
+	issue, dependency := getDependencyByRequest(c, form)
+	if c.Written() {
+		return
+	}
+
+	if err := issue.AddDependency(dependency); err != nil {
+		if database.IsErrCircularIssueDependency(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("The dependency is circular."))
+		} else {
+			c.Error(err, "add dependency")
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, toIssue(dependency))
+}
+
+func deleteIssueDependency(c *context.APIContext, form issueDependencyRequest) {
+	issue, dependency := getDependencyByRequest(c, form)
+	if c.Written() {
+		return
+	}
+
+	if err := issue.RemoveDependency(dependency); err != nil {
+		c.Error(err, "remove dependency")
+		return
+	}
+
+	c.NoContent()
+}
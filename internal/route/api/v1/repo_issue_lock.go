@@ -0,0 +1,55 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/cockroachdb/errors"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/database"
+)
+
+// ensureCanComment responses with 403 if the conversation of the issue is
+// locked and the context user is not a writer of the repository.
+func ensureCanComment(c *context.APIContext, issue *database.Issue) {
+	if issue.IsLocked && !c.Repo.IsWriter() {
+		c.ErrorStatus(http.StatusForbidden, errors.New("The conversation is locked to collaborators."))
+	}
+}
+
+type lockIssueRequest struct {
+	Reason string `json:"reason"`
+}
+
+func lockIssue(c *context.APIContext, form lockIssueRequest) {
+	issue, err := database.GetIssueByIndex(c.Repo.Repository.ID, c.ParamsInt64(":index"))
+	if err != nil {
+		c.NotFoundOrError(err, "get issue by index")
+		return
+	}
+
+	if !database.IsValidIssueLockReason(form.Reason) {
+		c.ErrorStatus(http.StatusUnprocessableEntity, errors.Newf("invalid lock reason: %s", form.Reason))
+		return
+	}
+
+	if err = issue.Lock(c.User, form.Reason); err != nil {
+		c.Error(err, "lock issue")
+		return
+	}
+	c.NoContent()
+}
+
+func unlockIssue(c *context.APIContext) {
+	issue, err := database.GetIssueByIndex(c.Repo.Repository.ID, c.ParamsInt64(":index"))
+	if err != nil {
+		c.NotFoundOrError(err, "get issue by index")
+		return
+	}
+
+	if err = issue.Unlock(c.User); err != nil {
+		c.Error(err, "unlock issue")
+		return
+	}
+	c.NoContent()
+}
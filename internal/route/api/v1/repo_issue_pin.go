@@ -0,0 +1,42 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/cockroachdb/errors"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/database"
+)
+
+func pinIssue(c *context.APIContext) {
+	issue, err := database.GetIssueByIndex(c.Repo.Repository.ID, c.ParamsInt64(":index"))
+	if err != nil {
+		c.NotFoundOrError(err, "get issue by index")
+		return
+	}
+
+	if err = issue.Pin(); err != nil {
+		if database.IsErrPinnedIssuesLimit(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.Newf("at most %d issues can be pinned", database.MaxPinnedIssues))
+		} else {
+			c.Error(err, "pin issue")
+		}
+		return
+	}
+	c.NoContent()
+}
+
+func unpinIssue(c *context.APIContext) {
+	issue, err := database.GetIssueByIndex(c.Repo.Repository.ID, c.ParamsInt64(":index"))
+	if err != nil {
+		c.NotFoundOrError(err, "get issue by index")
+		return
+	}
+
+	if err = issue.Unpin(); err != nil {
+		c.Error(err, "unpin issue")
+		return
+	}
+	c.NoContent()
+}
@@ -0,0 +1,169 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/cockroachdb/errors"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/database"
+	"gogs.io/gogs/internal/route/api/v1/types"
+)
+
+// toReactions converts reactions to API reactions with users loaded.
+func toReactions(reactions database.ReactionList) ([]*types.Reaction, error) {
+	if err := reactions.LoadUsers(); err != nil {
+		return nil, errors.Wrap(err, "load users")
+	}
+
+	apiReactions := make([]*types.Reaction, len(reactions))
+	for i := range reactions {
+		apiReactions[i] = toReaction(reactions[i])
+	}
+	return apiReactions, nil
+}
+
+// getReactionComment returns the comment of the issue in the repository of
+// given ID along with the issue.
+func getReactionComment(c *context.APIContext) (*database.Issue, *database.Comment) {
+	comment, err := database.GetCommentByID(c.ParamsInt64(":id"))
+	if err != nil {
+		c.NotFoundOrError(err, "get comment by ID")
+		return nil, nil
+	}
+
+	issue, err := database.GetIssueByID(comment.IssueID)
+	if err != nil {
+		c.NotFoundOrError(err, "get issue by ID")
+		return nil, nil
+	}
+
+	if issue.RepoID != c.Repo.Repository.ID || comment.Type != database.CommentTypeComment {
+		c.NotFound()
+		return nil, nil
+	}
+	return issue, comment
+}
+
+type issueReactionRequest struct {
+	Content string `json:"content" binding:"Required"`
+}
+
+// handleReactionError responses with proper status for errors of adding
+// reactions.
+func handleReactionError(c *context.APIContext, err error) {
+	if database.IsErrInvalidReaction(err) {
+		c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("The reaction is not allowed."))
+	} else if database.IsErrBlockedByUser(err) {
+		c.ErrorStatus(http.StatusForbidden, errors.New("The repository owner has blocked you."))
+	} else {
+		c.Error(err, "add reaction")
+	}
+}
+
+func listIssueReactions(c *context.APIContext) {
+	issue, err := database.GetIssueByIndex(c.Repo.Repository.ID, c.ParamsInt64(":index"))
+	if err != nil {
+		c.NotFoundOrError(err, "get issue by index")
+		return
+	}
+
+	reactions, err := database.GetIssueReactions(issue.ID)
+	if err != nil {
+		c.Error(err, "get issue reactions")
+		return
+	}
+
+	apiReactions, err := toReactions(reactions)
+	if err != nil {
+		c.Error(err, "convert reactions")
+		return
+	}
+	c.JSONSuccess(&apiReactions)
+}
+
+func createIssueReaction(c *context.APIContext, form issueReactionRequest) {
+	issue, err := database.GetIssueByIndex(c.Repo.Repository.ID, c.ParamsInt64(":index"))
+	if err != nil {
+		c.NotFoundOrError(err, "get issue by index")
+		return
+	}
+
+	ensureCanComment(c, issue)
+	if c.Written() {
+		return
+	}
+
+	reaction, err := issue.AddReaction(c.User, form.Content)
+	if err != nil {
+		handleReactionError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, toReaction(reaction))
+}
+
+func deleteIssueReaction(c *context.APIContext, form issueReactionRequest) {
+	issue, err := database.GetIssueByIndex(c.Repo.Repository.ID, c.ParamsInt64(":index"))
+	if err != nil {
+		c.NotFoundOrError(err, "get issue by index")
+		return
+	}
+
+	if err = issue.RemoveReaction(c.User, form.Content); err != nil {
+		c.Error(err, "remove reaction")
+		return
+	}
+	c.NoContent()
+}
+
+func listIssueCommentReactions(c *context.APIContext) {
+	_, comment := getReactionComment(c)
+	if c.Written() {
+		return
+	}
+
+	reactions, err := database.GetCommentReactions(comment)
+	if err != nil {
+		c.Error(err, "get comment reactions")
+		return
+	}
+
+	apiReactions, err := toReactions(reactions)
+	if err != nil {
+		c.Error(err, "convert reactions")
+		return
+	}
+	c.JSONSuccess(&apiReactions)
+}
+
+func createIssueCommentReaction(c *context.APIContext, form issueReactionRequest) {
+	issue, comment := getReactionComment(c)
+	if c.Written() {
+		return
+	}
+
+	ensureCanComment(c, issue)
+	if c.Written() {
+		return
+	}
+
+	reaction, err := issue.AddCommentReaction(c.User, comment, form.Content)
+	if err != nil {
+		handleReactionError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, toReaction(reaction))
+}
+
+func deleteIssueCommentReaction(c *context.APIContext, form issueReactionRequest) {
+	issue, comment := getReactionComment(c)
+	if c.Written() {
+		return
+	}
+
+	if err := issue.RemoveCommentReaction(c.User, comment, form.Content); err != nil {
+		c.Error(err, "remove reaction")
+		return
+	}
+	c.NoContent()
+}
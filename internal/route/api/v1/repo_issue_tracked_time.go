@@ -0,0 +1,166 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/database"
+	"gogs.io/gogs/internal/route/api/v1/types"
+)
+
+// toTrackedTimes converts tracked times to API tracked times with attributes
+// loaded.
+func toTrackedTimes(times database.TrackedTimeList) ([]*types.TrackedTime, error) {
+	if err := times.LoadAttributes(); err != nil {
+		return nil, errors.Wrap(err, "load attributes")
+	}
+
+	apiTimes := make([]*types.TrackedTime, len(times))
+	for i := range times {
+		apiTimes[i] = times[i].APIFormat()
+	}
+	return apiTimes, nil
+}
+
+func listIssueTrackedTimes(c *context.APIContext) {
+	issue, err := database.GetIssueByIndex(c.Repo.Repository.ID, c.ParamsInt64(":index"))
+	if err != nil {
+		c.NotFoundOrError(err, "get issue by index")
+		return
+	}
+
+	times, err := database.GetTrackedTimes(database.TrackedTimesOptions{IssueID: issue.ID})
+	if err != nil {
+		c.Error(err, "get tracked times")
+		return
+	}
+	for _, t := range times {
+		t.Issue = issue
+	}
+
+	apiTimes, err := toTrackedTimes(times)
+	if err != nil {
+		c.Error(err, "convert tracked times")
+		return
+	}
+	c.JSONSuccess(&apiTimes)
+}
+
+type addTrackedTimeRequest struct {
+	Time    int64      `json:"time" binding:"Required"` // In seconds.
+	Created *time.Time `json:"created"`
+}
+
+func addIssueTrackedTime(c *context.APIContext, form addTrackedTimeRequest) {
+	issue, err := database.GetIssueByIndex(c.Repo.Repository.ID, c.ParamsInt64(":index"))
+	if err != nil {
+		c.NotFoundOrError(err, "get issue by index")
+		return
+	}
+
+	var created time.Time
+	if form.Created != nil {
+		created = *form.Created
+	}
+	t, err := issue.AddTrackedTime(c.User, form.Time, created)
+	if err != nil {
+		if database.IsErrInvalidTrackedTime(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("Time must be positive."))
+		} else {
+			c.Error(err, "add tracked time")
+		}
+		return
+	}
+
+	t.Issue = issue
+	t.User = c.User
+	if err = t.LoadAttributes(); err != nil {
+		c.Error(err, "load attributes")
+		return
+	}
+	c.JSON(http.StatusCreated, t.APIFormat())
+}
+
+func deleteIssueTrackedTime(c *context.APIContext) {
+	issue, err := database.GetIssueByIndex(c.Repo.Repository.ID, c.ParamsInt64(":index"))
+	if err != nil {
+		c.NotFoundOrError(err, "get issue by index")
+		return
+	}
+
+	t, err := issue.GetTrackedTimeByID(c.ParamsInt64(":id"))
+	if err != nil {
+		c.NotFoundOrError(err, "get tracked time by ID")
+		return
+	}
+
+	if t.UserID != c.User.ID && !c.Repo.IsAdmin() {
+		c.Status(http.StatusForbidden)
+		return
+	}
+
+	if err = issue.DeleteTrackedTime(t.ID); err != nil {
+		c.Error(err, "delete tracked time")
+		return
+	}
+
+	c.NoContent()
+}
+
+func startIssueStopwatch(c *context.APIContext) {
+	issue, err := database.GetIssueByIndex(c.Repo.Repository.ID, c.ParamsInt64(":index"))
+	if err != nil {
+		c.NotFoundOrError(err, "get issue by index")
+		return
+	}
+
+	if err = issue.StartStopwatch(c.User); err != nil {
+		c.Error(err, "start stopwatch")
+		return
+	}
+
+	c.NoContent()
+}
+
+func stopIssueStopwatch(c *context.APIContext) {
+	issue, err := database.GetIssueByIndex(c.Repo.Repository.ID, c.ParamsInt64(":index"))
+	if err != nil {
+		c.NotFoundOrError(err, "get issue by index")
+		return
+	}
+
+	t, err := issue.StopStopwatch(c.User)
+	if err != nil {
+		c.NotFoundOrError(err, "stop stopwatch")
+		return
+	} else if t == nil {
+		c.NoContent()
+		return
+	}
+
+	t.Issue = issue
+	t.User = c.User
+	if err = t.LoadAttributes(); err != nil {
+		c.Error(err, "load attributes")
+		return
+	}
+	c.JSON(http.StatusCreated, t.APIFormat())
+}
+
+func deleteIssueStopwatch(c *context.APIContext) {
+	issue, err := database.GetIssueByIndex(c.Repo.Repository.ID, c.ParamsInt64(":index"))
+	if err != nil {
+		c.NotFoundOrError(err, "get issue by index")
+		return
+	}
+
+	if err = issue.CancelStopwatch(c.User); err != nil {
+		c.Error(err, "cancel stopwatch")
+		return
+	}
+
+	c.NoContent()
+}
@@ -0,0 +1,65 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/cockroachdb/errors"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/database"
+)
+
+type transferIssueRequest struct {
+	Owner string `json:"owner" binding:"Required"`
+	Repo  string `json:"repo" binding:"Required"`
+}
+
+func transferIssue(c *context.APIContext, form transferIssueRequest) {
+	issue, err := database.GetIssueByIndex(c.Repo.Repository.ID, c.ParamsInt64(":index"))
+	if err != nil {
+		c.NotFoundOrError(err, "get issue by index")
+		return
+	}
+	if issue.IsPull {
+		c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("Pull requests cannot be transferred."))
+		return
+	}
+
+	owner, err := database.Handle.Users().GetByUsername(c.Req.Context(), form.Owner)
+	if err != nil {
+		if database.IsErrUserNotExist(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("The target repository does not exist."))
+		} else {
+			c.Error(err, "get user by name")
+		}
+		return
+	}
+	newRepo, err := database.GetRepositoryByName(owner.ID, form.Repo)
+	if err != nil {
+		if database.IsErrRepoNotExist(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("The target repository does not exist."))
+		} else {
+			c.Error(err, "get repository by name")
+		}
+		return
+	}
+
+	if !database.Handle.Permissions().Authorize(c.Req.Context(), c.User.ID, newRepo.ID, database.AccessModeWrite,
+		database.AccessModeOptions{
+			OwnerID: newRepo.OwnerID,
+			Private: newRepo.IsPrivate,
+		},
+	) {
+		c.ErrorStatus(http.StatusForbidden, errors.New("Write access to the target repository is required."))
+		return
+	} else if newRepo.ID == issue.RepoID || !newRepo.EnableIssues || newRepo.EnableExternalTracker {
+		c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("The target repository does not accept issues."))
+		return
+	}
+
+	if err = database.TransferIssue(c.User, issue, newRepo); err != nil {
+		c.Error(err, "transfer issue")
+		return
+	}
+	c.JSON(http.StatusCreated, toIssue(issue))
+}
@@ -0,0 +1,197 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/cockroachdb/errors"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/database"
+	"gogs.io/gogs/internal/route/api/v1/types"
+)
+
+func updatePullRequestBranch(c *context.APIContext) {
+	issue, err := database.GetIssueByIndex(c.Repo.Repository.ID, c.ParamsInt64(":index"))
+	if err != nil {
+		c.NotFoundOrError(err, "get issue by index")
+		return
+	} else if !issue.IsPull {
+		c.NotFound()
+		return
+	}
+
+	if issue.IsClosed || issue.PullRequest.HasMerged {
+		c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("The pull request is closed or has been merged."))
+		return
+	}
+
+	pr := issue.PullRequest
+	if !pr.CanUpdateBranch(c.User.ID) {
+		c.ErrorStatus(http.StatusForbidden, errors.New("You are not allowed to update the head branch."))
+		return
+	}
+
+	style := database.UpdateStyle(c.Query("style"))
+	switch style {
+	case "":
+		style = database.UpdateStyleMerge
+	case database.UpdateStyleMerge, database.UpdateStyleRebase:
+	default:
+		c.ErrorStatus(http.StatusUnprocessableEntity, errors.Newf("Unsupported update style %q.", style))
+		return
+	}
+
+	if err = pr.UpdateBranch(c.User, style); err != nil {
+		switch {
+		case database.IsErrPullRequestUpToDate(err):
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("The head branch is already up to date."))
+		case database.IsErrPullRequestUpdateConflict(err):
+			c.ErrorStatus(http.StatusConflict, errors.New("The head branch cannot be updated without conflicts."))
+		default:
+			c.Error(err, "update branch")
+		}
+		return
+	}
+
+	c.NoContent()
+}
+
+// getReviewPullRequest returns the pull request of given index with attributes
+// required for updating review requests loaded.
+func getReviewPullRequest(c *context.APIContext) *database.PullRequest {
+	issue, err := database.GetIssueByIndex(c.Repo.Repository.ID, c.ParamsInt64(":index"))
+	if err != nil {
+		c.NotFoundOrError(err, "get issue by index")
+		return nil
+	} else if !issue.IsPull {
+		c.NotFound()
+		return nil
+	}
+
+	pr := issue.PullRequest
+	pr.Issue = issue
+	return pr
+}
+
+func toPullRequestReviewRequests(pr *database.PullRequest) *types.PullRequestReviewRequests {
+	requests := &types.PullRequestReviewRequests{
+		Users: make([]*types.User, len(pr.RequestedReviewers)),
+		Teams: make([]*types.OrganizationTeam, len(pr.RequestedTeams)),
+	}
+	for i := range pr.RequestedReviewers {
+		requests.Users[i] = toUser(pr.RequestedReviewers[i])
+	}
+	for i := range pr.RequestedTeams {
+		requests.Teams[i] = toOrganizationTeam(pr.RequestedTeams[i])
+	}
+	return requests
+}
+
+func listPullRequestReviewRequests(c *context.APIContext) {
+	pr := getReviewPullRequest(c)
+	if c.Written() {
+		return
+	}
+	c.JSONSuccess(toPullRequestReviewRequests(pr))
+}
+
+type pullRequestReviewRequestsRequest struct {
+	Reviewers     []string `json:"reviewers"`
+	TeamReviewers []string `json:"team_reviewers"`
+}
+
+// getReviewRequestTargets returns users and teams of given names, it responses
+// with 422 if any of them does not exist.
+func getReviewRequestTargets(c *context.APIContext, form pullRequestReviewRequestsRequest) ([]*database.User, []*database.Team) {
+	reviewers := make([]*database.User, 0, len(form.Reviewers))
+	for _, name := range form.Reviewers {
+		reviewer, err := database.Handle.Users().GetByUsername(c.Req.Context(), name)
+		if err != nil {
+			if database.IsErrUserNotExist(err) {
+				c.ErrorStatus(http.StatusUnprocessableEntity, errors.Newf("reviewer does not exist: [name: %s]", name))
+			} else {
+				c.Error(err, "get user by name")
+			}
+			return nil, nil
+		}
+		reviewers = append(reviewers, reviewer)
+	}
+
+	teams := make([]*database.Team, 0, len(form.TeamReviewers))
+	for _, name := range form.TeamReviewers {
+		team, err := database.GetTeamOfOrgByName(c.Repo.Owner.ID, name)
+		if err != nil {
+			if database.IsErrTeamNotExist(err) {
+				c.ErrorStatus(http.StatusUnprocessableEntity, errors.Newf("team does not exist: [name: %s]", name))
+			} else {
+				c.Error(err, "get team of organization by name")
+			}
+			return nil, nil
+		}
+		teams = append(teams, team)
+	}
+	return reviewers, teams
+}
+
+func createPullRequestReviewRequests(c *context.APIContext, form pullRequestReviewRequestsRequest) {
+	pr := getReviewPullRequest(c)
+	if c.Written() {
+		return
+	}
+
+	reviewers, teams := getReviewRequestTargets(c, form)
+	if c.Written() {
+		return
+	}
+
+	var err error
+	for _, reviewer := range reviewers {
+		if err = pr.RequestReview(c.User, reviewer); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		for _, team := range teams {
+			if err = pr.RequestTeamReview(c.User, team); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		if database.IsErrInvalidReviewRequest(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, err)
+		} else {
+			c.Error(err, "request review")
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, toPullRequestReviewRequests(pr))
+}
+
+func deletePullRequestReviewRequests(c *context.APIContext, form pullRequestReviewRequestsRequest) {
+	pr := getReviewPullRequest(c)
+	if c.Written() {
+		return
+	}
+
+	reviewers, teams := getReviewRequestTargets(c, form)
+	if c.Written() {
+		return
+	}
+
+	for _, reviewer := range reviewers {
+		if err := pr.RemoveReviewRequest(c.User, reviewer); err != nil {
+			c.Error(err, "remove review request")
+			return
+		}
+	}
+	for _, team := range teams {
+		if err := pr.RemoveTeamReviewRequest(c.User, team); err != nil {
+			c.Error(err, "remove team review request")
+			return
+		}
+	}
+
+	c.NoContent()
+}
@@ -15,11 +15,18 @@ import (
 )
 
 func searchRepos(c *context.APIContext) {
+	// NOTE: An empty keyword must not become "." to allow searching by filters only.
+	keyword := c.Query("q")
+	if keyword != "" {
+		keyword = path.Base(keyword)
+	}
 	opts := &database.SearchRepoOptions{
-		Keyword:  path.Base(c.Query("q")),
+		Keyword:  keyword,
 		OwnerID:  c.QueryInt64("uid"),
 		PageSize: toAllowedPageSize(c.QueryInt("limit")),
 		Page:     c.QueryInt("page"),
+		Language: c.Query("language"),
+		Topic:    c.Query("topic"),
 	}
 
 	// Check visibility.
@@ -394,6 +401,76 @@ func listForks(c *context.APIContext) {
 	c.JSONSuccess(&apiForks)
 }
 
+// listLanguages returns the number of bytes of each language in the default
+// branch of the repository.
+func listLanguages(c *context.APIContext) {
+	stats, err := database.GetLanguageStats(c.Repo.Repository.ID)
+	if err != nil {
+		c.Error(err, "get language stats")
+		return
+	}
+
+	languages := make(map[string]int64, len(stats))
+	for _, s := range stats {
+		languages[s.Language] = s.Size
+	}
+	c.JSONSuccess(languages)
+}
+
+func listTopics(c *context.APIContext) {
+	topics, err := database.GetRepoTopics(c.Repo.Repository.ID)
+	if err != nil {
+		c.Error(err, "get repository topics")
+		return
+	}
+	c.JSONSuccess(map[string][]string{"topics": topics})
+}
+
+type replaceTopicsRequest struct {
+	Topics []string `json:"topics"`
+}
+
+// replaceTopics replaces all topics of the repository, and responds with
+// normalized topics.
+func replaceTopics(c *context.APIContext, form replaceTopicsRequest) {
+	topics, err := database.NormalizeTopics(form.Topics)
+	if err != nil {
+		switch {
+		case database.IsErrInvalidTopic(err):
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.Newf("topic %q is not valid", err.(database.ErrInvalidTopic).Topic()))
+		case database.IsErrRepoTopicsLimit(err):
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.Newf("at most %d topics are allowed", database.MaxRepoTopics))
+		default:
+			c.Error(err, "normalize topics")
+		}
+		return
+	}
+
+	if err = database.SaveRepoTopics(c.Repo.Repository.ID, topics); err != nil {
+		c.Error(err, "save repository topics")
+		return
+	}
+	c.JSONSuccess(map[string][]string{"topics": topics})
+}
+
+// searchTopics returns topics of public repositories along with numbers of
+// repositories.
+func searchTopics(c *context.APIContext) {
+	opts := &database.SearchTopicOptions{
+		Keyword:  c.Query("q"),
+		Page:     c.QueryInt("page"),
+		PageSize: toAllowedPageSize(c.QueryInt("limit")),
+	}
+	topics, count, err := database.SearchTopics(opts)
+	if err != nil {
+		c.Error(err, "search topics")
+		return
+	}
+
+	c.SetLinkHeader(int(count), opts.PageSize)
+	c.JSONSuccess(topics)
+}
+
 type editIssueTrackerRequest struct {
 	EnableIssues          *bool   `json:"enable_issues"`
 	EnableExternalTracker *bool   `json:"enable_external_tracker"`
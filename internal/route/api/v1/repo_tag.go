@@ -1,7 +1,15 @@
 package v1
 
 import (
+	"net/http"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gogs/git-module"
+
 	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/database"
+	"gogs.io/gogs/internal/tool"
 )
 
 func listTags(c *context.APIContext) {
@@ -23,3 +31,167 @@ func listTags(c *context.APIContext) {
 
 	c.JSONSuccess(&apiTags)
 }
+
+// deleteTag deletes the tag along with its release. It is rejected if the tag
+// is protected and the user is not whitelisted.
+func deleteTag(c *context.APIContext) {
+	tagName := c.Params("*")
+	gitRepo, err := git.Open(c.Repo.Repository.RepoPath())
+	if err != nil {
+		c.Error(err, "open repository")
+		return
+	}
+	if !gitRepo.HasTag(tagName) {
+		c.NotFound()
+		return
+	}
+
+	allowed, err := database.CanUserChangeTag(c.Repo.Repository.ID, c.User.ID, tagName)
+	if err != nil {
+		c.Error(err, "check tag protection")
+		return
+	} else if !allowed {
+		c.ErrorStatus(http.StatusForbidden, errors.Newf("tag %q is protected", tagName))
+		return
+	}
+
+	rel, err := database.GetRelease(c.Repo.Repository.ID, tagName)
+	if err == nil {
+		err = database.DeleteReleaseOfRepoByID(c.Repo.Repository.ID, rel.ID)
+	} else if database.IsErrReleaseNotExist(err) {
+		err = gitRepo.DeleteTag(tagName)
+	}
+	if err != nil {
+		c.Error(err, "delete tag")
+		return
+	}
+
+	c.NoContent()
+}
+
+func listProtectedTags(c *context.APIContext) {
+	protectTags, err := database.GetProtectTagsByRepoID(c.Repo.Repository.ID)
+	if err != nil {
+		c.Error(err, "get protect tags by repository ID")
+		return
+	}
+
+	apiProtectedTags := make([]*protectedTag, len(protectTags))
+	for i := range protectTags {
+		if err = protectTags[i].LoadAttributes(); err != nil {
+			c.Error(err, "load attributes")
+			return
+		}
+		apiProtectedTags[i] = toProtectedTag(protectTags[i])
+	}
+
+	c.JSONSuccess(&apiProtectedTags)
+}
+
+func getProtectedTag(c *context.APIContext) {
+	protectTag, err := database.GetProtectTagByID(c.Repo.Repository.ID, c.ParamsInt64(":id"))
+	if err != nil {
+		c.NotFoundOrError(err, "get protect tag by ID")
+		return
+	}
+
+	if err = protectTag.LoadAttributes(); err != nil {
+		c.Error(err, "load attributes")
+		return
+	}
+	c.JSONSuccess(toProtectedTag(protectTag))
+}
+
+type protectedTagRequest struct {
+	Pattern        string   `json:"pattern" binding:"Required;MaxSize(255)"`
+	WhitelistUsers []string `json:"whitelist_users"`
+	WhitelistTeams []string `json:"whitelist_teams"`
+}
+
+// saveProtectedTag resolves names in the whitelist of the request, saves the
+// rule and responds with the saved rule.
+func saveProtectedTag(c *context.APIContext, protectTag *database.ProtectTag, form protectedTagRequest, status int) {
+	userIDs := make([]int64, 0, len(form.WhitelistUsers))
+	for _, name := range form.WhitelistUsers {
+		u, err := database.Handle.Users().GetByUsername(c.Req.Context(), name)
+		if err != nil {
+			if database.IsErrUserNotExist(err) {
+				c.ErrorStatus(http.StatusUnprocessableEntity, errors.Newf("user %q does not exist", name))
+			} else {
+				c.Error(err, "get user by name")
+			}
+			return
+		}
+		userIDs = append(userIDs, u.ID)
+	}
+
+	teamIDs := make([]int64, 0, len(form.WhitelistTeams))
+	if len(form.WhitelistTeams) > 0 && !c.Repo.Owner.IsOrganization() {
+		c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("teams can only be whitelisted for repositories of organizations"))
+		return
+	}
+	for _, name := range form.WhitelistTeams {
+		team, err := database.GetTeamOfOrgByName(c.Repo.Owner.ID, name)
+		if err != nil {
+			if database.IsErrTeamNotExist(err) {
+				c.ErrorStatus(http.StatusUnprocessableEntity, errors.Newf("team %q does not exist", name))
+			} else {
+				c.Error(err, "get team of organization by name")
+			}
+			return
+		}
+		teamIDs = append(teamIDs, team.ID)
+	}
+
+	protectTag.Pattern = strings.TrimSpace(form.Pattern)
+	protectTag.WhitelistUserIDs = strings.Join(tool.Int64sToStrings(userIDs), ",")
+	protectTag.WhitelistTeamIDs = strings.Join(tool.Int64sToStrings(teamIDs), ",")
+	if err := database.SaveProtectTag(c.Repo.Repository, protectTag); err != nil {
+		switch {
+		case database.IsErrInvalidTagPattern(err):
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.Newf("pattern %q is not valid", protectTag.Pattern))
+		case database.IsErrProtectTagAlreadyExist(err):
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.Newf("pattern %q already exists", protectTag.Pattern))
+		default:
+			c.Error(err, "save protect tag")
+		}
+		return
+	}
+
+	protectTag, err := database.GetProtectTagByID(c.Repo.Repository.ID, protectTag.ID)
+	if err != nil {
+		c.Error(err, "get protect tag by ID")
+		return
+	}
+	if err = protectTag.LoadAttributes(); err != nil {
+		c.Error(err, "load attributes")
+		return
+	}
+	c.JSON(status, toProtectedTag(protectTag))
+}
+
+func createProtectedTag(c *context.APIContext, form protectedTagRequest) {
+	saveProtectedTag(c, &database.ProtectTag{}, form, http.StatusCreated)
+}
+
+func editProtectedTag(c *context.APIContext, form protectedTagRequest) {
+	protectTag, err := database.GetProtectTagByID(c.Repo.Repository.ID, c.ParamsInt64(":id"))
+	if err != nil {
+		c.NotFoundOrError(err, "get protect tag by ID")
+		return
+	}
+	saveProtectedTag(c, protectTag, form, http.StatusOK)
+}
+
+func deleteProtectedTag(c *context.APIContext) {
+	if _, err := database.GetProtectTagByID(c.Repo.Repository.ID, c.ParamsInt64(":id")); err != nil {
+		c.NotFoundOrError(err, "get protect tag by ID")
+		return
+	}
+
+	if err := database.DeleteProtectTagByID(c.Repo.Repository.ID, c.ParamsInt64(":id")); err != nil {
+		c.Error(err, "delete protect tag by ID")
+		return
+	}
+	c.NoContent()
+}
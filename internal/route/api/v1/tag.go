@@ -1,8 +1,20 @@
 package v1
 
-import "gogs.io/gogs/internal/route/api/v1/types"
+import (
+	"time"
+
+	"gogs.io/gogs/internal/route/api/v1/types"
+)
 
 type tag struct {
 	Name   string                      `json:"name"`
 	Commit *types.WebhookPayloadCommit `json:"commit"`
 }
+
+type protectedTag struct {
+	ID             int64     `json:"id"`
+	Pattern        string    `json:"pattern"`
+	WhitelistUsers []string  `json:"whitelist_users"`
+	WhitelistTeams []string  `json:"whitelist_teams"`
+	Created        time.Time `json:"created_at"`
+}
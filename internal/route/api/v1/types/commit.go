@@ -17,6 +17,8 @@ type RepoCommit struct {
 	Committer *CommitUser `json:"committer"`
 	Message   string      `json:"message"`
 	Tree      *CommitMeta `json:"tree"`
+
+	Verification *CommitVerification `json:"verification"`
 }
 
 type Commit struct {
@@ -27,3 +29,11 @@ type Commit struct {
 	Committer  *User         `json:"committer"`
 	Parents    []*CommitMeta `json:"parents"`
 }
+
+type CommitVerification struct {
+	Verified   bool   `json:"verified"`
+	Reason     string `json:"reason"`
+	Signature  string `json:"signature"`
+	Payload    string `json:"payload"`
+	SigningKey string `json:"signing_key,omitempty"`
+}
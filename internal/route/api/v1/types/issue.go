@@ -15,19 +15,25 @@ type PullRequestMeta struct {
 }
 
 type Issue struct {
-	ID          int64            `json:"id"`
-	Index       int64            `json:"number"`
-	Poster      *User            `json:"user"`
-	Title       string           `json:"title"`
-	Body        string           `json:"body"`
-	Labels      []*IssueLabel    `json:"labels"`
-	Milestone   *IssueMilestone  `json:"milestone"`
-	Assignee    *User            `json:"assignee"`
-	State       IssueStateType   `json:"state"`
-	Comments    int              `json:"comments"`
-	Created     time.Time        `json:"created_at"`
-	Updated     time.Time        `json:"updated_at"`
-	PullRequest *PullRequestMeta `json:"pull_request"`
+	ID           int64            `json:"id"`
+	Index        int64            `json:"number"`
+	Poster       *User            `json:"user"`
+	Title        string           `json:"title"`
+	Body         string           `json:"body"`
+	Labels       []*IssueLabel    `json:"labels"`
+	Milestone    *IssueMilestone  `json:"milestone"`
+	Assignee     *User            `json:"assignee"`
+	Assignees    []*User          `json:"assignees"`
+	State        IssueStateType   `json:"state"`
+	Comments     int              `json:"comments"`
+	Reactions    int              `json:"reactions"`
+	TimeEstimate int64            `json:"time_estimate"` // In seconds.
+	Locked       bool             `json:"locked"`
+	LockReason   string           `json:"lock_reason"`
+	Pinned       bool             `json:"pinned"`
+	Created      time.Time        `json:"created_at"`
+	Updated      time.Time        `json:"updated_at"`
+	PullRequest  *PullRequestMeta `json:"pull_request"`
 }
 
 type IssueLabel struct {
@@ -56,3 +62,21 @@ type IssueComment struct {
 	Created time.Time `json:"created_at"`
 	Updated time.Time `json:"updated_at"`
 }
+
+type TrackedTime struct {
+	ID         int64     `json:"id"`
+	Created    time.Time `json:"created_at"`
+	Time       int64     `json:"time"` // In seconds.
+	UserID     int64     `json:"user_id"`
+	UserName   string    `json:"user_name"`
+	IssueID    int64     `json:"issue_id"`
+	IssueIndex int64     `json:"issue_number"`
+	Repo       string    `json:"repository"`
+}
+
+type Reaction struct {
+	ID      int64     `json:"id"`
+	User    *User     `json:"user"`
+	Content string    `json:"content"`
+	Created time.Time `json:"created_at"`
+}
@@ -30,6 +30,17 @@ type UserPublicKey struct {
 	Created time.Time `json:"created_at,omitempty"`
 }
 
+type UserGPGKey struct {
+	ID          int64     `json:"id"`
+	KeyID       string    `json:"key_id"`
+	Fingerprint string    `json:"fingerprint"`
+	PublicKey   string    `json:"public_key"`
+	Emails      []string  `json:"emails"`
+	CanSign     bool      `json:"can_sign"`
+	Created     time.Time `json:"created_at"`
+	Expires     time.Time `json:"expires_at,omitempty"`
+}
+
 type RepositoryCollaborator struct {
 	*User
 	Permissions RepositoryPermission `json:"permissions"`
@@ -30,6 +30,8 @@ type WebhookPayloadCommit struct {
 	Removed   []string            `json:"removed"`
 	Modified  []string            `json:"modified"`
 	Timestamp time.Time           `json:"timestamp"`
+
+	Verification *CommitVerification `json:"verification,omitempty"`
 }
 
 type WebhookPusherType string
@@ -50,6 +52,12 @@ const (
 	WebhookIssueMilestoned   WebhookIssueAction = "milestoned"
 	WebhookIssueDemilestoned WebhookIssueAction = "demilestoned"
 	WebhookIssueSynchronized WebhookIssueAction = "synchronized"
+
+	WebhookIssueReviewRequested      WebhookIssueAction = "review_requested"
+	WebhookIssueReviewRequestRemoved WebhookIssueAction = "review_request_removed"
+
+	WebhookIssueReacted   WebhookIssueAction = "reacted"
+	WebhookIssueUnreacted WebhookIssueAction = "unreacted"
 )
 
 type WebhookIssueCommentAction string
@@ -58,6 +66,9 @@ const (
 	WebhookIssueCommentCreated WebhookIssueCommentAction = "created"
 	WebhookIssueCommentEdited  WebhookIssueCommentAction = "edited"
 	WebhookIssueCommentDeleted WebhookIssueCommentAction = "deleted"
+
+	WebhookIssueCommentReacted   WebhookIssueCommentAction = "reacted"
+	WebhookIssueCommentUnreacted WebhookIssueCommentAction = "unreacted"
 )
 
 type WebhookReleaseAction string
@@ -120,6 +131,8 @@ type WebhookIssuesPayload struct {
 	Index      int64                  `json:"number"`
 	Issue      *Issue                 `json:"issue"`
 	Changes    *WebhookChangesPayload `json:"changes,omitempty"`
+	Assignee   *User                  `json:"assignee,omitempty"`
+	Reaction   *Reaction              `json:"reaction,omitempty"`
 	Repository *Repository            `json:"repository"`
 	Sender     *User                  `json:"sender"`
 }
@@ -131,6 +144,7 @@ type WebhookIssueCommentPayload struct {
 	Issue      *Issue                    `json:"issue"`
 	Comment    *IssueComment             `json:"comment"`
 	Changes    *WebhookChangesPayload    `json:"changes,omitempty"`
+	Reaction   *Reaction                 `json:"reaction,omitempty"`
 	Repository *Repository               `json:"repository"`
 	Sender     *User                     `json:"sender"`
 }
@@ -138,12 +152,16 @@ type WebhookIssueCommentPayload struct {
 func (p *WebhookIssueCommentPayload) JSONPayload() ([]byte, error) { return jsonPayload(p) }
 
 type WebhookPullRequestPayload struct {
-	Action      WebhookIssueAction     `json:"action"`
-	Index       int64                  `json:"number"`
-	PullRequest *PullRequest           `json:"pull_request"`
-	Changes     *WebhookChangesPayload `json:"changes,omitempty"`
-	Repository  *Repository            `json:"repository"`
-	Sender      *User                  `json:"sender"`
+	Action            WebhookIssueAction     `json:"action"`
+	Index             int64                  `json:"number"`
+	PullRequest       *PullRequest           `json:"pull_request"`
+	Changes           *WebhookChangesPayload `json:"changes,omitempty"`
+	Assignee          *User                  `json:"assignee,omitempty"`
+	RequestedReviewer *User                  `json:"requested_reviewer,omitempty"`
+	RequestedTeam     *OrganizationTeam      `json:"requested_team,omitempty"`
+	Reaction          *Reaction              `json:"reaction,omitempty"`
+	Repository        *Repository            `json:"repository"`
+	Sender            *User                  `json:"sender"`
 }
 
 func (p *WebhookPullRequestPayload) JSONPayload() ([]byte, error) { return jsonPayload(p) }
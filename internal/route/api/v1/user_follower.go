@@ -1,6 +1,10 @@
 package v1
 
 import (
+	"net/http"
+
+	"github.com/cockroachdb/errors"
+
 	"gogs.io/gogs/internal/context"
 	"gogs.io/gogs/internal/database"
 	"gogs.io/gogs/internal/route/api/v1/types"
@@ -90,7 +94,11 @@ func follow(c *context.APIContext) {
 		return
 	}
 	if err := database.Handle.Users().Follow(c.Req.Context(), c.User.ID, target.ID); err != nil {
-		c.Error(err, "follow user")
+		if database.IsErrBlockedByUser(err) {
+			c.ErrorStatus(http.StatusForbidden, errors.New("The user has blocked you."))
+		} else {
+			c.Error(err, "follow user")
+		}
 		return
 	}
 	c.NoContent()
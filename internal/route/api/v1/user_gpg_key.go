@@ -0,0 +1,77 @@
+package v1
+
+import (
+	"net/http"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/database"
+	"gogs.io/gogs/internal/route/api/v1/types"
+)
+
+func listGPGKeysOfUser(c *context.APIContext, uid int64) {
+	keys, err := database.Handle.GPGKeys().List(c.Req.Context(), uid)
+	if err != nil {
+		c.Error(err, "list GPG keys")
+		return
+	}
+
+	apiKeys := make([]*types.UserGPGKey, len(keys))
+	for i := range keys {
+		apiKeys[i] = toUserGPGKey(keys[i])
+	}
+
+	c.JSONSuccess(&apiKeys)
+}
+
+func listMyGPGKeys(c *context.APIContext) {
+	listGPGKeysOfUser(c, c.User.ID)
+}
+
+func listGPGKeys(c *context.APIContext) {
+	user := getUserByParams(c)
+	if c.Written() {
+		return
+	}
+	listGPGKeysOfUser(c, user.ID)
+}
+
+func getGPGKey(c *context.APIContext) {
+	key, err := database.Handle.GPGKeys().GetByID(c.Req.Context(), c.User.ID, c.ParamsInt64(":id"))
+	if err != nil {
+		c.NotFoundOrError(err, "get GPG key by ID")
+		return
+	}
+	c.JSONSuccess(toUserGPGKey(key))
+}
+
+type createGPGKeyRequest struct {
+	ArmoredPublicKey string `json:"armored_public_key" binding:"Required"`
+}
+
+func createGPGKey(c *context.APIContext, form createGPGKeyRequest) {
+	key, err := database.Handle.GPGKeys().Create(c.Req.Context(), c.User.ID, form.ArmoredPublicKey)
+	if err != nil {
+		if database.IsErrGPGKeyInvalid(err) || database.IsErrGPGKeyAlreadyExist(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, err)
+		} else {
+			c.Error(err, "create GPG key")
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, toUserGPGKey(key))
+}
+
+func deleteGPGKey(c *context.APIContext) {
+	_, err := database.Handle.GPGKeys().GetByID(c.Req.Context(), c.User.ID, c.ParamsInt64(":id"))
+	if err != nil {
+		c.NotFoundOrError(err, "get GPG key by ID")
+		return
+	}
+
+	err = database.Handle.GPGKeys().DeleteByID(c.Req.Context(), c.User.ID, c.ParamsInt64(":id"))
+	if err != nil {
+		c.Error(err, "delete GPG key")
+		return
+	}
+	c.NoContent()
+}
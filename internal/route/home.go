@@ -8,6 +8,7 @@ import (
 	"gogs.io/gogs/internal/conf"
 	"gogs.io/gogs/internal/context"
 	"gogs.io/gogs/internal/database"
+	"gogs.io/gogs/internal/route/repo"
 	"gogs.io/gogs/internal/route/user"
 )
 
@@ -15,6 +16,8 @@ const (
 	tmplExploreRepos         = "explore/repos"
 	tmplExploreUsers         = "explore/users"
 	tmplExploreOrganizations = "explore/organizations"
+	tmplExploreCode          = "explore/code"
+	tmplExploreTopics        = "explore/topics"
 )
 
 func Home(c *context.Context) {
@@ -41,9 +44,13 @@ func ExploreRepos(c *context.Context) {
 	}
 
 	keyword := c.Query("q")
+	language := c.Query("language")
+	topic := c.Query("topic")
 	repos, count, err := database.SearchRepositoryByName(&database.SearchRepoOptions{
 		Keyword:  keyword,
 		UserID:   c.UserID(),
+		Language: language,
+		Topic:    topic,
 		OrderBy:  "updated_unix DESC",
 		Page:     page,
 		PageSize: conf.UI.ExplorePagingNum,
@@ -53,9 +60,17 @@ func ExploreRepos(c *context.Context) {
 		return
 	}
 	c.Data["Keyword"] = keyword
+	c.Data["Language"] = language
+	c.Data["Topic"] = topic
 	c.Data["Total"] = count
 	c.Data["Page"] = paginater.New(int(count), conf.UI.ExplorePagingNum, page, 5)
 
+	c.Data["Languages"], err = database.GetPrimaryLanguages(c.UserID())
+	if err != nil {
+		c.Error(err, "get primary languages")
+		return
+	}
+
 	if err = database.RepositoryList(repos).LoadAttributes(); err != nil {
 		c.Error(err, "load attributes")
 		return
@@ -143,3 +158,39 @@ func ExploreOrganizations(c *context.Context) {
 		TplName:  tmplExploreOrganizations,
 	})
 }
+
+func ExploreCode(c *context.Context) {
+	c.Data["Title"] = c.Tr("explore")
+	c.Data["PageIsExplore"] = true
+	c.Data["PageIsExploreCode"] = true
+
+	repo.RenderCodeSearch(c, nil, tmplExploreCode)
+}
+
+func ExploreTopics(c *context.Context) {
+	c.Data["Title"] = c.Tr("explore")
+	c.Data["PageIsExplore"] = true
+	c.Data["PageIsExploreTopics"] = true
+
+	page := c.QueryInt("page")
+	if page <= 0 {
+		page = 1
+	}
+
+	keyword := c.Query("q")
+	topics, count, err := database.SearchTopics(&database.SearchTopicOptions{
+		Keyword:  keyword,
+		Page:     page,
+		PageSize: conf.UI.ExplorePagingNum,
+	})
+	if err != nil {
+		c.Error(err, "search topics")
+		return
+	}
+	c.Data["Keyword"] = keyword
+	c.Data["Total"] = count
+	c.Data["Page"] = paginater.New(int(count), conf.UI.ExplorePagingNum, page, 5)
+	c.Data["Topics"] = topics
+
+	c.Success(tmplExploreTopics)
+}
@@ -2,6 +2,7 @@ package org
 
 import (
 	"net/http"
+	"strings"
 
 	log "unknwon.dev/clog/v2"
 
@@ -11,11 +12,15 @@ import (
 	"gogs.io/gogs/internal/database"
 	"gogs.io/gogs/internal/form"
 	"gogs.io/gogs/internal/route/user"
+	"gogs.io/gogs/internal/template"
 )
 
 const (
-	tmplOrgSettingsOptions = "org/settings/options"
-	tmplOrgSettingsDelete  = "org/settings/delete"
+	tmplOrgSettingsOptions      = "org/settings/options"
+	tmplOrgSettingsBlockedUsers = "org/settings/blocked_users"
+	tmplOrgSettingsBranches     = "org/settings/branches"
+	tmplOrgSettingsBranch       = "org/settings/protected_branch"
+	tmplOrgSettingsDelete       = "org/settings/delete"
 )
 
 func Settings(c *context.Context) {
@@ -94,6 +99,161 @@ func SettingsDeleteAvatar(c *context.Context) {
 	c.Redirect(c.Org.OrgLink + "/settings")
 }
 
+func SettingsBlockedUsers(c *context.Context) {
+	c.Title("org.settings")
+	c.PageIs("SettingsBlockedUsers")
+
+	user.ListBlockedUsers(c, c.Org.Organization)
+	if c.Written() {
+		return
+	}
+
+	c.Success(tmplOrgSettingsBlockedUsers)
+}
+
+func SettingsBlockedUsersPost(c *context.Context) {
+	user.BlockUser(c, c.Org.Organization)
+	if c.Written() {
+		return
+	}
+
+	c.Redirect(c.Org.OrgLink + "/settings/blocked_users")
+}
+
+func SettingsUnblockUser(c *context.Context) {
+	if err := database.Handle.UserBlocks().Unblock(c.Req.Context(), c.Org.Organization.ID, c.QueryInt64("id")); err != nil {
+		c.Error(err, "unblock user")
+		return
+	}
+
+	c.Flash.Success(c.Tr("settings.unblock_success"))
+	c.JSONSuccess(map[string]any{
+		"redirect": c.Org.OrgLink + "/settings/blocked_users",
+	})
+}
+
+func SettingsProtectedBranches(c *context.Context) {
+	c.Title("org.settings.protected_branches")
+	c.PageIs("SettingsBranches")
+
+	// Redirect to the rule of given pattern
+	if pattern := strings.TrimSpace(c.Query("pattern")); pattern != "" {
+		if !database.IsValidBranchPattern(pattern) {
+			c.Flash.Error(c.Tr("repo.settings.protect_branch_pattern_invalid"))
+			c.Redirect(c.Org.OrgLink + "/settings/branches")
+			return
+		}
+		c.Redirect(c.Org.OrgLink + "/settings/branches/" + template.EscapePound(pattern))
+		return
+	}
+
+	protectBranches, err := database.GetProtectBranchesByOrgID(c.Org.Organization.ID)
+	if err != nil {
+		c.Error(err, "get protect branches by organization ID")
+		return
+	}
+	c.Data["ProtectBranches"] = protectBranches
+
+	c.Success(tmplOrgSettingsBranches)
+}
+
+// getOrgProtectBranchOfPattern returns the default rule of the organization
+// with the branch name or pattern in the URL, or a new rule if not exists. It
+// returns nil if any error occurred.
+func getOrgProtectBranchOfPattern(c *context.Context) *database.ProtectBranch {
+	pattern := c.Params("*")
+	if !database.IsValidBranchPattern(pattern) {
+		c.NotFound()
+		return nil
+	}
+
+	protectBranch, err := database.GetProtectBranchOfOrgByPattern(c.Org.Organization.ID, pattern)
+	if err != nil {
+		if !database.IsErrBranchNotExist(err) {
+			c.Error(err, "get protect branch of organization by pattern")
+			return nil
+		}
+
+		// No options found, create defaults.
+		protectBranch = &database.ProtectBranch{
+			OrgID: c.Org.Organization.ID,
+			Name:  pattern,
+		}
+	}
+	return protectBranch
+}
+
+func SettingsProtectedBranch(c *context.Context) {
+	protectBranch := getOrgProtectBranchOfPattern(c)
+	if c.Written() {
+		return
+	}
+
+	c.Data["Title"] = c.Tr("org.settings.protected_branches") + " - " + protectBranch.Name
+	c.PageIs("SettingsBranches")
+
+	org := c.Org.Organization
+	if err := org.GetMembers(0); err != nil {
+		c.Error(err, "get members")
+		return
+	}
+	c.Data["Users"] = org.Members
+	c.Data["whitelist_users"] = protectBranch.WhitelistUserIDs
+
+	if err := org.GetTeams(); err != nil {
+		c.Error(err, "get teams")
+		return
+	}
+	c.Data["Teams"] = org.Teams
+	c.Data["whitelist_teams"] = protectBranch.WhitelistTeamIDs
+	c.Data["CanWhitelist"] = true
+
+	c.Data["Branch"] = protectBranch
+	c.Success(tmplOrgSettingsBranch)
+}
+
+func SettingsProtectedBranchPost(c *context.Context, f form.ProtectBranch) {
+	protectBranch := getOrgProtectBranchOfPattern(c)
+	if c.Written() {
+		return
+	}
+
+	protectBranch.Protected = f.Protected
+	protectBranch.RequirePullRequest = f.RequirePullRequest
+	protectBranch.RequireSignedCommits = f.RequireSignedCommits
+	protectBranch.RequireLinearHistory = f.RequireLinearHistory
+	protectBranch.RequireCodeOwnerSignOff = f.RequireCodeOwnerSignOff
+	protectBranch.EnableWhitelist = f.EnableWhitelist
+	protectBranch.WhitelistUserIDs = f.WhitelistUsers
+	protectBranch.WhitelistTeamIDs = f.WhitelistTeams
+	if err := database.UpdateOrgDefaultProtectBranch(c.Org.Organization, protectBranch); err != nil {
+		c.Error(err, "update organization default protect branch")
+		return
+	}
+
+	c.Flash.Success(c.Tr("repo.settings.update_protect_branch_success"))
+	c.Redirect(c.Org.OrgLink + "/settings/branches/" + template.EscapePound(protectBranch.Name))
+}
+
+func DeleteProtectedBranch(c *context.Context) {
+	protectBranch, err := database.GetProtectBranchByID(c.QueryInt64("id"))
+	if err != nil {
+		if !database.IsErrBranchNotExist(err) {
+			c.Flash.Error("GetProtectBranchByID: " + err.Error())
+		}
+	} else if protectBranch.IsOrgDefault() && protectBranch.OrgID == c.Org.Organization.ID {
+		if err = database.DeleteProtectBranch(protectBranch); err != nil {
+			c.Flash.Error("DeleteProtectBranch: " + err.Error())
+		} else {
+			c.Flash.Success(c.Tr("repo.settings.protect_branch_deletion_success"))
+		}
+	}
+
+	c.JSONSuccess(map[string]any{
+		"redirect": c.Org.OrgLink + "/settings/branches",
+	})
+}
+
 func SettingsDelete(c *context.Context) {
 	c.Title("org.settings")
 	c.PageIs("SettingsDelete")
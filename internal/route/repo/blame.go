@@ -0,0 +1,103 @@
+package repo
+
+import (
+	gocontext "context"
+	"strings"
+	"time"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/database"
+	"gogs.io/gogs/internal/gitx"
+	"gogs.io/gogs/internal/template/highlight"
+	"gogs.io/gogs/internal/tool"
+)
+
+const tmplRepoBlame = "repo/blame"
+
+type blamePart struct {
+	User *database.User
+	*gitx.BlamePart
+}
+
+// matchUsersWithBlameParts matches existing users using emails of authors of
+// commits that last modified each part of the blamed file.
+func matchUsersWithBlameParts(ctx gocontext.Context, parts []*gitx.BlamePart) []*blamePart {
+	emailToUsers := make(map[string]*database.User)
+	newParts := make([]*blamePart, len(parts))
+	for i := range parts {
+		email := parts[i].Commit.Author.Email
+		u, ok := emailToUsers[email]
+		if !ok {
+			u = tryGetUserByEmail(ctx, email)
+			emailToUsers[email] = u
+		}
+
+		newParts[i] = &blamePart{
+			User:      u,
+			BlamePart: parts[i],
+		}
+	}
+	return newParts
+}
+
+// blameTimeout returns the timeout duration for blaming a file, which is as
+// expensive as generating a diff.
+func blameTimeout() time.Duration {
+	return time.Duration(conf.Git.Timeout.Diff) * time.Second
+}
+
+func Blame(c *context.Context) {
+	c.Data["PageIsViewFiles"] = true
+	c.Data["RequireHighlightJS"] = true
+
+	entry, err := c.Repo.Commit.TreeEntry(c.Repo.TreePath)
+	if err != nil {
+		c.NotFoundOrError(gitx.NewError(err), "get tree entry")
+		return
+	} else if entry.IsTree() {
+		c.NotFound()
+		return
+	}
+
+	blob := entry.Blob()
+	c.Data["Title"] = blob.Name() + " - " + c.Repo.Repository.FullName()
+	c.Data["FileName"] = blob.Name()
+	c.Data["FileSize"] = blob.Size()
+	c.Data["HighlightClass"] = highlight.FileNameToHighlightClass(blob.Name())
+	c.Data["RawFileLink"] = c.Repo.RepoLink + "/raw/" + c.Repo.CommitID + "/" + c.Repo.TreePath
+
+	treeNames := strings.Split(c.Repo.TreePath, "/")
+	paths := make([]string, len(treeNames))
+	for i := range treeNames {
+		paths[i] = strings.Join(treeNames[:i+1], "/")
+	}
+	c.Data["TreeNames"] = treeNames
+	c.Data["Paths"] = paths
+	c.Data["BranchLink"] = c.Repo.RepoLink + "/src/" + c.Repo.BranchName
+
+	if blob.Size() >= conf.UI.MaxDisplayFileSize {
+		c.Data["IsFileTooLarge"] = true
+		c.Success(tmplRepoBlame)
+		return
+	}
+
+	p, err := blob.Bytes()
+	if err != nil {
+		c.Error(err, "read blob")
+		return
+	}
+	if !tool.IsTextFile(p) {
+		c.Data["IsBinaryFile"] = true
+		c.Success(tmplRepoBlame)
+		return
+	}
+
+	parts, err := gitx.RepoBlame(c.Repo.GitRepo.Path(), c.Repo.CommitID, c.Repo.TreePath, blameTimeout())
+	if err != nil {
+		c.Error(err, "blame file")
+		return
+	}
+	c.Data["BlameParts"] = matchUsersWithBlameParts(c.Req.Context(), parts)
+	c.Success(tmplRepoBlame)
+}
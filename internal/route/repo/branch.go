@@ -31,9 +31,9 @@ func loadBranches(c *context.Context) []*Branch {
 		return nil
 	}
 
-	protectBranches, err := database.GetProtectBranchesByRepoID(c.Repo.Repository.ID)
+	protectBranches, err := database.GetProtectBranchRulesOfRepo(c.Repo.Repository.ID)
 	if err != nil {
-		c.Error(err, "get protect branches by repository ID")
+		c.Error(err, "get protect branch rules of repository")
 		return nil
 	}
 
@@ -45,16 +45,11 @@ func loadBranches(c *context.Context) []*Branch {
 			return nil
 		}
 
+		protectBranch := database.MatchProtectBranch(protectBranches, rawBranches[i].Name)
 		branches[i] = &Branch{
-			Name:   rawBranches[i].Name,
-			Commit: commit,
-		}
-
-		for j := range protectBranches {
-			if branches[i].Name == protectBranches[j].Name {
-				branches[i].IsProtected = true
-				break
-			}
+			Name:        rawBranches[i].Name,
+			Commit:      commit,
+			IsProtected: protectBranch != nil && protectBranch.Protected,
 		}
 	}
 
@@ -54,7 +54,7 @@ func renderCommits(c *context.Context, filename string) {
 	}
 
 	commits = RenderIssueLinks(commits, c.Repo.RepoLink)
-	c.Data["Commits"] = matchUsersWithCommitEmails(c.Req.Context(), commits)
+	c.Data["Commits"] = matchUsersWithCommitEmails(c.Req.Context(), c.Repo.GitRepo.Path(), commits)
 
 	if page > 1 {
 		c.Data["HasPrevious"] = true
@@ -91,7 +91,7 @@ func SearchCommits(c *context.Context) {
 	}
 
 	commits = RenderIssueLinks(commits, c.Repo.RepoLink)
-	c.Data["Commits"] = matchUsersWithCommitEmails(c.Req.Context(), commits)
+	c.Data["Commits"] = matchUsersWithCommitEmails(c.Req.Context(), c.Repo.GitRepo.Path(), commits)
 
 	c.Data["Keyword"] = keyword
 	c.Data["Username"] = c.Repo.Owner.Name
@@ -112,15 +112,18 @@ func tryGetUserByEmail(ctx gocontext.Context, email string) *database.User {
 }
 
 type userCommit struct {
-	User *database.User
+	User         *database.User
+	Verification *database.CommitVerification
 	*git.Commit
 }
 
-// matchUsersWithCommitEmails matches existing users using commit author emails.
-func matchUsersWithCommitEmails(ctx gocontext.Context, oldCommits []*git.Commit) []*userCommit {
+// matchUsersWithCommitEmails matches existing users using commit author emails,
+// and verifies signatures of commits in the repository.
+func matchUsersWithCommitEmails(ctx gocontext.Context, repoPath string, oldCommits []*git.Commit) []*userCommit {
 	emailToUsers := make(map[string]*database.User)
 	newCommits := make([]*userCommit, len(oldCommits))
 	usersStore := database.Handle.Users()
+	verifications := database.VerifyCommits(ctx, repoPath, oldCommits)
 	for i := range oldCommits {
 		var u *database.User
 		if v, ok := emailToUsers[oldCommits[i].Author.Email]; !ok {
@@ -131,8 +134,9 @@ func matchUsersWithCommitEmails(ctx gocontext.Context, oldCommits []*git.Commit)
 		}
 
 		newCommits[i] = &userCommit{
-			User:   u,
-			Commit: oldCommits[i],
+			User:         u,
+			Verification: verifications[oldCommits[i].ID.String()],
+			Commit:       oldCommits[i],
 		}
 	}
 	return newCommits
@@ -168,7 +172,7 @@ func CompareDiff(c *context.Context) {
 
 	c.Data["IsSplitStyle"] = c.Query("style") == "split"
 	c.Data["CommitRepoLink"] = c.Repo.RepoLink
-	c.Data["Commits"] = matchUsersWithCommitEmails(c.Req.Context(), commits)
+	c.Data["Commits"] = matchUsersWithCommitEmails(c.Req.Context(), c.Repo.GitRepo.Path(), commits)
 	c.Data["CommitsCount"] = len(commits)
 	c.Data["BeforeCommitID"] = beforeCommitID
 	c.Data["AfterCommitID"] = afterCommitID
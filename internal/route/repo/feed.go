@@ -0,0 +1,156 @@
+package repo
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/gogs/git-module"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/database"
+	"gogs.io/gogs/internal/feed"
+	"gogs.io/gogs/internal/gitx"
+	"gogs.io/gogs/internal/markup"
+	"gogs.io/gogs/internal/template"
+)
+
+// preformatted returns the plain text as an HTML preformatted block.
+func preformatted(text string) string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ""
+	}
+	return "<pre>" + html.EscapeString(text) + "</pre>"
+}
+
+// absoluteLinks makes root-relative links of rendered HTML absolute because
+// feed readers do not know the base URL of the site.
+func absoluteLinks(content string) string {
+	host := strings.TrimSuffix(strings.TrimSuffix(conf.Server.ExternalURL, "/"), conf.Server.Subpath)
+	return strings.NewReplacer(
+		` href="/`, ` href="`+host+"/",
+		` src="/`, ` src="`+host+"/",
+	).Replace(content)
+}
+
+// ReleasesFeed renders the Atom or RSS feed of published releases of the
+// repository.
+func ReleasesFeed(c *context.Context) {
+	repo := c.Repo.Repository
+	releases, err := database.GetPublishedReleasesByRepoID(repo.ID)
+	if err != nil {
+		c.Error(err, "get published releases by repository ID")
+		return
+	}
+	if len(releases) > conf.UI.FeedPagingNum {
+		releases = releases[:conf.UI.FeedPagingNum]
+	}
+
+	items := make([]*feed.Item, 0, len(releases))
+	for _, r := range releases {
+		if err = r.LoadAttributes(); err != nil {
+			c.Error(err, "load attributes")
+			return
+		}
+
+		title := r.Title
+		if title == "" {
+			title = r.TagName
+		}
+		items = append(items, &feed.Item{
+			ID:      fmt.Sprintf("%s/releases#%d", repo.HTMLURL(), r.ID),
+			Title:   title,
+			Link:    repo.HTMLURL() + "/src/" + template.EscapePound(r.TagName),
+			Content: absoluteLinks(string(markup.Markdown(r.Note, c.Repo.RepoLink, repo.ComposeMetas()))),
+			Author:  r.Publisher.Name,
+			Updated: r.Created,
+		})
+	}
+
+	c.Feed(&feed.Feed{
+		Title: c.Tr("repo.feed.releases_title", repo.FullName()),
+		Link:  repo.HTMLURL() + "/releases",
+		Items: items,
+	}, feed.Format(c.Params(":format")))
+}
+
+// TagsFeed renders the Atom or RSS feed of tags of the repository.
+func TagsFeed(c *context.Context) {
+	repo := c.Repo.Repository
+	tagsPage, err := gitx.Module.ListTagsAfter(c.Repo.GitRepo.Path(), "", conf.UI.FeedPagingNum)
+	if err != nil {
+		c.Error(err, "list tags")
+		return
+	}
+
+	items := make([]*feed.Item, 0, len(tagsPage.Tags))
+	for _, name := range tagsPage.Tags {
+		tag, err := c.Repo.GitRepo.Tag(name)
+		if err != nil {
+			c.Error(err, "get tag")
+			return
+		}
+		commit, err := tag.Commit()
+		if err != nil {
+			c.Error(err, "get tag commit")
+			return
+		}
+
+		// Annotated tags have their own taggers and messages.
+		author := commit.Author
+		content := commit.Message
+		if tag.Type() == git.ObjectTag && tag.Tagger() != nil {
+			author = tag.Tagger()
+			content = tag.Message()
+		}
+		items = append(items, &feed.Item{
+			Title:   name,
+			Link:    repo.HTMLURL() + "/src/" + template.EscapePound(name),
+			Content: preformatted(content),
+			Author:  author.Name,
+			Updated: author.When,
+		})
+	}
+
+	c.Feed(&feed.Feed{
+		Title: c.Tr("repo.feed.tags_title", repo.FullName()),
+		Link:  repo.HTMLURL() + "/releases",
+		Items: items,
+	}, feed.Format(c.Params(":format")))
+}
+
+// BranchFeed renders the Atom or RSS feed of latest commits on a branch of
+// the repository.
+func BranchFeed(c *context.Context) {
+	repo := c.Repo.Repository
+	branch := c.Params("*")
+	if !c.Repo.GitRepo.HasBranch(branch) {
+		c.NotFound()
+		return
+	}
+
+	commits, err := c.Repo.GitRepo.Log(git.RefsHeads+branch, git.LogOptions{MaxCount: conf.UI.FeedPagingNum})
+	if err != nil {
+		c.Error(err, "get commits")
+		return
+	}
+
+	items := make([]*feed.Item, 0, len(commits))
+	for _, commit := range commits {
+		items = append(items, &feed.Item{
+			Title:   commit.Summary(),
+			Link:    repo.HTMLURL() + "/commit/" + commit.ID.String(),
+			Content: preformatted(commit.Message),
+			Author:  commit.Author.Name,
+			Updated: commit.Committer.When,
+		})
+	}
+
+	c.Feed(&feed.Feed{
+		Title: c.Tr("repo.feed.commits_title", repo.FullName(), branch),
+		Link:  repo.HTMLURL() + "/commits/" + template.EscapePound(branch),
+		Items: items,
+	}, feed.Format(c.Params(":format")))
+}
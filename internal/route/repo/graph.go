@@ -0,0 +1,149 @@
+package repo
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/database"
+	"gogs.io/gogs/internal/gitx"
+)
+
+const (
+	GRAPH = "repo/graph"
+
+	// graphLaneWidth is the width of a lane of the commit graph in pixels.
+	graphLaneWidth = 16
+)
+
+// graphPath is an SVG path of lines of a row in the same color.
+type graphPath struct {
+	Color int
+	D     string
+}
+
+type graphRow struct {
+	*gitx.GraphRow
+	User  *database.User
+	Paths []*graphPath
+}
+
+// graphPaths returns SVG paths to draw lines of the row, where a lane is one
+// unit wide and the row is two units high. Lanes that pass through the row are
+// drawn as single vertical lines to keep the page small when there are many
+// lanes.
+func graphPaths(row *gitx.GraphRow) []*graphPath {
+	var builders [gitx.GraphColors]strings.Builder
+	write := func(color int, parts ...string) {
+		for _, part := range parts {
+			builders[color].WriteString(part)
+		}
+	}
+	itoa := strconv.Itoa
+
+	// Lanes that pass through the row from top to bottom without touching the
+	// commit.
+	upper := make(map[int]bool, len(row.Upper))
+	for _, line := range row.Upper {
+		if line.From == line.To && line.From != row.Column {
+			upper[line.From] = true
+		}
+	}
+	through := make(map[int]bool, len(upper))
+	for _, line := range row.Lower {
+		if line.From == line.To && upper[line.From] {
+			through[line.From] = true
+		}
+	}
+
+	for _, line := range row.Upper {
+		if line.From == line.To && through[line.From] {
+			continue // Drawn with the lower half
+		}
+		write(line.Color, "M", itoa(line.From), " 0L", itoa(line.To), " 1")
+	}
+	for _, line := range row.Lower {
+		if line.From == line.To && through[line.From] {
+			write(line.Color, "M", itoa(line.From), " 0V2")
+			continue
+		}
+		write(line.Color, "M", itoa(line.From), " 1L", itoa(line.To), " 2")
+	}
+
+	paths := make([]*graphPath, 0, gitx.GraphColors)
+	for color := range builders {
+		if builders[color].Len() > 0 {
+			paths = append(paths, &graphPath{
+				Color: color,
+				D:     builders[color].String(),
+			})
+		}
+	}
+	return paths
+}
+
+// Graph renders the commit graph of all branches or branches selected by the
+// "branch" query parameters.
+func Graph(c *context.Context) {
+	c.Data["Title"] = c.Tr("repo.commits.graph") + " · " + c.Repo.Repository.FullName()
+	c.Data["PageIsViewFiles"] = true
+	c.Data["PageIsCommitGraph"] = true
+
+	var branches []string
+	for _, branch := range c.QueryStrings("branch") {
+		if branch != "" {
+			branches = append(branches, branch)
+		}
+	}
+
+	page := max(c.QueryInt("page"), 1)
+	pageSize := conf.UI.User.CommitsPagingNum
+	graph, err := gitx.RepoGraph(c.Req.Context(), c.Repo.GitRepo.Path(), gitx.GraphOptions{
+		Branches: branches,
+		Skip:     (page - 1) * pageSize,
+		Limit:    pageSize,
+		Timeout:  time.Duration(conf.Git.Timeout.Diff) * time.Second,
+	})
+	if err != nil {
+		c.Error(err, "get commit graph")
+		return
+	}
+
+	emailToUsers := make(map[string]*database.User)
+	rows := make([]*graphRow, len(graph.Rows))
+	for i, row := range graph.Rows {
+		email := row.Commit.Author.Email
+		u, ok := emailToUsers[email]
+		if !ok {
+			u = tryGetUserByEmail(c.Req.Context(), email)
+			emailToUsers[email] = u
+		}
+		rows[i] = &graphRow{
+			GraphRow: row,
+			User:     u,
+			Paths:    graphPaths(row),
+		}
+	}
+	c.Data["GraphRows"] = rows
+	c.Data["GraphWidth"] = graph.Width * graphLaneWidth
+	c.Data["SelectedBranches"] = branches
+
+	query := make(url.Values)
+	query["branch"] = branches
+	c.Data["GraphQuery"] = query.Encode()
+	if page > 1 {
+		c.Data["HasPrevious"] = true
+		c.Data["PreviousPage"] = page - 1
+	}
+	if graph.HasMore {
+		c.Data["HasNext"] = true
+		c.Data["NextPage"] = page + 1
+	}
+
+	c.Data["Username"] = c.Repo.Owner.Name
+	c.Data["Reponame"] = c.Repo.Repository.Name
+	c.Success(GRAPH)
+}
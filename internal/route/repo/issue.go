@@ -103,6 +103,9 @@ func issues(c *context.Context, isPullList bool) {
 	viewType := c.Query("type")
 	sortType := c.Query("sort")
 	types := []string{"assigned", "created_by", "mentioned"}
+	if isPullList {
+		types = append(types, "review_requested")
+	}
 	if !slices.Contains(types, viewType) {
 		viewType = "all"
 	}
@@ -116,7 +119,8 @@ func issues(c *context.Context, isPullList bool) {
 
 	var (
 		assigneeID = c.QueryInt64("assignee")
-		posterID   int64
+		posterID   = c.QueryInt64("poster")
+		reviewerID int64
 	)
 	filterMode := database.FilterModeYourRepos
 	switch viewType {
@@ -128,6 +132,9 @@ func issues(c *context.Context, isPullList bool) {
 		posterID = c.User.ID
 	case "mentioned":
 		filterMode = database.FilterModeMention
+	case "review_requested":
+		filterMode = database.FilterModeReviewRequested
+		reviewerID = c.User.ID
 	}
 
 	var uid int64 = -1
@@ -139,15 +146,33 @@ func issues(c *context.Context, isPullList bool) {
 	selectLabels := c.Query("labels")
 	milestoneID := c.QueryInt64("milestone")
 	isShowClosed := c.Query("state") == "closed"
-	issueStats := database.GetIssueStats(&database.IssueStatsOptions{
+
+	// Issues matched by the keyword are further filtered by other conditions.
+	keyword := strings.TrimSpace(c.Query("q"))
+	var issueIDs []int64
+	if keyword != "" {
+		var err error
+		issueIDs, err = database.SearchIssueIDs(keyword, []int64{repo.ID})
+		if err != nil {
+			c.Error(err, "search issue IDs")
+			return
+		}
+	}
+
+	statsOpts := &database.IssueStatsOptions{
 		RepoID:      repo.ID,
 		UserID:      uid,
 		Labels:      selectLabels,
 		MilestoneID: milestoneID,
 		AssigneeID:  assigneeID,
+		IssueIDs:    issueIDs,
 		FilterMode:  filterMode,
 		IsPull:      isPullList,
-	})
+	}
+	if filterMode != database.FilterModeCreate {
+		statsOpts.PosterID = posterID
+	}
+	issueStats := database.GetIssueStats(statsOpts)
 
 	page := max(c.QueryInt("page"), 1)
 
@@ -165,7 +190,9 @@ func issues(c *context.Context, isPullList bool) {
 		AssigneeID:  assigneeID,
 		RepoID:      repo.ID,
 		PosterID:    posterID,
+		ReviewerID:  reviewerID,
 		MilestoneID: milestoneID,
+		IssueIDs:    issueIDs,
 		Page:        pager.Current(),
 		IsClosed:    isShowClosed,
 		IsMention:   filterMode == database.FilterModeMention,
@@ -202,6 +229,15 @@ func issues(c *context.Context, isPullList bool) {
 	}
 	c.Data["Issues"] = issues
 
+	// Pinned issues are shown above the first page of the list.
+	if pager.Current() == 1 {
+		c.Data["PinnedIssues"], err = database.GetPinnedIssues(repo.ID, isPullList)
+		if err != nil {
+			c.Error(err, "get pinned issues")
+			return
+		}
+	}
+
 	// Get milestones.
 	c.Data["Milestones"], err = database.GetMilestonesByRepoID(repo.ID)
 	if err != nil {
@@ -216,9 +252,18 @@ func issues(c *context.Context, isPullList bool) {
 		return
 	}
 
+	c.Data["Posters"], err = database.GetIssuePosters(repo.ID, isPullList)
+	if err != nil {
+		c.Error(err, "get issue posters")
+		return
+	}
+
 	if viewType == "assigned" {
 		assigneeID = 0 // Reset ID to prevent unexpected selection of assignee.
 	}
+	if viewType == "created_by" {
+		posterID = 0 // Reset ID to prevent unexpected selection of poster.
+	}
 
 	c.Data["IssueStats"] = issueStats
 	selectLabelsInt, _ := strconv.ParseInt(selectLabels, 10, 64)
@@ -227,6 +272,8 @@ func issues(c *context.Context, isPullList bool) {
 	c.Data["SortType"] = sortType
 	c.Data["MilestoneID"] = milestoneID
 	c.Data["AssigneeID"] = assigneeID
+	c.Data["PosterID"] = posterID
+	c.Data["Keyword"] = keyword
 	c.Data["IsShowClosed"] = isShowClosed
 	if isShowClosed {
 		c.Data["State"] = "closed"
@@ -271,6 +318,7 @@ func RetrieveRepoMilestonesAndAssignees(c *context.Context, repo *database.Repos
 		c.Error(err, "get assignees")
 		return
 	}
+	c.Data["AssigneeIDMark"] = map[int64]bool{}
 }
 
 func RetrieveRepoMetas(c *context.Context, repo *database.Repository) []*database.Label {
@@ -293,14 +341,23 @@ func RetrieveRepoMetas(c *context.Context, repo *database.Repository) []*databas
 	return labels
 }
 
-func getFileContentFromDefaultBranch(c *context.Context, filename string) (string, bool) {
+// loadDefaultBranchCommit loads the commit of the default branch if no commit
+// has been loaded, and returns false if the commit is not available.
+func loadDefaultBranchCommit(c *context.Context) bool {
 	if c.Repo.Commit == nil {
 		var err error
 		c.Repo.Commit, err = c.Repo.GitRepo.BranchCommit(c.Repo.Repository.DefaultBranch)
 		if err != nil {
-			return "", false
+			return false
 		}
 	}
+	return true
+}
+
+func getFileContentFromDefaultBranch(c *context.Context, filename string) (string, bool) {
+	if !loadDefaultBranchCommit(c) {
+		return "", false
+	}
 
 	entry, err := c.Repo.Commit.TreeEntry(filename)
 	if err != nil {
@@ -330,18 +387,58 @@ func NewIssue(c *context.Context) {
 	c.Data["RequireSimpleMDE"] = true
 	c.Data["title"] = c.Query("title")
 	c.Data["content"] = c.Query("content")
-	setTemplateIfExists(c, IssueTemplateKey, IssueTemplateCandidates)
+
+	// Let the user choose from issue templates unless one has been chosen or the
+	// issue is pre-filled.
+	tmpls := getIssueTemplates(c)
+	tmpl := getIssueTemplate(c, c.Query("template"))
+	if len(tmpls) > 0 && tmpl == nil && !c.QueryBool("blank") && c.Query("title") == "" && c.Query("content") == "" {
+		c.Data["IssueTemplates"] = tmpls
+		c.Success(tmplRepoIssueChoose)
+		return
+	}
+
+	if tmpl != nil {
+		c.Data["IssueTemplate"] = tmpl.Content
+		c.Data["template"] = tmpl.FileName
+		if c.Query("title") == "" {
+			c.Data["title"] = tmpl.Title
+		}
+	} else if len(tmpls) == 0 {
+		setTemplateIfExists(c, IssueTemplateKey, IssueTemplateCandidates)
+	}
 	renderAttachmentSettings(c)
 
-	RetrieveRepoMetas(c, c.Repo.Repository)
+	labels := RetrieveRepoMetas(c, c.Repo.Repository)
 	if c.Written() {
 		return
 	}
 
+	// Pre-select labels and assignees of the issue template for users who are
+	// able to change them.
+	if tmpl != nil && c.Repo.IsWriter() {
+		labelIDs, assigneeIDs, err := issueTemplateMetas(c.Repo.Repository, tmpl)
+		if err != nil {
+			c.Error(err, "get issue template metas")
+			return
+		}
+
+		labelIDMark := tool.Int64sToMap(labelIDs)
+		for i := range labels {
+			labels[i].IsChecked = labelIDMark[labels[i].ID]
+		}
+		c.Data["HasSelectedLabel"] = len(labelIDs) > 0
+		c.Data["label_ids"] = strings.Join(tool.Int64sToStrings(labelIDs), ",")
+
+		c.Data["HasSelectedAssignee"] = len(assigneeIDs) > 0
+		c.Data["AssigneeIDMark"] = tool.Int64sToMap(assigneeIDs)
+		c.Data["assignee_ids"] = strings.Join(tool.Int64sToStrings(assigneeIDs), ",")
+	}
+
 	c.Success(tmplRepoIssueNew)
 }
 
-func ValidateRepoMetas(c *context.Context, f form.NewIssue) ([]int64, int64, int64) {
+func ValidateRepoMetas(c *context.Context, f form.NewIssue) ([]int64, int64, []int64) {
 	var (
 		repo = c.Repo.Repository
 		err  error
@@ -349,11 +446,11 @@ func ValidateRepoMetas(c *context.Context, f form.NewIssue) ([]int64, int64, int
 
 	labels := RetrieveRepoMetas(c, c.Repo.Repository)
 	if c.Written() {
-		return nil, 0, 0
+		return nil, 0, nil
 	}
 
 	if !c.Repo.IsWriter() {
-		return nil, 0, 0
+		return nil, 0, nil
 	}
 
 	// Check labels.
@@ -376,23 +473,29 @@ func ValidateRepoMetas(c *context.Context, f form.NewIssue) ([]int64, int64, int
 		c.Data["Milestone"], err = repo.GetMilestoneByID(milestoneID)
 		if err != nil {
 			c.Error(err, "get milestone by ID")
-			return nil, 0, 0
+			return nil, 0, nil
 		}
 		c.Data["milestone_id"] = milestoneID
 	}
 
-	// Check assignee.
-	assigneeID := f.AssigneeID
-	if assigneeID > 0 {
-		c.Data["Assignee"], err = repo.GetAssigneeByID(assigneeID)
-		if err != nil {
+	// Check assignees.
+	assigneeIDs := tool.StringsToInt64s(strings.Split(f.AssigneeIDs, ","))
+	assigneeIDMark := make(map[int64]bool, len(assigneeIDs))
+	for _, assigneeID := range assigneeIDs {
+		if assigneeID <= 0 {
+			continue
+		}
+		if _, err = repo.GetAssigneeByID(assigneeID); err != nil {
 			c.Error(err, "get assignee by ID")
-			return nil, 0, 0
+			return nil, 0, nil
 		}
-		c.Data["assignee_id"] = assigneeID
+		assigneeIDMark[assigneeID] = true
 	}
+	c.Data["HasSelectedAssignee"] = len(assigneeIDMark) > 0
+	c.Data["AssigneeIDMark"] = assigneeIDMark
+	c.Data["assignee_ids"] = f.AssigneeIDs
 
-	return labelIDs, milestoneID, assigneeID
+	return labelIDs, milestoneID, assigneeIDs
 }
 
 func NewIssuePost(c *context.Context, f form.NewIssue) {
@@ -402,7 +505,9 @@ func NewIssuePost(c *context.Context, f form.NewIssue) {
 	c.Data["RequireSimpleMDE"] = true
 	renderAttachmentSettings(c)
 
-	labelIDs, milestoneID, assigneeID := ValidateRepoMetas(c, f)
+	c.Data["template"] = f.Template
+
+	labelIDs, milestoneID, assigneeIDs := ValidateRepoMetas(c, f)
 	if c.Written() {
 		return
 	}
@@ -412,6 +517,19 @@ func NewIssuePost(c *context.Context, f form.NewIssue) {
 		return
 	}
 
+	// Users who are not able to change labels and assignees still get the ones
+	// of the issue template.
+	if !c.Repo.IsWriter() {
+		if tmpl := getIssueTemplate(c, f.Template); tmpl != nil {
+			var err error
+			labelIDs, assigneeIDs, err = issueTemplateMetas(c.Repo.Repository, tmpl)
+			if err != nil {
+				c.Error(err, "get issue template metas")
+				return
+			}
+		}
+	}
+
 	var attachments []string
 	if conf.Attachment.Enabled {
 		attachments = f.Files
@@ -423,11 +541,14 @@ func NewIssuePost(c *context.Context, f form.NewIssue) {
 		PosterID:    c.User.ID,
 		Poster:      c.User,
 		MilestoneID: milestoneID,
-		AssigneeID:  assigneeID,
 		Content:     f.Content,
 	}
-	if err := database.NewIssue(c.Repo.Repository, issue, labelIDs, attachments); err != nil {
-		c.Error(err, "new issue")
+	if err := database.NewIssue(c.Repo.Repository, issue, labelIDs, assigneeIDs, attachments); err != nil {
+		if database.IsErrBlockedByUser(err) {
+			c.RenderWithErr(c.Tr("form.blocked_by_user"), http.StatusForbidden, tmplRepoIssueNew, &f)
+		} else {
+			c.Error(err, "new issue")
+		}
 		return
 	}
 
@@ -498,6 +619,17 @@ func viewIssue(c *context.Context, isPullList bool) {
 
 	issue, err := database.GetIssueByIndex(c.Repo.Repository.ID, index)
 	if err != nil {
+		if database.IsErrIssueNotExist(err) {
+			// Redirect to the new location if the issue has been transferred.
+			transferred, err := database.GetIssueRedirect(c.Repo.Repository.ID, index)
+			if err == nil {
+				c.RawRedirect(fmt.Sprintf("%s/issues/%d", transferred.Repo.Link(), transferred.Index))
+				return
+			} else if !database.IsErrIssueRedirectNotExist(err) {
+				c.Error(err, "get issue redirect")
+				return
+			}
+		}
 		c.NotFoundOrError(err, "get issue by index")
 		return
 	}
@@ -571,6 +703,37 @@ func viewIssue(c *context.Context, isPullList bool) {
 		if c.Written() {
 			return
 		}
+		c.Data["IssueLockReasons"] = database.IssueLockReasons
+
+		if issue.IsPull {
+			c.Data["ReviewableTeams"], err = issue.PullRequest.GetReviewableTeams()
+			if err != nil {
+				c.Error(err, "get reviewable teams")
+				return
+			}
+		}
+	}
+
+	RetrieveIssueDependencies(c, issue)
+	if c.Written() {
+		return
+	}
+
+	if issue.IsPull {
+		RetrievePullCodeOwners(c, issue.PullRequest)
+		if c.Written() {
+			return
+		}
+	}
+
+	RetrieveIssueTrackedTimes(c, issue)
+	if c.Written() {
+		return
+	}
+
+	RetrieveIssueReactions(c, issue)
+	if c.Written() {
+		return
 	}
 
 	if c.IsLogged {
@@ -803,16 +966,127 @@ func UpdateIssueAssignee(c *context.Context) {
 		return
 	}
 
-	assigneeID := c.QueryInt64("id")
-	if issue.AssigneeID == assigneeID {
-		c.JSONSuccess(map[string]any{
-			"ok": true,
-		})
+	if c.Query("action") == "clear" {
+		if err := issue.ClearAssignees(c.User); err != nil {
+			c.Error(err, "clear assignees")
+			return
+		}
+	} else {
+		var (
+			isAttach = c.Query("action") == "attach"
+			assignee *database.User
+			err      error
+		)
+		// Users who lost access to the repository can still be unassigned.
+		if isAttach {
+			assignee, err = c.Repo.Repository.GetAssigneeByID(c.QueryInt64("id"))
+		} else {
+			assignee, err = database.Handle.Users().GetByID(c.Req.Context(), c.QueryInt64("id"))
+		}
+		if err != nil {
+			c.NotFoundOrError(err, "get assignee by ID")
+			return
+		}
+
+		if isAttach {
+			err = issue.AddAssignee(c.User, assignee)
+		} else {
+			err = issue.RemoveAssignee(c.User, assignee)
+		}
+		if err != nil {
+			c.Error(err, "update assignee")
+			return
+		}
+	}
+
+	c.JSONSuccess(map[string]any{
+		"ok": true,
+	})
+}
+
+// getActionPullRequest returns the pull request of the issue with attributes
+// required for updating review requests loaded.
+func getActionPullRequest(c *context.Context) *database.PullRequest {
+	issue := getActionIssue(c)
+	if c.Written() {
+		return nil
+	}
+
+	if !issue.IsPull {
+		c.NotFound()
+		return nil
+	}
+
+	pr := issue.PullRequest
+	pr.Issue = issue
+	if err := pr.LoadAttributes(); err != nil {
+		c.Error(err, "load attributes")
+		return nil
+	}
+	return pr
+}
+
+func UpdatePullReviewer(c *context.Context) {
+	pr := getActionPullRequest(c)
+	if c.Written() {
 		return
 	}
 
-	if err := issue.ChangeAssignee(c.User, assigneeID); err != nil {
-		c.Error(err, "change assignee")
+	if c.Query("action") == "clear" {
+		if err := pr.ClearReviewRequests(c.User); err != nil {
+			c.Error(err, "clear review requests")
+			return
+		}
+	} else {
+		reviewer, err := database.Handle.Users().GetByID(c.Req.Context(), c.QueryInt64("id"))
+		if err != nil {
+			c.NotFoundOrError(err, "get reviewer by ID")
+			return
+		}
+
+		if c.Query("action") == "attach" {
+			err = pr.RequestReview(c.User, reviewer)
+		} else {
+			err = pr.RemoveReviewRequest(c.User, reviewer)
+		}
+		if err != nil {
+			if database.IsErrInvalidReviewRequest(err) {
+				c.Status(http.StatusUnprocessableEntity)
+			} else {
+				c.Error(err, "update review request")
+			}
+			return
+		}
+	}
+
+	c.JSONSuccess(map[string]any{
+		"ok": true,
+	})
+}
+
+func UpdatePullTeamReviewer(c *context.Context) {
+	pr := getActionPullRequest(c)
+	if c.Written() {
+		return
+	}
+
+	team, err := database.GetTeamByID(c.QueryInt64("id"))
+	if err != nil {
+		c.NotFoundOrError(err, "get team by ID")
+		return
+	}
+
+	if c.Query("action") == "attach" {
+		err = pr.RequestTeamReview(c.User, team)
+	} else {
+		err = pr.RemoveTeamReviewRequest(c.User, team)
+	}
+	if err != nil {
+		if database.IsErrInvalidReviewRequest(err) {
+			c.Status(http.StatusUnprocessableEntity)
+		} else {
+			c.Error(err, "update team review request")
+		}
 		return
 	}
 
@@ -874,7 +1148,11 @@ func NewComment(c *context.Context, f form.CreateComment) {
 				c.Flash.Info(c.Tr("repo.pulls.open_unmerged_pull_exists", pr.Index))
 			} else {
 				if err = issue.ChangeStatus(c.User, c.Repo.Repository, f.Status == "close"); err != nil {
-					log.Error("ChangeStatus: %v", err)
+					if database.IsErrIssueHasOpenDependencies(err) {
+						c.Flash.Error(c.Tr("repo.issues.dependency.close_blocked"))
+					} else {
+						log.Error("ChangeStatus: %v", err)
+					}
 				} else {
 					log.Trace("Issue [%d] status changed to closed: %v", issue.ID, issue.IsClosed)
 				}
@@ -903,9 +1181,18 @@ func NewComment(c *context.Context, f form.CreateComment) {
 		return
 	}
 
+	if !canCommentOnIssue(c, issue) {
+		c.Flash.Error(c.Tr("repo.issues.lock.comment_disallowed"))
+		return
+	}
+
 	comment, err = database.CreateIssueComment(c.User, c.Repo.Repository, issue, f.Content, attachments)
 	if err != nil {
-		c.Error(err, "create issue comment")
+		if database.IsErrBlockedByUser(err) {
+			c.Flash.Error(c.Tr("form.blocked_by_user"))
+		} else {
+			c.Error(err, "create issue comment")
+		}
 		return
 	}
 
@@ -1107,6 +1394,8 @@ func Milestones(c *context.Context) {
 		if m.NumOpenIssues+m.NumClosedIssues > 0 {
 			m.Completeness = m.NumClosedIssues * 100 / (m.NumOpenIssues + m.NumClosedIssues)
 		}
+		m.TrackedTime = m.SumTrackedTime()
+		m.TimeEstimate = m.SumTimeEstimate()
 		m.RenderedContent = string(markup.Markdown(m.Content, c.Repo.RepoLink, c.Repo.Repository.ComposeMetas()))
 	}
 	c.Data["Milestones"] = miles
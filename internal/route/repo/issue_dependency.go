@@ -0,0 +1,130 @@
+package repo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/database"
+)
+
+// canReadIssue returns true if the signed in user is allowed to read the issue,
+// which may be in a different repository. The repository of the issue must be
+// loaded.
+func canReadIssue(c *context.Context, issue *database.Issue) bool {
+	if issue.RepoID == c.Repo.Repository.ID {
+		return true
+	}
+
+	var userID int64
+	if c.IsLogged {
+		userID = c.User.ID
+	}
+	return database.Handle.Permissions().Authorize(c.Req.Context(), userID, issue.RepoID, database.AccessModeRead,
+		database.AccessModeOptions{
+			OwnerID: issue.Repo.OwnerID,
+			Private: issue.Repo.IsPrivate,
+		},
+	)
+}
+
+func filterReadableIssues(c *context.Context, issues []*database.Issue) []*database.Issue {
+	readable := make([]*database.Issue, 0, len(issues))
+	for _, issue := range issues {
+		if canReadIssue(c, issue) {
+			readable = append(readable, issue)
+		}
+	}
+	return readable
+}
+
+// RetrieveIssueDependencies sets issues that block or are blocked by the issue
+// and are readable by the signed in user for rendering.
+func RetrieveIssueDependencies(c *context.Context, issue *database.Issue) {
+	dependencies, err := issue.GetDependencies()
+	if err != nil {
+		c.Error(err, "get dependencies")
+		return
+	}
+	c.Data["Dependencies"] = filterReadableIssues(c, dependencies)
+
+	dependents, err := issue.GetDependents()
+	if err != nil {
+		c.Error(err, "get dependents")
+		return
+	}
+	c.Data["Dependents"] = filterReadableIssues(c, dependents)
+
+	hasOpenDependencies := false
+	for _, dependency := range dependencies {
+		if !dependency.IsClosed {
+			hasOpenDependencies = true
+			break
+		}
+	}
+	c.Data["HasOpenDependencies"] = hasOpenDependencies
+}
+
+// getIssueByRef returns the issue referenced in the form of "#index" within the
+// current repository or "owner/repo#index" in any repository.
+func getIssueByRef(c *context.Context, ref string) (*database.Issue, error) {
+	ref = strings.TrimSpace(ref)
+	if strings.HasPrefix(ref, "#") {
+		index, _ := strconv.ParseInt(ref[1:], 10, 64)
+		return database.GetIssueByIndex(c.Repo.Repository.ID, index)
+	}
+	return database.GetIssueByRef(ref)
+}
+
+func UpdateIssueDependency(c *context.Context) {
+	issue := getActionIssue(c)
+	if c.Written() {
+		return
+	}
+
+	typeName := "issues"
+	if issue.IsPull {
+		typeName = "pulls"
+	}
+	redirectTo := c.Repo.MakeURL(fmt.Sprintf("%s/%d", typeName, issue.Index))
+
+	isRemove := c.Query("action") == "remove"
+	var (
+		dependency *database.Issue
+		err        error
+	)
+	if isRemove {
+		dependency, err = database.GetIssueByID(c.QueryInt64("id"))
+	} else {
+		dependency, err = getIssueByRef(c, c.Query("ref"))
+	}
+	if err != nil {
+		if !database.IsErrIssueNotExist(err) && !database.IsErrRepoNotExist(err) &&
+			!database.IsErrUserNotExist(err) && !database.IsInvalidRepoReference(err) {
+			c.Error(err, "get dependency")
+			return
+		}
+		dependency = nil
+	}
+	if dependency == nil || !canReadIssue(c, dependency) {
+		c.Flash.Error(c.Tr("repo.issues.dependency.issue_not_exist"))
+		c.RawRedirect(redirectTo)
+		return
+	}
+
+	if isRemove {
+		err = issue.RemoveDependency(dependency)
+	} else {
+		err = issue.AddDependency(dependency)
+	}
+	if err != nil {
+		if !database.IsErrCircularIssueDependency(err) {
+			c.Error(err, "update dependency")
+			return
+		}
+		c.Flash.Error(c.Tr("repo.issues.dependency.circular"))
+	}
+
+	c.RawRedirect(redirectTo)
+}
@@ -0,0 +1,47 @@
+package repo
+
+import (
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/database"
+)
+
+// canCommentOnIssue returns true if the signed in user is allowed to comment on
+// or react to the issue, conversations of locked issues are limited to writers.
+func canCommentOnIssue(c *context.Context, issue *database.Issue) bool {
+	return !issue.IsLocked || c.Repo.IsWriter()
+}
+
+func LockIssue(c *context.Context) {
+	issue := getActionIssue(c)
+	if c.Written() {
+		return
+	}
+
+	reason := c.Query("reason")
+	if !database.IsValidIssueLockReason(reason) {
+		c.Flash.Error(c.Tr("repo.issues.lock.invalid_reason"))
+		c.RawRedirect(issueLink(c, issue))
+		return
+	}
+
+	if err := issue.Lock(c.User, reason); err != nil {
+		c.Error(err, "lock issue")
+		return
+	}
+
+	c.RawRedirect(issueLink(c, issue))
+}
+
+func UnlockIssue(c *context.Context) {
+	issue := getActionIssue(c)
+	if c.Written() {
+		return
+	}
+
+	if err := issue.Unlock(c.User); err != nil {
+		c.Error(err, "unlock issue")
+		return
+	}
+
+	c.RawRedirect(issueLink(c, issue))
+}
@@ -0,0 +1,37 @@
+package repo
+
+import (
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/database"
+)
+
+func PinIssue(c *context.Context) {
+	issue := getActionIssue(c)
+	if c.Written() {
+		return
+	}
+
+	if err := issue.Pin(); err != nil {
+		if !database.IsErrPinnedIssuesLimit(err) {
+			c.Error(err, "pin issue")
+			return
+		}
+		c.Flash.Error(c.Tr("repo.issues.pin.limit_reached", database.MaxPinnedIssues))
+	}
+
+	c.RawRedirect(issueLink(c, issue))
+}
+
+func UnpinIssue(c *context.Context) {
+	issue := getActionIssue(c)
+	if c.Written() {
+		return
+	}
+
+	if err := issue.Unpin(); err != nil {
+		c.Error(err, "unpin issue")
+		return
+	}
+
+	c.RawRedirect(issueLink(c, issue))
+}
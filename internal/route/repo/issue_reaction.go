@@ -0,0 +1,116 @@
+package repo
+
+import (
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/database"
+)
+
+// RetrieveIssueReactions assigns reactions to the issue and its comments for
+// rendering, along with the configured set of allowed reactions.
+func RetrieveIssueReactions(c *context.Context, issue *database.Issue) {
+	reactions, err := database.GetReactionsByIssueID(issue.ID)
+	if err != nil {
+		c.Error(err, "get reactions by issue ID")
+		return
+	}
+	if err = reactions.LoadUsers(); err != nil {
+		c.Error(err, "load users")
+		return
+	}
+
+	commentReactions := make(map[int64]database.ReactionList)
+	issue.Reactions = nil
+	for _, r := range reactions {
+		if r.CommentID == 0 {
+			issue.Reactions = append(issue.Reactions, r)
+		} else {
+			commentReactions[r.CommentID] = append(commentReactions[r.CommentID], r)
+		}
+	}
+	for _, comment := range issue.Comments {
+		comment.Reactions = commentReactions[comment.ID]
+	}
+
+	c.Data["AllowedReactions"] = conf.UI.Reactions
+}
+
+// changeReaction adds or removes the reaction of the current user based on the
+// action, the reaction is on the comment if it is not nil.
+func changeReaction(c *context.Context, issue *database.Issue, comment *database.Comment) {
+	link := issueLink(c, issue)
+	if comment != nil {
+		link += "#" + comment.HashTag()
+	}
+
+	if !canCommentOnIssue(c, issue) {
+		c.Flash.Error(c.Tr("repo.issues.lock.comment_disallowed"))
+		c.RawRedirect(link)
+		return
+	}
+
+	typ := c.Query("type")
+
+	var err error
+	switch c.Params(":action") {
+	case "react":
+		if comment != nil {
+			_, err = issue.AddCommentReaction(c.User, comment, typ)
+		} else {
+			_, err = issue.AddReaction(c.User, typ)
+		}
+	case "unreact":
+		if comment != nil {
+			err = issue.RemoveCommentReaction(c.User, comment, typ)
+		} else {
+			err = issue.RemoveReaction(c.User, typ)
+		}
+	default:
+		c.NotFound()
+		return
+	}
+	if err != nil {
+		if database.IsErrInvalidReaction(err) {
+			c.Flash.Error(c.Tr("repo.issues.reaction.invalid"))
+		} else if database.IsErrBlockedByUser(err) {
+			c.Flash.Error(c.Tr("form.blocked_by_user"))
+		} else {
+			c.Error(err, "change reaction")
+			return
+		}
+	}
+
+	c.RawRedirect(link)
+}
+
+func ChangeIssueReaction(c *context.Context) {
+	issue := getActionIssue(c)
+	if c.Written() {
+		return
+	}
+
+	changeReaction(c, issue, nil)
+}
+
+func ChangeCommentReaction(c *context.Context) {
+	comment, err := database.GetCommentByID(c.ParamsInt64(":id"))
+	if err != nil {
+		c.NotFoundOrError(err, "get comment by ID")
+		return
+	}
+
+	issue, err := database.GetIssueByID(comment.IssueID)
+	if err != nil {
+		c.NotFoundOrError(err, "get issue by ID")
+		return
+	}
+
+	if issue.RepoID != c.Repo.Repository.ID ||
+		comment.Type != database.CommentTypeComment ||
+		(!c.Repo.HasAccess() && issue.IsPull) {
+		c.NotFound()
+		return
+	}
+
+	changeReaction(c, issue, comment)
+}
@@ -0,0 +1,177 @@
+package repo
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"gopkg.in/yaml.v3"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/database"
+)
+
+const (
+	tmplRepoIssueChoose = "repo/issue/choose"
+
+	// IssueTemplatesDir is the directory on the default branch to read issue
+	// templates from.
+	IssueTemplatesDir = ".gogs/ISSUE_TEMPLATE"
+)
+
+// templateStrings is a list of strings that can be written either as a YAML
+// sequence or as a comma-separated string in the front matter.
+type templateStrings []string
+
+func (s *templateStrings) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*s = nil
+		for _, v := range strings.Split(value.Value, ",") {
+			v = strings.TrimSpace(v)
+			if v != "" {
+				*s = append(*s, v)
+			}
+		}
+		return nil
+	}
+
+	var list []string
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
+}
+
+// IssueTemplate is a template of new issues that is read from the repository.
+type IssueTemplate struct {
+	// FileName is the name of the template file within the IssueTemplatesDir.
+	FileName  string          `yaml:"-"`
+	Name      string          `yaml:"name"`
+	About     string          `yaml:"about"`
+	Title     string          `yaml:"title"`
+	Labels    templateStrings `yaml:"labels"`
+	Assignees templateStrings `yaml:"assignees"`
+	Content   string          `yaml:"-"`
+}
+
+// parseIssueTemplate parses the issue template with optional YAML front matter
+// that is delimited by "---" lines at the beginning of the content. The name
+// of the template defaults to the file name without extension.
+func parseIssueTemplate(fileName string, data []byte) (*IssueTemplate, error) {
+	tmpl := &IssueTemplate{FileName: fileName}
+
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if after, ok := strings.CutPrefix(content, "---\n"); ok {
+		// Surround with newlines to match the closing delimiter at both ends of the
+		// front matter.
+		frontMatter, body, found := strings.Cut("\n"+after+"\n", "\n---\n")
+		if !found {
+			return nil, errors.New("front matter is not closed")
+		}
+
+		if err := yaml.Unmarshal([]byte(frontMatter), tmpl); err != nil {
+			return nil, errors.Wrap(err, "unmarshal front matter")
+		}
+		content = strings.TrimSuffix(body, "\n")
+	}
+
+	tmpl.Content = content
+	if tmpl.Name == "" {
+		tmpl.Name = strings.TrimSuffix(fileName, path.Ext(fileName))
+	}
+	return tmpl, nil
+}
+
+// getIssueTemplates returns issue templates in the IssueTemplatesDir on the
+// default branch, templates that cannot be parsed are skipped.
+func getIssueTemplates(c *context.Context) []*IssueTemplate {
+	if !loadDefaultBranchCommit(c) {
+		return nil
+	}
+
+	tree, err := c.Repo.Commit.Subtree(IssueTemplatesDir)
+	if err != nil {
+		return nil
+	}
+	entries, err := tree.Entries()
+	if err != nil {
+		return nil
+	}
+
+	tmpls := make([]*IssueTemplate, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsBlob() || strings.ToLower(path.Ext(entry.Name())) != ".md" {
+			continue
+		}
+
+		p, err := entry.Blob().Bytes()
+		if err != nil {
+			log.Error("Failed to read issue template %q [repo_id: %d]: %v", entry.Name(), c.Repo.Repository.ID, err)
+			continue
+		}
+		tmpl, err := parseIssueTemplate(entry.Name(), p)
+		if err != nil {
+			log.Trace("Failed to parse issue template %q [repo_id: %d]: %v", entry.Name(), c.Repo.Repository.ID, err)
+			continue
+		}
+		tmpls = append(tmpls, tmpl)
+	}
+	sort.SliceStable(tmpls, func(i, j int) bool {
+		return strings.ToLower(tmpls[i].Name) < strings.ToLower(tmpls[j].Name)
+	})
+	return tmpls
+}
+
+// getIssueTemplate returns the issue template with given file name, or nil if
+// it does not exist.
+func getIssueTemplate(c *context.Context, fileName string) *IssueTemplate {
+	if fileName == "" {
+		return nil
+	}
+
+	for _, tmpl := range getIssueTemplates(c) {
+		if tmpl.FileName == fileName {
+			return tmpl
+		}
+	}
+	return nil
+}
+
+// issueTemplateMetas returns IDs of labels and assignees of the repository that
+// are named in the issue template. Names that do not match are ignored.
+func issueTemplateMetas(repo *database.Repository, tmpl *IssueTemplate) (labelIDs, assigneeIDs []int64, _ error) {
+	if len(tmpl.Labels) > 0 {
+		labels, err := database.GetLabelsByRepoID(repo.ID)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "get labels by repository ID")
+		}
+		for _, name := range tmpl.Labels {
+			for _, label := range labels {
+				if strings.EqualFold(label.Name, name) {
+					labelIDs = append(labelIDs, label.ID)
+					break
+				}
+			}
+		}
+	}
+
+	if len(tmpl.Assignees) > 0 {
+		assignees, err := repo.GetAssignees()
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "get assignees")
+		}
+		for _, name := range tmpl.Assignees {
+			name = strings.TrimPrefix(name, "@")
+			for _, assignee := range assignees {
+				if strings.EqualFold(assignee.Name, name) {
+					assigneeIDs = append(assigneeIDs, assignee.ID)
+					break
+				}
+			}
+		}
+	}
+	return labelIDs, assigneeIDs, nil
+}
@@ -0,0 +1,98 @@
+package repo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIssueTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		data     string
+		want     *IssueTemplate
+		wantErr  bool
+	}{
+		{
+			name:     "no front matter",
+			fileName: "bug_report.md",
+			data:     "## Steps to reproduce\n",
+			want: &IssueTemplate{
+				FileName: "bug_report.md",
+				Name:     "bug_report",
+				Content:  "## Steps to reproduce\n",
+			},
+		},
+		{
+			name:     "front matter with lists",
+			fileName: "bug_report.md",
+			data: `---
+name: Bug report
+about: Report something that is broken
+title: "[Bug] "
+labels:
+  - bug
+  - triage
+assignees: [alice]
+---
+## Steps to reproduce
+`,
+			want: &IssueTemplate{
+				FileName:  "bug_report.md",
+				Name:      "Bug report",
+				About:     "Report something that is broken",
+				Title:     "[Bug] ",
+				Labels:    templateStrings{"bug", "triage"},
+				Assignees: templateStrings{"alice"},
+				Content:   "## Steps to reproduce\n",
+			},
+		},
+		{
+			name:     "front matter with comma-separated strings",
+			fileName: "feature.md",
+			data:     "---\r\nlabels: enhancement, help wanted\r\nassignees: alice,bob\r\n---\r\nDescribe the feature",
+			want: &IssueTemplate{
+				FileName:  "feature.md",
+				Name:      "feature",
+				Labels:    templateStrings{"enhancement", "help wanted"},
+				Assignees: templateStrings{"alice", "bob"},
+				Content:   "Describe the feature",
+			},
+		},
+		{
+			name:     "empty front matter",
+			fileName: "question.md",
+			data:     "---\n---\n",
+			want: &IssueTemplate{
+				FileName: "question.md",
+				Name:     "question",
+				Content:  "",
+			},
+		},
+		{
+			name:     "front matter not closed",
+			fileName: "broken.md",
+			data:     "---\nname: Broken\n",
+			wantErr:  true,
+		},
+		{
+			name:     "invalid front matter",
+			fileName: "broken.md",
+			data:     "---\nlabels: {bug\n---\n",
+			wantErr:  true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseIssueTemplate(test.fileName, []byte(test.data))
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
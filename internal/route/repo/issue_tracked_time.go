@@ -0,0 +1,174 @@
+package repo
+
+import (
+	"fmt"
+	"time"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/database"
+	"gogs.io/gogs/internal/form"
+)
+
+// RetrieveIssueTrackedTimes sets the estimate and total tracked times of the
+// issue for rendering. Writers also get their running stopwatch and tracked
+// times that they are allowed to delete.
+func RetrieveIssueTrackedTimes(c *context.Context, issue *database.Issue) {
+	totals, err := issue.GetTrackedTimesByUser()
+	if err != nil {
+		c.Error(err, "get tracked times by user")
+		return
+	}
+	c.Data["TrackedTimesByUser"] = totals
+
+	var totalTrackedTime int64
+	for _, total := range totals {
+		totalTrackedTime += total.Time
+	}
+	c.Data["TotalTrackedTime"] = totalTrackedTime
+
+	if !c.Repo.IsWriter() {
+		return
+	}
+
+	stopwatch, err := issue.GetStopwatch(c.User.ID)
+	if err == nil {
+		c.Data["Stopwatch"] = stopwatch
+	} else if !database.IsErrStopwatchNotExist(err) {
+		c.Error(err, "get stopwatch")
+		return
+	}
+
+	// Admins are allowed to delete tracked times of everyone.
+	opts := database.TrackedTimesOptions{IssueID: issue.ID}
+	if !c.Repo.IsAdmin() {
+		opts.UserID = c.User.ID
+	}
+	times, err := database.GetTrackedTimes(opts)
+	if err != nil {
+		c.Error(err, "get tracked times")
+		return
+	}
+	for _, t := range times {
+		t.Issue = issue
+	}
+	if err = times.LoadAttributes(); err != nil {
+		c.Error(err, "load attributes")
+		return
+	}
+	c.Data["TrackedTimes"] = times
+}
+
+func issueLink(c *context.Context, issue *database.Issue) string {
+	if issue.IsPull {
+		return c.Repo.MakeURL(fmt.Sprintf("pulls/%d", issue.Index))
+	}
+	return c.Repo.MakeURL(fmt.Sprintf("issues/%d", issue.Index))
+}
+
+func ToggleStopwatch(c *context.Context) {
+	issue := getActionIssue(c)
+	if c.Written() {
+		return
+	}
+
+	_, err := issue.StopStopwatch(c.User)
+	if database.IsErrStopwatchNotExist(err) {
+		err = issue.StartStopwatch(c.User)
+	}
+	if err != nil {
+		c.Error(err, "toggle stopwatch")
+		return
+	}
+
+	c.RawRedirect(issueLink(c, issue))
+}
+
+func CancelStopwatch(c *context.Context) {
+	issue := getActionIssue(c)
+	if c.Written() {
+		return
+	}
+
+	if err := issue.CancelStopwatch(c.User); err != nil {
+		c.Error(err, "cancel stopwatch")
+		return
+	}
+
+	c.RawRedirect(issueLink(c, issue))
+}
+
+func AddTrackedTime(c *context.Context, f form.TrackedTime) {
+	issue := getActionIssue(c)
+	if c.Written() {
+		return
+	}
+
+	if c.HasError() || f.Hours < 0 || f.Minutes < 0 || f.Seconds() <= 0 {
+		c.Flash.Error(c.Tr("repo.issues.tracked_time.invalid"))
+		c.RawRedirect(issueLink(c, issue))
+		return
+	}
+
+	var created time.Time
+	if f.Date != "" {
+		var err error
+		created, err = time.ParseInLocation("2006-01-02", f.Date, time.Local)
+		if err != nil {
+			c.Flash.Error(c.Tr("repo.issues.tracked_time.invalid_date"))
+			c.RawRedirect(issueLink(c, issue))
+			return
+		}
+	}
+
+	if _, err := issue.AddTrackedTime(c.User, f.Seconds(), created); err != nil {
+		c.Error(err, "add tracked time")
+		return
+	}
+
+	c.RawRedirect(issueLink(c, issue))
+}
+
+func DeleteTrackedTime(c *context.Context) {
+	issue := getActionIssue(c)
+	if c.Written() {
+		return
+	}
+
+	t, err := issue.GetTrackedTimeByID(c.ParamsInt64(":id"))
+	if err != nil {
+		c.NotFoundOrError(err, "get tracked time by ID")
+		return
+	}
+
+	if t.UserID != c.User.ID && !c.Repo.IsAdmin() {
+		c.NotFound()
+		return
+	}
+
+	if err = issue.DeleteTrackedTime(t.ID); err != nil {
+		c.Error(err, "delete tracked time")
+		return
+	}
+
+	c.RawRedirect(issueLink(c, issue))
+}
+
+func UpdateTimeEstimate(c *context.Context, f form.TrackedTime) {
+	issue := getActionIssue(c)
+	if c.Written() {
+		return
+	}
+
+	if c.HasError() || f.Hours < 0 || f.Minutes < 0 {
+		c.Flash.Error(c.Tr("repo.issues.tracked_time.invalid"))
+		c.RawRedirect(issueLink(c, issue))
+		return
+	}
+
+	if err := issue.ChangeTimeEstimate(f.Seconds()); err != nil {
+		c.Error(err, "change time estimate")
+		return
+	}
+
+	c.RawRedirect(issueLink(c, issue))
+}
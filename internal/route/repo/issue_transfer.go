@@ -0,0 +1,60 @@
+package repo
+
+import (
+	"fmt"
+	"strings"
+
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/database"
+)
+
+// getTransferTargetRepo returns the repository referenced in the form of
+// "owner/name" that the signed in user can transfer issues to. It returns nil
+// without writing the response if no such repository exists.
+func getTransferTargetRepo(c *context.Context, ref string) *database.Repository {
+	repo, err := database.GetRepositoryByRef(strings.TrimSpace(ref))
+	if err != nil {
+		if !database.IsErrRepoNotExist(err) && !database.IsErrUserNotExist(err) && !database.IsInvalidRepoReference(err) {
+			c.Error(err, "get repository by reference")
+		}
+		return nil
+	}
+
+	if !repo.EnableIssues || repo.EnableExternalTracker ||
+		!database.Handle.Permissions().Authorize(c.Req.Context(), c.User.ID, repo.ID, database.AccessModeWrite,
+			database.AccessModeOptions{
+				OwnerID: repo.OwnerID,
+				Private: repo.IsPrivate,
+			},
+		) {
+		return nil
+	}
+	return repo
+}
+
+func TransferIssue(c *context.Context) {
+	issue := getActionIssue(c)
+	if c.Written() {
+		return
+	}
+
+	newRepo := getTransferTargetRepo(c, c.Query("repo"))
+	if c.Written() {
+		return
+	} else if newRepo == nil || newRepo.ID == issue.RepoID || issue.IsPull {
+		c.Flash.Error(c.Tr("repo.issues.transfer.repo_not_exist"))
+		c.RawRedirect(issueLink(c, issue))
+		return
+	}
+
+	if err := database.TransferIssue(c.User, issue, newRepo); err != nil {
+		c.Error(err, "transfer issue")
+		return
+	}
+	log.Trace("Issue transferred: %d -> %s#%d", issue.ID, newRepo.FullName(), issue.Index)
+
+	c.Flash.Success(c.Tr("repo.issues.transfer.success", newRepo.FullName()))
+	c.RawRedirect(fmt.Sprintf("%s/issues/%d", newRepo.Link(), issue.Index))
+}
@@ -33,6 +33,9 @@ var (
 		"PULL_REQUEST.md",
 		".gogs/PULL_REQUEST.md",
 		".github/PULL_REQUEST.md",
+		"PULL_REQUEST_TEMPLATE.md",
+		".gogs/PULL_REQUEST_TEMPLATE.md",
+		".github/PULL_REQUEST_TEMPLATE.md",
 	}
 
 	PullRequestTitleTemplateCandidates = []string{
@@ -243,6 +246,39 @@ func PrepareViewPullInfo(c *context.Context, issue *database.Issue) *gitx.PullRe
 	}
 	c.Data["NumCommits"] = len(prMeta.Commits)
 	c.Data["NumFiles"] = prMeta.NumFiles
+
+	c.Data["MergeStyles"], err = repo.AllowedMergeStyles(pull.BaseBranch)
+	if err != nil {
+		c.Error(err, "get allowed merge styles")
+		return nil
+	}
+	if c.IsLogged {
+		c.Data["SquashMessage"] = database.SquashCommitMessage(issue, c.User, prMeta.Commits)
+	}
+
+	baseCommitID, err := c.Repo.GitRepo.BranchCommitID(pull.BaseBranch)
+	if err != nil {
+		c.Error(err, "get base branch commit ID")
+		return nil
+	}
+	c.Data["NumBehindCommits"], err = c.Repo.GitRepo.RevListCount([]string{prMeta.MergeBase + ".." + baseCommitID})
+	if err != nil {
+		c.Error(err, "count behind commits")
+		return nil
+	}
+	c.Data["CanUpdateBranch"] = c.IsLogged && pull.CanUpdateBranch(c.User.ID)
+
+	if pull.IsAutoMergeScheduled() {
+		autoMerger, err := pull.AutoMerger()
+		if err != nil {
+			if !database.IsErrUserNotExist(err) {
+				c.Error(err, "get auto-merger")
+				return nil
+			}
+			autoMerger = database.NewGhostUser()
+		}
+		c.Data["AutoMerger"] = autoMerger
+	}
 	return prMeta
 }
 
@@ -294,7 +330,7 @@ func ViewPullCommits(c *context.Context) {
 		commits = prInfo.Commits
 	}
 
-	c.Data["Commits"] = matchUsersWithCommitEmails(c.Req.Context(), commits)
+	c.Data["Commits"] = matchUsersWithCommitEmails(c.Req.Context(), c.Repo.GitRepo.Path(), commits)
 	c.Data["CommitsCount"] = len(commits)
 
 	c.Success(tmplRepoPullsCommits)
@@ -367,6 +403,11 @@ func ViewPullFiles(c *context.Context) {
 	c.Data["Diff"] = diff
 	c.Data["DiffNotAvailable"] = diff.NumFiles() == 0
 
+	RetrievePullCodeOwners(c, pull)
+	if c.Written() {
+		return
+	}
+
 	commit, err := gitRepo.CatFileCommit(endCommitID)
 	if err != nil {
 		c.Error(err, "get commit")
@@ -419,10 +460,28 @@ func MergePullRequest(c *context.Context) {
 		return
 	}
 
+	mergeStyle, commitDescription := parseMergeOptions(c)
 	pr.Issue = issue
 	pr.Issue.Repo = c.Repo.Repository
-	if err = pr.Merge(c.User, c.Repo.GitRepo, database.MergeStyle(c.Query("merge_style")), c.Query("commit_description")); err != nil {
-		c.Error(err, "merge")
+	if err = pr.Merge(c.User, c.Repo.GitRepo, mergeStyle, commitDescription); err != nil {
+		switch {
+		case database.IsErrMergeStyleNotAllowed(err):
+			c.Flash.Error(c.Tr("repo.pulls.merge_style_not_allowed"))
+		case database.IsErrPullRequestNotFastForward(err):
+			c.Flash.Error(c.Tr("repo.pulls.cannot_fast_forward"))
+		case database.IsErrPullRequestHasMergeCommits(err):
+			c.Flash.Error(c.Tr("repo.pulls.has_merge_commits"))
+		case database.IsErrPullRequestHasUnverifiedCommits(err):
+			c.Flash.Error(c.Tr("repo.pulls.has_unverified_commits"))
+		case database.IsErrIssueHasOpenDependencies(err):
+			c.Flash.Error(c.Tr("repo.pulls.blocked_by_dependencies"))
+		case database.IsErrCodeOwnerSignOffRequired(err):
+			c.Flash.Error(c.Tr("repo.pulls.code_owners.sign_off_required"))
+		default:
+			c.Error(err, "merge")
+			return
+		}
+		c.Redirect(c.Repo.RepoLink + "/pulls/" + strconv.FormatInt(pr.Index, 10))
 		return
 	}
 
@@ -430,6 +489,136 @@ func MergePullRequest(c *context.Context) {
 	c.Redirect(c.Repo.RepoLink + "/pulls/" + strconv.FormatInt(pr.Index, 10))
 }
 
+// parseMergeOptions returns the merge style and the commit description from
+// the form of merging a pull request.
+func parseMergeOptions(c *context.Context) (database.MergeStyle, string) {
+	mergeStyle := database.MergeStyle(c.Query("merge_style"))
+	commitDescription := c.Query("commit_description")
+	if mergeStyle == database.MergeStyleSquash {
+		commitDescription = c.Query("squash_message")
+	}
+	return mergeStyle, commitDescription
+}
+
+// checkOpenPullRequest returns the open and unmerged pull request for
+// changing its auto-merge options or sign-offs.
+func checkOpenPullRequest(c *context.Context) *database.PullRequest {
+	issue := checkPullInfo(c)
+	if c.Written() {
+		return nil
+	}
+	if issue.IsClosed || issue.PullRequest.HasMerged {
+		c.NotFound()
+		return nil
+	}
+
+	pr := issue.PullRequest
+	pr.Issue = issue
+	pr.Issue.Repo = c.Repo.Repository
+	pr.BaseRepo = c.Repo.Repository
+	return pr
+}
+
+func ScheduleAutoMerge(c *context.Context) {
+	pr := checkOpenPullRequest(c)
+	if c.Written() {
+		return
+	}
+
+	mergeStyle, commitDescription := parseMergeOptions(c)
+	if err := pr.ScheduleAutoMerge(c.User, mergeStyle, commitDescription); err != nil {
+		if !database.IsErrMergeStyleNotAllowed(err) {
+			c.Error(err, "schedule auto-merge")
+			return
+		}
+		c.Flash.Error(c.Tr("repo.pulls.merge_style_not_allowed"))
+	} else {
+		log.Trace("Pull request auto-merge scheduled: %d", pr.ID)
+		c.Flash.Success(c.Tr("repo.pulls.auto_merge_scheduled"))
+	}
+	c.Redirect(c.Repo.RepoLink + "/pulls/" + strconv.FormatInt(pr.Index, 10))
+}
+
+func CancelAutoMerge(c *context.Context) {
+	pr := checkOpenPullRequest(c)
+	if c.Written() {
+		return
+	}
+
+	if pr.IsAutoMergeScheduled() {
+		if err := pr.CancelAutoMerge(c.User, ""); err != nil {
+			c.Error(err, "cancel auto-merge")
+			return
+		}
+		log.Trace("Pull request auto-merge cancelled: %d", pr.ID)
+		c.Flash.Success(c.Tr("repo.pulls.auto_merge_cancelled"))
+	}
+	c.Redirect(c.Repo.RepoLink + "/pulls/" + strconv.FormatInt(pr.Index, 10))
+}
+
+func UpdatePullRequestBranch(c *context.Context) {
+	issue := checkPullInfo(c)
+	if c.Written() {
+		return
+	}
+	if issue.IsClosed || issue.PullRequest.HasMerged {
+		c.NotFound()
+		return
+	}
+
+	pr := issue.PullRequest
+	if !pr.CanUpdateBranch(c.User.ID) {
+		c.NotFound()
+		return
+	}
+
+	style := database.UpdateStyle(c.Query("style"))
+	switch style {
+	case "":
+		style = database.UpdateStyleMerge
+	case database.UpdateStyleMerge, database.UpdateStyleRebase:
+	default:
+		c.Flash.Error(c.Tr("repo.pulls.update_branch_unsupported_style"))
+		c.Redirect(c.Repo.RepoLink + "/pulls/" + strconv.FormatInt(pr.Index, 10))
+		return
+	}
+
+	if err := pr.UpdateBranch(c.User, style); err != nil {
+		switch {
+		case database.IsErrPullRequestUpToDate(err):
+			c.Flash.Info(c.Tr("repo.pulls.update_branch_up_to_date"))
+		case database.IsErrPullRequestUpdateConflict(err):
+			c.Flash.Error(c.Tr("repo.pulls.update_branch_conflict"))
+		default:
+			c.Error(err, "update branch")
+			return
+		}
+	} else {
+		log.Trace("Pull request branch updated: %d", pr.ID)
+		c.Flash.Success(c.Tr("repo.pulls.update_branch_success"))
+	}
+	c.Redirect(c.Repo.RepoLink + "/pulls/" + strconv.FormatInt(pr.Index, 10))
+}
+
+func UpdateAllowMaintainerEdit(c *context.Context) {
+	issue := checkPullInfo(c)
+	if c.Written() {
+		return
+	}
+	if !issue.IsPoster(c.User.ID) || issue.PullRequest.HasMerged {
+		c.NotFound()
+		return
+	}
+
+	pr := issue.PullRequest
+	pr.AllowMaintainerEdit = c.QueryBool("allow") && pr.HeadRepoID != pr.BaseRepoID
+	if err := pr.UpdateCols("allow_maintainer_edit"); err != nil {
+		c.Error(err, "update pull request")
+		return
+	}
+	c.Redirect(c.Repo.RepoLink + "/pulls/" + strconv.FormatInt(pr.Index, 10))
+}
+
 func ParseCompareInfo(c *context.Context) (*database.User, *database.Repository, *git.Repository, *gitx.PullRequestMeta, string, string) {
 	baseRepo := c.Repo.Repository
 
@@ -602,7 +791,7 @@ func PrepareCompareDiff(
 		return false
 	}
 
-	c.Data["Commits"] = matchUsersWithCommitEmails(c.Req.Context(), meta.Commits)
+	c.Data["Commits"] = matchUsersWithCommitEmails(c.Req.Context(), headGitRepo.Path(), meta.Commits)
 	c.Data["CommitCount"] = len(meta.Commits)
 	c.Data["Username"] = headUser.Name
 	c.Data["Reponame"] = headRepo.Name
@@ -690,7 +879,7 @@ func CompareAndPullRequestPost(c *context.Context, f form.NewIssue) {
 		return
 	}
 
-	labelIDs, milestoneID, assigneeID := ValidateRepoMetas(c, f)
+	labelIDs, milestoneID, assigneeIDs := ValidateRepoMetas(c, f)
 	if c.Written() {
 		return
 	}
@@ -726,7 +915,6 @@ func CompareAndPullRequestPost(c *context.Context, f form.NewIssue) {
 		PosterID:    c.User.ID,
 		Poster:      c.User,
 		MilestoneID: milestoneID,
-		AssigneeID:  assigneeID,
 		IsPull:      true,
 		Content:     f.Content,
 	}
@@ -740,11 +928,18 @@ func CompareAndPullRequestPost(c *context.Context, f form.NewIssue) {
 		BaseRepo:     repo,
 		MergeBase:    meta.MergeBase,
 		Type:         database.PullRequestTypeGogs,
+
+		AllowMaintainerEdit: headRepo.ID != repo.ID && f.AllowMaintainerEdit,
 	}
 	// FIXME: check error in the case two people send pull request at almost same time, give nice error prompt
 	// instead of 500.
-	if err := database.NewPullRequest(repo, pullIssue, labelIDs, attachments, pullRequest, patch); err != nil {
-		c.Error(err, "new pull request")
+	if err := database.NewPullRequest(repo, pullIssue, labelIDs, assigneeIDs, attachments, pullRequest, patch); err != nil {
+		if database.IsErrBlockedByUser(err) {
+			c.Flash.Error(c.Tr("form.blocked_by_user"))
+			c.Redirect(conf.Server.Subpath + c.Req.URL.Path)
+		} else {
+			c.Error(err, "new pull request")
+		}
 		return
 	} else if err := pullRequest.PushToBaseRepo(); err != nil {
 		c.Error(err, "push to base repository")
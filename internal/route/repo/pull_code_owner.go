@@ -0,0 +1,123 @@
+package repo
+
+import (
+	"strconv"
+
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/database"
+)
+
+// RetrievePullCodeOwners sets code owners of changed files of the pull request
+// and the state of their sign-offs for rendering.
+func RetrievePullCodeOwners(c *context.Context, pr *database.PullRequest) {
+	codeOwners, err := pr.GetCodeOwners()
+	if err != nil {
+		c.Error(err, "get code owners")
+		return
+	} else if len(codeOwners) == 0 {
+		return
+	}
+
+	var (
+		owners        []*database.User
+		teams         []*database.Team
+		seenOwners    = make(map[int64]bool)
+		seenTeams     = make(map[int64]bool)
+		numSignedOff  int
+		codeOwnersMap = make(map[string]*database.CodeOwner, len(codeOwners))
+	)
+	for _, o := range codeOwners {
+		for _, u := range o.Owners {
+			if !seenOwners[u.ID] {
+				seenOwners[u.ID] = true
+				owners = append(owners, u)
+			}
+		}
+		for _, t := range o.Teams {
+			if !seenTeams[t.ID] {
+				seenTeams[t.ID] = true
+				teams = append(teams, t)
+			}
+		}
+		if o.SignedOff {
+			numSignedOff++
+		}
+		codeOwnersMap[o.Path] = o
+	}
+	c.Data["CodeOwners"] = codeOwners
+	c.Data["CodeOwnersByPath"] = codeOwnersMap
+	c.Data["CodeOwnerUsers"] = owners
+	c.Data["CodeOwnerTeams"] = teams
+	c.Data["NumCodeOwnerFiles"] = len(codeOwners)
+	c.Data["NumSignedOffCodeOwnerFiles"] = numSignedOff
+
+	c.Data["SignedOffUsers"], err = pr.GetSignedOffUsers()
+	if err != nil {
+		c.Error(err, "get signed off users")
+		return
+	}
+
+	if pr.HasMerged {
+		return
+	}
+
+	required, err := pr.IsCodeOwnerSignOffRequired()
+	if err != nil {
+		c.Error(err, "check if sign-offs are required")
+		return
+	}
+	c.Data["CodeOwnerSignOffRequired"] = required
+	c.Data["MissingCodeOwnerSignOffs"] = required && numSignedOff < len(codeOwners)
+
+	// Authors cannot sign off on their own pull requests.
+	if c.IsLogged && c.User.ID != pr.Issue.PosterID {
+		c.Data["IsCodeOwner"], err = pr.IsCodeOwner(c.User.ID)
+		if err != nil {
+			c.Error(err, "check code owner")
+			return
+		}
+		c.Data["HasSignedOff"], err = pr.HasSignedOff(c.User.ID)
+		if err != nil {
+			c.Error(err, "check sign-off")
+			return
+		}
+	}
+}
+
+func SignOffAsCodeOwner(c *context.Context) {
+	pr := checkOpenPullRequest(c)
+	if c.Written() {
+		return
+	}
+
+	if err := pr.SignOffAsCodeOwner(c.User); err != nil {
+		switch {
+		case database.IsErrSelfCodeOwnerSignOff(err):
+			c.Flash.Error(c.Tr("repo.pulls.code_owners.self_sign_off"))
+		case database.IsErrNotCodeOwner(err):
+			c.Flash.Error(c.Tr("repo.pulls.code_owners.not_owner"))
+		default:
+			c.Error(err, "sign off as code owner")
+			return
+		}
+	} else {
+		log.Trace("Pull request signed off by code owner [pull_request_id: %d, user_id: %d]", pr.ID, c.User.ID)
+		c.Flash.Success(c.Tr("repo.pulls.code_owners.signed_off"))
+	}
+	c.Redirect(c.Repo.RepoLink + "/pulls/" + strconv.FormatInt(pr.Index, 10))
+}
+
+func WithdrawCodeOwnerSignOff(c *context.Context) {
+	pr := checkOpenPullRequest(c)
+	if c.Written() {
+		return
+	}
+
+	if err := pr.WithdrawCodeOwnerSignOff(c.User); err != nil {
+		c.Error(err, "withdraw sign-off")
+		return
+	}
+	c.Redirect(c.Repo.RepoLink + "/pulls/" + strconv.FormatInt(pr.Index, 10))
+}
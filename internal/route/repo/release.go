@@ -47,6 +47,7 @@ func Releases(c *context.Context) {
 	c.Data["Title"] = c.Tr("repo.release.releases")
 	c.Data["PageIsViewFiles"] = true
 	c.Data["PageIsReleaseList"] = true
+	c.Data["FeedURL"] = c.Repo.RepoLink + "/releases"
 
 	tagsPage, err := gitx.Module.ListTagsAfter(c.Repo.GitRepo.Path(), c.Query("after"), 10)
 	if err != nil {
@@ -107,6 +108,8 @@ func Releases(c *context.Context) {
 			}
 			results[i].NumCommitsBehind = c.Repo.CommitsCount - results[i].NumCommits
 		}
+
+		results[i].Verification = database.VerifyTag(c.Req.Context(), c.Repo.GitRepo, rawTag)
 	}
 	database.SortReleases(results)
 
@@ -179,6 +182,11 @@ func NewReleasePost(c *context.Context, f form.NewRelease) {
 		return
 	}
 
+	isDraft := len(f.Draft) > 0
+	if !isDraft && !c.Repo.GitRepo.HasTag(f.TagName) && !checkTagProtection(c, f.TagName, &f) {
+		return
+	}
+
 	// Use current time if tag not yet exist, otherwise get time from Git
 	var tagCreatedUnix int64
 	tag, err := c.Repo.GitRepo.Tag(git.RefsTags + f.TagName)
@@ -215,7 +223,7 @@ func NewReleasePost(c *context.Context, f form.NewRelease) {
 		Sha1:         commit.ID.String(),
 		NumCommits:   commitsCount,
 		Note:         f.Content,
-		IsDraft:      len(f.Draft) > 0,
+		IsDraft:      isDraft,
 		IsPrerelease: f.Prerelease,
 		CreatedUnix:  tagCreatedUnix,
 	}
@@ -291,6 +299,10 @@ func EditReleasePost(c *context.Context, f form.EditRelease) {
 	}
 
 	isPublish := rel.IsDraft && f.Draft == ""
+	if isPublish && !c.Repo.GitRepo.HasTag(rel.TagName) && !checkTagProtection(c, rel.TagName, &f) {
+		return
+	}
+
 	rel.Title = f.Title
 	rel.Note = f.Content
 	rel.IsDraft = len(f.Draft) > 0
@@ -310,7 +322,43 @@ func UploadReleaseAttachment(c *context.Context) {
 	uploadAttachment(c, conf.Release.Attachment.AllowedTypes)
 }
 
+// checkTagProtection returns true if the current user is allowed to create
+// the tag. Otherwise, it renders the release form with an error and returns
+// false.
+func checkTagProtection(c *context.Context, tagName string, f any) bool {
+	allowed, err := database.CanUserChangeTag(c.Repo.Repository.ID, c.User.ID, tagName)
+	if err != nil {
+		c.Error(err, "check tag protection")
+		return false
+	} else if !allowed {
+		c.Data["Err_TagName"] = true
+		c.RenderWithErr(c.Tr("repo.release.tag_protected"), http.StatusForbidden, tmplRepoReleaseNew, f)
+		return false
+	}
+	return true
+}
+
 func DeleteRelease(c *context.Context) {
+	rel, err := database.GetReleaseByID(c.QueryInt64("id"))
+	if err != nil && !database.IsErrReleaseNotExist(err) {
+		c.Error(err, "get release by ID")
+		return
+	}
+
+	if err == nil && rel.RepoID == c.Repo.Repository.ID && !rel.IsDraft {
+		allowed, err := database.CanUserChangeTag(c.Repo.Repository.ID, c.User.ID, rel.TagName)
+		if err != nil {
+			c.Error(err, "check tag protection")
+			return
+		} else if !allowed {
+			c.Flash.Error(c.Tr("repo.release.tag_protected"))
+			c.JSONSuccess(map[string]any{
+				"redirect": c.Repo.RepoLink + "/releases",
+			})
+			return
+		}
+	}
+
 	if err := database.DeleteReleaseOfRepoByID(c.Repo.Repository.ID, c.QueryInt64("id")); err != nil {
 		c.Flash.Error("DeleteReleaseByID: " + err.Error())
 	} else {
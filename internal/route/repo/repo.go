@@ -2,9 +2,6 @@ package repo
 
 import (
 	"net/http"
-	"os"
-	"path"
-	"path/filepath"
 	"strings"
 
 	log "unknwon.dev/clog/v2"
@@ -15,8 +12,6 @@ import (
 	"gogs.io/gogs/internal/context"
 	"gogs.io/gogs/internal/database"
 	"gogs.io/gogs/internal/form"
-	"gogs.io/gogs/internal/osx"
-	"gogs.io/gogs/internal/tool"
 	"gogs.io/gogs/internal/urlx"
 )
 
@@ -261,8 +256,11 @@ func Action(c *context.Context) {
 	}
 
 	if err != nil {
-		c.Errorf(err, "action %q", c.Params(":action"))
-		return
+		if !database.IsErrBlockedByUser(err) {
+			c.Errorf(err, "action %q", c.Params(":action"))
+			return
+		}
+		c.Flash.Error(c.Tr("form.blocked_by_user"))
 	}
 
 	redirectTo := c.Query("redirect_to")
@@ -274,35 +272,24 @@ func Action(c *context.Context) {
 
 func Download(c *context.Context) {
 	var (
-		uri           = c.Params("*")
-		refName       string
-		ext           string
-		archivePath   string
-		archiveFormat git.ArchiveFormat
+		uri     = c.Params("*")
+		refName string
+		format  string
 	)
 
-	switch {
-	case strings.HasSuffix(uri, ".zip"):
-		ext = ".zip"
-		archivePath = filepath.Join(c.Repo.GitRepo.Path(), "archives", "zip")
-		archiveFormat = git.ArchiveZip
-	case strings.HasSuffix(uri, ".tar.gz"):
-		ext = ".tar.gz"
-		archivePath = filepath.Join(c.Repo.GitRepo.Path(), "archives", "targz")
-		archiveFormat = git.ArchiveTarGz
-	default:
+	// Formats like "tar" are suffixes of others like "tar.gz", the longest one
+	// wins.
+	for _, f := range conf.Repository.Archive.Formats {
+		if strings.HasSuffix(uri, "."+f) && len(f) > len(format) {
+			format = f
+		}
+	}
+	if format == "" {
 		log.Trace("Unknown format: %s", uri)
 		c.NotFound()
 		return
 	}
-	refName = strings.TrimSuffix(uri, ext)
-
-	if !osx.IsDir(archivePath) {
-		if err := os.MkdirAll(archivePath, os.ModePerm); err != nil {
-			c.Error(err, "create archive directory")
-			return
-		}
-	}
+	refName = strings.TrimSuffix(uri, "."+format)
 
 	// Get corresponding commit.
 	var (
@@ -333,13 +320,11 @@ func Download(c *context.Context) {
 		return
 	}
 
-	archivePath = path.Join(archivePath, tool.ShortSHA1(commit.ID.String())+ext)
-	if !osx.IsFile(archivePath) {
-		if err := commit.CreateArchive(archiveFormat, archivePath); err != nil {
-			c.Error(err, "creates archive")
-			return
-		}
+	archivePath, err := c.Repo.Repository.Archive(commit, format)
+	if err != nil {
+		c.Error(err, "create archive")
+		return
 	}
 
-	c.ServeFile(archivePath, c.Repo.Repository.Name+"-"+refName+ext)
+	c.ServeFile(archivePath, c.Repo.Repository.Name+"-"+refName+"."+format)
 }
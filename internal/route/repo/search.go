@@ -0,0 +1,64 @@
+package repo
+
+import (
+	"net/http"
+
+	"github.com/unknwon/paginater"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/database"
+	"gogs.io/gogs/internal/indexer"
+	"gogs.io/gogs/internal/linguist"
+)
+
+const tmplRepoSearch = "repo/search"
+
+// RenderCodeSearch searches code in given repositories, or all repositories the
+// current user has access to if none is given, and renders results with the
+// template.
+func RenderCodeSearch(c *context.Context, repoIDs []int64, tmplName string) {
+	if !database.IsCodeIndexerEnabled() {
+		c.NotFound()
+		return
+	}
+
+	page := max(c.QueryInt("page"), 1)
+	opts := indexer.CodeSearchOptions{
+		Keyword:  c.Query("q"),
+		IsRegexp: c.QueryBool("regex"),
+		RepoIDs:  repoIDs,
+		Path:     c.Query("path"),
+		Language: c.Query("language"),
+		Page:     page,
+		PageSize: conf.UI.ExplorePagingNum,
+	}
+	c.Data["Keyword"] = opts.Keyword
+	c.Data["IsRegexp"] = opts.IsRegexp
+	c.Data["SearchPath"] = opts.Path
+	c.Data["Language"] = opts.Language
+	c.Data["Languages"] = linguist.Languages()
+
+	matches, total, err := database.SearchCode(c.Req.Context(), c.UserID(), opts)
+	if err != nil {
+		if indexer.IsErrInvalidRegexp(err) {
+			c.Data["Err_Keyword"] = true
+			c.RenderWithErr(c.Tr("explore.code_invalid_regexp"), http.StatusUnprocessableEntity, tmplName, nil)
+			return
+		}
+		c.Error(err, "search code")
+		return
+	}
+	c.Data["CodeMatches"] = matches
+	c.Data["Total"] = total
+	c.Data["Page"] = paginater.New(total, conf.UI.ExplorePagingNum, page, 5)
+
+	c.Success(tmplName)
+}
+
+func Search(c *context.Context) {
+	c.Data["Title"] = c.Tr("explore.code") + " - " + c.Repo.Repository.FullName()
+	c.Data["PageIsViewFiles"] = true
+
+	RenderCodeSearch(c, []int64{c.Repo.Repository.ID}, tmplRepoSearch)
+}
@@ -18,6 +18,7 @@ import (
 	"gogs.io/gogs/internal/email"
 	"gogs.io/gogs/internal/form"
 	"gogs.io/gogs/internal/osx"
+	"gogs.io/gogs/internal/template"
 	"gogs.io/gogs/internal/tool"
 	"gogs.io/gogs/internal/userx"
 )
@@ -28,6 +29,7 @@ const (
 	tmplRepoSettingsCollaboration   = "repo/settings/collaboration"
 	tmplRepoSettingsBranches        = "repo/settings/branches"
 	tmplRepoSettingsProtectedBranch = "repo/settings/protected_branch"
+	tmplRepoSettingsTags            = "repo/settings/tags"
 	tmplRepoSettingsGithooks        = "repo/settings/githooks"
 	tmplRepoSettingsGithookEdit     = "repo/settings/githook_edit"
 	tmplRepoSettingsDeployKeys      = "repo/settings/deploy_keys"
@@ -37,6 +39,15 @@ func Settings(c *context.Context) {
 	c.Title("repo.settings")
 	c.PageIs("SettingsOptions")
 	c.RequireAutosize()
+
+	topics, err := database.GetRepoTopics(c.Repo.Repository.ID)
+	if err != nil {
+		c.Error(err, "get repository topics")
+		return
+	}
+	c.Data["Topics"] = strings.Join(topics, ", ")
+	c.Data["MaxRepoTopics"] = database.MaxRepoTopics
+
 	c.Success(tmplRepoSettingsOptions)
 }
 
@@ -49,11 +60,27 @@ func SettingsPost(c *context.Context, f form.RepoSetting) {
 
 	switch c.Query("action") {
 	case "update":
+		c.Data["Topics"] = f.Topics
+		c.Data["MaxRepoTopics"] = database.MaxRepoTopics
 		if c.HasError() {
 			c.HTML(http.StatusBadRequest, tmplRepoSettingsOptions)
 			return
 		}
 
+		topics, err := database.NormalizeTopics(strings.Split(f.Topics, ","))
+		if err != nil {
+			c.FormErr("Topics")
+			switch {
+			case database.IsErrInvalidTopic(err):
+				c.RenderWithErr(c.Tr("repo.settings.invalid_topic", err.(database.ErrInvalidTopic).Topic()), http.StatusBadRequest, tmplRepoSettingsOptions, &f)
+			case database.IsErrRepoTopicsLimit(err):
+				c.RenderWithErr(c.Tr("repo.settings.topics_limit", database.MaxRepoTopics), http.StatusBadRequest, tmplRepoSettingsOptions, &f)
+			default:
+				c.Error(err, "normalize topics")
+			}
+			return
+		}
+
 		isNameChanged := false
 		oldRepoName := repo.Name
 		newRepoName := f.RepoName
@@ -95,6 +122,10 @@ func SettingsPost(c *context.Context, f form.RepoSetting) {
 			c.Error(err, "update repository")
 			return
 		}
+		if err := database.SaveRepoTopics(repo.ID, topics); err != nil {
+			c.Error(err, "save repository topics")
+			return
+		}
 		log.Trace("Repository basic settings updated: %s/%s", c.Repo.Owner.Name, repo.Name)
 
 		if isNameChanged {
@@ -179,7 +210,10 @@ func SettingsPost(c *context.Context, f form.RepoSetting) {
 		repo.ExternalTrackerStyle = f.TrackerIssueStyle
 		repo.EnablePulls = f.EnablePulls
 		repo.PullsIgnoreWhitespace = f.PullsIgnoreWhitespace
+		repo.PullsAllowMerge = f.PullsAllowMerge
 		repo.PullsAllowRebase = f.PullsAllowRebase
+		repo.PullsAllowSquash = f.PullsAllowSquash
+		repo.PullsAllowFastForward = f.PullsAllowFastForward
 
 		if !repo.EnableWiki || repo.EnableExternalWiki {
 			repo.AllowPublicWiki = false
@@ -423,7 +457,12 @@ func SettingsCollaborationPost(c *context.Context) {
 	}
 
 	if err = c.Repo.Repository.AddCollaborator(u); err != nil {
-		c.Error(err, "add collaborator")
+		if database.IsErrBlockedByUser(err) {
+			c.Flash.Error(c.Tr("repo.settings.collaborator_blocked"))
+			c.Redirect(conf.Server.Subpath + c.Req.URL.Path)
+		} else {
+			c.Error(err, "add collaborator")
+		}
 		return
 	}
 
@@ -461,6 +500,12 @@ func DeleteCollaboration(c *context.Context) {
 	})
 }
 
+// BranchProtection is a branch along with the rule that applies to it.
+type BranchProtection struct {
+	Name string
+	Rule *database.ProtectBranch
+}
+
 func SettingsBranches(c *context.Context) {
 	c.Data["Title"] = c.Tr("repo.settings.branches")
 	c.Data["PageIsSettingsBranches"] = true
@@ -471,21 +516,38 @@ func SettingsBranches(c *context.Context) {
 		return
 	}
 
-	protectBranches, err := database.GetProtectBranchesByRepoID(c.Repo.Repository.ID)
+	// Redirect to the rule of given pattern
+	if pattern := strings.TrimSpace(c.Query("pattern")); pattern != "" {
+		if !database.IsValidBranchPattern(pattern) {
+			c.Flash.Error(c.Tr("repo.settings.protect_branch_pattern_invalid"))
+			c.Redirect(c.Repo.RepoLink + "/settings/branches")
+			return
+		}
+		c.Redirect(c.Repo.RepoLink + "/settings/branches/" + template.EscapePound(pattern))
+		return
+	}
+
+	protectBranches, err := database.GetProtectBranchRulesOfRepo(c.Repo.Repository.ID)
 	if err != nil {
-		c.Error(err, "get protect branch by repository ID")
+		c.Error(err, "get protect branch rules of repository")
 		return
 	}
 
-	// Filter out deleted branches
-	branches := make([]string, 0, len(protectBranches))
-	for i := range protectBranches {
-		if c.Repo.GitRepo.HasBranch(protectBranches[i].Name) {
-			branches = append(branches, protectBranches[i].Name)
+	branches, err := c.Repo.GitRepo.Branches()
+	if err != nil {
+		c.Error(err, "get branches")
+		return
+	}
+	branchProtections := make([]*BranchProtection, len(branches))
+	for i := range branches {
+		branchProtections[i] = &BranchProtection{
+			Name: branches[i],
+			Rule: database.MatchProtectBranch(protectBranches, branches[i]),
 		}
 	}
-	c.Data["ProtectBranches"] = branches
 
+	c.Data["ProtectBranches"] = protectBranches
+	c.Data["BranchProtections"] = branchProtections
 	c.Success(tmplRepoSettingsBranches)
 }
 
@@ -507,33 +569,47 @@ func UpdateDefaultBranch(c *context.Context) {
 		c.Error(err, "update repository")
 		return
 	}
+	database.UpdateCodeIndexer(c.Repo.Repository.ID)
+	database.UpdateLanguageStats(c.Repo.Repository.ID)
 
 	c.Flash.Success(c.Tr("repo.settings.update_default_branch_success"))
 	c.Redirect(c.Repo.RepoLink + "/settings/branches")
 }
 
-func SettingsProtectedBranch(c *context.Context) {
-	branch := c.Params("*")
-	if !c.Repo.GitRepo.HasBranch(branch) {
+// getProtectBranchOfPattern returns the rule of the repository with the branch
+// name or pattern in the URL, or a new rule if not exists. It returns nil if
+// any error occurred.
+func getProtectBranchOfPattern(c *context.Context) *database.ProtectBranch {
+	pattern := c.Params("*")
+	if !c.Repo.GitRepo.HasBranch(pattern) && !database.IsValidBranchPattern(pattern) {
 		c.NotFound()
-		return
+		return nil
 	}
 
-	c.Data["Title"] = c.Tr("repo.settings.protected_branches") + " - " + branch
-	c.Data["PageIsSettingsBranches"] = true
-
-	protectBranch, err := database.GetProtectBranchOfRepoByName(c.Repo.Repository.ID, branch)
+	protectBranch, err := database.GetProtectBranchOfRepoByPattern(c.Repo.Repository.ID, pattern)
 	if err != nil {
 		if !database.IsErrBranchNotExist(err) {
-			c.Error(err, "get protect branch of repository by name")
-			return
+			c.Error(err, "get protect branch of repository by pattern")
+			return nil
 		}
 
 		// No options found, create defaults.
 		protectBranch = &database.ProtectBranch{
-			Name: branch,
+			RepoID: c.Repo.Repository.ID,
+			Name:   pattern,
 		}
 	}
+	return protectBranch
+}
+
+func SettingsProtectedBranch(c *context.Context) {
+	protectBranch := getProtectBranchOfPattern(c)
+	if c.Written() {
+		return
+	}
+
+	c.Data["Title"] = c.Tr("repo.settings.protected_branches") + " - " + protectBranch.Name
+	c.Data["PageIsSettingsBranches"] = true
 
 	if c.Repo.Owner.IsOrganization() {
 		users, err := c.Repo.Repository.GetWriters()
@@ -551,6 +627,22 @@ func SettingsProtectedBranch(c *context.Context) {
 		}
 		c.Data["Teams"] = teams
 		c.Data["whitelist_teams"] = protectBranch.WhitelistTeamIDs
+		c.Data["CanWhitelist"] = true
+	}
+
+	if protectBranch.IsPattern() {
+		branches, err := c.Repo.GitRepo.Branches()
+		if err != nil {
+			c.Error(err, "get branches")
+			return
+		}
+		matchedBranches := make([]string, 0, len(branches))
+		for _, branch := range branches {
+			if protectBranch.Match(branch) {
+				matchedBranches = append(matchedBranches, branch)
+			}
+		}
+		c.Data["MatchedBranches"] = matchedBranches
 	}
 
 	c.Data["Branch"] = protectBranch
@@ -558,28 +650,17 @@ func SettingsProtectedBranch(c *context.Context) {
 }
 
 func SettingsProtectedBranchPost(c *context.Context, f form.ProtectBranch) {
-	branch := c.Params("*")
-	if !c.Repo.GitRepo.HasBranch(branch) {
-		c.NotFound()
+	protectBranch := getProtectBranchOfPattern(c)
+	if c.Written() {
 		return
 	}
 
-	protectBranch, err := database.GetProtectBranchOfRepoByName(c.Repo.Repository.ID, branch)
-	if err != nil {
-		if !database.IsErrBranchNotExist(err) {
-			c.Error(err, "get protect branch of repository by name")
-			return
-		}
-
-		// No options found, create defaults.
-		protectBranch = &database.ProtectBranch{
-			RepoID: c.Repo.Repository.ID,
-			Name:   branch,
-		}
-	}
-
+	var err error
 	protectBranch.Protected = f.Protected
 	protectBranch.RequirePullRequest = f.RequirePullRequest
+	protectBranch.RequireSignedCommits = f.RequireSignedCommits
+	protectBranch.RequireLinearHistory = f.RequireLinearHistory
+	protectBranch.RequireCodeOwnerSignOff = f.RequireCodeOwnerSignOff
 	protectBranch.EnableWhitelist = f.EnableWhitelist
 	if c.Repo.Owner.IsOrganization() {
 		err = database.UpdateOrgProtectBranch(c.Repo.Repository, protectBranch, f.WhitelistUsers, f.WhitelistTeams)
@@ -592,7 +673,141 @@ func SettingsProtectedBranchPost(c *context.Context, f form.ProtectBranch) {
 	}
 
 	c.Flash.Success(c.Tr("repo.settings.update_protect_branch_success"))
-	c.Redirect(fmt.Sprintf("%s/settings/branches/%s", c.Repo.RepoLink, branch))
+	c.Redirect(fmt.Sprintf("%s/settings/branches/%s", c.Repo.RepoLink, template.EscapePound(protectBranch.Name)))
+}
+
+func DeleteProtectedBranch(c *context.Context) {
+	protectBranch, err := database.GetProtectBranchByID(c.QueryInt64("id"))
+	if err != nil {
+		if !database.IsErrBranchNotExist(err) {
+			c.Flash.Error("GetProtectBranchByID: " + err.Error())
+		}
+	} else if protectBranch.RepoID == c.Repo.Repository.ID {
+		if err = database.DeleteProtectBranch(protectBranch); err != nil {
+			c.Flash.Error("DeleteProtectBranch: " + err.Error())
+		} else {
+			c.Flash.Success(c.Tr("repo.settings.protect_branch_deletion_success"))
+		}
+	}
+
+	c.JSONSuccess(map[string]any{
+		"redirect": c.Repo.RepoLink + "/settings/branches",
+	})
+}
+
+// prepareSettingsTags loads tag protection rules of the repository along with
+// candidates of the whitelist, and the rule to be edited in the form. It
+// returns false if any error occurred.
+func prepareSettingsTags(c *context.Context, protectTag *database.ProtectTag) bool {
+	c.Data["Title"] = c.Tr("repo.settings.protected_tags")
+	c.Data["PageIsSettingsTags"] = true
+
+	protectTags, err := database.GetProtectTagsByRepoID(c.Repo.Repository.ID)
+	if err != nil {
+		c.Error(err, "get protect tags by repository ID")
+		return false
+	}
+	for _, t := range protectTags {
+		if err = t.LoadAttributes(); err != nil {
+			c.Error(err, "load attributes")
+			return false
+		}
+	}
+	c.Data["ProtectTags"] = protectTags
+
+	users, err := c.Repo.Repository.GetWriters()
+	if err != nil {
+		c.Error(err, "get writers")
+		return false
+	}
+	c.Data["Users"] = users
+
+	if c.Repo.Owner.IsOrganization() {
+		teams, err := c.Repo.Owner.TeamsHaveAccessToRepo(c.Repo.Repository.ID, database.AccessModeWrite)
+		if err != nil {
+			c.Error(err, "get teams have access to the repository")
+			return false
+		}
+		c.Data["Teams"] = teams
+	}
+
+	c.Data["ProtectTag"] = protectTag
+	return true
+}
+
+func SettingsTags(c *context.Context) {
+	if !prepareSettingsTags(c, &database.ProtectTag{}) {
+		return
+	}
+	c.Success(tmplRepoSettingsTags)
+}
+
+func SettingsProtectedTag(c *context.Context) {
+	protectTag, err := database.GetProtectTagByID(c.Repo.Repository.ID, c.ParamsInt64(":id"))
+	if err != nil {
+		c.NotFoundOrError(err, "get protect tag by ID")
+		return
+	}
+
+	if !prepareSettingsTags(c, protectTag) {
+		return
+	}
+	c.Success(tmplRepoSettingsTags)
+}
+
+func saveProtectTag(c *context.Context, protectTag *database.ProtectTag, f form.ProtectTag) {
+	protectTag.Pattern = strings.TrimSpace(f.Pattern)
+	protectTag.WhitelistUserIDs = f.WhitelistUsers
+	protectTag.WhitelistTeamIDs = f.WhitelistTeams
+	if !prepareSettingsTags(c, protectTag) {
+		return
+	}
+
+	if c.HasError() {
+		c.HTML(http.StatusBadRequest, tmplRepoSettingsTags)
+		return
+	}
+
+	if err := database.SaveProtectTag(c.Repo.Repository, protectTag); err != nil {
+		c.FormErr("Pattern")
+		switch {
+		case database.IsErrInvalidTagPattern(err):
+			c.RenderWithErr(c.Tr("repo.settings.protect_tag_pattern_invalid"), http.StatusBadRequest, tmplRepoSettingsTags, nil)
+		case database.IsErrProtectTagAlreadyExist(err):
+			c.RenderWithErr(c.Tr("repo.settings.protect_tag_pattern_exists"), http.StatusUnprocessableEntity, tmplRepoSettingsTags, nil)
+		default:
+			c.Error(err, "save protect tag")
+		}
+		return
+	}
+
+	c.Flash.Success(c.Tr("repo.settings.update_protect_tag_success", protectTag.Pattern))
+	c.Redirect(c.Repo.RepoLink + "/settings/tags")
+}
+
+func SettingsTagsPost(c *context.Context, f form.ProtectTag) {
+	saveProtectTag(c, &database.ProtectTag{}, f)
+}
+
+func SettingsProtectedTagPost(c *context.Context, f form.ProtectTag) {
+	protectTag, err := database.GetProtectTagByID(c.Repo.Repository.ID, c.ParamsInt64(":id"))
+	if err != nil {
+		c.NotFoundOrError(err, "get protect tag by ID")
+		return
+	}
+	saveProtectTag(c, protectTag, f)
+}
+
+func DeleteProtectedTag(c *context.Context) {
+	if err := database.DeleteProtectTagByID(c.Repo.Repository.ID, c.QueryInt64("id")); err != nil {
+		c.Flash.Error("DeleteProtectTagByID: " + err.Error())
+	} else {
+		c.Flash.Success(c.Tr("repo.settings.protect_tag_deletion_success"))
+	}
+
+	c.JSONSuccess(map[string]any{
+		"redirect": c.Repo.RepoLink + "/settings/tags",
+	})
 }
 
 func SettingsGitHooks(c *context.Context) {
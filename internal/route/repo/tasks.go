@@ -66,5 +66,9 @@ func TriggerTask(c *macaron.Context) {
 
 	go database.HookQueue.Add(repo.ID)
 	go database.AddTestPullRequestTask(pusher, repo.ID, branch, true)
+	if branch == repo.DefaultBranch {
+		database.UpdateCodeIndexer(repo.ID)
+		database.UpdateLanguageStats(repo.ID)
+	}
 	c.Status(http.StatusAccepted)
 }
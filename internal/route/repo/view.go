@@ -258,8 +258,21 @@ func Home(c *context.Context) {
 			return
 		}
 		c.Data["CommitsCount"] = c.Repo.CommitsCount
+
+		c.Data["LanguageStats"], err = database.GetLanguageStats(c.Repo.Repository.ID)
+		if err != nil {
+			c.Error(err, "get language stats")
+			return
+		}
+
+		c.Data["Topics"], err = database.GetRepoTopics(c.Repo.Repository.ID)
+		if err != nil {
+			c.Error(err, "get repository topics")
+			return
+		}
 	}
 	c.Data["PageIsRepoHome"] = isRootDir
+	c.Data["IsCodeSearchEnabled"] = database.IsCodeIndexerEnabled()
 
 	// Get current entry user currently looking at.
 	entry, err := c.Repo.Commit.TreeEntry(c.Repo.TreePath)
@@ -0,0 +1,193 @@
+package scim
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+// comparison is a single attribute expression of a filter, e.g.
+// `userName eq "alice"`.
+type comparison struct {
+	// The lowercased attribute path, e.g. "username" or "name.formatted".
+	attr string
+	// The lowercased operator, e.g. "eq" or "pr".
+	op    string
+	value string
+}
+
+// filter is a parsed SCIM filter (RFC 7644, section 3.4.2.2). Only attribute
+// expressions joined by "and" are supported.
+type filter []comparison
+
+var filterOperators = map[string]bool{
+	"eq": true,
+	"ne": true,
+	"co": true,
+	"sw": true,
+	"ew": true,
+	"pr": true,
+}
+
+// tokenizeFilter splits the filter into space-separated tokens, with quoted
+// strings being unquoted as a single token.
+func tokenizeFilter(s string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == ' ':
+			i++
+
+		case s[i] == '"':
+			end := i + 1
+			for ; end < len(s) && s[end] != '"'; end++ {
+				if s[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(s) {
+				return nil, errors.New("unterminated string")
+			}
+
+			var token string
+			err := json.Unmarshal([]byte(s[i:end+1]), &token)
+			if err != nil {
+				return nil, errors.Newf("invalid string %s", s[i:end+1])
+			}
+			tokens = append(tokens, token)
+			i = end + 1
+
+		default:
+			end := strings.IndexByte(s[i:], ' ')
+			if end < 0 {
+				end = len(s) - i
+			}
+			tokens = append(tokens, s[i:i+end])
+			i += end
+		}
+	}
+	return tokens, nil
+}
+
+// parseFilter parses the filter expression. An empty expression results in a
+// filter that matches everything.
+func parseFilter(s string) (filter, error) {
+	tokens, err := tokenizeFilter(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var f filter
+	for len(tokens) > 0 {
+		if len(f) > 0 {
+			if !strings.EqualFold(tokens[0], "and") {
+				return nil, errors.Newf("unsupported logical operator %q", tokens[0])
+			}
+			tokens = tokens[1:]
+		}
+
+		if len(tokens) < 2 {
+			return nil, errors.New("incomplete attribute expression")
+		}
+		cmp := comparison{
+			attr: strings.ToLower(tokens[0]),
+			op:   strings.ToLower(tokens[1]),
+		}
+		if strings.ContainsAny(cmp.attr, "()[]") {
+			return nil, errors.Newf("unsupported attribute path %q", tokens[0])
+		} else if !filterOperators[cmp.op] {
+			return nil, errors.Newf("unsupported operator %q", tokens[1])
+		}
+
+		if cmp.op == "pr" {
+			tokens = tokens[2:]
+		} else {
+			if len(tokens) < 3 {
+				return nil, errors.New("incomplete attribute expression")
+			}
+			cmp.value = tokens[2]
+			tokens = tokens[3:]
+		}
+		f = append(f, cmp)
+	}
+	return f, nil
+}
+
+// match returns true if the attributes satisfy all comparisons of the filter.
+// Attributes are keyed by lowercased paths, and values are compared
+// case-insensitively.
+func (f filter) match(attrs map[string][]string) bool {
+	for _, cmp := range f {
+		if !cmp.match(attrs[cmp.attr]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (cmp comparison) match(values []string) bool {
+	if cmp.op == "ne" {
+		for _, v := range values {
+			if strings.EqualFold(v, cmp.value) {
+				return false
+			}
+		}
+		return true
+	}
+
+	want := strings.ToLower(cmp.value)
+	for _, v := range values {
+		v = strings.ToLower(v)
+		switch cmp.op {
+		case "eq":
+			if v == want {
+				return true
+			}
+		case "co":
+			if strings.Contains(v, want) {
+				return true
+			}
+		case "sw":
+			if strings.HasPrefix(v, want) {
+				return true
+			}
+		case "ew":
+			if strings.HasSuffix(v, want) {
+				return true
+			}
+		case "pr":
+			if v != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// equality returns the value if the filter is exactly one "eq" comparison on
+// the given attribute.
+func (f filter) equality(attr string) (string, bool) {
+	if len(f) != 1 || f[0].attr != attr || f[0].op != "eq" {
+		return "", false
+	}
+	return f[0].value, true
+}
+
+// parsePathFilter parses a value path with a filter, e.g.
+// `members[value eq "1"]`, and returns the attribute name and the filter. The
+// filter is nil if the path has no brackets.
+func parsePathFilter(path string) (attr string, f filter, err error) {
+	open := strings.IndexByte(path, '[')
+	if open < 0 {
+		return strings.ToLower(path), nil, nil
+	} else if !strings.HasSuffix(path, "]") {
+		return "", nil, errors.Newf("unsupported path %q", path)
+	}
+
+	f, err = parseFilter(path[open+1 : len(path)-1])
+	if err != nil {
+		return "", nil, err
+	}
+	return strings.ToLower(path[:open]), f, nil
+}
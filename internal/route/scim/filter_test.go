@@ -0,0 +1,122 @@
+package scim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  string
+		want    filter
+		wantErr string
+	}{
+		{
+			name:   "empty",
+			filter: "",
+		},
+		{
+			name:   "equality",
+			filter: `userName eq "alice"`,
+			want:   filter{{attr: "username", op: "eq", value: "alice"}},
+		},
+		{
+			name:   "escaped string",
+			filter: `displayName co "say \"hi\" "`,
+			want:   filter{{attr: "displayname", op: "co", value: `say "hi" `}},
+		},
+		{
+			name:   "present and joined",
+			filter: `emails pr AND active eq true`,
+			want: filter{
+				{attr: "emails", op: "pr"},
+				{attr: "active", op: "eq", value: "true"},
+			},
+		},
+		{
+			name:    "unsupported operator",
+			filter:  `userName gt "a"`,
+			wantErr: `unsupported operator "gt"`,
+		},
+		{
+			name:    "unsupported logical operator",
+			filter:  `userName eq "a" or userName eq "b"`,
+			wantErr: `unsupported logical operator "or"`,
+		},
+		{
+			name:    "unsupported attribute path",
+			filter:  `emails[type eq "work"] pr`,
+			wantErr: "unsupported attribute path",
+		},
+		{
+			name:    "incomplete",
+			filter:  `userName eq`,
+			wantErr: "incomplete attribute expression",
+		},
+		{
+			name:    "unterminated string",
+			filter:  `userName eq "alice`,
+			wantErr: "unterminated string",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseFilter(test.filter)
+			if test.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestFilter_Match(t *testing.T) {
+	attrs := map[string][]string{
+		"username": {"Alice"},
+		"emails":   {"alice@example.com", "alice@corp.example.com"},
+		"active":   {"true"},
+	}
+	tests := []struct {
+		filter string
+		want   bool
+	}{
+		{filter: "", want: true},
+		{filter: `userName eq "alice"`, want: true},
+		{filter: `userName ne "alice"`, want: false},
+		{filter: `userName ne "bob"`, want: true},
+		{filter: `userName sw "al"`, want: true},
+		{filter: `userName ew "ce"`, want: true},
+		{filter: `emails co "@corp."`, want: true},
+		{filter: `emails pr`, want: true},
+		{filter: `externalId pr`, want: false},
+		{filter: `userName eq "alice" and active eq false`, want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.filter, func(t *testing.T) {
+			f, err := parseFilter(test.filter)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, f.match(attrs))
+		})
+	}
+}
+
+func TestParsePathFilter(t *testing.T) {
+	attr, f, err := parsePathFilter("displayName")
+	require.NoError(t, err)
+	assert.Equal(t, "displayname", attr)
+	assert.Nil(t, f)
+
+	attr, f, err = parsePathFilter(`members[value eq "2"]`)
+	require.NoError(t, err)
+	assert.Equal(t, "members", attr)
+	assert.Equal(t, filter{{attr: "value", op: "eq", value: "2"}}, f)
+
+	_, _, err = parsePathFilter(`emails[type eq "work"].value`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,421 @@
+package scim
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/go-macaron/binding"
+	"gopkg.in/macaron.v1"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/database"
+)
+
+type groupMember struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// groupResource is the SCIM representation of a team of the configured
+// organization.
+type groupResource struct {
+	Schemas     []string      `json:"schemas"`
+	ID          string        `json:"id,omitempty"`
+	DisplayName string        `json:"displayName"`
+	Members     []groupMember `json:"members,omitempty"`
+	Meta        *meta         `json:"meta,omitempty"`
+}
+
+// toGroupResource converts the team to a group, members of the team must be
+// loaded beforehand.
+func toGroupResource(t *database.Team) *groupResource {
+	r := &groupResource{
+		Schemas:     []string{schemaGroup},
+		ID:          strconv.FormatInt(t.ID, 10),
+		DisplayName: t.Name,
+		Members:     make([]groupMember, 0, len(t.Members)),
+		Meta: &meta{
+			ResourceType: "Group",
+			Location:     location("Groups", t.ID),
+		},
+	}
+	for _, u := range t.Members {
+		r.Members = append(r.Members, groupMember{
+			Value:   strconv.FormatInt(u.ID, 10),
+			Display: u.Name,
+			Ref:     location("Users", u.ID),
+		})
+	}
+	return r
+}
+
+// groupAttributes returns the filterable attributes of the group.
+func groupAttributes(r *groupResource) map[string][]string {
+	attrs := map[string][]string{
+		"id":          {r.ID},
+		"displayname": {r.DisplayName},
+	}
+	for _, m := range r.Members {
+		attrs["members"] = append(attrs["members"], m.Value)
+		attrs["members.value"] = append(attrs["members.value"], m.Value)
+	}
+	return attrs
+}
+
+// applyGroupPatch applies PATCH operations to the group resource.
+func applyGroupPatch(r *groupResource, ops []patchOperation) error {
+	for _, op := range ops {
+		opName := strings.ToLower(op.Op)
+		if opName != "add" && opName != "replace" && opName != "remove" {
+			return errors.Newf("unsupported operation %q", op.Op)
+		}
+
+		attr, f, err := parsePathFilter(op.Path)
+		if err != nil {
+			return err
+		}
+
+		if attr == "" {
+			if opName == "remove" {
+				return errors.New("path is required for remove operation")
+			}
+
+			var values map[string]json.RawMessage
+			err = json.Unmarshal(op.Value, &values)
+			if err != nil {
+				return errors.New("value must be an object when path is not specified")
+			}
+			for path, value := range values {
+				err = applyGroupOperation(r, opName, strings.ToLower(path), nil, value)
+				if err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		err = applyGroupOperation(r, opName, attr, f, op.Value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyGroupOperation applies a single operation to the attribute of the
+// group. The filter is only used to select members for removal. Attributes
+// that are not stored are ignored.
+func applyGroupOperation(r *groupResource, op, attr string, f filter, raw json.RawMessage) error {
+	switch attr {
+	case "displayname":
+		if op == "remove" {
+			return errors.New(`attribute "displayName" cannot be removed`)
+		}
+		err := json.Unmarshal(raw, &r.DisplayName)
+		if err != nil {
+			return errors.New(`invalid string value for "displayName"`)
+		}
+
+	case "members":
+		var members []groupMember
+		if len(raw) > 0 {
+			err := json.Unmarshal(raw, &members)
+			if err != nil {
+				return errors.New(`invalid value for "members"`)
+			}
+		}
+
+		switch op {
+		case "add":
+			r.Members = append(r.Members, members...)
+
+		case "replace":
+			if f != nil {
+				return errors.New(`filter is not supported for replacing "members"`)
+			}
+			r.Members = members
+
+		case "remove":
+			remove := func(m groupMember) bool {
+				if f != nil {
+					return f.match(map[string][]string{"value": {m.Value}, "display": {m.Display}})
+				} else if len(members) == 0 {
+					return true // Remove all members
+				}
+				for _, rm := range members {
+					if rm.Value == m.Value {
+						return true
+					}
+				}
+				return false
+			}
+
+			kept := r.Members[:0]
+			for _, m := range r.Members {
+				if !remove(m) {
+					kept = append(kept, m)
+				}
+			}
+			r.Members = kept
+		}
+	}
+	return nil
+}
+
+func listGroups(c *macaron.Context, org *database.User) {
+	f, err := parseFilter(c.Query("filter"))
+	if err != nil {
+		responseError(c.Resp, http.StatusBadRequest, "invalidFilter", err.Error())
+		return
+	}
+
+	teams, err := database.GetTeamsByOrgID(org.ID)
+	if err != nil {
+		internalServerError(c.Resp)
+		log.Error("Failed to get teams of organization [id: %d]: %v", org.ID, err)
+		return
+	}
+
+	excludeMembers := strings.Contains(strings.ToLower(c.Query("excludedAttributes")), "members")
+	resources := make([]any, 0, len(teams))
+	for _, t := range teams {
+		if err = t.GetMembers(); err != nil {
+			internalServerError(c.Resp)
+			log.Error("Failed to get members of team [id: %d]: %v", t.ID, err)
+			return
+		}
+
+		r := toGroupResource(t)
+		if !f.match(groupAttributes(r)) {
+			continue
+		}
+		if excludeMembers {
+			r.Members = nil
+		}
+		resources = append(resources, r)
+	}
+	responseJSON(c.Resp, http.StatusOK, paginate(c, resources))
+}
+
+// groupByID maps the team with the ":id" URL parameter, and the team must
+// belong to the organization.
+func groupByID(c *macaron.Context, org *database.User) {
+	team, err := database.GetTeamByID(c.ParamsInt64(":id"))
+	if err != nil {
+		if database.IsErrTeamNotExist(err) {
+			responseError(c.Resp, http.StatusNotFound, "", "Group not found")
+		} else {
+			internalServerError(c.Resp)
+			log.Error("Failed to get team [id: %d]: %v", c.ParamsInt64(":id"), err)
+		}
+		return
+	} else if team.OrgID != org.ID {
+		responseError(c.Resp, http.StatusNotFound, "", "Group not found")
+		return
+	}
+
+	if err = team.GetMembers(); err != nil {
+		internalServerError(c.Resp)
+		log.Error("Failed to get members of team [id: %d]: %v", team.ID, err)
+		return
+	}
+	c.Map(team)
+}
+
+func getGroup(c *macaron.Context, team *database.Team) {
+	responseJSON(c.Resp, http.StatusOK, toGroupResource(team))
+}
+
+// validateTeamName responds with an error and returns false if the name is not
+// a valid team name.
+func validateTeamName(c *macaron.Context, name string) bool {
+	if name == "" || len(name) > 30 || binding.AlphaDashDotPattern.MatchString(name) {
+		responseError(c.Resp, http.StatusBadRequest, "invalidValue",
+			"Attribute displayName must be a valid team name with at most 30 alpha-numeric, dash, underscore or dot characters")
+		return false
+	}
+	return true
+}
+
+// setGroupMembers changes members of the team to exactly the given members.
+// It responds with an error and returns false on failure.
+func setGroupMembers(c *macaron.Context, org *database.User, team *database.Team, members []groupMember) bool {
+	want := make(map[int64]bool, len(members))
+	for _, m := range members {
+		userID, err := strconv.ParseInt(m.Value, 10, 64)
+		if err != nil {
+			responseError(c.Resp, http.StatusBadRequest, "invalidValue", "Invalid member "+strconv.Quote(m.Value))
+			return false
+		}
+
+		user, err := database.Handle.Users().GetByID(c.Req.Context(), userID)
+		if err != nil {
+			if database.IsErrUserNotExist(err) {
+				responseError(c.Resp, http.StatusBadRequest, "invalidValue", "User "+m.Value+" not found")
+			} else {
+				internalServerError(c.Resp)
+				log.Error("Failed to get user [id: %d]: %v", userID, err)
+			}
+			return false
+		} else if user.IsOrganization() {
+			responseError(c.Resp, http.StatusBadRequest, "invalidValue", "User "+m.Value+" not found")
+			return false
+		}
+		want[userID] = true
+	}
+
+	have := make(map[int64]bool, len(team.Members))
+	for _, u := range team.Members {
+		have[u.ID] = true
+		if want[u.ID] {
+			continue
+		}
+
+		err := database.RemoveTeamMember(org.ID, team.ID, u.ID)
+		if err != nil {
+			if database.IsErrLastOrgOwner(err) {
+				responseError(c.Resp, http.StatusConflict, "", "Cannot remove the last owner of the organization")
+			} else {
+				internalServerError(c.Resp)
+				log.Error("Failed to remove member [user_id: %d] from team [id: %d]: %v", u.ID, team.ID, err)
+			}
+			return false
+		}
+	}
+
+	for userID := range want {
+		if have[userID] {
+			continue
+		}
+
+		err := database.AddTeamMember(org.ID, team.ID, userID)
+		if err != nil {
+			internalServerError(c.Resp)
+			log.Error("Failed to add member [user_id: %d] to team [id: %d]: %v", userID, team.ID, err)
+			return false
+		}
+	}
+	return true
+}
+
+// responseGroup reloads members of the team and responds with the group.
+func responseGroup(c *macaron.Context, status int, team *database.Team) {
+	err := team.GetMembers()
+	if err != nil {
+		internalServerError(c.Resp)
+		log.Error("Failed to get members of team [id: %d]: %v", team.ID, err)
+		return
+	}
+	responseJSON(c.Resp, status, toGroupResource(team))
+}
+
+func createGroup(c *macaron.Context, org *database.User) {
+	var r groupResource
+	if !decodeBody(c, &r) || !validateTeamName(c, r.DisplayName) {
+		return
+	}
+
+	team := &database.Team{
+		OrgID:     org.ID,
+		Name:      r.DisplayName,
+		Authorize: database.AccessModeRead,
+	}
+	err := database.NewTeam(team)
+	if err != nil {
+		switch {
+		case database.IsErrTeamAlreadyExist(err):
+			responseError(c.Resp, http.StatusConflict, "uniqueness", err.Error())
+		case database.IsErrNameNotAllowed(err):
+			responseError(c.Resp, http.StatusBadRequest, "invalidValue", err.Error())
+		default:
+			internalServerError(c.Resp)
+			log.Error("Failed to create team: %v", err)
+		}
+		return
+	}
+	log.Trace("[SCIM] Team created: %s/%s", org.Name, team.Name)
+
+	if !setGroupMembers(c, org, team, r.Members) {
+		return
+	}
+
+	c.Resp.Header().Set("Location", location("Groups", team.ID))
+	responseGroup(c, http.StatusCreated, team)
+}
+
+// updateGroup updates the team to match the group and responds with the
+// updated group.
+func updateGroup(c *macaron.Context, org *database.User, team *database.Team, r *groupResource) {
+	if !validateTeamName(c, r.DisplayName) {
+		return
+	}
+
+	if r.DisplayName != team.Name {
+		if team.IsOwnerTeam() {
+			responseError(c.Resp, http.StatusBadRequest, "mutability", "The owner team cannot be renamed")
+			return
+		}
+
+		team.Name = r.DisplayName
+		err := database.UpdateTeam(team, false)
+		if err != nil {
+			switch {
+			case database.IsErrTeamAlreadyExist(err):
+				responseError(c.Resp, http.StatusConflict, "uniqueness", err.Error())
+			default:
+				internalServerError(c.Resp)
+				log.Error("Failed to update team [id: %d]: %v", team.ID, err)
+			}
+			return
+		}
+	}
+
+	if !setGroupMembers(c, org, team, r.Members) {
+		return
+	}
+	responseGroup(c, http.StatusOK, team)
+}
+
+func replaceGroup(c *macaron.Context, org *database.User, team *database.Team) {
+	var r groupResource
+	if !decodeBody(c, &r) {
+		return
+	}
+	updateGroup(c, org, team, &r)
+}
+
+func patchGroup(c *macaron.Context, org *database.User, team *database.Team) {
+	var req patchRequest
+	if !decodeBody(c, &req) {
+		return
+	}
+
+	r := toGroupResource(team)
+	err := applyGroupPatch(r, req.Operations)
+	if err != nil {
+		responseError(c.Resp, http.StatusBadRequest, "invalidValue", err.Error())
+		return
+	}
+	updateGroup(c, org, team, r)
+}
+
+func deleteGroup(c *macaron.Context, team *database.Team) {
+	if team.IsOwnerTeam() {
+		responseError(c.Resp, http.StatusBadRequest, "mutability", "The owner team cannot be deleted")
+		return
+	}
+
+	err := database.DeleteTeam(team)
+	if err != nil {
+		internalServerError(c.Resp)
+		log.Error("Failed to delete team [id: %d]: %v", team.ID, err)
+		return
+	}
+	log.Trace("[SCIM] Team deleted: %s", team.Name)
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,94 @@
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyGroupPatch(t *testing.T) {
+	newGroup := func() *groupResource {
+		return &groupResource{
+			DisplayName: "developers",
+			Members: []groupMember{
+				{Value: "1", Display: "alice"},
+				{Value: "2", Display: "bob"},
+			},
+		}
+	}
+	memberValues := func(r *groupResource) []string {
+		values := make([]string, 0, len(r.Members))
+		for _, m := range r.Members {
+			values = append(values, m.Value)
+		}
+		return values
+	}
+
+	tests := []struct {
+		name        string
+		ops         string
+		wantName    string
+		wantMembers []string
+		wantErr     string
+	}{
+		{
+			name:        "add members",
+			ops:         `[{"op":"add","path":"members","value":[{"value":"3"}]}]`,
+			wantName:    "developers",
+			wantMembers: []string{"1", "2", "3"},
+		},
+		{
+			name:        "remove member with filter",
+			ops:         `[{"op":"remove","path":"members[value eq \"1\"]"}]`,
+			wantName:    "developers",
+			wantMembers: []string{"2"},
+		},
+		{
+			name:        "remove members with value",
+			ops:         `[{"op":"Remove","path":"members","value":[{"value":"2"}]}]`,
+			wantName:    "developers",
+			wantMembers: []string{"1"},
+		},
+		{
+			name:        "remove all members",
+			ops:         `[{"op":"remove","path":"members"}]`,
+			wantName:    "developers",
+			wantMembers: []string{},
+		},
+		{
+			name:        "replace without path",
+			ops:         `[{"op":"replace","value":{"displayName":"engineers","members":[{"value":"4"}]}}]`,
+			wantName:    "engineers",
+			wantMembers: []string{"4"},
+		},
+		{
+			name:    "remove display name",
+			ops:     `[{"op":"remove","path":"displayName"}]`,
+			wantErr: `attribute "displayName" cannot be removed`,
+		},
+		{
+			name:    "invalid path filter",
+			ops:     `[{"op":"remove","path":"members[value gt \"1\"]"}]`,
+			wantErr: `unsupported operator "gt"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var ops []patchOperation
+			require.NoError(t, json.Unmarshal([]byte(test.ops), &ops))
+
+			r := newGroup()
+			err := applyGroupPatch(r, ops)
+			if test.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, test.wantErr, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.wantName, r.DisplayName)
+			assert.Equal(t, test.wantMembers, memberValues(r))
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package scim
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gopkg.in/macaron.v1"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/database"
+)
+
+const contentType = "application/scim+json"
+
+// The schema URIs defined by RFC 7643 and RFC 7644.
+const (
+	schemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	schemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	schemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	schemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	schemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	schemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// maxResults is the maximum number of resources returned in a single list
+// response.
+const maxResults = 100
+
+// RegisterRoutes registers SCIM routes using given router, and inherits all
+// groups and middleware.
+func RegisterRoutes(r *macaron.Router) {
+	r.Group("", func() {
+		r.Get("/ServiceProviderConfig", serveServiceProviderConfig)
+
+		r.Group("/Users", func() {
+			r.Combo("").
+				Get(listUsers).
+				Post(createUser)
+			r.Combo("/:id", userByID).
+				Get(getUser).
+				Put(replaceUser).
+				Patch(patchUser).
+				Delete(deleteUser)
+		})
+
+		r.Group("/Groups", func() {
+			r.Combo("").
+				Get(listGroups).
+				Post(createGroup)
+			r.Combo("/:id", groupByID).
+				Get(getGroup).
+				Put(replaceGroup).
+				Patch(patchGroup).
+				Delete(deleteGroup)
+		}, organization)
+	}, authenticate)
+}
+
+// authenticate checks the bearer token of the request against the one
+// configured for SCIM provisioning.
+func authenticate(c *macaron.Context) {
+	if !conf.SCIM.Enabled {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	fields := strings.Fields(c.Req.Header.Get("Authorization"))
+	if len(fields) != 2 || !strings.EqualFold(fields[0], "bearer") ||
+		subtle.ConstantTimeCompare([]byte(fields[1]), []byte(conf.SCIM.Token)) != 1 {
+		c.Resp.Header().Set("WWW-Authenticate", `Bearer realm="SCIM"`)
+		responseError(c.Resp, http.StatusUnauthorized, "", "Invalid or missing bearer token")
+		return
+	}
+}
+
+// organization maps the organization whose teams are provisioned as groups.
+func organization(c *macaron.Context) {
+	if conf.SCIM.Organization == "" {
+		responseError(c.Resp, http.StatusNotFound, "", "Groups are not configured")
+		return
+	}
+
+	org, err := database.GetOrgByName(conf.SCIM.Organization)
+	if err != nil {
+		internalServerError(c.Resp)
+		log.Error("Failed to get organization [name: %s]: %v", conf.SCIM.Organization, err)
+		return
+	}
+	c.Map(org)
+}
+
+type meta struct {
+	ResourceType string `json:"resourceType"`
+	Created      string `json:"created,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Location     string `json:"location"`
+}
+
+func location(endpoint string, id int64) string {
+	return conf.Server.ExternalURL + "scim/v2/" + endpoint + "/" + strconv.FormatInt(id, 10)
+}
+
+type listResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []any    `json:"Resources"`
+}
+
+// pagination returns the values of the "startIndex" (1-based) and "count"
+// query parameters, with the count being capped by maxResults.
+func pagination(c *macaron.Context) (startIndex, count int) {
+	startIndex = c.QueryInt("startIndex")
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	count = maxResults
+	if c.Query("count") != "" {
+		count = c.QueryInt("count")
+	}
+	if count < 0 {
+		count = 0
+	} else if count > maxResults {
+		count = maxResults
+	}
+	return startIndex, count
+}
+
+// paginate returns the resources selected by the "startIndex" and "count" query
+// parameters in a list response.
+func paginate(c *macaron.Context, resources []any) *listResponse {
+	startIndex, count := pagination(c)
+	page := make([]any, 0, count)
+	for i := startIndex - 1; i < len(resources) && len(page) < count; i++ {
+		page = append(page, resources[i])
+	}
+	return &listResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: len(resources),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	}
+}
+
+// patchRequest is the body of a PATCH request.
+type patchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []patchOperation `json:"Operations"`
+}
+
+type patchOperation struct {
+	// One of "add", "remove" and "replace", case-insensitive.
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// decodeBody decodes the JSON request body into v. It responds with an error
+// and returns false if the body is invalid.
+func decodeBody(c *macaron.Context, v any) bool {
+	defer func() { _ = c.Req.Request.Body.Close() }()
+	err := json.NewDecoder(c.Req.Request.Body).Decode(v)
+	if err != nil {
+		responseError(c.Resp, http.StatusBadRequest, "invalidSyntax", "Invalid request body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func serveServiceProviderConfig(c *macaron.Context) {
+	type supported struct {
+		Supported bool `json:"supported"`
+	}
+	type filter struct {
+		Supported  bool `json:"supported"`
+		MaxResults int  `json:"maxResults"`
+	}
+	type bulk struct {
+		Supported      bool `json:"supported"`
+		MaxOperations  int  `json:"maxOperations"`
+		MaxPayloadSize int  `json:"maxPayloadSize"`
+	}
+	type authenticationScheme struct {
+		Type        string `json:"type"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	responseJSON(c.Resp, http.StatusOK, map[string]any{
+		"schemas":        []string{schemaServiceProviderConfig},
+		"patch":          supported{Supported: true},
+		"bulk":           bulk{},
+		"filter":         filter{Supported: true, MaxResults: maxResults},
+		"changePassword": supported{Supported: true},
+		"sort":           supported{},
+		"etag":           supported{},
+		"authenticationSchemes": []authenticationScheme{
+			{
+				Type:        "oauthbearertoken",
+				Name:        "OAuth Bearer Token",
+				Description: "Authentication with the token configured in the [scim] section",
+			},
+		},
+	})
+}
+
+func responseJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		log.Error("Failed to encode JSON: %v", err)
+		return
+	}
+}
+
+// responseError responds with a SCIM error, the "scimType" is omitted when
+// empty.
+func responseError(w http.ResponseWriter, status int, scimType, detail string) {
+	type scimError struct {
+		Schemas  []string `json:"schemas"`
+		Status   string   `json:"status"`
+		SCIMType string   `json:"scimType,omitempty"`
+		Detail   string   `json:"detail"`
+	}
+	responseJSON(w, status, scimError{
+		Schemas:  []string{schemaError},
+		Status:   strconv.Itoa(status),
+		SCIMType: scimType,
+		Detail:   detail,
+	})
+}
+
+func internalServerError(w http.ResponseWriter) {
+	responseError(w, http.StatusInternalServerError, "", "Internal server error")
+}
@@ -0,0 +1,78 @@
+package scim
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+
+	"gogs.io/gogs/internal/conf"
+)
+
+func TestAuthenticate(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          conf.SCIMOpts
+		header        http.Header
+		expStatusCode int
+		expBody       string
+	}{
+		{
+			name:          "disabled",
+			opts:          conf.SCIMOpts{Token: "secret"},
+			header:        http.Header{"Authorization": []string{"Bearer secret"}},
+			expStatusCode: http.StatusNotFound,
+		},
+		{
+			name:          "no authorization",
+			opts:          conf.SCIMOpts{Enabled: true, Token: "secret"},
+			expStatusCode: http.StatusUnauthorized,
+			expBody:       `{"schemas":["urn:ietf:params:scim:api:messages:2.0:Error"],"status":"401","detail":"Invalid or missing bearer token"}` + "\n",
+		},
+		{
+			name:          "wrong token",
+			opts:          conf.SCIMOpts{Enabled: true, Token: "secret"},
+			header:        http.Header{"Authorization": []string{"Bearer guess"}},
+			expStatusCode: http.StatusUnauthorized,
+			expBody:       `{"schemas":["urn:ietf:params:scim:api:messages:2.0:Error"],"status":"401","detail":"Invalid or missing bearer token"}` + "\n",
+		},
+		{
+			name:          "valid token",
+			opts:          conf.SCIMOpts{Enabled: true, Token: "secret"},
+			header:        http.Header{"Authorization": []string{"bearer secret"}},
+			expStatusCode: http.StatusOK,
+			expBody:       "authenticated",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conf.SetMockSCIM(t, test.opts)
+
+			m := macaron.New()
+			m.Use(macaron.Renderer())
+			m.Get("/", authenticate, func() string {
+				return "authenticated"
+			})
+
+			r, err := http.NewRequest("GET", "/", nil)
+			require.NoError(t, err)
+			r.Header = test.header
+
+			rr := httptest.NewRecorder()
+			m.ServeHTTP(rr, r)
+
+			resp := rr.Result()
+			assert.Equal(t, test.expStatusCode, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			if test.expBody != "" {
+				assert.Equal(t, test.expBody, string(body))
+			}
+		})
+	}
+}
@@ -0,0 +1,501 @@
+package scim
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"gopkg.in/macaron.v1"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/database"
+	"gogs.io/gogs/internal/strx"
+)
+
+type userName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type userEmail struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// userResource is the SCIM representation of a user. The "active" attribute is
+// the inverse of database.User.ProhibitLogin.
+type userResource struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id,omitempty"`
+	UserName    string      `json:"userName"`
+	Name        *userName   `json:"name,omitempty"`
+	DisplayName string      `json:"displayName,omitempty"`
+	Emails      []userEmail `json:"emails,omitempty"`
+	Active      *bool       `json:"active,omitempty"`
+	// Password is write-only and never returned.
+	Password string `json:"password,omitempty"`
+	Meta     *meta  `json:"meta,omitempty"`
+}
+
+// fullName returns the full name of the user, preferring the structured name
+// over the display name.
+func (r *userResource) fullName() string {
+	if r.Name != nil {
+		if r.Name.Formatted != "" {
+			return r.Name.Formatted
+		}
+		if name := strings.TrimSpace(r.Name.GivenName + " " + r.Name.FamilyName); name != "" {
+			return name
+		}
+	}
+	return r.DisplayName
+}
+
+// primaryEmail returns the email marked as primary, or the first email if none
+// is marked.
+func (r *userResource) primaryEmail() string {
+	if len(r.Emails) == 0 {
+		return ""
+	}
+	email := r.Emails[0].Value
+	for _, e := range r.Emails {
+		if e.Primary {
+			email = e.Value
+			break
+		}
+	}
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func toUserResource(u *database.User) *userResource {
+	active := !u.ProhibitLogin
+	r := &userResource{
+		Schemas:     []string{schemaUser},
+		ID:          strconv.FormatInt(u.ID, 10),
+		UserName:    u.Name,
+		DisplayName: u.DisplayName(),
+		Emails: []userEmail{
+			{Value: u.Email, Type: "work", Primary: true},
+		},
+		Active: &active,
+		Meta: &meta{
+			ResourceType: "User",
+			Created:      u.Created.UTC().Format(time.RFC3339),
+			LastModified: u.Updated.UTC().Format(time.RFC3339),
+			Location:     location("Users", u.ID),
+		},
+	}
+	if u.FullName != "" {
+		r.Name = &userName{Formatted: u.FullName}
+	}
+	return r
+}
+
+// unmarshalBool decodes a boolean that some identity providers send as a
+// string, e.g. "False".
+func unmarshalBool(raw json.RawMessage) (bool, error) {
+	var b bool
+	if json.Unmarshal(raw, &b) == nil {
+		return b, nil
+	}
+
+	var s string
+	err := json.Unmarshal(raw, &s)
+	if err != nil {
+		return false, errors.Newf("invalid boolean %s", raw)
+	}
+	return strconv.ParseBool(s)
+}
+
+// setUserAttribute sets the attribute at the path to the raw JSON value.
+// Attributes that are not stored are ignored.
+func setUserAttribute(r *userResource, path string, raw json.RawMessage) error {
+	path = strings.TrimPrefix(strings.ToLower(path), strings.ToLower(schemaUser)+":")
+
+	unmarshalString := func(v *string) error {
+		err := json.Unmarshal(raw, v)
+		if err != nil {
+			return errors.Newf("invalid string value for %q", path)
+		}
+		return nil
+	}
+
+	switch {
+	case path == "username":
+		return unmarshalString(&r.UserName)
+
+	case path == "displayname":
+		var name string
+		if err := unmarshalString(&name); err != nil {
+			return err
+		}
+		r.DisplayName = name
+		r.Name = &userName{Formatted: name}
+
+	case path == "name":
+		var name userName
+		err := json.Unmarshal(raw, &name)
+		if err != nil {
+			return errors.Newf("invalid value for %q", path)
+		}
+		if name.Formatted == "" {
+			name.Formatted = strings.TrimSpace(name.GivenName + " " + name.FamilyName)
+		}
+		r.Name = &name
+		r.DisplayName = name.Formatted
+
+	case strings.HasPrefix(path, "name."):
+		var value string
+		if err := unmarshalString(&value); err != nil {
+			return err
+		}
+		if r.Name == nil {
+			r.Name = &userName{}
+		}
+		switch path {
+		case "name.formatted":
+			r.Name.Formatted = value
+		case "name.givenname":
+			r.Name.GivenName = value
+			r.Name.Formatted = strings.TrimSpace(r.Name.GivenName + " " + r.Name.FamilyName)
+		case "name.familyname":
+			r.Name.FamilyName = value
+			r.Name.Formatted = strings.TrimSpace(r.Name.GivenName + " " + r.Name.FamilyName)
+		default:
+			return nil
+		}
+		r.DisplayName = r.Name.Formatted
+
+	case path == "active":
+		active, err := unmarshalBool(raw)
+		if err != nil {
+			return err
+		}
+		r.Active = &active
+
+	case path == "password":
+		return unmarshalString(&r.Password)
+
+	case path == "emails":
+		var emails []userEmail
+		err := json.Unmarshal(raw, &emails)
+		if err != nil {
+			return errors.Newf("invalid value for %q", path)
+		}
+		r.Emails = emails
+
+	case strings.HasPrefix(path, "emails[") || path == "emails.value":
+		// Only the primary email is stored, thus any single email value replaces it.
+		var email string
+		if err := unmarshalString(&email); err != nil {
+			return err
+		}
+		r.Emails = []userEmail{{Value: email, Primary: true}}
+	}
+	return nil
+}
+
+// removeUserAttribute removes the attribute at the path. Attributes that are
+// not stored are ignored.
+func removeUserAttribute(r *userResource, path string) error {
+	path = strings.TrimPrefix(strings.ToLower(path), strings.ToLower(schemaUser)+":")
+	switch {
+	case path == "displayname" || path == "name" || strings.HasPrefix(path, "name."):
+		r.DisplayName = ""
+		r.Name = nil
+	case path == "username" || path == "active" || path == "password" || strings.HasPrefix(path, "emails"):
+		return errors.Newf("attribute %q cannot be removed", path)
+	}
+	return nil
+}
+
+// applyUserPatch applies PATCH operations to the user resource.
+func applyUserPatch(r *userResource, ops []patchOperation) error {
+	for _, op := range ops {
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+			if op.Path != "" {
+				if err := setUserAttribute(r, op.Path, op.Value); err != nil {
+					return err
+				}
+				continue
+			}
+
+			var values map[string]json.RawMessage
+			err := json.Unmarshal(op.Value, &values)
+			if err != nil {
+				return errors.New("value must be an object when path is not specified")
+			}
+			for path, value := range values {
+				if err = setUserAttribute(r, path, value); err != nil {
+					return err
+				}
+			}
+
+		case "remove":
+			if op.Path == "" {
+				return errors.New("path is required for remove operation")
+			}
+			if err := removeUserAttribute(r, op.Path); err != nil {
+				return err
+			}
+
+		default:
+			return errors.Newf("unsupported operation %q", op.Op)
+		}
+	}
+	return nil
+}
+
+// userFields maps filterable attributes of users to the fields in the database.
+var userFields = map[string]database.UserField{
+	"id":             database.UserFieldID,
+	"username":       database.UserFieldUsername,
+	"displayname":    database.UserFieldDisplayName,
+	"active":         database.UserFieldActive,
+	"name.formatted": database.UserFieldFullName,
+	"emails":         database.UserFieldEmail,
+	"emails.value":   database.UserFieldEmail,
+}
+
+// userComparisons translates the filter to comparisons of users in the
+// database.
+func userComparisons(f filter) ([]database.UserComparison, error) {
+	cmps := make([]database.UserComparison, 0, len(f))
+	for _, cmp := range f {
+		field, ok := userFields[cmp.attr]
+		if !ok {
+			return nil, errors.Newf("unsupported attribute %q", cmp.attr)
+		}
+		if (field == database.UserFieldID || field == database.UserFieldActive) &&
+			cmp.op != "eq" && cmp.op != "ne" && cmp.op != "pr" {
+			return nil, errors.Newf("unsupported operator %q on attribute %q", cmp.op, cmp.attr)
+		}
+		cmps = append(cmps, database.UserComparison{Field: field, Op: cmp.op, Value: cmp.value})
+	}
+	return cmps, nil
+}
+
+func listUsers(c *macaron.Context) {
+	f, err := parseFilter(c.Query("filter"))
+	if err != nil {
+		responseError(c.Resp, http.StatusBadRequest, "invalidFilter", err.Error())
+		return
+	}
+	cmps, err := userComparisons(f)
+	if err != nil {
+		responseError(c.Resp, http.StatusBadRequest, "invalidFilter", err.Error())
+		return
+	}
+
+	startIndex, count := pagination(c)
+	users, total, err := database.Handle.Users().Search(
+		c.Req.Context(),
+		database.SearchUsersOptions{
+			Comparisons: cmps,
+			Offset:      startIndex - 1,
+			Limit:       count,
+		},
+	)
+	if err != nil {
+		internalServerError(c.Resp)
+		log.Error("Failed to search users [filter: %s]: %v", c.Query("filter"), err)
+		return
+	}
+
+	resources := make([]any, 0, len(users))
+	for _, user := range users {
+		resources = append(resources, toUserResource(user))
+	}
+	responseJSON(c.Resp, http.StatusOK, &listResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: int(total),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+// userByID maps the individual user with the ":id" URL parameter.
+func userByID(c *macaron.Context) {
+	user, err := database.Handle.Users().GetByID(c.Req.Context(), c.ParamsInt64(":id"))
+	if err != nil {
+		if database.IsErrUserNotExist(err) {
+			responseError(c.Resp, http.StatusNotFound, "", "User not found")
+		} else {
+			internalServerError(c.Resp)
+			log.Error("Failed to get user [id: %d]: %v", c.ParamsInt64(":id"), err)
+		}
+		return
+	} else if user.IsOrganization() {
+		responseError(c.Resp, http.StatusNotFound, "", "User not found")
+		return
+	}
+	c.Map(user)
+}
+
+func getUser(c *macaron.Context, user *database.User) {
+	responseJSON(c.Resp, http.StatusOK, toUserResource(user))
+}
+
+// responseUserError responds with the error of creating or updating a user.
+func responseUserError(c *macaron.Context, err error, action string) {
+	switch {
+	case database.IsErrUserAlreadyExist(err), database.IsErrEmailAlreadyUsed(err):
+		responseError(c.Resp, http.StatusConflict, "uniqueness", err.Error())
+	case database.IsErrNameNotAllowed(err):
+		responseError(c.Resp, http.StatusBadRequest, "invalidValue", err.Error())
+	default:
+		internalServerError(c.Resp)
+		log.Error("Failed to %s: %v", action, err)
+	}
+}
+
+func createUser(c *macaron.Context) {
+	var r userResource
+	if !decodeBody(c, &r) {
+		return
+	}
+
+	email := r.primaryEmail()
+	if r.UserName == "" {
+		responseError(c.Resp, http.StatusBadRequest, "invalidValue", "Attribute userName is required")
+		return
+	} else if email == "" {
+		responseError(c.Resp, http.StatusBadRequest, "invalidValue", "At least one email is required")
+		return
+	}
+
+	// Users provisioned without a password have to reset it before signing in
+	// with a local password.
+	password := r.Password
+	if password == "" {
+		var err error
+		password, err = strx.RandomChars(32)
+		if err != nil {
+			internalServerError(c.Resp)
+			log.Error("Failed to generate random password: %v", err)
+			return
+		}
+	}
+
+	user, err := database.Handle.Users().Create(
+		c.Req.Context(),
+		r.UserName,
+		email,
+		database.CreateUserOptions{
+			FullName:  r.fullName(),
+			Password:  password,
+			Activated: true,
+		},
+	)
+	if err != nil {
+		responseUserError(c, err, "create user")
+		return
+	}
+	log.Trace("[SCIM] User created: %s", user.Name)
+
+	if r.Active != nil && !*r.Active {
+		prohibitLogin := true
+		err = database.Handle.Users().Update(c.Req.Context(), user.ID, database.UpdateUserOptions{ProhibitLogin: &prohibitLogin})
+		if err != nil {
+			internalServerError(c.Resp)
+			log.Error("Failed to deactivate user [id: %d]: %v", user.ID, err)
+			return
+		}
+		user.ProhibitLogin = true
+	}
+
+	c.Resp.Header().Set("Location", location("Users", user.ID))
+	responseJSON(c.Resp, http.StatusCreated, toUserResource(user))
+}
+
+// updateUser updates the user to match the resource and responds with the
+// updated resource.
+func updateUser(c *macaron.Context, user *database.User, r *userResource) {
+	if r.UserName == "" {
+		responseError(c.Resp, http.StatusBadRequest, "invalidValue", "Attribute userName is required")
+		return
+	}
+
+	if r.UserName != user.Name {
+		err := database.Handle.Users().ChangeUsername(c.Req.Context(), user.ID, r.UserName)
+		if err != nil {
+			responseUserError(c, err, "change username")
+			return
+		}
+		log.Trace("[SCIM] Username changed: %s -> %s", user.Name, r.UserName)
+	}
+
+	var opts database.UpdateUserOptions
+	if fullName := r.fullName(); fullName != user.FullName {
+		opts.FullName = &fullName
+	}
+	if email := r.primaryEmail(); email != "" && !strings.EqualFold(email, user.Email) {
+		opts.Email = &email
+	}
+	if r.Active != nil && *r.Active == user.ProhibitLogin {
+		prohibitLogin := !*r.Active
+		opts.ProhibitLogin = &prohibitLogin
+	}
+	if r.Password != "" {
+		opts.Password = &r.Password
+	}
+	err := database.Handle.Users().Update(c.Req.Context(), user.ID, opts)
+	if err != nil {
+		responseUserError(c, err, "update user")
+		return
+	}
+
+	updated, err := database.Handle.Users().GetByID(c.Req.Context(), user.ID)
+	if err != nil {
+		internalServerError(c.Resp)
+		log.Error("Failed to get user [id: %d]: %v", user.ID, err)
+		return
+	}
+	responseJSON(c.Resp, http.StatusOK, toUserResource(updated))
+}
+
+func replaceUser(c *macaron.Context, user *database.User) {
+	var r userResource
+	if !decodeBody(c, &r) {
+		return
+	}
+	updateUser(c, user, &r)
+}
+
+func patchUser(c *macaron.Context, user *database.User) {
+	var req patchRequest
+	if !decodeBody(c, &req) {
+		return
+	}
+
+	r := toUserResource(user)
+	err := applyUserPatch(r, req.Operations)
+	if err != nil {
+		responseError(c.Resp, http.StatusBadRequest, "invalidValue", err.Error())
+		return
+	}
+	updateUser(c, user, r)
+}
+
+func deleteUser(c *macaron.Context, user *database.User) {
+	err := database.Handle.Users().DeleteByID(c.Req.Context(), user.ID, false)
+	if err != nil {
+		if database.IsErrUserOwnRepos(err) || database.IsErrUserHasOrgs(err) {
+			responseError(c.Resp, http.StatusConflict, "", err.Error()+", deactivate the user instead")
+		} else {
+			internalServerError(c.Resp)
+			log.Error("Failed to delete user [id: %d]: %v", user.ID, err)
+		}
+		return
+	}
+	log.Trace("[SCIM] User deleted: %s", user.Name)
+
+	c.Status(http.StatusNoContent)
+}
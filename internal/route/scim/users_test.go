@@ -0,0 +1,173 @@
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/database"
+)
+
+func TestApplyUserPatch(t *testing.T) {
+	newUser := func() *userResource {
+		active := true
+		return &userResource{
+			UserName:    "alice",
+			Name:        &userName{Formatted: "Alice Smith"},
+			DisplayName: "Alice Smith",
+			Emails:      []userEmail{{Value: "alice@example.com", Primary: true}},
+			Active:      &active,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		ops     string
+		assert  func(t *testing.T, r *userResource)
+		wantErr string
+	}{
+		{
+			name: "deactivate with string value",
+			ops:  `[{"op":"Replace","path":"active","value":"False"}]`,
+			assert: func(t *testing.T, r *userResource) {
+				require.NotNil(t, r.Active)
+				assert.False(t, *r.Active)
+			},
+		},
+		{
+			name: "replace without path",
+			ops:  `[{"op":"replace","value":{"active":false,"userName":"alice2","name.givenName":"Ally"}}]`,
+			assert: func(t *testing.T, r *userResource) {
+				assert.False(t, *r.Active)
+				assert.Equal(t, "alice2", r.UserName)
+				assert.Equal(t, "Ally", r.fullName())
+			},
+		},
+		{
+			name: "replace name",
+			ops:  `[{"op":"replace","path":"name","value":{"givenName":"Bob","familyName":"Jones"}}]`,
+			assert: func(t *testing.T, r *userResource) {
+				assert.Equal(t, "Bob Jones", r.fullName())
+				assert.Equal(t, "Bob Jones", r.DisplayName)
+			},
+		},
+		{
+			name: "replace display name",
+			ops:  `[{"op":"replace","path":"displayName","value":"Ally"}]`,
+			assert: func(t *testing.T, r *userResource) {
+				assert.Equal(t, "Ally", r.fullName())
+			},
+		},
+		{
+			name: "replace email with value filter",
+			ops:  `[{"op":"replace","path":"emails[type eq \"work\"].value","value":"Alice@Corp.example.com"}]`,
+			assert: func(t *testing.T, r *userResource) {
+				assert.Equal(t, "alice@corp.example.com", r.primaryEmail())
+			},
+		},
+		{
+			name: "remove name",
+			ops:  `[{"op":"remove","path":"name.formatted"}]`,
+			assert: func(t *testing.T, r *userResource) {
+				assert.Empty(t, r.fullName())
+			},
+		},
+		{
+			name: "ignore unknown attributes",
+			ops:  `[{"op":"add","path":"title","value":"Engineer"}]`,
+			assert: func(t *testing.T, r *userResource) {
+				assert.Equal(t, newUser(), r)
+			},
+		},
+		{
+			name:    "remove username",
+			ops:     `[{"op":"remove","path":"userName"}]`,
+			wantErr: `attribute "username" cannot be removed`,
+		},
+		{
+			name:    "remove without path",
+			ops:     `[{"op":"remove"}]`,
+			wantErr: "path is required for remove operation",
+		},
+		{
+			name:    "unsupported operation",
+			ops:     `[{"op":"move","path":"userName"}]`,
+			wantErr: `unsupported operation "move"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var ops []patchOperation
+			require.NoError(t, json.Unmarshal([]byte(test.ops), &ops))
+
+			r := newUser()
+			err := applyUserPatch(r, ops)
+			if test.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, test.wantErr, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			test.assert(t, r)
+		})
+	}
+}
+
+func TestUserComparisons(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  string
+		want    []database.UserComparison
+		wantErr string
+	}{
+		{
+			name:   "empty",
+			filter: "",
+			want:   []database.UserComparison{},
+		},
+		{
+			name:   "joined",
+			filter: `userName sw "a" and emails.value co "@example.com" and active eq true`,
+			want: []database.UserComparison{
+				{Field: database.UserFieldUsername, Op: "sw", Value: "a"},
+				{Field: database.UserFieldEmail, Op: "co", Value: "@example.com"},
+				{Field: database.UserFieldActive, Op: "eq", Value: "true"},
+			},
+		},
+		{
+			name:   "names",
+			filter: `displayName eq "Alice" and name.formatted pr`,
+			want: []database.UserComparison{
+				{Field: database.UserFieldDisplayName, Op: "eq", Value: "Alice"},
+				{Field: database.UserFieldFullName, Op: "pr"},
+			},
+		},
+		{
+			name:    "unsupported attribute",
+			filter:  `name.givenName eq "Alice"`,
+			wantErr: `unsupported attribute "name.givenname"`,
+		},
+		{
+			name:    "unsupported operator",
+			filter:  `id sw "1"`,
+			wantErr: `unsupported operator "sw" on attribute "id"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := parseFilter(test.filter)
+			require.NoError(t, err)
+
+			got, err := userComparisons(f)
+			if test.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, test.wantErr, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
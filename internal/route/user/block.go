@@ -0,0 +1,94 @@
+package user
+
+import (
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/database"
+)
+
+const (
+	tmplUserSettingsBlockedUsers = "user/settings/blocked_users"
+)
+
+func SettingsBlockedUsers(c *context.Context) {
+	c.Title("settings.blocked_users")
+	c.PageIs("SettingsBlockedUsers")
+
+	ListBlockedUsers(c, c.User)
+	if c.Written() {
+		return
+	}
+
+	c.Success(tmplUserSettingsBlockedUsers)
+}
+
+func SettingsBlockedUsersPost(c *context.Context) {
+	BlockUser(c, c.User)
+	if c.Written() {
+		return
+	}
+
+	c.Redirect(conf.Server.Subpath + "/user/settings/blocked_users")
+}
+
+func SettingsUnblockUser(c *context.Context) {
+	if err := database.Handle.UserBlocks().Unblock(c.Req.Context(), c.User.ID, c.QueryInt64("id")); err != nil {
+		c.Error(err, "unblock user")
+		return
+	}
+
+	c.Flash.Success(c.Tr("settings.unblock_success"))
+	c.JSONSuccess(map[string]any{
+		"redirect": conf.Server.Subpath + "/user/settings/blocked_users",
+	})
+}
+
+// ListBlockedUsers sets the list of users blocked by the blocker, which is
+// either the signed in user or an organization, for rendering.
+func ListBlockedUsers(c *context.Context, blocker *database.User) {
+	users, err := database.Handle.UserBlocks().List(c.Req.Context(), blocker.ID)
+	if err != nil {
+		c.Error(err, "list blocked users")
+		return
+	}
+	c.Data["BlockedUsers"] = users
+}
+
+// BlockUser blocks the user named by the "blockee" form field on behalf of the
+// blocker, which is either the signed in user or an organization. The result
+// is reported by a flash message.
+func BlockUser(c *context.Context, blocker *database.User) {
+	name := c.Query("blockee")
+	if name == "" {
+		return
+	}
+
+	u, err := database.Handle.Users().GetByUsername(c.Req.Context(), name)
+	if err != nil {
+		if database.IsErrUserNotExist(err) {
+			c.Flash.Error(c.Tr("form.user_not_exist"))
+		} else {
+			c.Error(err, "get user by name")
+		}
+		return
+	}
+
+	switch {
+	case u.IsOrganization():
+		c.Flash.Error(c.Tr("settings.block_org_not_allowed"))
+		return
+	case u.ID == blocker.ID || u.ID == c.User.ID:
+		c.Flash.Error(c.Tr("settings.block_self_not_allowed"))
+		return
+	case blocker.IsOrganization() && database.IsOrganizationMember(blocker.ID, u.ID):
+		c.Flash.Error(c.Tr("settings.block_member_not_allowed"))
+		return
+	}
+
+	if err = database.Handle.UserBlocks().Block(c.Req.Context(), blocker.ID, u.ID); err != nil {
+		c.Error(err, "block user")
+		return
+	}
+
+	c.Flash.Success(c.Tr("settings.block_success", u.Name))
+}
@@ -0,0 +1,145 @@
+package user
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+
+	"github.com/microcosm-cc/bluemonday"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/database"
+	"gogs.io/gogs/internal/feed"
+	"gogs.io/gogs/internal/template"
+	"gogs.io/gogs/internal/tool"
+)
+
+// actionFeedItem converts the action to an item of feeds with absolute links.
+func actionFeedItem(c *context.Context, act *database.Action) *feed.Item {
+	repoLink := conf.Server.ExternalURL + act.GetRepoPath()
+	repoPath := html.EscapeString(act.GetRepoPath())
+	ref := html.EscapeString(act.GetBranch())
+	refLink := template.EscapePound(act.GetBranch())
+	refHref := html.EscapeString(refLink)
+
+	link := repoLink
+	var summary, content string
+	switch act.OpType {
+	case database.ActionCreateRepo:
+		summary = c.Tr("action.create_repo", repoLink, repoPath)
+	case database.ActionRenameRepo:
+		summary = c.Tr("action.rename_repo", html.EscapeString(act.GetContent()), repoLink, repoPath)
+	case database.ActionTransferRepo:
+		summary = c.Tr("action.transfer_repo", html.EscapeString(act.GetContent()), repoLink, repoPath)
+	case database.ActionForkRepo:
+		summary = c.Tr("action.fork_repo", repoLink, repoPath)
+
+	case database.ActionCommitRepo, database.ActionMirrorSyncPush:
+		if act.OpType == database.ActionCommitRepo {
+			summary = c.Tr("action.commit_repo", repoLink, refHref, ref, repoPath)
+		} else {
+			summary = c.Tr("action.mirror_sync_push", repoLink, refHref, ref, repoPath)
+		}
+		link = repoLink + "/src/" + refLink
+
+		push := template.ActionContent2Commits(act)
+		if push.Len > 1 && push.CompareURL != "" {
+			link = conf.Server.ExternalURL + push.CompareURL
+		}
+		content = "<ul>"
+		for _, commit := range push.Commits {
+			content += fmt.Sprintf(`<li><a href="%s/commit/%s">%s</a> %s</li>`,
+				repoLink, commit.Sha1, tool.ShortSHA1(commit.Sha1), html.EscapeString(commit.Message))
+		}
+		content += "</ul>"
+
+	case database.ActionCreateIssue, database.ActionCloseIssue, database.ActionReopenIssue, database.ActionCommentIssue,
+		database.ActionCreatePullRequest, database.ActionClosePullRequest, database.ActionReopenPullRequest, database.ActionMergePullRequest:
+		infos := act.GetIssueInfos()
+		index := html.EscapeString(infos[0])
+		switch act.OpType {
+		case database.ActionCreateIssue:
+			summary = c.Tr("action.create_issue", repoLink, index, repoPath)
+		case database.ActionCloseIssue:
+			summary = c.Tr("action.close_issue", repoLink, index, repoPath)
+		case database.ActionReopenIssue:
+			summary = c.Tr("action.reopen_issue", repoLink, index, repoPath)
+		case database.ActionCommentIssue:
+			summary = c.Tr("action.comment_issue", repoLink, index, repoPath)
+		case database.ActionCreatePullRequest:
+			summary = c.Tr("action.create_pull_request", repoLink, index, repoPath)
+		case database.ActionClosePullRequest:
+			summary = c.Tr("action.close_pull_request", repoLink, index, repoPath)
+		case database.ActionReopenPullRequest:
+			summary = c.Tr("action.reopen_pull_request", repoLink, index, repoPath)
+		case database.ActionMergePullRequest:
+			summary = c.Tr("action.merge_pull_request", repoLink, index, repoPath)
+		}
+
+		switch act.OpType {
+		case database.ActionCreatePullRequest, database.ActionClosePullRequest, database.ActionReopenPullRequest, database.ActionMergePullRequest:
+			link = repoLink + "/pulls/" + index
+		default:
+			link = repoLink + "/issues/" + index
+		}
+		if len(infos) > 1 {
+			content = "<p>" + html.EscapeString(infos[1]) + "</p>"
+		}
+
+	case database.ActionCreateBranch:
+		summary = c.Tr("action.create_branch", repoLink, refHref, ref, repoPath)
+		link = repoLink + "/src/" + refLink
+	case database.ActionDeleteBranch:
+		summary = c.Tr("action.delete_branch", repoLink, ref, repoPath)
+	case database.ActionPushTag:
+		summary = c.Tr("action.push_tag", repoLink, refHref, repoPath)
+		link = repoLink + "/src/" + refLink
+	case database.ActionDeleteTag:
+		summary = c.Tr("action.delete_tag", repoLink, ref, repoPath)
+	case database.ActionMirrorSyncCreate:
+		summary = c.Tr("action.mirror_sync_create", repoLink, refHref, repoPath)
+		link = repoLink + "/src/" + refLink
+	case database.ActionMirrorSyncDelete:
+		summary = c.Tr("action.mirror_sync_delete", repoLink, ref, repoPath)
+	}
+
+	actor := html.EscapeString(act.ActUserName)
+	summary = fmt.Sprintf(`<a href="%s%s">%s</a> %s`, conf.Server.ExternalURL, actor, actor, summary)
+	return &feed.Item{
+		ID:      link + "#action-" + strconv.FormatInt(act.ID, 10),
+		Title:   html.UnescapeString(bluemonday.StrictPolicy().Sanitize(summary)),
+		Link:    link,
+		Content: "<p>" + summary + "</p>" + content,
+		Author:  act.ActUserName,
+		Updated: act.Created,
+	}
+}
+
+// Feed renders the Atom or RSS feed of activity of the user or organization.
+// Private activity is only included for users who have access, e.g. the user
+// authenticated by the feed token.
+func Feed(c *context.Context, puser *context.ParamsUser) {
+	var actions []*database.Action
+	var err error
+	if puser.IsOrganization() {
+		actions, err = database.Handle.Actions().ListByOrganization(c.Req.Context(), puser.ID, c.UserID(), 0)
+	} else {
+		actions, err = database.Handle.Actions().ListByUser(c.Req.Context(), puser.ID, c.UserID(), 0, true)
+	}
+	if err != nil {
+		c.Error(err, "list actions")
+		return
+	}
+
+	items := make([]*feed.Item, len(actions))
+	for i, act := range actions {
+		items[i] = actionFeedItem(c, act)
+	}
+
+	c.Feed(&feed.Feed{
+		Title: c.Tr("user.feed_title", puser.DisplayName()),
+		Link:  conf.Server.ExternalURL + puser.Name,
+		Items: items,
+	}, feed.Format(c.Params(":format")))
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"slices"
+	"strings"
 
 	"github.com/unknwon/paginater"
 
@@ -210,6 +211,9 @@ func Issues(c *context.Context) {
 			string(database.FilterModeAssign),
 			string(database.FilterModeCreate),
 		}
+		if isPullList {
+			types = append(types, string(database.FilterModeReviewRequested))
+		}
 		if !slices.Contains(types, viewType) {
 			viewType = string(database.FilterModeYourRepos)
 		}
@@ -305,6 +309,32 @@ func Issues(c *context.Context) {
 	case database.FilterModeCreate:
 		// Get all issues created by this user.
 		issueOptions.PosterID = ctxUser.ID
+
+	case database.FilterModeReviewRequested:
+		// Get all pull requests requested to be reviewed by this user.
+		issueOptions.ReviewerID = ctxUser.ID
+	}
+
+	keyword := strings.TrimSpace(c.Query("q"))
+	if keyword != "" {
+		var searchRepoIDs []int64
+		if repoID > 0 {
+			searchRepoIDs = []int64{repoID}
+		} else if filterMode == database.FilterModeYourRepos {
+			searchRepoIDs = issueOptions.RepoIDs
+		} else {
+			// Only search repositories that have issues of the filter mode.
+			searchRepoIDs, err = database.IssueRepoIDs(issueOptions)
+			if err != nil {
+				c.Error(err, "get issue repository IDs")
+				return
+			}
+		}
+		issueOptions.IssueIDs, err = database.SearchIssueIDs(keyword, searchRepoIDs)
+		if err != nil {
+			c.Error(err, "search issue IDs")
+			return
+		}
 	}
 
 	issues, err := database.Issues(issueOptions)
@@ -340,6 +370,22 @@ func Issues(c *context.Context) {
 	}
 
 	issueStats := database.GetUserIssueStats(repoID, ctxUser.ID, userRepoIDs, filterMode, isPullList)
+	if keyword != "" {
+		// Only issues matched by the keyword are counted.
+		countOptions := *issueOptions
+		countOptions.IsClosed = false
+		issueStats.OpenCount, err = database.IssuesCount(&countOptions)
+		if err != nil {
+			c.Error(err, "count open issues")
+			return
+		}
+		countOptions.IsClosed = true
+		issueStats.ClosedCount, err = database.IssuesCount(&countOptions)
+		if err != nil {
+			c.Error(err, "count closed issues")
+			return
+		}
+	}
 
 	var total int
 	if !isShowClosed {
@@ -355,6 +401,7 @@ func Issues(c *context.Context) {
 	c.Data["ViewType"] = string(filterMode)
 	c.Data["SortType"] = sortType
 	c.Data["RepoID"] = repoID
+	c.Data["Keyword"] = keyword
 	c.Data["IsShowClosed"] = isShowClosed
 
 	if isShowClosed {
@@ -390,6 +437,7 @@ func showOrgProfile(c *context.Context) {
 
 	org := c.Org.Organization
 	c.Data["Title"] = org.FullName
+	c.Data["FeedURL"] = conf.Server.Subpath + "/" + org.Name
 
 	page := c.QueryInt("page")
 	if page <= 0 {
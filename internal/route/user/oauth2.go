@@ -0,0 +1,477 @@
+package user
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/database"
+	"gogs.io/gogs/internal/form"
+	"gogs.io/gogs/internal/strx"
+)
+
+const (
+	tmplUserSettingsOAuth2Applications = "user/settings/oauth2_applications"
+	tmplUserSettingsOAuth2Application  = "user/settings/oauth2_application"
+	tmplUserAuthOAuth2Authorize        = "user/auth/oauth2_authorize"
+)
+
+// MustEnableOAuth2 responds with 404 when the OAuth2 provider is disabled.
+func MustEnableOAuth2(c *context.Context) {
+	if !conf.OAuth2.Enabled {
+		c.NotFound()
+		return
+	}
+}
+
+func splitRedirectURIs(s string) []string {
+	return strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
+
+func renderOAuth2Applications(c *context.Context) bool {
+	apps, err := database.Handle.OAuth2().ListApplications(c.Req.Context(), c.User.ID)
+	if err != nil {
+		c.Errorf(err, "list OAuth2 applications")
+		return false
+	}
+	c.Data["Applications"] = apps
+
+	grants, err := database.Handle.OAuth2().ListGrants(c.Req.Context(), c.User.ID)
+	if err != nil {
+		c.Errorf(err, "list OAuth2 grants")
+		return false
+	}
+	c.Data["Grants"] = grants
+	return true
+}
+
+func SettingsOAuth2Applications(c *context.Context) {
+	c.Title("settings.oauth2_applications")
+	c.PageIs("SettingsOAuth2Applications")
+
+	if !renderOAuth2Applications(c) {
+		return
+	}
+	c.Success(tmplUserSettingsOAuth2Applications)
+}
+
+func SettingsOAuth2ApplicationsPost(c *context.Context, f form.OAuth2Application) {
+	c.Title("settings.oauth2_applications")
+	c.PageIs("SettingsOAuth2Applications")
+
+	if !renderOAuth2Applications(c) {
+		return
+	}
+
+	if c.HasError() {
+		c.HTML(http.StatusBadRequest, tmplUserSettingsOAuth2Applications)
+		return
+	}
+
+	app, err := database.Handle.OAuth2().CreateApplication(c.Req.Context(), c.User.ID,
+		database.CreateOAuth2ApplicationOptions{
+			Name:         f.Name,
+			RedirectURIs: splitRedirectURIs(f.RedirectURIs),
+		},
+	)
+	if err != nil {
+		if database.IsErrOAuth2RedirectURIInvalid(err) {
+			c.Data["HasError"] = true
+			c.FormErr("RedirectURIs")
+			c.RenderWithErr(c.Tr("form.invalid_redirect_uri", err.Error()), http.StatusUnprocessableEntity, tmplUserSettingsOAuth2Applications, &f)
+		} else {
+			c.Errorf(err, "create OAuth2 application")
+		}
+		return
+	}
+
+	c.Flash.Success(c.Tr("settings.oauth2_application_create_success"))
+	c.Flash.Info(app.ClientSecret)
+	c.RedirectSubpath(fmt.Sprintf("/user/settings/oauth2/%d", app.ID))
+}
+
+func DeleteOAuth2Application(c *context.Context) {
+	if err := database.Handle.OAuth2().DeleteApplication(c.Req.Context(), c.User.ID, c.QueryInt64("id")); err != nil {
+		c.Flash.Error("DeleteOAuth2Application: " + err.Error())
+	} else {
+		c.Flash.Success(c.Tr("settings.oauth2_application_deletion_success"))
+	}
+
+	c.JSONSuccess(map[string]any{
+		"redirect": conf.Server.Subpath + "/user/settings/oauth2",
+	})
+}
+
+func RevokeOAuth2Grant(c *context.Context) {
+	if err := database.Handle.OAuth2().RevokeGrant(c.Req.Context(), c.User.ID, c.QueryInt64("id")); err != nil {
+		c.Flash.Error("RevokeOAuth2Grant: " + err.Error())
+	} else {
+		c.Flash.Success(c.Tr("settings.oauth2_grant_revocation_success"))
+	}
+
+	c.JSONSuccess(map[string]any{
+		"redirect": conf.Server.Subpath + "/user/settings/oauth2",
+	})
+}
+
+func getOAuth2Application(c *context.Context) *database.OAuth2Application {
+	app, err := database.Handle.OAuth2().GetApplicationByID(c.Req.Context(), c.User.ID, c.ParamsInt64(":id"))
+	if err != nil {
+		c.NotFoundOrError(err, "get OAuth2 application by ID")
+		return nil
+	}
+	c.Data["Application"] = app
+	return app
+}
+
+func SettingsOAuth2Application(c *context.Context) {
+	c.Title("settings.oauth2_applications")
+	c.PageIs("SettingsOAuth2Applications")
+
+	app := getOAuth2Application(c)
+	if c.Written() {
+		return
+	}
+	c.Data["name"] = app.Name
+	c.Data["redirect_uris"] = strings.Join(app.RedirectURIList(), "\n")
+
+	c.Success(tmplUserSettingsOAuth2Application)
+}
+
+func SettingsOAuth2ApplicationPost(c *context.Context, f form.OAuth2Application) {
+	c.Title("settings.oauth2_applications")
+	c.PageIs("SettingsOAuth2Applications")
+
+	app := getOAuth2Application(c)
+	if c.Written() {
+		return
+	}
+
+	if c.HasError() {
+		c.HTML(http.StatusBadRequest, tmplUserSettingsOAuth2Application)
+		return
+	}
+
+	err := database.Handle.OAuth2().UpdateApplication(c.Req.Context(), c.User.ID, app.ID,
+		database.UpdateOAuth2ApplicationOptions{
+			Name:         f.Name,
+			RedirectURIs: splitRedirectURIs(f.RedirectURIs),
+		},
+	)
+	if err != nil {
+		if database.IsErrOAuth2RedirectURIInvalid(err) {
+			c.FormErr("RedirectURIs")
+			c.RenderWithErr(c.Tr("form.invalid_redirect_uri", err.Error()), http.StatusUnprocessableEntity, tmplUserSettingsOAuth2Application, &f)
+		} else {
+			c.Errorf(err, "update OAuth2 application")
+		}
+		return
+	}
+
+	c.Flash.Success(c.Tr("settings.oauth2_application_update_success"))
+	c.RedirectSubpath(fmt.Sprintf("/user/settings/oauth2/%d", app.ID))
+}
+
+func RegenerateOAuth2ClientSecret(c *context.Context) {
+	app := getOAuth2Application(c)
+	if c.Written() {
+		return
+	}
+
+	secret, err := database.Handle.OAuth2().RegenerateClientSecret(c.Req.Context(), c.User.ID, app.ID)
+	if err != nil {
+		c.Errorf(err, "regenerate client secret")
+		return
+	}
+
+	c.Flash.Success(c.Tr("settings.oauth2_client_secret_regenerate_success"))
+	c.Flash.Info(secret)
+	c.RedirectSubpath(fmt.Sprintf("/user/settings/oauth2/%d", app.ID))
+}
+
+// oauth2AuthorizeRequest is the parsed authorization request of the OAuth2
+// authorization code flow.
+type oauth2AuthorizeRequest struct {
+	app                 *database.OAuth2Application
+	redirectURI         string // The effective redirect URI
+	rawRedirectURI      string // The redirect URI given in the request, may be empty
+	state               string
+	codeChallenge       string
+	codeChallengeMethod string
+}
+
+// redirect redirects the user back to the application with given query
+// parameters and the state.
+func (r *oauth2AuthorizeRequest) redirect(c *context.Context, params url.Values) {
+	u, err := url.Parse(r.redirectURI)
+	if err != nil {
+		c.Errorf(err, "parse redirect URI")
+		return
+	}
+
+	q := u.Query()
+	for k := range params {
+		q.Set(k, params.Get(k))
+	}
+	if r.state != "" {
+		q.Set("state", r.state)
+	}
+	u.RawQuery = q.Encode()
+	c.Redirect(u.String())
+}
+
+// redirectError redirects the user back to the application with the error as
+// defined in https://datatracker.ietf.org/doc/html/rfc6749#section-4.1.2.1.
+func (r *oauth2AuthorizeRequest) redirectError(c *context.Context, code, description string) {
+	r.redirect(c, url.Values{
+		"error":             {code},
+		"error_description": {description},
+	})
+}
+
+// parseOAuth2AuthorizeRequest parses and validates the authorization request.
+// It renders the error page to the user when the client or the redirect URI is
+// invalid, and redirects the user back to the application with the error for
+// other invalid parameters. The request is only usable when c.Written() is
+// false.
+func parseOAuth2AuthorizeRequest(c *context.Context) *oauth2AuthorizeRequest {
+	renderError := func(msg string) {
+		c.Data["OAuth2Error"] = msg
+		c.HTML(http.StatusBadRequest, tmplUserAuthOAuth2Authorize)
+	}
+
+	app, err := database.Handle.OAuth2().GetApplicationByClientID(c.Req.Context(), c.Query("client_id"))
+	if err != nil {
+		if database.IsErrOAuth2ApplicationNotExist(err) {
+			renderError(c.Tr("auth.oauth2_invalid_client"))
+		} else {
+			c.Errorf(err, "get OAuth2 application by client ID")
+		}
+		return nil
+	}
+
+	r := &oauth2AuthorizeRequest{
+		app:                 app,
+		rawRedirectURI:      c.Query("redirect_uri"),
+		state:               c.Query("state"),
+		codeChallenge:       c.Query("code_challenge"),
+		codeChallengeMethod: c.Query("code_challenge_method"),
+	}
+	if r.rawRedirectURI == "" {
+		r.redirectURI = app.RedirectURIList()[0]
+	} else if app.HasRedirectURI(r.rawRedirectURI) {
+		r.redirectURI = r.rawRedirectURI
+	} else {
+		renderError(c.Tr("auth.oauth2_invalid_redirect_uri"))
+		return nil
+	}
+
+	if c.Query("response_type") != "code" {
+		r.redirectError(c, "unsupported_response_type", "Only the authorization code flow is supported")
+		return nil
+	}
+
+	switch r.codeChallengeMethod {
+	case "", database.OAuth2CodeChallengePlain, database.OAuth2CodeChallengeS256:
+	default:
+		r.redirectError(c, "invalid_request", "Code challenge method must be either plain or S256")
+		return nil
+	}
+	if r.codeChallengeMethod != "" && r.codeChallenge == "" {
+		r.redirectError(c, "invalid_request", "Code challenge is required")
+		return nil
+	}
+	return r
+}
+
+// grant records the authorization and redirects the user back to the
+// application with a new authorization code.
+func (r *oauth2AuthorizeRequest) grant(c *context.Context) {
+	code, err := database.Handle.OAuth2().CreateAuthorizationCode(c.Req.Context(), c.User.ID, r.app.ID,
+		database.CreateAuthorizationCodeOptions{
+			RedirectURI:         r.rawRedirectURI,
+			CodeChallenge:       r.codeChallenge,
+			CodeChallengeMethod: r.codeChallengeMethod,
+		},
+	)
+	if err != nil {
+		log.Error("Failed to create OAuth2 authorization code: %v", err)
+		r.redirectError(c, "server_error", "Failed to create authorization code")
+		return
+	}
+	r.redirect(c, url.Values{"code": {code}})
+}
+
+const oauth2ConsentNonceSessionKey = "oauth2_consent_nonce"
+
+// OAuth2Authorize shows the consent screen of the authorization request, or
+// grants it straight away when the user has authorized the application before.
+func OAuth2Authorize(c *context.Context) {
+	c.Title("auth.oauth2_authorize")
+
+	r := parseOAuth2AuthorizeRequest(c)
+	if c.Written() {
+		return
+	}
+
+	hasGrant, err := database.Handle.OAuth2().HasGrant(c.Req.Context(), c.User.ID, r.app.ID)
+	if err != nil {
+		c.Errorf(err, "check OAuth2 grant")
+		return
+	} else if hasGrant {
+		r.grant(c)
+		return
+	}
+
+	owner, err := database.Handle.Users().GetByID(c.Req.Context(), r.app.UserID)
+	if err != nil {
+		c.Errorf(err, "get owner of OAuth2 application")
+		return
+	}
+
+	// 🚨 SECURITY: The nonce is required to approve the request, so other sites
+	// cannot trick the user into submitting the consent form.
+	nonce, err := strx.RandomChars(32)
+	if err != nil {
+		c.Errorf(err, "generate nonce")
+		return
+	}
+	_ = c.Session.Set(oauth2ConsentNonceSessionKey, nonce)
+
+	c.Data["Application"] = r.app
+	c.Data["ApplicationOwner"] = owner
+	c.Data["RedirectURI"] = r.redirectURI
+	c.Data["RawRedirectURI"] = r.rawRedirectURI
+	c.Data["State"] = r.state
+	c.Data["CodeChallenge"] = r.codeChallenge
+	c.Data["CodeChallengeMethod"] = r.codeChallengeMethod
+	c.Data["Nonce"] = nonce
+	c.Success(tmplUserAuthOAuth2Authorize)
+}
+
+// OAuth2AuthorizePost handles the decision of the user on the consent screen.
+func OAuth2AuthorizePost(c *context.Context) {
+	c.Title("auth.oauth2_authorize")
+
+	r := parseOAuth2AuthorizeRequest(c)
+	if c.Written() {
+		return
+	}
+
+	nonce, _ := c.Session.Get(oauth2ConsentNonceSessionKey).(string)
+	_ = c.Session.Delete(oauth2ConsentNonceSessionKey)
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(nonce), []byte(c.Query("nonce"))) != 1 {
+		c.Data["OAuth2Error"] = c.Tr("auth.oauth2_invalid_nonce")
+		c.HTML(http.StatusBadRequest, tmplUserAuthOAuth2Authorize)
+		return
+	}
+
+	if c.Query("granted") != "true" {
+		r.redirectError(c, "access_denied", "The user has denied the request")
+		return
+	}
+	r.grant(c)
+}
+
+// oauth2TokenError responds with the error as defined in
+// https://datatracker.ietf.org/doc/html/rfc6749#section-5.2.
+func oauth2TokenError(c *context.Context, status int, code, description string) {
+	c.Header().Set("Cache-Control", "no-store")
+	c.Header().Set("Pragma", "no-cache")
+	c.JSON(status, map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+}
+
+// authenticateOAuth2Client authenticates the client of the token request by
+// either HTTP Basic Authentication or the request body.
+func authenticateOAuth2Client(c *context.Context) *database.OAuth2Application {
+	clientID, clientSecret, ok := c.Req.BasicAuth()
+	if ok {
+		// Credentials are form-encoded before being used in HTTP Basic
+		// Authentication, see https://datatracker.ietf.org/doc/html/rfc6749#section-2.3.1.
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID = c.Query("client_id")
+		clientSecret = c.Query("client_secret")
+	}
+
+	app, err := database.Handle.OAuth2().AuthenticateClient(c.Req.Context(), clientID, clientSecret)
+	if err != nil {
+		if database.IsErrOAuth2InvalidClient(err) {
+			if ok {
+				c.Header().Set("WWW-Authenticate", `Basic realm="OAuth2"`)
+			}
+			oauth2TokenError(c, http.StatusUnauthorized, "invalid_client", "Client authentication failed")
+		} else {
+			log.Error("Failed to authenticate OAuth2 client: %v", err)
+			oauth2TokenError(c, http.StatusInternalServerError, "server_error", "Failed to authenticate client")
+		}
+		return nil
+	}
+	return app
+}
+
+// OAuth2AccessToken exchanges an authorization code or a refresh token for a
+// new pair of access token and refresh token.
+func OAuth2AccessToken(c *context.Context) {
+	app := authenticateOAuth2Client(c)
+	if c.Written() {
+		return
+	}
+
+	var token *database.OAuth2Token
+	var err error
+	switch c.Query("grant_type") {
+	case "authorization_code":
+		token, err = database.Handle.OAuth2().ExchangeAuthorizationCode(c.Req.Context(), app, c.Query("code"), c.Query("redirect_uri"), c.Query("code_verifier"))
+	case "refresh_token":
+		token, err = database.Handle.OAuth2().RefreshToken(c.Req.Context(), app, c.Query("refresh_token"))
+	default:
+		oauth2TokenError(c, http.StatusBadRequest, "unsupported_grant_type", "Grant type must be either authorization_code or refresh_token")
+		return
+	}
+	if err != nil {
+		if database.IsErrOAuth2InvalidGrant(err) {
+			oauth2TokenError(c, http.StatusBadRequest, "invalid_grant", err.Error())
+		} else {
+			log.Error("Failed to issue OAuth2 token: %v", err)
+			oauth2TokenError(c, http.StatusInternalServerError, "server_error", "Failed to issue token")
+		}
+		return
+	}
+
+	c.Header().Set("Cache-Control", "no-store")
+	c.Header().Set("Pragma", "no-cache")
+	c.JSONSuccess(map[string]any{
+		"access_token":  token.AccessToken,
+		"token_type":    "bearer",
+		"expires_in":    token.ExpiresIn,
+		"refresh_token": token.RefreshToken,
+	})
+}
+
+// OAuth2Revoke revokes an access token or a refresh token as defined in
+// https://datatracker.ietf.org/doc/html/rfc7009.
+func OAuth2Revoke(c *context.Context) {
+	app := authenticateOAuth2Client(c)
+	if c.Written() {
+		return
+	}
+
+	err := database.Handle.OAuth2().RevokeToken(c.Req.Context(), app, c.Query("token"))
+	if err != nil {
+		log.Error("Failed to revoke OAuth2 token: %v", err)
+		oauth2TokenError(c, http.StatusServiceUnavailable, "server_error", "Failed to revoke token")
+		return
+	}
+	c.Status(http.StatusOK)
+}
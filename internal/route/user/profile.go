@@ -32,6 +32,7 @@ func Profile(c *context.Context, puser *context.ParamsUser) {
 	c.Title(puser.DisplayName())
 	c.PageIs("UserProfile")
 	c.Data["Owner"] = puser
+	c.Data["FeedURL"] = conf.Server.Subpath + "/" + puser.Name
 
 	orgs, err := database.GetOrgsByUserID(puser.ID, c.IsLogged && (c.User.IsAdmin || c.User.ID == puser.ID))
 	if err != nil {
@@ -117,8 +118,11 @@ func Action(c *context.Context, puser *context.ParamsUser) {
 	}
 
 	if err != nil {
-		c.Errorf(err, "action %q", c.Params(":action"))
-		return
+		if !database.IsErrBlockedByUser(err) {
+			c.Errorf(err, "action %q", c.Params(":action"))
+			return
+		}
+		c.Flash.Error(c.Tr("form.blocked_by_user"))
 	}
 
 	redirectTo := c.Query("redirect_to")
@@ -47,6 +47,7 @@ const (
 	tmplUserSettingsPassword               = "user/settings/password"
 	tmplUserSettingsEmail                  = "user/settings/email"
 	tmplUserSettingsSSHKeys                = "user/settings/sshkeys"
+	tmplUserSettingsGPGKeys                = "user/settings/gpgkeys"
 	tmplUserSettingsSecurity               = "user/settings/security"
 	tmplUserSettingsTwoFactorEnable        = "user/settings/two_factor_enable"
 	tmplUserSettingsTwoFactorRecoveryCodes = "user/settings/two_factor_recovery_codes"
@@ -439,6 +440,68 @@ func DeleteSSHKey(c *context.Context) {
 	})
 }
 
+func SettingsGPGKeys(c *context.Context) {
+	c.Title("settings.gpg_keys")
+	c.PageIs("SettingsGPGKeys")
+
+	keys, err := database.Handle.GPGKeys().List(c.Req.Context(), c.User.ID)
+	if err != nil {
+		c.Errorf(err, "list GPG keys")
+		return
+	}
+	c.Data["Keys"] = keys
+
+	c.Success(tmplUserSettingsGPGKeys)
+}
+
+func SettingsGPGKeysPost(c *context.Context, f form.AddGPGKey) {
+	c.Title("settings.gpg_keys")
+	c.PageIs("SettingsGPGKeys")
+
+	keys, err := database.Handle.GPGKeys().List(c.Req.Context(), c.User.ID)
+	if err != nil {
+		c.Errorf(err, "list GPG keys")
+		return
+	}
+	c.Data["Keys"] = keys
+
+	if c.HasError() {
+		c.HTML(http.StatusBadRequest, tmplUserSettingsGPGKeys)
+		return
+	}
+
+	key, err := database.Handle.GPGKeys().Create(c.Req.Context(), c.User.ID, f.Content)
+	if err != nil {
+		c.Data["HasError"] = true
+		switch {
+		case database.IsErrGPGKeyInvalid(err):
+			c.FormErr("Content")
+			c.RenderWithErr(c.Tr("form.invalid_gpg_key", err.Error()), http.StatusUnprocessableEntity, tmplUserSettingsGPGKeys, &f)
+		case database.IsErrGPGKeyAlreadyExist(err):
+			c.FormErr("Content")
+			c.RenderWithErr(c.Tr("settings.gpg_key_been_used"), http.StatusUnprocessableEntity, tmplUserSettingsGPGKeys, &f)
+		default:
+			c.Errorf(err, "create GPG key")
+		}
+		return
+	}
+
+	c.Flash.Success(c.Tr("settings.add_gpg_key_success", key.KeyID))
+	c.RedirectSubpath("/user/settings/gpg")
+}
+
+func DeleteGPGKey(c *context.Context) {
+	if err := database.Handle.GPGKeys().DeleteByID(c.Req.Context(), c.User.ID, c.QueryInt64("id")); err != nil {
+		c.Flash.Error("DeleteGPGKey: " + err.Error())
+	} else {
+		c.Flash.Success(c.Tr("settings.gpg_key_deletion_success"))
+	}
+
+	c.JSONSuccess(map[string]any{
+		"redirect": conf.Server.Subpath + "/user/settings/gpg",
+	})
+}
+
 func SettingsSecurity(c *context.Context) {
 	c.Title("settings.security")
 	c.PageIs("SettingsSecurity")
@@ -703,6 +766,20 @@ func (h *SettingsHandler) DeleteApplication() macaron.Handler {
 	}
 }
 
+func (h *SettingsHandler) RegenerateFeedToken() macaron.Handler {
+	return func(c *context.Context) {
+		token, err := h.store.RegenerateFeedToken(c.Req.Context(), c.User.ID)
+		if err != nil {
+			c.Errorf(err, "regenerate feed token")
+			return
+		}
+
+		c.Flash.Success(c.Tr("settings.regenerate_feed_token_success"))
+		c.Flash.Info(conf.Server.ExternalURL + c.User.Name + ".atom?token=" + token)
+		c.RedirectSubpath("/user/settings/applications")
+	}
+}
+
 func SettingsDelete(c *context.Context) {
 	c.Title("settings.delete")
 	c.PageIs("SettingsDelete")
@@ -756,6 +833,9 @@ type SettingsStore interface {
 	ListAccessTokens(ctx gocontext.Context, userID int64) ([]*database.AccessToken, error)
 	// DeleteAccessTokenByID deletes the access token by given ID.
 	DeleteAccessTokenByID(ctx gocontext.Context, userID, id int64) error
+	// RegenerateFeedToken generates and saves a new feed token for the user, the
+	// previous token becomes invalid immediately.
+	RegenerateFeedToken(ctx gocontext.Context, userID int64) (string, error)
 }
 
 type settingsStore struct{}
@@ -785,3 +865,7 @@ func (*settingsStore) ListAccessTokens(ctx gocontext.Context, userID int64) ([]*
 func (*settingsStore) DeleteAccessTokenByID(ctx gocontext.Context, userID, id int64) error {
 	return database.Handle.AccessTokens().DeleteByID(ctx, userID, id)
 }
+
+func (*settingsStore) RegenerateFeedToken(ctx gocontext.Context, userID int64) (string, error) {
+	return database.Handle.Users().RegenerateFeedToken(ctx, userID)
+}
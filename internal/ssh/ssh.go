@@ -30,7 +30,17 @@ func cleanCommand(cmd string) string {
 	return cmd[i:]
 }
 
-func handleServerConn(keyID string, chans <-chan ssh.NewChannel) {
+// servIdentity returns the identity argument of the "serv" command for the
+// authenticated connection, i.e. "key-<id>" for public keys and "user-<id>" for
+// certificates.
+func servIdentity(perms *ssh.Permissions) string {
+	if userID, ok := perms.Extensions["user-id"]; ok {
+		return "user-" + userID
+	}
+	return "key-" + perms.Extensions["key-id"]
+}
+
+func handleServerConn(identity string, chans <-chan ssh.NewChannel) {
 	for newChan := range chans {
 		if newChan.ChannelType() != "session" {
 			_ = newChan.Reject(ssh.UnknownChannelType, "unknown channel type")
@@ -58,7 +68,7 @@ func handleServerConn(keyID string, chans <-chan ssh.NewChannel) {
 					cmdName := strings.TrimLeft(payload, "'()")
 					log.Trace("SSH: Payload: %v", cmdName)
 
-					args := []string{"serv", "key-" + keyID, "--config=" + conf.CustomConf}
+					args := []string{"serv", identity, "--config=" + conf.CustomConf}
 					log.Trace("SSH: Arguments: %v", args)
 					cmd := exec.Command(conf.AppPath(), args...)
 					cmd.Env = append(os.Environ(), "SSH_ORIGINAL_COMMAND="+cmdName)
@@ -149,19 +159,52 @@ func listen(config *ssh.ServerConfig, host string, port int) {
 			log.Trace("SSH: Connection from %s (%s)", sConn.RemoteAddr(), sConn.ClientVersion())
 			// The incoming Request channel must be serviced.
 			go ssh.DiscardRequests(reqs)
-			go handleServerConn(sConn.Permissions.Extensions["key-id"], chans)
+			go handleServerConn(servIdentity(sConn.Permissions), chans)
 		}()
 	}
 }
 
+// authenticateCertificate authenticates the user certificate against given
+// trusted user CA keys and maps its principals to a Gogs user.
+func authenticateCertificate(conn ssh.ConnMetadata, cert *ssh.Certificate, authorities []ssh.PublicKey) (*ssh.Permissions, error) {
+	if len(authorities) == 0 {
+		return nil, errors.New("SSH certificate authentication is not enabled")
+	}
+
+	err := database.CheckSSHUserCertificate(cert, authorities, conn.RemoteAddr())
+	if err != nil {
+		log.Trace("SSH: Rejected certificate from %s: %v", conn.RemoteAddr(), err)
+		return nil, err
+	}
+
+	user, principal, err := database.GetUserBySSHCertificate(context.Background(), cert)
+	if err != nil {
+		if !database.IsErrUserNotExist(err) {
+			log.Error("GetUserBySSHCertificate: %v", err)
+		}
+		return nil, err
+	}
+	log.Trace("SSH: Certificate %q authenticated as %q via principal %q", cert.KeyId, user.Name, principal)
+	return &ssh.Permissions{Extensions: map[string]string{"user-id": strconv.FormatInt(user.ID, 10)}}, nil
+}
+
 // Listen starts a SSH server listens on given port.
 func Listen(opts conf.SSHOpts, appDataPath string) {
+	authorities, err := database.LoadTrustedUserCAKeys(opts.TrustedUserCAKeysFile)
+	if err != nil {
+		log.Fatal("SSH: Failed to load trusted user CA keys: %v", err)
+	}
+
 	config := &ssh.ServerConfig{
 		Config: ssh.Config{
 			Ciphers: opts.ServerCiphers,
 			MACs:    opts.ServerMACs,
 		},
 		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if cert, ok := key.(*ssh.Certificate); ok {
+				return authenticateCertificate(conn, cert, authorities)
+			}
+
 			pkey, err := database.SearchPublicKeyByContent(strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key))))
 			if err != nil {
 				if !database.IsErrKeyNotExist(err) {
@@ -64,6 +64,12 @@ func FuncMap() []template.FuncMap {
 			"DisableGravatar": func() bool {
 				return conf.Picture.DisableGravatar
 			},
+			"ArchiveFormats": func() []string {
+				return conf.Repository.Archive.Formats
+			},
+			"EnableOAuth2": func() bool {
+				return conf.OAuth2.Enabled
+			},
 			"ShowFooterTemplateLoadTime": func() bool {
 				return conf.Other.ShowFooterTemplateLoadTime
 			},
@@ -104,11 +110,13 @@ func FuncMap() []template.FuncMap {
 				return str[start:end]
 			},
 			"Join":                  strings.Join,
+			"ToUpper":               strings.ToUpper,
 			"EllipsisString":        strx.Ellipsis,
 			"DiffFileTypeToStr":     DiffFileTypeToStr,
 			"DiffLineTypeToStr":     DiffLineTypeToStr,
 			"Sha1":                  Sha1,
 			"ShortSHA1":             tool.ShortSHA1,
+			"FormatDuration":        tool.FormatDuration,
 			"ActionContent2Commits": ActionContent2Commits,
 			"EscapePound":           EscapePound,
 			"RenderCommitMessage":   RenderCommitMessage,
@@ -297,6 +297,22 @@ func TimeSince(t time.Time, lang string) template.HTML {
 	return template.HTML(fmt.Sprintf(`<span class="time-since" title="%s">%s</span>`, t.Format(conf.Time.FormatLayout), timeSince(t, lang)))
 }
 
+// FormatDuration formats the duration in seconds to a short string in hours
+// and minutes, e.g. "1h 30m".
+func FormatDuration(seconds int64) string {
+	hours, minutes := seconds/Hour, seconds%Hour/Minute
+	switch {
+	case hours > 0 && minutes > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%dh", hours)
+	case minutes > 0:
+		return fmt.Sprintf("%dm", minutes)
+	default:
+		return fmt.Sprintf("%ds", seconds)
+	}
+}
+
 // Subtract deals with subtraction of all types of number.
 func Subtract(left, right any) any {
 	var rleft, rright int64
@@ -0,0 +1,27 @@
+package tool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		seconds int64
+		want    string
+	}{
+		{name: "zero", seconds: 0, want: "0s"},
+		{name: "seconds", seconds: 59, want: "59s"},
+		{name: "minutes", seconds: 90, want: "1m"},
+		{name: "hours", seconds: 2 * Hour, want: "2h"},
+		{name: "hours and minutes", seconds: Hour + 30*Minute + 15, want: "1h 30m"},
+		{name: "more than a day", seconds: 25 * Hour, want: "25h"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, FormatDuration(test.seconds))
+		})
+	}
+}
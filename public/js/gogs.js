@@ -116,12 +116,6 @@ function initCommentForm() {
 
   initCommentPreviewTab($(".comment.form"));
 
-  // Labels
-  var $list = $(".ui.labels.list");
-  var $noSelect = $list.find(".no-select");
-  var $labelMenu = $(".select-label .menu");
-  var hasLabelUpdateAction = $labelMenu.data("action") == "update";
-
   function updateIssueMeta(url, action, id) {
     $.post(url, {
       action: action,
@@ -129,92 +123,99 @@ function initCommentForm() {
     });
   }
 
-  // Add &nbsp; to each unselected label to keep UI looks good.
-  // This should be added directly to HTML but somehow just get empty <span> on this page.
-  $labelMenu
-    .find(".item:not(.no-select) .octicon:not(.octicon-check)")
-    .each(function() {
-      $(this).html("&nbsp;");
-    });
-  $labelMenu.find(".item:not(.no-select)").click(function() {
-    if ($(this).hasClass("checked")) {
-      $(this).removeClass("checked");
-      $(this)
-        .find(".octicon")
-        .removeClass("octicon-check")
-        .html("&nbsp;");
-      if (hasLabelUpdateAction) {
-        updateIssueMeta(
-          $labelMenu.data("update-url"),
-          "detach",
-          $(this).data("id")
-        );
-      }
-    } else {
-      $(this).addClass("checked");
-      $(this)
-        .find(".octicon")
-        .addClass("octicon-check")
-        .html("");
-      if (hasLabelUpdateAction) {
-        updateIssueMeta(
-          $labelMenu.data("update-url"),
-          "attach",
-          $(this).data("id")
-        );
-      }
-    }
+  // Multiple selection of labels, assignees and reviewers. Items may override
+  // the update URL of the menu with their own "data-update-url".
+  function selectItems(select_id, list_id) {
+    var $list = $(list_id);
+    var $noSelect = $list.find(".no-select");
+    var $menu = $(select_id + " .menu");
+    var hasUpdateAction = $menu.data("action") == "update";
 
-    var labelIds = "";
-    $(this)
-      .parent()
-      .find(".item")
+    // Add &nbsp; to each unselected item to keep UI looks good.
+    // This should be added directly to HTML but somehow just get empty <span> on this page.
+    $menu
+      .find(".item:not(.no-select) .octicon:not(.octicon-check)")
       .each(function() {
-        if ($(this).hasClass("checked")) {
-          labelIds += $(this).data("id") + ",";
-          $($(this).data("id-selector")).removeClass("hide");
-        } else {
-          $($(this).data("id-selector")).addClass("hide");
-        }
+        $(this).html("&nbsp;");
       });
-    if (labelIds.length == 0) {
-      $noSelect.removeClass("hide");
-    } else {
-      $noSelect.addClass("hide");
-    }
-    $(
-      $(this)
-        .parent()
-        .data("id")
-    ).val(labelIds);
-    return false;
-  });
-  $labelMenu.find(".no-select.item").click(function() {
-    if (hasLabelUpdateAction) {
-      updateIssueMeta($labelMenu.data("update-url"), "clear", "");
-    }
-
-    $(this)
-      .parent()
-      .find(".item")
-      .each(function() {
+    $menu.find(".item:not(.no-select)").click(function() {
+      var updateUrl = $(this).data("update-url") || $menu.data("update-url");
+      if ($(this).hasClass("checked")) {
         $(this).removeClass("checked");
         $(this)
           .find(".octicon")
           .removeClass("octicon-check")
           .html("&nbsp;");
-      });
+        if (hasUpdateAction) {
+          updateIssueMeta(updateUrl, "detach", $(this).data("id"));
+        }
+      } else {
+        $(this).addClass("checked");
+        $(this)
+          .find(".octicon")
+          .addClass("octicon-check")
+          .html("");
+        if (hasUpdateAction) {
+          updateIssueMeta(updateUrl, "attach", $(this).data("id"));
+        }
+      }
 
-    $list.find(".item").each(function() {
-      $(this).addClass("hide");
+      var ids = "";
+      $(this)
+        .parent()
+        .find(".item")
+        .each(function() {
+          if ($(this).hasClass("checked")) {
+            ids += $(this).data("id") + ",";
+            $($(this).data("id-selector")).removeClass("hide");
+          } else {
+            $($(this).data("id-selector")).addClass("hide");
+          }
+        });
+      if (ids.length == 0) {
+        $noSelect.removeClass("hide");
+      } else {
+        $noSelect.addClass("hide");
+      }
+      $(
+        $(this)
+          .parent()
+          .data("id")
+      ).val(ids);
+      return false;
     });
-    $noSelect.removeClass("hide");
-    $(
+    $menu.find(".no-select.item").click(function() {
+      if (hasUpdateAction) {
+        updateIssueMeta($menu.data("update-url"), "clear", "");
+      }
+
       $(this)
         .parent()
-        .data("id")
-    ).val("");
-  });
+        .find(".item")
+        .each(function() {
+          $(this).removeClass("checked");
+          $(this)
+            .find(".octicon")
+            .removeClass("octicon-check")
+            .html("&nbsp;");
+        });
+
+      $list.find(".item").each(function() {
+        $(this).addClass("hide");
+      });
+      $noSelect.removeClass("hide");
+      $(
+        $(this)
+          .parent()
+          .data("id")
+      ).val("");
+    });
+  }
+
+  // Labels, assignees and reviewers
+  selectItems(".select-label", ".ui.labels.list");
+  selectItems(".select-assignees", ".ui.assignees.list");
+  selectItems(".select-reviewers", ".ui.reviewers.list");
 
   function selectItem(select_id, input_id) {
     var $menu = $(select_id + " .menu");
@@ -239,15 +240,6 @@ function initCommentForm() {
           $milestoneAnchor.attr("href", $(this).data("href"));
           $milestoneAnchor.text($(this).text());
           $list.find(".selected").empty().append($milestoneAnchor);
-          break;
-        case "#assignee_id":
-          var $assigneeAnchor = $('<a class="item"></a>');
-          $assigneeAnchor.attr("href", $(this).data("href"));
-          $assigneeAnchor.append(
-            $('<img class="ui avatar image">').attr("src", $(this).data("avatar"))
-          );
-          $assigneeAnchor.append($("<span></span>").text($(this).text()));
-          $list.find(".selected").empty().append($assigneeAnchor);
       }
       $(".ui" + select_id + ".list .no-select").addClass("hide");
       $(input_id).val($(this).data("id"));
@@ -270,9 +262,8 @@ function initCommentForm() {
     });
   }
 
-  // Milestone and assignee
+  // Milestone
   selectItem(".select-milestone", "#milestone_id");
-  selectItem(".select-assignee", "#assignee_id");
 }
 
 function initRepository() {
@@ -337,7 +328,10 @@ function initRepository() {
   }
 
   // Branches
-  if ($(".repository.settings.branches").length > 0) {
+  if (
+    $(".repository.settings.branches").length > 0 ||
+    $(".organization.settings.branches").length > 0
+  ) {
     initFilterSearchDropdown(".protected-branches .dropdown");
     $(".enable-protection, .enable-whitelist").change(function() {
       if (this.checked) {
@@ -615,7 +609,13 @@ function initRepository() {
       } else {
         $(".commit.description.field").hide();
       }
+      if ($(this).val() === "squash_and_merge") {
+        $(".squash.message.field").show();
+      } else {
+        $(".squash.message.field").hide();
+      }
     });
+    $(".comment.merge.box input[name=merge_style]:checked").change();
   }
 }
 
@@ -192,6 +192,14 @@ const KEYS = [
   "repo.copy_full_sha",
   "repo.renamed_from",
   "repo.authored",
+  "repo.commits.verified",
+  "repo.commits.unverified",
+  "repo.commits.verification_reason.unknown_signature_type",
+  "repo.commits.verification_reason.no_user",
+  "repo.commits.verification_reason.unknown_key",
+  "repo.commits.verification_reason.bad_signature",
+  "repo.commits.verification_reason.unverified_email",
+  "repo.commits.verification_reason.internal_error",
   "repo.parents",
   "repo.diff_label",
   "repo.patch_label",
@@ -43,12 +43,20 @@ export interface RepoCommitSignature {
   when: string;
 }
 
+export interface RepoCommitVerification {
+  verified: boolean;
+  reason: string;
+  signer?: string;
+  signingKey?: string;
+}
+
 export interface RepoCommitPage {
   sha: string;
   subject: string;
   body: string;
   author: RepoCommitSignature;
   parents: string[];
+  verification?: RepoCommitVerification;
   patch: string;
 }
 
@@ -186,7 +194,7 @@ function CommitBody({ body }: { body: string }) {
 
 export function RepoCommit() {
   const data = useLoaderData({ from: "/$owner/$repo/commit/$sha" });
-  const { sha, subject, body, author, parents, patch } = data;
+  const { sha, subject, body, author, parents, verification, patch } = data;
   const { owner, repo } = useParams({ from: "/$owner/$repo/commit/$sha" });
   const search: RepoCommitSearch = useSearch({ from: "/$owner/$repo/commit/$sha" });
   const navigate = useNavigate({ from: "/$owner/$repo/commit/$sha" });
@@ -743,9 +751,26 @@ export function RepoCommit() {
               <TooltipContent>{formatAbsoluteTime(author.when)}</TooltipContent>
             </Tooltip>
           </span>
-          {/* TODO: render a "Verified" pill once the backend exposes commit
-              signature verification. Hidden for now to avoid claiming
-              verification we don't actually perform. */}
+          {verification ? (
+            <Tooltip>
+              <TooltipTrigger asChild>
+                <span
+                  className={
+                    verification.verified
+                      ? "inline-flex h-5 items-center rounded-full border border-(--color-success) px-2 text-xs text-(--color-success)"
+                      : "inline-flex h-5 items-center rounded-full border border-(--color-border) px-2 text-xs"
+                  }
+                >
+                  {verification.verified ? t("repo.commits.verified") : t("repo.commits.unverified")}
+                </span>
+              </TooltipTrigger>
+              <TooltipContent>
+                {verification.verified
+                  ? [verification.signer, verification.signingKey].filter(Boolean).join(" · ")
+                  : t(`repo.commits.verification_reason.${verification.reason}`)}
+              </TooltipContent>
+            </Tooltip>
+          ) : null}
 
           <span aria-hidden className="hidden h-4 w-px bg-(--color-border) sm:inline-block" />
 